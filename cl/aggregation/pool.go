@@ -26,4 +26,6 @@ type AggregationPool interface {
 	// AddAttestation adds a single attestation to the pool.
 	AddAttestation(att *solid.Attestation) error
 	GetAggregatationByRoot(root common.Hash) *solid.Attestation
+	// GetAggregatationByRootAndCommittee returns the electra aggregate of a single committee for the given attestation data root.
+	GetAggregatationByRootAndCommittee(root common.Hash, committeeIndex uint64) *solid.Attestation
 }
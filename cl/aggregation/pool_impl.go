@@ -44,6 +44,13 @@ type aggregationPoolImpl struct {
 	ethClock       eth_clock.EthereumClock
 	aggregatesLock sync.RWMutex
 	aggregates     map[common.Hash]*solid.Attestation
+	// committeeAggregates holds the electra aggregates of a single committee, as all committees of a slot share the same attestation data.
+	committeeAggregates map[committeeAggregateKey]*solid.Attestation
+}
+
+type committeeAggregateKey struct {
+	root           common.Hash
+	committeeIndex uint64
 }
 
 func NewAggregationPool(
@@ -58,6 +65,8 @@ func NewAggregationPool(
 		netConfig:      netConfig,
 		aggregatesLock: sync.RWMutex{},
 		aggregates:     make(map[common.Hash]*solid.Attestation),
+
+		committeeAggregates: make(map[committeeAggregateKey]*solid.Attestation),
 	}
 	go p.sweepStaleAtt(ctx)
 	return p
@@ -71,6 +80,11 @@ func (p *aggregationPoolImpl) AddAttestation(inAtt *solid.Attestation) error {
 	}
 	p.aggregatesLock.Lock()
 	defer p.aggregatesLock.Unlock()
+	if inAtt.CommitteeBits != nil {
+		if err := p.addCommitteeAttestation(hashRoot, inAtt); err != nil {
+			return err
+		}
+	}
 	att, ok := p.aggregates[hashRoot]
 	if !ok {
 		p.aggregates[hashRoot] = inAtt.Copy()
@@ -133,12 +147,56 @@ func (p *aggregationPoolImpl) AddAttestation(inAtt *solid.Attestation) error {
 	return nil
 }
 
+// addCommitteeAttestation merges an electra attestation into the aggregate of its committee. Attestations spanning
+// more than one committee are only tracked in the per-root aggregates.
+func (p *aggregationPoolImpl) addCommitteeAttestation(hashRoot common.Hash, inAtt *solid.Attestation) error {
+	committees := inAtt.CommitteeBits.GetOnIndices()
+	if len(committees) != 1 {
+		return nil
+	}
+	key := committeeAggregateKey{root: hashRoot, committeeIndex: uint64(committees[0])}
+	att, ok := p.committeeAggregates[key]
+	if !ok {
+		p.committeeAggregates[key] = inAtt.Copy()
+		return nil
+	}
+	if utils.IsOverlappingBitlist(att.AggregationBits.Bytes(), inAtt.AggregationBits.Bytes()) {
+		return nil
+	}
+	merged, err := blsAggregate([][]byte{att.Signature[:], inAtt.Signature[:]})
+	if err != nil {
+		return err
+	}
+	if len(merged) != 96 {
+		return errors.New("merged signature is too long")
+	}
+	mergedBits, err := att.AggregationBits.Union(inAtt.AggregationBits)
+	if err != nil {
+		return err
+	}
+	var mergedSig [96]byte
+	copy(mergedSig[:], merged)
+	p.committeeAggregates[key] = &solid.Attestation{
+		AggregationBits: mergedBits,
+		CommitteeBits:   att.CommitteeBits,
+		Data:            att.Data,
+		Signature:       mergedSig,
+	}
+	return nil
+}
+
 func (p *aggregationPoolImpl) GetAggregatationByRoot(root common.Hash) *solid.Attestation {
 	p.aggregatesLock.RLock()
 	defer p.aggregatesLock.RUnlock()
 	return p.aggregates[root]
 }
 
+func (p *aggregationPoolImpl) GetAggregatationByRootAndCommittee(root common.Hash, committeeIndex uint64) *solid.Attestation {
+	p.aggregatesLock.RLock()
+	defer p.aggregatesLock.RUnlock()
+	return p.committeeAggregates[committeeAggregateKey{root: root, committeeIndex: committeeIndex}]
+}
+
 func (p *aggregationPoolImpl) sweepStaleAtt(ctx context.Context) {
 	ticker := time.NewTicker(time.Minute)
 	for {
@@ -158,6 +216,11 @@ func (p *aggregationPoolImpl) sweepStaleAtt(ctx context.Context) {
 			for _, hashRoot := range toRemoves {
 				delete(p.aggregates, hashRoot)
 			}
+			for key, att := range p.committeeAggregates {
+				if p.slotIsStale(att.Data.Slot) {
+					delete(p.committeeAggregates, key)
+				}
+			}
 			p.aggregatesLock.Unlock()
 		}
 	}
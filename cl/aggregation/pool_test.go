@@ -169,6 +169,48 @@ func (t *PoolTestSuite) TestAddAttestationElectra() {
 	}
 }
 
+func (t *PoolTestSuite) TestGetAggregationByRootAndCommitteeElectra() {
+	cBits1 := solid.NewBitVector(64)
+	cBits1.SetBitAt(0, true)
+	cBits2 := solid.NewBitVector(64)
+	cBits2.SetBitAt(10, true)
+
+	att1 := &solid.Attestation{
+		AggregationBits: solid.BitlistFromBytes([]byte{0b00000001, 0, 0, 0}, 2048*64),
+		Data:            attData1,
+		Signature:       [96]byte{'a', 'b', 'c', 'd', 'e', 'f'},
+		CommitteeBits:   cBits1,
+	}
+	att2 := &solid.Attestation{
+		AggregationBits: solid.BitlistFromBytes([]byte{0b00000000, 0b00001000, 0, 0}, 2048*64),
+		Data:            attData1,
+		Signature:       [96]byte{'d', 'e', 'f', 'g', 'h', 'i'},
+		CommitteeBits:   cBits2,
+	}
+	att3 := &solid.Attestation{
+		AggregationBits: solid.BitlistFromBytes([]byte{0b00000100, 0, 0, 0}, 2048*64),
+		Data:            attData1,
+		Signature:       [96]byte{'g', 'h', 'i', 'j', 'k', 'l'},
+		CommitteeBits:   cBits1,
+	}
+	t.mockEthClock.EXPECT().GetEpochAtSlot(gomock.Any()).Return(uint64(1)).Times(2)
+	t.mockEthClock.EXPECT().StateVersionByEpoch(gomock.Any()).Return(clparams.ElectraVersion).Times(2)
+
+	pool := NewAggregationPool(context.Background(), t.mockBeaconConfig, nil, t.mockEthClock)
+	for _, att := range []*solid.Attestation{att1, att2, att3} {
+		t.Require().NoError(pool.AddAttestation(att))
+	}
+
+	t.Equal(&solid.Attestation{
+		AggregationBits: solid.BitlistFromBytes([]byte{0b00000101, 0, 0, 0}, 2048*64),
+		Data:            attData1,
+		Signature:       mockAggrResult,
+		CommitteeBits:   cBits1,
+	}, pool.GetAggregatationByRootAndCommittee(attData1Root, 0))
+	t.Equal(att2, pool.GetAggregatationByRootAndCommittee(attData1Root, 10))
+	t.Nil(pool.GetAggregatationByRootAndCommittee(attData1Root, 1))
+}
+
 func (t *PoolTestSuite) TestAddAttestation() {
 	testcases := []struct {
 		name     string
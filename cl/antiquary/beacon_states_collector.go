@@ -59,6 +59,7 @@ type beaconStatesCollector struct {
 	currentSyncCommitteeCollector    *etl.Collector
 	eth1DataVotesCollector           *etl.Collector
 	stateEventsCollector             *etl.Collector
+	participationDiffsCollector      *etl.Collector
 	activeValidatorIndiciesCollector *etl.Collector
 	balancesDumpsCollector           *etl.Collector
 	effectiveBalancesDumpCollector   *etl.Collector
@@ -92,6 +93,7 @@ func newBeaconStatesCollector(beaconCfg *clparams.BeaconChainConfig, tmpdir stri
 		currentSyncCommitteeCollector:    etl.NewCollector(kv.CurrentSyncCommittee, tmpdir, etl.NewSortableBuffer(stateAntiquaryBufSz), logger).LogLvl(log.LvlTrace),
 		eth1DataVotesCollector:           etl.NewCollector(kv.Eth1DataVotes, tmpdir, etl.NewSortableBuffer(stateAntiquaryBufSz), logger).LogLvl(log.LvlTrace),
 		stateEventsCollector:             etl.NewCollector(kv.StateEvents, tmpdir, etl.NewSortableBuffer(stateAntiquaryBufSz), logger).LogLvl(log.LvlTrace),
+		participationDiffsCollector:      etl.NewCollector(kv.ParticipationDiffs, tmpdir, etl.NewSortableBuffer(stateAntiquaryBufSz), logger).LogLvl(log.LvlTrace),
 		activeValidatorIndiciesCollector: etl.NewCollector(kv.ActiveValidatorIndicies, tmpdir, etl.NewSortableBuffer(stateAntiquaryBufSz), logger).LogLvl(log.LvlTrace),
 		balancesDumpsCollector:           etl.NewCollector(kv.BalancesDump, tmpdir, etl.NewSortableBuffer(stateAntiquaryBufSz), logger).LogLvl(log.LvlTrace),
 		effectiveBalancesDumpCollector:   etl.NewCollector(kv.EffectiveBalancesDump, tmpdir, etl.NewSortableBuffer(stateAntiquaryBufSz), logger).LogLvl(log.LvlTrace),
@@ -161,6 +163,9 @@ func (i *beaconStatesCollector) addGenesisState(ctx context.Context, state *stat
 	if err := i.storeSlotData(state, nil); err != nil {
 		return err
 	}
+	if err := i.collectParticipationDiff(slot, nil, nil, state.RawPreviousEpochParticipation(), state.RawCurrentEpochParticipation()); err != nil {
+		return err
+	}
 
 	return i.stateEventsCollector.Collect(base_encoding.Encode64ToBytes4(slot), events.CopyBytes())
 }
@@ -274,6 +279,16 @@ func (i *beaconStatesCollector) collectStateEvents(slot uint64, events *state_ac
 	return i.stateEventsCollector.Collect(base_encoding.Encode64ToBytes4(slot), events.CopyBytes())
 }
 
+// collectParticipationDiff stores the participation flags changed by the block at the given slot, old participation
+// must already be rotated to the block's epoch.
+func (i *beaconStatesCollector) collectParticipationDiff(slot uint64, oldPrevious, oldCurrent, newPrevious, newCurrent []byte) error {
+	i.buf.Reset()
+	if err := state_accessors.ComputeParticipationDiff(oldPrevious, oldCurrent, newPrevious, newCurrent).EncodeTo(i.buf); err != nil {
+		return err
+	}
+	return i.participationDiffsCollector.Collect(base_encoding.Encode64ToBytes4(slot), i.buf.Bytes())
+}
+
 func (i *beaconStatesCollector) collectBalancesDiffs(ctx context.Context, slot uint64, old, new []byte) error {
 	return antiquateBytesListDiff(ctx, base_encoding.Encode64ToBytes4(slot), old, new, i.balancesCollector, base_encoding.ComputeCompressedSerializedUint64ListDiff)
 }
@@ -339,6 +354,9 @@ func (i *beaconStatesCollector) flush(ctx context.Context, tx kv.RwTx) error {
 	if err := i.stateEventsCollector.Load(tx, kv.StateEvents, loadfunc, etl.TransformArgs{Quit: ctx.Done()}); err != nil {
 		return err
 	}
+	if err := i.participationDiffsCollector.Load(tx, kv.ParticipationDiffs, loadfunc, etl.TransformArgs{Quit: ctx.Done()}); err != nil {
+		return err
+	}
 	if err := i.effectiveBalancesDumpCollector.Load(tx, kv.EffectiveBalancesDump, loadfunc, etl.TransformArgs{Quit: ctx.Done()}); err != nil {
 		return err
 	}
@@ -362,6 +380,7 @@ func (i *beaconStatesCollector) close() {
 	i.currentSyncCommitteeCollector.Close()
 	i.eth1DataVotesCollector.Close()
 	i.stateEventsCollector.Close()
+	i.participationDiffsCollector.Close()
 	i.activeValidatorIndiciesCollector.Close()
 	i.balancesDumpsCollector.Close()
 	i.effectiveBalancesDumpCollector.Close()
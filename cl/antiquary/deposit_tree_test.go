@@ -0,0 +1,112 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package antiquary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv/memdb"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/merkle_tree"
+	state_accessors "github.com/erigontech/erigon/cl/persistence/state"
+	"github.com/erigontech/erigon/cl/utils"
+)
+
+func testDeposits(t *testing.T, from, to int, proof func(h int) libcommon.Hash) (*solid.ListSSZ[*cltypes.Deposit], []libcommon.Hash) {
+	deposits := solid.NewDynamicListSSZ[*cltypes.Deposit](int(cltypes.MaxDeposits))
+	var leaves []libcommon.Hash
+	for i := from; i < to; i++ {
+		d := &cltypes.Deposit{Proof: solid.NewHashVector(merkle_tree.DepositContractDepth + 1), Data: &cltypes.DepositData{PubKey: [48]byte{byte(i)}, Amount: uint64(i)}}
+		for h := 0; h <= merkle_tree.DepositContractDepth; h++ {
+			d.Proof.Set(h, proof(h))
+		}
+		leaf, err := d.Data.HashSSZ()
+		require.NoError(t, err)
+		deposits.Append(d)
+		leaves = append(leaves, leaf)
+	}
+	return deposits, leaves
+}
+
+func TestExtendDepositTree(t *testing.T) {
+	garbage := func(h int) libcommon.Hash { return utils.Sha256([]byte{0xff, byte(h)}) }
+	first, firstLeaves := testDeposits(t, 0, 3, garbage)
+	second, secondLeaves := testDeposits(t, 3, 6, garbage)
+	expected := &merkle_tree.DepositTree{}
+	for _, leaf := range append(firstLeaves, secondLeaves...) {
+		require.NoError(t, expected.Push(leaf))
+	}
+
+	// the tree of the chain is extended, the proofs of the deposits are not looked at
+	tree, err := extendDepositTree(&merkle_tree.DepositTree{}, 0, first)
+	require.NoError(t, err)
+	tree, err = extendDepositTree(tree, 3, nil)
+	require.NoError(t, err)
+	tree, err = extendDepositTree(tree, 3, second)
+	require.NoError(t, err)
+	require.Equal(t, expected.Count(), tree.Count())
+	require.Equal(t, expected.Root(), tree.Root())
+
+	// deposits before the block are unknown: start from the proof of its first deposit
+	prefix := &merkle_tree.DepositTree{}
+	for _, leaf := range firstLeaves {
+		require.NoError(t, prefix.Push(leaf))
+	}
+	// 3 = 0b11, left siblings are at heights 1 and 0
+	finalized := prefix.Finalized()
+	second, _ = testDeposits(t, 3, 6, func(h int) libcommon.Hash {
+		if h <= 1 {
+			return finalized[1-h]
+		}
+		return garbage(h)
+	})
+	tree, err = extendDepositTree(nil, 3, second)
+	require.NoError(t, err)
+	require.Equal(t, expected.Root(), tree.Root())
+}
+
+func TestRestoreDepositTree(t *testing.T) {
+	db := memdb.NewTestDB(t)
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	tree, err := restoreDepositTree(tx, 0)
+	require.NoError(t, err)
+	require.Zero(t, tree.Count())
+	tree, err = restoreDepositTree(tx, 2)
+	require.NoError(t, err)
+	require.Nil(t, tree)
+
+	snapshot := &merkle_tree.DepositTree{}
+	require.NoError(t, snapshot.Push(libcommon.Hash{1}))
+	require.NoError(t, snapshot.Push(libcommon.Hash{2}))
+	require.NoError(t, state_accessors.WriteDepositTreeSnapshot(tx, snapshot, libcommon.Hash{3}))
+
+	tree, err = restoreDepositTree(tx, 2)
+	require.NoError(t, err)
+	require.Equal(t, snapshot.Root(), tree.Root())
+	// the snapshot does not hold all the deposits processed by the state
+	tree, err = restoreDepositTree(tx, 3)
+	require.NoError(t, err)
+	require.Nil(t, tree)
+}
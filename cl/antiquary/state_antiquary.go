@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -34,6 +35,7 @@ import (
 	"github.com/erigontech/erigon/cl/clparams/initial_state"
 	"github.com/erigontech/erigon/cl/cltypes"
 	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/merkle_tree"
 	"github.com/erigontech/erigon/cl/persistence/base_encoding"
 	"github.com/erigontech/erigon/cl/persistence/beacon_indicies"
 	state_accessors "github.com/erigontech/erigon/cl/persistence/state"
@@ -162,7 +164,7 @@ func (s *Antiquary) IncrementBeaconState(ctx context.Context, to uint64) error {
 	// Use this as the event slot (it will be incremented by 1 each time we process a block)
 	slot := s.currentState.Slot() + 1
 
-	var prevValSet []byte
+	var prevValSet, prevPreviousParticipation, prevCurrentParticipation []byte
 	events := state_accessors.NewStateEvents()
 	slashingOccured := false
 	// setup the events handler for historical states replay.
@@ -295,6 +297,12 @@ func (s *Antiquary) IncrementBeaconState(ctx context.Context, to uint64) error {
 		// We now compute the difference between the two balances.
 		prevValSet = prevValSet[:0]
 		prevValSet = append(prevValSet, s.currentState.RawValidatorSet()...)
+		prevPreviousParticipation = append(prevPreviousParticipation[:0], s.currentState.RawPreviousEpochParticipation()...)
+		prevCurrentParticipation = append(prevCurrentParticipation[:0], s.currentState.RawCurrentEpochParticipation()...)
+
+		if s.depositTree, err = extendDepositTree(s.depositTree, s.currentState.Eth1DepositIndex(), block.Block.Body.Deposits); err != nil {
+			return err
+		}
 
 		fullValidation := slot%1000 == 0 || first
 		blockRewardsCollector := &eth2.BlockRewardsCollector{}
@@ -325,6 +333,12 @@ func (s *Antiquary) IncrementBeaconState(ctx context.Context, to uint64) error {
 		}
 		events.Reset()
 
+		// the participation diff is taken against the participation rotated to the new epoch, so that it only holds what the block changed.
+		oldPrevious, oldCurrent := state_accessors.RotateEpochParticipation(prevPreviousParticipation, prevCurrentParticipation, state.Epoch(s.currentState)-prevEpoch)
+		if err := stateAntiquaryCollector.collectParticipationDiff(slot, oldPrevious, oldCurrent, s.currentState.RawPreviousEpochParticipation(), s.currentState.RawCurrentEpochParticipation()); err != nil {
+			return err
+		}
+
 		if isDumpSlot {
 			if err := stateAntiquaryCollector.collectBalancesDump(slot, s.currentState.RawBalances()); err != nil {
 				return err
@@ -413,9 +427,104 @@ func (s *Antiquary) IncrementBeaconState(ctx context.Context, to uint64) error {
 		return err
 	}
 	log.Info("Historical states antiquated", "slot", s.currentState.Slot(), "root", libcommon.Hash(stateRoot), "latency", endTime)
+	// Once we caught up with finality, keep the finalized state around so it can be served to checkpoint-syncing nodes.
+	if slot >= to && to > s.finalizedStateSlot {
+		if err := s.dumpFinalizedState(ctx, to); err != nil {
+			s.logger.Warn("Failed to write finalized state on disk", "err", err)
+		}
+	}
 	return nil
 }
 
+// dumpFinalizedState writes the post-state of the finalized checkpoint block on disk, along with the EIP-4881 snapshot of
+// its deposit tree. the state antiquary stops right before the finalized slot, so the finalized block (if any) is applied
+// on a copy of the current state.
+func (s *Antiquary) dumpFinalizedState(ctx context.Context, finalizedSlot uint64) error {
+	tx, err := s.mainDB.BeginRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	block, err := s.snReader.ReadBlockBySlot(ctx, tx, finalizedSlot)
+	if err != nil {
+		return err
+	}
+	tx.Rollback()
+
+	finalizedState := s.currentState
+	depositTree := s.depositTree
+	if block != nil {
+		if finalizedState, err = s.currentState.Copy(); err != nil {
+			return err
+		}
+		if depositTree != nil {
+			depositTree = depositTree.Copy()
+		}
+		if depositTree, err = extendDepositTree(depositTree, finalizedState.Eth1DepositIndex(), block.Block.Body.Deposits); err != nil {
+			return err
+		}
+		if err := transition.TransitionState(finalizedState, block, nil, false); err != nil {
+			return err
+		}
+	}
+	blockRoot, err := finalizedState.BlockRoot()
+	if err != nil {
+		return err
+	}
+	if err := state_accessors.WriteCheckpointState(filepath.Join(s.dirs.CaplinLatest, clparams.FinalizedStateFileName), blockRoot, finalizedState); err != nil {
+		return err
+	}
+	s.finalizedStateSlot = finalizedSlot
+
+	// the finalized eth1 data commits to the deposit contract at its block hash: only snapshot the tree if it holds
+	// exactly those deposits.
+	eth1Data := finalizedState.Eth1Data()
+	if depositTree == nil || depositTree.Count() != eth1Data.DepositCount || depositTree.Root() != eth1Data.Root {
+		return nil
+	}
+	rwTx, err := s.mainDB.BeginRw(ctx)
+	if err != nil {
+		return err
+	}
+	defer rwTx.Rollback()
+	if err := state_accessors.WriteDepositTreeSnapshot(rwTx, depositTree, eth1Data.BlockHash); err != nil {
+		return err
+	}
+	return rwTx.Commit()
+}
+
+// extendDepositTree appends the deposits of a block to the deposit tree of the chain, which holds eth1DepositIndex deposits.
+// if the deposits preceding the block are not known yet, the tree is started from the proof of the first deposit: its
+// left siblings are the complete subtrees preceding it.
+func extendDepositTree(tree *merkle_tree.DepositTree, eth1DepositIndex uint64, deposits *solid.ListSSZ[*cltypes.Deposit]) (*merkle_tree.DepositTree, error) {
+	if deposits == nil || deposits.Len() == 0 {
+		return tree, nil
+	}
+	if tree == nil || tree.Count() != eth1DepositIndex {
+		proof := make([]common.Hash, 0, merkle_tree.DepositContractDepth)
+		deposits.Get(0).Proof.Range(func(_ int, h common.Hash, _ int) bool {
+			proof = append(proof, h)
+			return len(proof) < merkle_tree.DepositContractDepth
+		})
+		var err error
+		if tree, err = merkle_tree.NewDepositTreeFromProof(eth1DepositIndex, proof); err != nil {
+			return nil, err
+		}
+	}
+	err := solid.RangeErr[*cltypes.Deposit](deposits, func(_ int, deposit *cltypes.Deposit, _ int) error {
+		leaf, err := deposit.Data.HashSSZ()
+		if err != nil {
+			return err
+		}
+		return tree.Push(leaf)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
 func (s *Antiquary) antiquateField(ctx context.Context, slot uint64, uncompressed []byte, buffer *bytes.Buffer, compressor *zstd.Encoder, collector *etl.Collector) error {
 	buffer.Reset()
 	compressor.Reset(buffer)
@@ -490,9 +599,24 @@ func (s *Antiquary) initializeStateAntiquaryIfNeeded(ctx context.Context, tx kv.
 
 	s.balances32 = s.balances32[:0]
 	s.balances32 = append(s.balances32, s.currentState.RawBalances()...)
+	if s.depositTree, err = restoreDepositTree(tx, s.currentState.Eth1DepositIndex()); err != nil {
+		return err
+	}
 	return s.currentState.InitBeaconState()
 }
 
+// restoreDepositTree returns the deposit tree holding the first eth1DepositIndex deposits, if a finalized snapshot of it was stored.
+func restoreDepositTree(tx kv.Tx, eth1DepositIndex uint64) (*merkle_tree.DepositTree, error) {
+	if eth1DepositIndex == 0 {
+		return &merkle_tree.DepositTree{}, nil
+	}
+	tree, _, err := state_accessors.ReadLatestDepositTreeSnapshot(tx)
+	if err != nil || tree == nil || tree.Count() != eth1DepositIndex {
+		return nil, err
+	}
+	return tree, nil
+}
+
 func computeSlotToBeRequested(tx kv.Tx, cfg *clparams.BeaconChainConfig, genesisSlot uint64, targetSlot uint64, backoffStep uint64) (uint64, error) {
 	// We want to backoff by some slots until we get a correct state from DB.
 	// we start from 2 * clparams.SlotsPerDump.
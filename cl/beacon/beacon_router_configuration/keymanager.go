@@ -0,0 +1,33 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package beacon_router_configuration
+
+import "time"
+
+// KeymanagerConfiguration - keymanager API (https://ethereum.github.io/keymanager-APIs) of the in-process
+// validator client. It is served apart from the beacon API and every request must carry the bearer token.
+type KeymanagerConfiguration struct {
+	Active   bool
+	Protocol string
+	Address  string
+	// TokenFile - file with the bearer token, a random one is written there if it doesn't exist
+	TokenFile string
+
+	ReadTimeTimeout time.Duration
+	IdleTimeout     time.Duration
+	WriteTimeout    time.Duration
+}
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"reflect"
 	"slices"
@@ -84,6 +85,11 @@ func (e *EndpointError) WriteTo(w http.ResponseWriter) {
 	}
 }
 
+// SSZStreamer is implemented by responses too large to be encoded in memory, such as full beacon states.
+type SSZStreamer interface {
+	StreamSSZ(w io.Writer) error
+}
+
 type EndpointHandler[T any] interface {
 	Handle(w http.ResponseWriter, r *http.Request) (T, error)
 }
@@ -131,6 +137,21 @@ func HandleEndpoint[T any](h EndpointHandler[T]) http.HandlerFunc {
 				w.WriteHeader(200)
 			}
 		case strings.Contains(contentType, "application/octet-stream"):
+			if resp, ok := any(ans).(*BeaconResponse); ok && resp.Version != nil {
+				w.Header().Set("Eth-Consensus-Version", resp.Version.String())
+			}
+			if streamer, ok := any(ans).(SSZStreamer); ok {
+				sw := &sszStreamWriter{w: w}
+				if err := streamer.StreamSSZ(sw); err != nil {
+					if sw.written {
+						// the status code is already out, all we can do is to cut the response short.
+						log.Warn("beaconapi failed to stream ssz", "type", reflect.TypeOf(ans), "err", err)
+						return
+					}
+					WrapEndpointError(err).WriteTo(w)
+				}
+				return
+			}
 			sszMarshaler, ok := any(ans).(ssz.Marshaler)
 			if !ok {
 				NewEndpointError(http.StatusBadRequest, ErrorSszNotSupported).WriteTo(w)
@@ -152,6 +173,21 @@ func HandleEndpoint[T any](h EndpointHandler[T]) http.HandlerFunc {
 	})
 }
 
+// sszStreamWriter only commits to an octet-stream response on the first write, so that errors happening before
+// anything was streamed can still be reported to the client.
+type sszStreamWriter struct {
+	w       http.ResponseWriter
+	written bool
+}
+
+func (s *sszStreamWriter) Write(p []byte) (int, error) {
+	if !s.written && len(p) > 0 {
+		s.w.Header().Set("Content-Type", "application/octet-stream")
+		s.written = true
+	}
+	return s.w.Write(p)
+}
+
 func isNil[T any](t T) bool {
 	v := reflect.ValueOf(t)
 	kind := v.Kind()
@@ -18,6 +18,7 @@ package beaconhttp
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 
 	"github.com/erigontech/erigon-lib/types/ssz"
@@ -112,3 +113,16 @@ func (b *BeaconResponse) EncodingSizeSSZ() int {
 	}
 	return marshaler.EncodingSizeSSZ()
 }
+
+// StreamSSZ writes the SSZ encoding of the data to w, without buffering it when the data supports streaming.
+func (b *BeaconResponse) StreamSSZ(w io.Writer) error {
+	if streamer, ok := b.Data.(SSZStreamer); ok {
+		return streamer.StreamSSZ(w)
+	}
+	encoded, err := b.EncodeSSZ(nil)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(encoded)
+	return err
+}
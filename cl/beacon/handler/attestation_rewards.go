@@ -30,7 +30,7 @@ import (
 	"github.com/erigontech/erigon/cl/cltypes/solid"
 	"github.com/erigontech/erigon/cl/persistence/beacon_indicies"
 	state_accessors "github.com/erigontech/erigon/cl/persistence/state"
-	"github.com/erigontech/erigon/cl/transition/impl/eth2/statechange"
+	"github.com/erigontech/erigon/cl/phase1/core/state"
 	"github.com/erigontech/erigon/cl/utils"
 )
 
@@ -245,35 +245,7 @@ func (a *ApiHandler) baseReward(version clparams.StateVersion, effectiveBalance,
 }
 
 func (a *ApiHandler) computeAttestationsRewardsForAltair(validatorSet *solid.ValidatorSet, inactivityScores solid.Uint64ListSSZ, previousParticipation *solid.ParticipationBitList, inactivityLeak bool, filterIndicies []uint64, epoch uint64) (*beaconhttp.BeaconResponse, error) {
-	totalActiveBalance := uint64(0)
-	prevEpoch := uint64(0)
-	if epoch > 0 {
-		prevEpoch = epoch - 1
-	}
-	flagsUnslashedIndiciesSet := statechange.GetUnslashedIndiciesSet(a.beaconChainCfg, prevEpoch, validatorSet, previousParticipation)
-	weights := a.beaconChainCfg.ParticipationWeights()
-	flagsTotalBalances := make([]uint64, len(weights))
-
-	validatorSet.Range(func(validatorIndex int, v solid.Validator, l int) bool {
-		if v.Active(epoch) {
-			totalActiveBalance += v.EffectiveBalance()
-		}
-
-		for i := range weights {
-			if flagsUnslashedIndiciesSet[i][validatorIndex] {
-				flagsTotalBalances[i] += v.EffectiveBalance()
-			}
-		}
-		return true
-	})
-	version := a.beaconChainCfg.GetCurrentStateVersion(epoch)
-	inactivityPenaltyDenominator := a.beaconChainCfg.InactivityScoreBias * a.beaconChainCfg.GetPenaltyQuotient(version)
-	rewardMultipliers := make([]uint64, len(weights))
-	for i := range weights {
-		rewardMultipliers[i] = weights[i] * (flagsTotalBalances[i] / a.beaconChainCfg.EffectiveBalanceIncrement)
-	}
-
-	rewardDenominator := (totalActiveBalance / a.beaconChainCfg.EffectiveBalanceIncrement) * a.beaconChainCfg.WeightDenominator
+	calculator := state.NewAttestationRewardsCalculator(a.beaconChainCfg, validatorSet, inactivityScores, previousParticipation, inactivityLeak, epoch)
 	var response *attestationsRewardsResponse
 	if len(filterIndicies) > 0 {
 		response = &attestationsRewardsResponse{
@@ -286,66 +258,31 @@ func (a *ApiHandler) computeAttestationsRewardsForAltair(validatorSet *solid.Val
 			TotalRewards: make([]TotalReward, 0, validatorSet.Length()),
 		}
 	}
-	// make a map with the filter indicies
-	totalActiveBalanceSqrt := utils.IntegerSquareRoot(totalActiveBalance)
 
-	fn := func(index uint64, v solid.Validator) error {
-		effectiveBalance := v.EffectiveBalance()
-		baseReward := a.baseReward(version, effectiveBalance, totalActiveBalanceSqrt)
-		// not eligible for rewards? then all empty
-		if !(v.Active(prevEpoch) || (v.Slashed() && prevEpoch+1 < v.WithdrawableEpoch())) {
-			response.IdealRewards = append(response.IdealRewards, IdealReward{EffectiveBalance: int64(effectiveBalance)})
-			response.TotalRewards = append(response.TotalRewards, TotalReward{ValidatorIndex: int64(index)})
-			return nil
-		}
-		idealReward := IdealReward{EffectiveBalance: int64(effectiveBalance)}
-		totalReward := TotalReward{ValidatorIndex: int64(index)}
-		if !inactivityLeak {
-			idealReward.Head = int64(baseReward * rewardMultipliers[a.beaconChainCfg.TimelyHeadFlagIndex] / rewardDenominator)
-			idealReward.Target = int64(baseReward * rewardMultipliers[a.beaconChainCfg.TimelyTargetFlagIndex] / rewardDenominator)
-			idealReward.Source = int64(baseReward * rewardMultipliers[a.beaconChainCfg.TimelySourceFlagIndex] / rewardDenominator)
-		}
-		// Note: for altair, we don't have the inclusion delay, always 0.
-		for flagIdx := range weights {
-			if flagsUnslashedIndiciesSet[flagIdx][index] {
-				if flagIdx == int(a.beaconChainCfg.TimelyHeadFlagIndex) {
-					totalReward.Head = idealReward.Head
-				} else if flagIdx == int(a.beaconChainCfg.TimelyTargetFlagIndex) {
-					totalReward.Target = idealReward.Target
-				} else if flagIdx == int(a.beaconChainCfg.TimelySourceFlagIndex) {
-					totalReward.Source = idealReward.Source
-				}
-			} else if flagIdx != int(a.beaconChainCfg.TimelyHeadFlagIndex) {
-				down := -int64(baseReward * weights[flagIdx] / a.beaconChainCfg.WeightDenominator)
-				if flagIdx == int(a.beaconChainCfg.TimelyHeadFlagIndex) {
-					totalReward.Head = down
-				} else if flagIdx == int(a.beaconChainCfg.TimelyTargetFlagIndex) {
-					totalReward.Target = down
-				} else if flagIdx == int(a.beaconChainCfg.TimelySourceFlagIndex) {
-					totalReward.Source = down
-				}
-			}
-		}
-		if !flagsUnslashedIndiciesSet[a.beaconChainCfg.TimelyTargetFlagIndex][index] {
-			inactivityScore := inactivityScores.Get(int(index))
-			totalReward.Inactivity = -int64((effectiveBalance * inactivityScore) / inactivityPenaltyDenominator)
-		}
-		response.IdealRewards = append(response.IdealRewards, idealReward)
-		response.TotalRewards = append(response.TotalRewards, totalReward)
-		return nil
+	fn := func(index uint64) {
+		rewards := calculator.Compute(index)
+		response.IdealRewards = append(response.IdealRewards, IdealReward{
+			EffectiveBalance: int64(rewards.EffectiveBalance),
+			Head:             rewards.IdealHead,
+			Target:           rewards.IdealTarget,
+			Source:           rewards.IdealSource,
+		})
+		response.TotalRewards = append(response.TotalRewards, TotalReward{
+			ValidatorIndex: int64(index),
+			Head:           rewards.Head,
+			Target:         rewards.Target,
+			Source:         rewards.Source,
+			Inactivity:     rewards.Inactivity,
+		})
 	}
 
 	if len(filterIndicies) > 0 {
 		for _, index := range filterIndicies {
-			if err := fn(index, validatorSet.Get(int(index))); err != nil {
-				return nil, err
-			}
+			fn(index)
 		}
 	} else {
 		for index := uint64(0); index < uint64(validatorSet.Length()); index++ {
-			if err := fn(index, validatorSet.Get(int(index))); err != nil {
-				return nil, err
-			}
+			fn(index)
 		}
 	}
 	return newBeaconResponse(response), nil
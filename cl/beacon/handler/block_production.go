@@ -430,6 +430,13 @@ func (a *ApiHandler) getBuilderPayload(
 	}
 	if ethHeader := header.Data.Message.Header; ethHeader != nil {
 		ethHeader.SetVersion(baseState.Version())
+		// the builder must move gas limit towards the one the proposer registered with
+		if preferred, ok := a.validatorParams.GetGasLimit(proposerIndex); ok {
+			parentGasLimit := baseBlock.Body.ExecutionPayload.GasLimit
+			if expected := expectedGasLimit(parentGasLimit, preferred); ethHeader.GasLimit != expected {
+				return nil, fmt.Errorf("builder gas limit %d, expected %d (parent %d, preferred %d)", ethHeader.GasLimit, expected, parentGasLimit, preferred)
+			}
+		}
 	}
 	// check kzg commitments
 	if header != nil && baseState.Version() >= clparams.DenebVersion {
@@ -450,6 +457,23 @@ func (a *ApiHandler) getBuilderPayload(
 	return header, nil
 }
 
+// expectedGasLimit - gas limit of the child block which goes from parent's one towards preferred as far as
+// the execution layer allows (by less than parent/1024 per block)
+func expectedGasLimit(parentGasLimit, preferred uint64) uint64 {
+	maxDelta := parentGasLimit/1024 - 1
+	if parentGasLimit < 1024 {
+		maxDelta = 0
+	}
+	switch {
+	case preferred > parentGasLimit:
+		return min(preferred, parentGasLimit+maxDelta)
+	case preferred < parentGasLimit:
+		return max(preferred, parentGasLimit-maxDelta)
+	default:
+		return parentGasLimit
+	}
+}
+
 func (a *ApiHandler) produceBeaconBody(
 	ctx context.Context,
 	apiVersion int,
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 
 	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/log/v3"
@@ -101,8 +102,20 @@ func (a *ApiHandler) PostEthV1BuilderRegisterValidator(w http.ResponseWriter, r
 	if err := a.builderClient.RegisterValidator(r.Context(), registerReq); err != nil {
 		return nil, beaconhttp.NewEndpointError(http.StatusInternalServerError, err)
 	}
+	headState := a.syncedData.HeadState()
 	for _, v := range registerReq {
 		a.logger.Debug("[Caplin] Registered new validator", "fee_recipient", v.Message.FeeRecipient)
+		if headState == nil {
+			continue
+		}
+		// remember preferred gas limit to check builder bids against it
+		gasLimit, err := strconv.ParseUint(v.Message.GasLimit, 10, 64)
+		if err != nil {
+			continue
+		}
+		if index, ok := headState.ValidatorIndexByPubkey(v.Message.PubKey); ok {
+			a.validatorParams.SetGasLimit(index, gasLimit)
+		}
 	}
 	log.Info("Registered new validator", "count", len(registerReq))
 	return newBeaconResponse(nil), nil
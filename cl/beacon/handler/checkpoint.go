@@ -0,0 +1,134 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/cl/beacon/beaconhttp"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/persistence/beacon_indicies"
+	state_accessors "github.com/erigontech/erigon/cl/persistence/state"
+)
+
+// checkpointStateStream streams the finalized state written on disk by the state antiquary.
+type checkpointStateStream struct {
+	reader *state_accessors.CheckpointStateReader
+}
+
+func (c *checkpointStateStream) StreamSSZ(w io.Writer) error {
+	defer c.reader.Close()
+	_, err := c.reader.WriteTo(w)
+	return err
+}
+
+// openCheckpointState opens the finalized state stored on disk if it is the post-state of blockRoot, it returns nil otherwise.
+func (a *ApiHandler) openCheckpointState(blockRoot libcommon.Hash) (*state_accessors.CheckpointStateReader, error) {
+	if a.dirs.CaplinLatest == "" {
+		return nil, nil
+	}
+	reader, err := state_accessors.OpenCheckpointState(filepath.Join(a.dirs.CaplinLatest, clparams.FinalizedStateFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if reader.Header.BlockRoot != blockRoot {
+		reader.Close()
+		return nil, nil
+	}
+	return reader, nil
+}
+
+func acceptsSSZ(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/octet-stream")
+}
+
+type weakSubjectivityResponse struct {
+	WsCheckpoint solid.Checkpoint `json:"ws_checkpoint"`
+	StateRoot    libcommon.Hash   `json:"state_root"`
+}
+
+func (a *ApiHandler) GetEthV1BeaconWeakSubjectivity(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
+	ctx := r.Context()
+	tx, err := a.indiciesDB.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	finalized := a.forkchoiceStore.FinalizedCheckpoint()
+	stateRoot, err := beacon_indicies.ReadStateRootByBlockRoot(ctx, tx, finalized.Root)
+	if err != nil {
+		return nil, err
+	}
+	if stateRoot == (libcommon.Hash{}) {
+		return nil, beaconhttp.NewEndpointError(http.StatusNotFound, fmt.Errorf("could not read state root of finalized block: %x", finalized.Root))
+	}
+	return newBeaconResponse(&weakSubjectivityResponse{
+		WsCheckpoint: finalized,
+		StateRoot:    stateRoot,
+	}), nil
+}
+
+func (a *ApiHandler) GetEthV1BeaconDepositSnapshot(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
+	ctx := r.Context()
+	tx, err := a.indiciesDB.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	tree, executionBlockHash, err := state_accessors.ReadLatestDepositTreeSnapshot(tx)
+	if err != nil {
+		return nil, err
+	}
+	tx.Rollback()
+	if tree == nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusNotFound, errors.New("no finalized deposit tree snapshot available, is the node running with historical states?"))
+	}
+	if a.engine == nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusServiceUnavailable, errors.New("execution engine is not available"))
+	}
+	executionBlockHeight, err := a.engine.HeaderNumber(ctx, executionBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	if executionBlockHeight == nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusNotFound, fmt.Errorf("execution block not found: %x", executionBlockHash))
+	}
+
+	snapshot := cltypes.NewDepositTreeSnapshot()
+	for _, root := range tree.Finalized() {
+		snapshot.Finalized.Append(root)
+	}
+	snapshot.DepositRoot = tree.Root()
+	snapshot.DepositCount = tree.Count()
+	snapshot.ExecutionBlockHash = executionBlockHash
+	snapshot.ExecutionBlockHeight = *executionBlockHeight
+	return newBeaconResponse(snapshot).WithFinalized(true), nil
+}
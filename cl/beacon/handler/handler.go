@@ -23,6 +23,7 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
 	sentinel "github.com/erigontech/erigon-lib/gointerfaces/sentinelproto"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/log/v3"
@@ -43,6 +44,7 @@ import (
 	"github.com/erigontech/erigon/cl/phase1/forkchoice"
 	"github.com/erigontech/erigon/cl/phase1/network/services"
 	"github.com/erigontech/erigon/cl/pool"
+	"github.com/erigontech/erigon/cl/sentinel/peers"
 	"github.com/erigontech/erigon/cl/utils/eth_clock"
 	"github.com/erigontech/erigon/cl/validator/attestation_producer"
 	"github.com/erigontech/erigon/cl/validator/committee_subscription"
@@ -73,8 +75,10 @@ type ApiHandler struct {
 	syncedData      synced_data.SyncedData
 	stateReader     *historical_states_reader.HistoricalStatesReader
 	sentinel        sentinel.SentinelClient
+	sentinelPeers   *peers.Pool
 	blobStoage      blob_storage.BlobStorage
 	caplinSnapshots *freezeblocks.CaplinSnapshots
+	dirs            datadir.Dirs
 
 	version string // Node's version
 
@@ -121,11 +125,13 @@ func NewApiHandler(
 	syncedData synced_data.SyncedData,
 	stateReader *historical_states_reader.HistoricalStatesReader,
 	sentinel sentinel.SentinelClient,
+	sentinelPeers *peers.Pool,
 	version string,
 	routerCfg *beacon_router_configuration.RouterConfiguration,
 	emitters *beaconevents.EventEmitter,
 	blobStoage blob_storage.BlobStorage,
 	caplinSnapshots *freezeblocks.CaplinSnapshots,
+	dirs datadir.Dirs,
 	validatorParams *validator_params.ValidatorParams,
 	attestationProducer attestation_producer.AttestationDataProducer,
 	engine execution_client.ExecutionEngine,
@@ -163,11 +169,13 @@ func NewApiHandler(
 			return solid.NewHashVector(int(beaconChainConfig.EpochsPerHistoricalVector))
 		}},
 		sentinel:                         sentinel,
+		sentinelPeers:                    sentinelPeers,
 		version:                          version,
 		routerCfg:                        routerCfg,
 		emitters:                         emitters,
 		blobStoage:                       blobStoage,
 		caplinSnapshots:                  caplinSnapshots,
+		dirs:                             dirs,
 		attestationProducer:              attestationProducer,
 		blobBundles:                      blobBundles,
 		engine:                           engine,
@@ -203,6 +211,11 @@ func (a *ApiHandler) init() {
 			r.Get("/validator_inclusion/{epoch}/{validator_id}", beaconhttp.HandleEndpointFunc(a.GetLighthouseValidatorInclusion))
 		})
 	}
+	if a.routerCfg.Validator {
+		r.Route("/caplin", func(r chi.Router) {
+			r.Get("/v1/validator_performance", beaconhttp.HandleEndpointFunc(a.GetCaplinV1ValidatorPerformance))
+		})
+	}
 	r.Route("/eth", func(r chi.Router) {
 		r.Route("/v1", func(r chi.Router) {
 			if a.routerCfg.Builder {
@@ -225,6 +238,7 @@ func (a *ApiHandler) init() {
 
 			if a.routerCfg.Debug {
 				r.Get("/debug/fork_choice", a.GetEthV1DebugBeaconForkChoice)
+				r.Get("/debug/beacon/states/{slot}/diff", beaconhttp.HandleEndpointFunc(a.getStateDiff))
 			}
 			if a.routerCfg.Config {
 				r.Route("/config", func(r chi.Router) {
@@ -254,6 +268,8 @@ func (a *ApiHandler) init() {
 						r.Get("/{block_id}/root", beaconhttp.HandleEndpointFunc(a.GetEthV1BeaconBlockRoot))
 					})
 					r.Get("/genesis", beaconhttp.HandleEndpointFunc(a.GetEthV1BeaconGenesis))
+					r.Get("/deposit_snapshot", beaconhttp.HandleEndpointFunc(a.GetEthV1BeaconDepositSnapshot))
+					r.Get("/weak_subjectivity", beaconhttp.HandleEndpointFunc(a.GetEthV1BeaconWeakSubjectivity))
 					r.Get("/blinded_blocks/{block_id}", beaconhttp.HandleEndpointFunc(a.GetEthV1BlindedBlock))
 					r.Route("/pool", func(r chi.Router) {
 						r.Get("/voluntary_exits", beaconhttp.HandleEndpointFunc(a.GetEthV1BeaconPoolVoluntaryExits))
@@ -288,6 +304,9 @@ func (a *ApiHandler) init() {
 							r.Get("/validator_balances", a.GetEthV1BeaconValidatorsBalances)
 							r.Post("/validator_balances", a.PostEthV1BeaconValidatorsBalances)
 							r.Get("/validators/{validator_id}", beaconhttp.HandleEndpointFunc(a.GetEthV1BeaconStatesValidator))
+							r.Get("/pending_deposits", beaconhttp.HandleEndpointFunc(a.GetEthV1BeaconStatesPendingDeposits))
+							r.Get("/pending_partial_withdrawals", beaconhttp.HandleEndpointFunc(a.GetEthV1BeaconStatesPendingPartialWithdrawals))
+							r.Get("/pending_consolidations", beaconhttp.HandleEndpointFunc(a.GetEthV1BeaconStatesPendingConsolidations))
 						})
 					})
 				})
@@ -332,11 +351,17 @@ func (a *ApiHandler) init() {
 					if a.routerCfg.Builder {
 						r.Post("/blinded_blocks", beaconhttp.HandleEndpointFunc(a.PostEthV2BlindedBlocks))
 					}
+					r.Route("/pool", func(r chi.Router) {
+						r.Get("/attestations", beaconhttp.HandleEndpointFunc(a.GetEthV2BeaconPoolAttestations))
+						r.Post("/attestations", a.PostEthV2BeaconPoolAttestations)
+					})
 				})
 			}
 			if a.routerCfg.Validator {
 				r.Route("/validator", func(r chi.Router) {
 					r.Get("/blocks/{slot}", beaconhttp.HandleEndpointFunc(a.GetEthV3ValidatorBlock)) // deprecate
+					r.Get("/aggregate_attestation", beaconhttp.HandleEndpointFunc(a.GetEthV2ValidatorAggregateAttestation))
+					r.Post("/aggregate_and_proofs", a.PostEthV2ValidatorAggregatesAndProof)
 				})
 			}
 		})
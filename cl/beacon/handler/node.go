@@ -23,8 +23,11 @@ import (
 	"runtime"
 	"strconv"
 
+	libp2ppeer "github.com/libp2p/go-libp2p/core/peer"
+
 	sentinel "github.com/erigontech/erigon-lib/gointerfaces/sentinelproto"
 	"github.com/erigontech/erigon/cl/beacon/beaconhttp"
+	"github.com/erigontech/erigon/cl/sentinel/peers"
 )
 
 /*
@@ -41,6 +44,8 @@ type peer struct {
 	LastSeenP2PAddress string `json:"last_seen_p2p_address"`
 	Direction          string `json:"direction"`
 	AgentVersion       string `json:"agent_version"`
+	// GossipScore is the gossipsub score of the peer, nil until it is computed.
+	GossipScore *peers.GossipScore `json:"gossip_score,omitempty"`
 }
 
 func (a *ApiHandler) GetEthV1NodeHealth(w http.ResponseWriter, r *http.Request) {
@@ -119,6 +124,7 @@ func (a *ApiHandler) GetEthV1NodePeersInfos(w http.ResponseWriter, r *http.Reque
 			LastSeenP2PAddress: ret.Peers[i].Address,
 			Direction:          ret.Peers[i].Direction,
 			AgentVersion:       ret.Peers[i].AgentVersion,
+			GossipScore:        a.peerGossipScore(ret.Peers[i].Pid),
 		})
 	}
 	if err := json.NewEncoder(w).Encode(map[string]interface{}{
@@ -150,6 +156,7 @@ func (a *ApiHandler) GetEthV1NodePeerInfos(w http.ResponseWriter, r *http.Reques
 					LastSeenP2PAddress: p.Address,
 					Direction:          p.Direction,
 					AgentVersion:       p.AgentVersion,
+					GossipScore:        a.peerGossipScore(p.Pid),
 				},
 			}); err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -198,3 +205,14 @@ func (a *ApiHandler) GetEthV1NodeSyncing(w http.ResponseWriter, r *http.Request)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
+
+func (a *ApiHandler) peerGossipScore(pid string) *peers.GossipScore {
+	if a.sentinelPeers == nil {
+		return nil
+	}
+	id, err := libp2ppeer.Decode(pid)
+	if err != nil {
+		return nil
+	}
+	return a.sentinelPeers.GossipScore(id)
+}
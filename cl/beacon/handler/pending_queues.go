@@ -0,0 +1,40 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/erigontech/erigon/cl/beacon/beaconhttp"
+)
+
+// errPendingQueuesNotTracked - the beacon state carries no electra pending queues until the electra state transition
+// lands: they are neither decoded nor upgraded into it, so they are not served (nor antiquated) instead of being served empty.
+var errPendingQueuesNotTracked = errors.New("electra pending queues are not tracked by the beacon state yet")
+
+func (a *ApiHandler) GetEthV1BeaconStatesPendingDeposits(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
+	return nil, beaconhttp.NewEndpointError(http.StatusNotImplemented, errPendingQueuesNotTracked)
+}
+
+func (a *ApiHandler) GetEthV1BeaconStatesPendingPartialWithdrawals(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
+	return nil, beaconhttp.NewEndpointError(http.StatusNotImplemented, errPendingQueuesNotTracked)
+}
+
+func (a *ApiHandler) GetEthV1BeaconStatesPendingConsolidations(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
+	return nil, beaconhttp.NewEndpointError(http.StatusNotImplemented, errPendingQueuesNotTracked)
+}
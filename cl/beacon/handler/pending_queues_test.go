@@ -0,0 +1,41 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cl/clparams"
+)
+
+func TestGetStatePendingQueuesNotImplemented(t *testing.T) {
+	_, _, _, _, _, handler, _, _, _, _ := setupTestingHandler(t, clparams.CapellaVersion, log.Root(), false)
+
+	server := httptest.NewServer(handler.mux)
+	defer server.Close()
+	for _, queue := range []string{"pending_deposits", "pending_partial_withdrawals", "pending_consolidations"} {
+		resp, err := http.Get(server.URL + "/eth/v1/beacon/states/head/" + queue)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusNotImplemented, resp.StatusCode, queue)
+	}
+}
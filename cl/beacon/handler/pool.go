@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 
 	sentinel "github.com/erigontech/erigon-lib/gointerfaces/sentinelproto"
@@ -62,7 +63,32 @@ func (a *ApiHandler) GetEthV1BeaconPoolAttestations(w http.ResponseWriter, r *ht
 	if slot == nil && committeeIndex == nil {
 		return newBeaconResponse(atts), nil
 	}
-	ret := make([]any, 0, len(atts))
+	return newBeaconResponse(a.filterPoolAttestations(atts, slot, committeeIndex, nil)), nil
+}
+
+// GetEthV2BeaconPoolAttestations is a handler for GET /eth/v2/beacon/pool/attestations.
+// unlike v1, it only returns attestations of a single fork, which is advertised in the Eth-Consensus-Version header.
+func (a *ApiHandler) GetEthV2BeaconPoolAttestations(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
+	slot, err := beaconhttp.Uint64FromQueryParams(r, "slot")
+	if err != nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, err)
+	}
+	committeeIndex, err := beaconhttp.Uint64FromQueryParams(r, "committee_index")
+	if err != nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, err)
+	}
+	epoch := a.ethClock.GetCurrentEpoch()
+	if slot != nil {
+		epoch = a.ethClock.GetEpochAtSlot(*slot)
+	}
+	version := a.beaconChainCfg.GetCurrentStateVersion(epoch)
+	atts := a.filterPoolAttestations(a.operationsPool.AttestationsPool.Raw(), slot, committeeIndex, &version)
+	return newBeaconResponse(atts).WithVersion(version), nil
+}
+
+// filterPoolAttestations filters the pooled attestations by slot and committee index, and optionally by fork.
+func (a *ApiHandler) filterPoolAttestations(atts []*solid.Attestation, slot, committeeIndex *uint64, version *clparams.StateVersion) []*solid.Attestation {
+	ret := make([]*solid.Attestation, 0, len(atts))
 	for i := range atts {
 		if slot != nil && atts[i].Data.Slot != *slot {
 			continue
@@ -80,10 +106,12 @@ func (a *ApiHandler) GetEthV1BeaconPoolAttestations(w http.ResponseWriter, r *ht
 		if committeeIndex != nil && cIndex != *committeeIndex {
 			continue
 		}
+		if version != nil && attVersion.AfterOrEqual(clparams.ElectraVersion) != version.AfterOrEqual(clparams.ElectraVersion) {
+			continue
+		}
 		ret = append(ret, atts[i])
 	}
-
-	return newBeaconResponse(ret), nil
+	return ret
 }
 
 func (a *ApiHandler) PostEthV1BeaconPoolAttestations(w http.ResponseWriter, r *http.Request) {
@@ -92,7 +120,32 @@ func (a *ApiHandler) PostEthV1BeaconPoolAttestations(w http.ResponseWriter, r *h
 		beaconhttp.NewEndpointError(http.StatusBadRequest, err).WriteTo(w)
 		return
 	}
+	a.submitPoolAttestations(w, r, req)
+}
 
+// PostEthV2BeaconPoolAttestations is a handler for POST /eth/v2/beacon/pool/attestations.
+// the Eth-Consensus-Version header is required and all the attestations must belong to that fork.
+func (a *ApiHandler) PostEthV2BeaconPoolAttestations(w http.ResponseWriter, r *http.Request) {
+	version, err := a.parseEthConsensusVersion(r.Header.Get("Eth-Consensus-Version"), 2)
+	if err != nil {
+		beaconhttp.NewEndpointError(http.StatusBadRequest, err).WriteTo(w)
+		return
+	}
+	req := []*solid.Attestation{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		beaconhttp.NewEndpointError(http.StatusBadRequest, err).WriteTo(w)
+		return
+	}
+	for i, attestation := range req {
+		if (attestation.CommitteeBits != nil) != version.AfterOrEqual(clparams.ElectraVersion) {
+			beaconhttp.NewEndpointError(http.StatusBadRequest, fmt.Errorf("attestation %d does not match consensus version %s", i, clparams.ClVersionToString(version))).WriteTo(w)
+			return
+		}
+	}
+	a.submitPoolAttestations(w, r, req)
+}
+
+func (a *ApiHandler) submitPoolAttestations(w http.ResponseWriter, r *http.Request, req []*solid.Attestation) {
 	headState := a.syncedData.HeadState()
 	if headState == nil {
 		beaconhttp.NewEndpointError(http.StatusServiceUnavailable, errors.New("head state not available")).WriteTo(w)
@@ -303,9 +356,38 @@ func (a *ApiHandler) PostEthV1ValidatorAggregatesAndProof(w http.ResponseWriter,
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	a.submitAggregatesAndProofs(w, r, req)
+}
+
+// PostEthV2ValidatorAggregatesAndProof is a handler for POST /eth/v2/validator/aggregate_and_proofs.
+// the Eth-Consensus-Version header is required and all the aggregates must belong to that fork.
+func (a *ApiHandler) PostEthV2ValidatorAggregatesAndProof(w http.ResponseWriter, r *http.Request) {
+	version, err := a.parseEthConsensusVersion(r.Header.Get("Eth-Consensus-Version"), 2)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := []*cltypes.SignedAggregateAndProof{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for i, v := range req {
+		if v.Message == nil || v.Message.Aggregate == nil {
+			http.Error(w, fmt.Sprintf("aggregate and proof %d is missing the aggregate", i), http.StatusBadRequest)
+			return
+		}
+		if (v.Message.Aggregate.CommitteeBits != nil) != version.AfterOrEqual(clparams.ElectraVersion) {
+			http.Error(w, fmt.Sprintf("aggregate and proof %d does not match consensus version %s", i, clparams.ClVersionToString(version)), http.StatusBadRequest)
+			return
+		}
+	}
+	a.submitAggregatesAndProofs(w, r, req)
+}
 
+func (a *ApiHandler) submitAggregatesAndProofs(w http.ResponseWriter, r *http.Request, req []*cltypes.SignedAggregateAndProof) {
 	failures := []poolingFailure{}
-	for _, v := range req {
+	for i, v := range req {
 		encodedSSZ, err := v.EncodeSSZ(nil)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -323,11 +405,17 @@ func (a *ApiHandler) PostEthV1ValidatorAggregatesAndProof(w http.ResponseWriter,
 			GossipData:              gossipData,
 			ImmediateProcess:        true, // we want to process aggregate and proof immediately
 		}); err != nil && !errors.Is(err, services.ErrIgnore) {
-			log.Warn("[Beacon REST] failed to process bls-change", "err", err)
-			failures = append(failures, poolingFailure{Index: len(failures), Message: err.Error()})
+			log.Warn("[Beacon REST] failed to process aggregate and proof", "err", err)
+			failures = append(failures, poolingFailure{Index: i, Message: err.Error()})
 			continue
 		}
 	}
+	if len(failures) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(poolingError{Code: http.StatusBadRequest, Message: "some failures", Failures: failures})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
 }
 
 // PostEthV1BeaconPoolSyncCommittees is a handler for POST /eth/v1/beacon/pool/sync_committees.
@@ -192,6 +192,16 @@ func (a *ApiHandler) getFullState(w http.ResponseWriter, r *http.Request) (*beac
 		return nil, beaconhttp.NewEndpointError(httpStatus, err)
 	}
 	isOptimistic := a.forkchoiceStore.IsRootOptimistic(blockRoot)
+	// Serve checkpoint-syncing nodes straight from disk, so the finalized state never needs to be loaded in memory.
+	if acceptsSSZ(r) {
+		checkpointState, err := a.openCheckpointState(blockRoot)
+		if err != nil {
+			return nil, err
+		}
+		if checkpointState != nil {
+			return newBeaconResponse(&checkpointStateStream{reader: checkpointState}).WithFinalized(true).WithVersion(checkpointState.Header.Version).WithOptimistic(isOptimistic), nil
+		}
+	}
 	state, err := a.forkchoiceStore.GetStateAtBlockRoot(blockRoot, true)
 	if err != nil {
 		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, err)
@@ -225,6 +235,46 @@ func (a *ApiHandler) getFullState(w http.ResponseWriter, r *http.Request) (*beac
 	return newBeaconResponse(state).WithFinalized(false).WithVersion(state.Version()).WithOptimistic(isOptimistic), nil
 }
 
+// getStateDiff serves the validators, balances and participation flags which changed between the historical states at
+// slots ?from= and {slot}, without reconstructing either state as a whole.
+func (a *ApiHandler) getStateDiff(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
+	ctx := r.Context()
+
+	slotStr, err := beaconhttp.StringFromRequest(r, "slot")
+	if err != nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, err)
+	}
+	slot, err := strconv.ParseUint(slotStr, 10, 64)
+	if err != nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, fmt.Errorf("invalid slot: %w", err))
+	}
+	from, err := beaconhttp.Uint64FromQueryParams(r, "from")
+	if err != nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, err)
+	}
+	if from == nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, errors.New("missing from slot"))
+	}
+	if *from > slot {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, fmt.Errorf("from slot %d is after slot %d", *from, slot))
+	}
+
+	tx, err := a.indiciesDB.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	diff, err := a.stateReader.ReadStateDiff(tx, *from, slot)
+	if err != nil {
+		return nil, err
+	}
+	if diff == nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusNotFound, fmt.Errorf("could not read states at slots %d and %d", *from, slot))
+	}
+	return newBeaconResponse(diff).WithFinalized(true), nil
+}
+
 type finalityCheckpointsResponse struct {
 	FinalizedCheckpoint         solid.Checkpoint `json:"finalized"`
 	CurrentJustifiedCheckpoint  solid.Checkpoint `json:"current_justified"`
@@ -157,6 +157,7 @@ func setupTestingHandler(t *testing.T, v clparams.StateVersion, logger log.Logge
 		syncedData,
 		statesReader,
 		nil,
+		nil,
 		"test-version", &beacon_router_configuration.RouterConfiguration{
 			Beacon:     true,
 			Node:       true,
@@ -166,7 +167,7 @@ func setupTestingHandler(t *testing.T, v clparams.StateVersion, logger log.Logge
 			Events:     true,
 			Validator:  true,
 			Lighthouse: true,
-		}, nil, blobStorage, nil, vp, nil, nil, fcu.SyncContributionPool, nil, nil,
+		}, nil, blobStorage, nil, datadir.New("/tmp"), vp, nil, nil, fcu.SyncContributionPool, nil, nil,
 		syncCommitteeMessagesService,
 		syncContributionService,
 		aggregateAndProofsService,
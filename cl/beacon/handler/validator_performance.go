@@ -0,0 +1,67 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/erigontech/erigon/cl/beacon/beaconhttp"
+	"github.com/erigontech/erigon/cl/monitor"
+)
+
+// GetCaplinV1ValidatorPerformance returns the per-epoch performance of the validators observed by the validator monitor.
+func (a *ApiHandler) GetCaplinV1ValidatorPerformance(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
+	strIdxs, err := beaconhttp.StringListFromQueryParams(r, "validator_ids")
+	if err != nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, err)
+	}
+	validators := make([]uint64, 0, len(strIdxs))
+	for _, idx := range strIdxs {
+		vid, err := strconv.ParseUint(idx, 10, 64)
+		if err != nil {
+			return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, fmt.Errorf("could not parse validator index: %w", err))
+		}
+		validators = append(validators, vid)
+	}
+
+	startEpoch, err := beaconhttp.Uint64FromQueryParams(r, "start_epoch")
+	if err != nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, err)
+	}
+	endEpoch, err := beaconhttp.Uint64FromQueryParams(r, "end_epoch")
+	if err != nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, err)
+	}
+	fromEpoch, toEpoch := uint64(0), a.ethClock.GetCurrentEpoch()
+	if startEpoch != nil {
+		fromEpoch = *startEpoch
+	}
+	if endEpoch != nil {
+		toEpoch = *endEpoch
+	}
+	if fromEpoch > toEpoch {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, fmt.Errorf("start epoch %d is after end epoch %d", fromEpoch, toEpoch))
+	}
+
+	performances := a.validatorsMonitor.GetValidatorPerformance(validators, fromEpoch, toEpoch)
+	if performances == nil {
+		performances = []monitor.ValidatorPerformance{}
+	}
+	return newBeaconResponse(performances), nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/monitor"
+	mockMonitor "github.com/erigontech/erigon/cl/monitor/mock_services"
+)
+
+func TestGetCaplinV1ValidatorPerformance(t *testing.T) {
+	_, _, _, _, _, handler, _, _, _, _ := setupTestingHandler(t, clparams.BellatrixVersion, log.Root(), false)
+	validatorMonitor := mockMonitor.NewMockValidatorMonitor(gomock.NewController(t))
+	handler.validatorsMonitor = validatorMonitor
+
+	validatorMonitor.EXPECT().GetValidatorPerformance([]uint64{1, 2}, uint64(3), uint64(4)).Return([]monitor.ValidatorPerformance{{
+		ValidatorIndex:      1,
+		Epoch:               3,
+		AttestationIncluded: true,
+		InclusionDelay:      1,
+		CorrectSource:       true,
+		SyncCommitteeHits:   2,
+		SyncCommitteeMisses: 1,
+		Rewards:             &monitor.AttestationRewards{Head: 10, Target: 20, Source: 30, Inactivity: -5},
+	}})
+	validatorMonitor.EXPECT().GetValidatorPerformance([]uint64{}, uint64(0), handler.ethClock.GetCurrentEpoch()).Return(nil)
+
+	server := httptest.NewServer(handler.mux)
+	defer server.Close()
+	get := func(query string) (int, string) {
+		resp, err := http.Get(server.URL + "/caplin/v1/validator_performance" + query)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	code, body := get("?validator_ids=1,2&start_epoch=3&end_epoch=4")
+	require.Equal(t, http.StatusOK, code)
+	require.JSONEq(t, `{"data":[{
+		"validator_index":"1","epoch":"3","attestation_included":true,"inclusion_delay":"1",
+		"correct_source":true,"correct_target":false,"correct_head":false,"proposals":"0","missed_proposals":"0",
+		"sync_committee_hits":"2","sync_committee_misses":"1",
+		"attestation_rewards":{"head":"10","target":"20","source":"30","inactivity":"-5"}
+	}]}`, body)
+
+	// no validators nor epochs: all the observed validators up to the current epoch
+	code, body = get("")
+	require.Equal(t, http.StatusOK, code)
+	require.JSONEq(t, `{"data":[]}`, body)
+
+	code, _ = get("?validator_ids=foo")
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = get("?start_epoch=5&end_epoch=4")
+	require.Equal(t, http.StatusBadRequest, code)
+}
@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
 	mockaggregation "github.com/erigontech/erigon/cl/aggregation/mock_services"
 	"github.com/erigontech/erigon/cl/beacon/beacon_router_configuration"
 	"github.com/erigontech/erigon/cl/cltypes/solid"
@@ -55,11 +56,13 @@ func (t *validatorTestSuite) SetupTest() {
 		nil,
 		nil,
 		nil,
+		nil,
 		"0",
 		&beacon_router_configuration.RouterConfiguration{Validator: true},
 		nil,
 		nil,
 		nil,
+		datadir.Dirs{},
 		nil,
 		nil,
 		nil,
@@ -35,6 +35,7 @@ import (
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon/cl/beacon/beaconhttp"
+	"github.com/erigontech/erigon/cl/clparams"
 	"github.com/erigontech/erigon/cl/cltypes/solid"
 	"github.com/erigontech/erigon/cl/persistence/beacon_indicies"
 	state_accessors "github.com/erigontech/erigon/cl/persistence/state"
@@ -799,3 +800,47 @@ func (a *ApiHandler) GetEthV1ValidatorAggregateAttestation(w http.ResponseWriter
 
 	return newBeaconResponse(att), nil
 }
+
+// GetEthV2ValidatorAggregateAttestation is a handler for GET /eth/v2/validator/aggregate_attestation.
+// since electra attestations of different committees share the same data root, the committee index is required.
+func (a *ApiHandler) GetEthV2ValidatorAggregateAttestation(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
+	attDataRoot := r.URL.Query().Get("attestation_data_root")
+	if attDataRoot == "" {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, errors.New("attestation_data_root is required"))
+	}
+	slot, err := beaconhttp.Uint64FromQueryParams(r, "slot")
+	if err != nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, errors.WithMessage(err, "invalid slot"))
+	}
+	if slot == nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, errors.New("slot is required"))
+	}
+	committeeIndex, err := beaconhttp.Uint64FromQueryParams(r, "committee_index")
+	if err != nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, errors.WithMessage(err, "invalid committee_index"))
+	}
+	if committeeIndex == nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, errors.New("committee_index is required"))
+	}
+
+	attDataRootHash := libcommon.HexToHash(attDataRoot)
+	version := a.beaconChainCfg.GetCurrentStateVersion(*slot / a.beaconChainCfg.SlotsPerEpoch)
+	var att *solid.Attestation
+	if version.AfterOrEqual(clparams.ElectraVersion) {
+		att = a.aggregatePool.GetAggregatationByRootAndCommittee(attDataRootHash, *committeeIndex)
+	} else {
+		att = a.aggregatePool.GetAggregatationByRoot(attDataRootHash)
+	}
+	if att == nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusNotFound, fmt.Errorf("attestation %s not found", attDataRoot))
+	}
+	if *slot != att.Data.Slot {
+		log.Debug("attestation slot does not match", "attestation_data_root", attDataRoot, "slot_inquire", *slot)
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, errors.New("attestation slot mismatch"))
+	}
+	if version.Before(clparams.ElectraVersion) && att.Data.CommitteeIndex != *committeeIndex {
+		return nil, beaconhttp.NewEndpointError(http.StatusNotFound, fmt.Errorf("attestation %s not found for committee %d", attDataRoot, *committeeIndex))
+	}
+
+	return newBeaconResponse(att).WithVersion(version), nil
+}
@@ -18,6 +18,7 @@ package beacon
 
 import (
 	"context"
+	"crypto/subtle"
 	"net"
 	"net/http"
 	"time"
@@ -83,3 +84,42 @@ func ListenAndServe(beaconHandler *LayeredBeaconHandler, routerCfg beacon_router
 	log.Info("[Beacon API] Listening", "addr", routerCfg.Address)
 	return nil
 }
+
+// ListenAndServeKeymanager - serves keymanager API of the validator client, requests must carry `Authorization: Bearer <token>`
+func ListenAndServeKeymanager(keymanagerHandler http.Handler, routerCfg beacon_router_configuration.KeymanagerConfiguration, token string) error {
+	listener, err := net.Listen(routerCfg.Protocol, routerCfg.Address)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	mux := chi.NewRouter()
+	mux.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth := r.Header.Get("Authorization")
+			if auth == "" {
+				http.Error(w, "missing bearer token", http.StatusUnauthorized)
+				return
+			}
+			if subtle.ConstantTimeCompare([]byte(auth), []byte("Bearer "+token)) != 1 {
+				log.Warn("[Keymanager API] Invalid bearer token", "path", r.URL.Path)
+				http.Error(w, "invalid bearer token", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	mux.Mount("/", keymanagerHandler)
+
+	server := &http.Server{
+		Handler:      mux,
+		ReadTimeout:  routerCfg.ReadTimeTimeout,
+		IdleTimeout:  routerCfg.IdleTimeout,
+		WriteTimeout: routerCfg.WriteTimeout,
+	}
+	log.Info("[Keymanager API] Listening", "addr", routerCfg.Address)
+	if err := server.Serve(listener); err != nil {
+		log.Warn("[Keymanager API] failed to start serving", "addr", routerCfg.Address, "err", err)
+		return err
+	}
+	return nil
+}
@@ -39,6 +39,9 @@ import (
 
 var LatestStateFileName = "latest.ssz_snappy"
 
+// FinalizedStateFileName is the latest finalized state written by the state antiquary, served to checkpoint-syncing peers.
+var FinalizedStateFileName = "finalized.ssz_snappy"
+
 type CaplinConfig struct {
 	Backfilling               bool
 	BlobBackfilling           bool
@@ -53,6 +56,27 @@ type CaplinConfig struct {
 	MevRelayUrl string
 	// EnableValidatorMonitor is used to enable the validator monitor metrics and corresponding logs
 	EnableValidatorMonitor bool
+	// ValidatorMonitorIndicies are validators observed by the validator monitor on top of the ones registered through the validator API
+	ValidatorMonitorIndicies []uint64
+
+	// In-process validator client, enabled if ValidatorKeystoreDir is set (EIP-2335 keystores)
+	ValidatorKeystoreDir  string
+	ValidatorPasswordFile string
+	ValidatorFeeRecipient libcommon.Address
+	ValidatorGraffiti     string
+	// ValidatorRemoteSignerURL is Web3Signer compatible signer: validator client signs with all of its keys
+	ValidatorRemoteSignerURL string
+	// ValidatorDoppelgangerEpochs is the amount of epochs to watch for activity of our validators before signing (0 - disabled)
+	ValidatorDoppelgangerEpochs uint64
+	// EIP-3076 interchange files: imported on start, exported on stop
+	ValidatorSlashingProtectionImport string
+	ValidatorSlashingProtectionExport string
+	// KeymanagerAPI serves keymanager API of the validator client, it runs the client even without ValidatorKeystoreDir
+	KeymanagerAPI beacon_router_configuration.KeymanagerConfiguration
+
+	// LightClient follows the chain with light client updates only (no beacon state), bootstrapping from LightClientTrustedRoot
+	LightClient            bool
+	LightClientTrustedRoot libcommon.Hash
 
 	// Devnets config
 	CustomConfigPath       string
@@ -83,6 +107,10 @@ func (c CaplinConfig) HaveInvalidDevnetParams() bool {
 	return c.CustomConfigPath == "" || c.CustomGenesisStatePath == ""
 }
 
+func (c CaplinConfig) ValidatorClientEnabled() bool {
+	return c.ValidatorKeystoreDir != "" || c.ValidatorRemoteSignerURL != "" || c.KeymanagerAPI.Active
+}
+
 func (c CaplinConfig) RelayUrlExist() bool {
 	return c.MevRelayUrl != ""
 }
@@ -197,6 +225,7 @@ type NetworkConfig struct {
 	Eth2key                    string // ETH2Key is the ENR key of the Ethereum consensus object in an enr.
 	AttSubnetKey               string // AttSubnetKey is the ENR key of the subnet bitfield in the enr.
 	SyncCommsSubnetKey         string // SyncCommsSubnetKey is the ENR key of the sync committee subnet bitfield in the enr.
+	CustodyGroupCountKey       string // CustodyGroupCountKey is the ENR key of the number of data column custody groups in the enr.
 	MinimumPeersInSubnetSearch uint64 // PeersInSubnetSearch is the required amount of peers that we need to be able to lookup in a subnet search.
 
 	BootNodes   []string
@@ -219,6 +248,7 @@ var NetworkConfigs map[NetworkType]NetworkConfig = map[NetworkType]NetworkConfig
 		Eth2key:                         "eth2",
 		AttSubnetKey:                    "attnets",
 		SyncCommsSubnetKey:              "syncnets",
+		CustodyGroupCountKey:            "cgc",
 		MinimumPeersInSubnetSearch:      20,
 		BootNodes:                       MainnetBootstrapNodes,
 	},
@@ -238,6 +268,7 @@ var NetworkConfigs map[NetworkType]NetworkConfig = map[NetworkType]NetworkConfig
 		Eth2key:                         "eth2",
 		AttSubnetKey:                    "attnets",
 		SyncCommsSubnetKey:              "syncnets",
+		CustodyGroupCountKey:            "cgc",
 		MinimumPeersInSubnetSearch:      20,
 		BootNodes:                       SepoliaBootstrapNodes,
 	},
@@ -257,6 +288,7 @@ var NetworkConfigs map[NetworkType]NetworkConfig = map[NetworkType]NetworkConfig
 		Eth2key:                         "eth2",
 		AttSubnetKey:                    "attnets",
 		SyncCommsSubnetKey:              "syncnets",
+		CustodyGroupCountKey:            "cgc",
 		MinimumPeersInSubnetSearch:      20,
 		BootNodes:                       GnosisBootstrapNodes,
 	},
@@ -276,6 +308,7 @@ var NetworkConfigs map[NetworkType]NetworkConfig = map[NetworkType]NetworkConfig
 		Eth2key:                         "eth2",
 		AttSubnetKey:                    "attnets",
 		SyncCommsSubnetKey:              "syncnets",
+		CustodyGroupCountKey:            "cgc",
 		MinimumPeersInSubnetSearch:      20,
 		BootNodes:                       ChiadoBootstrapNodes,
 	},
@@ -295,6 +328,7 @@ var NetworkConfigs map[NetworkType]NetworkConfig = map[NetworkType]NetworkConfig
 		Eth2key:                         "eth2",
 		AttSubnetKey:                    "attnets",
 		SyncCommsSubnetKey:              "syncnets",
+		CustodyGroupCountKey:            "cgc",
 		MinimumPeersInSubnetSearch:      20,
 		BootNodes:                       HoleskyBootstrapNodes,
 	},
@@ -484,6 +518,8 @@ type BeaconChainConfig struct {
 	DenebForkEpoch       uint64            `yaml:"DENEB_FORK_EPOCH" spec:"true" json:"DENEB_FORK_EPOCH,string"`         // DenebForkEpoch is used to represent the assigned fork epoch for Deneb.
 	ElectraForkVersion   ConfigForkVersion `yaml:"ELECTRA_FORK_VERSION" spec:"true" json:"ELECTRA_FORK_VERSION"`        // ElectraForkVersion is used to represent the fork version for Electra.
 	ElectraForkEpoch     uint64            `yaml:"ELECTRA_FORK_EPOCH" spec:"true" json:"ELECTRA_FORK_EPOCH,string"`     // ElectraForkEpoch is used to represent the assigned fork epoch for Electra.
+	Eip7594ForkVersion   ConfigForkVersion `yaml:"EIP7594_FORK_VERSION" spec:"true" json:"EIP7594_FORK_VERSION"`        // Eip7594ForkVersion is used to represent the fork version for PeerDAS.
+	Eip7594ForkEpoch     uint64            `yaml:"EIP7594_FORK_EPOCH" spec:"true" json:"EIP7594_FORK_EPOCH,string"`     // Eip7594ForkEpoch is used to represent the assigned fork epoch for PeerDAS.
 
 	ForkVersionSchedule map[libcommon.Bytes4]VersionScheduleEntry `json:"-"` // Schedule of fork epochs by version.
 
@@ -546,12 +582,19 @@ type BeaconChainConfig struct {
 	DataColumnSidecarSubnetCount uint64 `yaml:"DATA_COLUMN_SIDECAR_SUBNET_COUNT" spec:"true" json:"DATA_COLUMN_SIDECAR_SUBNET_COUNT,string"` // DataColumnSidecarSubnetCount defines the number of sidecars in the data column subnet.
 	MaxRequestDataColumnSidecars uint64 `yaml:"MAX_REQUEST_DATA_COLUMN_SIDECARS" spec:"true" json:"MAX_REQUEST_DATA_COLUMN_SIDECARS,string"` // MaxRequestDataColumnSidecars defines the maximum number of data column sidecars that can be requested.
 	SamplesPerSlot               uint64 `yaml:"SAMPLES_PER_SLOT" spec:"true" json:"SAMPLES_PER_SLOT,string"`                                 // SamplesPerSlot defines the number of samples per slot.
-	CustodyRequirement           uint64 `yaml:"CUSTODY_REQUIREMENT" spec:"true" json:"CUSTODY_REQUIREMENT,string"`                           // CustodyRequirement defines the custody requirement.
+	NumberOfCustodyGroups        uint64 `yaml:"NUMBER_OF_CUSTODY_GROUPS" spec:"true" json:"NUMBER_OF_CUSTODY_GROUPS,string"`                 // NumberOfCustodyGroups defines the number of custody groups the columns are split into.
+	CustodyRequirement           uint64 `yaml:"CUSTODY_REQUIREMENT" spec:"true" json:"CUSTODY_REQUIREMENT,string"`                           // CustodyRequirement defines the minimum number of custody groups of a node.
 	TargetNumberOfPeers          uint64 `yaml:"TARGET_NUMBER_OF_PEERS" spec:"true" json:"TARGET_NUMBER_OF_PEERS,string"`                     // TargetNumberOfPeers defines the target number of peers.
 
 	// Electra
-	MinPerEpochChurnLimitElectra        uint64 `yaml:"MIN_PER_EPOCH_CHURN_LIMIT_ELECTRA" spec:"true" json:"MIN_PER_EPOCH_CHURN_LIMIT_ELECTRA,string"`                 // MinPerEpochChurnLimitElectra defines the minimum per epoch churn limit for Electra.
-	MaxPerEpochActivationExitChurnLimit uint64 `yaml:"MAX_PER_EPOCH_ACTIVATION_EXIT_CHURN_LIMIT" spec:"true" json:"MAX_PER_EPOCH_ACTIVATION_EXIT_CHURN_LIMIT,string"` // MaxPerEpochActivationExitChurnLimit defines the maximum per epoch activation exit churn limit for Electra.
+	MinPerEpochChurnLimitElectra        uint64     `yaml:"MIN_PER_EPOCH_CHURN_LIMIT_ELECTRA" spec:"true" json:"MIN_PER_EPOCH_CHURN_LIMIT_ELECTRA,string"`                 // MinPerEpochChurnLimitElectra defines the minimum per epoch churn limit for Electra.
+	MaxPerEpochActivationExitChurnLimit uint64     `yaml:"MAX_PER_EPOCH_ACTIVATION_EXIT_CHURN_LIMIT" spec:"true" json:"MAX_PER_EPOCH_ACTIVATION_EXIT_CHURN_LIMIT,string"` // MaxPerEpochActivationExitChurnLimit defines the maximum per epoch activation exit churn limit for Electra.
+	MinActivationBalance                uint64     `yaml:"MIN_ACTIVATION_BALANCE" spec:"true" json:"MIN_ACTIVATION_BALANCE,string"`                                       // MinActivationBalance is the minimum balance for a validator to become active.
+	MaxEffectiveBalanceElectra          uint64     `yaml:"MAX_EFFECTIVE_BALANCE_ELECTRA" spec:"true" json:"MAX_EFFECTIVE_BALANCE_ELECTRA,string"`                         // MaxEffectiveBalanceElectra is the maximal effective balance of a validator with compounding credentials.
+	CompoundingWithdrawalPrefixByte     ConfigByte `yaml:"COMPOUNDING_WITHDRAWAL_PREFIX" spec:"true" json:"COMPOUNDING_WITHDRAWAL_PREFIX"`                                // CompoundingWithdrawalPrefixByte is the first byte of compounding withdrawal credentials.
+	PendingDepositsLimit                uint64     `yaml:"PENDING_DEPOSITS_LIMIT" spec:"true" json:"PENDING_DEPOSITS_LIMIT,string"`                                       // PendingDepositsLimit is the maximum length of the pending deposits queue.
+	PendingPartialWithdrawalsLimit      uint64     `yaml:"PENDING_PARTIAL_WITHDRAWALS_LIMIT" spec:"true" json:"PENDING_PARTIAL_WITHDRAWALS_LIMIT,string"`                 // PendingPartialWithdrawalsLimit is the maximum length of the pending partial withdrawals queue.
+	PendingConsolidationsLimit          uint64     `yaml:"PENDING_CONSOLIDATIONS_LIMIT" spec:"true" json:"PENDING_CONSOLIDATIONS_LIMIT,string"`                           // PendingConsolidationsLimit is the maximum length of the pending consolidations queue.
 }
 
 func (b *BeaconChainConfig) RoundSlotToEpoch(slot uint64) uint64 {
@@ -738,6 +781,8 @@ var MainnetBeaconConfig BeaconChainConfig = BeaconChainConfig{
 	DenebForkEpoch:       269568,
 	// ElectraForkVersion:   Not Set,
 	ElectraForkEpoch: math.MaxUint64,
+	// Eip7594ForkVersion:   Not Set,
+	Eip7594ForkEpoch: math.MaxUint64,
 
 	// New values introduced in Altair hard fork 1.
 	// Participation flag indices.
@@ -795,11 +840,18 @@ var MainnetBeaconConfig BeaconChainConfig = BeaconChainConfig{
 	DataColumnSidecarSubnetCount: 32,
 	MaxRequestDataColumnSidecars: 16384,
 	SamplesPerSlot:               8,
-	CustodyRequirement:           1,
+	NumberOfCustodyGroups:        128,
+	CustodyRequirement:           4,
 	TargetNumberOfPeers:          70,
 
 	MinPerEpochChurnLimitElectra:        128000000000,
 	MaxPerEpochActivationExitChurnLimit: 256000000000,
+	MinActivationBalance:                32 * 1e9,
+	MaxEffectiveBalanceElectra:          2048 * 1e9,
+	CompoundingWithdrawalPrefixByte:     ConfigByte(2),
+	PendingDepositsLimit:                1 << 27,
+	PendingPartialWithdrawalsLimit:      1 << 27,
+	PendingConsolidationsLimit:          1 << 18,
 }
 
 func mainnetConfig() BeaconChainConfig {
@@ -1048,6 +1100,16 @@ func (b *BeaconChainConfig) GetForkEpochByVersion(v StateVersion) uint64 {
 	panic("invalid version")
 }
 
+// IsPeerDASActive returns whether data columns replace blob sidecars at the given epoch.
+func (b *BeaconChainConfig) IsPeerDASActive(epoch uint64) bool {
+	return epoch >= b.Eip7594ForkEpoch
+}
+
+// IsPeerDASScheduled returns whether the network has a PeerDAS fork, in which case data columns are followed on the network.
+func (b *BeaconChainConfig) IsPeerDASScheduled() bool {
+	return b.Eip7594ForkEpoch != math.MaxUint64
+}
+
 func GetConfigsByNetwork(net NetworkType) (*NetworkConfig, *BeaconChainConfig) {
 	networkConfig := NetworkConfigs[net]
 	beaconConfig := BeaconConfigs[net]
@@ -402,6 +402,11 @@ func (b *BeaconBody) KzgCommitmentMerkleProof(index int) ([][32]byte, error) {
 	return append(branch, kzgCommitmentsProof...), nil
 }
 
+// KzgCommitmentsInclusionProof returns the proof of the whole commitments list, which is the one carried by data column sidecars.
+func (b *BeaconBody) KzgCommitmentsInclusionProof() ([][32]byte, error) {
+	return merkle_tree.MerkleProof(KzgCommitmentsInclusionProofDepth, blobKzgCommitmentsBodyIndex, b.getSchema(false)...)
+}
+
 func (b *BeaconBody) UnmarshalJSON(buf []byte) error {
 	var (
 		maxAttSlashing = MaxAttesterSlashings
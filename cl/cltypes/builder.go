@@ -16,7 +16,12 @@
 
 package cltypes
 
-import libcommon "github.com/erigontech/erigon-lib/common"
+import (
+	"strconv"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/cl/merkle_tree"
+)
 
 // ValidatorRegistration is used as request payload for validator registration in builder client.
 type ValidatorRegistration struct {
@@ -30,3 +35,16 @@ type ValidatorRegistrationMessage struct {
 	Timestamp    string            `json:"timestamp"`
 	PubKey       libcommon.Bytes48 `json:"pubkey"`
 }
+
+// HashSSZ - hash tree root of the message, signed with DOMAIN_APPLICATION_BUILDER
+func (m *ValidatorRegistrationMessage) HashSSZ() ([32]byte, error) {
+	gasLimit, err := strconv.ParseUint(m.GasLimit, 10, 64)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	timestamp, err := strconv.ParseUint(m.Timestamp, 10, 64)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return merkle_tree.HashTreeRoot(m.FeeRecipient[:], gasLimit, timestamp, m.PubKey[:])
+}
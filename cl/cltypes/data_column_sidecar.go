@@ -0,0 +1,207 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package cltypes
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutility"
+	"github.com/erigontech/erigon-lib/common/length"
+	"github.com/erigontech/erigon-lib/types/clonable"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/merkle_tree"
+	ssz2 "github.com/erigontech/erigon/cl/ssz"
+	"github.com/erigontech/erigon/cl/utils"
+)
+
+const (
+	// https://github.com/ethereum/consensus-specs/blob/dev/specs/_features/eip7594/polynomial-commitments-sampling.md#cells
+	FIELD_ELEMENTS_PER_CELL = 64
+	BYTES_PER_CELL          = BYTES_PER_FIELD_ELEMENT * FIELD_ELEMENTS_PER_CELL
+
+	NumberOfColumns                   = 128
+	KzgCommitmentsInclusionProofDepth = 4
+	// index of blob_kzg_commitments in the beacon block body.
+	blobKzgCommitmentsBodyIndex = 11
+)
+
+var (
+	cellT = reflect.TypeOf(Cell{})
+
+	_ ssz2.SizedObjectSSZ = (*Cell)(nil)
+
+	ErrInvalidDataColumnSidecar = errors.New("invalid data column sidecar")
+)
+
+// Cell is a column of a blob extended with its erasure coding, see kzg.Cell.
+type Cell [BYTES_PER_CELL]byte
+
+func (c *Cell) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hexutility.Bytes(c[:]))
+}
+
+func (c *Cell) UnmarshalJSON(in []byte) error {
+	return hexutility.UnmarshalFixedJSON(cellT, in, c[:])
+}
+
+func (*Cell) Clone() clonable.Clonable {
+	return &Cell{}
+}
+
+func (c *Cell) DecodeSSZ(buf []byte, version int) error {
+	return ssz2.UnmarshalSSZ(buf, version, c[:])
+}
+
+func (c *Cell) EncodeSSZ(buf []byte) ([]byte, error) {
+	return append(buf, c[:]...), nil
+}
+
+func (c *Cell) EncodingSizeSSZ() int {
+	return BYTES_PER_CELL
+}
+
+func (*Cell) Static() bool {
+	return true
+}
+
+func (c *Cell) HashSSZ() ([32]byte, error) {
+	return merkle_tree.BytesRoot(c[:])
+}
+
+// DataColumnSidecar holds the same column of cells of every blob of a block, along with their commitments and cell proofs.
+type DataColumnSidecar struct {
+	Index                        uint64                         `json:"index,string"`
+	Column                       *solid.ListSSZ[*Cell]          `json:"column"`
+	KzgCommitments               *solid.ListSSZ[*KZGCommitment] `json:"kzg_commitments"`
+	KzgProofs                    *solid.ListSSZ[*KZGProof]      `json:"kzg_proofs"`
+	SignedBlockHeader            *SignedBeaconBlockHeader       `json:"signed_block_header"`
+	KzgCommitmentsInclusionProof solid.HashVectorSSZ            `json:"kzg_commitments_inclusion_proof"`
+}
+
+func NewDataColumnSidecar() *DataColumnSidecar {
+	return &DataColumnSidecar{
+		Column:                       solid.NewStaticListSSZ[*Cell](MaxBlobsCommittmentsPerBlock, BYTES_PER_CELL),
+		KzgCommitments:               solid.NewStaticListSSZ[*KZGCommitment](MaxBlobsCommittmentsPerBlock, length.Bytes48),
+		KzgProofs:                    solid.NewStaticListSSZ[*KZGProof](MaxBlobsCommittmentsPerBlock, length.Bytes48),
+		SignedBlockHeader:            &SignedBeaconBlockHeader{Header: &BeaconBlockHeader{}},
+		KzgCommitmentsInclusionProof: solid.NewHashVector(KzgCommitmentsInclusionProofDepth),
+	}
+}
+
+func (d *DataColumnSidecar) UnmarshalJSON(buf []byte) error {
+	type tmp DataColumnSidecar
+	t := (*tmp)(NewDataColumnSidecar())
+	if err := json.Unmarshal(buf, t); err != nil {
+		return err
+	}
+	*d = DataColumnSidecar(*t)
+	return nil
+}
+
+func (d *DataColumnSidecar) EncodeSSZ(buf []byte) ([]byte, error) {
+	return ssz2.MarshalSSZ(buf, d.getSchema()...)
+}
+
+func (d *DataColumnSidecar) DecodeSSZ(buf []byte, version int) error {
+	*d = *NewDataColumnSidecar()
+	return ssz2.UnmarshalSSZ(buf, version, d.getSchema()...)
+}
+
+func (d *DataColumnSidecar) EncodingSizeSSZ() int {
+	return length.BlockNum + 3*4 + d.Column.EncodingSizeSSZ() + d.KzgCommitments.EncodingSizeSSZ() + d.KzgProofs.EncodingSizeSSZ() +
+		d.SignedBlockHeader.EncodingSizeSSZ() + KzgCommitmentsInclusionProofDepth*length.Hash
+}
+
+func (d *DataColumnSidecar) HashSSZ() ([32]byte, error) {
+	return merkle_tree.HashTreeRoot(d.getSchema()...)
+}
+
+func (*DataColumnSidecar) Clone() clonable.Clonable {
+	return NewDataColumnSidecar()
+}
+
+func (d *DataColumnSidecar) getSchema() []interface{} {
+	return []interface{}{&d.Index, d.Column, d.KzgCommitments, d.KzgProofs, d.SignedBlockHeader, d.KzgCommitmentsInclusionProof}
+}
+
+// VerifyDataColumnSidecar implements verify_data_column_sidecar from EIP-7594: it checks the sidecar is well formed.
+func VerifyDataColumnSidecar(sidecar *DataColumnSidecar, beaconCfg *clparams.BeaconChainConfig) error {
+	if sidecar.Index >= beaconCfg.NumberOfColumns {
+		return errors.New("data column index out of range")
+	}
+	if sidecar.KzgCommitments.Len() == 0 {
+		return errors.New("data column sidecar has no commitments")
+	}
+	if sidecar.Column.Len() != sidecar.KzgCommitments.Len() || sidecar.Column.Len() != sidecar.KzgProofs.Len() {
+		return ErrInvalidDataColumnSidecar
+	}
+	return nil
+}
+
+// VerifyDataColumnSidecarInclusionProof implements verify_data_column_sidecar_inclusion_proof from EIP-7594: it checks that
+// the commitments of the sidecar are the ones of the block body.
+func VerifyDataColumnSidecarInclusionProof(sidecar *DataColumnSidecar) bool {
+	if sidecar.KzgCommitmentsInclusionProof == nil || sidecar.KzgCommitmentsInclusionProof.Length() != KzgCommitmentsInclusionProofDepth {
+		return false
+	}
+	leaf, err := sidecar.KzgCommitments.HashSSZ()
+	if err != nil {
+		return false
+	}
+	branch := make([]libcommon.Hash, KzgCommitmentsInclusionProofDepth)
+	for i := range branch {
+		branch[i] = sidecar.KzgCommitmentsInclusionProof.Get(i)
+	}
+	return utils.IsValidMerkleBranch(leaf, branch, KzgCommitmentsInclusionProofDepth, blobKzgCommitmentsBodyIndex, sidecar.SignedBlockHeader.Header.BodyRoot)
+}
+
+// DataColumnIdentifier identifies a data column sidecar by its block root and column index.
+type DataColumnIdentifier struct {
+	BlockRoot libcommon.Hash `json:"block_root"`
+	Index     uint64         `json:"index,string"`
+}
+
+func NewDataColumnIdentifier(blockRoot libcommon.Hash, index uint64) *DataColumnIdentifier {
+	return &DataColumnIdentifier{
+		BlockRoot: blockRoot,
+		Index:     index,
+	}
+}
+
+func (d *DataColumnIdentifier) EncodeSSZ(buf []byte) ([]byte, error) {
+	return ssz2.MarshalSSZ(buf, d.BlockRoot[:], &d.Index)
+}
+
+func (d *DataColumnIdentifier) EncodingSizeSSZ() int {
+	return length.Hash + length.BlockNum
+}
+
+func (d *DataColumnIdentifier) DecodeSSZ(buf []byte, version int) error {
+	return ssz2.UnmarshalSSZ(buf, version, d.BlockRoot[:], &d.Index)
+}
+
+func (d *DataColumnIdentifier) HashSSZ() ([32]byte, error) {
+	return merkle_tree.HashTreeRoot(d.BlockRoot[:], &d.Index)
+}
+
+func (*DataColumnIdentifier) Clone() clonable.Clonable {
+	return &DataColumnIdentifier{}
+}
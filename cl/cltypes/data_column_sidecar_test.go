@@ -0,0 +1,85 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package cltypes
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon/cl/clparams"
+)
+
+func TestDataColumnSidecar(t *testing.T) {
+	_, bc := clparams.GetConfigsByNetwork(clparams.GnosisNetwork)
+	block := NewSignedBeaconBlock(bc, clparams.DenebVersion)
+	require.NoError(t, block.DecodeSSZ(beaconBodySSZ, int(clparams.DenebVersion)))
+	body := block.Block.Body
+	body.BlobKzgCommitments.Append(&KZGCommitment{1})
+	body.BlobKzgCommitments.Append(&KZGCommitment{2})
+	bodyRoot, err := body.HashSSZ()
+	require.NoError(t, err)
+	proof, err := body.KzgCommitmentsInclusionProof()
+	require.NoError(t, err)
+
+	sidecar := NewDataColumnSidecar()
+	sidecar.Index = 7
+	sidecar.SignedBlockHeader.Header.BodyRoot = bodyRoot
+	body.BlobKzgCommitments.Range(func(_ int, commitment *KZGCommitment, _ int) bool {
+		sidecar.KzgCommitments.Append(commitment)
+		sidecar.Column.Append(&Cell{byte(sidecar.Column.Len())})
+		sidecar.KzgProofs.Append(&KZGProof{3})
+		return true
+	})
+	for i, node := range proof {
+		sidecar.KzgCommitmentsInclusionProof.Set(i, node)
+	}
+	require.NoError(t, VerifyDataColumnSidecar(sidecar, bc))
+	require.True(t, VerifyDataColumnSidecarInclusionProof(sidecar))
+
+	// ssz and json round trips
+	encoded, err := sidecar.EncodeSSZ(nil)
+	require.NoError(t, err)
+	require.Len(t, encoded, sidecar.EncodingSizeSSZ())
+	decoded := &DataColumnSidecar{}
+	require.NoError(t, decoded.DecodeSSZ(encoded, int(clparams.DenebVersion)))
+	root, err := sidecar.HashSSZ()
+	require.NoError(t, err)
+	decodedRoot, err := decoded.HashSSZ()
+	require.NoError(t, err)
+	require.Equal(t, root, decodedRoot)
+	require.True(t, VerifyDataColumnSidecarInclusionProof(decoded))
+
+	encodedJSON, err := json.Marshal(sidecar)
+	require.NoError(t, err)
+	fromJSON := &DataColumnSidecar{}
+	require.NoError(t, json.Unmarshal(encodedJSON, fromJSON))
+	jsonRoot, err := fromJSON.HashSSZ()
+	require.NoError(t, err)
+	require.Equal(t, root, jsonRoot)
+
+	// commitments not in the block
+	tampered := &DataColumnSidecar{}
+	require.NoError(t, tampered.DecodeSSZ(encoded, int(clparams.DenebVersion)))
+	tampered.KzgCommitments.Get(0)[0] = 9
+	require.False(t, VerifyDataColumnSidecarInclusionProof(tampered))
+	tampered.KzgProofs.Truncate(1)
+	require.ErrorIs(t, VerifyDataColumnSidecar(tampered, bc), ErrInvalidDataColumnSidecar)
+	tampered.Index = bc.NumberOfColumns
+	require.Error(t, VerifyDataColumnSidecar(tampered, bc))
+}
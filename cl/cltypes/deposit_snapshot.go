@@ -0,0 +1,67 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package cltypes
+
+import (
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/length"
+	"github.com/erigontech/erigon-lib/types/clonable"
+
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/merkle_tree"
+	ssz2 "github.com/erigontech/erigon/cl/ssz"
+)
+
+// DepositTreeSnapshot is the EIP-4881 snapshot of the finalized part of the deposit tree.
+type DepositTreeSnapshot struct {
+	Finalized            solid.HashListSSZ `json:"finalized"`
+	DepositRoot          libcommon.Hash    `json:"deposit_root"`
+	DepositCount         uint64            `json:"deposit_count,string"`
+	ExecutionBlockHash   libcommon.Hash    `json:"execution_block_hash"`
+	ExecutionBlockHeight uint64            `json:"execution_block_height,string"`
+}
+
+func NewDepositTreeSnapshot() *DepositTreeSnapshot {
+	return &DepositTreeSnapshot{
+		Finalized: solid.NewHashList(merkle_tree.DepositContractDepth),
+	}
+}
+
+func (d *DepositTreeSnapshot) EncodeSSZ(buf []byte) ([]byte, error) {
+	return ssz2.MarshalSSZ(buf, d.Finalized, d.DepositRoot[:], d.DepositCount, d.ExecutionBlockHash[:], d.ExecutionBlockHeight)
+}
+
+func (d *DepositTreeSnapshot) DecodeSSZ(buf []byte, version int) error {
+	d.Finalized = solid.NewHashList(merkle_tree.DepositContractDepth)
+	return ssz2.UnmarshalSSZ(buf, version, d.Finalized, d.DepositRoot[:], &d.DepositCount, d.ExecutionBlockHash[:], &d.ExecutionBlockHeight)
+}
+
+func (d *DepositTreeSnapshot) EncodingSizeSSZ() int {
+	return 4 + d.Finalized.EncodingSizeSSZ() + length.Hash*2 + 8*2
+}
+
+func (d *DepositTreeSnapshot) HashSSZ() ([32]byte, error) {
+	return merkle_tree.HashTreeRoot(d.Finalized, d.DepositRoot[:], d.DepositCount, d.ExecutionBlockHash[:], d.ExecutionBlockHeight)
+}
+
+func (*DepositTreeSnapshot) Clone() clonable.Clonable {
+	return NewDepositTreeSnapshot()
+}
+
+func (*DepositTreeSnapshot) Static() bool {
+	return false
+}
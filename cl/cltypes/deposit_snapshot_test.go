@@ -0,0 +1,62 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package cltypes_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/cl/cltypes"
+)
+
+func TestDepositTreeSnapshotEncoding(t *testing.T) {
+	snapshot := cltypes.NewDepositTreeSnapshot()
+	snapshot.Finalized.Append(common.HexToHash("0x01"))
+	snapshot.Finalized.Append(common.HexToHash("0x02"))
+	snapshot.DepositRoot = common.HexToHash("0x03")
+	snapshot.DepositCount = 3
+	snapshot.ExecutionBlockHash = common.HexToHash("0x04")
+	snapshot.ExecutionBlockHeight = 1000
+
+	encoded, err := snapshot.EncodeSSZ(nil)
+	require.NoError(t, err)
+	require.Len(t, encoded, snapshot.EncodingSizeSSZ())
+
+	decoded := cltypes.NewDepositTreeSnapshot()
+	require.NoError(t, decoded.DecodeSSZ(encoded, 0))
+	require.Equal(t, 2, decoded.Finalized.Length())
+	require.Equal(t, snapshot.Finalized.Get(1), decoded.Finalized.Get(1))
+	require.Equal(t, snapshot.DepositRoot, decoded.DepositRoot)
+	require.Equal(t, snapshot.DepositCount, decoded.DepositCount)
+	require.Equal(t, snapshot.ExecutionBlockHash, decoded.ExecutionBlockHash)
+	require.Equal(t, snapshot.ExecutionBlockHeight, decoded.ExecutionBlockHeight)
+
+	root1, err := snapshot.HashSSZ()
+	require.NoError(t, err)
+	root2, err := decoded.HashSSZ()
+	require.NoError(t, err)
+	require.Equal(t, root1, root2)
+
+	encodedJSON, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	require.Contains(t, string(encodedJSON), `"deposit_count":"3"`)
+	require.Contains(t, string(encodedJSON), `"execution_block_height":"1000"`)
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/erigontech/erigon-lib/types/clonable"
 	"github.com/erigontech/erigon-lib/types/ssz"
 
+	"github.com/erigontech/erigon/cl/cltypes/solid"
 	ssz2 "github.com/erigontech/erigon/cl/ssz"
 )
 
@@ -197,3 +198,30 @@ func (l *BlobsByRangeRequest) EncodingSizeSSZ() int {
 func (*BlobsByRangeRequest) Clone() clonable.Clonable {
 	return &BlobsByRangeRequest{}
 }
+
+type DataColumnsByRangeRequest struct {
+	StartSlot uint64
+	Count     uint64
+	Columns   solid.Uint64ListSSZ
+}
+
+func NewDataColumnsByRangeRequest() *DataColumnsByRangeRequest {
+	return &DataColumnsByRangeRequest{Columns: solid.NewUint64ListSSZ(NumberOfColumns)}
+}
+
+func (l *DataColumnsByRangeRequest) EncodeSSZ(buf []byte) ([]byte, error) {
+	return ssz2.MarshalSSZ(buf, &l.StartSlot, &l.Count, l.Columns)
+}
+
+func (l *DataColumnsByRangeRequest) DecodeSSZ(buf []byte, version int) error {
+	l.Columns = solid.NewUint64ListSSZ(NumberOfColumns)
+	return ssz2.UnmarshalSSZ(buf, version, &l.StartSlot, &l.Count, l.Columns)
+}
+
+func (l *DataColumnsByRangeRequest) EncodingSizeSSZ() int {
+	return 16 + 4 + l.Columns.EncodingSizeSSZ()
+}
+
+func (*DataColumnsByRangeRequest) Clone() clonable.Clonable {
+	return NewDataColumnsByRangeRequest()
+}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/erigontech/erigon/cl/clparams"
 	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
 )
 
 var testMetadata = &cltypes.Metadata{
@@ -70,6 +71,12 @@ var testBlobRequestByRange = &cltypes.BlobsByRangeRequest{
 	Count:     10,
 }
 
+var testDataColumnsRequestByRange = &cltypes.DataColumnsByRangeRequest{
+	StartSlot: 100,
+	Count:     10,
+	Columns:   solid.NewUint64ListSSZFromSlice(cltypes.NumberOfColumns, []uint64{1, 33, 65}),
+}
+
 func TestMarshalNetworkTypes(t *testing.T) {
 	cases := []ssz.EncodableSSZ{
 		testMetadata,
@@ -79,6 +86,7 @@ func TestMarshalNetworkTypes(t *testing.T) {
 		testBlockRoot,
 		testLightClientUpdatesByRange,
 		testBlobRequestByRange,
+		testDataColumnsRequestByRange,
 	}
 
 	unmarshalDestinations := []ssz.EncodableSSZ{
@@ -89,6 +97,7 @@ func TestMarshalNetworkTypes(t *testing.T) {
 		&cltypes.Root{},
 		&cltypes.LightClientUpdatesByRangeRequest{},
 		&cltypes.BlobsByRangeRequest{},
+		&cltypes.DataColumnsByRangeRequest{},
 	}
 	for i, tc := range cases {
 		marshalledBytes, err := tc.EncodeSSZ(nil)
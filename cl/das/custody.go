@@ -0,0 +1,98 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+// Package das implements the PeerDAS (EIP-7594) data availability sampling helpers: custody of data columns and
+// verification of data column sidecars.
+package das
+
+import (
+	"encoding/binary"
+	"errors"
+	"slices"
+
+	"github.com/holiman/uint256"
+
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/utils"
+	"github.com/erigontech/erigon/p2p/enode"
+)
+
+var ErrInvalidCustodyGroupCount = errors.New("custody group count exceeds the number of custody groups")
+
+// GetCustodyGroups implements get_custody_groups from EIP-7594: it returns the sorted custody groups of a node, which
+// are derived by hashing the node id and its successors.
+func GetCustodyGroups(nodeID enode.ID, custodyGroupCount uint64, beaconCfg *clparams.BeaconChainConfig) ([]uint64, error) {
+	if custodyGroupCount > beaconCfg.NumberOfCustodyGroups {
+		return nil, ErrInvalidCustodyGroupCount
+	}
+	currentID := new(uint256.Int).SetBytes32(nodeID[:])
+	groups := make([]uint64, 0, custodyGroupCount)
+	seen := make(map[uint64]struct{}, custodyGroupCount)
+	for uint64(len(groups)) < custodyGroupCount {
+		// uint_to_bytes is little endian
+		idBytes := currentID.Bytes32()
+		slices.Reverse(idBytes[:])
+		hash := utils.Sha256(idBytes[:])
+		group := binary.LittleEndian.Uint64(hash[:8]) % beaconCfg.NumberOfCustodyGroups
+		if _, ok := seen[group]; !ok {
+			seen[group] = struct{}{}
+			groups = append(groups, group)
+		}
+		// wraps around to 0 after the maximum uint256
+		currentID.AddUint64(currentID, 1)
+	}
+	slices.Sort(groups)
+	return groups, nil
+}
+
+// ComputeColumnsForCustodyGroup implements compute_columns_for_custody_group from EIP-7594.
+func ComputeColumnsForCustodyGroup(custodyGroup uint64, beaconCfg *clparams.BeaconChainConfig) []uint64 {
+	columnsPerGroup := beaconCfg.NumberOfColumns / beaconCfg.NumberOfCustodyGroups
+	columns := make([]uint64, columnsPerGroup)
+	for i := range columns {
+		columns[i] = beaconCfg.NumberOfCustodyGroups*uint64(i) + custodyGroup
+	}
+	return columns
+}
+
+// GetCustodyColumns returns the sorted data columns a node must custody.
+func GetCustodyColumns(nodeID enode.ID, custodyGroupCount uint64, beaconCfg *clparams.BeaconChainConfig) ([]uint64, error) {
+	groups, err := GetCustodyGroups(nodeID, custodyGroupCount, beaconCfg)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]uint64, 0, len(groups)*int(beaconCfg.NumberOfColumns/beaconCfg.NumberOfCustodyGroups))
+	for _, group := range groups {
+		columns = append(columns, ComputeColumnsForCustodyGroup(group, beaconCfg)...)
+	}
+	slices.Sort(columns)
+	return columns, nil
+}
+
+// ComputeSubnetForDataColumnSidecar implements compute_subnet_for_data_column_sidecar from EIP-7594.
+func ComputeSubnetForDataColumnSidecar(columnIndex uint64, beaconCfg *clparams.BeaconChainConfig) uint64 {
+	return columnIndex % beaconCfg.DataColumnSidecarSubnetCount
+}
+
+// GetCustodySubnets returns the sorted data column subnets a node custodying the given columns must subscribe to.
+func GetCustodySubnets(columns []uint64, beaconCfg *clparams.BeaconChainConfig) []uint64 {
+	subnets := make([]uint64, 0, len(columns))
+	for _, column := range columns {
+		subnets = append(subnets, ComputeSubnetForDataColumnSidecar(column, beaconCfg))
+	}
+	slices.Sort(subnets)
+	return slices.Compact(subnets)
+}
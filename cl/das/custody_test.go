@@ -0,0 +1,69 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package das
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/p2p/enode"
+)
+
+func TestGetCustodyColumns(t *testing.T) {
+	cfg := clparams.MainnetBeaconConfig
+	cfg.NumberOfCustodyGroups = 64 // two columns per group
+
+	// the maximum node id wraps around while looking for groups.
+	var nodeID enode.ID
+	for i := range nodeID {
+		nodeID[i] = 0xff
+	}
+	groups, err := GetCustodyGroups(nodeID, cfg.CustodyRequirement, &cfg)
+	require.NoError(t, err)
+	require.Len(t, groups, int(cfg.CustodyRequirement))
+	require.True(t, slices.IsSorted(groups))
+	require.Len(t, slices.Compact(slices.Clone(groups)), len(groups))
+
+	again, err := GetCustodyGroups(nodeID, cfg.CustodyRequirement, &cfg)
+	require.NoError(t, err)
+	require.Equal(t, groups, again)
+
+	columns, err := GetCustodyColumns(nodeID, cfg.CustodyRequirement, &cfg)
+	require.NoError(t, err)
+	require.Len(t, columns, 2*len(groups))
+	for _, column := range columns {
+		require.Contains(t, groups, column%cfg.NumberOfCustodyGroups)
+	}
+	for _, subnet := range GetCustodySubnets(columns, &cfg) {
+		require.Less(t, subnet, cfg.DataColumnSidecarSubnetCount)
+	}
+
+	// custodying every group means custodying every column.
+	columns, err = GetCustodyColumns(enode.ID{1}, cfg.NumberOfCustodyGroups, &cfg)
+	require.NoError(t, err)
+	require.Len(t, columns, int(cfg.NumberOfColumns))
+	for i, column := range columns {
+		require.Equal(t, uint64(i), column)
+	}
+	require.Len(t, GetCustodySubnets(columns, &cfg), int(cfg.DataColumnSidecarSubnetCount))
+
+	_, err = GetCustodyGroups(nodeID, cfg.NumberOfCustodyGroups+1, &cfg)
+	require.ErrorIs(t, err, ErrInvalidCustodyGroupCount)
+}
@@ -0,0 +1,41 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package das
+
+import (
+	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
+
+	"github.com/erigontech/erigon-lib/crypto/kzg"
+	"github.com/erigontech/erigon/cl/cltypes"
+)
+
+// VerifyDataColumnSidecarKZGProofs implements verify_data_column_sidecar_kzg_proofs from EIP-7594: it checks every
+// cell of the column against the commitment of its blob. The sidecar must have passed cltypes.VerifyDataColumnSidecar.
+func VerifyDataColumnSidecarKZGProofs(sidecar *cltypes.DataColumnSidecar) error {
+	count := sidecar.Column.Len()
+	commitments := make([]gokzg4844.KZGCommitment, count)
+	cellIndices := make([]uint64, count)
+	cells := make([]kzg.Cell, count)
+	proofs := make([]gokzg4844.KZGProof, count)
+	for i := 0; i < count; i++ {
+		commitments[i] = gokzg4844.KZGCommitment(*sidecar.KzgCommitments.Get(i))
+		cellIndices[i] = sidecar.Index
+		cells[i] = kzg.Cell(*sidecar.Column.Get(i))
+		proofs[i] = gokzg4844.KZGProof(*sidecar.KzgProofs.Get(i))
+	}
+	return kzg.VerifyCellKZGProofBatch(commitments, cellIndices, cells, proofs)
+}
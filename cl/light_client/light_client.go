@@ -0,0 +1,309 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package light_client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/gointerfaces/grpcutil"
+	sentinel "github.com/erigontech/erigon-lib/gointerfaces/sentinelproto"
+	"github.com/erigontech/erigon-lib/log/v3"
+
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/gossip"
+	"github.com/erigontech/erigon/cl/phase1/execution_client"
+	"github.com/erigontech/erigon/cl/rpc"
+	"github.com/erigontech/erigon/cl/utils/eth_clock"
+)
+
+const (
+	// maxRequestLightClientUpdates is MAX_REQUEST_LIGHT_CLIENT_UPDATES from the p2p specs.
+	maxRequestLightClientUpdates = 128
+	// gossipReconnectBackoff is how long followGossip waits before subscribing again once the gossip stream broke.
+	gossipReconnectBackoff = 3 * time.Second
+)
+
+// LightClient follows the chain through light client updates only: it bootstraps from a trusted block root,
+// catches up with updates by range and then follows finality and optimistic updates gossip.
+// The execution engine's forkchoice is driven by the execution payload headers of the verified light client headers.
+type LightClient struct {
+	beaconConfig *clparams.BeaconChainConfig
+	ethClock     eth_clock.EthereumClock
+	sentinel     sentinel.SentinelClient
+	rpc          *rpc.BeaconRpcP2P
+	engine       execution_client.ExecutionEngine
+	trustedRoot  libcommon.Hash
+	logger       log.Logger
+
+	mu    sync.Mutex
+	store *Store
+	// last forkchoice sent to the execution engine
+	forkChoiceMu  sync.Mutex
+	headHash      libcommon.Hash
+	finalizedHash libcommon.Hash
+}
+
+func NewLightClient(
+	beaconConfig *clparams.BeaconChainConfig,
+	ethClock eth_clock.EthereumClock,
+	sentinel sentinel.SentinelClient,
+	beaconRpc *rpc.BeaconRpcP2P,
+	engine execution_client.ExecutionEngine,
+	trustedRoot libcommon.Hash,
+	logger log.Logger,
+) *LightClient {
+	return &LightClient{
+		beaconConfig: beaconConfig,
+		ethClock:     ethClock,
+		sentinel:     sentinel,
+		rpc:          beaconRpc,
+		engine:       engine,
+		trustedRoot:  trustedRoot,
+		logger:       logger,
+	}
+}
+
+// Start bootstraps the light client store and follows the chain until the context is cancelled.
+func (l *LightClient) Start(ctx context.Context) error {
+	if err := l.bootstrap(ctx); err != nil {
+		return err
+	}
+	go l.followGossip(ctx)
+
+	ticker := time.NewTicker(time.Duration(l.beaconConfig.SecondsPerSlot) * time.Second)
+	defer ticker.Stop()
+	for {
+		if err := l.catchUp(ctx); err != nil {
+			l.logger.Debug("[Light Client] Could not fetch light client updates", "err", err)
+		}
+		l.mu.Lock()
+		if l.store.ProcessForceUpdate(l.ethClock.GetCurrentSlot()) {
+			l.logger.Warn("[Light Client] Forced light client update, no finality within the update timeout")
+		}
+		l.mu.Unlock()
+		l.onStoreChange(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// FinalizedHeader returns the latest finalized light client header.
+func (l *LightClient) FinalizedHeader() *cltypes.LightClientHeader {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.store == nil {
+		return nil
+	}
+	return l.store.FinalizedHeader
+}
+
+// OptimisticHeader returns the most recent reasonably-safe light client header.
+func (l *LightClient) OptimisticHeader() *cltypes.LightClientHeader {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.store == nil {
+		return nil
+	}
+	return l.store.OptimisticHeader
+}
+
+func (l *LightClient) bootstrap(ctx context.Context) error {
+	genesisValidatorsRoot := l.ethClock.GenesisValidatorsRoot()
+	logInterval := time.NewTicker(30 * time.Second)
+	defer logInterval.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-logInterval.C:
+			l.logger.Info("[Light Client] Waiting for light client bootstrap", "root", l.trustedRoot)
+		default:
+		}
+		bootstrap, pid, err := l.rpc.SendLightClientBootstrapReq(ctx, l.trustedRoot)
+		if err != nil {
+			l.logger.Trace("[Light Client] Could not fetch bootstrap", "err", err)
+			time.Sleep(time.Second)
+			continue
+		}
+		store, err := NewStore(l.beaconConfig, genesisValidatorsRoot, l.trustedRoot, bootstrap)
+		if err != nil {
+			l.logger.Debug("[Light Client] Received invalid bootstrap", "peer", pid, "err", err)
+			l.rpc.BanPeer(pid)
+			continue
+		}
+		l.mu.Lock()
+		l.store = store
+		l.mu.Unlock()
+		l.logger.Info("[Light Client] Bootstrapped", "slot", bootstrap.Header.Beacon.Slot, "root", l.trustedRoot)
+		return nil
+	}
+}
+
+// catchUp fetches the best updates of the sync committee periods between the store and the wall clock.
+func (l *LightClient) catchUp(ctx context.Context) error {
+	for {
+		currentSlot := l.ethClock.GetCurrentSlot()
+		currentPeriod := l.beaconConfig.SyncCommitteePeriod(currentSlot)
+
+		l.mu.Lock()
+		storePeriod := l.beaconConfig.SyncCommitteePeriod(l.store.FinalizedHeader.Beacon.Slot)
+		nextKnown := l.store.isNextSyncCommitteeKnown()
+		l.mu.Unlock()
+		if nextKnown && storePeriod >= currentPeriod {
+			return nil
+		}
+
+		count := min(currentPeriod-storePeriod+1, maxRequestLightClientUpdates)
+		updates, pid, err := l.rpc.SendLightClientUpdatesByRangeReq(ctx, storePeriod, count)
+		if err != nil {
+			return err
+		}
+
+		l.mu.Lock()
+		for _, update := range updates {
+			if err := l.store.ProcessUpdate(update, currentSlot); err != nil {
+				l.logger.Debug("[Light Client] Discarded light client update", "peer", pid, "signatureSlot", update.SignatureSlot, "err", err)
+			}
+		}
+		progressed := l.beaconConfig.SyncCommitteePeriod(l.store.FinalizedHeader.Beacon.Slot) != storePeriod ||
+			l.store.isNextSyncCommitteeKnown() != nextKnown
+		l.mu.Unlock()
+		if !progressed {
+			return nil
+		}
+		l.onStoreChange(ctx)
+	}
+}
+
+func (l *LightClient) followGossip(ctx context.Context) {
+	filter := "light_client_*"
+Reconnect:
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		subscription, err := l.sentinel.SubscribeGossip(ctx, &sentinel.SubscriptionData{Filter: &filter}, grpc.WaitForReady(true))
+		if err != nil {
+			return
+		}
+
+		for {
+			data, err := subscription.Recv()
+			if err != nil {
+				if !grpcutil.IsRetryLater(err) && !grpcutil.IsEndOfStream(err) {
+					l.logger.Warn("[Light Client] Fatal error receiving gossip", "err", err)
+				}
+				// back off before subscribing again, a sentinel which keeps failing would make us spin otherwise
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(gossipReconnectBackoff):
+				}
+				continue Reconnect
+			}
+			if err := l.onGossip(data); err != nil {
+				l.logger.Debug("[Light Client] Discarded gossip update", "topic", data.Name, "err", err)
+				continue
+			}
+			if _, err := l.sentinel.PublishGossip(ctx, data); err != nil {
+				l.logger.Debug("[Light Client] Failed to publish gossip", "err", err)
+			}
+			l.onStoreChange(ctx)
+		}
+	}
+}
+
+func (l *LightClient) onGossip(data *sentinel.GossipData) error {
+	currentSlot := l.ethClock.GetCurrentSlot()
+	version := l.beaconConfig.GetCurrentStateVersion(l.ethClock.GetCurrentEpoch())
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch data.Name {
+	case gossip.TopicNameLightClientFinalityUpdate:
+		update := cltypes.NewLightClientFinalityUpdate(version)
+		if err := update.DecodeSSZ(data.Data, int(version)); err != nil {
+			return err
+		}
+		return l.store.ProcessFinalityUpdate(update, currentSlot)
+	case gossip.TopicNameLightClientOptimisticUpdate:
+		update := cltypes.NewLightClientOptimisticUpdate(version)
+		if err := update.DecodeSSZ(data.Data, int(version)); err != nil {
+			return err
+		}
+		return l.store.ProcessOptimisticUpdate(update, currentSlot)
+	}
+	return errors.New("unexpected gossip topic")
+}
+
+// onStoreChange sends the new forkchoice to the execution engine and refreshes the status advertised to peers.
+func (l *LightClient) onStoreChange(ctx context.Context) {
+	l.forkChoiceMu.Lock()
+	defer l.forkChoiceMu.Unlock()
+
+	l.mu.Lock()
+	finalized, head := l.store.FinalizedHeader, l.store.OptimisticHeader
+	l.mu.Unlock()
+
+	finalizedRoot, err := finalized.Beacon.HashSSZ()
+	if err != nil {
+		l.logger.Warn("[Light Client] Could not hash finalized header", "err", err)
+		return
+	}
+	headRoot, err := head.Beacon.HashSSZ()
+	if err != nil {
+		l.logger.Warn("[Light Client] Could not hash optimistic header", "err", err)
+		return
+	}
+	if err := l.rpc.SetStatus(finalizedRoot, finalized.Beacon.Slot/l.beaconConfig.SlotsPerEpoch, headRoot, head.Beacon.Slot); err != nil {
+		l.logger.Debug("[Light Client] Could not set status", "err", err)
+	}
+
+	// Execution payload headers are part of light client headers only since Capella.
+	if l.engine == nil || head.Version() < clparams.CapellaVersion {
+		return
+	}
+	headHash := head.ExecutionPayloadHeader.BlockHash
+	var finalizedHash libcommon.Hash
+	if finalized.Version() >= clparams.CapellaVersion {
+		finalizedHash = finalized.ExecutionPayloadHeader.BlockHash
+	}
+	if headHash == l.headHash && finalizedHash == l.finalizedHash {
+		return
+	}
+	if _, err := l.engine.ForkChoiceUpdate(ctx, finalizedHash, headHash, nil); err != nil {
+		l.logger.Warn("[Light Client] Could not update forkchoice", "head", headHash, "finalized", finalizedHash, "err", err)
+		return
+	}
+	l.headHash, l.finalizedHash = headHash, finalizedHash
+	l.logger.Info("[Light Client] Forkchoice updated", "slot", head.Beacon.Slot, "head", headHash,
+		"finalizedSlot", finalized.Beacon.Slot, "finalized", finalizedHash)
+}
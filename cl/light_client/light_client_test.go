@@ -0,0 +1,71 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package light_client
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	sentinel "github.com/erigontech/erigon-lib/gointerfaces/sentinelproto"
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+// brokenGossipSentinel - sentinel whose gossip streams fail right away
+type brokenGossipSentinel struct {
+	sentinel.SentinelClient
+	subscriptions atomic.Int32
+}
+
+type brokenGossipStream struct {
+	grpc.ClientStream
+}
+
+func (brokenGossipStream) Recv() (*sentinel.GossipData, error) {
+	return nil, errors.New("broken stream")
+}
+
+func (s *brokenGossipSentinel) SubscribeGossip(context.Context, *sentinel.SubscriptionData, ...grpc.CallOption) (sentinel.Sentinel_SubscribeGossipClient, error) {
+	s.subscriptions.Add(1)
+	return brokenGossipStream{}, nil
+}
+
+func TestFollowGossipBacksOff(t *testing.T) {
+	s := &brokenGossipSentinel{}
+	l := &LightClient{sentinel: s, logger: log.New()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		l.followGossip(ctx)
+		close(done)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, int32(1), s.subscriptions.Load())
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("followGossip did not return on context cancellation")
+	}
+}
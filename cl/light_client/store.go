@@ -0,0 +1,458 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package light_client
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Giulio2002/bls"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/fork"
+	"github.com/erigontech/erigon/cl/utils"
+)
+
+// Generalized indicies of the light client proofs (pre-Electra), as depth and subtree index.
+const (
+	finalizedRootDepth        = 6
+	finalizedRootIndex        = 41 // FINALIZED_ROOT_GINDEX = 105
+	nextSyncCommitteeDepth    = 5
+	nextSyncCommitteeIndex    = 23 // NEXT_SYNC_COMMITTEE_GINDEX = 55
+	currentSyncCommitteeDepth = 5
+	currentSyncCommitteeIndex = 22 // CURRENT_SYNC_COMMITTEE_GINDEX = 54
+	executionPayloadDepth     = 4
+	executionPayloadIndex     = 9 // EXECUTION_PAYLOAD_GINDEX = 25
+)
+
+var (
+	ErrInvalidHeader             = errors.New("invalid light client header")
+	ErrUntrustedBootstrap        = errors.New("bootstrap header does not match trusted block root")
+	ErrInvalidSyncCommitteeProof = errors.New("invalid sync committee merkle proof")
+	ErrInvalidFinalityProof      = errors.New("invalid finality merkle proof")
+	ErrNotEnoughParticipants     = errors.New("not enough sync committee participants")
+	ErrInvalidUpdateSlots        = errors.New("invalid light client update slots")
+	ErrInvalidUpdatePeriod       = errors.New("light client update skips a sync committee period")
+	ErrIrrelevantUpdate          = errors.New("light client update is not relevant")
+	ErrInvalidSignature          = errors.New("invalid sync committee signature")
+	ErrUnexpectedSyncCommittee   = errors.New("unexpected next sync committee in light client update")
+)
+
+// Store implements the LightClientStore of the Altair light client sync protocol.
+// It is not safe for concurrent use.
+type Store struct {
+	beaconConfig          *clparams.BeaconChainConfig
+	genesisValidatorsRoot libcommon.Hash
+
+	// Header that is finalized
+	FinalizedHeader *cltypes.LightClientHeader
+	// Sync committees corresponding to the finalized header
+	CurrentSyncCommittee *solid.SyncCommittee
+	NextSyncCommittee    *solid.SyncCommittee
+	// Best available header to switch finalized head to if we see nothing else
+	BestValidUpdate *cltypes.LightClientUpdate
+	// Most recent available reasonably-safe header
+	OptimisticHeader *cltypes.LightClientHeader
+	// Max number of active participants in a sync committee (used to calculate safety threshold)
+	PreviousMaxActiveParticipants uint64
+	CurrentMaxActiveParticipants  uint64
+}
+
+// def initialize_light_client_store(trusted_block_root: Root,
+//
+//	bootstrap: LightClientBootstrap) -> LightClientStore:
+//	assert is_valid_light_client_header(bootstrap.header)
+//	assert hash_tree_root(bootstrap.header.beacon) == trusted_block_root
+//
+//	assert is_valid_merkle_branch(
+//	    leaf=hash_tree_root(bootstrap.current_sync_committee),
+//	    branch=bootstrap.current_sync_committee_branch,
+//	    depth=floorlog2(CURRENT_SYNC_COMMITTEE_GINDEX),
+//	    index=get_subtree_index(CURRENT_SYNC_COMMITTEE_GINDEX),
+//	    root=bootstrap.header.beacon.state_root,
+//	)
+//
+//	return LightClientStore(...)
+func NewStore(beaconConfig *clparams.BeaconChainConfig, genesisValidatorsRoot, trustedBlockRoot libcommon.Hash, bootstrap *cltypes.LightClientBootstrap) (*Store, error) {
+	if !isValidLightClientHeader(bootstrap.Header) {
+		return nil, ErrInvalidHeader
+	}
+	root, err := bootstrap.Header.Beacon.HashSSZ()
+	if err != nil {
+		return nil, err
+	}
+	if root != trustedBlockRoot {
+		return nil, ErrUntrustedBootstrap
+	}
+	committeeRoot, err := bootstrap.CurrentSyncCommittee.HashSSZ()
+	if err != nil {
+		return nil, err
+	}
+	if !utils.IsValidMerkleBranch(committeeRoot, branchToHashes(bootstrap.CurrentSyncCommitteeBranch), currentSyncCommitteeDepth, currentSyncCommitteeIndex, bootstrap.Header.Beacon.Root) {
+		return nil, ErrInvalidSyncCommitteeProof
+	}
+	return &Store{
+		beaconConfig:          beaconConfig,
+		genesisValidatorsRoot: genesisValidatorsRoot,
+		FinalizedHeader:       bootstrap.Header,
+		CurrentSyncCommittee:  bootstrap.CurrentSyncCommittee,
+		NextSyncCommittee:     &solid.SyncCommittee{},
+		OptimisticHeader:      bootstrap.Header,
+	}, nil
+}
+
+// ProcessUpdate implements process_light_client_update.
+func (s *Store) ProcessUpdate(update *cltypes.LightClientUpdate, currentSlot uint64) error {
+	if err := s.validateUpdate(update, currentSlot); err != nil {
+		return err
+	}
+	participants := uint64(update.SyncAggregate.Sum())
+	// Update the best update in case we have to force-update to it if the timeout elapses
+	if s.BestValidUpdate == nil || s.isBetterUpdate(update, s.BestValidUpdate) {
+		s.BestValidUpdate = update
+	}
+	// Track the maximum number of active participants in the committee signatures
+	s.CurrentMaxActiveParticipants = max(s.CurrentMaxActiveParticipants, participants)
+	// Update the optimistic header
+	if participants > s.safetyThreshold() && update.AttestedHeader.Beacon.Slot > s.OptimisticHeader.Beacon.Slot {
+		s.OptimisticHeader = update.AttestedHeader
+	}
+	// Update finalized header
+	updateHasFinalizedNextSyncCommittee := !s.isNextSyncCommitteeKnown() &&
+		isSyncCommitteeUpdate(update) && isFinalityUpdate(update) &&
+		s.beaconConfig.SyncCommitteePeriod(update.FinalizedHeader.Beacon.Slot) == s.beaconConfig.SyncCommitteePeriod(update.AttestedHeader.Beacon.Slot)
+	if participants*3 >= uint64(len(update.SyncAggregate.SyncCommiteeBits))*8*2 &&
+		(update.FinalizedHeader.Beacon.Slot > s.FinalizedHeader.Beacon.Slot || updateHasFinalizedNextSyncCommittee) {
+		// Normal update through 2/3 threshold
+		s.applyUpdate(update)
+		s.BestValidUpdate = nil
+	}
+	return nil
+}
+
+// ProcessFinalityUpdate implements process_light_client_finality_update.
+func (s *Store) ProcessFinalityUpdate(finalityUpdate *cltypes.LightClientFinalityUpdate, currentSlot uint64) error {
+	update := cltypes.NewLightClientUpdate(finalityUpdate.AttestedHeader.Version())
+	update.AttestedHeader = finalityUpdate.AttestedHeader
+	update.FinalizedHeader = finalityUpdate.FinalizedHeader
+	update.FinalityBranch = finalityUpdate.FinalityBranch
+	update.SyncAggregate = finalityUpdate.SyncAggregate
+	update.SignatureSlot = finalityUpdate.SignatureSlot
+	return s.ProcessUpdate(update, currentSlot)
+}
+
+// ProcessOptimisticUpdate implements process_light_client_optimistic_update.
+func (s *Store) ProcessOptimisticUpdate(optimisticUpdate *cltypes.LightClientOptimisticUpdate, currentSlot uint64) error {
+	update := cltypes.NewLightClientUpdate(optimisticUpdate.AttestedHeader.Version())
+	update.AttestedHeader = optimisticUpdate.AttestedHeader
+	update.SyncAggregate = optimisticUpdate.SyncAggregate
+	update.SignatureSlot = optimisticUpdate.SignatureSlot
+	return s.ProcessUpdate(update, currentSlot)
+}
+
+// def process_light_client_store_force_update(store: LightClientStore, current_slot: Slot) -> None:
+//
+//	if (
+//	    current_slot > store.finalized_header.beacon.slot + UPDATE_TIMEOUT
+//	    and store.best_valid_update is not None
+//	):
+//	    # Forced best update when the update timeout has elapsed.
+//	    # Because the apply logic waits for `finalized_header.beacon.slot` to indicate sync committee finality,
+//	    # the `attested_header` may be treated as `finalized_header` in extended periods of non-finality
+//	    # to guarantee progression into later sync committee periods according to `is_better_update`.
+//	    if store.best_valid_update.finalized_header.beacon.slot <= store.finalized_header.beacon.slot:
+//	        store.best_valid_update.finalized_header = store.best_valid_update.attested_header
+//	    apply_light_client_update(store, store.best_valid_update)
+//	    store.best_valid_update = None
+func (s *Store) ProcessForceUpdate(currentSlot uint64) bool {
+	updateTimeout := s.beaconConfig.SlotsPerEpoch * s.beaconConfig.EpochsPerSyncCommitteePeriod
+	if currentSlot <= s.FinalizedHeader.Beacon.Slot+updateTimeout || s.BestValidUpdate == nil {
+		return false
+	}
+	if s.BestValidUpdate.FinalizedHeader.Beacon.Slot <= s.FinalizedHeader.Beacon.Slot {
+		s.BestValidUpdate.FinalizedHeader = s.BestValidUpdate.AttestedHeader
+	}
+	s.applyUpdate(s.BestValidUpdate)
+	s.BestValidUpdate = nil
+	return true
+}
+
+// def validate_light_client_update(store: LightClientStore,
+//
+//	update: LightClientUpdate,
+//	current_slot: Slot,
+//	genesis_validators_root: Root) -> None:
+func (s *Store) validateUpdate(update *cltypes.LightClientUpdate, currentSlot uint64) error {
+	// Verify sync committee has sufficient participants
+	if uint64(update.SyncAggregate.Sum()) < s.beaconConfig.MinSyncCommitteeParticipants {
+		return ErrNotEnoughParticipants
+	}
+	// Verify update does not skip a sync committee period
+	if !isValidLightClientHeader(update.AttestedHeader) {
+		return ErrInvalidHeader
+	}
+	attestedSlot := update.AttestedHeader.Beacon.Slot
+	finalizedSlot := update.FinalizedHeader.Beacon.Slot
+	if !(currentSlot >= update.SignatureSlot && update.SignatureSlot > attestedSlot && attestedSlot >= finalizedSlot) {
+		return fmt.Errorf("%w: current %d, signature %d, attested %d, finalized %d", ErrInvalidUpdateSlots, currentSlot, update.SignatureSlot, attestedSlot, finalizedSlot)
+	}
+	storePeriod := s.beaconConfig.SyncCommitteePeriod(s.FinalizedHeader.Beacon.Slot)
+	signaturePeriod := s.beaconConfig.SyncCommitteePeriod(update.SignatureSlot)
+	if s.isNextSyncCommitteeKnown() {
+		if signaturePeriod != storePeriod && signaturePeriod != storePeriod+1 {
+			return ErrInvalidUpdatePeriod
+		}
+	} else if signaturePeriod != storePeriod {
+		return ErrInvalidUpdatePeriod
+	}
+
+	// Verify update is relevant
+	attestedPeriod := s.beaconConfig.SyncCommitteePeriod(attestedSlot)
+	updateHasNextSyncCommittee := !s.isNextSyncCommitteeKnown() && isSyncCommitteeUpdate(update) && attestedPeriod == storePeriod
+	if attestedSlot <= s.FinalizedHeader.Beacon.Slot && !updateHasNextSyncCommittee {
+		return ErrIrrelevantUpdate
+	}
+
+	// Verify that the `finality_branch`, if present, confirms `finalized_header`
+	// to match the finalized checkpoint root saved in the state of `attested_header`.
+	// Note that the genesis finalized checkpoint root is represented as a zero hash.
+	if !isFinalityUpdate(update) {
+		if *update.FinalizedHeader.Beacon != (cltypes.BeaconBlockHeader{}) {
+			return ErrInvalidFinalityProof
+		}
+	} else {
+		var finalizedRoot libcommon.Hash
+		if finalizedSlot != s.beaconConfig.GenesisSlot {
+			if !isValidLightClientHeader(update.FinalizedHeader) {
+				return ErrInvalidHeader
+			}
+			var err error
+			if finalizedRoot, err = update.FinalizedHeader.Beacon.HashSSZ(); err != nil {
+				return err
+			}
+		}
+		if !utils.IsValidMerkleBranch(finalizedRoot, branchToHashes(update.FinalityBranch), finalizedRootDepth, finalizedRootIndex, update.AttestedHeader.Beacon.Root) {
+			return ErrInvalidFinalityProof
+		}
+	}
+
+	// Verify that the `next_sync_committee`, if present, actually is the next sync committee saved in the
+	// state of the `attested_header`
+	if !isSyncCommitteeUpdate(update) {
+		if !update.NextSyncCommittee.Equal(&solid.SyncCommittee{}) {
+			return ErrUnexpectedSyncCommittee
+		}
+	} else {
+		if attestedPeriod == storePeriod && s.isNextSyncCommitteeKnown() && !update.NextSyncCommittee.Equal(s.NextSyncCommittee) {
+			return ErrUnexpectedSyncCommittee
+		}
+		committeeRoot, err := update.NextSyncCommittee.HashSSZ()
+		if err != nil {
+			return err
+		}
+		if !utils.IsValidMerkleBranch(committeeRoot, branchToHashes(update.NextSyncCommitteeBranch), nextSyncCommitteeDepth, nextSyncCommitteeIndex, update.AttestedHeader.Beacon.Root) {
+			return ErrInvalidSyncCommitteeProof
+		}
+	}
+
+	// Verify sync committee aggregate signature
+	syncCommittee := s.NextSyncCommittee
+	if signaturePeriod == storePeriod {
+		syncCommittee = s.CurrentSyncCommittee
+	}
+	committee := syncCommittee.GetCommittee()
+	participantPubkeys := make([][]byte, 0, len(committee))
+	for i := range committee {
+		if update.SyncAggregate.IsSet(uint64(i)) {
+			participantPubkeys = append(participantPubkeys, committee[i][:])
+		}
+	}
+	forkVersionSlot := max(update.SignatureSlot, 1) - 1
+	forkVersion := s.beaconConfig.GetForkVersionByVersion(s.beaconConfig.GetCurrentStateVersion(forkVersionSlot / s.beaconConfig.SlotsPerEpoch))
+	domain, err := fork.ComputeDomain(s.beaconConfig.DomainSyncCommittee[:], utils.Uint32ToBytes4(forkVersion), s.genesisValidatorsRoot)
+	if err != nil {
+		return err
+	}
+	signingRoot, err := fork.ComputeSigningRoot(update.AttestedHeader.Beacon, domain)
+	if err != nil {
+		return err
+	}
+	valid, err := bls.VerifyAggregate(update.SyncAggregate.SyncCommiteeSignature[:], signingRoot[:], participantPubkeys)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// def apply_light_client_update(store: LightClientStore, update: LightClientUpdate) -> None:
+//
+//	store_period = compute_sync_committee_period_at_slot(store.finalized_header.beacon.slot)
+//	update_finalized_period = compute_sync_committee_period_at_slot(update.finalized_header.beacon.slot)
+//	if not is_next_sync_committee_known(store):
+//	    assert update_finalized_period == store_period
+//	    store.next_sync_committee = update.next_sync_committee
+//	elif update_finalized_period == store_period + 1:
+//	    store.current_sync_committee = store.next_sync_committee
+//	    store.next_sync_committee = update.next_sync_committee
+//	    store.previous_max_active_participants = store.current_max_active_participants
+//	    store.current_max_active_participants = 0
+//	if update.finalized_header.beacon.slot > store.finalized_header.beacon.slot:
+//	    store.finalized_header = update.finalized_header
+//	    if store.finalized_header.beacon.slot > store.optimistic_header.beacon.slot:
+//	        store.optimistic_header = store.finalized_header
+func (s *Store) applyUpdate(update *cltypes.LightClientUpdate) {
+	storePeriod := s.beaconConfig.SyncCommitteePeriod(s.FinalizedHeader.Beacon.Slot)
+	finalizedPeriod := s.beaconConfig.SyncCommitteePeriod(update.FinalizedHeader.Beacon.Slot)
+	if !s.isNextSyncCommitteeKnown() {
+		if finalizedPeriod != storePeriod {
+			return
+		}
+		s.NextSyncCommittee = update.NextSyncCommittee
+	} else if finalizedPeriod == storePeriod+1 {
+		s.CurrentSyncCommittee = s.NextSyncCommittee
+		s.NextSyncCommittee = update.NextSyncCommittee
+		s.PreviousMaxActiveParticipants = s.CurrentMaxActiveParticipants
+		s.CurrentMaxActiveParticipants = 0
+	}
+	if update.FinalizedHeader.Beacon.Slot > s.FinalizedHeader.Beacon.Slot {
+		s.FinalizedHeader = update.FinalizedHeader
+		if s.FinalizedHeader.Beacon.Slot > s.OptimisticHeader.Beacon.Slot {
+			s.OptimisticHeader = s.FinalizedHeader
+		}
+	}
+}
+
+// isBetterUpdate implements is_better_update.
+func (s *Store) isBetterUpdate(newUpdate, oldUpdate *cltypes.LightClientUpdate) bool {
+	// Compare supermajority (> 2/3) sync committee participation
+	maxActiveParticipants := len(newUpdate.SyncAggregate.SyncCommiteeBits) * 8
+	newActiveParticipants := newUpdate.SyncAggregate.Sum()
+	oldActiveParticipants := oldUpdate.SyncAggregate.Sum()
+	newHasSupermajority := newActiveParticipants*3 >= maxActiveParticipants*2
+	oldHasSupermajority := oldActiveParticipants*3 >= maxActiveParticipants*2
+	if newHasSupermajority != oldHasSupermajority {
+		return newHasSupermajority
+	}
+	if !newHasSupermajority && newActiveParticipants != oldActiveParticipants {
+		return newActiveParticipants > oldActiveParticipants
+	}
+
+	// Compare presence of relevant sync committee
+	newHasRelevantSyncCommittee := isSyncCommitteeUpdate(newUpdate) &&
+		s.beaconConfig.SyncCommitteePeriod(newUpdate.AttestedHeader.Beacon.Slot) == s.beaconConfig.SyncCommitteePeriod(newUpdate.SignatureSlot)
+	oldHasRelevantSyncCommittee := isSyncCommitteeUpdate(oldUpdate) &&
+		s.beaconConfig.SyncCommitteePeriod(oldUpdate.AttestedHeader.Beacon.Slot) == s.beaconConfig.SyncCommitteePeriod(oldUpdate.SignatureSlot)
+	if newHasRelevantSyncCommittee != oldHasRelevantSyncCommittee {
+		return newHasRelevantSyncCommittee
+	}
+
+	// Compare indication of any finality
+	newHasFinality := isFinalityUpdate(newUpdate)
+	oldHasFinality := isFinalityUpdate(oldUpdate)
+	if newHasFinality != oldHasFinality {
+		return newHasFinality
+	}
+
+	// Compare sync committee finality
+	if newHasFinality {
+		newHasSyncCommitteeFinality := s.beaconConfig.SyncCommitteePeriod(newUpdate.FinalizedHeader.Beacon.Slot) == s.beaconConfig.SyncCommitteePeriod(newUpdate.AttestedHeader.Beacon.Slot)
+		oldHasSyncCommitteeFinality := s.beaconConfig.SyncCommitteePeriod(oldUpdate.FinalizedHeader.Beacon.Slot) == s.beaconConfig.SyncCommitteePeriod(oldUpdate.AttestedHeader.Beacon.Slot)
+		if newHasSyncCommitteeFinality != oldHasSyncCommitteeFinality {
+			return newHasSyncCommitteeFinality
+		}
+	}
+
+	// Tiebreaker 1: Sync committee participation beyond supermajority
+	if newActiveParticipants != oldActiveParticipants {
+		return newActiveParticipants > oldActiveParticipants
+	}
+
+	// Tiebreaker 2: Prefer older data (fewer changes to best)
+	if newUpdate.AttestedHeader.Beacon.Slot != oldUpdate.AttestedHeader.Beacon.Slot {
+		return newUpdate.AttestedHeader.Beacon.Slot < oldUpdate.AttestedHeader.Beacon.Slot
+	}
+	return newUpdate.SignatureSlot < oldUpdate.SignatureSlot
+}
+
+func (s *Store) isNextSyncCommitteeKnown() bool {
+	return !s.NextSyncCommittee.Equal(&solid.SyncCommittee{})
+}
+
+func (s *Store) safetyThreshold() uint64 {
+	return max(s.PreviousMaxActiveParticipants, s.CurrentMaxActiveParticipants) / 2
+}
+
+// def is_valid_light_client_header(header: LightClientHeader) -> bool:
+//
+//	epoch = compute_epoch_at_slot(header.beacon.slot)
+//	if epoch < CAPELLA_FORK_EPOCH:
+//	    return (header.execution == ExecutionPayloadHeader() and header.execution_branch == ExecutionBranch())
+//	return is_valid_merkle_branch(
+//	    leaf=get_lc_execution_root(header),
+//	    branch=header.execution_branch,
+//	    depth=floorlog2(EXECUTION_PAYLOAD_GINDEX),
+//	    index=get_subtree_index(EXECUTION_PAYLOAD_GINDEX),
+//	    root=header.beacon.body_root,
+//	)
+func isValidLightClientHeader(header *cltypes.LightClientHeader) bool {
+	if header == nil || header.Beacon == nil {
+		return false
+	}
+	if header.Version() < clparams.CapellaVersion {
+		return true
+	}
+	if header.ExecutionPayloadHeader == nil || header.ExecutionBranch == nil {
+		return false
+	}
+	executionRoot, err := header.ExecutionPayloadHeader.HashSSZ()
+	if err != nil {
+		return false
+	}
+	return utils.IsValidMerkleBranch(executionRoot, branchToHashes(header.ExecutionBranch), executionPayloadDepth, executionPayloadIndex, header.Beacon.BodyRoot)
+}
+
+func isSyncCommitteeUpdate(update *cltypes.LightClientUpdate) bool {
+	return !isEmptyBranch(update.NextSyncCommitteeBranch)
+}
+
+func isFinalityUpdate(update *cltypes.LightClientUpdate) bool {
+	return !isEmptyBranch(update.FinalityBranch)
+}
+
+func isEmptyBranch(branch solid.HashVectorSSZ) bool {
+	for i := 0; i < branch.Length(); i++ {
+		if branch.Get(i) != (libcommon.Hash{}) {
+			return false
+		}
+	}
+	return true
+}
+
+func branchToHashes(branch solid.HashVectorSSZ) []libcommon.Hash {
+	hashes := make([]libcommon.Hash, branch.Length())
+	for i := range hashes {
+		hashes[i] = branch.Get(i)
+	}
+	return hashes
+}
@@ -0,0 +1,218 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package light_client
+
+import (
+	"testing"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/cl/antiquary/tests"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/cltypes/lightclient_utils"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/fork"
+	"github.com/erigontech/erigon/cl/utils"
+)
+
+// participants signing the test updates, above the 2/3 supermajority of the sync committee.
+const testParticipants = 400
+
+type testChain struct {
+	cfg                   *clparams.BeaconChainConfig
+	genesisValidatorsRoot libcommon.Hash
+	keys                  []*bls.PrivateKey
+	trustedRoot           libcommon.Hash
+	bootstrap             *cltypes.LightClientBootstrap
+	update                *cltypes.LightClientUpdate
+}
+
+func hashVector(branch [][32]byte) solid.HashVectorSSZ {
+	v := solid.NewHashVector(len(branch))
+	for i := range branch {
+		v.Set(i, branch[i])
+	}
+	return v
+}
+
+// newTestChain builds a bootstrap at the first block and an update attesting the second block, which finalizes
+// the bootstrap block and carries the next sync committee.
+func newTestChain(t *testing.T) *testChain {
+	cfg := &clparams.MainnetBeaconConfig
+	blocks, _, postState := tests.GetCapellaRandom()
+
+	keys := make([]*bls.PrivateKey, cfg.SyncCommitteeSize)
+	pubkeys := make([]libcommon.Bytes48, cfg.SyncCommitteeSize)
+	for i := range keys {
+		var err error
+		keys[i], err = bls.GenerateKey()
+		require.NoError(t, err)
+		copy(pubkeys[i][:], bls.CompressPublicKey(keys[i].PublicKey()))
+	}
+	committee := solid.NewSyncCommitteeFromParameters(pubkeys, pubkeys[0])
+
+	// Bootstrap: block 0 with our sync committee in its state.
+	bootState, err := postState.Copy()
+	require.NoError(t, err)
+	bootState.SetCurrentSyncCommittee(committee)
+	bootHeader, err := lightclient_utils.BlockToLightClientHeader(blocks[0])
+	require.NoError(t, err)
+	bootHeader.Beacon.Root, err = bootState.HashSSZ()
+	require.NoError(t, err)
+	currentBranch, err := bootState.CurrentSyncCommitteeBranch()
+	require.NoError(t, err)
+	trustedRoot, err := bootHeader.Beacon.HashSSZ()
+	require.NoError(t, err)
+
+	// Update: block 1 attested, finalizing block 0.
+	attestedState, err := bootState.Copy()
+	require.NoError(t, err)
+	attestedState.SetNextSyncCommittee(committee)
+	attestedState.SetFinalizedCheckpoint(solid.Checkpoint{Epoch: bootHeader.Beacon.Slot / cfg.SlotsPerEpoch, Root: trustedRoot})
+	attestedHeader, err := lightclient_utils.BlockToLightClientHeader(blocks[1])
+	require.NoError(t, err)
+	attestedHeader.Beacon.Root, err = attestedState.HashSSZ()
+	require.NoError(t, err)
+	nextBranch, err := attestedState.NextSyncCommitteeBranch()
+	require.NoError(t, err)
+	finalityBranch, err := attestedState.FinalityRootBranch()
+	require.NoError(t, err)
+
+	c := &testChain{
+		cfg:                   cfg,
+		genesisValidatorsRoot: postState.GenesisValidatorsRoot(),
+		keys:                  keys,
+		trustedRoot:           trustedRoot,
+		bootstrap: &cltypes.LightClientBootstrap{
+			Header:                     bootHeader,
+			CurrentSyncCommittee:       committee,
+			CurrentSyncCommitteeBranch: hashVector(currentBranch),
+		},
+		update: &cltypes.LightClientUpdate{
+			AttestedHeader:          attestedHeader,
+			NextSyncCommittee:       committee,
+			NextSyncCommitteeBranch: hashVector(nextBranch),
+			FinalizedHeader:         bootHeader,
+			FinalityBranch:          hashVector(finalityBranch),
+			SignatureSlot:           attestedHeader.Beacon.Slot + 1,
+		},
+	}
+	c.update.SyncAggregate = c.sign(t, attestedHeader.Beacon, c.update.SignatureSlot, testParticipants)
+	return c
+}
+
+func (c *testChain) sign(t *testing.T, header *cltypes.BeaconBlockHeader, signatureSlot uint64, participants int) *cltypes.SyncAggregate {
+	forkVersion := c.cfg.GetForkVersionByVersion(c.cfg.GetCurrentStateVersion((signatureSlot - 1) / c.cfg.SlotsPerEpoch))
+	domain, err := fork.ComputeDomain(c.cfg.DomainSyncCommittee[:], utils.Uint32ToBytes4(forkVersion), c.genesisValidatorsRoot)
+	require.NoError(t, err)
+	signingRoot, err := fork.ComputeSigningRoot(header, domain)
+	require.NoError(t, err)
+
+	aggregate := &cltypes.SyncAggregate{}
+	signatures := make([][]byte, 0, participants)
+	for i := 0; i < participants; i++ {
+		aggregate.SyncCommiteeBits[i/8] |= 1 << (i % 8)
+		signatures = append(signatures, c.keys[i].Sign(signingRoot[:]).Bytes())
+	}
+	signature, err := bls.AggregateSignatures(signatures)
+	require.NoError(t, err)
+	copy(aggregate.SyncCommiteeSignature[:], signature)
+	return aggregate
+}
+
+func TestStoreBootstrap(t *testing.T) {
+	c := newTestChain(t)
+
+	store, err := NewStore(c.cfg, c.genesisValidatorsRoot, c.trustedRoot, c.bootstrap)
+	require.NoError(t, err)
+	require.Equal(t, c.bootstrap.Header, store.FinalizedHeader)
+	require.Equal(t, c.bootstrap.Header, store.OptimisticHeader)
+	require.False(t, store.isNextSyncCommitteeKnown())
+
+	_, err = NewStore(c.cfg, c.genesisValidatorsRoot, libcommon.Hash{1}, c.bootstrap)
+	require.ErrorIs(t, err, ErrUntrustedBootstrap)
+
+	c.bootstrap.CurrentSyncCommitteeBranch.Set(0, libcommon.Hash{1})
+	_, err = NewStore(c.cfg, c.genesisValidatorsRoot, c.trustedRoot, c.bootstrap)
+	require.ErrorIs(t, err, ErrInvalidSyncCommitteeProof)
+}
+
+func TestStoreProcessUpdate(t *testing.T) {
+	c := newTestChain(t)
+	store, err := NewStore(c.cfg, c.genesisValidatorsRoot, c.trustedRoot, c.bootstrap)
+	require.NoError(t, err)
+
+	require.NoError(t, store.ProcessUpdate(c.update, c.update.SignatureSlot))
+	require.True(t, store.isNextSyncCommitteeKnown())
+	require.Equal(t, c.update.AttestedHeader, store.OptimisticHeader)
+	require.Equal(t, c.bootstrap.Header, store.FinalizedHeader)
+	require.Nil(t, store.BestValidUpdate)
+	require.Equal(t, uint64(testParticipants), store.CurrentMaxActiveParticipants)
+}
+
+func TestStoreRejectsInvalidUpdates(t *testing.T) {
+	c := newTestChain(t)
+	newStore := func() *Store {
+		store, err := NewStore(c.cfg, c.genesisValidatorsRoot, c.trustedRoot, c.bootstrap)
+		require.NoError(t, err)
+		return store
+	}
+
+	// signed in the future
+	require.ErrorIs(t, newStore().ProcessUpdate(c.update, c.update.SignatureSlot-1), ErrInvalidUpdateSlots)
+
+	// a participant bit without its signature
+	update := *c.update
+	aggregate := *c.update.SyncAggregate
+	aggregate.SyncCommiteeBits[testParticipants/8] |= 1 << (testParticipants % 8)
+	update.SyncAggregate = &aggregate
+	require.ErrorIs(t, newStore().ProcessUpdate(&update, update.SignatureSlot), ErrInvalidSignature)
+
+	// finalized header not in the attested state
+	update = *c.update
+	update.FinalityBranch = hashVector(make([][32]byte, cltypes.FinalizedBranchSize))
+	update.FinalityBranch.Set(0, libcommon.Hash{1})
+	require.ErrorIs(t, newStore().ProcessUpdate(&update, update.SignatureSlot), ErrInvalidFinalityProof)
+
+	// next sync committee not in the attested state
+	update = *c.update
+	update.NextSyncCommittee = c.update.NextSyncCommittee.Copy()
+	update.NextSyncCommittee[0] ^= 0xff
+	require.ErrorIs(t, newStore().ProcessUpdate(&update, update.SignatureSlot), ErrInvalidSyncCommitteeProof)
+}
+
+func TestStoreProcessOptimisticUpdate(t *testing.T) {
+	c := newTestChain(t)
+	store, err := NewStore(c.cfg, c.genesisValidatorsRoot, c.trustedRoot, c.bootstrap)
+	require.NoError(t, err)
+
+	optimistic := &cltypes.LightClientOptimisticUpdate{
+		AttestedHeader: c.update.AttestedHeader,
+		SyncAggregate:  c.update.SyncAggregate,
+		SignatureSlot:  c.update.SignatureSlot,
+	}
+	require.NoError(t, store.ProcessOptimisticUpdate(optimistic, optimistic.SignatureSlot))
+	require.Equal(t, c.update.AttestedHeader, store.OptimisticHeader)
+	require.Equal(t, c.bootstrap.Header, store.FinalizedHeader)
+	require.False(t, store.isNextSyncCommitteeKnown())
+	// kept for a forced update in case finality does not come
+	require.NotNil(t, store.BestValidUpdate)
+}
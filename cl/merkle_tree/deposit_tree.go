@@ -0,0 +1,130 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package merkle_tree
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/cl/utils"
+)
+
+// DepositContractDepth is the depth of the deposit contract merkle tree.
+const DepositContractDepth = 32
+
+var ErrDepositTreeFull = errors.New("deposit tree is full")
+
+// DepositTree is the incremental merkle tree of the deposit contract. it only keeps the left-most branch, which
+// is exactly the set of finalized subtree roots described by EIP-4881, so it can be turned into a snapshot at any time.
+type DepositTree struct {
+	branch [DepositContractDepth]common.Hash
+	count  uint64
+}
+
+// NewDepositTreeFromSnapshot restores a deposit tree from the finalized roots of an EIP-4881 snapshot.
+func NewDepositTreeFromSnapshot(finalized []common.Hash, depositCount uint64) (*DepositTree, error) {
+	t := &DepositTree{count: depositCount}
+	idx := 0
+	for h := DepositContractDepth - 1; h >= 0; h-- {
+		if (depositCount>>h)&1 == 0 {
+			continue
+		}
+		if idx >= len(finalized) {
+			return nil, errors.New("not enough finalized roots for deposit count")
+		}
+		t.branch[h] = finalized[idx]
+		idx++
+	}
+	if idx != len(finalized) {
+		return nil, errors.New("too many finalized roots for deposit count")
+	}
+	return t, nil
+}
+
+// NewDepositTreeFromProof restores the deposit tree holding the first index deposits from the merkle proof of the
+// deposit at position index: the left siblings along that proof are exactly the complete subtrees preceding it.
+func NewDepositTreeFromProof(index uint64, proof []common.Hash) (*DepositTree, error) {
+	if len(proof) < DepositContractDepth {
+		return nil, errors.New("deposit proof is too short")
+	}
+	t := &DepositTree{count: index}
+	for h := 0; h < DepositContractDepth; h++ {
+		if (index>>h)&1 == 1 {
+			t.branch[h] = proof[h]
+		}
+	}
+	return t, nil
+}
+
+// Push appends a deposit data root to the tree.
+func (t *DepositTree) Push(leaf common.Hash) error {
+	if t.count >= 1<<DepositContractDepth-1 {
+		return ErrDepositTreeFull
+	}
+	t.count++
+	size := t.count
+	node := leaf
+	for h := 0; h < DepositContractDepth; h++ {
+		if size&1 == 1 {
+			t.branch[h] = node
+			return nil
+		}
+		node = utils.Sha256(t.branch[h][:], node[:])
+		size >>= 1
+	}
+	return nil
+}
+
+// Count returns the number of deposits in the tree.
+func (t *DepositTree) Count() uint64 {
+	return t.count
+}
+
+// Root returns the deposit root, as returned by get_deposit_root in the deposit contract (length mixed in).
+func (t *DepositTree) Root() common.Hash {
+	var node common.Hash
+	size := t.count
+	for h := 0; h < DepositContractDepth; h++ {
+		if size&1 == 1 {
+			node = utils.Sha256(t.branch[h][:], node[:])
+		} else {
+			node = utils.Sha256(node[:], ZeroHashes[h][:])
+		}
+		size >>= 1
+	}
+	var length [32]byte
+	binary.LittleEndian.PutUint64(length[:], t.count)
+	return utils.Sha256(node[:], length[:])
+}
+
+// Finalized returns the roots of the complete subtrees, from the left-most (largest) to the right-most one.
+func (t *DepositTree) Finalized() []common.Hash {
+	finalized := make([]common.Hash, 0, DepositContractDepth)
+	for h := DepositContractDepth - 1; h >= 0; h-- {
+		if (t.count>>h)&1 == 1 {
+			finalized = append(finalized, t.branch[h])
+		}
+	}
+	return finalized
+}
+
+// Copy returns a copy of the tree.
+func (t *DepositTree) Copy() *DepositTree {
+	cpy := *t
+	return &cpy
+}
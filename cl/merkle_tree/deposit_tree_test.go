@@ -0,0 +1,97 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package merkle_tree_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/cl/merkle_tree"
+	"github.com/erigontech/erigon/cl/utils"
+)
+
+func depositRootFromLeaves(t *testing.T, leaves [][32]byte) common.Hash {
+	elements := make([][32]byte, len(leaves))
+	copy(elements, leaves)
+	root, err := merkle_tree.MerkleizeVector(elements, 1<<merkle_tree.DepositContractDepth)
+	require.NoError(t, err)
+	var length [32]byte
+	binary.LittleEndian.PutUint64(length[:], uint64(len(leaves)))
+	return utils.Sha256(root[:], length[:])
+}
+
+func TestDepositTreeRoot(t *testing.T) {
+	tree := &merkle_tree.DepositTree{}
+	leaves := [][32]byte{}
+	require.Equal(t, depositRootFromLeaves(t, leaves), tree.Root())
+	for i := 0; i < 37; i++ {
+		leaf := utils.Sha256([]byte{byte(i)})
+		leaves = append(leaves, leaf)
+		require.NoError(t, tree.Push(leaf))
+		require.Equal(t, uint64(len(leaves)), tree.Count())
+		require.Equal(t, depositRootFromLeaves(t, leaves), tree.Root(), "deposit count %d", len(leaves))
+	}
+}
+
+func TestDepositTreeSnapshotRoundTrip(t *testing.T) {
+	tree := &merkle_tree.DepositTree{}
+	for i := 0; i < 21; i++ {
+		require.NoError(t, tree.Push(utils.Sha256([]byte{byte(i)})))
+	}
+	finalized := tree.Finalized()
+	// 21 = 0b10101, so we expect one finalized root per set bit.
+	require.Len(t, finalized, 3)
+
+	restored, err := merkle_tree.NewDepositTreeFromSnapshot(finalized, tree.Count())
+	require.NoError(t, err)
+	require.Equal(t, tree.Root(), restored.Root())
+
+	// Both trees must keep agreeing once new deposits are pushed.
+	for i := 21; i < 40; i++ {
+		leaf := utils.Sha256([]byte{byte(i)})
+		require.NoError(t, tree.Push(leaf))
+		require.NoError(t, restored.Push(leaf))
+		require.Equal(t, tree.Root(), restored.Root())
+	}
+
+	_, err = merkle_tree.NewDepositTreeFromSnapshot(finalized[:2], 21)
+	require.Error(t, err)
+}
+
+func TestDepositTreeFromProof(t *testing.T) {
+	tree := &merkle_tree.DepositTree{}
+	for i := 0; i < 13; i++ {
+		require.NoError(t, tree.Push(utils.Sha256([]byte{byte(i)})))
+	}
+	// 13 = 0b1101, the left siblings of the next deposit are the finalized roots at heights 3, 2 and 0.
+	finalized := tree.Finalized()
+	proof := make([]common.Hash, merkle_tree.DepositContractDepth+1)
+	for h := range proof {
+		proof[h] = utils.Sha256([]byte{0xff, byte(h)})
+	}
+	proof[3], proof[2], proof[0] = finalized[0], finalized[1], finalized[2]
+
+	restored, err := merkle_tree.NewDepositTreeFromProof(tree.Count(), proof)
+	require.NoError(t, err)
+	require.Equal(t, tree.Root(), restored.Root())
+
+	_, err = merkle_tree.NewDepositTreeFromProof(tree.Count(), proof[:10])
+	require.Error(t, err)
+}
@@ -1,7 +1,9 @@
 package monitor
 
 import (
+	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
 	"github.com/erigontech/erigon/cl/phase1/core/state"
 )
 
@@ -10,6 +12,19 @@ type ValidatorMonitor interface {
 	ObserveValidator(vid uint64)
 	RemoveValidator(vid uint64)
 	OnNewBlock(state *state.CachingBeaconState, block *cltypes.BeaconBlock) error
+	// SetForkChoiceReader sets where the attestation rewards of the observed validators are computed from.
+	SetForkChoiceReader(reader ForkChoiceReader)
+	// GetValidatorPerformance returns the performance of the given validators (all the observed ones if empty)
+	// over the epochs [fromEpoch, toEpoch], sorted by validator and epoch.
+	GetValidatorPerformance(validators []uint64, fromEpoch, toEpoch uint64) []ValidatorPerformance
+}
+
+// ForkChoiceReader is the fork choice data the validator monitor computes attestation rewards from.
+type ForkChoiceReader interface {
+	GetValidatorSet(blockRoot common.Hash) (*solid.ValidatorSet, error)
+	GetInactivitiesScores(blockRoot common.Hash) (solid.Uint64ListSSZ, error)
+	GetPreviousParticipationIndicies(blockRoot common.Hash) (*solid.ParticipationBitList, error)
+	GetFinalityCheckpoints(blockRoot common.Hash) (solid.Checkpoint, solid.Checkpoint, solid.Checkpoint, bool)
 }
 
 type dummyValdatorMonitor struct{}
@@ -21,3 +36,9 @@ func (d *dummyValdatorMonitor) RemoveValidator(vid uint64) {}
 func (d *dummyValdatorMonitor) OnNewBlock(_ *state.CachingBeaconState, _ *cltypes.BeaconBlock) error {
 	return nil
 }
+
+func (d *dummyValdatorMonitor) SetForkChoiceReader(_ ForkChoiceReader) {}
+
+func (d *dummyValdatorMonitor) GetValidatorPerformance(_ []uint64, _, _ uint64) []ValidatorPerformance {
+	return nil
+}
@@ -1,6 +1,7 @@
 package monitor
 
 import (
+	"fmt"
 	"sort"
 	"sync"
 	"time"
@@ -262,3 +263,31 @@ func ObserveBlobVerificationTime(startTime time.Time) {
 func ObserveExecutionTime(startTime time.Time) {
 	executionTime.Set(microToMilli(time.Since(startTime).Microseconds()))
 }
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// observeValidatorPerformance sets the per validator metrics of the last reported epoch of an observed validator.
+func observeValidatorPerformance(p *ValidatorPerformance) {
+	metrics.GetOrCreateGauge(fmt.Sprintf(`validator_inclusion_delay{validator="%d"}`, p.ValidatorIndex)).Set(float64(p.InclusionDelay))
+	metrics.GetOrCreateGauge(fmt.Sprintf(`validator_attestation_correct{validator="%d",vote="source"}`, p.ValidatorIndex)).Set(boolToFloat(p.CorrectSource))
+	metrics.GetOrCreateGauge(fmt.Sprintf(`validator_attestation_correct{validator="%d",vote="target"}`, p.ValidatorIndex)).Set(boolToFloat(p.CorrectTarget))
+	metrics.GetOrCreateGauge(fmt.Sprintf(`validator_attestation_correct{validator="%d",vote="head"}`, p.ValidatorIndex)).Set(boolToFloat(p.CorrectHead))
+	metrics.GetOrCreateCounter(fmt.Sprintf(`validator_sync_committee_hit{validator="%d"}`, p.ValidatorIndex)).AddInt(int(p.SyncCommitteeHits))
+	metrics.GetOrCreateCounter(fmt.Sprintf(`validator_sync_committee_miss{validator="%d"}`, p.ValidatorIndex)).AddInt(int(p.SyncCommitteeMisses))
+	if p.Rewards == nil {
+		return
+	}
+	metrics.GetOrCreateGauge(fmt.Sprintf(`validator_attestation_reward{validator="%d",kind="head"}`, p.ValidatorIndex)).Set(float64(p.Rewards.Head))
+	metrics.GetOrCreateGauge(fmt.Sprintf(`validator_attestation_reward{validator="%d",kind="target"}`, p.ValidatorIndex)).Set(float64(p.Rewards.Target))
+	metrics.GetOrCreateGauge(fmt.Sprintf(`validator_attestation_reward{validator="%d",kind="source"}`, p.ValidatorIndex)).Set(float64(p.Rewards.Source))
+	metrics.GetOrCreateGauge(fmt.Sprintf(`validator_attestation_reward{validator="%d",kind="inactivity"}`, p.ValidatorIndex)).Set(float64(p.Rewards.Inactivity))
+}
+
+func observeValidatorMissedProposal(vid uint64) {
+	metrics.GetOrCreateCounter(fmt.Sprintf(`validator_proposal_missed{validator="%d"}`, vid)).AddInt(1)
+}
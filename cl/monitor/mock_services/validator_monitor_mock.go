@@ -13,6 +13,7 @@ import (
 	reflect "reflect"
 
 	cltypes "github.com/erigontech/erigon/cl/cltypes"
+	monitor "github.com/erigontech/erigon/cl/monitor"
 	state "github.com/erigontech/erigon/cl/phase1/core/state"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -41,6 +42,44 @@ func (m *MockValidatorMonitor) EXPECT() *MockValidatorMonitorMockRecorder {
 	return m.recorder
 }
 
+// GetValidatorPerformance mocks base method.
+func (m *MockValidatorMonitor) GetValidatorPerformance(validators []uint64, fromEpoch, toEpoch uint64) []monitor.ValidatorPerformance {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetValidatorPerformance", validators, fromEpoch, toEpoch)
+	ret0, _ := ret[0].([]monitor.ValidatorPerformance)
+	return ret0
+}
+
+// GetValidatorPerformance indicates an expected call of GetValidatorPerformance.
+func (mr *MockValidatorMonitorMockRecorder) GetValidatorPerformance(validators, fromEpoch, toEpoch any) *MockValidatorMonitorGetValidatorPerformanceCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetValidatorPerformance", reflect.TypeOf((*MockValidatorMonitor)(nil).GetValidatorPerformance), validators, fromEpoch, toEpoch)
+	return &MockValidatorMonitorGetValidatorPerformanceCall{Call: call}
+}
+
+// MockValidatorMonitorGetValidatorPerformanceCall wrap *gomock.Call
+type MockValidatorMonitorGetValidatorPerformanceCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockValidatorMonitorGetValidatorPerformanceCall) Return(arg0 []monitor.ValidatorPerformance) *MockValidatorMonitorGetValidatorPerformanceCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockValidatorMonitorGetValidatorPerformanceCall) Do(f func([]uint64, uint64, uint64) []monitor.ValidatorPerformance) *MockValidatorMonitorGetValidatorPerformanceCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockValidatorMonitorGetValidatorPerformanceCall) DoAndReturn(f func([]uint64, uint64, uint64) []monitor.ValidatorPerformance) *MockValidatorMonitorGetValidatorPerformanceCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // ObserveValidator mocks base method.
 func (m *MockValidatorMonitor) ObserveValidator(vid uint64) {
 	m.ctrl.T.Helper()
@@ -150,3 +189,39 @@ func (c *MockValidatorMonitorRemoveValidatorCall) DoAndReturn(f func(uint64)) *M
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// SetForkChoiceReader mocks base method.
+func (m *MockValidatorMonitor) SetForkChoiceReader(reader monitor.ForkChoiceReader) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetForkChoiceReader", reader)
+}
+
+// SetForkChoiceReader indicates an expected call of SetForkChoiceReader.
+func (mr *MockValidatorMonitorMockRecorder) SetForkChoiceReader(reader any) *MockValidatorMonitorSetForkChoiceReaderCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetForkChoiceReader", reflect.TypeOf((*MockValidatorMonitor)(nil).SetForkChoiceReader), reader)
+	return &MockValidatorMonitorSetForkChoiceReaderCall{Call: call}
+}
+
+// MockValidatorMonitorSetForkChoiceReaderCall wrap *gomock.Call
+type MockValidatorMonitorSetForkChoiceReaderCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockValidatorMonitorSetForkChoiceReaderCall) Return() *MockValidatorMonitorSetForkChoiceReaderCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockValidatorMonitorSetForkChoiceReaderCall) Do(f func(monitor.ForkChoiceReader)) *MockValidatorMonitorSetForkChoiceReaderCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockValidatorMonitorSetForkChoiceReaderCall) DoAndReturn(f func(monitor.ForkChoiceReader)) *MockValidatorMonitorSetForkChoiceReaderCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
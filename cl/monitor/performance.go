@@ -0,0 +1,125 @@
+package monitor
+
+import (
+	"sort"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/phase1/core/state"
+)
+
+// maxPerformanceHistory is the number of epochs of performance kept for every observed validator (~1 day on mainnet).
+const maxPerformanceHistory = 225
+
+// ValidatorPerformance is the performance of an observed validator over an epoch.
+type ValidatorPerformance struct {
+	ValidatorIndex      uint64 `json:"validator_index,string"`
+	Epoch               uint64 `json:"epoch,string"`
+	AttestationIncluded bool   `json:"attestation_included"`
+	// InclusionDelay is the smallest inclusion delay of the attestations of the validator, 0 if none was included.
+	InclusionDelay      uint64 `json:"inclusion_delay,string"`
+	CorrectSource       bool   `json:"correct_source"`
+	CorrectTarget       bool   `json:"correct_target"`
+	CorrectHead         bool   `json:"correct_head"`
+	Proposals           uint64 `json:"proposals,string"`
+	MissedProposals     uint64 `json:"missed_proposals,string"`
+	SyncCommitteeHits   uint64 `json:"sync_committee_hits,string"`
+	SyncCommitteeMisses uint64 `json:"sync_committee_misses,string"`
+	// Rewards is nil if the fork choice data of the epoch was not available.
+	Rewards *AttestationRewards `json:"attestation_rewards,omitempty"`
+}
+
+// AttestationRewards are the attestation rewards of a validator for an epoch in gwei, penalties are negative.
+type AttestationRewards struct {
+	Head       int64 `json:"head,string"`
+	Target     int64 `json:"target,string"`
+	Source     int64 `json:"source,string"`
+	Inactivity int64 `json:"inactivity,string"`
+}
+
+func (s *validatorStatuses) addPerformance(performance *ValidatorPerformance) {
+	s.vStatusMutex.Lock()
+	defer s.vStatusMutex.Unlock()
+	if _, ok := s.statuses[performance.ValidatorIndex]; !ok {
+		// removed in the meantime
+		return
+	}
+	performances := append(s.performances[performance.ValidatorIndex], performance)
+	if len(performances) > maxPerformanceHistory {
+		performances = performances[len(performances)-maxPerformanceHistory:]
+	}
+	s.performances[performance.ValidatorIndex] = performances
+}
+
+func (m *validatorMonitorImpl) GetValidatorPerformance(validators []uint64, fromEpoch, toEpoch uint64) []ValidatorPerformance {
+	s := m.vaidatorStatuses
+	s.vStatusMutex.RLock()
+	defer s.vStatusMutex.RUnlock()
+	if len(validators) == 0 {
+		for vid := range s.statuses {
+			validators = append(validators, vid)
+		}
+	} else {
+		validators = append([]uint64{}, validators...)
+	}
+	sort.Slice(validators, func(i, j int) bool { return validators[i] < validators[j] })
+	ret := []ValidatorPerformance{}
+	for _, vid := range validators {
+		for _, performance := range s.performances[vid] {
+			if performance.Epoch >= fromEpoch && performance.Epoch <= toEpoch {
+				ret = append(ret, *performance)
+			}
+		}
+	}
+	return ret
+}
+
+// attestationRewards computes the attestation rewards of the given epoch from the fork choice data of the last block
+// of the next epoch, as attestations of an epoch can be included until the end of the next one. It returns nil if the
+// data is not available.
+func (m *validatorMonitorImpl) attestationRewards(epoch uint64) func(vid uint64) *AttestationRewards {
+	reader, ok := m.forkChoice.Load().(ForkChoiceReader)
+	if !ok || m.beaconCfg.GetCurrentStateVersion(epoch) < clparams.AltairVersion {
+		return nil
+	}
+	headState := m.syncedData.HeadState()
+	lastSlot := (epoch+2)*m.beaconCfg.SlotsPerEpoch - 1
+	if headState == nil || headState.Slot() <= lastSlot {
+		return nil
+	}
+	blockRoot, err := headState.GetBlockRootAtSlot(lastSlot)
+	if err != nil {
+		log.Debug("[monitor] failed to get block root for rewards", "epoch", epoch, "err", err)
+		return nil
+	}
+	validatorSet, err := reader.GetValidatorSet(blockRoot)
+	if err != nil || validatorSet == nil {
+		return nil
+	}
+	inactivityScores, err := reader.GetInactivitiesScores(blockRoot)
+	if err != nil || inactivityScores == nil {
+		return nil
+	}
+	participation, err := reader.GetPreviousParticipationIndicies(blockRoot)
+	if err != nil || participation == nil {
+		return nil
+	}
+	finalizedCheckpoint, _, _, ok := reader.GetFinalityCheckpoints(blockRoot)
+	if !ok {
+		return nil
+	}
+	inactivityLeak := epoch-finalizedCheckpoint.Epoch > m.beaconCfg.MinEpochsToInactivityPenalty
+	calculator := state.NewAttestationRewardsCalculator(m.beaconCfg, validatorSet, inactivityScores, participation, inactivityLeak, epoch+1)
+	return func(vid uint64) *AttestationRewards {
+		if vid >= uint64(validatorSet.Length()) {
+			return nil
+		}
+		rewards := calculator.Compute(vid)
+		return &AttestationRewards{
+			Head:       rewards.Head,
+			Target:     rewards.Target,
+			Source:     rewards.Source,
+			Inactivity: rewards.Inactivity,
+		}
+	}
+}
@@ -2,6 +2,7 @@ package monitor
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	mapset "github.com/deckarep/golang-set/v2"
@@ -20,10 +21,14 @@ type validatorMonitorImpl struct {
 	ethClock         eth_clock.EthereumClock
 	beaconCfg        *clparams.BeaconChainConfig
 	vaidatorStatuses *validatorStatuses // map validatorID -> epoch -> validatorStatus
+	forkChoice       atomic.Value       // ForkChoiceReader
 }
 
+// NewValidatorMonitor creates the validator monitor, validators are the ones observed from the start on top of the
+// ones registered through the validator API.
 func NewValidatorMonitor(
 	enableMonitor bool,
+	validators []uint64,
 	ethClock eth_clock.EthereumClock,
 	beaconConfig *clparams.BeaconChainConfig,
 	syncedData *synced_data.SyncedDataManager,
@@ -38,6 +43,9 @@ func NewValidatorMonitor(
 		syncedData:       syncedData,
 		vaidatorStatuses: newValidatorStatuses(),
 	}
+	for _, vid := range validators {
+		m.ObserveValidator(vid)
+	}
 	go m.runReportAttesterStatus()
 	go m.runReportProposerStatus()
 	return m
@@ -51,7 +59,11 @@ func (m *validatorMonitorImpl) RemoveValidator(vid uint64) {
 	m.vaidatorStatuses.removeValidator(vid)
 }
 
-func (m *validatorMonitorImpl) OnNewBlock(state *state.CachingBeaconState, block *cltypes.BeaconBlock) error {
+func (m *validatorMonitorImpl) SetForkChoiceReader(reader ForkChoiceReader) {
+	m.forkChoice.Store(reader)
+}
+
+func (m *validatorMonitorImpl) OnNewBlock(s *state.CachingBeaconState, block *cltypes.BeaconBlock) error {
 	var (
 		atts         = block.Body.Attestations
 		blockEpoch   = m.ethClock.GetEpochAtSlot(block.Slot)
@@ -65,22 +77,38 @@ func (m *validatorMonitorImpl) OnNewBlock(state *state.CachingBeaconState, block
 	// todo: maybe launch a goroutine to update attester status
 	// update attester status
 	atts.Range(func(i int, att *solid.Attestation, length int) bool {
-		indicies, err := state.GetAttestingIndicies(att, true)
+		indicies, err := s.GetAttestingIndicies(att, true)
 		if err != nil {
 			log.Warn("failed to get attesting indicies", "err", err, "slot", block.Slot, "stateRoot", block.StateRoot)
 			return false
 		}
 		slot := att.Data.Slot
 		attEpoch := m.ethClock.GetEpochAtSlot(slot)
+		inclusionDelay := block.Slot - slot
+		correctTarget, correctHead := attestationCorrectness(s, att.Data)
 		for _, vidx := range indicies {
 			status := m.vaidatorStatuses.getValidatorStatus(vidx, attEpoch)
 			if status == nil {
 				continue
 			}
-			status.updateAttesterStatus(att)
+			status.updateAttesterStatus(att, inclusionDelay, correctTarget, correctHead)
 		}
 		return true
 	})
+	// update sync committee status, the aggregate of the block is for the previous slot.
+	if block.Version() >= clparams.AltairVersion && block.Body.SyncAggregate != nil && block.Slot > 0 {
+		bits := block.Body.SyncAggregate.SyncCommiteeBits
+		signedEpoch := m.ethClock.GetEpochAtSlot(block.Slot - 1)
+		for i, pubkey := range syncAggregateCommittee(s, block.Slot).GetCommittee() {
+			vidx, ok := s.ValidatorIndexByPubkey(pubkey)
+			if !ok {
+				continue
+			}
+			if status := m.vaidatorStatuses.getValidatorStatus(vidx, signedEpoch); status != nil {
+				status.updateSyncCommitteeStatus(bits[i/8]&(1<<(i%8)) != 0)
+			}
+		}
+	}
 	// update proposer status
 	pIndex := block.ProposerIndex
 	if status := m.vaidatorStatuses.getValidatorStatus(pIndex, blockEpoch); status != nil {
@@ -90,6 +118,17 @@ func (m *validatorMonitorImpl) OnNewBlock(state *state.CachingBeaconState, block
 	return nil
 }
 
+// syncAggregateCommittee returns the sync committee whose participation the sync aggregate of the block at slot carries.
+// The aggregate is signed at the previous slot by the committee of the period of slot, so when the block crosses a period
+// boundary it is the next sync committee of a state still in the previous period, and the current one of the post state.
+func syncAggregateCommittee(s *state.CachingBeaconState, slot uint64) *solid.SyncCommittee {
+	slotsPerPeriod := s.BeaconConfig().SlotsPerEpoch * s.BeaconConfig().EpochsPerSyncCommitteePeriod
+	if s.Slot()/slotsPerPeriod < slot/slotsPerPeriod {
+		return s.NextSyncCommittee()
+	}
+	return s.CurrentSyncCommittee()
+}
+
 func (m *validatorMonitorImpl) runReportAttesterStatus() {
 	// every epoch seconds
 	epochDuration := time.Duration(m.beaconCfg.SlotsPerEpoch) * time.Duration(m.beaconCfg.SecondsPerSlot) * time.Second
@@ -100,19 +139,33 @@ func (m *validatorMonitorImpl) runReportAttesterStatus() {
 		epoch := currentEpoch - 2
 		hitCount := 0
 		missCount := 0
+		rewards := m.attestationRewards(epoch)
+		performances := []*ValidatorPerformance{}
 		m.vaidatorStatuses.iterate(func(vindex uint64, epochStatuses map[uint64]*validatorStatus) {
-			if status, ok := epochStatuses[epoch]; ok {
-				successAtt := status.attestedBlockRoots.Cardinality()
+			status, ok := epochStatuses[epoch]
+			if !ok {
+				status = newValidatorStatus()
+			}
+			delete(epochStatuses, epoch)
+			performance := status.performance(vindex, epoch)
+			if rewards != nil {
+				performance.Rewards = rewards(vindex)
+			}
+			performances = append(performances, performance)
+			if successAtt := status.attestedBlockRoots.Cardinality(); successAtt > 0 {
 				metricAttestHit.AddInt(successAtt)
 				hitCount += successAtt
-				delete(epochStatuses, epoch)
-				log.Debug("[monitor] report attester status hit", "epoch", epoch, "vindex", vindex, "countAttestedBlock", status.attestedBlockRoots.Cardinality())
+				log.Debug("[monitor] report attester status hit", "epoch", epoch, "vindex", vindex, "countAttestedBlock", successAtt, "inclusionDelay", performance.InclusionDelay)
 			} else {
 				metricAttestMiss.AddInt(1)
 				missCount++
 				log.Debug("[monitor] report attester status miss", "epoch", epoch, "vindex", vindex, "countAttestedBlock", 0)
 			}
 		})
+		for _, performance := range performances {
+			m.vaidatorStatuses.addPerformance(performance)
+			observeValidatorPerformance(performance)
+		}
 		log.Info("[monitor] report attester hit/miss", "epoch", epoch, "hitCount", hitCount, "missCount", missCount, "cur_epoch", currentEpoch)
 	}
 
@@ -140,6 +193,8 @@ func (m *validatorMonitorImpl) runReportProposerStatus() {
 				log.Info("[monitor] proposer hit", "slot", prevSlot, "proposerIndex", proposerIndex)
 			} else {
 				metricProposerMiss.AddInt(1)
+				observeValidatorMissedProposal(proposerIndex)
+				status.missedProposeSlots.Add(prevSlot)
 				log.Info("[monitor] proposer miss", "slot", prevSlot, "proposerIndex", proposerIndex)
 			}
 		}
@@ -151,21 +206,94 @@ type validatorStatus struct {
 	attestedBlockRoots mapset.Set[common.Hash]
 	// proposeSlots is the set of slots that the proposer has successfully proposed blocks during one epoch.
 	proposeSlots mapset.Set[uint64]
+	// missedProposeSlots is the set of slots that the proposer was expected to propose a block at but did not.
+	missedProposeSlots mapset.Set[uint64]
+
+	mu sync.Mutex
+	// inclusionDelay is the smallest inclusion delay of the attestations of the validator, 0 if none was included.
+	inclusionDelay      uint64
+	correctTarget       bool
+	correctHead         bool
+	syncCommitteeHits   uint64
+	syncCommitteeMisses uint64
 }
 
-func (s *validatorStatus) updateAttesterStatus(att *solid.Attestation) {
+func newValidatorStatus() *validatorStatus {
+	return &validatorStatus{
+		attestedBlockRoots: mapset.NewSet[common.Hash](),
+		proposeSlots:       mapset.NewSet[uint64](),
+		missedProposeSlots: mapset.NewSet[uint64](),
+	}
+}
+
+func (s *validatorStatus) updateAttesterStatus(att *solid.Attestation, inclusionDelay uint64, correctTarget, correctHead bool) {
 	data := att.Data
 	s.attestedBlockRoots.Add(data.BeaconBlockRoot)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inclusionDelay == 0 || inclusionDelay < s.inclusionDelay {
+		s.inclusionDelay = inclusionDelay
+	}
+	s.correctTarget = s.correctTarget || correctTarget
+	s.correctHead = s.correctHead || correctHead
+}
+
+func (s *validatorStatus) updateSyncCommitteeStatus(participated bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if participated {
+		s.syncCommitteeHits++
+	} else {
+		s.syncCommitteeMisses++
+	}
+}
+
+// performance returns the performance of the validator over the epoch of the status.
+func (s *validatorStatus) performance(vid, epoch uint64) *ValidatorPerformance {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	included := s.attestedBlockRoots.Cardinality() > 0
+	return &ValidatorPerformance{
+		ValidatorIndex:      vid,
+		Epoch:               epoch,
+		AttestationIncluded: included,
+		InclusionDelay:      s.inclusionDelay,
+		// an attestation can only be included with the justified checkpoint as source.
+		CorrectSource:       included,
+		CorrectTarget:       s.correctTarget,
+		CorrectHead:         s.correctHead,
+		Proposals:           uint64(s.proposeSlots.Cardinality()),
+		MissedProposals:     uint64(s.missedProposeSlots.Cardinality()),
+		SyncCommitteeHits:   s.syncCommitteeHits,
+		SyncCommitteeMisses: s.syncCommitteeMisses,
+	}
+}
+
+// attestationCorrectness checks the target and head votes of an attestation against the chain of the state including it.
+func attestationCorrectness(s *state.CachingBeaconState, data *solid.AttestationData) (correctTarget, correctHead bool) {
+	targetRoot, err := state.GetBlockRoot(s, data.Target.Epoch)
+	if err != nil {
+		return false, false
+	}
+	headRoot, err := s.GetBlockRootAtSlot(data.Slot)
+	if err != nil {
+		return false, false
+	}
+	correctTarget = data.Target.Root == targetRoot
+	return correctTarget, correctTarget && data.BeaconBlockRoot == headRoot
 }
 
 type validatorStatuses struct {
-	statuses     map[uint64]map[uint64]*validatorStatus
+	statuses map[uint64]map[uint64]*validatorStatus
+	// performances is the timeline of the reported epochs of every validator, oldest first.
+	performances map[uint64][]*ValidatorPerformance
 	vStatusMutex sync.RWMutex
 }
 
 func newValidatorStatuses() *validatorStatuses {
 	return &validatorStatuses{
-		statuses: make(map[uint64]map[uint64]*validatorStatus),
+		statuses:     make(map[uint64]map[uint64]*validatorStatus),
+		performances: make(map[uint64][]*ValidatorPerformance),
 	}
 }
 
@@ -179,10 +307,7 @@ func (s *validatorStatuses) getValidatorStatus(vid uint64, epoch uint64) *valida
 		return nil
 	}
 	if _, ok := statusByEpoch[epoch]; !ok {
-		statusByEpoch[epoch] = &validatorStatus{
-			attestedBlockRoots: mapset.NewSet[common.Hash](),
-			proposeSlots:       mapset.NewSet[uint64](),
-		}
+		statusByEpoch[epoch] = newValidatorStatus()
 	}
 
 	return statusByEpoch[epoch]
@@ -202,6 +327,7 @@ func (s *validatorStatuses) removeValidator(vid uint64) {
 	defer s.vStatusMutex.Unlock()
 	if _, ok := s.statuses[vid]; ok {
 		delete(s.statuses, vid)
+		delete(s.performances, vid)
 		log.Info("[monitor] remove validator", "vid", vid)
 	}
 }
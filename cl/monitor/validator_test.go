@@ -0,0 +1,85 @@
+package monitor
+
+import (
+	"testing"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/phase1/core/state"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatorStatusPerformance(t *testing.T) {
+	status := newValidatorStatus()
+	require.Equal(t, &ValidatorPerformance{ValidatorIndex: 1, Epoch: 2}, status.performance(1, 2))
+
+	att := &solid.Attestation{Data: &solid.AttestationData{Slot: 5, BeaconBlockRoot: common.Hash{1}}}
+	status.updateAttesterStatus(att, 3, false, false)
+	status.updateAttesterStatus(att, 1, true, true)
+	status.updateAttesterStatus(att, 2, false, false)
+	status.updateSyncCommitteeStatus(true)
+	status.updateSyncCommitteeStatus(false)
+	status.updateSyncCommitteeStatus(true)
+	status.proposeSlots.Add(64)
+	status.missedProposeSlots.Add(65)
+
+	require.Equal(t, &ValidatorPerformance{
+		ValidatorIndex:      1,
+		Epoch:               2,
+		AttestationIncluded: true,
+		InclusionDelay:      1,
+		CorrectSource:       true,
+		CorrectTarget:       true,
+		CorrectHead:         true,
+		Proposals:           1,
+		MissedProposals:     1,
+		SyncCommitteeHits:   2,
+		SyncCommitteeMisses: 1,
+	}, status.performance(1, 2))
+}
+
+func TestValidatorPerformanceHistory(t *testing.T) {
+	m := &validatorMonitorImpl{vaidatorStatuses: newValidatorStatuses()}
+	m.ObserveValidator(7)
+	m.ObserveValidator(3)
+	for epoch := uint64(0); epoch < maxPerformanceHistory+10; epoch++ {
+		m.vaidatorStatuses.addPerformance(&ValidatorPerformance{ValidatorIndex: 7, Epoch: epoch})
+		m.vaidatorStatuses.addPerformance(&ValidatorPerformance{ValidatorIndex: 3, Epoch: epoch})
+	}
+	// not observed
+	m.vaidatorStatuses.addPerformance(&ValidatorPerformance{ValidatorIndex: 5, Epoch: 100})
+
+	all := m.GetValidatorPerformance(nil, 0, 1000)
+	require.Len(t, all, 2*maxPerformanceHistory)
+	require.Equal(t, uint64(3), all[0].ValidatorIndex)
+	require.Equal(t, uint64(10), all[0].Epoch)
+	require.Equal(t, uint64(7), all[len(all)-1].ValidatorIndex)
+
+	validators := []uint64{7, 5}
+	ranged := m.GetValidatorPerformance(validators, 100, 101)
+	require.Equal(t, []ValidatorPerformance{{ValidatorIndex: 7, Epoch: 100}, {ValidatorIndex: 7, Epoch: 101}}, ranged)
+	require.Equal(t, []uint64{7, 5}, validators)
+
+	m.RemoveValidator(7)
+	require.Empty(t, m.GetValidatorPerformance([]uint64{7}, 0, 1000))
+}
+
+func TestSyncAggregateCommittee(t *testing.T) {
+	cfg := clparams.MainnetBeaconConfig
+	slotsPerPeriod := cfg.SlotsPerEpoch * cfg.EpochsPerSyncCommitteePeriod
+	current, next := &solid.SyncCommittee{1}, &solid.SyncCommittee{2}
+	s := state.New(&cfg)
+	s.SetCurrentSyncCommittee(current)
+	s.SetNextSyncCommittee(next)
+
+	// within the period
+	s.SetSlot(slotsPerPeriod + 5)
+	require.Equal(t, current, syncAggregateCommittee(s, slotsPerPeriod+6))
+	// the last slot of the period signs for the first block of the next one
+	s.SetSlot(2*slotsPerPeriod - 1)
+	require.Equal(t, next, syncAggregateCommittee(s, 2*slotsPerPeriod))
+	// the post state of that block already rotated the committees
+	s.SetSlot(2 * slotsPerPeriod)
+	require.Equal(t, current, syncAggregateCommittee(s, 2*slotsPerPeriod))
+}
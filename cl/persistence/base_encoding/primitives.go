@@ -18,6 +18,7 @@ package base_encoding
 
 import (
 	"encoding/binary"
+	"io"
 
 	libcommon "github.com/erigontech/erigon-lib/common"
 )
@@ -63,6 +64,11 @@ func DecodeCompactUint64(buf []byte) (x uint64) {
 	return
 }
 
+// ReadCompactUint64 reads a number encoded with EncodeCompactUint64 from a stream
+func ReadCompactUint64(r io.ByteReader) (uint64, error) {
+	return binary.ReadUvarint(r)
+}
+
 func EncodePeriodAndRoot(period uint32, root libcommon.Hash) []byte {
 	out := make([]byte, 36)
 	binary.BigEndian.PutUint32(out[:4], period)
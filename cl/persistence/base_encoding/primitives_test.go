@@ -32,6 +32,10 @@ func Test64(t *testing.T) {
 
 	out = EncodeCompactUint64(number)
 	require.Equal(t, DecodeCompactUint64(out), number)
+
+	decoded, err := ReadCompactUint64(bytes.NewReader(out))
+	require.NoError(t, err)
+	require.Equal(t, decoded, number)
 }
 
 func TestDiff64(t *testing.T) {
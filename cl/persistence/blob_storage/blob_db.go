@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"path"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -47,6 +48,12 @@ type BlobStorage interface {
 	ReadBlobSidecars(ctx context.Context, slot uint64, blockRoot libcommon.Hash) (out []*cltypes.BlobSidecar, found bool, err error)
 	WriteStream(w io.Writer, slot uint64, blockRoot libcommon.Hash, idx uint64) error // Used for P2P networking
 	KzgCommitmentsCount(ctx context.Context, blockRoot libcommon.Hash) (uint32, error)
+	// PeerDAS data columns
+	WriteDataColumnSidecars(ctx context.Context, blockRoot libcommon.Hash, sidecars []*cltypes.DataColumnSidecar) error
+	RemoveDataColumnSidecars(ctx context.Context, slot uint64, blockRoot libcommon.Hash) error
+	ReadDataColumnSidecars(ctx context.Context, slot uint64, blockRoot libcommon.Hash) ([]*cltypes.DataColumnSidecar, error)
+	DataColumnIndicies(ctx context.Context, blockRoot libcommon.Hash) ([]uint64, error)
+	WriteDataColumnStream(w io.Writer, slot uint64, blockRoot libcommon.Hash, idx uint64) error // Used for P2P networking
 	Prune() error
 }
 
@@ -166,6 +173,7 @@ func (bs *BlobStore) Prune() error {
 	// delete all the folders that are older than slotsKept
 	for i := startPrune; i < currentSlot; i += subdivisionSlot {
 		bs.fs.RemoveAll(strconv.FormatUint(i/subdivisionSlot, 10))
+		bs.fs.RemoveAll(path.Join(dataColumnsFolder, strconv.FormatUint(i/subdivisionSlot, 10)))
 	}
 	return nil
 }
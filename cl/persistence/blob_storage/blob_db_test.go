@@ -66,3 +66,43 @@ func TestBlobDB(t *testing.T) {
 	require.Equal(t, s1.SignedBlockHeader, sidecars[0].SignedBlockHeader)
 	require.Equal(t, s2.SignedBlockHeader, sidecars[1].SignedBlockHeader)
 }
+
+func TestDataColumnDB(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	newSidecar := func(index uint64) *cltypes.DataColumnSidecar {
+		sidecar := cltypes.NewDataColumnSidecar()
+		sidecar.Index = index
+		sidecar.SignedBlockHeader.Header.Slot = 1
+		sidecar.Column.Append(&cltypes.Cell{byte(index)})
+		sidecar.KzgCommitments.Append(&cltypes.KZGCommitment{2})
+		sidecar.KzgProofs.Append(&cltypes.KZGProof{3})
+		return sidecar
+	}
+
+	bs := NewBlobStore(db, afero.NewMemMapFs(), 12, &clparams.MainnetBeaconConfig, nil)
+	blockRoot := libcommon.Hash{1}
+	require.NoError(t, bs.WriteDataColumnSidecars(context.Background(), blockRoot, []*cltypes.DataColumnSidecar{newSidecar(65), newSidecar(3)}))
+	require.NoError(t, bs.WriteDataColumnSidecars(context.Background(), blockRoot, []*cltypes.DataColumnSidecar{newSidecar(127)}))
+
+	indicies, err := bs.DataColumnIndicies(context.Background(), blockRoot)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{3, 65, 127}, indicies)
+
+	sidecars, err := bs.ReadDataColumnSidecars(context.Background(), 1, blockRoot)
+	require.NoError(t, err)
+	require.Len(t, sidecars, 3)
+	for i, index := range indicies {
+		expectedRoot, err := newSidecar(index).HashSSZ()
+		require.NoError(t, err)
+		root, err := sidecars[i].HashSSZ()
+		require.NoError(t, err)
+		require.Equal(t, expectedRoot, root)
+	}
+
+	require.NoError(t, bs.RemoveDataColumnSidecars(context.Background(), 1, blockRoot))
+	indicies, err = bs.DataColumnIndicies(context.Background(), blockRoot)
+	require.NoError(t, err)
+	require.Empty(t, indicies)
+}
@@ -0,0 +1,184 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package blob_storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/sentinel/communication/ssz_snappy"
+	"github.com/spf13/afero"
+)
+
+const dataColumnsFolder = "data_columns"
+
+/*
+file system layout: data_columns/<slot/subdivisionSlot>/<blockRoot>_<index>
+indicies:
+- <blockRoot> -> bitvector of the stored columns // block
+*/
+
+func dataColumnSidecarFilePath(slot, index uint64, blockRoot libcommon.Hash) (folderpath, filepath string) {
+	folderpath = path.Join(dataColumnsFolder, strconv.FormatUint(slot/subdivisionSlot, 10))
+	filepath = fmt.Sprintf("%s/%s_%d", folderpath, blockRoot.String(), index)
+	return
+}
+
+// WriteDataColumnSidecars writes the sidecars on the database, they must all be for the same blockRoot. Unlike blobs, only
+// the custodied columns of a block are stored, so the sidecars are added to the ones already stored.
+func (bs *BlobStore) WriteDataColumnSidecars(ctx context.Context, blockRoot libcommon.Hash, sidecars []*cltypes.DataColumnSidecar) error {
+	if len(sidecars) == 0 {
+		return nil
+	}
+	for _, sidecar := range sidecars {
+		if sidecar.Index >= cltypes.NumberOfColumns {
+			return fmt.Errorf("data column index %d out of range", sidecar.Index)
+		}
+		if err := bs.writeDataColumnSidecar(blockRoot, sidecar); err != nil {
+			return err
+		}
+	}
+	tx, err := bs.db.BeginRw(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	// Wait for the columns to be written on disk and then write the index on mdbx
+	stored := make([]byte, cltypes.NumberOfColumns/8)
+	val, err := tx.GetOne(kv.BlockRootToDataColumns, blockRoot[:])
+	if err != nil {
+		return err
+	}
+	copy(stored, val)
+	for _, sidecar := range sidecars {
+		stored[sidecar.Index/8] |= 1 << (sidecar.Index % 8)
+	}
+	if err := tx.Put(kv.BlockRootToDataColumns, blockRoot[:], stored); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (bs *BlobStore) writeDataColumnSidecar(blockRoot libcommon.Hash, sidecar *cltypes.DataColumnSidecar) error {
+	folderPath, filePath := dataColumnSidecarFilePath(sidecar.SignedBlockHeader.Header.Slot, sidecar.Index, blockRoot)
+	// mkdir the whole folder and subfolders
+	bs.fs.MkdirAll(folderPath, 0755)
+	file, err := bs.fs.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := ssz_snappy.EncodeAndWrite(file, sidecar); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// DataColumnIndicies returns the sorted indicies of the columns stored for the block.
+func (bs *BlobStore) DataColumnIndicies(ctx context.Context, blockRoot libcommon.Hash) ([]uint64, error) {
+	tx, err := bs.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	val, err := tx.GetOne(kv.BlockRootToDataColumns, blockRoot[:])
+	if err != nil {
+		return nil, err
+	}
+	var indicies []uint64
+	for i := uint64(0); i < uint64(len(val))*8; i++ {
+		if val[i/8]&(1<<(i%8)) != 0 {
+			indicies = append(indicies, i)
+		}
+	}
+	return indicies, nil
+}
+
+// ReadDataColumnSidecars reads all the stored columns of the block, sorted by index.
+func (bs *BlobStore) ReadDataColumnSidecars(ctx context.Context, slot uint64, blockRoot libcommon.Hash) ([]*cltypes.DataColumnSidecar, error) {
+	indicies, err := bs.DataColumnIndicies(ctx, blockRoot)
+	if err != nil {
+		return nil, err
+	}
+	sidecars := make([]*cltypes.DataColumnSidecar, 0, len(indicies))
+	for _, index := range indicies {
+		_, filePath := dataColumnSidecarFilePath(slot, index, blockRoot)
+		sidecar, err := bs.readDataColumnSidecar(filePath)
+		if err != nil {
+			if errors.Is(err, afero.ErrFileNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		sidecars = append(sidecars, sidecar)
+	}
+	return sidecars, nil
+}
+
+func (bs *BlobStore) readDataColumnSidecar(filePath string) (*cltypes.DataColumnSidecar, error) {
+	file, err := bs.fs.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	sidecar := cltypes.NewDataColumnSidecar()
+	if err := ssz_snappy.DecodeAndReadNoForkDigest(file, sidecar, clparams.DenebVersion); err != nil {
+		return nil, err
+	}
+	return sidecar, nil
+}
+
+func (bs *BlobStore) WriteDataColumnStream(w io.Writer, slot uint64, blockRoot libcommon.Hash, idx uint64) error {
+	_, filePath := dataColumnSidecarFilePath(slot, idx, blockRoot)
+	file, err := bs.fs.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(w, file)
+	return err
+}
+
+func (bs *BlobStore) RemoveDataColumnSidecars(ctx context.Context, slot uint64, blockRoot libcommon.Hash) error {
+	indicies, err := bs.DataColumnIndicies(ctx, blockRoot)
+	if err != nil {
+		return err
+	}
+	tx, err := bs.db.BeginRw(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, index := range indicies {
+		_, filePath := dataColumnSidecarFilePath(slot, index, blockRoot)
+		if err := bs.fs.Remove(filePath); err != nil && !errors.Is(err, afero.ErrFileNotFound) {
+			return err
+		}
+	}
+	if err := tx.Delete(kv.BlockRootToDataColumns, blockRoot[:]); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,170 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package state_accessors
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/golang/snappy"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/phase1/core/state"
+)
+
+const checkpointStateHeaderSize = 1 + 8 + 32 + 32 + 8
+
+// CheckpointStateHeader describes the state stored in a checkpoint state file.
+type CheckpointStateHeader struct {
+	Version   clparams.StateVersion
+	Slot      uint64
+	BlockRoot libcommon.Hash
+	StateRoot libcommon.Hash
+	// Length is the size of the uncompressed SSZ encoding of the state.
+	Length uint64
+}
+
+func (h *CheckpointStateHeader) encode() []byte {
+	buf := make([]byte, checkpointStateHeaderSize)
+	buf[0] = byte(h.Version)
+	binary.BigEndian.PutUint64(buf[1:], h.Slot)
+	copy(buf[9:], h.BlockRoot[:])
+	copy(buf[41:], h.StateRoot[:])
+	binary.BigEndian.PutUint64(buf[73:], h.Length)
+	return buf
+}
+
+func (h *CheckpointStateHeader) decode(buf []byte) error {
+	if len(buf) != checkpointStateHeaderSize {
+		return errors.New("invalid checkpoint state header size")
+	}
+	h.Version = clparams.StateVersion(buf[0])
+	h.Slot = binary.BigEndian.Uint64(buf[1:])
+	copy(h.BlockRoot[:], buf[9:])
+	copy(h.StateRoot[:], buf[41:])
+	h.Length = binary.BigEndian.Uint64(buf[73:])
+	return nil
+}
+
+// WriteCheckpointState writes the given state (whose latest block is blockRoot) to path. the file is a small fixed-size
+// header followed by the snappy framed SSZ encoding of the state, so that readers can stream it without decoding it.
+// The file is written to a temporary file first and renamed, so concurrent readers never observe a partial state.
+func WriteCheckpointState(path string, blockRoot libcommon.Hash, s *state.CachingBeaconState) error {
+	stateRoot, err := s.HashSSZ()
+	if err != nil {
+		return err
+	}
+	encoded, err := s.EncodeSSZ(nil)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+
+	header := CheckpointStateHeader{
+		Version:   s.Version(),
+		Slot:      s.Slot(),
+		BlockRoot: blockRoot,
+		StateRoot: stateRoot,
+		Length:    uint64(len(encoded)),
+	}
+	if _, err := f.Write(header.encode()); err != nil {
+		f.Close()
+		return err
+	}
+	w := snappy.NewBufferedWriter(f)
+	if _, err := w.Write(encoded); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// CheckpointStateReader streams the SSZ encoding of a state stored with WriteCheckpointState.
+type CheckpointStateReader struct {
+	Header CheckpointStateHeader
+
+	f *os.File
+	r io.Reader
+}
+
+// OpenCheckpointState opens the checkpoint state file at path and reads its header.
+func OpenCheckpointState(path string) (*CheckpointStateReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	buffered := bufio.NewReader(f)
+	headerBuf := make([]byte, checkpointStateHeaderSize)
+	if _, err := io.ReadFull(buffered, headerBuf); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read checkpoint state header: %w", err)
+	}
+	r := &CheckpointStateReader{f: f}
+	if err := r.Header.decode(headerBuf); err != nil {
+		f.Close()
+		return nil, err
+	}
+	r.r = io.LimitReader(snappy.NewReader(buffered), int64(r.Header.Length))
+	return r, nil
+}
+
+// Read reads the uncompressed SSZ encoding of the state.
+func (r *CheckpointStateReader) Read(p []byte) (int, error) {
+	return r.r.Read(p)
+}
+
+// WriteTo copies the whole SSZ encoding of the state to w.
+func (r *CheckpointStateReader) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.Copy(w, r.r)
+	if err != nil {
+		return n, err
+	}
+	if uint64(n) != r.Header.Length {
+		return n, fmt.Errorf("checkpoint state is truncated: expected %d bytes, got %d", r.Header.Length, n)
+	}
+	return n, nil
+}
+
+func (r *CheckpointStateReader) Close() error {
+	return r.f.Close()
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package state_accessors_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/cl/antiquary/tests"
+	state_accessors "github.com/erigontech/erigon/cl/persistence/state"
+)
+
+func TestCheckpointStateRoundTrip(t *testing.T) {
+	_, postState, _ := tests.GetBellatrixRandom()
+	path := filepath.Join(t.TempDir(), "finalized.ssz_snappy")
+	blockRoot := libcommon.HexToHash("0x1234")
+
+	require.NoError(t, state_accessors.WriteCheckpointState(path, blockRoot, postState))
+
+	r, err := state_accessors.OpenCheckpointState(path)
+	require.NoError(t, err)
+	defer r.Close()
+
+	expected, err := postState.EncodeSSZ(nil)
+	require.NoError(t, err)
+	expectedRoot, err := postState.HashSSZ()
+	require.NoError(t, err)
+
+	require.Equal(t, postState.Version(), r.Header.Version)
+	require.Equal(t, postState.Slot(), r.Header.Slot)
+	require.Equal(t, blockRoot, r.Header.BlockRoot)
+	require.Equal(t, libcommon.Hash(expectedRoot), r.Header.StateRoot)
+	require.Equal(t, uint64(len(expected)), r.Header.Length)
+
+	var out bytes.Buffer
+	_, err = r.WriteTo(&out)
+	require.NoError(t, err)
+	require.Equal(t, expected, out.Bytes())
+}
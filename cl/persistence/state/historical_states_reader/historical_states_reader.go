@@ -274,6 +274,17 @@ func (r *HistoricalStatesReader) ReadHistoricalState(ctx context.Context, tx kv.
 		return nil, fmt.Errorf("failed to read historical summaries: %w", err)
 	}
 	ret.SetHistoricalSummaries(historicalSummaries)
+	if ret.Version() < clparams.ElectraVersion {
+		return ret, nil
+	}
+
+	// Electra churn and queues
+	ret.SetDepositRequestsStartIndex(slotData.DepositRequestsStartIndex)
+	ret.SetDepositBalanceToConsume(slotData.DepositBalanceToConsume)
+	ret.SetExitBalanceToConsume(slotData.ExitBalanceToConsume)
+	ret.SetEarliestExitEpoch(slotData.EarliestExitEpoch)
+	ret.SetConsolidationBalanceToConsume(slotData.ConsolidationBalanceToConsume)
+	ret.SetEarliestConsolidationEpoch(slotData.EarliestConsolidationEpoch)
 	return ret, nil
 }
 
@@ -741,6 +752,15 @@ func (r *HistoricalStatesReader) ReadParticipations(tx kv.Tx, slot uint64) (*sol
 	}
 	validatorLength := sd.ValidatorLength
 
+	// Rebuilding the flags out of the per-slot participation diffs is much cheaper than replaying the attestations.
+	previous, current, ok, err := r.reconstructParticipationsFromDiffs(tx, slot, validatorLength)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ok {
+		return solid.ParticipationBitListFromBytes(current, int(r.cfg.ValidatorRegistryLimit)), solid.ParticipationBitListFromBytes(previous, int(r.cfg.ValidatorRegistryLimit)), nil
+	}
+
 	currentIdxs := solid.NewParticipationBitList(int(validatorLength), int(r.cfg.ValidatorRegistryLimit))
 	previousIdxs := solid.NewParticipationBitList(int(validatorLength), int(r.cfg.ValidatorRegistryLimit))
 	if err != nil {
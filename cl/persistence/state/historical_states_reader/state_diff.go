@@ -0,0 +1,238 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package historical_states_reader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sort"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/persistence/base_encoding"
+	state_accessors "github.com/erigontech/erigon/cl/persistence/state"
+)
+
+var ErrInvalidStateDiffRange = errors.New("the state diff range must go forward in time")
+
+// reconstructParticipationsFromDiffs rebuilds the epoch participation flags at the given slot out of the participation diffs
+// of the previous and current epochs. ok is false if the diffs do not cover that range (states antiquated before they existed).
+func (r *HistoricalStatesReader) reconstructParticipationsFromDiffs(tx kv.Tx, slot, validatorSetLength uint64) (previous, current []byte, ok bool, err error) {
+	_, prevEpoch := r.computeRelevantEpochs(slot)
+	beginSlot := prevEpoch * r.cfg.SlotsPerEpoch
+
+	cursor, err := tx.Cursor(kv.ParticipationDiffs)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	defer cursor.Close()
+	k, _, err := cursor.First()
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if k == nil || base_encoding.Decode64FromBytes4(k) > beginSlot {
+		return nil, nil, false, nil
+	}
+
+	previous, current = make([]byte, validatorSetLength), make([]byte, validatorSetLength)
+	// the current epoch participation is empty at the beginning of the previous epoch, and the previous epoch participation
+	// is not relevant, as it will be replaced once we rotate to the current epoch.
+	currentEpoch := prevEpoch
+	diff := &state_accessors.ParticipationDiff{}
+	k, v, err := cursor.Seek(base_encoding.Encode64ToBytes4(beginSlot))
+	if err != nil {
+		return nil, nil, false, err
+	}
+	for k != nil && base_encoding.Decode64FromBytes4(k) <= slot {
+		diffEpoch := base_encoding.Decode64FromBytes4(k) / r.cfg.SlotsPerEpoch
+		if diffEpoch != currentEpoch {
+			rotateParticipation(previous, current, diffEpoch-currentEpoch)
+			currentEpoch = diffEpoch
+		}
+		if err := diff.DecodeFrom(bytes.NewReader(v)); err != nil {
+			return nil, nil, false, err
+		}
+		if err := diff.Apply(previous, current); err != nil {
+			return nil, nil, false, err
+		}
+		if k, v, err = cursor.Next(); err != nil {
+			return nil, nil, false, err
+		}
+	}
+	// the slot may be in an epoch with no block processed before it.
+	if slotEpoch := slot / r.cfg.SlotsPerEpoch; slotEpoch != currentEpoch {
+		rotateParticipation(previous, current, slotEpoch-currentEpoch)
+	}
+	return previous, current, true, nil
+}
+
+// rotateParticipation is the in-place version of state_accessors.RotateEpochParticipation.
+func rotateParticipation(previous, current []byte, epochsCrossed uint64) {
+	if epochsCrossed == 1 {
+		copy(previous, current)
+	} else {
+		clear(previous)
+	}
+	clear(current)
+}
+
+// ReadStateDiff returns the validators, balances and participation flags which changed between the states at slots from and to.
+// It returns nil if either state is not available.
+func (r *HistoricalStatesReader) ReadStateDiff(tx kv.Tx, from, to uint64) (*state_accessors.StateDiff, error) {
+	if from > to {
+		return nil, ErrInvalidStateDiffRange
+	}
+	latestProcessedState, err := state_accessors.GetStateProcessingProgress(tx)
+	if err != nil {
+		return nil, err
+	}
+	if to > latestProcessedState || to > r.validatorTable.Slot() {
+		return nil, nil
+	}
+	fromData, err := state_accessors.ReadSlotData(tx, from)
+	if err != nil {
+		return nil, err
+	}
+	toData, err := state_accessors.ReadSlotData(tx, to)
+	if err != nil {
+		return nil, err
+	}
+	if fromData == nil || toData == nil {
+		return nil, nil
+	}
+	diff := &state_accessors.StateDiff{
+		FromSlot:         from,
+		ToSlot:           to,
+		ValidatorsLength: toData.ValidatorLength,
+	}
+
+	// Balances
+	fromBalances, err := r.reconstructBalances(tx, fromData.ValidatorLength, from, kv.ValidatorBalance, kv.BalancesDump)
+	if err != nil {
+		return nil, err
+	}
+	toBalances, err := r.reconstructBalances(tx, toData.ValidatorLength, to, kv.ValidatorBalance, kv.BalancesDump)
+	if err != nil {
+		return nil, err
+	}
+	forEachChangedUint64(fromBalances, toBalances, func(index, value uint64) {
+		diff.Balances = append(diff.Balances, state_accessors.IndexedBalance{Index: index, Balance: value})
+	})
+
+	// Validators: the ones touched by an event, plus the ones whose effective balance changed.
+	changedValidators, err := r.readChangedValidators(tx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	fromEffectiveBalances, err := r.reconstructDiffedUint64List(tx, fromData.ValidatorLength, from, kv.ValidatorEffectiveBalance, kv.EffectiveBalancesDump)
+	if err != nil {
+		return nil, err
+	}
+	toEffectiveBalances, err := r.reconstructDiffedUint64List(tx, toData.ValidatorLength, to, kv.ValidatorEffectiveBalance, kv.EffectiveBalancesDump)
+	if err != nil {
+		return nil, err
+	}
+	forEachChangedUint64(fromEffectiveBalances, toEffectiveBalances, func(index, _ uint64) {
+		changedValidators[index] = struct{}{}
+	})
+	indicies := make([]uint64, 0, len(changedValidators))
+	for index := range changedValidators {
+		if index < toData.ValidatorLength {
+			indicies = append(indicies, index)
+		}
+	}
+	sort.Slice(indicies, func(i, j int) bool { return indicies[i] < indicies[j] })
+	for _, index := range indicies {
+		validator := solid.NewValidator()
+		r.validatorTable.GetInPlace(index, to, validator)
+		validator.SetEffectiveBalanceFromBytes(toEffectiveBalances[index*8 : index*8+8])
+		diff.Validators = append(diff.Validators, state_accessors.IndexedValidator{Index: index, Validator: validator})
+	}
+
+	// Participation, which does not exist before altair.
+	if toData.Version < clparams.AltairVersion {
+		return diff, nil
+	}
+	var fromPrevious, fromCurrent []byte
+	if fromData.Version >= clparams.AltairVersion {
+		currentParticipation, previousParticipation, err := r.ReadParticipations(tx, from)
+		if err != nil {
+			return nil, err
+		}
+		fromPrevious, fromCurrent = previousParticipation.Bytes(), currentParticipation.Bytes()
+	}
+	toCurrent, toPrevious, err := r.ReadParticipations(tx, to)
+	if err != nil {
+		return nil, err
+	}
+	participationDiff := state_accessors.ComputeParticipationDiff(fromPrevious, fromCurrent, toPrevious.Bytes(), toCurrent.Bytes())
+	diff.PreviousEpochParticipation = participationDiff.Previous
+	diff.CurrentEpochParticipation = participationDiff.Current
+	return diff, nil
+}
+
+// readChangedValidators returns the indicies of the validators touched by a state event in the slots (from, to].
+func (r *HistoricalStatesReader) readChangedValidators(tx kv.Tx, from, to uint64) (map[uint64]struct{}, error) {
+	changed := make(map[uint64]struct{})
+	touch := func(validatorIndex uint64) error {
+		changed[validatorIndex] = struct{}{}
+		return nil
+	}
+	touchEpoch := func(validatorIndex, _ uint64) error { return touch(validatorIndex) }
+
+	cursor, err := tx.Cursor(kv.StateEvents)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+	k, v, err := cursor.Seek(base_encoding.Encode64ToBytes4(from + 1))
+	if err != nil {
+		return nil, err
+	}
+	for k != nil && base_encoding.Decode64FromBytes4(k) <= to {
+		if err := state_accessors.ReplayEvents(
+			func(validatorIndex uint64, _ solid.Validator) error { return touch(validatorIndex) },
+			touchEpoch,
+			touchEpoch,
+			func(validatorIndex uint64, _ libcommon.Hash) error { return touch(validatorIndex) },
+			touchEpoch,
+			touchEpoch,
+			func(validatorIndex uint64, _ bool) error { return touch(validatorIndex) },
+			state_accessors.NewStateEventsFromBytes(v),
+		); err != nil {
+			return nil, err
+		}
+		if k, v, err = cursor.Next(); err != nil {
+			return nil, err
+		}
+	}
+	return changed, nil
+}
+
+// forEachChangedUint64 calls fn with the index and new value of every entry of the serialized uint64 lists which differs,
+// entries missing from the old list are considered changed.
+func forEachChangedUint64(old, new []byte, fn func(index, value uint64)) {
+	for i := 0; i+8 <= len(new); i += 8 {
+		if i+8 <= len(old) && bytes.Equal(old[i:i+8], new[i:i+8]) {
+			continue
+		}
+		fn(uint64(i/8), binary.LittleEndian.Uint64(new[i:i+8]))
+	}
+}
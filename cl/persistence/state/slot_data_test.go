@@ -45,3 +45,23 @@ func TestSlotData(t *testing.T) {
 
 	require.Equal(t, m, m2)
 }
+
+func TestSlotDataElectra(t *testing.T) {
+	m := &SlotData{
+		Version:                       clparams.ElectraVersion,
+		Eth1Data:                      &cltypes.Eth1Data{},
+		Fork:                          &cltypes.Fork{Epoch: 12},
+		NextWithdrawalIndex:           3,
+		DepositRequestsStartIndex:     ^uint64(0),
+		DepositBalanceToConsume:       1,
+		ExitBalanceToConsume:          2,
+		EarliestExitEpoch:             3,
+		ConsolidationBalanceToConsume: 4,
+		EarliestConsolidationEpoch:    5,
+	}
+	var b bytes.Buffer
+	require.NoError(t, m.WriteTo(&b))
+	m2 := &SlotData{}
+	require.NoError(t, m2.ReadFrom(&b))
+	require.Equal(t, m, m2)
+}
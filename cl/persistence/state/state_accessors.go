@@ -18,14 +18,17 @@ package state_accessors
 
 import (
 	"bytes"
+	"errors"
 
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon/cl/cltypes"
 	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/merkle_tree"
 	"github.com/erigontech/erigon/cl/persistence/base_encoding"
 	"github.com/erigontech/erigon/cl/phase1/core/state"
 
 	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/length"
 )
 
 // InitializeValidatorTable initializes the validator table in the database.
@@ -313,3 +316,49 @@ func ReadActiveIndicies(tx kv.Tx, slot uint64) ([]uint64, error) {
 	buf := bytes.NewBuffer(v)
 	return base_encoding.ReadRabbits(nil, buf)
 }
+
+// EncodeDepositTreeSnapshot encodes the finalized roots of a deposit tree along with the eth1 block hash at which the
+// deposit contract held exactly tree.Count() deposits, as stored in kv.DepositTreeSnapshots.
+func EncodeDepositTreeSnapshot(tree *merkle_tree.DepositTree, executionBlockHash libcommon.Hash) []byte {
+	finalized := tree.Finalized()
+	out := make([]byte, 0, length.Hash*(len(finalized)+1))
+	out = append(out, executionBlockHash[:]...)
+	for _, root := range finalized {
+		out = append(out, root[:]...)
+	}
+	return out
+}
+
+// WriteDepositTreeSnapshot stores the snapshot of a finalized deposit tree, keyed by its deposit count.
+func WriteDepositTreeSnapshot(tx kv.RwTx, tree *merkle_tree.DepositTree, executionBlockHash libcommon.Hash) error {
+	return tx.Put(kv.DepositTreeSnapshots, base_encoding.Encode64ToBytes4(tree.Count()), EncodeDepositTreeSnapshot(tree, executionBlockHash))
+}
+
+// ReadLatestDepositTreeSnapshot reads the deposit tree snapshot with the highest deposit count.
+func ReadLatestDepositTreeSnapshot(tx kv.Tx) (tree *merkle_tree.DepositTree, executionBlockHash libcommon.Hash, err error) {
+	c, err := tx.Cursor(kv.DepositTreeSnapshots)
+	if err != nil {
+		return nil, libcommon.Hash{}, err
+	}
+	defer c.Close()
+	k, v, err := c.Last()
+	if err != nil {
+		return nil, libcommon.Hash{}, err
+	}
+	if len(k) == 0 {
+		return nil, libcommon.Hash{}, nil
+	}
+	if len(v) < length.Hash || len(v)%length.Hash != 0 {
+		return nil, libcommon.Hash{}, errors.New("invalid deposit tree snapshot encoding")
+	}
+	executionBlockHash = libcommon.BytesToHash(v[:length.Hash])
+	finalized := make([]libcommon.Hash, 0, len(v)/length.Hash-1)
+	for i := length.Hash; i < len(v); i += length.Hash {
+		finalized = append(finalized, libcommon.BytesToHash(v[i:i+length.Hash]))
+	}
+	tree, err = merkle_tree.NewDepositTreeFromSnapshot(finalized, base_encoding.Decode64FromBytes4(k))
+	if err != nil {
+		return nil, libcommon.Hash{}, err
+	}
+	return tree, executionBlockHash, nil
+}
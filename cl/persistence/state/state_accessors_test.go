@@ -0,0 +1,53 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package state_accessors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv/memdb"
+	"github.com/erigontech/erigon/cl/merkle_tree"
+	"github.com/erigontech/erigon/cl/utils"
+)
+
+func TestReadLatestDepositTreeSnapshot(t *testing.T) {
+	db := memdb.NewTestDB(t)
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	tree, _, err := ReadLatestDepositTreeSnapshot(tx)
+	require.NoError(t, err)
+	require.Nil(t, tree)
+
+	expected := &merkle_tree.DepositTree{}
+	for i := 0; i < 11; i++ {
+		require.NoError(t, expected.Push(utils.Sha256([]byte{byte(i)})))
+		blockHash := libcommon.Hash{byte(i)}
+		require.NoError(t, WriteDepositTreeSnapshot(tx, expected, blockHash))
+	}
+
+	tree, blockHash, err := ReadLatestDepositTreeSnapshot(tx)
+	require.NoError(t, err)
+	require.Equal(t, expected.Count(), tree.Count())
+	require.Equal(t, expected.Root(), tree.Root())
+	require.Equal(t, libcommon.Hash{10}, blockHash)
+}
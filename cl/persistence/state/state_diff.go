@@ -0,0 +1,196 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package state_accessors
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/persistence/base_encoding"
+)
+
+// The per-slot state diffs are made of three parts, all keyed by slot:
+//  - kv.ValidatorBalance: the balances of the slot against the balances at the start of the epoch (see base_encoding.ComputeCompressedSerializedUint64ListDiff).
+//  - kv.StateEvents: the validator events (new validators, exits, slashings...) which happened during the slot.
+//  - kv.ParticipationDiffs: the epoch participation flags changed by the slot's block (ParticipationDiff).
+// The participation of any slot can be rebuilt from the diffs of at most two epochs, as the previous epoch participation
+// is the current epoch participation of the epoch before, and the current epoch participation starts empty every epoch.
+
+var ErrInvalidParticipationDiff = errors.New("invalid participation diff")
+
+// ParticipationChange is the new participation flags of a single validator.
+type ParticipationChange struct {
+	Index uint64 `json:"index,string"`
+	Flags byte   `json:"flags,string"`
+}
+
+// ParticipationDiff holds the epoch participation flags changed by a block, against the participation the state had before
+// the block once rotated to the block's epoch (see RotateEpochParticipation).
+type ParticipationDiff struct {
+	Previous []ParticipationChange
+	Current  []ParticipationChange
+}
+
+// RotateEpochParticipation returns the participation flags of a state after crossing epochsCrossed epoch boundaries:
+// the current epoch participation becomes the previous one and the current epoch participation starts empty.
+func RotateEpochParticipation(previous, current []byte, epochsCrossed uint64) ([]byte, []byte) {
+	switch epochsCrossed {
+	case 0:
+		return previous, current
+	case 1:
+		return current, nil
+	default:
+		return nil, nil
+	}
+}
+
+// ComputeParticipationDiff computes the changes between the old and new participation flags, the old lists can be shorter
+// than the new ones, in which case the missing flags are considered empty.
+func ComputeParticipationDiff(oldPrevious, oldCurrent, newPrevious, newCurrent []byte) *ParticipationDiff {
+	return &ParticipationDiff{
+		Previous: computeParticipationChanges(oldPrevious, newPrevious),
+		Current:  computeParticipationChanges(oldCurrent, newCurrent),
+	}
+}
+
+func computeParticipationChanges(old, new []byte) []ParticipationChange {
+	var changes []ParticipationChange
+	for i, flags := range new {
+		var oldFlags byte
+		if i < len(old) {
+			oldFlags = old[i]
+		}
+		if flags != oldFlags {
+			changes = append(changes, ParticipationChange{Index: uint64(i), Flags: flags})
+		}
+	}
+	return changes
+}
+
+// Apply applies the diff to the participation flags, which must be long enough to hold every changed index.
+func (p *ParticipationDiff) Apply(previous, current []byte) error {
+	if err := applyParticipationChanges(previous, p.Previous); err != nil {
+		return err
+	}
+	return applyParticipationChanges(current, p.Current)
+}
+
+func applyParticipationChanges(participation []byte, changes []ParticipationChange) error {
+	for _, change := range changes {
+		if change.Index >= uint64(len(participation)) {
+			return ErrInvalidParticipationDiff
+		}
+		participation[change.Index] = change.Flags
+	}
+	return nil
+}
+
+// EncodeTo serializes the diff as, for each of the previous and current lists, the number of changes followed by
+// the delta-encoded indicies and their flags.
+func (p *ParticipationDiff) EncodeTo(w io.Writer) error {
+	for _, changes := range [][]ParticipationChange{p.Previous, p.Current} {
+		if _, err := w.Write(base_encoding.EncodeCompactUint64(uint64(len(changes)))); err != nil {
+			return err
+		}
+		var prevIndex uint64
+		for _, change := range changes {
+			if _, err := w.Write(base_encoding.EncodeCompactUint64(change.Index - prevIndex)); err != nil {
+				return err
+			}
+			if _, err := w.Write([]byte{change.Flags}); err != nil {
+				return err
+			}
+			prevIndex = change.Index
+		}
+	}
+	return nil
+}
+
+func (p *ParticipationDiff) DecodeFrom(r io.Reader) error {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	var err error
+	if p.Previous, err = readParticipationChanges(br, p.Previous[:0]); err != nil {
+		return err
+	}
+	p.Current, err = readParticipationChanges(br, p.Current[:0])
+	return err
+}
+
+func readParticipationChanges(r io.ByteReader, out []ParticipationChange) ([]ParticipationChange, error) {
+	count, err := base_encoding.ReadCompactUint64(r)
+	if err != nil {
+		return nil, err
+	}
+	var index uint64
+	for i := uint64(0); i < count; i++ {
+		delta, err := base_encoding.ReadCompactUint64(r)
+		if err != nil {
+			return nil, err
+		}
+		flags, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		index += delta
+		out = append(out, ParticipationChange{Index: index, Flags: flags})
+	}
+	return out, nil
+}
+
+// ReadParticipationDiff reads the participation diff of the block at the given slot, nil if there is none.
+func ReadParticipationDiff(tx kv.Tx, slot uint64) (*ParticipationDiff, error) {
+	v, err := tx.GetOne(kv.ParticipationDiffs, base_encoding.Encode64ToBytes4(slot))
+	if err != nil {
+		return nil, err
+	}
+	if len(v) == 0 {
+		return nil, nil
+	}
+	diff := &ParticipationDiff{}
+	return diff, diff.DecodeFrom(bytes.NewReader(v))
+}
+
+// IndexedValidator is a validator along with its index in the registry.
+type IndexedValidator struct {
+	Index     uint64          `json:"index,string"`
+	Validator solid.Validator `json:"validator"`
+}
+
+// IndexedBalance is a balance along with its validator index.
+type IndexedBalance struct {
+	Index   uint64 `json:"index,string"`
+	Balance uint64 `json:"balance,string"`
+}
+
+// StateDiff is the difference between the registry and participation of the beacon states at two slots: only the
+// validators, balances and participation flags which changed are present, with their values at ToSlot.
+type StateDiff struct {
+	FromSlot                   uint64                `json:"from_slot,string"`
+	ToSlot                     uint64                `json:"to_slot,string"`
+	ValidatorsLength           uint64                `json:"validators_length,string"`
+	Validators                 []IndexedValidator    `json:"validators"`
+	Balances                   []IndexedBalance      `json:"balances"`
+	PreviousEpochParticipation []ParticipationChange `json:"previous_epoch_participation"`
+	CurrentEpochParticipation  []ParticipationChange `json:"current_epoch_participation"`
+}
@@ -0,0 +1,66 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package state_accessors
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParticipationDiff(t *testing.T) {
+	oldPrevious := []byte{7, 7, 0, 3}
+	oldCurrent := []byte{1, 0, 0, 0}
+	newPrevious := []byte{7, 7, 1, 3, 0}
+	newCurrent := []byte{1, 3, 0, 0, 0, 0}
+
+	diff := ComputeParticipationDiff(oldPrevious, oldCurrent, newPrevious, newCurrent)
+	require.Equal(t, []ParticipationChange{{Index: 2, Flags: 1}}, diff.Previous)
+	require.Equal(t, []ParticipationChange{{Index: 1, Flags: 3}}, diff.Current)
+
+	var b bytes.Buffer
+	require.NoError(t, diff.EncodeTo(&b))
+	decoded := &ParticipationDiff{}
+	require.NoError(t, decoded.DecodeFrom(&b))
+	require.Equal(t, diff, decoded)
+
+	previous, current := make([]byte, len(newPrevious)), make([]byte, len(newCurrent))
+	copy(previous, oldPrevious)
+	copy(current, oldCurrent)
+	require.NoError(t, decoded.Apply(previous, current))
+	require.Equal(t, newPrevious, previous)
+	require.Equal(t, newCurrent, current)
+
+	require.ErrorIs(t, decoded.Apply(previous[:1], current[:1]), ErrInvalidParticipationDiff)
+}
+
+func TestRotateEpochParticipation(t *testing.T) {
+	previous, current := []byte{1, 2}, []byte{3, 4}
+
+	p, c := RotateEpochParticipation(previous, current, 0)
+	require.Equal(t, previous, p)
+	require.Equal(t, current, c)
+
+	p, c = RotateEpochParticipation(previous, current, 1)
+	require.Equal(t, current, p)
+	require.Empty(t, c)
+
+	p, c = RotateEpochParticipation(previous, current, 2)
+	require.Empty(t, p)
+	require.Empty(t, c)
+}
@@ -48,6 +48,11 @@ func NewStateEvents() *StateEvents {
 	return &StateEvents{}
 }
 
+// NewStateEventsFromBytes wraps the events previously serialized with CopyBytes.
+func NewStateEventsFromBytes(buf []byte) *StateEvents {
+	return &StateEvents{buf: buf}
+}
+
 func (se *StateEvents) AddValidator(validatorIndex uint64, validator solid.Validator) {
 	se.buf = append(se.buf, byte(addValidator))
 	se.buf = binary.BigEndian.AppendUint64(se.buf, validatorIndex)
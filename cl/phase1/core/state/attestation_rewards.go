@@ -0,0 +1,155 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"runtime"
+
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/utils"
+	"github.com/erigontech/erigon/cl/utils/threading"
+)
+
+// AttestationRewards are the rewards of a validator for its attestations of an epoch, penalties are negative.
+// The ideal rewards are the ones of a validator with the same effective balance attesting perfectly.
+type AttestationRewards struct {
+	EffectiveBalance uint64
+	IdealHead        int64
+	IdealTarget      int64
+	IdealSource      int64
+	Head             int64
+	Target           int64
+	Source           int64
+	Inactivity       int64
+}
+
+// AttestationRewardsCalculator computes the post-altair attestation rewards of the epoch before the given one, from the
+// previous epoch participation of the last state of the given epoch.
+type AttestationRewardsCalculator struct {
+	beaconCfg                 *clparams.BeaconChainConfig
+	validatorSet              *solid.ValidatorSet
+	inactivityScores          solid.Uint64ListSSZ
+	inactivityLeak            bool
+	prevEpoch                 uint64
+	flagsUnslashedIndiciesSet [][]bool
+	rewardMultipliers         []uint64
+	rewardDenominator         uint64
+	baseRewardPerIncrement    uint64
+	inactivityPenaltyDenom    uint64
+}
+
+func NewAttestationRewardsCalculator(beaconCfg *clparams.BeaconChainConfig, validatorSet *solid.ValidatorSet, inactivityScores solid.Uint64ListSSZ,
+	previousParticipation *solid.ParticipationBitList, inactivityLeak bool, epoch uint64) *AttestationRewardsCalculator {
+	prevEpoch := uint64(0)
+	if epoch > 0 {
+		prevEpoch = epoch - 1
+	}
+	weights := beaconCfg.ParticipationWeights()
+	flagsUnslashedIndiciesSet := make([][]bool, len(weights))
+	for i := range weights {
+		flagsUnslashedIndiciesSet[i] = make([]bool, validatorSet.Length())
+	}
+	threading.ParallellForLoop(runtime.NumCPU(), 0, validatorSet.Length(), func(validatorIndex int) error {
+		for i := range weights {
+			flagsUnslashedIndiciesSet[i][validatorIndex] = IsUnslashedParticipatingIndex(validatorSet, previousParticipation, prevEpoch, uint64(validatorIndex), i)
+		}
+		return nil
+	})
+
+	totalActiveBalance := uint64(0)
+	flagsTotalBalances := make([]uint64, len(weights))
+	validatorSet.Range(func(validatorIndex int, v solid.Validator, l int) bool {
+		if v.Active(epoch) {
+			totalActiveBalance += v.EffectiveBalance()
+		}
+		for i := range weights {
+			if flagsUnslashedIndiciesSet[i][validatorIndex] {
+				flagsTotalBalances[i] += v.EffectiveBalance()
+			}
+		}
+		return true
+	})
+	rewardMultipliers := make([]uint64, len(weights))
+	for i := range weights {
+		rewardMultipliers[i] = weights[i] * (flagsTotalBalances[i] / beaconCfg.EffectiveBalanceIncrement)
+	}
+	version := beaconCfg.GetCurrentStateVersion(epoch)
+	totalActiveBalanceSqrt := utils.IntegerSquareRoot(totalActiveBalance)
+	if totalActiveBalanceSqrt == 0 {
+		// an empty validator set, avoid the division by zero
+		totalActiveBalanceSqrt = 1
+	}
+	return &AttestationRewardsCalculator{
+		beaconCfg:                 beaconCfg,
+		validatorSet:              validatorSet,
+		inactivityScores:          inactivityScores,
+		inactivityLeak:            inactivityLeak,
+		prevEpoch:                 prevEpoch,
+		flagsUnslashedIndiciesSet: flagsUnslashedIndiciesSet,
+		rewardMultipliers:         rewardMultipliers,
+		rewardDenominator:         max(totalActiveBalance/beaconCfg.EffectiveBalanceIncrement, 1) * beaconCfg.WeightDenominator,
+		baseRewardPerIncrement:    beaconCfg.EffectiveBalanceIncrement * beaconCfg.BaseRewardFactor / totalActiveBalanceSqrt,
+		inactivityPenaltyDenom:    beaconCfg.InactivityScoreBias * beaconCfg.GetPenaltyQuotient(version),
+	}
+}
+
+// Compute returns the attestation rewards of the validator at the given index.
+func (c *AttestationRewardsCalculator) Compute(index uint64) AttestationRewards {
+	cfg := c.beaconCfg
+	v := c.validatorSet.Get(int(index))
+	effectiveBalance := v.EffectiveBalance()
+	rewards := AttestationRewards{EffectiveBalance: effectiveBalance}
+	// not eligible for rewards? then all empty
+	if !(v.Active(c.prevEpoch) || (v.Slashed() && c.prevEpoch+1 < v.WithdrawableEpoch())) {
+		return rewards
+	}
+	baseReward := (effectiveBalance / cfg.EffectiveBalanceIncrement) * c.baseRewardPerIncrement
+	if !c.inactivityLeak {
+		rewards.IdealHead = int64(baseReward * c.rewardMultipliers[cfg.TimelyHeadFlagIndex] / c.rewardDenominator)
+		rewards.IdealTarget = int64(baseReward * c.rewardMultipliers[cfg.TimelyTargetFlagIndex] / c.rewardDenominator)
+		rewards.IdealSource = int64(baseReward * c.rewardMultipliers[cfg.TimelySourceFlagIndex] / c.rewardDenominator)
+	}
+	// Note: for altair, we don't have the inclusion delay, always 0.
+	for flagIdx, weight := range cfg.ParticipationWeights() {
+		switch {
+		case c.flagsUnslashedIndiciesSet[flagIdx][index]:
+			switch flagIdx {
+			case int(cfg.TimelyHeadFlagIndex):
+				rewards.Head = rewards.IdealHead
+			case int(cfg.TimelyTargetFlagIndex):
+				rewards.Target = rewards.IdealTarget
+			case int(cfg.TimelySourceFlagIndex):
+				rewards.Source = rewards.IdealSource
+			}
+		case flagIdx != int(cfg.TimelyHeadFlagIndex):
+			// missing the head vote is not penalized
+			down := -int64(baseReward * weight / cfg.WeightDenominator)
+			switch flagIdx {
+			case int(cfg.TimelyTargetFlagIndex):
+				rewards.Target = down
+			case int(cfg.TimelySourceFlagIndex):
+				rewards.Source = down
+			}
+		}
+	}
+	if !c.flagsUnslashedIndiciesSet[cfg.TimelyTargetFlagIndex][index] {
+		inactivityScore := c.inactivityScores.Get(int(index))
+		rewards.Inactivity = -int64((effectiveBalance * inactivityScore) / c.inactivityPenaltyDenom)
+	}
+	return rewards
+}
@@ -78,6 +78,27 @@ func (b *BeaconState) CopyInto(dst *BeaconState) error {
 		dst.historicalSummaries.Append(value)
 		return true
 	})
+	dst.depositRequestsStartIndex = b.depositRequestsStartIndex
+	dst.depositBalanceToConsume = b.depositBalanceToConsume
+	dst.exitBalanceToConsume = b.exitBalanceToConsume
+	dst.earliestExitEpoch = b.earliestExitEpoch
+	dst.consolidationBalanceToConsume = b.consolidationBalanceToConsume
+	dst.earliestConsolidationEpoch = b.earliestConsolidationEpoch
+	dst.pendingDeposits = solid.NewStaticListSSZ[*solid.PendingDeposit](int(b.beaconConfig.PendingDepositsLimit), solid.PendingDepositSizeSSZ)
+	b.pendingDeposits.Range(func(_ int, value *solid.PendingDeposit, _ int) bool {
+		dst.pendingDeposits.Append(value)
+		return true
+	})
+	dst.pendingPartialWithdrawals = solid.NewStaticListSSZ[*solid.PendingPartialWithdrawal](int(b.beaconConfig.PendingPartialWithdrawalsLimit), solid.PendingPartialWithdrawalSizeSSZ)
+	b.pendingPartialWithdrawals.Range(func(_ int, value *solid.PendingPartialWithdrawal, _ int) bool {
+		dst.pendingPartialWithdrawals.Append(value)
+		return true
+	})
+	dst.pendingConsolidations = solid.NewStaticListSSZ[*solid.PendingConsolidation](int(b.beaconConfig.PendingConsolidationsLimit), solid.PendingConsolidationSizeSSZ)
+	b.pendingConsolidations.Range(func(_ int, value *solid.PendingConsolidation, _ int) bool {
+		dst.pendingConsolidations.Append(value)
+		return true
+	})
 	dst.version = b.version
 	// Now sync internals
 	copy(dst.leaves, b.leaves)
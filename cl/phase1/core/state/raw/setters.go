@@ -369,6 +369,42 @@ func (b *BeaconState) SetHistoricalSummaries(l *solid.ListSSZ[*cltypes.Historica
 	b.markLeaf(HistoricalSummariesLeafIndex)
 }
 
+func (b *BeaconState) SetDepositRequestsStartIndex(index uint64) {
+	b.depositRequestsStartIndex = index
+}
+
+func (b *BeaconState) SetDepositBalanceToConsume(balance uint64) {
+	b.depositBalanceToConsume = balance
+}
+
+func (b *BeaconState) SetExitBalanceToConsume(balance uint64) {
+	b.exitBalanceToConsume = balance
+}
+
+func (b *BeaconState) SetEarliestExitEpoch(epoch uint64) {
+	b.earliestExitEpoch = epoch
+}
+
+func (b *BeaconState) SetConsolidationBalanceToConsume(balance uint64) {
+	b.consolidationBalanceToConsume = balance
+}
+
+func (b *BeaconState) SetEarliestConsolidationEpoch(epoch uint64) {
+	b.earliestConsolidationEpoch = epoch
+}
+
+func (b *BeaconState) SetPendingDeposits(l *solid.ListSSZ[*solid.PendingDeposit]) {
+	b.pendingDeposits = l
+}
+
+func (b *BeaconState) SetPendingPartialWithdrawals(l *solid.ListSSZ[*solid.PendingPartialWithdrawal]) {
+	b.pendingPartialWithdrawals = l
+}
+
+func (b *BeaconState) SetPendingConsolidations(l *solid.ListSSZ[*solid.PendingConsolidation]) {
+	b.pendingConsolidations = l
+}
+
 func (b *BeaconState) AddHistoricalSummary(summary *cltypes.HistoricalSummary) {
 	b.historicalSummaries.Append(summary)
 	b.markLeaf(HistoricalSummariesLeafIndex)
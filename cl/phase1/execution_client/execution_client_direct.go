@@ -154,6 +154,10 @@ func (cc *ExecutionClientDirect) HasBlock(ctx context.Context, hash libcommon.Ha
 	return cc.chainRW.HasBlock(ctx, hash)
 }
 
+func (cc *ExecutionClientDirect) HeaderNumber(ctx context.Context, hash libcommon.Hash) (*uint64, error) {
+	return cc.chainRW.HeaderNumber(ctx, hash)
+}
+
 func (cc *ExecutionClientDirect) GetAssembledBlock(_ context.Context, idBytes []byte) (*cltypes.Eth1Block, *engine_types.BlobsBundleV1, *big.Int, error) {
 	return cc.chainRW.GetAssembledBlock(binary.LittleEndian.Uint64(idBytes))
 }
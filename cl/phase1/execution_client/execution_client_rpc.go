@@ -262,6 +262,21 @@ func (cc *ExecutionClientRpc) HasBlock(ctx context.Context, hash libcommon.Hash)
 	panic("unimplemented")
 }
 
+// HeaderNumber gets the number of the block with the given hash through the eth namespace exposed on the engine port.
+func (cc *ExecutionClientRpc) HeaderNumber(ctx context.Context, hash libcommon.Hash) (*uint64, error) {
+	var result *struct {
+		Number hexutil.Uint64 `json:"number"`
+	}
+	if err := cc.client.CallContext(ctx, &result, rpc_helper.GetBlockByHash, hash, false); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, nil
+	}
+	number := uint64(result.Number)
+	return &number, nil
+}
+
 // Block production
 
 func (cc *ExecutionClientRpc) GetAssembledBlock(ctx context.Context, id []byte) (*cltypes.Eth1Block, *engine_types.BlobsBundleV1, *big.Int, error) {
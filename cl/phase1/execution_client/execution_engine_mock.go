@@ -319,6 +319,45 @@ func (c *MockExecutionEngineHasBlockCall) DoAndReturn(f func(context.Context, co
 	return c
 }
 
+// HeaderNumber mocks base method.
+func (m *MockExecutionEngine) HeaderNumber(ctx context.Context, hash common.Hash) (*uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HeaderNumber", ctx, hash)
+	ret0, _ := ret[0].(*uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HeaderNumber indicates an expected call of HeaderNumber.
+func (mr *MockExecutionEngineMockRecorder) HeaderNumber(ctx, hash any) *MockExecutionEngineHeaderNumberCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeaderNumber", reflect.TypeOf((*MockExecutionEngine)(nil).HeaderNumber), ctx, hash)
+	return &MockExecutionEngineHeaderNumberCall{Call: call}
+}
+
+// MockExecutionEngineHeaderNumberCall wrap *gomock.Call
+type MockExecutionEngineHeaderNumberCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockExecutionEngineHeaderNumberCall) Return(arg0 *uint64, arg1 error) *MockExecutionEngineHeaderNumberCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockExecutionEngineHeaderNumberCall) Do(f func(context.Context, common.Hash) (*uint64, error)) *MockExecutionEngineHeaderNumberCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockExecutionEngineHeaderNumberCall) DoAndReturn(f func(context.Context, common.Hash) (*uint64, error)) *MockExecutionEngineHeaderNumberCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // HasGapInSnapshots mocks base method.
 func (m *MockExecutionEngine) HasGapInSnapshots(ctx context.Context) bool {
 	m.ctrl.T.Helper()
@@ -58,6 +58,7 @@ type GossipManager struct {
 	// Services for processing messages from the network
 	blockService                 services.BlockService
 	blobService                  services.BlobSidecarsService
+	dataColumnSidecarService     services.DataColumnSidecarService
 	syncCommitteeMessagesService services.SyncCommitteeMessagesService
 	syncContributionService      services.SyncContributionService
 	aggregateAndProofService     services.AggregateAndProofService
@@ -77,6 +78,7 @@ func NewGossipReceiver(
 	comitteeSub *committee_subscription.CommitteeSubscribeMgmt,
 	blockService services.BlockService,
 	blobService services.BlobSidecarsService,
+	dataColumnSidecarService services.DataColumnSidecarService,
 	syncCommitteeMessagesService services.SyncCommitteeMessagesService,
 	syncContributionService services.SyncContributionService,
 	aggregateAndProofService services.AggregateAndProofService,
@@ -95,6 +97,7 @@ func NewGossipReceiver(
 		committeeSub:                 comitteeSub,
 		blockService:                 blockService,
 		blobService:                  blobService,
+		dataColumnSidecarService:     dataColumnSidecarService,
 		syncCommitteeMessagesService: syncCommitteeMessagesService,
 		syncContributionService:      syncContributionService,
 		aggregateAndProofService:     aggregateAndProofService,
@@ -139,15 +142,16 @@ func (g *GossipManager) onRecv(ctx context.Context, data *sentinel.GossipData, l
 	}
 	monitor.ObserveGossipTopicSeen(data.Name, len(data.Data))
 
+	// feed the validation result into the gossipsub score of the peer: only rejected messages count against it, neither
+	// ignored messages nor failures of our own do.
 	if err := g.routeAndProcess(ctx, data); err != nil {
+		if errors.Is(err, services.ErrReject) && data.Peer != nil {
+			g.sentinel.PenalizePeer(ctx, data.Peer)
+		}
 		return err
 	}
-	if errors.Is(err, services.ErrIgnore) {
-		return nil
-	}
-	if err != nil {
-		g.sentinel.BanPeer(ctx, data.Peer)
-		return err
+	if data.Peer != nil {
+		g.sentinel.RewardPeer(ctx, data.Peer)
 	}
 	if _, err := g.sentinel.PublishGossip(ctx, data); err != nil {
 		log.Warn("failed publish gossip", "err", err)
@@ -192,27 +196,27 @@ func (g *GossipManager) routeAndProcess(ctx context.Context, data *sentinel.Goss
 	case gossip.TopicNameBeaconBlock:
 		obj := cltypes.NewSignedBeaconBlock(g.beaconConfig, version)
 		if err := obj.DecodeSSZ(data.Data, int(version)); err != nil {
-			return err
+			return fmt.Errorf("%w: %w", services.ErrReject, err)
 		}
 		log.Debug("Received block via gossip", "slot", obj.Block.Slot)
 		return g.blockService.ProcessMessage(ctx, data.SubnetId, obj)
 	case gossip.TopicNameSyncCommitteeContributionAndProof:
 		obj := &cltypes.SignedContributionAndProof{}
 		if err := obj.DecodeSSZ(data.Data, int(version)); err != nil {
-			return err
+			return fmt.Errorf("%w: %w", services.ErrReject, err)
 		}
 		return g.syncContributionService.ProcessMessage(ctx, data.SubnetId, obj)
 	case gossip.TopicNameVoluntaryExit:
 		obj := &cltypes.SignedVoluntaryExit{}
 		if err := obj.DecodeSSZ(data.Data, int(version)); err != nil {
-			return err
+			return fmt.Errorf("%w: %w", services.ErrReject, err)
 		}
 		return g.voluntaryExitService.ProcessMessage(ctx, data.SubnetId, obj)
 
 	case gossip.TopicNameProposerSlashing:
 		obj := &cltypes.ProposerSlashing{}
 		if err := obj.DecodeSSZ(data.Data, int(version)); err != nil {
-			return err
+			return fmt.Errorf("%w: %w", services.ErrReject, err)
 		}
 		return g.proposerSlashingService.ProcessMessage(ctx, data.SubnetId, obj)
 	case gossip.TopicNameAttesterSlashing:
@@ -223,7 +227,7 @@ func (g *GossipManager) routeAndProcess(ctx context.Context, data *sentinel.Goss
 			SignedBLSToExecutionChange: &cltypes.SignedBLSToExecutionChange{},
 		}
 		if err := obj.SignedBLSToExecutionChange.DecodeSSZ(data.Data, int(version)); err != nil {
-			return err
+			return fmt.Errorf("%w: %w", services.ErrReject, err)
 		}
 		return g.blsToExecutionChangeService.ProcessMessage(ctx, data.SubnetId, obj)
 	case gossip.TopicNameBeaconAggregateAndProof:
@@ -233,7 +237,7 @@ func (g *GossipManager) routeAndProcess(ctx context.Context, data *sentinel.Goss
 		}
 
 		if err := obj.SignedAggregateAndProof.DecodeSSZ(common.CopyBytes(data.Data), int(version)); err != nil {
-			return err
+			return fmt.Errorf("%w: %w", services.ErrReject, err)
 		}
 		return g.aggregateAndProofService.ProcessMessage(ctx, data.SubnetId, obj)
 	default:
@@ -242,15 +246,22 @@ func (g *GossipManager) routeAndProcess(ctx context.Context, data *sentinel.Goss
 			// decode sidecar
 			blobSideCar := &cltypes.BlobSidecar{}
 			if err := blobSideCar.DecodeSSZ(data.Data, int(version)); err != nil {
-				return err
+				return fmt.Errorf("%w: %w", services.ErrReject, err)
 			}
 			defer log.Debug("Received blob sidecar via gossip", "index", *data.SubnetId, "size", datasize.ByteSize(len(blobSideCar.Blob)))
 			// The background checks above are enough for now.
 			return g.blobService.ProcessMessage(ctx, data.SubnetId, blobSideCar)
+		case gossip.IsTopicDataColumnSidecar(data.Name):
+			sidecar := cltypes.NewDataColumnSidecar()
+			if err := sidecar.DecodeSSZ(data.Data, int(version)); err != nil {
+				return fmt.Errorf("%w: %w", services.ErrReject, err)
+			}
+			defer log.Debug("Received data column sidecar via gossip", "index", sidecar.Index, "blobs", sidecar.Column.Len())
+			return g.dataColumnSidecarService.ProcessMessage(ctx, data.SubnetId, sidecar)
 		case gossip.IsTopicSyncCommittee(data.Name):
 			msg := &cltypes.SyncCommitteeMessage{}
 			if err := msg.DecodeSSZ(common.CopyBytes(data.Data), int(version)); err != nil {
-				return err
+				return fmt.Errorf("%w: %w", services.ErrReject, err)
 			}
 			return g.syncCommitteeMessagesService.ProcessMessage(ctx, data.SubnetId, msg)
 		case gossip.IsTopicBeaconAttestation(data.Name):
@@ -261,7 +272,7 @@ func (g *GossipManager) routeAndProcess(ctx context.Context, data *sentinel.Goss
 			}
 
 			if err := obj.Attestation.DecodeSSZ(common.CopyBytes(data.Data), int(version)); err != nil {
-				return err
+				return fmt.Errorf("%w: %w", services.ErrReject, err)
 			}
 
 			if g.committeeSub.NeedToAggregate(obj.Attestation) {
@@ -314,7 +325,7 @@ func (g *GossipManager) Start(ctx context.Context) {
 
 	sendOrDrop := func(ch chan<- *sentinel.GossipData, data *sentinel.GossipData) {
 		// Skip processing the received data if the node is not ready to process operations.
-		if !g.isReadyToProcessOperations() && data.Name != gossip.TopicNameBeaconBlock && !gossip.IsTopicBlobSidecar(data.Name) {
+		if !g.isReadyToProcessOperations() && data.Name != gossip.TopicNameBeaconBlock && !gossip.IsTopicBlobSidecar(data.Name) && !gossip.IsTopicDataColumnSidecar(data.Name) {
 			return
 		}
 		select {
@@ -351,7 +362,7 @@ Reconnect:
 			switch {
 			case data.Name == gossip.TopicNameBeaconBlock:
 				sendOrDrop(blocksCh, data)
-			case gossip.IsTopicBlobSidecar(data.Name):
+			case gossip.IsTopicBlobSidecar(data.Name) || gossip.IsTopicDataColumnSidecar(data.Name):
 				sendOrDrop(blobsCh, data)
 			case gossip.IsTopicSyncCommittee(data.Name) || data.Name == gossip.TopicNameSyncCommitteeContributionAndProof:
 				sendOrDrop(syncCommitteesCh, data)
@@ -18,7 +18,6 @@ package services
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"slices"
 	"sync"
@@ -153,11 +152,11 @@ func (a *aggregateAndProofServiceImpl) ProcessMessage(
 	// [REJECT] The committee index is within the expected range -- i.e. index < get_committee_count_per_slot(state, aggregate.data.target.epoch).
 	committeeCountPerSlot := headState.CommitteeCount(target.Epoch)
 	if committeeIndex >= committeeCountPerSlot {
-		return errors.New("invalid committee index in aggregate and proof")
+		return fmt.Errorf("%w: invalid committee index in aggregate and proof", ErrReject)
 	}
 	// [REJECT] The aggregate attestation's epoch matches its target -- i.e. aggregate.data.target.epoch == compute_epoch_at_slot(aggregate.data.slot)
 	if aggregateData.Target.Epoch != epoch {
-		return errors.New("invalid target epoch in aggregate and proof")
+		return fmt.Errorf("%w: invalid target epoch in aggregate and proof", ErrReject)
 	}
 	committee, err := headState.GetBeaconCommitee(slot, committeeIndex)
 	if err != nil {
@@ -169,21 +168,21 @@ func (a *aggregateAndProofServiceImpl) ProcessMessage(
 		return err
 	}
 	if len(attestingIndices) == 0 {
-		return errors.New("no attesting indicies")
+		return fmt.Errorf("%w: no attesting indicies", ErrReject)
 	}
 
 	monitor.ObserveNumberOfAggregateSignatures(len(attestingIndices))
 
 	// [REJECT] The aggregator's validator index is within the committee -- i.e. aggregate_and_proof.aggregator_index in get_beacon_committee(state, aggregate.data.slot, index).
 	if !slices.Contains(committee, aggregateAndProof.SignedAggregateAndProof.Message.AggregatorIndex) {
-		return errors.New("committee index not in committee")
+		return fmt.Errorf("%w: committee index not in committee", ErrReject)
 	}
 	// [REJECT] The aggregate attestation's target block is an ancestor of the block named in the LMD vote -- i.e. get_checkpoint_block(store, aggregate.data.beacon_block_root, aggregate.data.target.epoch) == aggregate.data.target.root
 	if a.forkchoiceStore.Ancestor(
 		aggregateData.BeaconBlockRoot,
 		target.Epoch*a.beaconCfg.SlotsPerEpoch,
 	) != target.Root {
-		return errors.New("invalid target block")
+		return fmt.Errorf("%w: invalid target block", ErrReject)
 	}
 	if a.test {
 		return nil
@@ -192,7 +191,7 @@ func (a *aggregateAndProofServiceImpl) ProcessMessage(
 	// [REJECT] aggregate_and_proof.selection_proof selects the validator as an aggregator for the slot -- i.e. is_aggregator(state, aggregate.data.slot, index, aggregate_and_proof.selection_proof) returns True.
 	if !state.IsAggregator(a.beaconCfg, uint64(len(committee)), committeeIndex, selectionProof) {
 		log.Warn("receveived aggregate and proof from invalid aggregator")
-		return errors.New("invalid aggregate and proof")
+		return fmt.Errorf("%w: invalid aggregate and proof", ErrReject)
 	}
 
 	// aggregate signatures for later verification
@@ -313,7 +312,7 @@ func AggregateMessageSignature(
 
 	inds := indexedAttestation.AttestingIndices
 	if inds.Length() == 0 {
-		return nil, nil, nil, errors.New("isValidIndexedAttestation: attesting indices are not sorted or are null")
+		return nil, nil, nil, fmt.Errorf("%w: isValidIndexedAttestation: attesting indices are not sorted or are null", ErrReject)
 	}
 
 	pks := make([][]byte, 0, inds.Length())
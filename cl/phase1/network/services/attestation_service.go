@@ -135,12 +135,12 @@ func (s *attestationService) ProcessMessage(ctx context.Context, subnet *uint64,
 	// [REJECT] The committee index is within the expected range
 	committeeCount := computeCommitteeCountPerSlot(headState, slot, s.beaconCfg.SlotsPerEpoch)
 	if committeeIndex >= committeeCount {
-		return fmt.Errorf("committee index out of range, %d >= %d", committeeIndex, committeeCount)
+		return fmt.Errorf("%w: committee index out of range, %d >= %d", ErrReject, committeeIndex, committeeCount)
 	}
 	// [REJECT] The attestation is for the correct subnet -- i.e. compute_subnet_for_attestation(committees_per_slot, attestation.data.slot, index) == subnet_id
 	subnetId := computeSubnetForAttestation(committeeCount, slot, committeeIndex, s.beaconCfg.SlotsPerEpoch, s.netCfg.AttestationSubnetCount)
 	if subnet == nil || subnetId != *subnet {
-		return errors.New("wrong subnet")
+		return fmt.Errorf("%w: wrong subnet", ErrReject)
 	}
 	// [IGNORE] attestation.data.slot is within the last ATTESTATION_PROPAGATION_SLOT_RANGE slots (within a MAXIMUM_GOSSIP_CLOCK_DISPARITY allowance) --
 	// i.e. attestation.data.slot + ATTESTATION_PROPAGATION_SLOT_RANGE >= current_slot >= attestation.data.slot (a client MAY queue future attestations for processing at the appropriate slot).
@@ -150,7 +150,7 @@ func (s *attestationService) ProcessMessage(ctx context.Context, subnet *uint64,
 	}
 	// [REJECT] The attestation's epoch matches its target -- i.e. attestation.data.target.epoch == compute_epoch_at_slot(attestation.data.slot)
 	if targetEpoch != slot/s.beaconCfg.SlotsPerEpoch {
-		return errors.New("epoch mismatch")
+		return fmt.Errorf("%w: epoch mismatch", ErrReject)
 	}
 	// [REJECT] The number of aggregation bits matches the committee size -- i.e. len(aggregation_bits) == len(get_beacon_committee(state, attestation.data.slot, index)).
 	beaconCommittee, err := s.forkchoiceStore.GetBeaconCommitee(slot, committeeIndex)
@@ -161,7 +161,7 @@ func (s *attestationService) ProcessMessage(ctx context.Context, subnet *uint64,
 	expectedAggregationBitsLength := len(beaconCommittee)
 	actualAggregationBitsLength := utils.GetBitlistLength(bits)
 	if actualAggregationBitsLength != expectedAggregationBitsLength {
-		return fmt.Errorf("aggregation bits count mismatch: %d != %d", actualAggregationBitsLength, expectedAggregationBitsLength)
+		return fmt.Errorf("%w: aggregation bits count mismatch: %d != %d", ErrReject, actualAggregationBitsLength, expectedAggregationBitsLength)
 	}
 
 	//[REJECT] The attestation is unaggregated -- that is, it has exactly one participating validator (len([bit for bit in aggregation_bits if bit]) == 1, i.e. exactly 1 bit is set).
@@ -183,14 +183,14 @@ func (s *attestationService) ProcessMessage(ctx context.Context, subnet *uint64,
 		return ErrIgnore // Ignore if it is just an empty bitlist
 	}
 	if setBits != 1 {
-		return errors.New("attestation does not have exactly one participating validator")
+		return fmt.Errorf("%w: attestation does not have exactly one participating validator", ErrReject)
 	}
 	// [IGNORE] There has been no other valid attestation seen on an attestation subnet that has an identical attestation.data.target.epoch and participating validator index.
 	if err != nil {
 		return err
 	}
 	if onBitIndex >= len(beaconCommittee) {
-		return errors.New("on bit index out of committee range")
+		return fmt.Errorf("%w: on bit index out of committee range", ErrReject)
 	}
 	// mark the validator as seen
 	vIndex := beaconCommittee[onBitIndex]
@@ -226,7 +226,7 @@ func (s *attestationService) ProcessMessage(ctx context.Context, subnet *uint64,
 	// get_checkpoint_block(store, attestation.data.beacon_block_root, attestation.data.target.epoch) == attestation.data.target.root
 	startSlotAtEpoch := targetEpoch * s.beaconCfg.SlotsPerEpoch
 	if targetBlock := s.forkchoiceStore.Ancestor(root, startSlotAtEpoch); targetBlock != att.Attestation.Data.Target.Root {
-		return fmt.Errorf("invalid target block. root %v targetEpoch %v attTargetBlockRoot %v targetBlock %v", root.Hex(), targetEpoch, att.Attestation.Data.Target.Root.Hex(), targetBlock.Hex())
+		return fmt.Errorf("%w: invalid target block. root %v targetEpoch %v attTargetBlockRoot %v targetBlock %v", ErrReject, root.Hex(), targetEpoch, att.Attestation.Data.Target.Root.Hex(), targetBlock.Hex())
 	}
 	// [IGNORE] The current finalized_checkpoint is an ancestor of the block defined by attestation.data.beacon_block_root --
 	// i.e. get_checkpoint_block(store, attestation.data.beacon_block_root, store.finalized_checkpoint.epoch) == store.finalized_checkpoint.root
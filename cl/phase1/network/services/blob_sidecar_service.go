@@ -92,7 +92,7 @@ func (b *blobSidecarService) ProcessMessage(ctx context.Context, subnetId *uint6
 
 	// [REJECT] The sidecar's index is consistent with MAX_BLOBS_PER_BLOCK -- i.e. blob_sidecar.index < MAX_BLOBS_PER_BLOCK.
 	if msg.Index >= b.beaconCfg.MaxBlobsPerBlock {
-		return errors.New("blob index out of range")
+		return fmt.Errorf("%w: blob index out of range", ErrReject)
 	}
 	sidecarSubnetIndex := msg.Index % b.beaconCfg.MaxBlobsPerBlock
 	if sidecarSubnetIndex != *subnetId {
@@ -145,7 +145,7 @@ func (b *blobSidecarService) verifyAndStoreBlobSidecar(headState *state.CachingB
 
 	start := time.Now()
 	if err := kzgCtx.VerifyBlobKZGProof(gokzg4844.Blob(msg.Blob), gokzg4844.KZGCommitment(msg.KzgCommitment), gokzg4844.KZGProof(msg.KzgProof)); err != nil {
-		return fmt.Errorf("blob KZG proof verification failed: %v", err)
+		return fmt.Errorf("%w: blob KZG proof verification failed: %v", ErrReject, err)
 	}
 	if !b.test {
 		if err := b.verifySidecarsSignature(headState, msg.SignedBlockHeader); err != nil {
@@ -158,13 +158,18 @@ func (b *blobSidecarService) verifyAndStoreBlobSidecar(headState *state.CachingB
 }
 
 func (b *blobSidecarService) verifySidecarsSignature(headState *state.CachingBeaconState, header *cltypes.SignedBeaconBlockHeader) error {
-	parentHeader, ok := b.forkchoiceStore.GetHeader(header.Header.ParentRoot)
+	return verifySidecarHeaderSignature(b.beaconCfg, b.forkchoiceStore, headState, header)
+}
+
+// verifySidecarHeaderSignature checks the proposer signature of the block header carried by a sidecar.
+func verifySidecarHeaderSignature(beaconCfg *clparams.BeaconChainConfig, forkchoiceStore forkchoice.ForkChoiceStorage, headState *state.CachingBeaconState, header *cltypes.SignedBeaconBlockHeader) error {
+	parentHeader, ok := forkchoiceStore.GetHeader(header.Header.ParentRoot)
 	if !ok {
 		return errors.New("parent header not found")
 	}
-	currentVersion := b.beaconCfg.GetCurrentStateVersion(parentHeader.Slot / b.beaconCfg.SlotsPerEpoch)
-	forkVersion := b.beaconCfg.GetForkVersionByVersion(currentVersion)
-	domain, err := fork.ComputeDomain(b.beaconCfg.DomainBeaconProposer[:], utils.Uint32ToBytes4(forkVersion), headState.GenesisValidatorsRoot())
+	currentVersion := beaconCfg.GetCurrentStateVersion(parentHeader.Slot / beaconCfg.SlotsPerEpoch)
+	forkVersion := beaconCfg.GetForkVersionByVersion(currentVersion)
+	domain, err := fork.ComputeDomain(beaconCfg.DomainBeaconProposer[:], utils.Uint32ToBytes4(forkVersion), headState.GenesisValidatorsRoot())
 	if err != nil {
 		return err
 	}
@@ -180,7 +185,7 @@ func (b *blobSidecarService) verifySidecarsSignature(headState *state.CachingBea
 		return err
 	}
 	if !ok {
-		return errors.New("blob signature validation: signature not valid")
+		return fmt.Errorf("%w: blob signature validation: signature not valid", ErrReject)
 	}
 	return nil
 }
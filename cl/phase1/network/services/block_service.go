@@ -18,7 +18,7 @@ package services
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -38,7 +38,7 @@ import (
 )
 
 var (
-	ErrInvalidSignature = errors.New("invalid signature")
+	ErrInvalidSignature = fmt.Errorf("%w: invalid signature", ErrReject)
 )
 
 type proposerIndexAndSlot struct {
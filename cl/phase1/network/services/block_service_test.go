@@ -50,7 +50,7 @@ func TestBlockServiceUnsynced(t *testing.T) {
 	blocks, _, _ := tests.GetBellatrixRandom()
 
 	blockService, _, _, _ := setupBlockService(t, ctrl)
-	require.Error(t, blockService.ProcessMessage(context.Background(), nil, blocks[0]))
+	require.ErrorIs(t, blockService.ProcessMessage(context.Background(), nil, blocks[0]), ErrIgnore)
 }
 
 func TestBlockServiceIgnoreSlot(t *testing.T) {
@@ -64,7 +64,7 @@ func TestBlockServiceIgnoreSlot(t *testing.T) {
 	ethClock.EXPECT().GetCurrentSlot().Return(uint64(0)).AnyTimes()
 	ethClock.EXPECT().IsSlotCurrentSlotWithMaximumClockDisparity(gomock.Any()).Return(false).AnyTimes()
 
-	require.Error(t, blockService.ProcessMessage(context.Background(), nil, blocks[0]))
+	require.ErrorIs(t, blockService.ProcessMessage(context.Background(), nil, blocks[0]), ErrIgnore)
 }
 
 func TestBlockServiceLowerThanFinalizedCheckpoint(t *testing.T) {
@@ -80,7 +80,7 @@ func TestBlockServiceLowerThanFinalizedCheckpoint(t *testing.T) {
 	fcu.FinalizedCheckpointVal = post.FinalizedCheckpoint()
 	blocks[0].Block.Slot = 0
 
-	require.Error(t, blockService.ProcessMessage(context.Background(), nil, blocks[0]))
+	require.ErrorIs(t, blockService.ProcessMessage(context.Background(), nil, blocks[0]), ErrIgnore)
 }
 
 func TestBlockServiceUnseenParentRoot(t *testing.T) {
@@ -112,7 +112,7 @@ func TestBlockServiceYoungerThanParent(t *testing.T) {
 	fcu.Headers[blocks[1].Block.ParentRoot] = blocks[0].SignedBeaconBlockHeader().Header.Copy()
 	blocks[1].Block.Slot--
 
-	require.Error(t, blockService.ProcessMessage(context.Background(), nil, blocks[1]))
+	require.ErrorIs(t, blockService.ProcessMessage(context.Background(), nil, blocks[1]), ErrReject)
 }
 
 func TestBlockServiceInvalidCommitmentsPerBlock(t *testing.T) {
@@ -132,7 +132,7 @@ func TestBlockServiceInvalidCommitmentsPerBlock(t *testing.T) {
 	for i := 0; i < 100; i++ {
 		blocks[1].Block.Body.BlobKzgCommitments.Append(&cltypes.KZGCommitment{})
 	}
-	require.Error(t, blockService.ProcessMessage(context.Background(), nil, blocks[1]))
+	require.ErrorIs(t, blockService.ProcessMessage(context.Background(), nil, blocks[1]), ErrReject)
 }
 
 func TestBlockServiceSuccess(t *testing.T) {
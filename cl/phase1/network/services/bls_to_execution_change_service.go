@@ -19,7 +19,6 @@ package services
 import (
 	"bytes"
 	"context"
-	"errors"
 	"fmt"
 
 	"github.com/Giulio2002/bls"
@@ -92,7 +91,7 @@ func (s *blsToExecutionChangeService) ProcessMessage(ctx context.Context, subnet
 
 	// assert validator.withdrawal_credentials[:1] == BLS_WITHDRAWAL_PREFIX
 	if wc[0] != byte(s.beaconCfg.BLSWithdrawalPrefixByte) {
-		return errors.New("invalid withdrawal credentials prefix")
+		return fmt.Errorf("%w: invalid withdrawal credentials prefix", ErrReject)
 	}
 
 	// assert validator.withdrawal_credentials[1:] == hash(address_change.from_bls_pubkey)[1:]
@@ -100,7 +99,7 @@ func (s *blsToExecutionChangeService) ProcessMessage(ctx context.Context, subnet
 	// Check the validator's withdrawal credentials against the provided message.
 	hashedFrom := utils.Sha256(change.From[:])
 	if !bytes.Equal(hashedFrom[1:], wc[1:]) {
-		return errors.New("invalid withdrawal credentials hash")
+		return fmt.Errorf("%w: invalid withdrawal credentials hash", ErrReject)
 	}
 
 	// assert bls.Verify(address_change.from_bls_pubkey, signing_root, signed_address_change.signature)
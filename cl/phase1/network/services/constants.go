@@ -18,6 +18,7 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"time"
 )
 
@@ -37,9 +38,11 @@ const (
 
 var (
 	ErrIgnore                          = errors.New("ignore") // ErrIgnore is used to indicate that the message should be ignored.
-	ErrBlockYoungerThanParent          = errors.New("block is younger than parent")
-	ErrInvalidCommitmentsCount         = errors.New("invalid commitments count")
-	ErrCommitmentsInclusionProofFailed = errors.New("commitments inclusion proof failed")
-	ErrInvalidSidecarSlot              = errors.New("invalid sidecar slot")
-	ErrBlobIndexOutOfRange             = errors.New("blob index out of range")
+	ErrReject                          = errors.New("reject") // ErrReject is wrapped by errors of messages which failed validation, their sender gets penalized.
+	ErrBlockYoungerThanParent          = fmt.Errorf("%w: block is younger than parent", ErrReject)
+	ErrInvalidCommitmentsCount         = fmt.Errorf("%w: invalid commitments count", ErrReject)
+	ErrCommitmentsInclusionProofFailed = fmt.Errorf("%w: commitments inclusion proof failed", ErrReject)
+	ErrInvalidSidecarSlot              = fmt.Errorf("%w: invalid sidecar slot", ErrReject)
+	ErrBlobIndexOutOfRange             = fmt.Errorf("%w: blob index out of range", ErrReject)
+	ErrInvalidDataColumnSubnet         = fmt.Errorf("%w: data column sidecar on the wrong subnet", ErrReject)
 )
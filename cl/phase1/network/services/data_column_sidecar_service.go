@@ -0,0 +1,127 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/erigontech/erigon/cl/beacon/synced_data"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/das"
+	"github.com/erigontech/erigon/cl/monitor"
+	"github.com/erigontech/erigon/cl/persistence/blob_storage"
+	"github.com/erigontech/erigon/cl/phase1/forkchoice"
+	"github.com/erigontech/erigon/cl/utils/eth_clock"
+)
+
+type dataColumnSidecarService struct {
+	forkchoiceStore   forkchoice.ForkChoiceStorage
+	beaconCfg         *clparams.BeaconChainConfig
+	syncedDataManager *synced_data.SyncedDataManager
+	ethClock          eth_clock.EthereumClock
+	blobStorage       blob_storage.BlobStorage
+}
+
+// NewDataColumnSidecarService creates a new data column sidecar service
+func NewDataColumnSidecarService(
+	beaconCfg *clparams.BeaconChainConfig,
+	forkchoiceStore forkchoice.ForkChoiceStorage,
+	syncedDataManager *synced_data.SyncedDataManager,
+	ethClock eth_clock.EthereumClock,
+	blobStorage blob_storage.BlobStorage,
+) DataColumnSidecarService {
+	return &dataColumnSidecarService{
+		beaconCfg:         beaconCfg,
+		forkchoiceStore:   forkchoiceStore,
+		syncedDataManager: syncedDataManager,
+		ethClock:          ethClock,
+		blobStorage:       blobStorage,
+	}
+}
+
+// ProcessMessage processes a data column sidecar message
+func (d *dataColumnSidecarService) ProcessMessage(ctx context.Context, subnetId *uint64, msg *cltypes.DataColumnSidecar) error {
+	headState := d.syncedDataManager.HeadState()
+	if headState == nil {
+		return ErrIgnore
+	}
+	sidecarSlot := msg.SignedBlockHeader.Header.Slot
+	if !d.beaconCfg.IsPeerDASActive(sidecarSlot / d.beaconCfg.SlotsPerEpoch) {
+		return ErrIgnore
+	}
+
+	// [REJECT] The sidecar is valid as verified by verify_data_column_sidecar(sidecar).
+	if err := cltypes.VerifyDataColumnSidecar(msg, d.beaconCfg); err != nil {
+		return err
+	}
+	// [REJECT] The sidecar is for the correct subnet -- i.e. compute_subnet_for_data_column_sidecar(sidecar.index) == subnet_id.
+	if subnetId == nil || das.ComputeSubnetForDataColumnSidecar(msg.Index, d.beaconCfg) != *subnetId {
+		return ErrInvalidDataColumnSubnet
+	}
+	currentSlot := d.ethClock.GetCurrentSlot()
+	// [IGNORE] The sidecar is not from a future slot (with a MAXIMUM_GOSSIP_CLOCK_DISPARITY allowance).
+	if currentSlot < sidecarSlot && !d.ethClock.IsSlotCurrentSlotWithMaximumClockDisparity(sidecarSlot) {
+		return ErrIgnore
+	}
+	// [IGNORE] The sidecar is from a slot greater than the latest finalized slot.
+	if d.forkchoiceStore.FinalizedSlot() >= sidecarSlot {
+		return ErrIgnore
+	}
+
+	blockRoot, err := msg.SignedBlockHeader.Header.HashSSZ()
+	if err != nil {
+		return err
+	}
+	// [IGNORE] The sidecar is the first sidecar for the tuple (block_header.slot, block_header.proposer_index, sidecar.index).
+	stored, err := d.blobStorage.DataColumnIndicies(ctx, blockRoot)
+	if err != nil {
+		return err
+	}
+	if _, found := slices.BinarySearch(stored, msg.Index); found {
+		return ErrIgnore
+	}
+
+	// [IGNORE] The sidecar's block's parent has been seen.
+	parentHeader, has := d.forkchoiceStore.GetHeader(msg.SignedBlockHeader.Header.ParentRoot)
+	if !has {
+		return ErrIgnore
+	}
+	// [REJECT] The sidecar is from a higher slot than the sidecar's block's parent.
+	if sidecarSlot <= parentHeader.Slot {
+		return ErrInvalidSidecarSlot
+	}
+
+	// [REJECT] The sidecar's kzg_commitments field inclusion proof is valid.
+	if !cltypes.VerifyDataColumnSidecarInclusionProof(msg) {
+		return ErrCommitmentsInclusionProofFailed
+	}
+	start := time.Now()
+	// [REJECT] The sidecar's column data is valid as verified by verify_data_column_sidecar_kzg_proofs(sidecar).
+	if err := das.VerifyDataColumnSidecarKZGProofs(msg); err != nil {
+		return fmt.Errorf("%w: data column KZG proof verification failed: %v", ErrReject, err)
+	}
+	// [REJECT] The proposer signature of sidecar.signed_block_header is valid with respect to the block_header.proposer_index pubkey.
+	if err := verifySidecarHeaderSignature(d.beaconCfg, d.forkchoiceStore, headState, msg.SignedBlockHeader); err != nil {
+		return err
+	}
+	monitor.ObserveBlobVerificationTime(start)
+	return d.blobStorage.WriteDataColumnSidecars(ctx, blockRoot, []*cltypes.DataColumnSidecar{msg})
+}
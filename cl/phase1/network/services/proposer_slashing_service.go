@@ -18,7 +18,6 @@ package services
 
 import (
 	"context"
-	"errors"
 	"fmt"
 
 	"github.com/erigontech/erigon/cl/beacon/beaconevents"
@@ -78,17 +77,17 @@ func (s *proposerSlashingService) ProcessMessage(ctx context.Context, subnet *ui
 
 	// Verify header slots match
 	if h1.Slot != h2.Slot {
-		return fmt.Errorf("non-matching slots on proposer slashing: %d != %d", h1.Slot, h2.Slot)
+		return fmt.Errorf("%w: non-matching slots on proposer slashing: %d != %d", ErrReject, h1.Slot, h2.Slot)
 	}
 
 	// Verify header proposer indices match
 	if h1.ProposerIndex != h2.ProposerIndex {
-		return fmt.Errorf("non-matching proposer indices proposer slashing: %d != %d", h1.ProposerIndex, h2.ProposerIndex)
+		return fmt.Errorf("%w: non-matching proposer indices proposer slashing: %d != %d", ErrReject, h1.ProposerIndex, h2.ProposerIndex)
 	}
 
 	// Verify the headers are different
 	if *h1 == *h2 {
-		return errors.New("proposee slashing headers are the same")
+		return fmt.Errorf("%w: proposee slashing headers are the same", ErrReject)
 	}
 
 	// Verify the proposer is slashable
@@ -101,7 +100,7 @@ func (s *proposerSlashingService) ProcessMessage(ctx context.Context, subnet *ui
 		return fmt.Errorf("unable to retrieve state: %v", err)
 	}
 	if !proposer.IsSlashable(s.ethClock.GetCurrentEpoch()) {
-		return fmt.Errorf("proposer is not slashable: %v", proposer)
+		return fmt.Errorf("%w: proposer is not slashable: %v", ErrReject, proposer)
 	}
 
 	// Verify signatures for both headers
@@ -120,7 +119,7 @@ func (s *proposerSlashingService) ProcessMessage(ctx context.Context, subnet *ui
 			return fmt.Errorf("unable to verify signature: %v", err)
 		}
 		if !valid {
-			return fmt.Errorf("invalid signature: signature %v, root %v, pubkey %v", signedHeader.Signature[:], signingRoot[:], pk)
+			return fmt.Errorf("%w: invalid signature: signature %v, root %v, pubkey %v", ErrReject, signedHeader.Signature[:], signingRoot[:], pk)
 		}
 	}
 
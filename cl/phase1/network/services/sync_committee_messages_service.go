@@ -18,7 +18,6 @@ package services
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"slices"
 	"sync"
@@ -96,7 +95,7 @@ func (s *syncCommitteeMessagesService) ProcessMessage(ctx context.Context, subne
 	}
 
 	if !slices.Contains(subnets, *subnet) {
-		return fmt.Errorf("validator is not into any subnet %d", *subnet)
+		return fmt.Errorf("%w: validator is not into any subnet %d", ErrReject, *subnet)
 	}
 	// [IGNORE] There has been no other valid sync committee message for the declared slot for the validator referenced by sync_committee_message.validator_index.
 	if _, ok := s.seenSyncCommitteeMessages[seenSyncCommitteeMessageIdentifier]; ok {
@@ -139,10 +138,10 @@ func verifySyncCommitteeMessageSignature(s *state.CachingBeaconState, msg *cltyp
 	}
 	valid, err := bls.Verify(msg.Signature[:], signingRoot[:], publicKey[:])
 	if err != nil {
-		return errors.New("invalid signature")
+		return fmt.Errorf("%w: invalid signature", ErrReject)
 	}
 	if !valid {
-		return errors.New("invalid signature")
+		return fmt.Errorf("%w: invalid signature", ErrReject)
 	}
 	return nil
 }
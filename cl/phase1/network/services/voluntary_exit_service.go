@@ -28,7 +28,6 @@ import (
 	"github.com/erigontech/erigon/cl/pool"
 	"github.com/erigontech/erigon/cl/utils"
 	"github.com/erigontech/erigon/cl/utils/eth_clock"
-	"github.com/pkg/errors"
 )
 
 type voluntaryExitService struct {
@@ -79,25 +78,25 @@ func (s *voluntaryExitService) ProcessMessage(ctx context.Context, subnet *uint6
 	// Verify the validator is active
 	// assert is_active_validator(validator, get_current_epoch(state))
 	if !val.Active(curEpoch) {
-		return errors.New("validator is not active")
+		return fmt.Errorf("%w: validator is not active", ErrReject)
 	}
 
 	// Verify exit has not been initiated
 	// assert validator.exit_epoch == FAR_FUTURE_EPOCH
 	if !(val.ExitEpoch() == s.beaconCfg.FarFutureEpoch) {
-		return fmt.Errorf("verify exit has not been initiated. exitEpoch: %d, farFutureEpoch: %d", val.ExitEpoch(), s.beaconCfg.FarFutureEpoch)
+		return fmt.Errorf("%w: verify exit has not been initiated. exitEpoch: %d, farFutureEpoch: %d", ErrReject, val.ExitEpoch(), s.beaconCfg.FarFutureEpoch)
 	}
 
 	// Exits must specify an epoch when they become valid; they are not valid before then
 	// assert get_current_epoch(state) >= voluntary_exit.epoch
 	if !(curEpoch >= voluntaryExit.Epoch) {
-		return errors.New("exits must specify an epoch when they become valid; they are not valid before then")
+		return fmt.Errorf("%w: exits must specify an epoch when they become valid; they are not valid before then", ErrReject)
 	}
 
 	// Verify the validator has been active long enough
 	// assert get_current_epoch(state) >= validator.activation_epoch + SHARD_COMMITTEE_PERIOD
 	if !(curEpoch >= val.ActivationEpoch()+s.beaconCfg.ShardCommitteePeriod) {
-		return errors.New("verify the validator has been active long enough")
+		return fmt.Errorf("%w: verify the validator has been active long enough", ErrReject)
 	}
 
 	// Verify signature
@@ -122,7 +121,7 @@ func (s *voluntaryExitService) ProcessMessage(ctx context.Context, subnet *uint6
 	if valid, err := blsVerify(msg.Signature[:], signingRoot[:], pk[:]); err != nil {
 		return err
 	} else if !valid {
-		return errors.New("ProcessVoluntaryExit: BLS verification failed")
+		return fmt.Errorf("%w: ProcessVoluntaryExit: BLS verification failed", ErrReject)
 	}
 
 	s.operationsPool.VoluntaryExitsPool.Insert(voluntaryExit.ValidatorIndex, msg)
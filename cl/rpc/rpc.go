@@ -272,6 +272,167 @@ func (b *BeaconRpcP2P) SendBeaconBlocksByRootReq(ctx context.Context, roots [][3
 	return b.sendBlocksRequest(ctx, communication.BeaconBlocksByRootProtocolV2, data, uint64(len(roots)))
 }
 
+// sendChunkedRequest sends a request and calls decode for each response chunk along with its fork version.
+func (b *BeaconRpcP2P) sendChunkedRequest(ctx context.Context, topic string, reqData []byte, count uint64, decode func(raw []byte, version clparams.StateVersion) error) (string, error) {
+	ctx, cn := context.WithTimeout(ctx, time.Second*2)
+	defer cn()
+	message, err := b.sentinel.SendRequest(ctx, &sentinel.RequestData{
+		Data:  reqData,
+		Topic: topic,
+	})
+	if err != nil {
+		return "", err
+	}
+	if message.Error {
+		rd := snappy.NewReader(bytes.NewBuffer(message.Data))
+		errBytes, _ := io.ReadAll(rd)
+		log.Trace("received req error", "topic", topic, "err", string(errBytes), "raw", string(message.Data))
+		return message.Peer.Pid, fmt.Errorf("request to %s failed", topic)
+	}
+
+	r := bytes.NewReader(message.Data)
+	for i := 0; i < int(count); i++ {
+		forkDigest := make([]byte, 4)
+		if _, err := r.Read(forkDigest); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return message.Peer.Pid, err
+		}
+
+		// Read varint for length of message.
+		encodedLn, _, err := ssz_snappy.ReadUvarint(r)
+		if err != nil {
+			return message.Peer.Pid, fmt.Errorf("unable to read varint from message prefix: %w", err)
+		}
+		// Sanity check for message size.
+		if encodedLn > uint64(maxMessageLength) {
+			return message.Peer.Pid, errors.New("received message too big")
+		}
+
+		// Read bytes using snappy into a new raw buffer of side encodedLn.
+		raw := make([]byte, encodedLn)
+		sr := snappy.NewReader(r)
+		bytesRead := 0
+		for bytesRead < int(encodedLn) {
+			n, err := sr.Read(raw[bytesRead:])
+			if err != nil {
+				return message.Peer.Pid, fmt.Errorf("read error: %w", err)
+			}
+			bytesRead += n
+		}
+		// Fork digests
+		respForkDigest := binary.BigEndian.Uint32(forkDigest)
+		if respForkDigest == 0 {
+			return message.Peer.Pid, errors.New("null fork digest")
+		}
+
+		version, err := b.ethClock.StateVersionByForkDigest(utils.Uint32ToBytes4(respForkDigest))
+		if err != nil {
+			return message.Peer.Pid, err
+		}
+		if err := decode(raw, version); err != nil {
+			return message.Peer.Pid, err
+		}
+		// TODO(issues/5884): figure out why there is this extra byte.
+		r.ReadByte()
+	}
+
+	return message.Peer.Pid, nil
+}
+
+// SendLightClientBootstrapReq retrieves the light client bootstrap for the given block root.
+func (b *BeaconRpcP2P) SendLightClientBootstrapReq(ctx context.Context, root libcommon.Hash) (*cltypes.LightClientBootstrap, string, error) {
+	var buffer buffer.Buffer
+	if err := ssz_snappy.EncodeAndWrite(&buffer, &cltypes.Root{Root: root}); err != nil {
+		return nil, "", err
+	}
+
+	var bootstrap *cltypes.LightClientBootstrap
+	data := libcommon.CopyBytes(buffer.Bytes())
+	pid, err := b.sendChunkedRequest(ctx, communication.LightClientBootstrapProtocolV1, data, 1, func(raw []byte, version clparams.StateVersion) error {
+		bootstrap = cltypes.NewLightClientBootstrap(version)
+		return bootstrap.DecodeSSZ(raw, int(version))
+	})
+	if err != nil {
+		return nil, pid, err
+	}
+	if bootstrap == nil {
+		return nil, pid, errors.New("empty light client bootstrap response")
+	}
+	return bootstrap, pid, nil
+}
+
+// SendLightClientUpdatesByRangeReq retrieves the best light client updates for sync committee periods [startPeriod, startPeriod+count).
+func (b *BeaconRpcP2P) SendLightClientUpdatesByRangeReq(ctx context.Context, startPeriod, count uint64) ([]*cltypes.LightClientUpdate, string, error) {
+	var buffer buffer.Buffer
+	if err := ssz_snappy.EncodeAndWrite(&buffer, &cltypes.LightClientUpdatesByRangeRequest{
+		StartPeriod: startPeriod,
+		Count:       count,
+	}); err != nil {
+		return nil, "", err
+	}
+
+	updates := []*cltypes.LightClientUpdate{}
+	data := libcommon.CopyBytes(buffer.Bytes())
+	pid, err := b.sendChunkedRequest(ctx, communication.LightClientUpdatesByRangeProtocolV1, data, count, func(raw []byte, version clparams.StateVersion) error {
+		update := cltypes.NewLightClientUpdate(version)
+		if err := update.DecodeSSZ(raw, int(version)); err != nil {
+			return err
+		}
+		updates = append(updates, update)
+		return nil
+	})
+	if err != nil {
+		return nil, pid, err
+	}
+	return updates, pid, nil
+}
+
+// SendDataColumnSidecarsByRangeReq retrieves the given columns of the blocks in [start, start+count).
+func (b *BeaconRpcP2P) SendDataColumnSidecarsByRangeReq(ctx context.Context, start, count uint64, columns []uint64) ([]*cltypes.DataColumnSidecar, string, error) {
+	req := cltypes.NewDataColumnsByRangeRequest()
+	req.StartSlot = start
+	req.Count = count
+	for _, column := range columns {
+		req.Columns.Append(column)
+	}
+	var buffer buffer.Buffer
+	if err := ssz_snappy.EncodeAndWrite(&buffer, req); err != nil {
+		return nil, "", err
+	}
+
+	data := libcommon.CopyBytes(buffer.Bytes())
+	return b.sendDataColumnSidecars(ctx, communication.DataColumnSidecarsByRangeProtocolV1, data, count*uint64(len(columns)))
+}
+
+// SendDataColumnSidecarsByRootReq retrieves data column sidecars by their identifiers.
+func (b *BeaconRpcP2P) SendDataColumnSidecarsByRootReq(ctx context.Context, req *solid.ListSSZ[*cltypes.DataColumnIdentifier]) ([]*cltypes.DataColumnSidecar, string, error) {
+	var buffer buffer.Buffer
+	if err := ssz_snappy.EncodeAndWrite(&buffer, req); err != nil {
+		return nil, "", err
+	}
+
+	data := libcommon.CopyBytes(buffer.Bytes())
+	return b.sendDataColumnSidecars(ctx, communication.DataColumnSidecarsByRootProtocolV1, data, uint64(req.Len()))
+}
+
+func (b *BeaconRpcP2P) sendDataColumnSidecars(ctx context.Context, topic string, reqData []byte, count uint64) ([]*cltypes.DataColumnSidecar, string, error) {
+	sidecars := []*cltypes.DataColumnSidecar{}
+	pid, err := b.sendChunkedRequest(ctx, topic, reqData, count, func(raw []byte, version clparams.StateVersion) error {
+		sidecar := cltypes.NewDataColumnSidecar()
+		if err := sidecar.DecodeSSZ(raw, int(version)); err != nil {
+			return err
+		}
+		sidecars = append(sidecars, sidecar)
+		return nil
+	})
+	if err != nil {
+		return nil, pid, err
+	}
+	return sidecars, pid, nil
+}
+
 // Peers retrieves peer count.
 func (b *BeaconRpcP2P) Peers() (uint64, error) {
 	amount, err := b.sentinel.GetPeers(b.ctx, &sentinel.EmptyMessage{})
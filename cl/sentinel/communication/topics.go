@@ -34,6 +34,8 @@ const BeaconBlocksByRangeTopic = "/beacon_blocks_by_range"
 const BeaconBlocksByRootTopic = "/beacon_blocks_by_root"
 const BlobSidecarByRootTopic = "/blob_sidecars_by_root"
 const BlobSidecarByRangeTopic = "/blob_sidecars_by_range"
+const DataColumnSidecarsByRootTopic = "/data_column_sidecars_by_root"
+const DataColumnSidecarsByRangeTopic = "/data_column_sidecars_by_range"
 const LightClientOptimisticUpdateTopic = "/light_client_optimistic_update"
 const LightClientFinalityUpdateTopic = "/light_client_finality_update"
 const LightClientBootstrapTopic = "/light_client_bootstrap"
@@ -57,7 +59,11 @@ var (
 
 	BlobSidecarByRootProtocolV1 = ProtocolPrefix + BlobSidecarByRootTopic + Schema1 + EncodingProtocol
 
-	BlobSidecarByRangeProtocolV1          = ProtocolPrefix + BlobSidecarByRangeTopic + Schema1 + EncodingProtocol
+	BlobSidecarByRangeProtocolV1 = ProtocolPrefix + BlobSidecarByRangeTopic + Schema1 + EncodingProtocol
+
+	DataColumnSidecarsByRootProtocolV1  = ProtocolPrefix + DataColumnSidecarsByRootTopic + Schema1 + EncodingProtocol
+	DataColumnSidecarsByRangeProtocolV1 = ProtocolPrefix + DataColumnSidecarsByRangeTopic + Schema1 + EncodingProtocol
+
 	LightClientOptimisticUpdateProtocolV1 = ProtocolPrefix + LightClientOptimisticUpdateTopic + Schema1 + EncodingProtocol
 	LightClientFinalityUpdateProtocolV1   = ProtocolPrefix + LightClientFinalityUpdateTopic + Schema1 + EncodingProtocol
 	LightClientBootstrapProtocolV1        = ProtocolPrefix + LightClientBootstrapTopic + Schema1 + EncodingProtocol
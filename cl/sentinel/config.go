@@ -49,6 +49,7 @@ type SentinelConfig struct {
 	LocalDiscovery bool
 
 	EnableBlocks       bool
+	LightClient        bool // Subscribe to light client gossip only, serve no chain data
 	SubscribeAllTopics bool // Capture all topics
 	ActiveIndicies     uint64
 	MaxPeerCount       uint64
@@ -173,6 +173,9 @@ func (s *Sentinel) setupENR(
 	node.Set(enr.WithEntry(s.cfg.NetworkConfig.Eth2key, forkId))
 	node.Set(enr.WithEntry(s.cfg.NetworkConfig.AttSubnetKey, bitfield.NewBitvector64().Bytes()))
 	node.Set(enr.WithEntry(s.cfg.NetworkConfig.SyncCommsSubnetKey, bitfield.Bitvector4{byte(0x00)}.Bytes()))
+	if s.cfg.BeaconConfig.IsPeerDASScheduled() {
+		node.Set(enr.WithEntry(s.cfg.NetworkConfig.CustodyGroupCountKey, s.cfg.BeaconConfig.CustodyRequirement))
+	}
 	return node, nil
 }
 
@@ -61,6 +61,9 @@ const (
 	// blsToExecutionChangeWeight specifies the scoring weight that we apply to
 	// our bls to execution topic.
 	blsToExecutionChangeWeight = 0.05
+	// blobSidecarsTotalWeight specifies the scoring weight that we apply to
+	// our blob sidecar and data column sidecar subnet topics.
+	blobSidecarsTotalWeight = 0.8
 
 	// maxInMeshScore describes the max score a peer can attain from being in the mesh.
 	maxInMeshScore = 10
@@ -242,7 +245,9 @@ func (s *Sentinel) SubscribeGossip(topic GossipTopic, expiration time.Time, opts
 	}
 	topicScoreParams := s.topicScoreParams(topic.Name)
 	if topicScoreParams != nil {
-		sub.topic.SetScoreParams(topicScoreParams)
+		if err := sub.topic.SetScoreParams(topicScoreParams); err != nil {
+			log.Warn("[Gossip] Failed to set topic score parameters", "topic", path, "err", err)
+		}
 	}
 	s.subManager.AddSubscription(path, sub)
 
@@ -261,10 +266,24 @@ func (s *Sentinel) Unsubscribe(topic GossipTopic, opts ...pubsub.TopicOpt) (err
 
 func (s *Sentinel) topicScoreParams(topic string) *pubsub.TopicScoreParams {
 	switch {
-	case strings.Contains(topic, gossip.TopicNameBeaconBlock) || gossip.IsTopicBlobSidecar(topic):
+	case strings.Contains(topic, gossip.TopicNameBeaconBlock):
 		return s.defaultBlockTopicParams()
+	case gossip.IsTopicBlobSidecar(topic):
+		return s.defaultBlobSubnetTopicParams(s.cfg.BeaconConfig.MaxBlobsPerBlock)
+	case gossip.IsTopicDataColumnSidecar(topic):
+		return s.defaultBlobSubnetTopicParams(s.cfg.BeaconConfig.DataColumnSidecarSubnetCount)
+	case strings.Contains(topic, gossip.TopicNameBeaconAggregateAndProof):
+		return s.defaultAggregateTopicParams()
+	case strings.Contains(topic, gossip.TopicNameSyncCommitteeContributionAndProof):
+		return s.defaultSyncContributionTopicParams()
 	case strings.Contains(topic, gossip.TopicNameVoluntaryExit):
 		return s.defaultVoluntaryExitTopicParams()
+	case strings.Contains(topic, gossip.TopicNameBlsToExecutionChange):
+		return s.defaultBlsToExecutionChangeTopicParams()
+	case strings.Contains(topic, gossip.TopicNameProposerSlashing):
+		return s.defaultSlashingTopicParams(proposerSlashingWeight)
+	case strings.Contains(topic, gossip.TopicNameAttesterSlashing):
+		return s.defaultSlashingTopicParams(attesterSlashingWeight)
 	case gossip.IsTopicBeaconAttestation(topic):
 		return s.defaultAggregateSubnetTopicParams()
 	case gossip.IsTopicSyncCommittee(topic):
@@ -323,6 +342,93 @@ func (s *Sentinel) defaultVoluntaryExitTopicParams() *pubsub.TopicScoreParams {
 	}
 }
 
+// defaultBlobSubnetTopicParams splits the block parameters across the subnets, as every subnet is expected to carry
+// about one sidecar per block.
+func (s *Sentinel) defaultBlobSubnetTopicParams(subnetCount uint64) *pubsub.TopicScoreParams {
+	if subnetCount == 0 {
+		return nil
+	}
+	params := s.defaultBlockTopicParams()
+	params.TopicWeight = blobSidecarsTotalWeight / float64(subnetCount)
+	params.InvalidMessageDeliveriesWeight = -maxScore() / params.TopicWeight
+	return params
+}
+
+func (s *Sentinel) defaultAggregateTopicParams() *pubsub.TopicScoreParams {
+	aggregatorsPerSlot := s.committeeCountPerSlot() * s.cfg.BeaconConfig.TargetAggregatorsPerCommittee
+	return s.aggregationTopicParams(aggregateWeight, aggregatorsPerSlot)
+}
+
+func (s *Sentinel) defaultSyncContributionTopicParams() *pubsub.TopicScoreParams {
+	aggregatorsPerSlot := s.cfg.BeaconConfig.SyncCommitteeSubnetCount * s.cfg.BeaconConfig.TargetAggregatorsPerSyncSubcommittee
+	return s.aggregationTopicParams(syncContributionWeight, aggregatorsPerSlot)
+}
+
+// aggregationTopicParams are the parameters of a topic receiving about messagesPerSlot messages every slot.
+func (s *Sentinel) aggregationTopicParams(topicWeight float64, messagesPerSlot uint64) *pubsub.TopicScoreParams {
+	decayDuration := 1 * s.oneEpochDuration()
+	rate := messagesPerSlot * 2 / gossipSubD
+	if rate == 0 {
+		log.Trace("rate is 0, skipping initializing topic scoring")
+		return nil
+	}
+	// Determine expected first deliveries based on the message rate.
+	firstMessageCap, err := decayLimit(s.scoreDecay(decayDuration), float64(rate))
+	if err != nil {
+		log.Trace("skipping initializing topic scoring", "err", err)
+		return nil
+	}
+	firstMessageWeight := float64(maxFirstDeliveryScore) / firstMessageCap
+	// Determine expected mesh deliveries based on message rate applied with a dampening factor.
+	meshThreshold, err := decayThreshold(s.scoreDecay(decayDuration), float64(messagesPerSlot)/float64(dampeningFactor))
+	if err != nil {
+		log.Trace("skipping initializing topic scoring", "err", err)
+		return nil
+	}
+
+	return &pubsub.TopicScoreParams{
+		TopicWeight:                     topicWeight,
+		TimeInMeshWeight:                maxInMeshScore / s.inMeshCap(),
+		TimeInMeshQuantum:               s.oneSlotDuration(),
+		TimeInMeshCap:                   s.inMeshCap(),
+		FirstMessageDeliveriesWeight:    firstMessageWeight,
+		FirstMessageDeliveriesDecay:     s.scoreDecay(decayDuration),
+		FirstMessageDeliveriesCap:       firstMessageCap,
+		MeshMessageDeliveriesWeight:     0,
+		MeshMessageDeliveriesDecay:      s.scoreDecay(decayDuration),
+		MeshMessageDeliveriesCap:        4 * meshThreshold,
+		MeshMessageDeliveriesThreshold:  meshThreshold,
+		MeshMessageDeliveriesWindow:     2 * time.Second,
+		MeshMessageDeliveriesActivation: 1 * s.oneEpochDuration(),
+		MeshFailurePenaltyWeight:        0,
+		MeshFailurePenaltyDecay:         s.scoreDecay(decayDuration),
+		InvalidMessageDeliveriesWeight:  -maxScore() / topicWeight,
+		InvalidMessageDeliveriesDecay:   s.scoreDecay(50 * s.oneEpochDuration()),
+	}
+}
+
+func (s *Sentinel) defaultBlsToExecutionChangeTopicParams() *pubsub.TopicScoreParams {
+	params := s.defaultVoluntaryExitTopicParams()
+	params.TopicWeight = blsToExecutionChangeWeight
+	return params
+}
+
+// defaultSlashingTopicParams are the parameters of the slashing topics, slashings being rare any first delivery is
+// rewarded.
+func (s *Sentinel) defaultSlashingTopicParams(topicWeight float64) *pubsub.TopicScoreParams {
+	return &pubsub.TopicScoreParams{
+		TopicWeight:                    topicWeight,
+		TimeInMeshWeight:               maxInMeshScore / s.inMeshCap(),
+		TimeInMeshQuantum:              s.oneSlotDuration(),
+		TimeInMeshCap:                  s.inMeshCap(),
+		FirstMessageDeliveriesWeight:   36,
+		FirstMessageDeliveriesDecay:    s.scoreDecay(100 * s.oneEpochDuration()),
+		FirstMessageDeliveriesCap:      1,
+		InvalidMessageDeliveriesWeight: -2000,
+		InvalidMessageDeliveriesDecay:  s.scoreDecay(50 * s.oneEpochDuration()),
+	}
+}
+
 func (s *Sentinel) defaultSyncSubnetTopicParams(activeValidators uint64) *pubsub.TopicScoreParams {
 	subnetCount := s.cfg.BeaconConfig.SyncCommitteeSubnetCount
 	// Get weight for each specific subnet.
@@ -409,7 +515,7 @@ func (s *Sentinel) committeeCountPerSlot() uint64 {
 func maxScore() float64 {
 	totalWeight := beaconBlockWeight + aggregateWeight + syncContributionWeight +
 		attestationTotalWeight + syncCommitteesTotalWeight + attesterSlashingWeight +
-		proposerSlashingWeight + voluntaryExitWeight + blsToExecutionChangeWeight
+		proposerSlashingWeight + voluntaryExitWeight + blsToExecutionChangeWeight + blobSidecarsTotalWeight
 	return (maxInMeshScore + maxFirstDeliveryScore) * totalWeight
 }
 
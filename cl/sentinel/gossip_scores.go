@@ -0,0 +1,138 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentinel
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/erigontech/erigon/cl/sentinel/peers"
+)
+
+const (
+	// validGossipMessagesCap is the number of (decayed) valid messages a peer is rewarded for.
+	validGossipMessagesCap = 100
+	// validGossipMessageWeight is the reward of a valid message, a peer cannot earn more than maxInMeshScore this way.
+	validGossipMessageWeight = float64(maxInMeshScore) / validGossipMessagesCap
+)
+
+// gossipValidations are the decayed counts of the gossip messages of a peer accepted and rejected by the gossip manager.
+type gossipValidations struct {
+	valid   float64
+	invalid float64
+	updated time.Time
+}
+
+// gossipValidationScores turns the validation results of the gossip manager into the application specific score of
+// the peers. The counters decay like the gossipsub ones, so that a peer recovers from a few invalid messages.
+type gossipValidationScores struct {
+	decay         float64 // applied every decayInterval
+	decayInterval time.Duration
+
+	peers map[peer.ID]*gossipValidations
+	mu    sync.Mutex
+}
+
+func newGossipValidationScores(decay float64, decayInterval time.Duration) *gossipValidationScores {
+	return &gossipValidationScores{
+		decay:         decay,
+		decayInterval: decayInterval,
+		peers:         make(map[peer.ID]*gossipValidations),
+	}
+}
+
+// decayed returns the counters of the peer decayed up to now. assume has lock
+func (g *gossipValidationScores) decayed(pid peer.ID, now time.Time) *gossipValidations {
+	v, ok := g.peers[pid]
+	if !ok {
+		v = &gossipValidations{updated: now}
+		g.peers[pid] = v
+		return v
+	}
+	if intervals := now.Sub(v.updated) / g.decayInterval; intervals > 0 {
+		factor := math.Pow(g.decay, float64(intervals))
+		v.valid *= factor
+		v.invalid *= factor
+		v.updated = v.updated.Add(intervals * g.decayInterval)
+	}
+	return v
+}
+
+func (g *gossipValidationScores) report(pid peer.ID, valid bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	v := g.decayed(pid, time.Now())
+	if valid {
+		v.valid++
+	} else {
+		v.invalid++
+	}
+}
+
+// score is the application specific score of the peer: rewards for valid messages are capped while penalties for
+// invalid messages grow quadratically, like the gossipsub invalid message deliveries penalty.
+func (g *gossipValidationScores) score(pid peer.ID) float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.peers[pid]; !ok {
+		return 0
+	}
+	v := g.decayed(pid, time.Now())
+	if v.valid < decayToZero && v.invalid < decayToZero {
+		delete(g.peers, pid)
+		return 0
+	}
+	return math.Min(v.valid, validGossipMessagesCap)*validGossipMessageWeight - v.invalid*v.invalid*maxScore()/4
+}
+
+// ReportGossipValidation feeds the result of the validation of a gossip message of the peer into its score.
+func (s *Sentinel) ReportGossipValidation(pid peer.ID, valid bool) {
+	s.gossipValidations.report(pid, valid)
+}
+
+// inspectPeerScores stores the gossipsub scores of the connected peers in the peer pool.
+func (s *Sentinel) inspectPeerScores(snapshots map[peer.ID]*pubsub.PeerScoreSnapshot) {
+	scores := make(map[peer.ID]*peers.GossipScore, len(snapshots))
+	for pid, snapshot := range snapshots {
+		score := &peers.GossipScore{
+			Score:              snapshot.Score,
+			AppSpecificScore:   snapshot.AppSpecificScore,
+			IPColocationFactor: snapshot.IPColocationFactor,
+			BehaviourPenalty:   snapshot.BehaviourPenalty,
+			Topics:             make(map[string]*peers.TopicGossipScore, len(snapshot.Topics)),
+		}
+		for topic, topicSnapshot := range snapshot.Topics {
+			// reference: /eth2/d31f6191/beacon_attestation_45/ssz_snappy
+			if parts := strings.Split(topic, "/"); len(parts) >= 4 {
+				topic = parts[3]
+			}
+			score.Topics[topic] = &peers.TopicGossipScore{
+				TimeInMesh:               topicSnapshot.TimeInMesh.Seconds(),
+				FirstMessageDeliveries:   topicSnapshot.FirstMessageDeliveries,
+				MeshMessageDeliveries:    topicSnapshot.MeshMessageDeliveries,
+				InvalidMessageDeliveries: topicSnapshot.InvalidMessageDeliveries,
+			}
+		}
+		scores[pid] = score
+	}
+	s.peers.SetGossipScores(scores)
+}
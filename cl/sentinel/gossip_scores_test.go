@@ -0,0 +1,96 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sentinel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/gossip"
+)
+
+func TestTopicScoreParams(t *testing.T) {
+	networkConfig, beaconConfig := clparams.GetConfigsByNetwork(clparams.MainnetNetwork)
+	s := &Sentinel{cfg: &SentinelConfig{
+		NetworkConfig:  networkConfig,
+		BeaconConfig:   beaconConfig,
+		ActiveIndicies: 1_000_000,
+	}}
+	s.gossipValidations = newGossipValidationScores(s.scoreDecay(10*s.oneEpochDuration()), s.oneSlotDuration())
+
+	topics := []string{
+		gossip.TopicNameBeaconBlock,
+		gossip.TopicNameBeaconAggregateAndProof,
+		gossip.TopicNameSyncCommitteeContributionAndProof,
+		gossip.TopicNameVoluntaryExit,
+		gossip.TopicNameBlsToExecutionChange,
+		gossip.TopicNameProposerSlashing,
+		gossip.TopicNameAttesterSlashing,
+		gossip.TopicNameBeaconAttestation(3),
+		gossip.TopicNameSyncCommittee(1),
+		gossip.TopicNameBlobSidecar(2),
+	}
+	params := s.pubsubPeerScoreParams()
+	for _, topic := range topics {
+		topicParams := s.topicScoreParams(topic)
+		require.NotNil(t, topicParams, topic)
+		params.Topics[topic] = topicParams
+	}
+	// an invalid message outweighs whatever a peer can earn.
+	require.InDelta(t, -maxScore(), params.Topics[gossip.TopicNameBeaconAggregateAndProof].InvalidMessageDeliveriesWeight*aggregateWeight, 1e-9)
+	require.Less(t, params.Topics[gossip.TopicNameBlobSidecar(2)].TopicWeight, params.Topics[gossip.TopicNameBeaconBlock].TopicWeight)
+
+	// the router validates the parameters of every topic.
+	mn := mocknet.New()
+	defer mn.Close()
+	h, err := mn.GenPeer()
+	require.NoError(t, err)
+	_, err = pubsub.NewGossipSub(context.Background(), h, pubsub.WithPeerScore(params, s.pubsubPeerScoreThresholds()))
+	require.NoError(t, err)
+}
+
+func TestGossipValidationScores(t *testing.T) {
+	scores := newGossipValidationScores(0.5, time.Hour)
+	pid := peer.ID("peer")
+	require.Zero(t, scores.score(pid))
+
+	for i := 0; i < 2*validGossipMessagesCap; i++ {
+		scores.report(pid, true)
+	}
+	// rewards are capped
+	require.InDelta(t, float64(maxInMeshScore), scores.score(pid), 1e-9)
+
+	scores.report(pid, false)
+	scores.report(pid, false)
+	require.InDelta(t, maxInMeshScore-maxScore(), scores.score(pid), 1e-9)
+
+	// counters decay every interval
+	scores.peers[pid].updated = scores.peers[pid].updated.Add(-2 * time.Hour)
+	require.InDelta(t, 50*validGossipMessageWeight-0.25*maxScore()/4, scores.score(pid), 1e-9)
+
+	// decayed peers are forgotten
+	scores.peers[pid].updated = scores.peers[pid].updated.Add(-20 * time.Hour)
+	require.Zero(t, scores.score(pid))
+	require.Empty(t, scores.peers)
+}
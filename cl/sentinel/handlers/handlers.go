@@ -55,6 +55,7 @@ type RateLimits struct {
 	beaconBlocksByRootLimit  int
 	lightClientLimit         int
 	blobSidecarsLimit        int
+	dataColumnSidecarsLimit  int
 }
 
 const (
@@ -63,6 +64,8 @@ const (
 	blockHandlerRateLimit = 200
 	lightClientRateLimit  = 500
 	blobHandlerRateLimit  = 50 // very generous here.
+	// a column is one cell of every blob, a peer asks for a few columns per block.
+	dataColumnHandlerRateLimit = 8 * blobHandlerRateLimit
 )
 
 var rateLimits = RateLimits{
@@ -75,6 +78,7 @@ var rateLimits = RateLimits{
 	beaconBlocksByRootLimit:  blockHandlerRateLimit,
 	lightClientLimit:         lightClientRateLimit,
 	blobSidecarsLimit:        blobHandlerRateLimit,
+	dataColumnSidecarsLimit:  dataColumnHandlerRateLimit,
 }
 
 type ConsensusHandlers struct {
@@ -123,15 +127,19 @@ func NewConsensusHandlers(ctx context.Context, db freezeblocks.BeaconSnapshotRea
 	}
 
 	hm := map[string]func(s network.Stream) error{
-		communication.PingProtocolV1:                        c.pingHandler,
-		communication.GoodbyeProtocolV1:                     c.goodbyeHandler,
-		communication.StatusProtocolV1:                      c.statusHandler,
-		communication.MetadataProtocolV1:                    c.metadataV1Handler,
-		communication.MetadataProtocolV2:                    c.metadataV2Handler,
-		communication.LightClientOptimisticUpdateProtocolV1: c.optimisticLightClientUpdateHandler,
-		communication.LightClientFinalityUpdateProtocolV1:   c.finalityLightClientUpdateHandler,
-		communication.LightClientBootstrapProtocolV1:        c.lightClientBootstrapHandler,
-		communication.LightClientUpdatesByRangeProtocolV1:   c.lightClientUpdatesByRangeHandler,
+		communication.PingProtocolV1:     c.pingHandler,
+		communication.GoodbyeProtocolV1:  c.goodbyeHandler,
+		communication.StatusProtocolV1:   c.statusHandler,
+		communication.MetadataProtocolV1: c.metadataV1Handler,
+		communication.MetadataProtocolV2: c.metadataV2Handler,
+	}
+
+	// light client data is served from fork choice, which a light client node does not have.
+	if c.forkChoiceReader != nil {
+		hm[communication.LightClientOptimisticUpdateProtocolV1] = c.optimisticLightClientUpdateHandler
+		hm[communication.LightClientFinalityUpdateProtocolV1] = c.finalityLightClientUpdateHandler
+		hm[communication.LightClientBootstrapProtocolV1] = c.lightClientBootstrapHandler
+		hm[communication.LightClientUpdatesByRangeProtocolV1] = c.lightClientUpdatesByRangeHandler
 	}
 
 	if c.enableBlocks {
@@ -139,6 +147,10 @@ func NewConsensusHandlers(ctx context.Context, db freezeblocks.BeaconSnapshotRea
 		hm[communication.BeaconBlocksByRootProtocolV2] = c.beaconBlocksByRootHandler
 		hm[communication.BlobSidecarByRangeProtocolV1] = c.blobsSidecarsByRangeHandler
 		hm[communication.BlobSidecarByRootProtocolV1] = c.blobsSidecarsByIdsHandler
+		if c.beaconConfig.IsPeerDASScheduled() {
+			hm[communication.DataColumnSidecarsByRangeProtocolV1] = c.dataColumnSidecarsByRangeHandler
+			hm[communication.DataColumnSidecarsByRootProtocolV1] = c.dataColumnSidecarsByRootHandler
+		}
 	}
 
 	c.handlers = map[protocol.ID]network.StreamHandler{}
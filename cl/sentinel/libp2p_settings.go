@@ -31,19 +31,23 @@ func (s *Sentinel) scoreDecay(totalDurationDecay time.Duration) float64 {
 	return math.Pow(decayToZero, 1/float64(numOfTimes))
 }
 
-func (s *Sentinel) pubsubOptions() []pubsub.Option {
-	thresholds := &pubsub.PeerScoreThresholds{
+func (s *Sentinel) pubsubPeerScoreThresholds() *pubsub.PeerScoreThresholds {
+	return &pubsub.PeerScoreThresholds{
 		GossipThreshold:             -4000,
 		PublishThreshold:            -8000,
 		GraylistThreshold:           -16000,
 		AcceptPXThreshold:           100,
 		OpportunisticGraftThreshold: 5,
 	}
-	scoreParams := &pubsub.PeerScoreParams{
+}
+
+// pubsubPeerScoreParams are the peer score parameters, the parameters of the topics are set on subscription.
+func (s *Sentinel) pubsubPeerScoreParams() *pubsub.PeerScoreParams {
+	return &pubsub.PeerScoreParams{
 		Topics:        make(map[string]*pubsub.TopicScoreParams),
 		TopicScoreCap: 32.72,
 		AppSpecificScore: func(p peer.ID) float64 {
-			return 0
+			return s.gossipValidations.score(p)
 		},
 		AppSpecificWeight:           1,
 		IPColocationFactorWeight:    -35.11,
@@ -56,6 +60,9 @@ func (s *Sentinel) pubsubOptions() []pubsub.Option {
 		DecayToZero:                 decayToZero,
 		RetainScore:                 100 * s.oneEpochDuration(), // Retain for 100 epochs
 	}
+}
+
+func (s *Sentinel) pubsubOptions() []pubsub.Option {
 	pubsubQueueSize := 600
 	psOpts := []pubsub.Option{
 		pubsub.WithMessageSignaturePolicy(pubsub.StrictNoSign),
@@ -64,7 +71,8 @@ func (s *Sentinel) pubsubOptions() []pubsub.Option {
 		pubsub.WithPeerOutboundQueueSize(pubsubQueueSize),
 		pubsub.WithMaxMessageSize(int(s.cfg.NetworkConfig.GossipMaxSizeBellatrix)),
 		pubsub.WithValidateQueueSize(pubsubQueueSize),
-		pubsub.WithPeerScore(scoreParams, thresholds),
+		pubsub.WithPeerScore(s.pubsubPeerScoreParams(), s.pubsubPeerScoreThresholds()),
+		pubsub.WithPeerScoreInspect(s.inspectPeerScores, s.oneSlotDuration()),
 		pubsub.WithGossipSubParams(pubsubGossipParam()),
 	}
 	return psOpts
@@ -0,0 +1,54 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package peers
+
+import (
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// GossipScore is the gossipsub score of a peer, along with its components.
+type GossipScore struct {
+	Score              float64 `json:"score"`
+	AppSpecificScore   float64 `json:"app_specific_score"`
+	IPColocationFactor float64 `json:"ip_colocation_factor"`
+	BehaviourPenalty   float64 `json:"behaviour_penalty"`
+	// Topics are the counters of the scored topics the peer is in, by topic name (e.g beacon_attestation_3).
+	Topics map[string]*TopicGossipScore `json:"topics"`
+}
+
+// TopicGossipScore are the counters the gossipsub score of a peer in a topic is computed from.
+type TopicGossipScore struct {
+	// TimeInMesh is in seconds.
+	TimeInMesh               float64 `json:"time_in_mesh"`
+	FirstMessageDeliveries   float64 `json:"first_message_deliveries"`
+	MeshMessageDeliveries    float64 `json:"mesh_message_deliveries"`
+	InvalidMessageDeliveries float64 `json:"invalid_message_deliveries"`
+}
+
+// SetGossipScores replaces the gossipsub scores of the connected peers.
+func (p *Pool) SetGossipScores(scores map[peer.ID]*GossipScore) {
+	p.gossipScores.Store(&scores)
+}
+
+// GossipScore returns the last gossipsub score of the peer, nil if it is unknown.
+func (p *Pool) GossipScore(pid peer.ID) *GossipScore {
+	scores := p.gossipScores.Load()
+	if scores == nil {
+		return nil
+	}
+	return (*scores)[pid]
+}
@@ -59,6 +59,8 @@ type Pool struct {
 
 	bannedPeers sync.Map
 	queue       *ring.Buffer[*Item]
+	// gossipScores is the last snapshot of the gossipsub scores of the connected peers.
+	gossipScores atomic.Pointer[map[peer.ID]*GossipScore]
 
 	mu sync.Mutex
 }
@@ -107,6 +107,9 @@ type Sentinel struct {
 	pidToEnr         sync.Map
 	ethClock         eth_clock.EthereumClock
 
+	// gossipValidations is the application specific part of the gossipsub peer score.
+	gossipValidations *gossipValidationScores
+
 	metadataLock sync.Mutex
 }
 
@@ -248,6 +251,9 @@ func New(
 	subnetCount := cfg.NetworkConfig.AttestationSubnetCount +
 		cfg.BeaconConfig.SyncCommitteeSubnetCount +
 		cfg.BeaconConfig.MaxBlobsPerBlock
+	if cfg.BeaconConfig.IsPeerDASScheduled() {
+		subnetCount += cfg.BeaconConfig.DataColumnSidecarSubnetCount
+	}
 
 	defaultLimits := rcmgr.DefaultLimits.AutoScale()
 	newLimit := rcmgr.PartialLimitConfig{
@@ -286,6 +292,7 @@ func New(
 
 	s.handshaker = handshake.New(ctx, s.ethClock, cfg.BeaconConfig, s.httpApi)
 
+	s.gossipValidations = newGossipValidationScores(s.scoreDecay(10*s.oneEpochDuration()), s.oneSlotDuration())
 	pubsub.TimeCacheDuration = 550 * gossipSubHeartbeatInterval
 	s.pubsub, err = pubsub.NewGossipSub(s.ctx, s.host, s.pubsubOptions()...)
 	if err != nil {
@@ -357,6 +364,11 @@ func (s *Sentinel) String() string {
 	return s.listener.Self().String()
 }
 
+// NodeID returns the discovery node id of the sentinel, which its custodied data columns are derived from.
+func (s *Sentinel) NodeID() enode.ID {
+	return s.listener.LocalNode().ID()
+}
+
 func (s *Sentinel) HasTooManyPeers() bool {
 	active, _, _ := s.GetPeersCount()
 	return active >= int(s.cfg.MaxPeerCount)
@@ -97,6 +97,26 @@ func (s *SentinelServer) BanPeer(_ context.Context, p *sentinelrpc.Peer) (*senti
 	return &sentinelrpc.EmptyMessage{}, nil
 }
 
+// PenalizePeer is called when a gossip message of the peer failed validation, it lowers its gossipsub score.
+func (s *SentinelServer) PenalizePeer(_ context.Context, p *sentinelrpc.Peer) (*sentinelrpc.EmptyMessage, error) {
+	var pid peer.ID
+	if err := pid.UnmarshalText([]byte(p.Pid)); err != nil {
+		return nil, err
+	}
+	s.sentinel.ReportGossipValidation(pid, false)
+	return &sentinelrpc.EmptyMessage{}, nil
+}
+
+// RewardPeer is called when a gossip message of the peer passed validation, it raises its gossipsub score.
+func (s *SentinelServer) RewardPeer(_ context.Context, p *sentinelrpc.Peer) (*sentinelrpc.EmptyMessage, error) {
+	var pid peer.ID
+	if err := pid.UnmarshalText([]byte(p.Pid)); err != nil {
+		return nil, err
+	}
+	s.sentinel.ReportGossipValidation(pid, true)
+	return &sentinelrpc.EmptyMessage{}, nil
+}
+
 func (s *SentinelServer) PublishGossip(_ context.Context, msg *sentinelrpc.GossipData) (*sentinelrpc.EmptyMessage, error) {
 	manager := s.sentinel.GossipManager()
 	// Snappify payload before sending it to gossip
@@ -123,6 +143,11 @@ func (s *SentinelServer) PublishGossip(_ context.Context, msg *sentinelrpc.Gossi
 				return nil, errors.New("subnetId is required for blob sidecar")
 			}
 			subscription = manager.GetMatchingSubscription(gossip.TopicNameBlobSidecar(*msg.SubnetId))
+		case gossip.IsTopicDataColumnSidecar(msg.Name):
+			if msg.SubnetId == nil {
+				return nil, errors.New("subnetId is required for data column sidecar")
+			}
+			subscription = manager.GetMatchingSubscription(gossip.TopicNameDataColumnSidecar(*msg.SubnetId))
 		case gossip.IsTopicSyncCommittee(msg.Name):
 			if msg.SubnetId == nil {
 				return nil, errors.New("subnetId is required for sync_committee")
@@ -406,6 +431,7 @@ func (s *SentinelServer) handleGossipPacket(pkt *sentinel.GossipMessage) error {
 	default:
 		// case for:
 		// TopicNamePrefixBlobSidecar
+		// TopicNamePrefixDataColumnSidecar
 		// TopicNamePrefixBeaconAttestation
 		// TopicNamePrefixSyncCommittee
 		subnet := extractSubnetIndexByGossipTopic(gossipTopic)
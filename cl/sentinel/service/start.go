@@ -24,10 +24,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/erigontech/erigon/cl/das"
 	"github.com/erigontech/erigon/cl/gossip"
 	"github.com/erigontech/erigon/cl/persistence/blob_storage"
 	"github.com/erigontech/erigon/cl/phase1/forkchoice"
 	"github.com/erigontech/erigon/cl/sentinel"
+	"github.com/erigontech/erigon/cl/sentinel/peers"
 	"github.com/erigontech/erigon/cl/utils/eth_clock"
 	"github.com/erigontech/erigon/common/math"
 	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
@@ -104,6 +106,15 @@ func createSentinel(
 	if err := sent.Start(); err != nil {
 		return nil, err
 	}
+	if cfg.LightClient {
+		// light client nodes follow the chain through light client updates only.
+		for _, v := range []sentinel.GossipTopic{sentinel.LightClientFinalityUpdateSsz, sentinel.LightClientOptimisticUpdateSsz} {
+			if _, err := sent.SubscribeGossip(v, time.Unix(0, math.MaxInt64)); err != nil {
+				logger.Error("[Sentinel] failed to start sentinel", "err", err)
+			}
+		}
+		return sent, nil
+	}
 	gossipTopics := []sentinel.GossipTopic{
 		sentinel.BeaconBlockSsz,
 		//sentinel.VoluntaryExitSsz,
@@ -138,6 +149,20 @@ func createSentinel(
 			int(cfg.BeaconConfig.SyncCommitteeSubnetCount),
 		)...)
 
+	if cfg.BeaconConfig.IsPeerDASScheduled() {
+		// only the subnets of the custodied data columns are followed.
+		custodyColumns, err := das.GetCustodyColumns(sent.NodeID(), cfg.BeaconConfig.CustodyRequirement, cfg.BeaconConfig)
+		if err != nil {
+			return nil, err
+		}
+		for _, subnet := range das.GetCustodySubnets(custodyColumns, cfg.BeaconConfig) {
+			gossipTopics = append(gossipTopics, sentinel.GossipTopic{
+				Name:     gossip.TopicNameDataColumnSidecar(subnet),
+				CodecStr: sentinel.SSZSnappyCodec,
+			})
+		}
+	}
+
 	for _, v := range gossipTopics {
 		if err := sent.Unsubscribe(v); err != nil {
 			logger.Error("[Sentinel] failed to start sentinel", "err", err)
@@ -172,7 +197,7 @@ func StartSentinelService(
 	srvCfg *ServerConfig,
 	ethClock eth_clock.EthereumClock,
 	forkChoiceReader forkchoice.ForkChoiceStorageReader,
-	logger log.Logger) (sentinelrpc.SentinelClient, error) {
+	logger log.Logger) (sentinelrpc.SentinelClient, *peers.Pool, error) {
 	ctx := context.Background()
 	sent, err := createSentinel(
 		cfg,
@@ -184,7 +209,7 @@ func StartSentinelService(
 		logger,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	// rcmgrObs.MustRegisterWith(prometheus.DefaultRegisterer)
 	logger.Info("[Sentinel] Sentinel started", "enr", sent.String())
@@ -194,7 +219,7 @@ func StartSentinelService(
 	server := NewSentinelServer(ctx, sent, logger)
 	go StartServe(server, srvCfg, srvCfg.Creds)
 
-	return direct.NewSentinelClientDirect(server), nil
+	return direct.NewSentinelClientDirect(server), sent.Peers(), nil
 }
 
 func StartServe(
@@ -62,7 +62,8 @@ func init() {
 		With("validity", spectest.UnimplementedHandler).
 		With("initialization", spectest.UnimplementedHandler)
 	TestFormats.Add("kzg").
-		With("", spectest.UnimplementedHandler)
+		With("", spectest.UnimplementedHandler).
+		With("verify_cell_kzg_proof_batch", VerifyCellKZGProofBatch)
 	TestFormats.Add("light_client").
 		WithFn("single_merkle_proof", LightClientBeaconBlockBodyExecutionMerkleProof)
 	TestFormats.Add("merkle_proof").
@@ -0,0 +1,95 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package consensus_tests
+
+import (
+	"fmt"
+	"io/fs"
+	"testing"
+
+	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/crypto/kzg"
+	"github.com/erigontech/erigon/spectest"
+)
+
+type verifyCellKZGProofBatchData struct {
+	Input struct {
+		Commitments []string `yaml:"commitments"`
+		CellIndices []uint64 `yaml:"cell_indices"`
+		Cells       []string `yaml:"cells"`
+		Proofs      []string `yaml:"proofs"`
+	} `yaml:"input"`
+	// nil if the input is invalid
+	Output *bool `yaml:"output"`
+}
+
+func decodeFixedHex(dst []byte, s string) error {
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return err
+	}
+	if len(b) != len(dst) {
+		return fmt.Errorf("expected %d bytes, got %d", len(dst), len(b))
+	}
+	copy(dst, b)
+	return nil
+}
+
+// decodeCellKZGProofBatch decodes the vector input, it fails on inputs which are malformed before reaching the verifier.
+func decodeCellKZGProofBatch(data *verifyCellKZGProofBatchData) (commitments []gokzg4844.KZGCommitment, cells []kzg.Cell, proofs []gokzg4844.KZGProof, err error) {
+	commitments = make([]gokzg4844.KZGCommitment, len(data.Input.Commitments))
+	for i, c := range data.Input.Commitments {
+		if err = decodeFixedHex(commitments[i][:], c); err != nil {
+			return
+		}
+	}
+	cells = make([]kzg.Cell, len(data.Input.Cells))
+	for i, c := range data.Input.Cells {
+		if err = decodeFixedHex(cells[i][:], c); err != nil {
+			return
+		}
+	}
+	proofs = make([]gokzg4844.KZGProof, len(data.Input.Proofs))
+	for i, p := range data.Input.Proofs {
+		if err = decodeFixedHex(proofs[i][:], p); err != nil {
+			return
+		}
+	}
+	return
+}
+
+var VerifyCellKZGProofBatch = spectest.HandlerFunc(func(t *testing.T, root fs.FS, c spectest.TestCase) (err error) {
+	data := verifyCellKZGProofBatchData{}
+	require.NoError(t, spectest.ReadYml(root, "data.yaml", &data))
+
+	commitments, cells, proofs, err := decodeCellKZGProofBatch(&data)
+	if err == nil {
+		err = kzg.VerifyCellKZGProofBatch(commitments, data.Input.CellIndices, cells, proofs)
+	}
+	switch {
+	case data.Output == nil:
+		require.Error(t, err)
+	case *data.Output:
+		require.NoError(t, err)
+	default:
+		require.ErrorIs(t, err, kzg.ErrInvalidCellProof)
+	}
+	return nil
+})
@@ -184,7 +184,12 @@ func (c *CommitteeSubscribeMgmt) NeedToAggregate(att *solid.Attestation) bool {
 			log.Warn("failed to hash attestation data", "err", err)
 			return false
 		}
-		aggregation := c.aggregationPool.GetAggregatationByRoot(root)
+		var aggregation *solid.Attestation
+		if clVersion.AfterOrEqual(clparams.ElectraVersion) {
+			aggregation = c.aggregationPool.GetAggregatationByRootAndCommittee(root, committeeIndex)
+		} else {
+			aggregation = c.aggregationPool.GetAggregatationByRoot(root)
+		}
 		if aggregation == nil ||
 			!utils.IsNonStrictSupersetBitlist(aggregation.AggregationBits.Bytes(), att.AggregationBits.Bytes()) {
 			// the on bit is not set. need to aggregate
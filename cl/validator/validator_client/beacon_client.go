@@ -0,0 +1,121 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package validator_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// beaconClient - Beacon API client which serves requests by the in-process handler (cl/beacon/handler):
+// no network, no serialization of the node's internals beyond the API itself.
+type beaconClient struct {
+	handler http.Handler
+}
+
+// responseBuffer - http.ResponseWriter which keeps the response of in-process handler
+type responseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseBuffer() *responseBuffer {
+	return &responseBuffer{header: http.Header{}}
+}
+
+func (w *responseBuffer) Header() http.Header {
+	return w.header
+}
+
+func (w *responseBuffer) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *responseBuffer) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
+
+// Flush - handlers may flush, there is nothing to flush to
+func (w *responseBuffer) Flush() {}
+
+// apiResponse - standard Beacon API envelope
+type apiResponse[T any] struct {
+	Data                    T      `json:"data"`
+	Version                 string `json:"version,omitempty"`
+	ExecutionPayloadBlinded bool   `json:"execution_payload_blinded,omitempty"`
+	DependentRoot           string `json:"dependent_root,omitempty"`
+}
+
+// do - `body` is JSON encoded if not nil, response is decoded into `out` if not nil
+func (b *beaconClient) do(ctx context.Context, method, path string, query url.Values, header http.Header, body any, out any) (http.Header, error) {
+	var reqBody io.Reader
+	if body != nil {
+		enc, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(enc)
+	}
+	target := path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp := newResponseBuffer()
+	b.handler.ServeHTTP(resp, req)
+	resp.WriteHeader(http.StatusOK) // handler wrote nothing
+	respBody := resp.body.Bytes()
+	if resp.status >= http.StatusMultipleChoices {
+		return resp.header, fmt.Errorf("%s %s: %d %s", method, path, resp.status, strings.TrimSpace(string(respBody)))
+	}
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return resp.header, fmt.Errorf("%s %s: %w", method, path, err)
+		}
+	}
+	return resp.header, nil
+}
+
+func (b *beaconClient) get(ctx context.Context, path string, query url.Values, out any) (http.Header, error) {
+	return b.do(ctx, http.MethodGet, path, query, nil, nil, out)
+}
+
+func (b *beaconClient) post(ctx context.Context, path string, body any, out any) error {
+	_, err := b.do(ctx, http.MethodPost, path, nil, nil, body, out)
+	return err
+}
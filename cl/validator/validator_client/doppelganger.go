@@ -0,0 +1,62 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package validator_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrDoppelganger = errors.New("doppelganger detected: validator is already live elsewhere")
+
+type livenessResponse struct {
+	Index  uint64 `json:"index,string"`
+	IsLive bool   `json:"is_live"`
+}
+
+// doppelgangerCheck - before signing anything, watches `epochs` full epochs: if any of our validators attested
+// or proposed there, someone else runs the same keys and we must not start. Watching starts at the next epoch:
+// liveness of the current (and previous) epoch includes our own duties performed before restart.
+func (v *ValidatorClient) doppelgangerCheck(ctx context.Context, epochs uint64) error {
+	if epochs == 0 {
+		return nil
+	}
+	indices := v.activeIndices()
+	if len(indices) == 0 {
+		return nil
+	}
+	startEpoch := v.ethClock.GetCurrentEpoch() + 1
+	v.logger.Info("[Validator] Doppelganger check started", "validators", len(indices), "epochs", epochs)
+	// liveness of epoch N is final only once epoch N+1 is over (attestations of N can be included during N+1)
+	for epoch := startEpoch; epoch < startEpoch+epochs; epoch++ {
+		if err := sleepUntil(ctx, v.ethClock.GetSlotTime((epoch+2)*v.beaconCfg.SlotsPerEpoch)); err != nil {
+			return err
+		}
+		var resp apiResponse[[]livenessResponse]
+		if err := v.beacon.post(ctx, fmt.Sprintf("/eth/v1/validator/liveness/%d", epoch), indices, &resp); err != nil {
+			return fmt.Errorf("doppelganger check: %w", err)
+		}
+		for _, l := range resp.Data {
+			if l.IsLive {
+				return fmt.Errorf("%w: index %d at epoch %d", ErrDoppelganger, l.Index, epoch)
+			}
+		}
+		v.logger.Info("[Validator] Doppelganger check: no activity", "epoch", epoch)
+	}
+	return nil
+}
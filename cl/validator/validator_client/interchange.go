@@ -0,0 +1,186 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package validator_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/length"
+	"github.com/erigontech/erigon-lib/kv"
+)
+
+const interchangeFormatVersion = "5"
+
+// Interchange - EIP-3076 slashing protection interchange format (complete form)
+type Interchange struct {
+	Metadata struct {
+		InterchangeFormatVersion string         `json:"interchange_format_version"`
+		GenesisValidatorsRoot    libcommon.Hash `json:"genesis_validators_root"`
+	} `json:"metadata"`
+	Data []InterchangeValidator `json:"data"`
+}
+
+type InterchangeValidator struct {
+	Pubkey             libcommon.Bytes48        `json:"pubkey"`
+	SignedBlocks       []InterchangeBlock       `json:"signed_blocks"`
+	SignedAttestations []InterchangeAttestation `json:"signed_attestations"`
+}
+
+type InterchangeBlock struct {
+	Slot        uint64          `json:"slot,string"`
+	SigningRoot *libcommon.Hash `json:"signing_root,omitempty"`
+}
+
+type InterchangeAttestation struct {
+	SourceEpoch uint64          `json:"source_epoch,string"`
+	TargetEpoch uint64          `json:"target_epoch,string"`
+	SigningRoot *libcommon.Hash `json:"signing_root,omitempty"`
+}
+
+func rootOrZero(root *libcommon.Hash) libcommon.Hash {
+	if root == nil {
+		return libcommon.Hash{}
+	}
+	return *root
+}
+
+func rootOrNil(root []byte) *libcommon.Hash {
+	h := libcommon.BytesToHash(root)
+	if h == (libcommon.Hash{}) {
+		return nil
+	}
+	return &h
+}
+
+// ImportInterchange - merges interchange file into local history. Records which conflict with local ones are
+// kept with unknown signing root, so nothing at their slot/target can be signed again.
+func (s *SlashingProtection) ImportInterchange(ctx context.Context, r io.Reader) (validators int, err error) {
+	var interchange Interchange
+	if err := json.NewDecoder(r).Decode(&interchange); err != nil {
+		return 0, err
+	}
+	if interchange.Metadata.InterchangeFormatVersion != interchangeFormatVersion {
+		return 0, fmt.Errorf("unsupported interchange format version: %q", interchange.Metadata.InterchangeFormatVersion)
+	}
+	if interchange.Metadata.GenesisValidatorsRoot != s.genesisValidatorsRoot {
+		return 0, fmt.Errorf("interchange genesis validators root %x doesn't match %x", interchange.Metadata.GenesisValidatorsRoot, s.genesisValidatorsRoot)
+	}
+	return len(interchange.Data), s.db.Update(ctx, func(tx kv.RwTx) error {
+		for _, v := range interchange.Data {
+			for _, b := range v.SignedBlocks {
+				key, root := slashingProtectionKey(v.Pubkey, b.Slot), rootOrZero(b.SigningRoot)
+				existing, err := tx.GetOne(slashingProtectionBlocks, key)
+				if err != nil {
+					return err
+				}
+				if existing != nil && !bytes.Equal(existing, root[:]) {
+					root = libcommon.Hash{}
+				}
+				if err := tx.Put(slashingProtectionBlocks, key, root[:]); err != nil {
+					return err
+				}
+			}
+			for _, a := range v.SignedAttestations {
+				root := rootOrZero(a.SigningRoot)
+				existing, err := tx.GetOne(slashingProtectionAttestations, slashingProtectionKey(v.Pubkey, a.TargetEpoch))
+				if err != nil {
+					return err
+				}
+				if existing != nil && (binary.BigEndian.Uint64(existing[:8]) != a.SourceEpoch || !bytes.Equal(existing[8:], root[:])) {
+					root = libcommon.Hash{}
+				}
+				if err := putAttestation(tx, v.Pubkey, a.SourceEpoch, a.TargetEpoch, root); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// ExportInterchange - writes local history of given validators (or of all validators if none given) in EIP-3076 format
+func (s *SlashingProtection) ExportInterchange(ctx context.Context, w io.Writer, pubkeys ...libcommon.Bytes48) error {
+	interchange, err := s.interchange(ctx, pubkeys)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(interchange)
+}
+
+func (s *SlashingProtection) interchange(ctx context.Context, pubkeys []libcommon.Bytes48) (*Interchange, error) {
+	interchange := &Interchange{}
+	interchange.Metadata.InterchangeFormatVersion = interchangeFormatVersion
+	interchange.Metadata.GenesisValidatorsRoot = s.genesisValidatorsRoot
+	interchange.Data = []InterchangeValidator{}
+
+	validators := map[libcommon.Bytes48]*InterchangeValidator{}
+	validator := func(k []byte) *InterchangeValidator {
+		pubkey := libcommon.Bytes48(k[:length.Bytes48])
+		v, ok := validators[pubkey]
+		if !ok {
+			if len(pubkeys) > 0 && !slices.Contains(pubkeys, pubkey) {
+				return nil
+			}
+			v = &InterchangeValidator{Pubkey: pubkey, SignedBlocks: []InterchangeBlock{}, SignedAttestations: []InterchangeAttestation{}}
+			validators[pubkey] = v
+		}
+		return v
+	}
+	if err := s.db.View(ctx, func(tx kv.Tx) error {
+		if err := tx.ForEach(slashingProtectionBlocks, nil, func(k, v []byte) error {
+			val := validator(k)
+			if val == nil {
+				return nil
+			}
+			val.SignedBlocks = append(val.SignedBlocks, InterchangeBlock{
+				Slot:        binary.BigEndian.Uint64(k[length.Bytes48:]),
+				SigningRoot: rootOrNil(v),
+			})
+			return nil
+		}); err != nil {
+			return err
+		}
+		return tx.ForEach(slashingProtectionAttestations, nil, func(k, v []byte) error {
+			val := validator(k)
+			if val == nil {
+				return nil
+			}
+			val.SignedAttestations = append(val.SignedAttestations, InterchangeAttestation{
+				SourceEpoch: binary.BigEndian.Uint64(v[:8]),
+				TargetEpoch: binary.BigEndian.Uint64(k[length.Bytes48:]),
+				SigningRoot: rootOrNil(v[8:]),
+			})
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+	for _, v := range validators {
+		interchange.Data = append(interchange.Data, *v)
+	}
+	slices.SortFunc(interchange.Data, func(a, b InterchangeValidator) int { return bytes.Compare(a.Pubkey[:], b.Pubkey[:]) })
+	return interchange, nil
+}
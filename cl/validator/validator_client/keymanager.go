@@ -0,0 +1,530 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package validator_client
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Giulio2002/bls"
+	"github.com/go-chi/chi/v5"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/cl/beacon/beaconhttp"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes"
+)
+
+// keystores imported by keymanager API: <DataDir>/keystores/<pubkey>.json, password in <DataDir>/secrets/<pubkey>
+const (
+	managedKeystoresDir = "keystores"
+	managedSecretsDir   = "secrets"
+)
+
+// statuses of keymanager API import/delete operations
+const (
+	keymanagerStatusImported  = "imported"
+	keymanagerStatusDuplicate = "duplicate"
+	keymanagerStatusDeleted   = "deleted"
+	keymanagerStatusNotActive = "not_active"
+	keymanagerStatusNotFound  = "not_found"
+	keymanagerStatusError     = "error"
+)
+
+type keymanagerStatus struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+func keymanagerError(err error) keymanagerStatus {
+	return keymanagerStatus{Status: keymanagerStatusError, Message: err.Error()}
+}
+
+// LoadOrCreateKeymanagerToken - reads bearer token of keymanager API, a random one is generated if the file doesn't exist
+func LoadOrCreateKeymanagerToken(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		token := strings.TrimSpace(string(data))
+		if token == "" {
+			return "", fmt.Errorf("keymanager token file %s is empty", path)
+		}
+		return token, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := "api-token-0x" + hex.EncodeToString(b)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(token), 0600); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func managedKeystoreName(pubkey libcommon.Bytes48) string {
+	return "0x" + hex.EncodeToString(pubkey[:])
+}
+
+// loadManagedKeystores - keystores imported by keymanager API before restart
+func (v *ValidatorClient) loadManagedKeystores() error {
+	files, err := filepath.Glob(filepath.Join(v.cfg.DataDir, managedKeystoresDir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		var ks Keystore
+		if err := json.Unmarshal(data, &ks); err != nil {
+			return fmt.Errorf("keystore %s: %w", file, err)
+		}
+		password, err := os.ReadFile(filepath.Join(v.cfg.DataDir, managedSecretsDir, strings.TrimSuffix(filepath.Base(file), ".json")))
+		if err != nil {
+			return fmt.Errorf("keystore %s: %w", file, err)
+		}
+		key, err := ks.Decrypt(string(password))
+		if err != nil {
+			return fmt.Errorf("keystore %s: %w", file, err)
+		}
+		if !v.addValidator(localValidator(key, ks.Path, false)) {
+			v.logger.Warn("[Validator] Keystore is already loaded from keystore dir", "file", file)
+		}
+	}
+	return nil
+}
+
+// KeymanagerHandler - keymanager API (https://ethereum.github.io/keymanager-APIs) of the validator client,
+// authentication is up to the caller
+func (v *ValidatorClient) KeymanagerHandler() http.Handler {
+	r := chi.NewRouter()
+	r.Route("/eth/v1", func(r chi.Router) {
+		r.Get("/keystores", beaconhttp.HandleEndpointFunc(v.getKeystores))
+		r.Post("/keystores", beaconhttp.HandleEndpointFunc(v.postKeystores))
+		r.Delete("/keystores", beaconhttp.HandleEndpointFunc(v.deleteKeystores))
+		r.Get("/remotekeys", beaconhttp.HandleEndpointFunc(v.getRemoteKeys))
+		r.Post("/remotekeys", beaconhttp.HandleEndpointFunc(v.postRemoteKeys))
+		r.Delete("/remotekeys", beaconhttp.HandleEndpointFunc(v.deleteRemoteKeys))
+		r.Route("/validator/{pubkey}", func(r chi.Router) {
+			r.Post("/voluntary_exit", beaconhttp.HandleEndpointFunc(v.postVoluntaryExit))
+			r.Get("/feerecipient", beaconhttp.HandleEndpointFunc(v.getFeeRecipient))
+			r.Post("/feerecipient", keymanagerSettingsEndpoint(v, http.StatusAccepted, setFeeRecipient))
+			r.Delete("/feerecipient", keymanagerSettingsEndpoint(v, http.StatusNoContent, func(s *ValidatorSettings, _ []byte) error {
+				s.FeeRecipient = nil
+				return nil
+			}))
+			r.Get("/gas_limit", beaconhttp.HandleEndpointFunc(v.getGasLimit))
+			r.Post("/gas_limit", keymanagerSettingsEndpoint(v, http.StatusAccepted, setGasLimit))
+			r.Delete("/gas_limit", keymanagerSettingsEndpoint(v, http.StatusNoContent, func(s *ValidatorSettings, _ []byte) error {
+				s.GasLimit = 0
+				return nil
+			}))
+			r.Get("/graffiti", beaconhttp.HandleEndpointFunc(v.getGraffiti))
+			r.Post("/graffiti", keymanagerSettingsEndpoint(v, http.StatusAccepted, setGraffiti))
+			r.Delete("/graffiti", keymanagerSettingsEndpoint(v, http.StatusNoContent, func(s *ValidatorSettings, _ []byte) error {
+				s.Graffiti = nil
+				return nil
+			}))
+		})
+	})
+	return r
+}
+
+type keystoreResponse struct {
+	ValidatingPubkey libcommon.Bytes48 `json:"validating_pubkey"`
+	DerivationPath   string            `json:"derivation_path"`
+	Readonly         bool              `json:"readonly"`
+}
+
+func (v *ValidatorClient) getKeystores(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
+	v.keysMu.RLock()
+	defer v.keysMu.RUnlock()
+	keystores := make([]keystoreResponse, 0, len(v.validators))
+	for _, val := range v.validators {
+		if val.signerURL != "" {
+			continue
+		}
+		keystores = append(keystores, keystoreResponse{ValidatingPubkey: val.pubkey, DerivationPath: val.derivationPath, Readonly: val.readonly})
+	}
+	return beaconhttp.NewBeaconResponse(keystores), nil
+}
+
+type importKeystoresRequest struct {
+	Keystores          []string `json:"keystores"`
+	Passwords          []string `json:"passwords"`
+	SlashingProtection string   `json:"slashing_protection,omitempty"`
+}
+
+func (v *ValidatorClient) postKeystores(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
+	var req importKeystoresRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, err)
+	}
+	if len(req.Keystores) != len(req.Passwords) {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, errors.New("keystores and passwords must have the same length"))
+	}
+	// history comes first: imported keys may sign as soon as they are added
+	if req.SlashingProtection != "" {
+		if _, err := v.protection.ImportInterchange(r.Context(), strings.NewReader(req.SlashingProtection)); err != nil {
+			return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, fmt.Errorf("slashing protection: %w", err))
+		}
+	}
+	statuses := make([]keymanagerStatus, len(req.Keystores))
+	for i := range req.Keystores {
+		statuses[i] = v.importKeystore(req.Keystores[i], req.Passwords[i])
+	}
+	return beaconhttp.NewBeaconResponse(statuses), nil
+}
+
+func (v *ValidatorClient) importKeystore(keystore, password string) keymanagerStatus {
+	var ks Keystore
+	if err := json.Unmarshal([]byte(keystore), &ks); err != nil {
+		return keymanagerError(err)
+	}
+	// decryption is slow (scrypt), it must not block signing
+	key, err := ks.Decrypt(password)
+	if err != nil {
+		return keymanagerError(err)
+	}
+	pubkey := libcommon.Bytes48(bls.CompressPublicKey(key.PublicKey()))
+
+	v.keysMu.Lock()
+	defer v.keysMu.Unlock()
+	if _, ok := v.byPubkey[pubkey]; ok {
+		return keymanagerStatus{Status: keymanagerStatusDuplicate}
+	}
+	name := managedKeystoreName(pubkey)
+	if err := os.WriteFile(filepath.Join(v.cfg.DataDir, managedSecretsDir, name), []byte(password), 0600); err != nil {
+		return keymanagerError(err)
+	}
+	if err := os.WriteFile(filepath.Join(v.cfg.DataDir, managedKeystoresDir, name+".json"), []byte(keystore), 0600); err != nil {
+		return keymanagerError(err)
+	}
+	v.addValidator(localValidator(key, ks.Path, false))
+	v.logger.Info("[Validator] Imported keystore", "pubkey", pubkey)
+	return keymanagerStatus{Status: keymanagerStatusImported}
+}
+
+type deleteKeysRequest struct {
+	Pubkeys []libcommon.Bytes48 `json:"pubkeys"`
+}
+
+func (v *ValidatorClient) deleteKeystores(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
+	var req deleteKeysRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, err)
+	}
+	statuses := make([]keymanagerStatus, len(req.Pubkeys))
+	v.keysMu.Lock()
+	for i, pubkey := range req.Pubkeys {
+		statuses[i] = v.deleteKeystore(pubkey)
+	}
+	v.keysMu.Unlock()
+
+	// signing holds keysMu, so the history is complete: deleted keys don't sign anymore
+	interchange, err := v.protection.interchange(r.Context(), req.Pubkeys)
+	if err != nil {
+		return nil, err
+	}
+	for i, pubkey := range req.Pubkeys {
+		if statuses[i].Status != keymanagerStatusNotFound {
+			continue
+		}
+		for _, val := range interchange.Data {
+			if val.Pubkey == pubkey {
+				statuses[i].Status = keymanagerStatusNotActive
+			}
+		}
+	}
+	slashingProtection, err := json.Marshal(interchange)
+	if err != nil {
+		return nil, err
+	}
+	return beaconhttp.NewBeaconResponse(statuses).With("slashing_protection", string(slashingProtection)), nil
+}
+
+// deleteKeystore - caller must hold keysMu
+func (v *ValidatorClient) deleteKeystore(pubkey libcommon.Bytes48) keymanagerStatus {
+	val, ok := v.byPubkey[pubkey]
+	if !ok || val.signerURL != "" {
+		return keymanagerStatus{Status: keymanagerStatusNotFound}
+	}
+	if val.readonly {
+		return keymanagerError(errors.New("keystore is read-only: it is loaded from keystore dir"))
+	}
+	name := managedKeystoreName(pubkey)
+	if err := os.Remove(filepath.Join(v.cfg.DataDir, managedKeystoresDir, name+".json")); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return keymanagerError(err)
+	}
+	if err := os.Remove(filepath.Join(v.cfg.DataDir, managedSecretsDir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return keymanagerError(err)
+	}
+	v.removeValidator(pubkey)
+	v.logger.Info("[Validator] Deleted keystore", "pubkey", pubkey)
+	return keymanagerStatus{Status: keymanagerStatusDeleted}
+}
+
+type remoteKey struct {
+	Pubkey   libcommon.Bytes48 `json:"pubkey"`
+	URL      string            `json:"url"`
+	Readonly bool              `json:"readonly"`
+}
+
+func (v *ValidatorClient) getRemoteKeys(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
+	v.keysMu.RLock()
+	defer v.keysMu.RUnlock()
+	keys := make([]remoteKey, 0, len(v.validators))
+	for _, val := range v.validators {
+		if val.signerURL != "" {
+			keys = append(keys, remoteKey{Pubkey: val.pubkey, URL: val.signerURL, Readonly: val.readonly})
+		}
+	}
+	return beaconhttp.NewBeaconResponse(keys), nil
+}
+
+func (v *ValidatorClient) postRemoteKeys(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
+	var req struct {
+		RemoteKeys []remoteKey `json:"remote_keys"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, err)
+	}
+	statuses := make([]keymanagerStatus, len(req.RemoteKeys))
+	v.keysMu.Lock()
+	defer v.keysMu.Unlock()
+	for i, key := range req.RemoteKeys {
+		if _, ok := v.byPubkey[key.Pubkey]; ok {
+			statuses[i] = keymanagerStatus{Status: keymanagerStatusDuplicate}
+			continue
+		}
+		if key.URL == "" {
+			statuses[i] = keymanagerError(errors.New("empty url"))
+			continue
+		}
+		if err := v.settings.setRemoteKey(key.Pubkey, key.URL); err != nil {
+			statuses[i] = keymanagerError(err)
+			continue
+		}
+		v.addValidator(remoteValidator(key.Pubkey, key.URL, false))
+		v.logger.Info("[Validator] Imported remote key", "pubkey", key.Pubkey, "url", key.URL)
+		statuses[i] = keymanagerStatus{Status: keymanagerStatusImported}
+	}
+	return beaconhttp.NewBeaconResponse(statuses), nil
+}
+
+func (v *ValidatorClient) deleteRemoteKeys(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
+	var req deleteKeysRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, err)
+	}
+	statuses := make([]keymanagerStatus, len(req.Pubkeys))
+	v.keysMu.Lock()
+	defer v.keysMu.Unlock()
+	for i, pubkey := range req.Pubkeys {
+		val, ok := v.byPubkey[pubkey]
+		switch {
+		case !ok || val.signerURL == "":
+			statuses[i] = keymanagerStatus{Status: keymanagerStatusNotFound}
+		case val.readonly:
+			statuses[i] = keymanagerError(errors.New("remote key is read-only: it is found at remote signer url"))
+		default:
+			if err := v.settings.setRemoteKey(pubkey, ""); err != nil {
+				statuses[i] = keymanagerError(err)
+				continue
+			}
+			v.removeValidator(pubkey)
+			v.logger.Info("[Validator] Deleted remote key", "pubkey", pubkey)
+			statuses[i] = keymanagerStatus{Status: keymanagerStatusDeleted}
+		}
+	}
+	return beaconhttp.NewBeaconResponse(statuses), nil
+}
+
+// postVoluntaryExit - signs voluntary exit, publishing it is up to the caller
+func (v *ValidatorClient) postVoluntaryExit(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
+	pubkey, err := v.pubkeyFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	val, ok := v.getValidator(pubkey)
+	if !ok {
+		return nil, beaconhttp.NewEndpointError(http.StatusNotFound, errors.New("validator not found"))
+	}
+	v.keysMu.RLock()
+	index, active := val.index, val.active
+	v.keysMu.RUnlock()
+	if !active {
+		return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, errors.New("validator index is not known yet"))
+	}
+	var epoch uint64
+	if e := r.URL.Query().Get("epoch"); e != "" {
+		if epoch, err = strconv.ParseUint(e, 10, 64); err != nil {
+			return nil, beaconhttp.NewEndpointError(http.StatusBadRequest, err)
+		}
+	} else {
+		epoch = v.ethClock.GetCurrentEpoch()
+	}
+	exit := &cltypes.VoluntaryExit{Epoch: epoch, ValidatorIndex: index}
+	objectRoot, err := exit.HashSSZ()
+	if err != nil {
+		return nil, err
+	}
+	// EIP-7044: exits are signed in capella domain since deneb, so they never expire
+	version := v.beaconCfg.GetCurrentStateVersion(epoch)
+	if version >= clparams.DenebVersion {
+		version = clparams.CapellaVersion
+	}
+	req, err := v.signRequest(objectRoot, v.beaconCfg.DomainVoluntaryExit, version, SignTypeVoluntaryExit, exit)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := val.signer.Sign(r.Context(), req)
+	if err != nil {
+		return nil, err
+	}
+	return beaconhttp.NewBeaconResponse(&cltypes.SignedVoluntaryExit{VoluntaryExit: exit, Signature: signature}), nil
+}
+
+// pubkeyFromRequest - {pubkey} of the path, it must be one of our keys (local or remote)
+func (v *ValidatorClient) pubkeyFromRequest(r *http.Request) (libcommon.Bytes48, error) {
+	var pubkey libcommon.Bytes48
+	if err := pubkey.UnmarshalText([]byte(chi.URLParam(r, "pubkey"))); err != nil {
+		return pubkey, beaconhttp.NewEndpointError(http.StatusBadRequest, err)
+	}
+	if _, ok := v.getValidator(pubkey); !ok {
+		return pubkey, beaconhttp.NewEndpointError(http.StatusNotFound, errors.New("validator not found"))
+	}
+	return pubkey, nil
+}
+
+// keymanagerSettingsEndpoint - POST/DELETE of a per-validator setting, answers with empty body and `status`
+func keymanagerSettingsEndpoint(v *ValidatorClient, status int, set func(s *ValidatorSettings, body []byte) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pubkey, err := v.pubkeyFromRequest(r)
+		if err != nil {
+			var endpointErr *beaconhttp.EndpointError
+			if !errors.As(err, &endpointErr) {
+				endpointErr = beaconhttp.NewEndpointError(http.StatusInternalServerError, err)
+			}
+			endpointErr.WriteTo(w)
+			return
+		}
+		var body bytes.Buffer
+		if _, err := body.ReadFrom(r.Body); err != nil {
+			beaconhttp.NewEndpointError(http.StatusBadRequest, err).WriteTo(w)
+			return
+		}
+		var setErr error
+		if err := v.settings.update(pubkey, func(s *ValidatorSettings) {
+			setErr = set(s, body.Bytes())
+		}); err != nil {
+			beaconhttp.NewEndpointError(http.StatusInternalServerError, err).WriteTo(w)
+			return
+		}
+		if setErr != nil {
+			beaconhttp.NewEndpointError(http.StatusBadRequest, setErr).WriteTo(w)
+			return
+		}
+		w.WriteHeader(status)
+	}
+}
+
+func setFeeRecipient(s *ValidatorSettings, body []byte) error {
+	var req struct {
+		EthAddress libcommon.Address `json:"ethaddress"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return err
+	}
+	if req.EthAddress == (libcommon.Address{}) {
+		return errors.New("fee recipient can't be zero address")
+	}
+	s.FeeRecipient = &req.EthAddress
+	return nil
+}
+
+func setGasLimit(s *ValidatorSettings, body []byte) error {
+	var req struct {
+		GasLimit string `json:"gas_limit"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return err
+	}
+	gasLimit, err := strconv.ParseUint(req.GasLimit, 10, 64)
+	if err != nil {
+		return err
+	}
+	if gasLimit == 0 {
+		return errors.New("gas limit can't be zero")
+	}
+	s.GasLimit = gasLimit
+	return nil
+}
+
+func setGraffiti(s *ValidatorSettings, body []byte) error {
+	var req struct {
+		Graffiti string `json:"graffiti"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return err
+	}
+	if len(req.Graffiti) > 32 {
+		return errors.New("graffiti is longer than 32 bytes")
+	}
+	s.Graffiti = &req.Graffiti
+	return nil
+}
+
+func (v *ValidatorClient) getFeeRecipient(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
+	pubkey, err := v.pubkeyFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return beaconhttp.NewBeaconResponse(map[string]any{"pubkey": pubkey, "ethaddress": v.feeRecipient(pubkey)}), nil
+}
+
+func (v *ValidatorClient) getGasLimit(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
+	pubkey, err := v.pubkeyFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return beaconhttp.NewBeaconResponse(map[string]any{"pubkey": pubkey, "gas_limit": strconv.FormatUint(v.gasLimit(pubkey), 10)}), nil
+}
+
+func (v *ValidatorClient) getGraffiti(w http.ResponseWriter, r *http.Request) (*beaconhttp.BeaconResponse, error) {
+	pubkey, err := v.pubkeyFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return beaconhttp.NewBeaconResponse(map[string]any{"pubkey": pubkey, "graffiti": v.graffiti(pubkey)}), nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package validator_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+const keystoreTestPubkey = "0x9612d7a727c9d0a22e185a1c768478dfe919cada9266988cb32359c11f2b7b27f4ae4040902382ae2910c15e2b420d07"
+
+func newTestKeymanagerClient(t *testing.T, dataDir string) *ValidatorClient {
+	t.Helper()
+	for _, dir := range []string{managedKeystoresDir, managedSecretsDir} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dataDir, dir), 0700))
+	}
+	settings, err := openSettingsStore(filepath.Join(dataDir, "validator_definitions.json"))
+	require.NoError(t, err)
+	v := &ValidatorClient{
+		cfg:        Config{DataDir: dataDir, FeeRecipient: libcommon.Address{1}},
+		settings:   settings,
+		protection: openTestSlashingProtection(t, libcommon.Hash{1}),
+		logger:     log.New(),
+		byPubkey:   map[libcommon.Bytes48]*validator{},
+	}
+	require.NoError(t, v.loadManagedKeystores())
+	return v
+}
+
+func keymanagerRequest(t *testing.T, h http.Handler, method, path string, body any, out any) int {
+	t.Helper()
+	var reqBody bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(body))
+	}
+	req := httptest.NewRequest(method, path, &reqBody)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if out != nil && rec.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), out))
+	}
+	return rec.Code
+}
+
+func TestKeymanagerKeystores(t *testing.T) {
+	dataDir := t.TempDir()
+	v := newTestKeymanagerClient(t, dataDir)
+	h := v.KeymanagerHandler()
+
+	var statuses struct {
+		Data []keymanagerStatus `json:"data"`
+	}
+	importReq := importKeystoresRequest{Keystores: []string{keystorePbkdf2, keystorePbkdf2, "{}"}, Passwords: []string{keystoreTestPassword, keystoreTestPassword, ""}}
+	require.Equal(t, http.StatusOK, keymanagerRequest(t, h, http.MethodPost, "/eth/v1/keystores", importReq, &statuses))
+	require.Len(t, statuses.Data, 3)
+	require.Equal(t, keymanagerStatusImported, statuses.Data[0].Status)
+	require.Equal(t, keymanagerStatusDuplicate, statuses.Data[1].Status)
+	require.Equal(t, keymanagerStatusError, statuses.Data[2].Status)
+
+	var keystores struct {
+		Data []keystoreResponse `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, keymanagerRequest(t, h, http.MethodGet, "/eth/v1/keystores", nil, &keystores))
+	require.Len(t, keystores.Data, 1)
+	pubkey := keystores.Data[0].ValidatingPubkey
+	require.Equal(t, keystoreTestPubkey, pubkey.String())
+	require.Equal(t, "m/12381/60/0/0", keystores.Data[0].DerivationPath)
+	require.False(t, keystores.Data[0].Readonly)
+
+	// imported keystore survives restart
+	require.Len(t, newTestKeymanagerClient(t, dataDir).validators, 1)
+
+	require.NoError(t, v.protection.CheckAndInsertBlock(context.Background(), pubkey, 10, libcommon.Hash{1}))
+	var deleted struct {
+		Data               []keymanagerStatus `json:"data"`
+		SlashingProtection string             `json:"slashing_protection"`
+	}
+	deleteReq := deleteKeysRequest{Pubkeys: []libcommon.Bytes48{pubkey, pubkey, {2}}}
+	require.Equal(t, http.StatusOK, keymanagerRequest(t, h, http.MethodDelete, "/eth/v1/keystores", deleteReq, &deleted))
+	require.Equal(t, []keymanagerStatus{{Status: keymanagerStatusDeleted}, {Status: keymanagerStatusNotActive}, {Status: keymanagerStatusNotFound}}, deleted.Data)
+	var interchange Interchange
+	require.NoError(t, json.Unmarshal([]byte(deleted.SlashingProtection), &interchange))
+	require.Len(t, interchange.Data, 1)
+	require.Equal(t, uint64(10), interchange.Data[0].SignedBlocks[0].Slot)
+	require.Empty(t, newTestKeymanagerClient(t, dataDir).validators)
+}
+
+func TestKeymanagerValidatorSettings(t *testing.T) {
+	dataDir := t.TempDir()
+	v := newTestKeymanagerClient(t, dataDir)
+	h := v.KeymanagerHandler()
+	var statuses struct {
+		Data []keymanagerStatus `json:"data"`
+	}
+	importReq := importKeystoresRequest{Keystores: []string{keystorePbkdf2}, Passwords: []string{keystoreTestPassword}}
+	require.Equal(t, http.StatusOK, keymanagerRequest(t, h, http.MethodPost, "/eth/v1/keystores", importReq, &statuses))
+	base := "/eth/v1/validator/" + keystoreTestPubkey
+
+	var feeRecipient struct {
+		Data struct {
+			EthAddress libcommon.Address `json:"ethaddress"`
+		} `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, keymanagerRequest(t, h, http.MethodGet, base+"/feerecipient", nil, &feeRecipient))
+	require.Equal(t, libcommon.Address{1}, feeRecipient.Data.EthAddress, "default of config")
+	require.Equal(t, http.StatusAccepted, keymanagerRequest(t, h, http.MethodPost, base+"/feerecipient", map[string]any{"ethaddress": libcommon.Address{2}}, nil))
+	require.Equal(t, http.StatusOK, keymanagerRequest(t, h, http.MethodGet, base+"/feerecipient", nil, &feeRecipient))
+	require.Equal(t, libcommon.Address{2}, feeRecipient.Data.EthAddress)
+
+	require.Equal(t, http.StatusAccepted, keymanagerRequest(t, h, http.MethodPost, base+"/gas_limit", map[string]any{"gas_limit": "36000000"}, nil))
+	require.Equal(t, http.StatusBadRequest, keymanagerRequest(t, h, http.MethodPost, base+"/gas_limit", map[string]any{"gas_limit": "abc"}, nil))
+	require.Equal(t, http.StatusAccepted, keymanagerRequest(t, h, http.MethodPost, base+"/graffiti", map[string]any{"graffiti": "erigon"}, nil))
+	require.Equal(t, http.StatusNotFound, keymanagerRequest(t, h, http.MethodPost, "/eth/v1/validator/"+libcommon.Bytes48{1}.String()+"/graffiti", map[string]any{"graffiti": "x"}, nil))
+
+	// settings are persisted
+	restarted := newTestKeymanagerClient(t, dataDir)
+	require.Equal(t, libcommon.Address{2}, restarted.feeRecipient(libcommon.Bytes48(libcommon.FromHex(keystoreTestPubkey))))
+	require.Equal(t, uint64(36000000), restarted.gasLimit(libcommon.Bytes48(libcommon.FromHex(keystoreTestPubkey))))
+	require.Equal(t, "erigon", restarted.graffiti(libcommon.Bytes48(libcommon.FromHex(keystoreTestPubkey))))
+
+	require.Equal(t, http.StatusNoContent, keymanagerRequest(t, h, http.MethodDelete, base+"/gas_limit", nil, nil))
+	require.Equal(t, uint64(DefaultGasLimit), v.gasLimit(libcommon.Bytes48(libcommon.FromHex(keystoreTestPubkey))))
+}
@@ -0,0 +1,207 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package validator_client
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Giulio2002/bls"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/text/unicode/norm"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+)
+
+// Keystore - EIP-2335 BLS12-381 keystore (version 4)
+type Keystore struct {
+	Crypto      keystoreCrypto `json:"crypto"`
+	Description string         `json:"description"`
+	Pubkey      string         `json:"pubkey"`
+	Path        string         `json:"path"`
+	UUID        string         `json:"uuid"`
+	Version     int            `json:"version"`
+}
+
+type keystoreCrypto struct {
+	Kdf      keystoreModule `json:"kdf"`
+	Checksum keystoreModule `json:"checksum"`
+	Cipher   keystoreModule `json:"cipher"`
+}
+
+type keystoreModule struct {
+	Function string          `json:"function"`
+	Params   json.RawMessage `json:"params"`
+	Message  string          `json:"message"`
+}
+
+type scryptParams struct {
+	Dklen int    `json:"dklen"`
+	N     int    `json:"n"`
+	P     int    `json:"p"`
+	R     int    `json:"r"`
+	Salt  string `json:"salt"`
+}
+
+type pbkdf2Params struct {
+	Dklen int    `json:"dklen"`
+	C     int    `json:"c"`
+	Prf   string `json:"prf"`
+	Salt  string `json:"salt"`
+}
+
+type aesParams struct {
+	Iv string `json:"iv"`
+}
+
+var ErrKeystoreChecksum = errors.New("keystore checksum mismatch: invalid password")
+
+// normalizeKeystorePassword - NFKD and stripped of C0, C1 and Delete control codes, as EIP-2335 requires
+func normalizeKeystorePassword(password string) []byte {
+	return []byte(strings.Map(func(r rune) rune {
+		if r < 0x20 || (r >= 0x7f && r <= 0x9f) {
+			return -1
+		}
+		return r
+	}, norm.NFKD.String(password)))
+}
+
+func (k *Keystore) decryptionKey(password string) ([]byte, error) {
+	switch k.Crypto.Kdf.Function {
+	case "scrypt":
+		var p scryptParams
+		if err := json.Unmarshal(k.Crypto.Kdf.Params, &p); err != nil {
+			return nil, err
+		}
+		salt, err := hex.DecodeString(p.Salt)
+		if err != nil {
+			return nil, err
+		}
+		return scrypt.Key(normalizeKeystorePassword(password), salt, p.N, p.R, p.P, p.Dklen)
+	case "pbkdf2":
+		var p pbkdf2Params
+		if err := json.Unmarshal(k.Crypto.Kdf.Params, &p); err != nil {
+			return nil, err
+		}
+		if p.Prf != "hmac-sha256" {
+			return nil, fmt.Errorf("unsupported pbkdf2 prf: %s", p.Prf)
+		}
+		salt, err := hex.DecodeString(p.Salt)
+		if err != nil {
+			return nil, err
+		}
+		return pbkdf2.Key(normalizeKeystorePassword(password), salt, p.C, p.Dklen, sha256.New), nil
+	default:
+		return nil, fmt.Errorf("unsupported kdf: %s", k.Crypto.Kdf.Function)
+	}
+}
+
+// Decrypt - returns secret key stored in keystore
+func (k *Keystore) Decrypt(password string) (*bls.PrivateKey, error) {
+	if k.Version != 4 {
+		return nil, fmt.Errorf("unsupported keystore version: %d", k.Version)
+	}
+	if k.Crypto.Checksum.Function != "sha256" {
+		return nil, fmt.Errorf("unsupported checksum: %s", k.Crypto.Checksum.Function)
+	}
+	if k.Crypto.Cipher.Function != "aes-128-ctr" {
+		return nil, fmt.Errorf("unsupported cipher: %s", k.Crypto.Cipher.Function)
+	}
+	dk, err := k.decryptionKey(password)
+	if err != nil {
+		return nil, err
+	}
+	if len(dk) < 32 {
+		return nil, fmt.Errorf("decryption key is too short: %d", len(dk))
+	}
+	cipherMessage, err := hex.DecodeString(k.Crypto.Cipher.Message)
+	if err != nil {
+		return nil, err
+	}
+	checksum, err := hex.DecodeString(k.Crypto.Checksum.Message)
+	if err != nil {
+		return nil, err
+	}
+	if expected := sha256.Sum256(append(libcommon.Copy(dk[16:32]), cipherMessage...)); !bytes.Equal(expected[:], checksum) {
+		return nil, ErrKeystoreChecksum
+	}
+
+	var p aesParams
+	if err := json.Unmarshal(k.Crypto.Cipher.Params, &p); err != nil {
+		return nil, err
+	}
+	iv, err := hex.DecodeString(p.Iv)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(dk[:16])
+	if err != nil {
+		return nil, err
+	}
+	secret := make([]byte, len(cipherMessage))
+	cipher.NewCTR(block, iv).XORKeyStream(secret, cipherMessage)
+
+	key, err := bls.NewPrivateKeyFromBytes(secret)
+	if err != nil {
+		return nil, err
+	}
+	if k.Pubkey != "" {
+		pubkey, err := hex.DecodeString(strings.TrimPrefix(k.Pubkey, "0x"))
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(bls.CompressPublicKey(key.PublicKey()), pubkey) {
+			return nil, fmt.Errorf("keystore pubkey %s doesn't match secret key", k.Pubkey)
+		}
+	}
+	return key, nil
+}
+
+// LoadKeystores - decrypts all *.json keystores of `dir` with the same password
+func LoadKeystores(dir string, password string) ([]*bls.PrivateKey, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*bls.PrivateKey, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var ks Keystore
+		if err := json.Unmarshal(data, &ks); err != nil {
+			return nil, fmt.Errorf("keystore %s: %w", file, err)
+		}
+		key, err := ks.Decrypt(password)
+		if err != nil {
+			return nil, fmt.Errorf("keystore %s: %w", file, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package validator_client
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+)
+
+// DefaultGasLimit - gas limit registered with builders if neither keymanager API nor config sets one
+const DefaultGasLimit = 30_000_000
+
+// ValidatorSettings - per-validator overrides of Config set by keymanager API, nil/zero - not set
+type ValidatorSettings struct {
+	FeeRecipient *libcommon.Address `json:"fee_recipient,omitempty"`
+	GasLimit     uint64             `json:"gas_limit,omitempty,string"`
+	Graffiti     *string            `json:"graffiti,omitempty"`
+}
+
+func (s *ValidatorSettings) empty() bool {
+	return s.FeeRecipient == nil && s.GasLimit == 0 && s.Graffiti == nil
+}
+
+type validatorDefinitions struct {
+	Settings   map[libcommon.Bytes48]*ValidatorSettings `json:"settings"`
+	RemoteKeys map[libcommon.Bytes48]string             `json:"remote_keys"` // pubkey -> remote signer url
+}
+
+// settingsStore - validator definitions which survive restarts, kept in a single json file
+type settingsStore struct {
+	path string
+
+	mu   sync.RWMutex
+	defs validatorDefinitions
+}
+
+func openSettingsStore(path string) (*settingsStore, error) {
+	s := &settingsStore{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.defs); err != nil {
+			return nil, err
+		}
+	}
+	if s.defs.Settings == nil {
+		s.defs.Settings = map[libcommon.Bytes48]*ValidatorSettings{}
+	}
+	if s.defs.RemoteKeys == nil {
+		s.defs.RemoteKeys = map[libcommon.Bytes48]string{}
+	}
+	return s, nil
+}
+
+// save - caller must hold mu. Written through temporary file so crash never leaves it truncated.
+func (s *settingsStore) save() error {
+	data, err := json.MarshalIndent(s.defs, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func (s *settingsStore) settings(pubkey libcommon.Bytes48) ValidatorSettings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if settings, ok := s.defs.Settings[pubkey]; ok {
+		return *settings
+	}
+	return ValidatorSettings{}
+}
+
+// update - applies `f` to settings of the validator and persists them
+func (s *settingsStore) update(pubkey libcommon.Bytes48, f func(*ValidatorSettings)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, had := s.defs.Settings[pubkey]
+	var settings ValidatorSettings
+	if had {
+		settings = *prev
+	}
+	f(&settings)
+	if settings.empty() {
+		delete(s.defs.Settings, pubkey)
+	} else {
+		s.defs.Settings[pubkey] = &settings
+	}
+	if err := s.save(); err != nil {
+		if had {
+			s.defs.Settings[pubkey] = prev
+		} else {
+			delete(s.defs.Settings, pubkey)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *settingsStore) remoteKeys() map[libcommon.Bytes48]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make(map[libcommon.Bytes48]string, len(s.defs.RemoteKeys))
+	for k, v := range s.defs.RemoteKeys {
+		keys[k] = v
+	}
+	return keys
+}
+
+// setRemoteKey - url == "" removes the key
+func (s *settingsStore) setRemoteKey(pubkey libcommon.Bytes48, url string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, had := s.defs.RemoteKeys[pubkey]
+	if url == "" {
+		delete(s.defs.RemoteKeys, pubkey)
+	} else {
+		s.defs.RemoteKeys[pubkey] = url
+	}
+	if err := s.save(); err != nil {
+		if had {
+			s.defs.RemoteKeys[pubkey] = prev
+		} else {
+			delete(s.defs.RemoteKeys, pubkey)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package validator_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Giulio2002/bls"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+)
+
+// SignType - type of the signed object, named as in Web3Signer API
+type SignType string
+
+const (
+	SignTypeBlock                             SignType = "BLOCK_V2"
+	SignTypeAttestation                       SignType = "ATTESTATION"
+	SignTypeAggregateAndProof                 SignType = "AGGREGATE_AND_PROOF"
+	SignTypeAggregateAndProofV2               SignType = "AGGREGATE_AND_PROOF_V2"
+	SignTypeAggregationSlot                   SignType = "AGGREGATION_SLOT"
+	SignTypeRandaoReveal                      SignType = "RANDAO_REVEAL"
+	SignTypeSyncCommitteeMessage              SignType = "SYNC_COMMITTEE_MESSAGE"
+	SignTypeSyncCommitteeSelectionProof       SignType = "SYNC_COMMITTEE_SELECTION_PROOF"
+	SignTypeSyncCommitteeContributionAndProof SignType = "SYNC_COMMITTEE_CONTRIBUTION_AND_PROOF"
+	SignTypeVoluntaryExit                     SignType = "VOLUNTARY_EXIT"
+	SignTypeValidatorRegistration             SignType = "VALIDATOR_REGISTRATION"
+)
+
+// payloadKey - field of Web3Signer request which holds the object
+func (t SignType) payloadKey() string {
+	switch t {
+	case SignTypeBlock:
+		return "beacon_block"
+	case SignTypeAggregateAndProof, SignTypeAggregateAndProofV2:
+		return "aggregate_and_proof"
+	case SignTypeSyncCommitteeSelectionProof:
+		return "sync_aggregator_selection_data"
+	case SignTypeSyncCommitteeContributionAndProof:
+		return "contribution_and_proof"
+	default:
+		return strings.ToLower(string(t))
+	}
+}
+
+type ForkInfo struct {
+	Fork struct {
+		PreviousVersion libcommon.Bytes4 `json:"previous_version"`
+		CurrentVersion  libcommon.Bytes4 `json:"current_version"`
+		Epoch           uint64           `json:"epoch,string"`
+	} `json:"fork"`
+	GenesisValidatorsRoot libcommon.Hash `json:"genesis_validators_root"`
+}
+
+// SignRequest - signing root along with the object it's computed from: local signer needs just the root,
+// remote signer may check the object against its own policies and slashing protection
+type SignRequest struct {
+	Type        SignType
+	SigningRoot libcommon.Hash
+	ForkInfo    *ForkInfo // nil for builder registrations, they are signed out of any fork
+	Payload     any
+}
+
+type Signer interface {
+	Sign(ctx context.Context, req *SignRequest) (libcommon.Bytes96, error)
+}
+
+type localSigner struct {
+	key *bls.PrivateKey
+}
+
+func NewLocalSigner(key *bls.PrivateKey) Signer {
+	return &localSigner{key: key}
+}
+
+func (s *localSigner) Sign(_ context.Context, req *SignRequest) (libcommon.Bytes96, error) {
+	return libcommon.Bytes96(s.key.Sign(req.SigningRoot[:]).Bytes()), nil
+}
+
+// web3SignerTimeout - signing is on the critical path of duties, slow signer is as bad as unavailable one
+const web3SignerTimeout = 5 * time.Second
+
+var web3SignerClient = &http.Client{Timeout: web3SignerTimeout}
+
+// web3Signer - remote signer speaking Web3Signer eth2 API (https://consensys.github.io/web3signer/web3signer-eth2.html)
+type web3Signer struct {
+	url    string
+	pubkey libcommon.Bytes48
+}
+
+func NewWeb3Signer(url string, pubkey libcommon.Bytes48) Signer {
+	return &web3Signer{url: strings.TrimRight(url, "/"), pubkey: pubkey}
+}
+
+func (s *web3Signer) Sign(ctx context.Context, req *SignRequest) (libcommon.Bytes96, error) {
+	body := map[string]any{
+		"type":                req.Type,
+		"signingRoot":         req.SigningRoot,
+		req.Type.payloadKey(): req.Payload,
+	}
+	if req.ForkInfo != nil {
+		body["fork_info"] = req.ForkInfo
+	}
+	enc, err := json.Marshal(body)
+	if err != nil {
+		return libcommon.Bytes96{}, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/api/v1/eth2/sign/%s", s.url, s.pubkey), bytes.NewReader(enc))
+	if err != nil {
+		return libcommon.Bytes96{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+	resp, err := web3SignerClient.Do(httpReq)
+	if err != nil {
+		return libcommon.Bytes96{}, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return libcommon.Bytes96{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return libcommon.Bytes96{}, fmt.Errorf("remote signer: %s %d %s", req.Type, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	// json is returned only if signer supports it, older ones answer with plain hex
+	var signature libcommon.Bytes96
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		var out struct {
+			Signature libcommon.Bytes96 `json:"signature"`
+		}
+		if err := json.Unmarshal(respBody, &out); err != nil {
+			return libcommon.Bytes96{}, fmt.Errorf("remote signer: %w", err)
+		}
+		return out.Signature, nil
+	}
+	if err := signature.UnmarshalText(bytes.TrimSpace(respBody)); err != nil {
+		return libcommon.Bytes96{}, fmt.Errorf("remote signer: %w", err)
+	}
+	return signature, nil
+}
+
+// ListWeb3SignerKeys - public keys the remote signer has
+func ListWeb3SignerKeys(ctx context.Context, url string) ([]libcommon.Bytes48, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(url, "/")+"/api/v1/eth2/publicKeys", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := web3SignerClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("remote signer: list keys: %d", resp.StatusCode)
+	}
+	var keys []libcommon.Bytes48
+	if err := json.NewDecoder(resp.Body).Decode(&keys); err != nil {
+		return nil, fmt.Errorf("remote signer: list keys: %w", err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,257 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package validator_client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/log/v3"
+
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/utils/eth_clock"
+)
+
+// stubWeb3Signer - in-process Web3Signer: signs signing root of any request with its key and records requests
+type stubWeb3Signer struct {
+	t         *testing.T
+	key       *bls.PrivateKey
+	plainText bool // answer with hex instead of json, as older signers do
+
+	mu       sync.Mutex
+	requests map[SignType]map[string]json.RawMessage
+}
+
+func newStubWeb3Signer(t *testing.T, key *bls.PrivateKey) (*stubWeb3Signer, string) {
+	s := &stubWeb3Signer{t: t, key: key, requests: map[SignType]map[string]json.RawMessage{}}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return s, srv.URL
+}
+
+func (s *stubWeb3Signer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	pubkey := libcommon.Bytes48(bls.CompressPublicKey(s.key.PublicKey()))
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v1/eth2/publicKeys":
+		json.NewEncoder(w).Encode([]libcommon.Bytes48{pubkey})
+		return
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v1/eth2/sign/"+pubkey.String():
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	var req map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var signType SignType
+	var signingRoot libcommon.Hash
+	require.NoError(s.t, json.Unmarshal(req["type"], &signType))
+	require.NoError(s.t, json.Unmarshal(req["signingRoot"], &signingRoot))
+	s.mu.Lock()
+	s.requests[signType] = req
+	s.mu.Unlock()
+
+	signature := libcommon.Bytes96(s.key.Sign(signingRoot[:]).Bytes())
+	if s.plainText {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, signature.String())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"signature": signature})
+}
+
+func (s *stubWeb3Signer) request(signType SignType) map[string]json.RawMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[signType]
+}
+
+// registrationsRecorder - beacon api which accepts anything, keeps builder registrations
+type registrationsRecorder struct {
+	registrations []*cltypes.ValidatorRegistration
+}
+
+func (b *registrationsRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/eth/v1/validator/register_validator" {
+		b.registrations = nil
+		json.NewDecoder(r.Body).Decode(&b.registrations)
+	}
+}
+
+func newTestSigningClient(t *testing.T, val *validator) (*ValidatorClient, *registrationsRecorder) {
+	beaconCfg := clparams.MainnetBeaconConfig
+	dataDir := t.TempDir()
+	settings, err := openSettingsStore(dataDir + "/validator_definitions.json")
+	require.NoError(t, err)
+	val.index, val.active = 7, true
+	beacon := &registrationsRecorder{}
+	v := &ValidatorClient{
+		cfg:           Config{DataDir: dataDir, FeeRecipient: libcommon.Address{1}},
+		beaconCfg:     &beaconCfg,
+		ethClock:      eth_clock.NewEthereumClock(0, libcommon.Hash{1}, &beaconCfg),
+		beacon:        &beaconClient{handler: beacon},
+		settings:      settings,
+		protection:    openTestSlashingProtection(t, libcommon.Hash{1}),
+		logger:        log.New(),
+		byPubkey:      map[libcommon.Bytes48]*validator{},
+		registrations: map[libcommon.Bytes48]*cltypes.ValidatorRegistration{},
+	}
+	v.addValidator(val)
+	return v, beacon
+}
+
+func TestWeb3SignerSignsAsLocalKey(t *testing.T) {
+	ctx := context.Background()
+	key, err := bls.GenerateKey()
+	require.NoError(t, err)
+	stub, url := newStubWeb3Signer(t, key)
+
+	pubkeys, err := ListWeb3SignerKeys(ctx, url)
+	require.NoError(t, err)
+	require.Len(t, pubkeys, 1)
+
+	local, localBeacon := newTestSigningClient(t, localValidator(key, "", true))
+	remote, remoteBeacon := newTestSigningClient(t, remoteValidator(pubkeys[0], url, true))
+	localVal, remoteVal := local.validators[0], remote.validators[0]
+	require.Equal(t, localVal.pubkey, remoteVal.pubkey)
+
+	// each duty gives the same signature with remote signer as with local key
+	check := func(name string, sign func(v *ValidatorClient, val *validator) (libcommon.Bytes96, error)) {
+		localSig, err := sign(local, localVal)
+		require.NoError(t, err, name)
+		remoteSig, err := sign(remote, remoteVal)
+		require.NoError(t, err, name)
+		require.Equal(t, localSig, remoteSig, name)
+	}
+	epoch := local.beaconCfg.DenebForkEpoch + 1
+	slot := epoch * local.beaconCfg.SlotsPerEpoch
+
+	check("block", func(v *ValidatorClient, val *validator) (libcommon.Bytes96, error) {
+		return v.signBlock(ctx, val, &cltypes.BeaconBlockHeader{Slot: slot, ProposerIndex: 7, BodyRoot: libcommon.Hash{2}}, clparams.DenebVersion)
+	})
+	check("randao", func(v *ValidatorClient, val *validator) (libcommon.Bytes96, error) {
+		return v.signEpochOrSlot(ctx, val, v.beaconCfg.DomainRandao, epoch, epoch, SignTypeRandaoReveal, map[string]string{"epoch": "1"})
+	})
+	data := &solid.AttestationData{
+		Slot:            slot,
+		BeaconBlockRoot: libcommon.Hash{3},
+		Source:          solid.Checkpoint{Epoch: epoch - 1, Root: libcommon.Hash{4}},
+		Target:          solid.Checkpoint{Epoch: epoch, Root: libcommon.Hash{5}},
+	}
+	duty := &attesterDuty{Pubkey: localVal.pubkey, ValidatorIndex: 7, CommitteeLength: 4, ValidatorCommitteeIndex: 1, Slot: slot}
+	check("attestation", func(v *ValidatorClient, val *validator) (libcommon.Bytes96, error) {
+		att, err := v.signAttestation(ctx, duty, data, false)
+		if err != nil {
+			return libcommon.Bytes96{}, err
+		}
+		return att.Signature, nil
+	})
+	check("aggregate", func(v *ValidatorClient, val *validator) (libcommon.Bytes96, error) {
+		aggregateAndProof := &cltypes.AggregateAndProof{AggregatorIndex: 7, Aggregate: &solid.Attestation{AggregationBits: solid.NewBitList(1, int(v.beaconCfg.MaxValidatorsPerCommittee)), Data: data}, SelectionProof: libcommon.Bytes96{6}}
+		return v.sign(ctx, val, aggregateAndProof, v.beaconCfg.DomainAggregateAndProof, epoch, SignTypeAggregateAndProof, aggregateAndProof)
+	})
+	check("sync committee message", func(v *ValidatorClient, val *validator) (libcommon.Bytes96, error) {
+		req, err := v.signRequest(libcommon.Hash{7}, v.beaconCfg.DomainSyncCommittee, clparams.DenebVersion, SignTypeSyncCommitteeMessage, nil)
+		if err != nil {
+			return libcommon.Bytes96{}, err
+		}
+		return val.signer.Sign(ctx, req)
+	})
+	selectionData := &cltypes.SyncAggregatorSelectionData{Slot: slot, SubcommitteeIndex: 1}
+	check("sync committee selection proof", func(v *ValidatorClient, val *validator) (libcommon.Bytes96, error) {
+		return v.sign(ctx, val, selectionData, v.beaconCfg.DomainSyncCommitteeSelectionProof, epoch, SignTypeSyncCommitteeSelectionProof, selectionData)
+	})
+	contributionAndProof := &cltypes.ContributionAndProof{
+		AggregatorIndex: 7,
+		Contribution:    &cltypes.Contribution{Slot: slot, BeaconBlockRoot: libcommon.Hash{7}, SubcommitteeIndex: 1, AggregationBits: make([]byte, cltypes.SyncCommitteeAggregationBitsSize)},
+		SelectionProof:  libcommon.Bytes96{8},
+	}
+	check("sync committee contribution and proof", func(v *ValidatorClient, val *validator) (libcommon.Bytes96, error) {
+		return v.sign(ctx, val, contributionAndProof, v.beaconCfg.DomainContributionAndProof, epoch, SignTypeSyncCommitteeContributionAndProof, contributionAndProof)
+	})
+	check("voluntary exit", func(v *ValidatorClient, val *validator) (libcommon.Bytes96, error) {
+		rec := httptest.NewRecorder()
+		v.KeymanagerHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/eth/v1/validator/%s/voluntary_exit?epoch=%d", val.pubkey, epoch), nil))
+		var resp apiResponse[cltypes.SignedVoluntaryExit]
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			return libcommon.Bytes96{}, err
+		}
+		return resp.Data.Signature, nil
+	})
+	check("validator registration", func(v *ValidatorClient, val *validator) (libcommon.Bytes96, error) {
+		if err := v.registerValidators(ctx); err != nil {
+			return libcommon.Bytes96{}, err
+		}
+		return v.registrations[val.pubkey].Signature, nil
+	})
+	require.Len(t, localBeacon.registrations, 1)
+	require.Len(t, remoteBeacon.registrations, 1)
+
+	// remote signer gets the object and the fork along with signing root
+	block := stub.request(SignTypeBlock)
+	require.JSONEq(t, `"DENEB"`, string(mustField(t, block["beacon_block"], "version")))
+	require.Contains(t, string(block["fork_info"]), fmt.Sprintf(`"current_version":"0x%08x"`, local.beaconCfg.DenebForkVersion))
+	require.Contains(t, string(stub.request(SignTypeAttestation)["attestation"]), `"target"`)
+	require.JSONEq(t, `"1"`, string(mustField(t, stub.request(SignTypeSyncCommitteeSelectionProof)["sync_aggregator_selection_data"], "subcommittee_index")))
+	require.Contains(t, string(stub.request(SignTypeSyncCommitteeContributionAndProof)["contribution_and_proof"]), `"contribution"`)
+	require.Contains(t, string(stub.request(SignTypeVoluntaryExit)["fork_info"]), fmt.Sprintf(`"current_version":"0x%08x"`, local.beaconCfg.CapellaForkVersion), "EIP-7044")
+	require.NotContains(t, stub.request(SignTypeValidatorRegistration), "fork_info")
+	require.Contains(t, string(stub.request(SignTypeValidatorRegistration)["validator_registration"]), `"gas_limit":"30000000"`)
+
+	// remote signer is asked only for what slashing protection allows
+	_, err = remote.signBlock(ctx, remoteVal, &cltypes.BeaconBlockHeader{Slot: slot, ProposerIndex: 7, BodyRoot: libcommon.Hash{9}}, clparams.DenebVersion)
+	require.ErrorIs(t, err, ErrSlashableBlock)
+
+	// plain text answers of older signers
+	stub.plainText = true
+	check("plain text", func(v *ValidatorClient, val *validator) (libcommon.Bytes96, error) {
+		return v.signEpochOrSlot(ctx, val, v.beaconCfg.DomainRandao, epoch+1, epoch+1, SignTypeRandaoReveal, nil)
+	})
+}
+
+func mustField(t *testing.T, obj json.RawMessage, field string) json.RawMessage {
+	t.Helper()
+	var m map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(obj, &m))
+	require.Contains(t, m, field)
+	return m[field]
+}
+
+func TestWeb3SignerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "signing operation failed due to slashing protection rules", http.StatusPreconditionFailed)
+	}))
+	defer srv.Close()
+	_, err := NewWeb3Signer(srv.URL, libcommon.Bytes48{1}).Sign(context.Background(), &SignRequest{Type: SignTypeAttestation})
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "412"), err.Error())
+}
@@ -0,0 +1,218 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package validator_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/c2h5oh/datasize"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/length"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/mdbx"
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+const (
+	slashingProtectionBlocks       = "SlashingProtectionBlocks"       // pubkey + slot -> signing root
+	slashingProtectionAttestations = "SlashingProtectionAttestations" // pubkey + target epoch -> source epoch + signing root
+	slashingProtectionMinSource    = "SlashingProtectionMinSource"    // pubkey -> lowest source epoch ever signed/imported
+	slashingProtectionMeta         = "SlashingProtectionMeta"
+)
+
+var slashingProtectionTables = kv.TableCfg{
+	slashingProtectionBlocks:       {},
+	slashingProtectionAttestations: {},
+	slashingProtectionMinSource:    {},
+	slashingProtectionMeta:         {},
+}
+
+var genesisValidatorsRootKey = []byte("genesis_validators_root")
+
+var (
+	ErrSlashableBlock       = errors.New("slashable block proposal")
+	ErrSlashableAttestation = errors.New("slashable attestation")
+)
+
+// SlashingProtection - local history of signed blocks and attestations (EIP-3076 rules).
+// Signing roots may be unknown (zero) for records imported from interchange files: such records
+// forbid re-signing at the same slot/target.
+type SlashingProtection struct {
+	db                    kv.RwDB
+	genesisValidatorsRoot libcommon.Hash
+}
+
+func OpenSlashingProtection(ctx context.Context, path string, genesisValidatorsRoot libcommon.Hash, logger log.Logger) (*SlashingProtection, error) {
+	db, err := mdbx.NewMDBX(logger).
+		Label(kv.ConsensusDB).
+		Path(path).
+		WithTableCfg(func(_ kv.TableCfg) kv.TableCfg { return slashingProtectionTables }).
+		MapSize(1 * datasize.GB).
+		Open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s := &SlashingProtection{db: db, genesisValidatorsRoot: genesisValidatorsRoot}
+	if err := db.Update(ctx, func(tx kv.RwTx) error {
+		stored, err := tx.GetOne(slashingProtectionMeta, genesisValidatorsRootKey)
+		if err != nil {
+			return err
+		}
+		if len(stored) == 0 {
+			return tx.Put(slashingProtectionMeta, genesisValidatorsRootKey, genesisValidatorsRoot[:])
+		}
+		if !bytes.Equal(stored, genesisValidatorsRoot[:]) {
+			return fmt.Errorf("slashing protection db %s belongs to another network: genesis validators root %x", path, stored)
+		}
+		return nil
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SlashingProtection) Close() {
+	s.db.Close()
+}
+
+func slashingProtectionKey(pubkey libcommon.Bytes48, n uint64) []byte {
+	k := make([]byte, length.Bytes48+8)
+	copy(k, pubkey[:])
+	binary.BigEndian.PutUint64(k[length.Bytes48:], n)
+	return k
+}
+
+// CheckAndInsertBlock - records block proposal or returns ErrSlashableBlock. Re-signing of the same block is allowed.
+func (s *SlashingProtection) CheckAndInsertBlock(ctx context.Context, pubkey libcommon.Bytes48, slot uint64, signingRoot libcommon.Hash) error {
+	return s.db.Update(ctx, func(tx kv.RwTx) error {
+		return checkAndInsertBlock(tx, pubkey, slot, signingRoot)
+	})
+}
+
+func checkAndInsertBlock(tx kv.RwTx, pubkey libcommon.Bytes48, slot uint64, signingRoot libcommon.Hash) error {
+	key := slashingProtectionKey(pubkey, slot)
+	existing, err := tx.GetOne(slashingProtectionBlocks, key)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		if signingRoot != (libcommon.Hash{}) && bytes.Equal(existing, signingRoot[:]) {
+			return nil
+		}
+		return fmt.Errorf("%w: pubkey %x already signed block at slot %d", ErrSlashableBlock, pubkey, slot)
+	}
+	c, err := tx.Cursor(slashingProtectionBlocks)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	first, _, err := c.Seek(pubkey[:])
+	if err != nil {
+		return err
+	}
+	if bytes.HasPrefix(first, pubkey[:]) {
+		if minSlot := binary.BigEndian.Uint64(first[length.Bytes48:]); slot <= minSlot {
+			return fmt.Errorf("%w: pubkey %x slot %d is not above lowest signed slot %d", ErrSlashableBlock, pubkey, slot, minSlot)
+		}
+	}
+	return tx.Put(slashingProtectionBlocks, key, signingRoot[:])
+}
+
+// CheckAndInsertAttestation - records attestation or returns ErrSlashableAttestation (double vote, surround vote,
+// or vote below the lowest recorded source/target). Re-signing of the same attestation is allowed.
+func (s *SlashingProtection) CheckAndInsertAttestation(ctx context.Context, pubkey libcommon.Bytes48, source, target uint64, signingRoot libcommon.Hash) error {
+	return s.db.Update(ctx, func(tx kv.RwTx) error {
+		return checkAndInsertAttestation(tx, pubkey, source, target, signingRoot)
+	})
+}
+
+func checkAndInsertAttestation(tx kv.RwTx, pubkey libcommon.Bytes48, source, target uint64, signingRoot libcommon.Hash) error {
+	if source > target {
+		return fmt.Errorf("%w: source %d is above target %d", ErrSlashableAttestation, source, target)
+	}
+	key := slashingProtectionKey(pubkey, target)
+	existing, err := tx.GetOne(slashingProtectionAttestations, key)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		if signingRoot != (libcommon.Hash{}) && binary.BigEndian.Uint64(existing[:8]) == source && bytes.Equal(existing[8:], signingRoot[:]) {
+			return nil
+		}
+		return fmt.Errorf("%w: pubkey %x double vote at target %d", ErrSlashableAttestation, pubkey, target)
+	}
+
+	minSource, err := tx.GetOne(slashingProtectionMinSource, pubkey[:])
+	if err != nil {
+		return err
+	}
+	if minSource != nil && source < binary.BigEndian.Uint64(minSource) {
+		return fmt.Errorf("%w: pubkey %x source %d is below lowest signed source %d", ErrSlashableAttestation, pubkey, source, binary.BigEndian.Uint64(minSource))
+	}
+
+	c, err := tx.Cursor(slashingProtectionAttestations)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	k, _, err := c.Seek(pubkey[:])
+	if err != nil {
+		return err
+	}
+	if bytes.HasPrefix(k, pubkey[:]) {
+		if minTarget := binary.BigEndian.Uint64(k[length.Bytes48:]); target <= minTarget {
+			return fmt.Errorf("%w: pubkey %x target %d is not above lowest signed target %d", ErrSlashableAttestation, pubkey, target, minTarget)
+		}
+	}
+	// only attestations with target above our source can surround or be surrounded
+	for k, v, err := c.Seek(slashingProtectionKey(pubkey, source+1)); k != nil && bytes.HasPrefix(k, pubkey[:]); k, v, err = c.Next() {
+		if err != nil {
+			return err
+		}
+		prevTarget, prevSource := binary.BigEndian.Uint64(k[length.Bytes48:]), binary.BigEndian.Uint64(v[:8])
+		if prevTarget < target && prevSource > source {
+			return fmt.Errorf("%w: pubkey %x vote %d->%d surrounds %d->%d", ErrSlashableAttestation, pubkey, source, target, prevSource, prevTarget)
+		}
+		if prevTarget > target && prevSource < source {
+			return fmt.Errorf("%w: pubkey %x vote %d->%d is surrounded by %d->%d", ErrSlashableAttestation, pubkey, source, target, prevSource, prevTarget)
+		}
+	}
+	return putAttestation(tx, pubkey, source, target, signingRoot)
+}
+
+func putAttestation(tx kv.RwTx, pubkey libcommon.Bytes48, source, target uint64, signingRoot libcommon.Hash) error {
+	v := make([]byte, 8+length.Hash)
+	binary.BigEndian.PutUint64(v, source)
+	copy(v[8:], signingRoot[:])
+	if err := tx.Put(slashingProtectionAttestations, slashingProtectionKey(pubkey, target), v); err != nil {
+		return err
+	}
+	minSource, err := tx.GetOne(slashingProtectionMinSource, pubkey[:])
+	if err != nil {
+		return err
+	}
+	if minSource == nil || source < binary.BigEndian.Uint64(minSource) {
+		return tx.Put(slashingProtectionMinSource, pubkey[:], v[:8])
+	}
+	return nil
+}
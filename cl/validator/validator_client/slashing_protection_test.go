@@ -0,0 +1,120 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package validator_client
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+func openTestSlashingProtection(t *testing.T, gvr libcommon.Hash) *SlashingProtection {
+	t.Helper()
+	s, err := OpenSlashingProtection(context.Background(), filepath.Join(t.TempDir(), "slashing_protection"), gvr, log.New())
+	require.NoError(t, err)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestSlashingProtectionBlocks(t *testing.T) {
+	ctx := context.Background()
+	s := openTestSlashingProtection(t, libcommon.Hash{1})
+	pk := libcommon.Bytes48{1}
+
+	require.NoError(t, s.CheckAndInsertBlock(ctx, pk, 10, libcommon.Hash{1}))
+	require.NoError(t, s.CheckAndInsertBlock(ctx, pk, 10, libcommon.Hash{1}), "re-signing same block")
+	require.ErrorIs(t, s.CheckAndInsertBlock(ctx, pk, 10, libcommon.Hash{2}), ErrSlashableBlock, "double proposal")
+	require.ErrorIs(t, s.CheckAndInsertBlock(ctx, pk, 9, libcommon.Hash{3}), ErrSlashableBlock, "below lowest slot")
+	require.NoError(t, s.CheckAndInsertBlock(ctx, pk, 12, libcommon.Hash{4}))
+	require.NoError(t, s.CheckAndInsertBlock(ctx, pk, 11, libcommon.Hash{5}))
+	require.NoError(t, s.CheckAndInsertBlock(ctx, libcommon.Bytes48{2}, 9, libcommon.Hash{3}), "other validator")
+}
+
+func TestSlashingProtectionAttestations(t *testing.T) {
+	ctx := context.Background()
+	s := openTestSlashingProtection(t, libcommon.Hash{1})
+	pk := libcommon.Bytes48{1}
+
+	require.NoError(t, s.CheckAndInsertAttestation(ctx, pk, 2, 3, libcommon.Hash{1}))
+	require.NoError(t, s.CheckAndInsertAttestation(ctx, pk, 2, 3, libcommon.Hash{1}), "re-signing same attestation")
+	require.ErrorIs(t, s.CheckAndInsertAttestation(ctx, pk, 2, 3, libcommon.Hash{2}), ErrSlashableAttestation, "double vote")
+	require.ErrorIs(t, s.CheckAndInsertAttestation(ctx, pk, 4, 3, libcommon.Hash{2}), ErrSlashableAttestation, "source above target")
+	require.NoError(t, s.CheckAndInsertAttestation(ctx, pk, 5, 6, libcommon.Hash{3}))
+	require.ErrorIs(t, s.CheckAndInsertAttestation(ctx, pk, 4, 7, libcommon.Hash{4}), ErrSlashableAttestation, "surrounding vote")
+	require.NoError(t, s.CheckAndInsertAttestation(ctx, pk, 6, 10, libcommon.Hash{5}))
+	require.ErrorIs(t, s.CheckAndInsertAttestation(ctx, pk, 7, 8, libcommon.Hash{6}), ErrSlashableAttestation, "surrounded vote")
+	require.ErrorIs(t, s.CheckAndInsertAttestation(ctx, pk, 1, 4, libcommon.Hash{6}), ErrSlashableAttestation, "below lowest source")
+	require.ErrorIs(t, s.CheckAndInsertAttestation(ctx, pk, 2, 2, libcommon.Hash{6}), ErrSlashableAttestation, "below lowest target")
+	require.NoError(t, s.CheckAndInsertAttestation(ctx, pk, 6, 8, libcommon.Hash{7}))
+}
+
+func TestSlashingProtectionInterchange(t *testing.T) {
+	ctx := context.Background()
+	gvr := libcommon.HexToHash("0x04700007fabc8282644aed6d1c7c9e21d38a03a0c4ba193f3afe428824b3a673")
+	const interchange = `{
+  "metadata": {
+    "interchange_format_version": "5",
+    "genesis_validators_root": "0x04700007fabc8282644aed6d1c7c9e21d38a03a0c4ba193f3afe428824b3a673"
+  },
+  "data": [
+    {
+      "pubkey": "0xb845089a1457f811bfc000588fbb4e713669be8ce060ea6be3c6ece09afc3794106c91ca73acda5e5457122d58723bed",
+      "signed_blocks": [
+        {"slot": "81952", "signing_root": "0x4ff6f743a43f3b4f95350831aeaf0a122a1a392922c45d804280284a69eb850b"},
+        {"slot": "81951"}
+      ],
+      "signed_attestations": [
+        {"source_epoch": "2290", "target_epoch": "3007", "signing_root": "0x587d6a4f59a58fe24f406e0502413e77fe1babddee641fda30034ed37ecc884d"},
+        {"source_epoch": "2290", "target_epoch": "3008"}
+      ]
+    }
+  ]
+}`
+	s := openTestSlashingProtection(t, gvr)
+	n, err := s.ImportInterchange(ctx, strings.NewReader(interchange))
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	pk := libcommon.Bytes48(libcommon.FromHex("0xb845089a1457f811bfc000588fbb4e713669be8ce060ea6be3c6ece09afc3794106c91ca73acda5e5457122d58723bed"))
+	require.ErrorIs(t, s.CheckAndInsertBlock(ctx, pk, 81951, libcommon.Hash{}), ErrSlashableBlock, "unknown root")
+	require.NoError(t, s.CheckAndInsertBlock(ctx, pk, 81952, libcommon.HexToHash("0x4ff6f743a43f3b4f95350831aeaf0a122a1a392922c45d804280284a69eb850b")))
+	require.ErrorIs(t, s.CheckAndInsertAttestation(ctx, pk, 2290, 3008, libcommon.Hash{1}), ErrSlashableAttestation)
+	require.ErrorIs(t, s.CheckAndInsertAttestation(ctx, pk, 2289, 3009, libcommon.Hash{1}), ErrSlashableAttestation)
+	require.NoError(t, s.CheckAndInsertAttestation(ctx, pk, 3008, 3009, libcommon.Hash{1}))
+
+	var buf bytes.Buffer
+	require.NoError(t, s.ExportInterchange(ctx, &buf))
+
+	// exported file is importable into fresh db and round-trips
+	other := openTestSlashingProtection(t, gvr)
+	_, err = other.ImportInterchange(ctx, bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	var buf2 bytes.Buffer
+	require.NoError(t, other.ExportInterchange(ctx, &buf2))
+	require.Equal(t, buf.String(), buf2.String())
+	require.Contains(t, buf.String(), `"target_epoch": "3009"`)
+
+	_, err = openTestSlashingProtection(t, libcommon.Hash{1}).ImportInterchange(ctx, bytes.NewReader(buf.Bytes()))
+	require.ErrorContains(t, err, "genesis validators root")
+}
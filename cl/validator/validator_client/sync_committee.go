@@ -0,0 +1,182 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package validator_client
+
+import (
+	"context"
+	"encoding/binary"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/utils"
+)
+
+// syncCommitteeMessages - members of the current sync committee sign head block root at 1/3 of the slot,
+// aggregators of its subcommittees publish contributions to the same root at 2/3 (as the spec recommends)
+func (v *ValidatorClient) syncCommitteeMessages(ctx context.Context, slot uint64) {
+	v.mu.Lock()
+	duties := v.syncDuties
+	v.mu.Unlock()
+	if len(duties) == 0 {
+		return
+	}
+	slotTime := v.ethClock.GetSlotTime(slot)
+	slotDuration := time.Duration(v.beaconCfg.SecondsPerSlot) * time.Second
+	if err := sleepUntil(ctx, slotTime.Add(slotDuration/3)); err != nil {
+		return
+	}
+	var head apiResponse[struct {
+		Root libcommon.Hash `json:"root"`
+	}]
+	if _, err := v.beacon.get(ctx, "/eth/v1/beacon/blocks/head/root", nil, &head); err != nil {
+		v.logger.Warn("[Validator] Failed to get head block root", "slot", slot, "err", err)
+		return
+	}
+	root := head.Data.Root
+	epoch := slot / v.beaconCfg.SlotsPerEpoch
+	payload := map[string]any{"beacon_block_root": root, "slot": strconv.FormatUint(slot, 10)}
+	messages := make([]*cltypes.SyncCommitteeMessage, 0, len(duties))
+	for _, d := range duties {
+		val, ok := v.getValidator(d.Pubkey)
+		if !ok {
+			continue
+		}
+		// block root is the object root: its hash tree root is itself
+		req, err := v.signRequest(root, v.beaconCfg.DomainSyncCommittee, v.beaconCfg.GetCurrentStateVersion(epoch), SignTypeSyncCommitteeMessage, payload)
+		if err != nil {
+			v.logger.Warn("[Validator] Failed to sign sync committee message", "slot", slot, "index", d.ValidatorIndex, "err", err)
+			continue
+		}
+		signature, err := val.signer.Sign(ctx, req)
+		if err != nil {
+			v.logger.Warn("[Validator] Failed to sign sync committee message", "slot", slot, "index", d.ValidatorIndex, "err", err)
+			continue
+		}
+		messages = append(messages, &cltypes.SyncCommitteeMessage{
+			Slot:            slot,
+			BeaconBlockRoot: root,
+			ValidatorIndex:  d.ValidatorIndex,
+			Signature:       signature,
+		})
+	}
+	if len(messages) == 0 {
+		return
+	}
+	if err := v.beacon.post(ctx, "/eth/v1/beacon/pool/sync_committees", messages, nil); err != nil {
+		v.logger.Warn("[Validator] Failed to publish sync committee messages", "slot", slot, "err", err)
+		return
+	}
+	v.logger.Debug("[Validator] Published sync committee messages", "slot", slot, "count", len(messages))
+
+	if err := sleepUntil(ctx, slotTime.Add(2*slotDuration/3)); err != nil {
+		return
+	}
+	var contributions []*cltypes.SignedContributionAndProof
+	for _, d := range duties {
+		val, ok := v.getValidator(d.Pubkey)
+		if !ok {
+			continue
+		}
+		subcommittees, err := v.syncSubcommittees(d)
+		if err != nil {
+			v.logger.Warn("[Validator] Invalid sync committee duty", "index", d.ValidatorIndex, "err", err)
+			continue
+		}
+		for _, subcommittee := range subcommittees {
+			contribution, err := v.syncContribution(ctx, val, d.ValidatorIndex, slot, subcommittee, root)
+			if err != nil {
+				v.logger.Warn("[Validator] Failed to aggregate sync committee contribution", "slot", slot, "index", d.ValidatorIndex, "subcommittee", subcommittee, "err", err)
+				continue
+			}
+			if contribution != nil {
+				contributions = append(contributions, contribution)
+			}
+		}
+	}
+	if len(contributions) == 0 {
+		return
+	}
+	if err := v.beacon.post(ctx, "/eth/v1/validator/contribution_and_proofs", contributions, nil); err != nil {
+		v.logger.Warn("[Validator] Failed to publish sync committee contributions", "slot", slot, "err", err)
+		return
+	}
+	v.logger.Debug("[Validator] Published sync committee contributions", "slot", slot, "count", len(contributions))
+}
+
+// syncSubcommittees - subcommittees of validator's positions in the sync committee
+func (v *ValidatorClient) syncSubcommittees(d *syncDuty) ([]uint64, error) {
+	subcommitteeSize := v.beaconCfg.SyncCommitteeSize / v.beaconCfg.SyncCommitteeSubnetCount
+	var subcommittees []uint64
+	for _, idx := range d.ValidatorSyncCommitteeIndices {
+		i, err := strconv.ParseUint(idx, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if subcommittee := i / subcommitteeSize; !slices.Contains(subcommittees, subcommittee) {
+			subcommittees = append(subcommittees, subcommittee)
+		}
+	}
+	return subcommittees, nil
+}
+
+// isSyncCommitteeAggregator - is_sync_committee_aggregator of the spec
+func (v *ValidatorClient) isSyncCommitteeAggregator(selectionProof libcommon.Bytes96) bool {
+	modulo := max(1, v.beaconCfg.SyncCommitteeSize/v.beaconCfg.SyncCommitteeSubnetCount/v.beaconCfg.TargetAggregatorsPerSyncSubcommittee)
+	h := utils.Sha256(selectionProof[:])
+	return binary.LittleEndian.Uint64(h[:8])%modulo == 0
+}
+
+// syncContribution - signed contribution of the subcommittee to `root`, nil if validator isn't its aggregator
+// or there is nothing to aggregate
+func (v *ValidatorClient) syncContribution(ctx context.Context, val *validator, index, slot, subcommittee uint64, root libcommon.Hash) (*cltypes.SignedContributionAndProof, error) {
+	epoch := slot / v.beaconCfg.SlotsPerEpoch
+	selectionData := &cltypes.SyncAggregatorSelectionData{Slot: slot, SubcommitteeIndex: subcommittee}
+	selectionProof, err := v.sign(ctx, val, selectionData, v.beaconCfg.DomainSyncCommitteeSelectionProof, epoch, SignTypeSyncCommitteeSelectionProof, selectionData)
+	if err != nil {
+		return nil, err
+	}
+	if !v.isSyncCommitteeAggregator(selectionProof) {
+		return nil, nil
+	}
+	query := url.Values{
+		"slot":               {strconv.FormatUint(slot, 10)},
+		"subcommittee_index": {strconv.FormatUint(subcommittee, 10)},
+		"beacon_block_root":  {root.Hex()},
+	}
+	var resp apiResponse[*cltypes.Contribution]
+	if _, err := v.beacon.get(ctx, "/eth/v1/validator/sync_committee_contribution", query, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data == nil || !slices.ContainsFunc(resp.Data.AggregationBits, func(b byte) bool { return b != 0 }) {
+		return nil, nil
+	}
+	contributionAndProof := &cltypes.ContributionAndProof{
+		AggregatorIndex: index,
+		Contribution:    resp.Data,
+		SelectionProof:  selectionProof,
+	}
+	signature, err := v.sign(ctx, val, contributionAndProof, v.beaconCfg.DomainContributionAndProof, epoch, SignTypeSyncCommitteeContributionAndProof, contributionAndProof)
+	if err != nil {
+		return nil, err
+	}
+	return &cltypes.SignedContributionAndProof{Message: contributionAndProof, Signature: signature}, nil
+}
@@ -0,0 +1,931 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package validator_client
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Giulio2002/bls"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/types/ssz"
+
+	"github.com/erigontech/erigon/cl/beacon/beaconhttp"
+	"github.com/erigontech/erigon/cl/beacon/building"
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/fork"
+	"github.com/erigontech/erigon/cl/utils"
+	"github.com/erigontech/erigon/cl/utils/eth_clock"
+)
+
+var errValidatorDeleted = errors.New("validator was deleted")
+
+// maxValidatorsLookup - limit of ids per request of /eth/v1/beacon/states/{state_id}/validators
+const maxValidatorsLookup = 128
+
+type Config struct {
+	// DataDir - slashing protection db, keystores imported by keymanager API and per-validator settings
+	DataDir string
+	// KeystoreDir - keystores sharing the password of PasswordFile, they can't be deleted by keymanager API
+	KeystoreDir  string
+	PasswordFile string
+	FeeRecipient libcommon.Address // sent by prepare_beacon_proposer if set, can be overridden per validator
+	Graffiti     string
+	// DoppelgangerEpochs - amount of epochs to watch for our validators activity before signing anything (0 - disabled)
+	DoppelgangerEpochs uint64
+	// Builder - register validators with builders (beacon api must run with builder endpoints)
+	Builder bool
+	// RemoteSignerURL - Web3Signer compatible signer, all its keys are used as read-only remote keys
+	RemoteSignerURL string
+
+	SlashingProtectionImport string // EIP-3076 interchange file to merge into db on start
+	SlashingProtectionExport string // EIP-3076 interchange file to write on stop
+}
+
+type validator struct {
+	signer         Signer
+	pubkey         libcommon.Bytes48
+	index          uint64
+	active         bool // index is known: validator is in the beacon state
+	readonly       bool // loaded from Config.KeystoreDir or found at Config.RemoteSignerURL
+	derivationPath string
+	signerURL      string // remote signer of the key, empty for keystores
+}
+
+func localValidator(key *bls.PrivateKey, derivationPath string, readonly bool) *validator {
+	return &validator{
+		signer:         NewLocalSigner(key),
+		pubkey:         libcommon.Bytes48(bls.CompressPublicKey(key.PublicKey())),
+		readonly:       readonly,
+		derivationPath: derivationPath,
+	}
+}
+
+func remoteValidator(pubkey libcommon.Bytes48, url string, readonly bool) *validator {
+	return &validator{signer: NewWeb3Signer(url, pubkey), pubkey: pubkey, readonly: readonly, signerURL: url}
+}
+
+type proposerDuty struct {
+	Pubkey         libcommon.Bytes48 `json:"pubkey"`
+	ValidatorIndex uint64            `json:"validator_index,string"`
+	Slot           uint64            `json:"slot,string"`
+}
+
+type attesterDuty struct {
+	Pubkey                  libcommon.Bytes48 `json:"pubkey"`
+	ValidatorIndex          uint64            `json:"validator_index,string"`
+	CommitteeIndex          uint64            `json:"committee_index,string"`
+	CommitteeLength         uint64            `json:"committee_length,string"`
+	ValidatorCommitteeIndex uint64            `json:"validator_committee_index,string"`
+	CommitteesAtSlot        uint64            `json:"committees_at_slot,string"`
+	Slot                    uint64            `json:"slot,string"`
+
+	selectionProof libcommon.Bytes96
+	isAggregator   bool
+}
+
+type syncDuty struct {
+	Pubkey                        libcommon.Bytes48 `json:"pubkey"`
+	ValidatorIndex                uint64            `json:"validator_index,string"`
+	ValidatorSyncCommitteeIndices []string          `json:"validator_sync_committee_indices"`
+}
+
+type validatorResponse struct {
+	Index     uint64 `json:"index,string"`
+	Validator struct {
+		Pubkey libcommon.Bytes48 `json:"pubkey"`
+	} `json:"validator"`
+}
+
+// ValidatorClient - performs duties of local validators through Beacon API handlers of the same process
+type ValidatorClient struct {
+	cfg        Config
+	beaconCfg  *clparams.BeaconChainConfig
+	ethClock   eth_clock.EthereumClock
+	beacon     *beaconClient
+	protection *SlashingProtection
+	logger     log.Logger
+
+	settings *settingsStore
+
+	// validators can be imported and deleted at runtime by keymanager API
+	keysMu      sync.RWMutex
+	validators  []*validator
+	byPubkey    map[libcommon.Bytes48]*validator
+	keysChanged atomic.Bool // duties must be refetched
+
+	mu             sync.Mutex
+	proposerDuties map[uint64]*proposerDuty   // slot -> duty
+	attesterDuties map[uint64][]*attesterDuty // slot -> duties
+	dutiesEpoch    uint64                     // attester duties are fetched up to this epoch (inclusive)
+	syncDuties     []*syncDuty                // of dutiesEpoch-1
+	registrations  map[libcommon.Bytes48]*cltypes.ValidatorRegistration
+}
+
+// NewValidatorClient - loads keystores and opens slashing protection db. `handler` serves Beacon API
+// (validator and beacon namespaces must be enabled).
+func NewValidatorClient(ctx context.Context, cfg Config, beaconCfg *clparams.BeaconChainConfig, ethClock eth_clock.EthereumClock, handler http.Handler, logger log.Logger) (*ValidatorClient, error) {
+	for _, dir := range []string{filepath.Join(cfg.DataDir, managedKeystoresDir), filepath.Join(cfg.DataDir, managedSecretsDir)} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, err
+		}
+	}
+	settings, err := openSettingsStore(filepath.Join(cfg.DataDir, "validator_definitions.json"))
+	if err != nil {
+		return nil, fmt.Errorf("open validator definitions: %w", err)
+	}
+	v := &ValidatorClient{
+		cfg:            cfg,
+		beaconCfg:      beaconCfg,
+		ethClock:       ethClock,
+		beacon:         &beaconClient{handler: handler},
+		settings:       settings,
+		logger:         logger,
+		byPubkey:       map[libcommon.Bytes48]*validator{},
+		proposerDuties: map[uint64]*proposerDuty{},
+		attesterDuties: map[uint64][]*attesterDuty{},
+		registrations:  map[libcommon.Bytes48]*cltypes.ValidatorRegistration{},
+	}
+	if cfg.KeystoreDir != "" {
+		password, err := os.ReadFile(cfg.PasswordFile)
+		if err != nil {
+			return nil, fmt.Errorf("read password file: %w", err)
+		}
+		keys, err := LoadKeystores(cfg.KeystoreDir, strings.TrimRight(string(password), "\r\n"))
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			v.addValidator(localValidator(key, "", true))
+		}
+	}
+	if err := v.loadManagedKeystores(); err != nil {
+		return nil, err
+	}
+	if cfg.RemoteSignerURL != "" {
+		pubkeys, err := ListWeb3SignerKeys(ctx, cfg.RemoteSignerURL)
+		if err != nil {
+			return nil, err
+		}
+		for _, pubkey := range pubkeys {
+			v.addValidator(remoteValidator(pubkey, cfg.RemoteSignerURL, true))
+		}
+		logger.Info("[Validator] Loaded keys of remote signer", "url", cfg.RemoteSignerURL, "keys", len(pubkeys))
+	}
+	for pubkey, url := range settings.remoteKeys() {
+		if !v.addValidator(remoteValidator(pubkey, url, false)) {
+			logger.Warn("[Validator] Remote key is already loaded", "pubkey", pubkey)
+		}
+	}
+
+	protection, err := OpenSlashingProtection(ctx, filepath.Join(cfg.DataDir, "slashing_protection"), ethClock.GenesisValidatorsRoot(), logger)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SlashingProtectionImport != "" {
+		f, err := os.Open(cfg.SlashingProtectionImport)
+		if err != nil {
+			protection.Close()
+			return nil, err
+		}
+		n, err := protection.ImportInterchange(ctx, f)
+		f.Close()
+		if err != nil {
+			protection.Close()
+			return nil, fmt.Errorf("import slashing protection %s: %w", cfg.SlashingProtectionImport, err)
+		}
+		logger.Info("[Validator] Imported slashing protection", "file", cfg.SlashingProtectionImport, "validators", n)
+	}
+	v.protection = protection
+	logger.Info("[Validator] Loaded keystores", "validators", len(v.validators))
+	return v, nil
+}
+
+// addValidator - caller must hold keysMu (unless the client isn't running yet). Returns false if key is already known.
+func (v *ValidatorClient) addValidator(val *validator) bool {
+	if _, ok := v.byPubkey[val.pubkey]; ok {
+		return false
+	}
+	v.validators = append(v.validators, val)
+	v.byPubkey[val.pubkey] = val
+	v.keysChanged.Store(true)
+	return true
+}
+
+// removeValidator - caller must hold keysMu
+func (v *ValidatorClient) removeValidator(pubkey libcommon.Bytes48) {
+	delete(v.byPubkey, pubkey)
+	v.validators = slices.DeleteFunc(v.validators, func(val *validator) bool { return val.pubkey == pubkey })
+	v.keysChanged.Store(true)
+}
+
+func (v *ValidatorClient) getValidator(pubkey libcommon.Bytes48) (*validator, bool) {
+	v.keysMu.RLock()
+	defer v.keysMu.RUnlock()
+	val, ok := v.byPubkey[pubkey]
+	return val, ok
+}
+
+// activeValidators - validators with known index. Index of active validator never changes, so it can be read without lock.
+func (v *ValidatorClient) activeValidators() []*validator {
+	v.keysMu.RLock()
+	defer v.keysMu.RUnlock()
+	active := make([]*validator, 0, len(v.validators))
+	for _, val := range v.validators {
+		if val.active {
+			active = append(active, val)
+		}
+	}
+	return active
+}
+
+func (v *ValidatorClient) feeRecipient(pubkey libcommon.Bytes48) libcommon.Address {
+	if settings := v.settings.settings(pubkey); settings.FeeRecipient != nil {
+		return *settings.FeeRecipient
+	}
+	return v.cfg.FeeRecipient
+}
+
+func (v *ValidatorClient) gasLimit(pubkey libcommon.Bytes48) uint64 {
+	if settings := v.settings.settings(pubkey); settings.GasLimit != 0 {
+		return settings.GasLimit
+	}
+	return DefaultGasLimit
+}
+
+func (v *ValidatorClient) graffiti(pubkey libcommon.Bytes48) string {
+	if settings := v.settings.settings(pubkey); settings.Graffiti != nil {
+		return *settings.Graffiti
+	}
+	return v.cfg.Graffiti
+}
+
+// Run - blocks until ctx is done or doppelganger is detected. Closes slashing protection db on exit.
+func (v *ValidatorClient) Run(ctx context.Context) error {
+	defer v.protection.Close()
+	if v.cfg.SlashingProtectionExport != "" {
+		defer v.exportSlashingProtection()
+	}
+
+	// wait for the node to serve head state
+	for {
+		err := v.resolveIndices(ctx)
+		if err == nil {
+			break
+		}
+		v.logger.Debug("[Validator] Waiting for beacon node", "err", err)
+		if err := sleepUntil(ctx, v.ethClock.GetSlotTime(v.ethClock.GetCurrentSlot()+1)); err != nil {
+			return err
+		}
+	}
+	if err := v.doppelgangerCheck(ctx, v.cfg.DoppelgangerEpochs); err != nil {
+		return err
+	}
+
+	v.logger.Info("[Validator] Started performing duties")
+	var (
+		dutiesUpdated bool
+		dutiesEpoch   uint64
+	)
+	for slot := v.ethClock.GetCurrentSlot() + 1; ; slot++ {
+		if err := sleepUntil(ctx, v.ethClock.GetSlotTime(slot)); err != nil {
+			return err
+		}
+		if current := v.ethClock.GetCurrentSlot(); slot < current {
+			slot = current // we fell behind: skip missed slots
+		}
+		keysChanged := v.keysChanged.Swap(false)
+		if epoch := slot / v.beaconCfg.SlotsPerEpoch; !dutiesUpdated || epoch != dutiesEpoch || keysChanged {
+			// retried every slot until success
+			if err := v.updateDuties(ctx, epoch, keysChanged); err != nil {
+				v.logger.Warn("[Validator] Failed to update duties", "epoch", epoch, "err", err)
+				dutiesUpdated = false
+				if keysChanged {
+					v.keysChanged.Store(true)
+				}
+			} else {
+				dutiesUpdated, dutiesEpoch = true, epoch
+			}
+		}
+		go v.propose(ctx, slot)
+		go v.attest(ctx, slot)
+		go v.syncCommitteeMessages(ctx, slot)
+	}
+}
+
+func (v *ValidatorClient) exportSlashingProtection() {
+	f, err := os.Create(v.cfg.SlashingProtectionExport)
+	if err != nil {
+		v.logger.Error("[Validator] Failed to export slashing protection", "err", err)
+		return
+	}
+	defer f.Close()
+	if err := v.protection.ExportInterchange(context.Background(), f); err != nil {
+		v.logger.Error("[Validator] Failed to export slashing protection", "err", err)
+		return
+	}
+	v.logger.Info("[Validator] Exported slashing protection", "file", v.cfg.SlashingProtectionExport)
+}
+
+func sleepUntil(ctx context.Context, t time.Time) error {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// resolveIndices - finds indices of validators which are not known yet (deposits may be processed later)
+func (v *ValidatorClient) resolveIndices(ctx context.Context) error {
+	var ids []string
+	v.keysMu.RLock()
+	for _, val := range v.validators {
+		if !val.active {
+			ids = append(ids, hex.EncodeToString(val.pubkey[:]))
+		}
+	}
+	v.keysMu.RUnlock()
+	for len(ids) > 0 {
+		batch := ids[:min(len(ids), maxValidatorsLookup)]
+		ids = ids[len(batch):]
+		for i := range batch {
+			batch[i] = "0x" + batch[i]
+		}
+		var resp apiResponse[[]validatorResponse]
+		if err := v.beacon.post(ctx, "/eth/v1/beacon/states/head/validators", map[string][]string{"ids": batch}, &resp); err != nil {
+			return err
+		}
+		v.keysMu.Lock()
+		for _, r := range resp.Data {
+			if val, ok := v.byPubkey[r.Validator.Pubkey]; ok && !val.active {
+				val.index, val.active = r.Index, true
+				v.logger.Info("[Validator] Validator found", "index", r.Index, "pubkey", r.Validator.Pubkey)
+			}
+		}
+		v.keysMu.Unlock()
+	}
+	return nil
+}
+
+func (v *ValidatorClient) activeIndices() []string {
+	active := v.activeValidators()
+	indices := make([]string, 0, len(active))
+	for _, val := range active {
+		indices = append(indices, strconv.FormatUint(val.index, 10))
+	}
+	return indices
+}
+
+func (v *ValidatorClient) domain(domainType libcommon.Bytes4, version clparams.StateVersion) ([]byte, error) {
+	forkVersion := utils.Uint32ToBytes4(v.beaconCfg.GetForkVersionByVersion(version))
+	return fork.ComputeDomain(domainType[:], forkVersion, v.ethClock.GenesisValidatorsRoot())
+}
+
+// forkInfo - fork of the domain for remote signer
+func (v *ValidatorClient) forkInfo(version clparams.StateVersion) *ForkInfo {
+	info := &ForkInfo{GenesisValidatorsRoot: v.ethClock.GenesisValidatorsRoot()}
+	previous := version
+	if version > clparams.Phase0Version {
+		previous = version - 1
+	}
+	info.Fork.PreviousVersion = utils.Uint32ToBytes4(v.beaconCfg.GetForkVersionByVersion(previous))
+	info.Fork.CurrentVersion = utils.Uint32ToBytes4(v.beaconCfg.GetForkVersionByVersion(version))
+	info.Fork.Epoch = v.beaconCfg.GetForkEpochByVersion(version)
+	return info
+}
+
+// signRequest - request to sign object with given hash tree root in the domain of `version`
+func (v *ValidatorClient) signRequest(objectRoot libcommon.Hash, domainType libcommon.Bytes4, version clparams.StateVersion, signType SignType, payload any) (*SignRequest, error) {
+	domain, err := v.domain(domainType, version)
+	if err != nil {
+		return nil, err
+	}
+	return &SignRequest{
+		Type:        signType,
+		SigningRoot: utils.Sha256(objectRoot[:], domain),
+		ForkInfo:    v.forkInfo(version),
+		Payload:     payload,
+	}, nil
+}
+
+// signEpochOrSlot - signature over uint64 (randao reveal, selection proof)
+func (v *ValidatorClient) signEpochOrSlot(ctx context.Context, val *validator, domainType libcommon.Bytes4, epoch, n uint64, signType SignType, payload any) (libcommon.Bytes96, error) {
+	var objectRoot libcommon.Hash
+	binary.LittleEndian.PutUint64(objectRoot[:], n)
+	req, err := v.signRequest(objectRoot, domainType, v.beaconCfg.GetCurrentStateVersion(epoch), signType, payload)
+	if err != nil {
+		return libcommon.Bytes96{}, err
+	}
+	return val.signer.Sign(ctx, req)
+}
+
+// sign - signature of object which isn't slashable
+func (v *ValidatorClient) sign(ctx context.Context, val *validator, obj ssz.HashableSSZ, domainType libcommon.Bytes4, epoch uint64, signType SignType, payload any) (libcommon.Bytes96, error) {
+	objectRoot, err := obj.HashSSZ()
+	if err != nil {
+		return libcommon.Bytes96{}, err
+	}
+	req, err := v.signRequest(objectRoot, domainType, v.beaconCfg.GetCurrentStateVersion(epoch), signType, payload)
+	if err != nil {
+		return libcommon.Bytes96{}, err
+	}
+	return val.signer.Sign(ctx, req)
+}
+
+// updateDuties - proposer duties of `epoch`, attester duties of `epoch` and `epoch+1` (to subscribe to subnets in advance).
+// Attester duties which are already known are refetched only if `keysChanged`.
+func (v *ValidatorClient) updateDuties(ctx context.Context, epoch uint64, keysChanged bool) error {
+	if err := v.resolveIndices(ctx); err != nil {
+		return err
+	}
+	indices := v.activeIndices()
+	if len(indices) == 0 {
+		return nil
+	}
+
+	var proposers apiResponse[[]*proposerDuty]
+	if _, err := v.beacon.get(ctx, fmt.Sprintf("/eth/v1/validator/duties/proposer/%d", epoch), nil, &proposers); err != nil {
+		return err
+	}
+	var subscriptions []building.BeaconCommitteeSubscription
+	attesterDuties := map[uint64][]*attesterDuty{}
+	fromEpoch := epoch
+	v.mu.Lock()
+	if v.dutiesEpoch >= epoch && len(v.attesterDuties) > 0 && !keysChanged {
+		fromEpoch = epoch + 1
+	}
+	v.mu.Unlock()
+	for e := fromEpoch; e <= epoch+1; e++ {
+		var attesters apiResponse[[]*attesterDuty]
+		if err := v.beacon.post(ctx, fmt.Sprintf("/eth/v1/validator/duties/attester/%d", e), indices, &attesters); err != nil {
+			return err
+		}
+		for _, d := range attesters.Data {
+			val, ok := v.getValidator(d.Pubkey)
+			if !ok {
+				continue
+			}
+			proof, err := v.signEpochOrSlot(ctx, val, v.beaconCfg.DomainSelectionProof, e, d.Slot,
+				SignTypeAggregationSlot, map[string]string{"slot": strconv.FormatUint(d.Slot, 10)})
+			if err != nil {
+				return err
+			}
+			d.selectionProof, d.isAggregator = proof, v.isAggregator(d.CommitteeLength, proof)
+			attesterDuties[d.Slot] = append(attesterDuties[d.Slot], d)
+			subscriptions = append(subscriptions, building.BeaconCommitteeSubscription{
+				ValidatorIndex:   int(d.ValidatorIndex),
+				CommitteeIndex:   int(d.CommitteeIndex),
+				CommitteesAtSlot: int(d.CommitteesAtSlot),
+				Slot:             int(d.Slot),
+				IsAggregator:     d.isAggregator,
+			})
+		}
+	}
+	if len(subscriptions) > 0 {
+		if err := v.beacon.post(ctx, "/eth/v1/validator/beacon_committee_subscriptions", subscriptions, nil); err != nil {
+			return err
+		}
+	}
+	var syncDuties apiResponse[[]*syncDuty]
+	if v.beaconCfg.GetCurrentStateVersion(epoch) >= clparams.AltairVersion {
+		if err := v.beacon.post(ctx, fmt.Sprintf("/eth/v1/validator/duties/sync/%d", epoch), indices, &syncDuties); err != nil {
+			return err
+		}
+		if len(syncDuties.Data) > 0 {
+			untilEpoch := (epoch/v.beaconCfg.EpochsPerSyncCommitteePeriod + 1) * v.beaconCfg.EpochsPerSyncCommitteePeriod
+			syncSubscriptions := make([]building.SyncCommitteeSubscription, 0, len(syncDuties.Data))
+			for _, d := range syncDuties.Data {
+				subscription := building.SyncCommitteeSubscription{ValidatorIndex: int(d.ValidatorIndex), UntilEpoch: int(untilEpoch)}
+				for _, idx := range d.ValidatorSyncCommitteeIndices {
+					i, err := strconv.Atoi(idx)
+					if err != nil {
+						return err
+					}
+					subscription.SyncCommitteeIndices = append(subscription.SyncCommitteeIndices, beaconhttp.IntStr(i))
+				}
+				syncSubscriptions = append(syncSubscriptions, subscription)
+			}
+			if err := v.beacon.post(ctx, "/eth/v1/validator/sync_committee_subscriptions", syncSubscriptions, nil); err != nil {
+				return err
+			}
+		}
+	}
+	prepare := make([]building.PrepareBeaconProposer, 0, len(indices))
+	for _, val := range v.activeValidators() {
+		if feeRecipient := v.feeRecipient(val.pubkey); feeRecipient != (libcommon.Address{}) {
+			prepare = append(prepare, building.PrepareBeaconProposer{ValidatorIndex: int(val.index), FeeRecipient: feeRecipient})
+		}
+	}
+	if len(prepare) > 0 {
+		if err := v.beacon.post(ctx, "/eth/v1/validator/prepare_beacon_proposer", prepare, nil); err != nil {
+			return err
+		}
+	}
+	if v.cfg.Builder {
+		if err := v.registerValidators(ctx); err != nil {
+			return err
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	firstSlot := epoch * v.beaconCfg.SlotsPerEpoch
+	for slot := range v.proposerDuties {
+		if slot < firstSlot {
+			delete(v.proposerDuties, slot)
+		}
+	}
+	for slot := range v.attesterDuties {
+		if slot < firstSlot {
+			delete(v.attesterDuties, slot)
+		}
+	}
+	for _, d := range proposers.Data {
+		if _, ok := v.getValidator(d.Pubkey); ok {
+			v.proposerDuties[d.Slot] = d
+		}
+	}
+	if keysChanged {
+		// duties of deleted validators must be gone
+		for slot := range v.attesterDuties {
+			if slot/v.beaconCfg.SlotsPerEpoch >= fromEpoch {
+				delete(v.attesterDuties, slot)
+			}
+		}
+	}
+	for slot, duties := range attesterDuties {
+		v.attesterDuties[slot] = duties
+	}
+	v.syncDuties = syncDuties.Data
+	v.dutiesEpoch = epoch + 1
+	return nil
+}
+
+// registerValidators - sends builder registrations of active validators, they are re-signed only when fee recipient or gas limit change
+func (v *ValidatorClient) registerValidators(ctx context.Context) error {
+	// builder domain doesn't depend on fork and chain
+	domain, err := fork.ComputeDomain(v.beaconCfg.DomainApplicationBuilder[:], utils.Uint32ToBytes4(uint32(v.beaconCfg.GenesisForkVersion)), libcommon.Hash{})
+	if err != nil {
+		return err
+	}
+	var registrations []*cltypes.ValidatorRegistration
+	for _, val := range v.activeValidators() {
+		feeRecipient := v.feeRecipient(val.pubkey)
+		if feeRecipient == (libcommon.Address{}) {
+			continue
+		}
+		gasLimit := strconv.FormatUint(v.gasLimit(val.pubkey), 10)
+		v.mu.Lock()
+		registration, ok := v.registrations[val.pubkey]
+		v.mu.Unlock()
+		if !ok || registration.Message.FeeRecipient != feeRecipient || registration.Message.GasLimit != gasLimit {
+			registration = &cltypes.ValidatorRegistration{Message: cltypes.ValidatorRegistrationMessage{
+				FeeRecipient: feeRecipient,
+				GasLimit:     gasLimit,
+				Timestamp:    strconv.FormatInt(time.Now().Unix(), 10),
+				PubKey:       val.pubkey,
+			}}
+			signingRoot, err := fork.ComputeSigningRoot(&registration.Message, domain)
+			if err != nil {
+				return err
+			}
+			registration.Signature, err = val.signer.Sign(ctx, &SignRequest{
+				Type:        SignTypeValidatorRegistration,
+				SigningRoot: signingRoot,
+				Payload:     &registration.Message,
+			})
+			if err != nil {
+				return err
+			}
+			v.mu.Lock()
+			v.registrations[val.pubkey] = registration
+			v.mu.Unlock()
+		}
+		registrations = append(registrations, registration)
+	}
+	if len(registrations) == 0 {
+		return nil
+	}
+	return v.beacon.post(ctx, "/eth/v1/validator/register_validator", registrations, nil)
+}
+
+// isAggregator - is_aggregator of the spec
+func (v *ValidatorClient) isAggregator(committeeLength uint64, selectionProof libcommon.Bytes96) bool {
+	modulo := max(1, committeeLength/v.beaconCfg.TargetAggregatorsPerCommittee)
+	h := utils.Sha256(selectionProof[:])
+	return binary.LittleEndian.Uint64(h[:8])%modulo == 0
+}
+
+func (v *ValidatorClient) propose(ctx context.Context, slot uint64) {
+	v.mu.Lock()
+	duty, ok := v.proposerDuties[slot]
+	v.mu.Unlock()
+	if !ok {
+		return
+	}
+	val, ok := v.getValidator(duty.Pubkey)
+	if !ok {
+		return
+	}
+	if err := v.proposeBlock(ctx, val, slot); err != nil {
+		v.logger.Warn("[Validator] Failed to propose block", "slot", slot, "index", duty.ValidatorIndex, "err", err)
+		return
+	}
+	v.logger.Info("[Validator] Proposed block", "slot", slot, "index", duty.ValidatorIndex)
+}
+
+func (v *ValidatorClient) proposeBlock(ctx context.Context, val *validator, slot uint64) error {
+	epoch := slot / v.beaconCfg.SlotsPerEpoch
+	randaoReveal, err := v.signEpochOrSlot(ctx, val, v.beaconCfg.DomainRandao, epoch, epoch,
+		SignTypeRandaoReveal, map[string]string{"epoch": strconv.FormatUint(epoch, 10)})
+	if err != nil {
+		return err
+	}
+	query := url.Values{"randao_reveal": {"0x" + hex.EncodeToString(randaoReveal[:])}}
+	if graffiti := v.graffiti(val.pubkey); graffiti != "" {
+		query.Set("graffiti", hex.EncodeToString([]byte(graffiti)))
+	}
+	var resp apiResponse[json.RawMessage]
+	header, err := v.beacon.get(ctx, fmt.Sprintf("/eth/v3/validator/blocks/%d", slot), query, &resp)
+	if err != nil {
+		return err
+	}
+	version, err := clparams.StringToClVersion(header.Get("Eth-Consensus-Version"))
+	if err != nil {
+		return err
+	}
+	publishHeader := http.Header{"Eth-Consensus-Version": {clparams.ClVersionToString(version)}}
+
+	if resp.ExecutionPayloadBlinded {
+		block := cltypes.NewBlindedBeaconBlock(v.beaconCfg, version)
+		if err := json.Unmarshal(resp.Data, block); err != nil {
+			return err
+		}
+		beaconHeader, err := blockHeader(block.Slot, block.ProposerIndex, block.ParentRoot, block.StateRoot, block.Body)
+		if err != nil {
+			return err
+		}
+		signature, err := v.signBlock(ctx, val, beaconHeader, version)
+		if err != nil {
+			return err
+		}
+		_, err = v.beacon.do(ctx, http.MethodPost, "/eth/v2/beacon/blinded_blocks", nil, publishHeader,
+			&cltypes.SignedBlindedBeaconBlock{Block: block, Signature: signature}, nil)
+		return err
+	}
+	block := cltypes.NewDenebBeaconBlock(v.beaconCfg, version)
+	if err := json.Unmarshal(resp.Data, block); err != nil {
+		return err
+	}
+	beaconHeader, err := blockHeader(block.Block.Slot, block.Block.ProposerIndex, block.Block.ParentRoot, block.Block.StateRoot, block.Block.Body)
+	if err != nil {
+		return err
+	}
+	signature, err := v.signBlock(ctx, val, beaconHeader, version)
+	if err != nil {
+		return err
+	}
+	_, err = v.beacon.do(ctx, http.MethodPost, "/eth/v2/beacon/blocks", nil, publishHeader, &cltypes.DenebSignedBeaconBlock{
+		SignedBlock: &cltypes.SignedBeaconBlock{Block: block.Block, Signature: signature},
+		KZGProofs:   block.KZGProofs,
+		Blobs:       block.Blobs,
+	}, nil)
+	return err
+}
+
+func blockHeader(slot, proposerIndex uint64, parentRoot, stateRoot libcommon.Hash, body ssz.HashableSSZ) (*cltypes.BeaconBlockHeader, error) {
+	bodyRoot, err := body.HashSSZ()
+	if err != nil {
+		return nil, err
+	}
+	return &cltypes.BeaconBlockHeader{Slot: slot, ProposerIndex: proposerIndex, ParentRoot: parentRoot, Root: stateRoot, BodyRoot: bodyRoot}, nil
+}
+
+// signBlock - header has the same root as the block, it's what remote signer gets
+func (v *ValidatorClient) signBlock(ctx context.Context, val *validator, header *cltypes.BeaconBlockHeader, version clparams.StateVersion) (libcommon.Bytes96, error) {
+	objectRoot, err := header.HashSSZ()
+	if err != nil {
+		return libcommon.Bytes96{}, err
+	}
+	req, err := v.signRequest(objectRoot, v.beaconCfg.DomainBeaconProposer, version, SignTypeBlock,
+		map[string]any{"version": strings.ToUpper(clparams.ClVersionToString(version)), "block_header": header})
+	if err != nil {
+		return libcommon.Bytes96{}, err
+	}
+	// deletion by keymanager API waits for signing in progress, so exported history includes it
+	v.keysMu.RLock()
+	defer v.keysMu.RUnlock()
+	if v.byPubkey[val.pubkey] != val {
+		return libcommon.Bytes96{}, errValidatorDeleted
+	}
+	// slashing protection goes first: the signer must not be asked for what we would refuse
+	if err := v.protection.CheckAndInsertBlock(ctx, val.pubkey, header.Slot, req.SigningRoot); err != nil {
+		return libcommon.Bytes96{}, err
+	}
+	return val.signer.Sign(ctx, req)
+}
+
+// attest - attests at 1/3 of the slot and aggregates at 2/3 (as the spec recommends)
+func (v *ValidatorClient) attest(ctx context.Context, slot uint64) {
+	v.mu.Lock()
+	duties := v.attesterDuties[slot]
+	v.mu.Unlock()
+	if len(duties) == 0 {
+		return
+	}
+	slotTime := v.ethClock.GetSlotTime(slot)
+	slotDuration := time.Duration(v.beaconCfg.SecondsPerSlot) * time.Second
+	if err := sleepUntil(ctx, slotTime.Add(slotDuration/3)); err != nil {
+		return
+	}
+
+	epoch := slot / v.beaconCfg.SlotsPerEpoch
+	version := v.beaconCfg.GetCurrentStateVersion(epoch)
+	electra := version.AfterOrEqual(clparams.ElectraVersion)
+	dataByCommittee := map[uint64]*solid.AttestationData{}
+	attestations := make([]*solid.Attestation, 0, len(duties))
+	for _, d := range duties {
+		data, ok := dataByCommittee[d.CommitteeIndex]
+		if !ok {
+			var resp apiResponse[*solid.AttestationData]
+			query := url.Values{"slot": {strconv.FormatUint(slot, 10)}, "committee_index": {strconv.FormatUint(d.CommitteeIndex, 10)}}
+			if _, err := v.beacon.get(ctx, "/eth/v1/validator/attestation_data", query, &resp); err != nil {
+				v.logger.Warn("[Validator] Failed to get attestation data", "slot", slot, "committee", d.CommitteeIndex, "err", err)
+				continue
+			}
+			data = resp.Data
+			dataByCommittee[d.CommitteeIndex] = data
+		}
+		att, err := v.signAttestation(ctx, d, data, electra)
+		if err != nil {
+			v.logger.Warn("[Validator] Failed to sign attestation", "slot", slot, "index", d.ValidatorIndex, "err", err)
+			continue
+		}
+		attestations = append(attestations, att)
+	}
+	if len(attestations) > 0 {
+		if err := v.publish(ctx, "/beacon/pool/attestations", version, attestations); err != nil {
+			v.logger.Warn("[Validator] Failed to publish attestations", "slot", slot, "err", err)
+		} else {
+			v.logger.Debug("[Validator] Published attestations", "slot", slot, "count", len(attestations))
+		}
+	}
+
+	if err := sleepUntil(ctx, slotTime.Add(2*slotDuration/3)); err != nil {
+		return
+	}
+	var aggregates []*cltypes.SignedAggregateAndProof
+	for _, d := range duties {
+		data, ok := dataByCommittee[d.CommitteeIndex]
+		if !d.isAggregator || !ok {
+			continue
+		}
+		aggregate, err := v.aggregate(ctx, d, data, electra)
+		if err != nil {
+			v.logger.Warn("[Validator] Failed to aggregate", "slot", slot, "index", d.ValidatorIndex, "err", err)
+			continue
+		}
+		aggregates = append(aggregates, aggregate)
+	}
+	if len(aggregates) > 0 {
+		if err := v.publish(ctx, "/validator/aggregate_and_proofs", version, aggregates); err != nil {
+			v.logger.Warn("[Validator] Failed to publish aggregates", "slot", slot, "err", err)
+		}
+	}
+}
+
+// publish - posts attestations or aggregates to pool endpoint `path`: Electra ones have committee bits,
+// only v2 endpoint takes them
+func (v *ValidatorClient) publish(ctx context.Context, path string, version clparams.StateVersion, body any) error {
+	if version.Before(clparams.ElectraVersion) {
+		return v.beacon.post(ctx, "/eth/v1"+path, body, nil)
+	}
+	header := http.Header{"Eth-Consensus-Version": {clparams.ClVersionToString(version)}}
+	_, err := v.beacon.do(ctx, http.MethodPost, "/eth/v2"+path, nil, header, body, nil)
+	return err
+}
+
+func (v *ValidatorClient) signAttestation(ctx context.Context, d *attesterDuty, data *solid.AttestationData, electra bool) (*solid.Attestation, error) {
+	val, ok := v.getValidator(d.Pubkey)
+	if !ok {
+		return nil, errValidatorDeleted
+	}
+	objectRoot, err := data.HashSSZ()
+	if err != nil {
+		return nil, err
+	}
+	req, err := v.signRequest(objectRoot, v.beaconCfg.DomainBeaconAttester, v.beaconCfg.GetCurrentStateVersion(data.Target.Epoch), SignTypeAttestation, data)
+	if err != nil {
+		return nil, err
+	}
+	v.keysMu.RLock()
+	if v.byPubkey[val.pubkey] != val {
+		v.keysMu.RUnlock()
+		return nil, errValidatorDeleted
+	}
+	err = v.protection.CheckAndInsertAttestation(ctx, val.pubkey, data.Source.Epoch, data.Target.Epoch, req.SigningRoot)
+	var signature libcommon.Bytes96
+	if err == nil {
+		signature, err = val.signer.Sign(ctx, req)
+	}
+	v.keysMu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// bitlist of committee length with our bit set, plus the length delimiter bit
+	bitsCap := int(v.beaconCfg.MaxValidatorsPerCommittee)
+	if electra {
+		bitsCap *= int(v.beaconCfg.MaxCommitteesPerSlot)
+	}
+	bits := make([]byte, d.CommitteeLength/8+1)
+	bits[d.ValidatorCommitteeIndex/8] |= 1 << (d.ValidatorCommitteeIndex % 8)
+	bits[d.CommitteeLength/8] |= 1 << (d.CommitteeLength % 8)
+	att := &solid.Attestation{
+		AggregationBits: solid.BitlistFromBytes(bits, bitsCap),
+		Data:            data,
+		Signature:       signature,
+	}
+	if electra {
+		att.CommitteeBits = solid.NewBitVector(int(v.beaconCfg.MaxCommitteesPerSlot))
+		if err := att.CommitteeBits.SetBitAt(int(d.CommitteeIndex), true); err != nil {
+			return nil, err
+		}
+	}
+	return att, nil
+}
+
+func (v *ValidatorClient) aggregate(ctx context.Context, d *attesterDuty, data *solid.AttestationData, electra bool) (*cltypes.SignedAggregateAndProof, error) {
+	dataRoot, err := data.HashSSZ()
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{
+		"attestation_data_root": {libcommon.Hash(dataRoot).Hex()},
+		"slot":                  {strconv.FormatUint(d.Slot, 10)},
+		"committee_index":       {strconv.FormatUint(d.CommitteeIndex, 10)},
+	}
+	// data of Electra attestations is the same for all committees of the slot: v2 finds aggregate by committee
+	path := "/eth/v1/validator/aggregate_attestation"
+	if electra {
+		path = "/eth/v2/validator/aggregate_attestation"
+	}
+	var resp apiResponse[*solid.Attestation]
+	if _, err := v.beacon.get(ctx, path, query, &resp); err != nil {
+		return nil, err
+	}
+	aggregateAndProof := &cltypes.AggregateAndProof{
+		AggregatorIndex: d.ValidatorIndex,
+		Aggregate:       resp.Data,
+		SelectionProof:  d.selectionProof,
+	}
+	val, ok := v.getValidator(d.Pubkey)
+	if !ok {
+		return nil, errValidatorDeleted
+	}
+	epoch := d.Slot / v.beaconCfg.SlotsPerEpoch
+	signType, payload := SignTypeAggregateAndProof, any(aggregateAndProof)
+	if version := v.beaconCfg.GetCurrentStateVersion(epoch); version >= clparams.ElectraVersion {
+		signType, payload = SignTypeAggregateAndProofV2, map[string]any{"version": strings.ToUpper(clparams.ClVersionToString(version)), "data": aggregateAndProof}
+	}
+	signature, err := v.sign(ctx, val, aggregateAndProof, v.beaconCfg.DomainAggregateAndProof, epoch, signType, payload)
+	if err != nil {
+		return nil, err
+	}
+	return &cltypes.SignedAggregateAndProof{Message: aggregateAndProof, Signature: signature}, nil
+}
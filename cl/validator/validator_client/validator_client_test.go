@@ -0,0 +1,167 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package validator_client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/cltypes/solid"
+	"github.com/erigontech/erigon/cl/fork"
+	"github.com/erigontech/erigon/cl/utils"
+)
+
+// dutiesRecorder - beacon api which serves data for duties and keeps what is published, by path
+type dutiesRecorder struct {
+	mu        sync.Mutex
+	data      *solid.AttestationData
+	root      libcommon.Hash
+	published map[string][]byte
+	versions  map[string]string
+}
+
+func newDutiesRecorder(data *solid.AttestationData) *dutiesRecorder {
+	return &dutiesRecorder{data: data, root: libcommon.Hash{9}, published: map[string][]byte{}, versions: map[string]string{}}
+}
+
+func (b *dutiesRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if r.Method == http.MethodPost {
+		var body json.RawMessage
+		json.NewDecoder(r.Body).Decode(&body)
+		b.published[r.URL.Path] = body
+		b.versions[r.URL.Path] = r.Header.Get("Eth-Consensus-Version")
+		return
+	}
+	var data any
+	switch r.URL.Path {
+	case "/eth/v1/validator/attestation_data":
+		data = b.data
+	case "/eth/v2/validator/aggregate_attestation":
+		data = &solid.Attestation{AggregationBits: solid.NewBitList(1, 2048), Data: b.data, CommitteeBits: solid.NewBitVector(64)}
+	case "/eth/v1/beacon/blocks/head/root":
+		data = map[string]libcommon.Hash{"root": b.root}
+	case "/eth/v1/validator/sync_committee_contribution":
+		bits := make([]byte, cltypes.SyncCommitteeAggregationBitsSize)
+		bits[0] = 1
+		data = &cltypes.Contribution{Slot: b.data.Slot, BeaconBlockRoot: b.root, SubcommitteeIndex: 1, AggregationBits: bits}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"data": data})
+}
+
+func (b *dutiesRecorder) publishedTo(path string) ([]byte, string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.published[path], b.versions[path]
+}
+
+func TestElectraAttestationsUseV2Endpoints(t *testing.T) {
+	key, err := bls.GenerateKey()
+	require.NoError(t, err)
+	v, _ := newTestSigningClient(t, localValidator(key, "", true))
+	epoch := v.beaconCfg.DenebForkEpoch + 1
+	slot := epoch * v.beaconCfg.SlotsPerEpoch
+
+	data := &solid.AttestationData{
+		Slot:            slot,
+		BeaconBlockRoot: libcommon.Hash{3},
+		Source:          solid.Checkpoint{Epoch: epoch - 1, Root: libcommon.Hash{4}},
+		Target:          solid.Checkpoint{Epoch: epoch, Root: libcommon.Hash{5}},
+	}
+	beacon := newDutiesRecorder(data)
+	v.beacon = &beaconClient{handler: beacon}
+	duty := &attesterDuty{Pubkey: v.validators[0].pubkey, ValidatorIndex: 7, CommitteeIndex: 2, CommitteeLength: 4, ValidatorCommitteeIndex: 1, Slot: slot, isAggregator: true}
+
+	ctx := context.Background()
+	attestation, err := v.signAttestation(ctx, duty, data, true)
+	require.NoError(t, err)
+	require.NoError(t, v.publish(ctx, "/beacon/pool/attestations", clparams.ElectraVersion, []*solid.Attestation{attestation}))
+	aggregate, err := v.aggregate(ctx, duty, data, true)
+	require.NoError(t, err)
+	require.NoError(t, v.publish(ctx, "/validator/aggregate_and_proofs", clparams.ElectraVersion, []*cltypes.SignedAggregateAndProof{aggregate}))
+	for _, path := range []string{"/eth/v2/beacon/pool/attestations", "/eth/v2/validator/aggregate_and_proofs"} {
+		body, version := beacon.publishedTo(path)
+		require.NotEmpty(t, body, path)
+		require.Equal(t, "electra", version, path)
+		require.Contains(t, string(body), `"committee_bits"`, path)
+	}
+
+	// before Electra: v1 endpoints, without committee bits
+	attestation, err = v.signAttestation(ctx, &attesterDuty{Pubkey: duty.Pubkey, ValidatorIndex: 7, CommitteeLength: 4, Slot: slot + 1},
+		&solid.AttestationData{Slot: slot + 1, Source: data.Source, Target: solid.Checkpoint{Epoch: epoch + 1}}, false)
+	require.NoError(t, err)
+	require.NoError(t, v.publish(ctx, "/beacon/pool/attestations", clparams.DenebVersion, []*solid.Attestation{attestation}))
+	body, _ := beacon.publishedTo("/eth/v1/beacon/pool/attestations")
+	require.NotEmpty(t, body)
+	require.NotContains(t, string(body), `"committee_bits"`)
+}
+
+func TestSyncCommitteeContribution(t *testing.T) {
+	key, err := bls.GenerateKey()
+	require.NoError(t, err)
+	v, _ := newTestSigningClient(t, localValidator(key, "", true))
+	// every member is an aggregator
+	v.beaconCfg.TargetAggregatorsPerSyncSubcommittee = v.beaconCfg.SyncCommitteeSize
+	epoch := v.beaconCfg.DenebForkEpoch + 1
+	slot := epoch * v.beaconCfg.SlotsPerEpoch
+
+	beacon := newDutiesRecorder(&solid.AttestationData{Slot: slot})
+	v.beacon = &beaconClient{handler: beacon}
+	pubkey := v.validators[0].pubkey
+	// positions 130 and 140 are both in subcommittee 1: one contribution
+	v.syncDuties = []*syncDuty{{Pubkey: pubkey, ValidatorIndex: 7, ValidatorSyncCommitteeIndices: []string{"130", "140"}}}
+
+	v.syncCommitteeMessages(context.Background(), slot)
+	body, _ := beacon.publishedTo("/eth/v1/beacon/pool/sync_committees")
+	require.NotEmpty(t, body)
+	body, _ = beacon.publishedTo("/eth/v1/validator/contribution_and_proofs")
+	var contributions []*cltypes.SignedContributionAndProof
+	require.NoError(t, json.Unmarshal(body, &contributions))
+	require.Len(t, contributions, 1)
+	contribution := contributions[0]
+	require.Equal(t, uint64(7), contribution.Message.AggregatorIndex)
+	require.Equal(t, beacon.root, contribution.Message.Contribution.BeaconBlockRoot)
+
+	forkVersion := utils.Uint32ToBytes4(v.beaconCfg.GetForkVersionByVersion(clparams.DenebVersion))
+	verify := func(obj interface{ HashSSZ() ([32]byte, error) }, domainType libcommon.Bytes4, signature libcommon.Bytes96) {
+		t.Helper()
+		domain, err := fork.ComputeDomain(domainType[:], forkVersion, v.ethClock.GenesisValidatorsRoot())
+		require.NoError(t, err)
+		signingRoot, err := fork.ComputeSigningRoot(obj, domain)
+		require.NoError(t, err)
+		ok, err := bls.Verify(signature[:], signingRoot[:], pubkey[:])
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	verify(&cltypes.SyncAggregatorSelectionData{Slot: slot, SubcommitteeIndex: 1}, v.beaconCfg.DomainSyncCommitteeSelectionProof, contribution.Message.SelectionProof)
+	verify(contribution.Message, v.beaconCfg.DomainContributionAndProof, contribution.Signature)
+}
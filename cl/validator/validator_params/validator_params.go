@@ -24,6 +24,7 @@ import (
 
 type ValidatorParams struct {
 	feeRecipients sync.Map
+	gasLimits     sync.Map
 }
 
 func NewValidatorParams() *ValidatorParams {
@@ -41,3 +42,16 @@ func (vp *ValidatorParams) GetFeeRecipient(validatorIndex uint64) (libcommon.Add
 	}
 	return val.(libcommon.Address), true
 }
+
+func (vp *ValidatorParams) SetGasLimit(validatorIndex uint64, gasLimit uint64) {
+	vp.gasLimits.Store(validatorIndex, gasLimit)
+}
+
+// GetGasLimit - gas limit the validator registered with the builder network
+func (vp *ValidatorParams) GetGasLimit(validatorIndex uint64) (uint64, bool) {
+	val, ok := vp.gasLimits.Load(validatorIndex)
+	if !ok {
+		return 0, false
+	}
+	return val.(uint64), true
+}
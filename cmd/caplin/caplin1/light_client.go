@@ -0,0 +1,89 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package caplin1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/credentials"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/log/v3"
+
+	"github.com/erigontech/erigon/cl/clparams"
+	"github.com/erigontech/erigon/cl/cltypes"
+	"github.com/erigontech/erigon/cl/light_client"
+	"github.com/erigontech/erigon/cl/phase1/core/state"
+	"github.com/erigontech/erigon/cl/phase1/execution_client"
+	"github.com/erigontech/erigon/cl/rpc"
+	"github.com/erigontech/erigon/cl/sentinel"
+	"github.com/erigontech/erigon/cl/sentinel/service"
+	"github.com/erigontech/erigon/cl/utils/eth_clock"
+)
+
+// runLightClient runs Caplin in light client sync mode: no beacon state, blocks or databases are kept,
+// the chain is followed through light client updates and the execution engine is driven from them.
+func runLightClient(ctx context.Context, engine execution_client.ExecutionEngine, config clparams.CaplinConfig, dirs datadir.Dirs,
+	networkConfig *clparams.NetworkConfig, beaconConfig *clparams.BeaconChainConfig, genesisState *state.CachingBeaconState,
+	creds credentials.TransportCredentials) error {
+	if config.LightClientTrustedRoot == (libcommon.Hash{}) {
+		return errors.New("light client mode requires a trusted block root (--caplin.light-client.trusted-root)")
+	}
+	if config.ValidatorClientEnabled() {
+		return errors.New("validator client is not supported in light client mode")
+	}
+	if config.BeaconAPIRouter.Active {
+		log.Warn("[Light Client] Beacon API is not served in light client mode")
+	}
+	logger := log.New("app", "caplin")
+	logger.Info("Starting caplin in light client mode", "trustedRoot", config.LightClientTrustedRoot)
+
+	ctx, cn := context.WithCancel(ctx)
+	defer cn()
+
+	ethClock := eth_clock.NewEthereumClock(genesisState.GenesisTime(), genesisState.GenesisValidatorsRoot(), beaconConfig)
+	forkDigest, err := ethClock.CurrentForkDigest()
+	if err != nil {
+		return err
+	}
+	sentinelClient, _, err := service.StartSentinelService(&sentinel.SentinelConfig{
+		IpAddr:        config.CaplinDiscoveryAddr,
+		Port:          int(config.CaplinDiscoveryPort),
+		TCPPort:       uint(config.CaplinDiscoveryTCPPort),
+		NetworkConfig: networkConfig,
+		BeaconConfig:  beaconConfig,
+		TmpDir:        dirs.Tmp,
+		LightClient:   true,
+		MaxPeerCount:  config.MaxPeerCount,
+	}, nil, nil, nil, &service.ServerConfig{
+		Network: "tcp",
+		Addr:    fmt.Sprintf("%s:%d", config.SentinelAddr, config.SentinelPort),
+		Creds:   creds,
+		InitialStatus: &cltypes.Status{
+			ForkDigest: forkDigest,
+		},
+	}, ethClock, nil, logger)
+	if err != nil {
+		return err
+	}
+	beaconRpc := rpc.NewBeaconRpcP2P(ctx, sentinelClient, beaconConfig, ethClock)
+
+	return light_client.NewLightClient(beaconConfig, ethClock, sentinelClient, beaconRpc, engine, config.LightClientTrustedRoot, logger).Start(ctx)
+}
@@ -48,6 +48,7 @@ import (
 	"github.com/erigontech/erigon/cl/validator/attestation_producer"
 	"github.com/erigontech/erigon/cl/validator/committee_subscription"
 	"github.com/erigontech/erigon/cl/validator/sync_contribution_pool"
+	"github.com/erigontech/erigon/cl/validator/validator_client"
 	"github.com/erigontech/erigon/cl/validator/validator_params"
 	"github.com/erigontech/erigon/eth/ethconfig"
 	"github.com/erigontech/erigon/params"
@@ -199,6 +200,10 @@ func RunCaplinService(ctx context.Context, engine execution_client.ExecutionEngi
 		}
 	}
 
+	if config.LightClient {
+		return runLightClient(ctx, engine, config, dirs, networkConfig, beaconConfig, genesisState, creds)
+	}
+
 	state, err := checkpoint_sync.ReadOrFetchLatestBeaconState(ctx, dirs, beaconConfig, config, genesisDb)
 	if err != nil {
 		return err
@@ -224,6 +229,9 @@ func RunCaplinService(ctx context.Context, engine execution_client.ExecutionEngi
 			config.BeaconAPIRouter.Builder = false
 		}
 	}
+	if config.ValidatorClientEnabled() && !(config.BeaconAPIRouter.Active && config.BeaconAPIRouter.Beacon && config.BeaconAPIRouter.Validator) {
+		return errors.New("validator client performs duties through beacon api: enable it with beacon and validator endpoints (--beacon.api=beacon,validator)")
+	}
 	log.Info("Starting caplin")
 
 	if eth1Getter != nil {
@@ -258,7 +266,7 @@ func RunCaplinService(ctx context.Context, engine execution_client.ExecutionEngi
 	syncContributionPool := sync_contribution_pool.NewSyncContributionPool(beaconConfig)
 	emitters := beaconevents.NewEventEmitter()
 	aggregationPool := aggregation.NewAggregationPool(ctx, beaconConfig, networkConfig, ethClock)
-	validatorMonitor := monitor.NewValidatorMonitor(config.EnableValidatorMonitor, ethClock, beaconConfig, syncedDataManager)
+	validatorMonitor := monitor.NewValidatorMonitor(config.EnableValidatorMonitor, config.ValidatorMonitorIndicies, ethClock, beaconConfig, syncedDataManager)
 	forkChoice, err := forkchoice.NewForkChoiceStore(
 		ethClock, state, engine, pool, fork_graph.NewForkGraphDisk(state, fcuFs, config.BeaconAPIRouter, emitters),
 		emitters, syncedDataManager, blobStorage, validatorMonitor)
@@ -266,6 +274,7 @@ func RunCaplinService(ctx context.Context, engine execution_client.ExecutionEngi
 		logger.Error("Could not create forkchoice", "err", err)
 		return err
 	}
+	validatorMonitor.SetForkChoiceReader(forkChoice)
 	bls.SetEnabledCaching(true)
 
 	forkDigest, err := ethClock.CurrentForkDigest()
@@ -274,7 +283,7 @@ func RunCaplinService(ctx context.Context, engine execution_client.ExecutionEngi
 	}
 	activeIndicies := state.GetActiveValidatorsIndices(state.Slot() / beaconConfig.SlotsPerEpoch)
 
-	sentinel, err := service.StartSentinelService(&sentinel.SentinelConfig{
+	sentinel, sentinelPeers, err := service.StartSentinelService(&sentinel.SentinelConfig{
 		IpAddr:             config.CaplinDiscoveryAddr,
 		Port:               int(config.CaplinDiscoveryPort),
 		TCPPort:            uint(config.CaplinDiscoveryTCPPort),
@@ -306,6 +315,7 @@ func RunCaplinService(ctx context.Context, engine execution_client.ExecutionEngi
 	// Define gossip services
 	blockService := services.NewBlockService(ctx, indexDB, forkChoice, syncedDataManager, ethClock, beaconConfig, emitters)
 	blobService := services.NewBlobSidecarService(ctx, beaconConfig, forkChoice, syncedDataManager, ethClock, emitters, false)
+	dataColumnSidecarService := services.NewDataColumnSidecarService(beaconConfig, forkChoice, syncedDataManager, ethClock, blobStorage)
 	syncCommitteeMessagesService := services.NewSyncCommitteeMessagesService(beaconConfig, ethClock, syncedDataManager, syncContributionPool, false)
 	attestationService := services.NewAttestationService(ctx, forkChoice, committeeSub, ethClock, syncedDataManager, beaconConfig, networkConfig, emitters, batchSignatureVerifier)
 	syncContributionService := services.NewSyncContributionService(syncedDataManager, beaconConfig, syncContributionPool, ethClock, emitters, false)
@@ -320,7 +330,7 @@ func RunCaplinService(ctx context.Context, engine execution_client.ExecutionEngi
 
 	// Create the gossip manager
 	gossipManager := network.NewGossipReceiver(sentinel, forkChoice, beaconConfig, networkConfig, ethClock, emitters, committeeSub,
-		blockService, blobService, syncCommitteeMessagesService, syncContributionService, aggregateAndProofService,
+		blockService, blobService, dataColumnSidecarService, syncCommitteeMessagesService, syncContributionService, aggregateAndProofService,
 		attestationService, voluntaryExitService, blsToExecutionChangeService, proposerSlashingService)
 	{ // start ticking forkChoice
 		go func() {
@@ -407,11 +417,13 @@ func RunCaplinService(ctx context.Context, engine execution_client.ExecutionEngi
 			syncedDataManager,
 			statesReader,
 			sentinel,
+			sentinelPeers,
 			params.GitTag,
 			&config.BeaconAPIRouter,
 			emitters,
 			blobStorage,
 			csn,
+			dirs,
 			validatorParameters,
 			attestationProducer,
 			engine,
@@ -432,6 +444,42 @@ func RunCaplinService(ctx context.Context, engine execution_client.ExecutionEngi
 			ArchiveApi: apiHandler,
 		}, config.BeaconAPIRouter)
 		log.Info("Beacon API started", "addr", config.BeaconAPIRouter.Address)
+
+		if config.ValidatorClientEnabled() {
+			validatorDir := path.Join(dirs.DataDir, "caplin", "validator")
+			vc, err := validator_client.NewValidatorClient(ctx, validator_client.Config{
+				DataDir:                  validatorDir,
+				KeystoreDir:              config.ValidatorKeystoreDir,
+				PasswordFile:             config.ValidatorPasswordFile,
+				FeeRecipient:             config.ValidatorFeeRecipient,
+				Graffiti:                 config.ValidatorGraffiti,
+				DoppelgangerEpochs:       config.ValidatorDoppelgangerEpochs,
+				Builder:                  config.BeaconAPIRouter.Builder,
+				RemoteSignerURL:          config.ValidatorRemoteSignerURL,
+				SlashingProtectionImport: config.ValidatorSlashingProtectionImport,
+				SlashingProtectionExport: config.ValidatorSlashingProtectionExport,
+			}, beaconConfig, ethClock, apiHandler, logger)
+			if err != nil {
+				return fmt.Errorf("could not start validator client: %w", err)
+			}
+			if config.KeymanagerAPI.Active {
+				tokenFile := config.KeymanagerAPI.TokenFile
+				if tokenFile == "" {
+					tokenFile = path.Join(validatorDir, "api-token.txt")
+				}
+				token, err := validator_client.LoadOrCreateKeymanagerToken(tokenFile)
+				if err != nil {
+					return fmt.Errorf("could not read keymanager api token: %w", err)
+				}
+				go beacon.ListenAndServeKeymanager(vc.KeymanagerHandler(), config.KeymanagerAPI, token)
+				log.Info("Keymanager API started", "addr", config.KeymanagerAPI.Address, "token_file", tokenFile)
+			}
+			go func() {
+				if err := vc.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+					logger.Error("[Validator] Validator client stopped", "err", err)
+				}
+			}()
+		}
 	}
 
 	stageCfg := stages.ClStagesCfg(
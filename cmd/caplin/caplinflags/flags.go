@@ -42,6 +42,22 @@ var CliFlags = []cli.Flag{
 	&utils.BeaconApiAllowOriginsFlag,
 	&utils.CaplinCheckpointSyncUrlFlag,
 	&utils.CaplinMaxPeerCount,
+	&utils.CaplinValidatorKeystoreDirFlag,
+	&utils.CaplinValidatorPasswordFileFlag,
+	&utils.CaplinValidatorFeeRecipientFlag,
+	&utils.CaplinValidatorGraffitiFlag,
+	&utils.CaplinValidatorRemoteSignerURLFlag,
+	&utils.CaplinValidatorDoppelgangerEpochsFlag,
+	&utils.CaplinValidatorSlashingProtectionImportFlag,
+	&utils.CaplinValidatorSlashingProtectionExportFlag,
+	&utils.CaplinKeymanagerFlag,
+	&utils.CaplinKeymanagerAddrFlag,
+	&utils.CaplinKeymanagerPortFlag,
+	&utils.CaplinKeymanagerTokenFileFlag,
+	&utils.CaplinValidatorMonitorFlag,
+	&utils.CaplinValidatorMonitorIndiciesFlag,
+	&utils.CaplinLightClientFlag,
+	&utils.CaplinLightClientTrustedRootFlag,
 }
 
 var (
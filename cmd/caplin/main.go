@@ -108,7 +108,7 @@ func runCaplinNode(cliCtx *cli.Context) error {
 
 	blockSnapBuildSema := semaphore.NewWeighted(int64(dbg.BuildSnapshotAllowance))
 
-	return caplin1.RunCaplinService(ctx, executionEngine, clparams.CaplinConfig{
+	caplinConfig := clparams.CaplinConfig{
 		CaplinDiscoveryAddr:    cfg.Addr,
 		CaplinDiscoveryPort:    uint64(cfg.Port),
 		CaplinDiscoveryTCPPort: uint64(cfg.ServerTcpPort),
@@ -118,5 +118,9 @@ func runCaplinNode(cliCtx *cli.Context) error {
 		CustomConfigPath:       cfg.CustomConfig,
 		CustomGenesisStatePath: cfg.CustomGenesisState,
 		MaxPeerCount:           cfg.MaxPeerCount,
-	}, cfg.Dirs, nil, nil, nil, blockSnapBuildSema)
+	}
+	utils.SetCaplinValidatorClient(cliCtx, &caplinConfig)
+	utils.SetCaplinValidatorMonitor(cliCtx, &caplinConfig)
+	utils.SetCaplinLightClient(cliCtx, &caplinConfig)
+	return caplin1.RunCaplinService(ctx, executionEngine, caplinConfig, cfg.Dirs, nil, nil, nil, blockSnapBuildSema)
 }
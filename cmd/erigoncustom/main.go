@@ -22,6 +22,7 @@ import (
 
 	"github.com/urfave/cli/v2"
 
+	"github.com/erigontech/erigon/core/vm"
 	erigonapp "github.com/erigontech/erigon/turbo/app"
 	erigoncli "github.com/erigontech/erigon/turbo/cli"
 )
@@ -37,6 +38,18 @@ const (
 	customBucketName = "ch.torquem.demo.tgcustom.CUSTOM_BUCKET" //nolint
 )
 
+// defining a custom precompile, which returns its input
+type echoPrecompile struct{}
+
+func (echoPrecompile) RequiredGas(input []byte) uint64  { return 15 + 3*uint64((len(input)+31)/32) }
+func (echoPrecompile) Run(input []byte) ([]byte, error) { return input, nil }
+
+// registering the custom precompile, for the chain config of a custom chain
+// to run it, e.g.: "precompiles": [{"name": "echo", "address": "0x0000000000000000000000000000000000000100", "time": 1700000000}]
+func init() {
+	vm.RegisterPrecompile("echo", echoPrecompile{})
+}
+
 // the regular main function
 func main() {
 	// initializing Erigon application here and providing our custom flag
@@ -0,0 +1,485 @@
+// Copyright 2021 The go-ethereum Authors
+// (original work)
+// Copyright 2024 The Erigon Authors
+// (modifications)
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package t8ntool
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutility"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/common/math"
+	"github.com/erigontech/erigon/consensus/clique"
+	"github.com/erigontech/erigon/consensus/ethash"
+	"github.com/erigontech/erigon/consensus/ethash/ethashcfg"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/crypto"
+	"github.com/erigontech/erigon/rlp"
+)
+
+//go:generate gencodec -type header -field-override headerMarshaling -out gen_header.go
+type header struct {
+	ParentHash            libcommon.Hash     `json:"parentHash"`
+	OmmerHash             *libcommon.Hash    `json:"sha3Uncles"`
+	Coinbase              *libcommon.Address `json:"miner"`
+	Root                  libcommon.Hash     `json:"stateRoot"        gencodec:"required"`
+	TxHash                *libcommon.Hash    `json:"transactionsRoot"`
+	ReceiptHash           *libcommon.Hash    `json:"receiptsRoot"`
+	Bloom                 types.Bloom        `json:"logsBloom"`
+	Difficulty            *big.Int           `json:"difficulty"`
+	Number                *big.Int           `json:"number"           gencodec:"required"`
+	GasLimit              uint64             `json:"gasLimit"         gencodec:"required"`
+	GasUsed               uint64             `json:"gasUsed"`
+	Time                  uint64             `json:"timestamp"        gencodec:"required"`
+	Extra                 []byte             `json:"extraData"`
+	MixDigest             libcommon.Hash     `json:"mixHash"`
+	Nonce                 *types.BlockNonce  `json:"nonce"`
+	BaseFee               *big.Int           `json:"baseFeePerGas"`
+	WithdrawalsHash       *libcommon.Hash    `json:"withdrawalsRoot"`
+	BlobGasUsed           *uint64            `json:"blobGasUsed"`
+	ExcessBlobGas         *uint64            `json:"excessBlobGas"`
+	ParentBeaconBlockRoot *libcommon.Hash    `json:"parentBeaconBlockRoot"`
+	RequestsHash          *libcommon.Hash    `json:"requestsHash"`
+}
+
+type headerMarshaling struct {
+	Difficulty    *math.HexOrDecimal256
+	Number        *math.HexOrDecimal256
+	GasLimit      math.HexOrDecimal64
+	GasUsed       math.HexOrDecimal64
+	Time          math.HexOrDecimal64
+	Extra         hexutility.Bytes
+	BaseFee       *math.HexOrDecimal256
+	BlobGasUsed   *math.HexOrDecimal64
+	ExcessBlobGas *math.HexOrDecimal64
+}
+
+type bbInput struct {
+	Header      *header             `json:"header,omitempty"`
+	OmmersRlp   []string            `json:"ommers,omitempty"`
+	TxRlp       string              `json:"txs,omitempty"`
+	Withdrawals []*types.Withdrawal `json:"withdrawals,omitempty"`
+	// Requests are the EIP-7685 requests, each one being the request type followed by the request data.
+	Requests []hexutility.Bytes `json:"requests,omitempty"`
+	Clique   *cliqueInput       `json:"clique,omitempty"`
+
+	Ethash    bool                `json:"-"`
+	EthashDir string              `json:"-"`
+	PowMode   ethashcfg.Mode      `json:"-"`
+	Txs       []types.Transaction `json:"-"`
+	Ommers    []*types.Header     `json:"-"`
+}
+
+type cliqueInput struct {
+	Key       *ecdsa.PrivateKey
+	Voted     *libcommon.Address
+	Authorize *bool
+	Vanity    libcommon.Hash
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (c *cliqueInput) UnmarshalJSON(input []byte) error {
+	var x struct {
+		Key       *libcommon.Hash    `json:"secretKey"`
+		Voted     *libcommon.Address `json:"voted"`
+		Authorize *bool              `json:"authorize"`
+		Vanity    libcommon.Hash     `json:"vanity"`
+	}
+	if err := json.Unmarshal(input, &x); err != nil {
+		return err
+	}
+	if x.Key == nil {
+		return errors.New("missing required field 'secretKey' for cliqueInput")
+	}
+	if ecdsaKey, err := crypto.ToECDSA(x.Key[:]); err != nil {
+		return err
+	} else {
+		c.Key = ecdsaKey
+	}
+	c.Voted = x.Voted
+	c.Authorize = x.Authorize
+	c.Vanity = x.Vanity
+	return nil
+}
+
+// ToBlock converts i into a *types.Block. The roots of the header which are not provided are derived
+// from the block contents, so that invalid blocks can still be built by providing them.
+func (i *bbInput) ToBlock() (*types.Block, error) {
+	header := &types.Header{
+		ParentHash:            i.Header.ParentHash,
+		UncleHash:             types.EmptyUncleHash,
+		Coinbase:              libcommon.Address{},
+		Root:                  i.Header.Root,
+		TxHash:                types.EmptyRootHash,
+		ReceiptHash:           types.EmptyRootHash,
+		Bloom:                 i.Header.Bloom,
+		Difficulty:            new(big.Int),
+		Number:                i.Header.Number,
+		GasLimit:              i.Header.GasLimit,
+		GasUsed:               i.Header.GasUsed,
+		Time:                  i.Header.Time,
+		Extra:                 i.Header.Extra,
+		MixDigest:             i.Header.MixDigest,
+		BaseFee:               i.Header.BaseFee,
+		WithdrawalsHash:       i.Header.WithdrawalsHash,
+		BlobGasUsed:           i.Header.BlobGasUsed,
+		ExcessBlobGas:         i.Header.ExcessBlobGas,
+		ParentBeaconBlockRoot: i.Header.ParentBeaconBlockRoot,
+		RequestsHash:          i.Header.RequestsHash,
+	}
+
+	// Fill optional values.
+	if i.Header.OmmerHash != nil {
+		header.UncleHash = *i.Header.OmmerHash
+	} else if len(i.Ommers) != 0 {
+		// Calculate the ommer hash if none is provided and there are ommers to hash
+		header.UncleHash = types.CalcUncleHash(i.Ommers)
+	}
+	if i.Header.Coinbase != nil {
+		header.Coinbase = *i.Header.Coinbase
+	}
+	if i.Header.TxHash != nil {
+		header.TxHash = *i.Header.TxHash
+	} else if len(i.Txs) != 0 {
+		header.TxHash = types.DeriveSha(types.Transactions(i.Txs))
+	}
+	if i.Header.ReceiptHash != nil {
+		header.ReceiptHash = *i.Header.ReceiptHash
+	}
+	if i.Header.Nonce != nil {
+		header.Nonce = *i.Header.Nonce
+	}
+	if i.Header.Difficulty != nil {
+		header.Difficulty = i.Header.Difficulty
+	}
+	if header.WithdrawalsHash == nil && i.Withdrawals != nil {
+		h := types.DeriveSha(types.Withdrawals(i.Withdrawals))
+		header.WithdrawalsHash = &h
+	}
+	if header.RequestsHash == nil && i.Requests != nil {
+		requests, err := flatRequests(i.Requests)
+		if err != nil {
+			return nil, NewError(ErrorJson, err)
+		}
+		header.RequestsHash = requests.Hash()
+	}
+	return types.NewBlockFromNetwork(header, &types.Body{Transactions: i.Txs, Uncles: i.Ommers, Withdrawals: i.Withdrawals}), nil
+}
+
+// flatRequests collates the data of the requests by type, in the order of the known request types.
+func flatRequests(requests []hexutility.Bytes) (types.FlatRequests, error) {
+	flat := make(types.FlatRequests, len(types.KnownRequestTypes))
+	for i, t := range types.KnownRequestTypes {
+		flat[i].Type = t
+	}
+	for n, r := range requests {
+		if len(r) == 0 {
+			return nil, fmt.Errorf("request %d: empty request", n)
+		}
+		known := false
+		for i := range flat {
+			if flat[i].Type == r[0] {
+				flat[i].RequestData = append(flat[i].RequestData, r[1:]...)
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("request %d: unknown request type %d", n, r[0])
+		}
+	}
+	return flat, nil
+}
+
+// SealBlock seals the given block using the configured engine. Blocks without a seal engine,
+// as the proof-of-stake ones, are returned as they are.
+func (i *bbInput) SealBlock(block *types.Block) (*types.Block, error) {
+	switch {
+	case i.Ethash:
+		return i.sealEthash(block)
+	case i.Clique != nil:
+		return i.sealClique(block)
+	default:
+		return block, nil
+	}
+}
+
+// sealEthash seals the given block using ethash.
+func (i *bbInput) sealEthash(block *types.Block) (*types.Block, error) {
+	if i.Header.Nonce != nil {
+		return nil, NewError(ErrorVMConfig, errors.New("sealing with ethash will overwrite provided nonce"))
+	}
+	header := block.Header()
+	if header.Difficulty.Sign() == 0 {
+		return nil, NewError(ErrorVMConfig, errors.New("sealing with ethash requires a non-zero difficulty, proof-of-stake blocks are not sealed"))
+	}
+	if i.PowMode == ethashcfg.ModeFake {
+		// Same as the fake engine: the block is valid whatever the seal
+		header.Nonce, header.MixDigest = types.BlockNonce{}, libcommon.Hash{}
+		return block.WithSeal(header), nil
+	}
+	engine := ethash.New(ethashcfg.Config{
+		CachesInMem:    2,
+		DatasetDir:     i.EthashDir,
+		DatasetsInMem:  1,
+		DatasetsOnDisk: 2,
+		PowMode:        i.PowMode,
+	}, nil, true)
+	defer engine.Close()
+	sealed, err := engine.SealLocally(header, nil)
+	if err != nil {
+		return nil, NewError(ErrorEVM, fmt.Errorf("failed to seal block: %v", err))
+	}
+	return block.WithSeal(sealed), nil
+}
+
+// sealClique seals the given block using clique.
+func (i *bbInput) sealClique(block *types.Block) (*types.Block, error) {
+	// If any clique value overwrites an explicit header value, fail
+	// to avoid silently building a block with unexpected values.
+	if i.Header.Extra != nil {
+		return nil, NewError(ErrorVMConfig, errors.New("sealing with clique will overwrite provided extra data"))
+	}
+	header := block.Header()
+	if i.Clique.Voted != nil {
+		if i.Header.Coinbase != nil {
+			return nil, NewError(ErrorVMConfig, errors.New("sealing with clique and voting will overwrite provided coinbase"))
+		}
+		header.Coinbase = *i.Clique.Voted
+	}
+	if i.Clique.Authorize != nil {
+		if i.Header.Nonce != nil {
+			return nil, NewError(ErrorVMConfig, errors.New("sealing with clique and voting will overwrite provided nonce"))
+		}
+		if *i.Clique.Authorize {
+			copy(header.Nonce[:], clique.NonceAuthVote)
+		} else {
+			header.Nonce = types.BlockNonce{}
+		}
+	}
+	// Extra is fixed 32 byte vanity and 65 byte signature
+	header.Extra = make([]byte, clique.ExtraVanity+clique.ExtraSeal)
+	copy(header.Extra[:clique.ExtraVanity], i.Clique.Vanity[:])
+
+	// Sign the seal hash and fill in the rest of the extra data
+	h := clique.SealHash(header)
+	sighash, err := crypto.Sign(h[:], i.Clique.Key)
+	if err != nil {
+		return nil, NewError(ErrorEVM, fmt.Errorf("failed to sign block: %v", err))
+	}
+	copy(header.Extra[clique.ExtraVanity:], sighash)
+	return block.WithSeal(header), nil
+}
+
+// BuildBlock constructs a block from the given inputs.
+func BuildBlock(ctx *cli.Context) error {
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StderrHandler))
+
+	baseDir := ""
+	// If user specified a basedir, make sure it exists
+	if ctx.IsSet(OutputBasedir.Name) {
+		if base := ctx.String(OutputBasedir.Name); len(base) > 0 {
+			if err := os.MkdirAll(base, 0755); err != nil {
+				return NewError(ErrorIO, fmt.Errorf("failed creating output basedir: %v", err))
+			}
+			baseDir = base
+		}
+	}
+	inputData, err := readInput(ctx)
+	if err != nil {
+		return err
+	}
+	block, err := inputData.ToBlock()
+	if err != nil {
+		return err
+	}
+	block, err = inputData.SealBlock(block)
+	if err != nil {
+		return err
+	}
+	return dispatchBlock(ctx, baseDir, block)
+}
+
+func readInput(ctx *cli.Context) (*bbInput, error) {
+	var (
+		headerStr      = ctx.String(InputHeaderFlag.Name)
+		ommersStr      = ctx.String(InputOmmersFlag.Name)
+		withdrawalsStr = ctx.String(InputWithdrawalsFlag.Name)
+		requestsStr    = ctx.String(InputRequestsFlag.Name)
+		txsStr         = ctx.String(InputTxsRlpFlag.Name)
+		cliqueStr      = ctx.String(SealCliqueFlag.Name)
+		ethashOn       = ctx.Bool(SealEthashFlag.Name)
+		ethashDir      = ctx.String(SealEthashDirFlag.Name)
+		ethashMode     = ctx.String(SealEthashModeFlag.Name)
+		inputData      = &bbInput{}
+	)
+	if ethashOn && cliqueStr != "" {
+		return nil, NewError(ErrorVMConfig, errors.New("both ethash and clique sealing specified, only one may be chosen"))
+	}
+	if ethashOn {
+		inputData.Ethash = ethashOn
+		inputData.EthashDir = ethashDir
+		switch ethashMode {
+		case "normal":
+			inputData.PowMode = ethashcfg.ModeNormal
+		case "test":
+			inputData.PowMode = ethashcfg.ModeTest
+		case "fake":
+			inputData.PowMode = ethashcfg.ModeFake
+		default:
+			return nil, NewError(ErrorVMConfig, fmt.Errorf("unknown pow mode: %s, supported modes: test, fake, normal", ethashMode))
+		}
+	}
+	if headerStr == stdinSelector || ommersStr == stdinSelector || withdrawalsStr == stdinSelector ||
+		requestsStr == stdinSelector || txsStr == stdinSelector || cliqueStr == stdinSelector {
+		decoder := json.NewDecoder(os.Stdin)
+		if err := decoder.Decode(inputData); err != nil {
+			return nil, NewError(ErrorJson, fmt.Errorf("failed unmarshaling stdin: %v", err))
+		}
+	}
+	if cliqueStr != stdinSelector && cliqueStr != "" {
+		var cliqueData cliqueInput
+		if err := readFile(cliqueStr, "clique", &cliqueData); err != nil {
+			return nil, err
+		}
+		inputData.Clique = &cliqueData
+	}
+	if headerStr != stdinSelector {
+		var env header
+		if err := readFile(headerStr, "header", &env); err != nil {
+			return nil, err
+		}
+		inputData.Header = &env
+	}
+	if ommersStr != stdinSelector && ommersStr != "" {
+		var ommers []string
+		if err := readFile(ommersStr, "ommers", &ommers); err != nil {
+			return nil, err
+		}
+		inputData.OmmersRlp = ommers
+	}
+	if withdrawalsStr != stdinSelector && withdrawalsStr != "" {
+		var withdrawals []*types.Withdrawal
+		if err := readFile(withdrawalsStr, "withdrawals", &withdrawals); err != nil {
+			return nil, err
+		}
+		inputData.Withdrawals = withdrawals
+	}
+	if requestsStr != stdinSelector && requestsStr != "" {
+		var requests []hexutility.Bytes
+		if err := readFile(requestsStr, "requests", &requests); err != nil {
+			return nil, err
+		}
+		inputData.Requests = requests
+	}
+	if txsStr != stdinSelector {
+		var txs string
+		if err := readFile(txsStr, "txs", &txs); err != nil {
+			return nil, err
+		}
+		inputData.TxRlp = txs
+	}
+	if inputData.Header == nil {
+		return nil, NewError(ErrorJson, errors.New("missing block header"))
+	}
+	// Deserialize rlp txs and ommers
+	var (
+		ommers = []*types.Header{}
+		txs    = []types.Transaction{}
+	)
+	if inputData.TxRlp != "" {
+		var err error
+		if txs, err = decodeTransactions(libcommon.FromHex(inputData.TxRlp)); err != nil {
+			return nil, NewError(ErrorRlp, fmt.Errorf("unable to decode transaction from rlp data: %v", err))
+		}
+	}
+	for _, str := range inputData.OmmersRlp {
+		type extblock struct {
+			Header *types.Header
+			Rest   []rlp.RawValue `rlp:"tail"` // body of the ommer, ignored
+		}
+		var ommer *extblock
+		if err := rlp.DecodeBytes(libcommon.FromHex(str), &ommer); err != nil {
+			return nil, NewError(ErrorRlp, fmt.Errorf("unable to decode ommer from rlp data: %v", err))
+		}
+		ommers = append(ommers, ommer.Header)
+	}
+	inputData.Txs = txs
+	inputData.Ommers = ommers
+
+	return inputData, nil
+}
+
+// readFile unmarshals the json file at path into dest.
+func readFile(path, desc string, dest interface{}) error {
+	inFile, err := os.Open(path)
+	if err != nil {
+		return NewError(ErrorIO, fmt.Errorf("failed reading %s file: %v", desc, err))
+	}
+	defer inFile.Close()
+	decoder := json.NewDecoder(inFile)
+	if err := decoder.Decode(dest); err != nil {
+		return NewError(ErrorJson, fmt.Errorf("failed unmarshaling %s file: %v", desc, err))
+	}
+	return nil
+}
+
+// dispatchBlock writes the output data to either stderr or stdout, or to the specified
+// files
+func dispatchBlock(ctx *cli.Context, baseDir string, block *types.Block) error {
+	raw, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		return NewError(ErrorRlp, fmt.Errorf("failed encoding block: %v", err))
+	}
+	type blockInfo struct {
+		Rlp  hexutility.Bytes `json:"rlp"`
+		Hash libcommon.Hash   `json:"hash"`
+	}
+	enc := blockInfo{
+		Rlp:  raw,
+		Hash: block.Hash(),
+	}
+	b, err := json.MarshalIndent(enc, "", "  ")
+	if err != nil {
+		return NewError(ErrorJson, fmt.Errorf("failed marshalling output: %v", err))
+	}
+	switch dest := ctx.String(OutputBlockFlag.Name); dest {
+	case "":
+		// don't save
+	case "stdout":
+		os.Stdout.Write(b)
+		os.Stdout.WriteString("\n")
+	case "stderr":
+		os.Stderr.Write(b)
+		os.Stderr.WriteString("\n")
+	default:
+		if err := saveFile(baseDir, dest, enc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2021 The go-ethereum Authors
+// (original work)
+// Copyright 2024 The Erigon Authors
+// (modifications)
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package t8ntool
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/common/hexutility"
+	"github.com/erigontech/erigon-lib/crypto/kzg"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/params"
+	"github.com/erigontech/erigon/rlp"
+	"github.com/erigontech/erigon/tests"
+)
+
+var (
+	errNoBlobHashes         = errors.New("blob transaction without blob hashes")
+	errTooManyBlobs         = errors.New("blob transaction with more blobs than allowed in a block")
+	errInvalidVersionedHash = errors.New("invalid blob versioned hash, must start with VERSIONED_HASH_VERSION_KZG")
+	errNoAuthorizations     = errors.New("set code transaction with empty authorization list")
+)
+
+type result struct {
+	Error        error
+	Address      libcommon.Address
+	Hash         libcommon.Hash
+	IntrinsicGas uint64
+}
+
+// MarshalJSON marshals as JSON with a hash.
+func (r *result) MarshalJSON() ([]byte, error) {
+	type xx struct {
+		Error        string             `json:"error,omitempty"`
+		Address      *libcommon.Address `json:"address,omitempty"`
+		Hash         *libcommon.Hash    `json:"hash,omitempty"`
+		IntrinsicGas hexutil.Uint64     `json:"intrinsicGas,omitempty"`
+	}
+	var out xx
+	if r.Error != nil {
+		out.Error = r.Error.Error()
+	}
+	if r.Address != (libcommon.Address{}) {
+		out.Address = &r.Address
+	}
+	if r.Hash != (libcommon.Hash{}) {
+		out.Hash = &r.Hash
+	}
+	out.IntrinsicGas = hexutil.Uint64(r.IntrinsicGas)
+	return json.Marshal(out)
+}
+
+// Transaction performs the static validity checks of the rlp encoded transactions, that is the ones which
+// do not depend on the state: signature, intrinsic gas, value limits and fee semantics, and the support of
+// the transaction type by the fork.
+func Transaction(ctx *cli.Context) error {
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StderrHandler))
+
+	// Construct the chainconfig
+	chainConfig, extraEips, err := tests.GetChainConfig(ctx.String(ForknameFlag.Name))
+	if err != nil {
+		return NewError(ErrorVMConfig, fmt.Errorf("failed constructing chain configuration: %v", err))
+	}
+	// Set the chain id
+	chainConfig.ChainID = big.NewInt(ctx.Int64(ChainIDFlag.Name))
+
+	var (
+		txStr     = ctx.String(InputTxsFlag.Name)
+		inputData = &input{}
+		body      hexutility.Bytes
+	)
+	if txStr == stdinSelector {
+		decoder := json.NewDecoder(os.Stdin)
+		if err := decoder.Decode(inputData); err != nil {
+			return NewError(ErrorJson, fmt.Errorf("failed unmarshaling stdin: %v", err))
+		}
+		// Decode the body of already signed transactions
+		body = libcommon.FromHex(inputData.TxRlp)
+	} else {
+		// Read input from file
+		inFile, err := os.Open(txStr)
+		if err != nil {
+			return NewError(ErrorIO, fmt.Errorf("failed reading txs file: %v", err))
+		}
+		defer inFile.Close()
+		decoder := json.NewDecoder(inFile)
+		if err := decoder.Decode(&body); err != nil {
+			return NewError(ErrorJson, fmt.Errorf("failed unmarshaling txs-file: %v", err))
+		}
+	}
+	txs, err := decodeTransactions(body)
+	if err != nil {
+		return NewError(ErrorRlp, fmt.Errorf("failed decoding transactions: %v", err))
+	}
+
+	vmConfig := vm.Config{ExtraEips: extraEips}
+	// The forks of the test configurations are all active from genesis
+	rules := chainConfig.Rules(0, 0)
+	signer := types.MakeSigner(chainConfig, 0, 0)
+	results := make([]*result, 0, len(txs))
+	for _, tx := range txs {
+		r := &result{Hash: tx.Hash()}
+		r.Address, r.IntrinsicGas, r.Error = validateTransaction(tx, chainConfig, rules, &vmConfig, signer)
+		results = append(results, r)
+	}
+	out, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return NewError(ErrorJson, fmt.Errorf("failed marshalling output: %v", err))
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// decodeTransactions decodes an rlp list of transactions, typed ones being wrapped in rlp strings
+// as in block bodies.
+func decodeTransactions(body []byte) (types.Transactions, error) {
+	s := rlp.NewStream(bytes.NewReader(body), uint64(len(body)))
+	if _, err := s.List(); err != nil {
+		return nil, err
+	}
+	var txs types.Transactions
+	for {
+		tx, err := types.DecodeRLPTransaction(s, false)
+		if errors.Is(err, rlp.EOL) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", len(txs), err)
+		}
+		txs = append(txs, tx)
+	}
+	return txs, s.ListEnd()
+}
+
+// validateTransaction returns the sender and the intrinsic gas of the transaction, along with the
+// first reason it is invalid for.
+func validateTransaction(tx types.Transaction, chainConfig *chain.Config, rules *chain.Rules, vmConfig *vm.Config, signer *types.Signer) (libcommon.Address, uint64, error) {
+	var supported bool
+	switch tx.Type() {
+	case types.LegacyTxType:
+		supported = true
+	case types.AccessListTxType:
+		supported = rules.IsBerlin
+	case types.DynamicFeeTxType:
+		supported = rules.IsLondon
+	case types.BlobTxType:
+		supported = rules.IsCancun
+	case types.SetCodeTxType:
+		supported = rules.IsPrague
+	}
+	if !supported {
+		return libcommon.Address{}, 0, core.ErrTxTypeNotSupported
+	}
+	sender, err := signer.Sender(tx)
+	if err != nil {
+		return libcommon.Address{}, 0, err
+	}
+
+	var authorizationsLen uint64
+	if tx.Type() == types.SetCodeTxType {
+		authorizationsLen = uint64(len(tx.(*types.SetCodeTransaction).GetAuthorizations()))
+	}
+	contractCreation := tx.GetTo() == nil
+	isEIP3860 := vmConfig.HasEip3860(rules)
+	gas, err := core.IntrinsicGas(tx.GetData(), tx.GetAccessList(), contractCreation, rules.IsHomestead, rules.IsIstanbul, isEIP3860, authorizationsLen)
+	if err != nil {
+		return sender, 0, err
+	}
+	switch {
+	case tx.GetGas() < gas:
+		return sender, gas, fmt.Errorf("%w: have %d, want %d", core.ErrIntrinsicGas, tx.GetGas(), gas)
+	case tx.GetNonce()+1 < tx.GetNonce():
+		return sender, gas, core.ErrNonceMax
+	case tx.GetFeeCap().Cmp(tx.GetTip()) < 0:
+		return sender, gas, fmt.Errorf("%w: tip: %s, gasFeeCap: %s", core.ErrTipAboveFeeCap, tx.GetTip(), tx.GetFeeCap())
+	case isEIP3860 && contractCreation && len(tx.GetData()) > params.MaxInitCodeSize:
+		return sender, gas, fmt.Errorf("%w: code size %v limit %v", core.ErrMaxInitCodeSizeExceeded, len(tx.GetData()), params.MaxInitCodeSize)
+	}
+
+	switch tx.Type() {
+	case types.BlobTxType:
+		blobHashes := tx.GetBlobHashes()
+		if len(blobHashes) == 0 {
+			return sender, gas, errNoBlobHashes
+		}
+		if uint64(len(blobHashes)) > chainConfig.GetMaxBlobsPerBlock() {
+			return sender, gas, fmt.Errorf("%w: have %d, max %d", errTooManyBlobs, len(blobHashes), chainConfig.GetMaxBlobsPerBlock())
+		}
+		for _, h := range blobHashes {
+			if h[0] != kzg.BlobCommitmentVersionKZG {
+				return sender, gas, errInvalidVersionedHash
+			}
+		}
+	case types.SetCodeTxType:
+		if authorizationsLen == 0 {
+			return sender, gas, errNoAuthorizations
+		}
+	}
+	return sender, gas, nil
+}
@@ -66,6 +66,7 @@ const (
 
 	ErrorJson = 10
 	ErrorIO   = 11
+	ErrorRlp  = 12
 
 	stdinSelector = "stdin"
 )
@@ -96,6 +97,7 @@ type input struct {
 	Alloc types.GenesisAlloc `json:"alloc,omitempty"`
 	Env   *stEnv             `json:"env,omitempty"`
 	Txs   []*txWithKey       `json:"txs,omitempty"`
+	TxRlp string             `json:"txsRlp,omitempty"`
 }
 
 func Main(ctx *cli.Context) error {
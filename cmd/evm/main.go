@@ -50,6 +50,10 @@ var (
 		Name:  "cpuprofile",
 		Usage: "creates a CPU profile at the given path",
 	}
+	ProfileFlag = cli.StringFlag{
+		Name:  "profile",
+		Usage: "writes the gas profile of the execution at the given path, as folded stacks for flame graph tools",
+	}
 	StatDumpFlag = cli.BoolFlag{
 		Name:  "statdump",
 		Usage: "displays stack and heap memory information",
@@ -158,6 +162,40 @@ var stateTransitionCommand = cli.Command{
 	},
 }
 
+var transactionCommand = cli.Command{
+	Name:    "transaction",
+	Aliases: []string{"t9n"},
+	Usage:   "performs transaction validation",
+	Action:  t8ntool.Transaction,
+	Flags: []cli.Flag{
+		&t8ntool.InputTxsFlag,
+		&t8ntool.ChainIDFlag,
+		&t8ntool.ForknameFlag,
+		&t8ntool.VerbosityFlag,
+	},
+}
+
+var blockBuilderCommand = cli.Command{
+	Name:    "block-builder",
+	Aliases: []string{"b11r"},
+	Usage:   "builds a block",
+	Action:  t8ntool.BuildBlock,
+	Flags: []cli.Flag{
+		&t8ntool.OutputBasedir,
+		&t8ntool.OutputBlockFlag,
+		&t8ntool.InputHeaderFlag,
+		&t8ntool.InputOmmersFlag,
+		&t8ntool.InputWithdrawalsFlag,
+		&t8ntool.InputRequestsFlag,
+		&t8ntool.InputTxsRlpFlag,
+		&t8ntool.SealCliqueFlag,
+		&t8ntool.SealEthashFlag,
+		&t8ntool.SealEthashDirFlag,
+		&t8ntool.SealEthashModeFlag,
+		&t8ntool.VerbosityFlag,
+	},
+}
+
 func init() {
 	app.Flags = []cli.Flag{
 		&BenchFlag,
@@ -174,6 +212,7 @@ func init() {
 		&InputFileFlag,
 		&MemProfileFlag,
 		&CPUProfileFlag,
+		&ProfileFlag,
 		&StatDumpFlag,
 		&GenesisFlag,
 		&MachineFlag,
@@ -190,6 +229,8 @@ func init() {
 		&runCommand,
 		&stateTestCommand,
 		&stateTransitionCommand,
+		&transactionCommand,
+		&blockBuilderCommand,
 	}
 }
 
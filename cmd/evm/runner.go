@@ -23,6 +23,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/erigontech/erigon-lib/config3"
 	"github.com/erigontech/erigon-lib/kv/temporal"
@@ -56,7 +57,9 @@ import (
 	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/core/vm"
 	"github.com/erigontech/erigon/core/vm/runtime"
+	"github.com/erigontech/erigon/eth/tracers"
 	"github.com/erigontech/erigon/eth/tracers/logger"
+	_ "github.com/erigontech/erigon/eth/tracers/native"
 	"github.com/erigontech/erigon/params"
 )
 
@@ -144,6 +147,7 @@ func runCmd(ctx *cli.Context) error {
 
 	var (
 		tracer        vm.EVMLogger
+		profiler      tracers.Tracer
 		debugLogger   *logger.StructLogger
 		statedb       *state.IntraBlockState
 		chainConfig   *chain.Config
@@ -159,6 +163,17 @@ func runCmd(ctx *cli.Context) error {
 	} else {
 		debugLogger = logger.NewStructLogger(logconfig)
 	}
+	profilePath := ctx.String(ProfileFlag.Name)
+	if profilePath != "" {
+		if tracer != nil {
+			return errors.New("--profile can't be combined with --json or --debug")
+		}
+		var err error
+		if profiler, err = tracers.New("profileTracer", new(tracers.Context), nil); err != nil {
+			return err
+		}
+		tracer = profiler
+	}
 	db := memdb.New(os.TempDir())
 	defer db.Close()
 	if ctx.String(GenesisFlag.Name) != "" {
@@ -262,7 +277,7 @@ func runCmd(ctx *cli.Context) error {
 		BlockNumber: new(big.Int).SetUint64(genesisConfig.Number),
 		EVMConfig: vm.Config{
 			Tracer: tracer,
-			Debug:  ctx.Bool(DebugFlag.Name) || ctx.Bool(MachineFlag.Name),
+			Debug:  ctx.Bool(DebugFlag.Name) || ctx.Bool(MachineFlag.Name) || profiler != nil,
 		},
 	}
 
@@ -359,6 +374,13 @@ func runCmd(ctx *cli.Context) error {
 		logger.WriteLogs(os.Stderr, statedb.Logs())
 	}
 
+	if profiler != nil {
+		if err := writeProfile(profilePath, profiler); err != nil {
+			fmt.Fprintf(os.Stderr, "could not write gas profile: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	if bench || ctx.Bool(StatDumpFlag.Name) {
 		_, printErr := fmt.Fprintf(os.Stderr, `EVM gas used:    %d
 execution time:  %v
@@ -369,7 +391,7 @@ allocated bytes: %d
 			log.Warn("Failed to print to stderr", "err", printErr)
 		}
 	}
-	if tracer == nil {
+	if tracer == nil || profiler != nil {
 		fmt.Printf("0x%x\n", output)
 		if err != nil {
 			fmt.Printf(" error: %v\n", err)
@@ -378,3 +400,23 @@ allocated bytes: %d
 
 	return nil
 }
+
+// writeProfile writes the folded call stacks of the gas profile at the given path.
+func writeProfile(path string, profiler tracers.Tracer) error {
+	res, err := profiler.GetResult()
+	if err != nil {
+		return err
+	}
+	var profile struct {
+		Folded []string `json:"folded"`
+	}
+	if err := json.Unmarshal(res, &profile); err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	for _, line := range profile.Folded {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return os.WriteFile(path, b.Bytes(), 0644)
+}
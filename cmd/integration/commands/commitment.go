@@ -0,0 +1,176 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/erigontech/erigon-lib/commitment"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon/turbo/debug"
+)
+
+var (
+	verifySteps    []uint
+	verifyMaxDiffs int
+)
+
+func init() {
+	withDataDir(cmdCommitmentVerify)
+	cmdCommitmentVerify.Flags().UintSliceVar(&verifySteps, "steps", nil, "steps to verify (must be end of commitment file). By default: last step in files")
+	cmdCommitmentVerify.Flags().IntVar(&verifyMaxDiffs, "max-diffs", 16, "stop collecting diverged branches after this amount (0 - unlimited)")
+
+	cmdCommitment.AddCommand(cmdCommitmentVerify)
+	rootCmd.AddCommand(cmdCommitment)
+}
+
+var cmdCommitment = &cobra.Command{
+	Use:   "commitment",
+	Short: "Commitment domain tools",
+}
+
+var cmdCommitmentVerify = &cobra.Command{
+	Use:   "verify",
+	Short: "Recompute state root from Accounts/Storage domain files and compare it with Commitment domain and headers",
+	Long: `Offline: nothing is written. For each step trie is built from scratch and every branch is compared with
+Commitment domain; first diverged branch (deepest one of first diverged subtree) is the place to look at.
+Commitment file itself can be visualized by cmd/commitment-prefix.`,
+	Example: "go run ./cmd/integration commitment verify --datadir=... --steps=1024,1536",
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := debug.SetupCobra(cmd, "integration")
+		db, err := openDB(dbCfg(kv.ChainDB, chaindata), false, logger)
+		if err != nil {
+			logger.Error("Opening DB", "error", err)
+			return
+		}
+		defer db.Close()
+
+		if err := commitmentVerify(db, cmd.Context(), logger); err != nil {
+			if !errors.Is(err, context.Canceled) {
+				logger.Error(err.Error())
+			}
+			return
+		}
+	},
+}
+
+func commitmentVerify(db kv.RoDB, ctx context.Context, logger log.Logger) error {
+	sn, borSn, agg, _ := allSnapshots(ctx, db, logger)
+	defer sn.Close()
+	defer borSn.Close()
+	defer agg.Close()
+	br, _ := blocksIO(db, logger)
+
+	ac := agg.BeginFilesRo()
+	defer ac.Close()
+
+	steps := make([]uint64, 0, len(verifySteps))
+	for _, s := range verifySteps {
+		steps = append(steps, uint64(s))
+	}
+	if len(steps) == 0 {
+		available := ac.CommitmentFilesEndSteps()
+		if len(available) == 0 {
+			return errors.New("no commitment files found")
+		}
+		steps = available[len(available)-1:]
+	}
+
+	tx, err := db.BeginRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var failed int
+	for _, step := range steps {
+		report, err := ac.VerifyCommitment(ctx, step, verifyMaxDiffs)
+		if err != nil {
+			return fmt.Errorf("step %d: %w", step, err)
+		}
+		header, err := br.HeaderByNumber(ctx, tx, report.StateBlockNum)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("step %d: txNum=%d, commitment state at block %d (txNum=%d), keys=%d, branches=%d, took=%s\n",
+			step, report.TxNum, report.StateBlockNum, report.StateTxNum, report.Keys, report.Branches, report.Took)
+		fmt.Printf("  stored root  %x\n  rebuilt root %x\n", report.StoredRoot, report.RebuiltRoot)
+		if header != nil {
+			fmt.Printf("  header root  %x\n", header.Root)
+		} else {
+			fmt.Printf("  header %d not found\n", report.StateBlockNum)
+		}
+		headerOk := header != nil && bytes.Equal(header.Root[:], report.StoredRoot)
+		if report.Ok() && headerOk {
+			fmt.Printf("  OK\n")
+			continue
+		}
+		failed++
+		if !headerOk {
+			fmt.Printf("  MISMATCH: stored root doesn't match header\n")
+		}
+		if len(report.Diverged) == 0 {
+			if !bytes.Equal(report.StoredRoot, report.RebuiltRoot) {
+				// the branches match, so the difference is in the root cell itself
+				fmt.Printf("  MISMATCH: rebuilt root %x doesn't match stored root %x, no branch diverged\n", report.RebuiltRoot, report.StoredRoot)
+			}
+			continue
+		}
+		fmt.Printf("  MISMATCH: %d diverged branches (max %d)\n", len(report.Diverged), verifyMaxDiffs)
+		for i, d := range report.Diverged {
+			fmt.Printf("  #%d prefix=[%s] (key %x) children=%016b\n", i, nibblesString(commitment.CompactedKeyToHex(d.Prefix)), d.Prefix, d.Children)
+			if i > 0 {
+				continue // full dump only for the first one
+			}
+			printBranch("stored", d.Prefix, d.Stored)
+			printBranch("rebuilt", d.Prefix, d.Rebuilt)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("commitment verification failed at %d of %d steps", failed, len(steps))
+	}
+	return nil
+}
+
+func nibblesString(nibbles []byte) string {
+	var sb strings.Builder
+	for _, n := range nibbles {
+		sb.WriteByte("0123456789abcdef"[n&0xf])
+	}
+	return sb.String()
+}
+
+func printBranch(name string, prefix []byte, branch commitment.BranchData) {
+	if len(branch) == 0 {
+		fmt.Printf("    %s: <none>\n", name)
+		return
+	}
+	// same stats as cmd/commitment-prefix collects per file
+	st := commitment.DecodeBranchAndCollectStat(prefix, branch, commitment.VariantHexPatriciaTrie)
+	fmt.Printf("    %s: cells=%d accounts=%d storages=%d hashes=%d extensions=%d\n", name, st.CellCount, st.APKCount, st.SPKCount, st.HashCount, st.ExtCount)
+	for _, line := range strings.Split(strings.TrimRight(branch.String(), "\n"), "\n") {
+		fmt.Printf("      %s\n", line)
+	}
+}
@@ -69,6 +69,7 @@ import (
 	"github.com/erigontech/erigon/core/rawdb"
 	"github.com/erigontech/erigon/core/state"
 	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/vm"
 	"github.com/erigontech/erigon/core/vm/evmtypes"
 	"github.com/erigontech/erigon/eth/ethconfig"
 	"github.com/erigontech/erigon/node"
@@ -175,6 +176,7 @@ func RootCommand() (*cobra.Command, *httpcfg.HttpCfg) {
 	rootCmd.PersistentFlags().IntVar(&cfg.RpcFiltersConfig.RpcSubscriptionFiltersMaxTxs, "rpc.subscription.filters.maxtxs", rpchelper.DefaultFiltersConfig.RpcSubscriptionFiltersMaxTxs, "Maximum number of transactions to store per subscription.")
 	rootCmd.PersistentFlags().IntVar(&cfg.RpcFiltersConfig.RpcSubscriptionFiltersMaxAddresses, "rpc.subscription.filters.maxaddresses", rpchelper.DefaultFiltersConfig.RpcSubscriptionFiltersMaxAddresses, "Maximum number of addresses per subscription to filter logs by.")
 	rootCmd.PersistentFlags().IntVar(&cfg.RpcFiltersConfig.RpcSubscriptionFiltersMaxTopics, "rpc.subscription.filters.maxtopics", rpchelper.DefaultFiltersConfig.RpcSubscriptionFiltersMaxTopics, "Maximum number of topics per subscription to filter logs by.")
+	rootCmd.PersistentFlags().StringVar(&cfg.RpcFiltersConfig.RpcSubscriptionStateDiffsSocket, "rpc.subscription.statediffs.socket", rpchelper.DefaultFiltersConfig.RpcSubscriptionStateDiffsSocket, "Path of unix socket to stream per-block state diffs (NDJSON). Empty - disabled.")
 	rootCmd.PersistentFlags().IntVar(&cfg.BatchLimit, utils.RpcBatchLimit.Name, utils.RpcBatchLimit.Value, utils.RpcBatchLimit.Usage)
 	rootCmd.PersistentFlags().IntVar(&cfg.ReturnDataLimit, utils.RpcReturnDataLimit.Name, utils.RpcReturnDataLimit.Value, utils.RpcReturnDataLimit.Usage)
 	rootCmd.PersistentFlags().BoolVar(&cfg.AllowUnprotectedTxs, utils.AllowUnprotectedTxs.Name, utils.AllowUnprotectedTxs.Value, utils.AllowUnprotectedTxs.Usage)
@@ -230,7 +232,7 @@ type StateChangesClient interface {
 	StateChanges(ctx context.Context, in *remote.StateChangeRequest, opts ...grpc.CallOption) (remote.KV_StateChangesClient, error)
 }
 
-func subscribeToStateChangesLoop(ctx context.Context, client StateChangesClient, cache kvcache.Cache) {
+func subscribeToStateChangesLoop(ctx context.Context, client StateChangesClient, cache kvcache.Cache, ff *rpchelper.Filters) {
 	go func() {
 		for {
 			select {
@@ -238,7 +240,7 @@ func subscribeToStateChangesLoop(ctx context.Context, client StateChangesClient,
 				return
 			default:
 			}
-			if err := subscribeToStateChanges(ctx, client, cache); err != nil {
+			if err := subscribeToStateChanges(ctx, client, cache, ff); err != nil {
 				if grpcutil.IsRetryLater(err) || grpcutil.IsEndOfStream(err) {
 					time.Sleep(3 * time.Second)
 					continue
@@ -249,7 +251,7 @@ func subscribeToStateChangesLoop(ctx context.Context, client StateChangesClient,
 	}()
 }
 
-func subscribeToStateChanges(ctx context.Context, client StateChangesClient, cache kvcache.Cache) error {
+func subscribeToStateChanges(ctx context.Context, client StateChangesClient, cache kvcache.Cache, ff *rpchelper.Filters) error {
 	streamCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 	stream, err := client.StateChanges(streamCtx, &remote.StateChangeRequest{WithStorage: true, WithTransactions: false}, grpc.WaitForReady(true))
@@ -265,6 +267,9 @@ func subscribeToStateChanges(ctx context.Context, client StateChangesClient, cac
 		}
 
 		cache.OnNewBlock(req)
+		if ff != nil {
+			ff.OnStateChanges(req)
+		}
 	}
 }
 
@@ -319,8 +324,6 @@ func EmbeddedServices(ctx context.Context,
 		stateCache = kvcache.NewDummy()
 	}
 
-	subscribeToStateChangesLoop(ctx, stateDiffClient, stateCache)
-
 	directClient := direct.NewEthBackendClientDirect(ethBackendServer)
 
 	eth = rpcservices.NewRemoteBackend(directClient, erigonDB, blockReader)
@@ -328,6 +331,7 @@ func EmbeddedServices(ctx context.Context,
 	txPool = direct.NewTxPoolClient(txPoolServer)
 	mining = direct.NewMiningClient(miningServer)
 	ff = rpchelper.New(ctx, rpcFiltersConfig, eth, txPool, mining, func() {}, logger)
+	subscribeToStateChangesLoop(ctx, stateDiffClient, stateCache, ff)
 
 	return
 }
@@ -405,6 +409,9 @@ func RemoteServices(ctx context.Context, cfg *httpcfg.HttpCfg, logger log.Logger
 		if cc == nil {
 			return nil, nil, nil, nil, nil, nil, nil, ff, nil, nil, errors.New("chain config not found in db. Need start erigon at least once on this db")
 		}
+		if err := vm.CheckPrecompiles(cc); err != nil {
+			return nil, nil, nil, nil, nil, nil, nil, ff, nil, nil, err
+		}
 
 		// Configure sapshots
 		allSnapshots = freezeblocks.NewRoSnapshots(cfg.Snap, cfg.Dirs.Snap, 0, logger)
@@ -501,8 +508,6 @@ func RemoteServices(ctx context.Context, cfg *httpcfg.HttpCfg, logger log.Logger
 		logger.Info("if you run RPCDaemon on same machine with Erigon add --datadir option")
 	}
 
-	subscribeToStateChangesLoop(ctx, remoteKvClient, stateCache)
-
 	txpoolConn := conn
 	if cfg.TxPoolApiAddr != cfg.PrivateApiAddr {
 		txpoolConn, err = grpcutil.Connect(creds, cfg.TxPoolApiAddr)
@@ -614,6 +619,7 @@ func RemoteServices(ctx context.Context, cfg *httpcfg.HttpCfg, logger log.Logger
 	}()
 
 	ff = rpchelper.New(ctx, cfg.RpcFiltersConfig, eth, txPool, mining, onNewSnapshot, logger)
+	subscribeToStateChangesLoop(ctx, remoteKvClient, stateCache, ff)
 	return db, eth, txPool, mining, stateCache, blockReader, engine, ff, bridgeReader, heimdallReader, err
 }
 
@@ -0,0 +1,250 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package exec3
+
+import (
+	"context"
+	"sync"
+
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/metrics"
+
+	"github.com/erigontech/erigon/consensus"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/types/accounts"
+	"github.com/erigontech/erigon/core/vm"
+)
+
+var (
+	mxBlockSTMValid      = metrics.GetOrCreateCounter(`exec_blockstm_txs{result="valid"}`)
+	mxBlockSTMReexecuted = metrics.GetOrCreateCounter(`exec_blockstm_txs{result="reexecuted"}`)
+)
+
+// BlockSTM executes the transactions of a block optimistically in parallel,
+// following Block-STM: workers execute each transaction against the writes of
+// the transactions before it published so far, then the transactions are
+// committed in order, once validated against the writes of the transactions
+// before them. A transaction whose reads were invalidated by a conflict is
+// executed again at commit, when the transactions before it are all
+// committed. The results are thus those of a serial execution.
+type BlockSTM struct {
+	ctx         context.Context
+	logger      log.Logger
+	chainConfig *chain.Config
+	engine      consensus.Engine
+	vmConfig    vm.Config
+	workers     []*Worker // the last one executes the transactions at commit
+}
+
+func NewBlockSTM(ctx context.Context, logger log.Logger, chainConfig *chain.Config, engine consensus.Engine, vmConfig vm.Config, workerCount int) *BlockSTM {
+	e := &BlockSTM{
+		ctx:         ctx,
+		logger:      logger,
+		chainConfig: chainConfig,
+		engine:      engine,
+		vmConfig:    vmConfig,
+		workers:     make([]*Worker, max(workerCount, 1)+1),
+	}
+	for i := range e.workers {
+		e.workers[i] = e.newWorker()
+	}
+	return e
+}
+
+func (e *BlockSTM) newWorker() *Worker {
+	w := NewWorker(nil, e.logger, e.ctx, false, nil, nil, nil, e.chainConfig, nil, nil, e.engine, datadir.Dirs{}, false)
+	w.SetVMConfig(e.vmConfig)
+	return w
+}
+
+type blockSTMResult struct {
+	txIndex int
+	reads   *state.VersionedReader // nil if the execution panicked
+}
+
+// Execute executes tasks, the transactions of a block in order, on base: the
+// state once the block is initialised, which is only read from the calling
+// goroutine. Each task gets the results RunTxTaskNoLock would give it, except
+// that its writes are left to apply to the state, in VersionedWrites.
+// Execution stops at the first transaction failing with an error.
+//
+// tasks[i] must be the task of the i-th transaction of the block.
+func (e *BlockSTM) Execute(ctx context.Context, tasks []*state.TxTask, base state.StateReader) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	var (
+		versions = state.NewVersionMap(len(tasks), tasks[0].Rules.IsSpuriousDragon)
+		queue    = make(chan *state.TxTask, len(tasks))
+		results  = make(chan blockSTMResult, len(tasks))
+		reads    = make(chan func())
+		wg       sync.WaitGroup
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	for _, txTask := range tasks {
+		queue <- txTask
+	}
+	close(queue)
+	remote := &remoteReader{ctx: ctx, reads: reads, base: base}
+	for i := 0; i < len(e.workers)-1; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for txTask := range queue {
+				if ctx.Err() != nil {
+					return
+				}
+				results <- blockSTMResult{txIndex: txTask.TxIndex, reads: e.speculate(i, versions, txTask, remote)}
+			}
+		}(i)
+	}
+
+	executed := make([]bool, len(tasks))
+	speculated := make([]*state.VersionedReader, len(tasks))
+	for next := 0; next < len(tasks); {
+		if !executed[next] {
+			select {
+			case read := <-reads:
+				read()
+			case result := <-results:
+				executed[result.txIndex] = true
+				speculated[result.txIndex] = result.reads
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			continue
+		}
+
+		// All the transactions before are committed: the execution is valid
+		// if it read their final writes.
+		txTask := tasks[next]
+		valid := false
+		if reads := speculated[next]; reads != nil {
+			var err error
+			if valid, err = reads.Validate(base); err != nil {
+				return err
+			}
+		}
+		if valid {
+			mxBlockSTMValid.Inc()
+		} else {
+			mxBlockSTMReexecuted.Inc()
+			e.execute(e.workers[len(e.workers)-1], versions, txTask, base)
+		}
+		if txTask.Error != nil {
+			return nil
+		}
+		next++
+	}
+	return nil
+}
+
+// speculate executes a transaction ahead of its commit on the i-th worker. It
+// returns nil if the execution panicked, which the inconsistent state read
+// by a transaction executed too early may cause: it is executed again at
+// commit.
+func (e *BlockSTM) speculate(i int, versions *state.VersionMap, txTask *state.TxTask, base state.StateReader) (reads *state.VersionedReader) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			e.logger.Debug("[blockstm] speculative execution panicked", "block", txTask.BlockNum, "txIndex", txTask.TxIndex, "err", rec)
+			e.workers[i] = e.newWorker()
+			reads = nil
+		}
+	}()
+	return e.execute(e.workers[i], versions, txTask, base)
+}
+
+// execute executes a transaction on a worker, and publishes its writes.
+func (e *BlockSTM) execute(w *Worker, versions *state.VersionMap, txTask *state.TxTask, base state.StateReader) *state.VersionedReader {
+	txTask.Reset()
+	txTask.Error, txTask.Failed, txTask.UsedGas = nil, false, 0
+
+	reader := versions.NewReader(txTask.TxIndex, base)
+	writes := &state.VersionedWrites{}
+	ibs := state.New(reader)
+	w.runTx(txTask, ibs, txTask.Rules)
+	if txTask.Error == nil {
+		txTask.BalanceIncreaseSet = ibs.BalanceIncreaseSet()
+		txTask.Error = ibs.MakeWriteSet(txTask.Rules, writes)
+	}
+	txTask.VersionedWrites = writes
+	versions.Publish(txTask.TxIndex, writes, txTask.BalanceIncreaseSet)
+	return reader
+}
+
+// remoteReader reads the state from the goroutine executing the block, which
+// serves the reads in between the commits: the state is not safe for
+// concurrent use.
+type remoteReader struct {
+	ctx   context.Context
+	reads chan<- func()
+	base  state.StateReader
+}
+
+func (r *remoteReader) read(f func()) error {
+	done := make(chan struct{})
+	select {
+	case r.reads <- func() { f(); close(done) }:
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	}
+	<-done
+	return nil
+}
+
+func (r *remoteReader) ReadAccountData(address common.Address) (acc *accounts.Account, err error) {
+	if err := r.read(func() { acc, err = r.base.ReadAccountData(address) }); err != nil {
+		return nil, err
+	}
+	return acc, err
+}
+
+func (r *remoteReader) ReadAccountStorage(address common.Address, incarnation uint64, key *common.Hash) (enc []byte, err error) {
+	if err := r.read(func() { enc, err = r.base.ReadAccountStorage(address, incarnation, key) }); err != nil {
+		return nil, err
+	}
+	return enc, err
+}
+
+func (r *remoteReader) ReadAccountCode(address common.Address, incarnation uint64, codeHash common.Hash) (code []byte, err error) {
+	if err := r.read(func() { code, err = r.base.ReadAccountCode(address, incarnation, codeHash) }); err != nil {
+		return nil, err
+	}
+	return code, err
+}
+
+func (r *remoteReader) ReadAccountCodeSize(address common.Address, incarnation uint64, codeHash common.Hash) (size int, err error) {
+	if err := r.read(func() { size, err = r.base.ReadAccountCodeSize(address, incarnation, codeHash) }); err != nil {
+		return 0, err
+	}
+	return size, err
+}
+
+func (r *remoteReader) ReadAccountIncarnation(address common.Address) (incarnation uint64, err error) {
+	if err := r.read(func() { incarnation, err = r.base.ReadAccountIncarnation(address) }); err != nil {
+		return 0, err
+	}
+	return incarnation, err
+}
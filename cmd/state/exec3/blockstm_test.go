@@ -0,0 +1,273 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package exec3_test
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/metrics"
+	state2 "github.com/erigontech/erigon-lib/state"
+
+	"github.com/erigontech/erigon/cmd/state/exec3"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/types/accounts"
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/crypto"
+	"github.com/erigontech/erigon/eth/ethconfig"
+	"github.com/erigontech/erigon/params"
+	"github.com/erigontech/erigon/turbo/stages/mock"
+)
+
+// conflictingChain returns a genesis funding three senders along with a
+// counter contract, the key of the first sender, and a function generating
+// blocks of conflicting transactions on the genesis.
+func conflictingChain(t *testing.T) (*types.Genesis, *ecdsa.PrivateKey, func(m *mock.MockSentry, n int) *core.ChainPack) {
+	var (
+		keys    = make([]*ecdsa.PrivateKey, 3)
+		senders = make([]libcommon.Address, len(keys))
+		counter = libcommon.HexToAddress("0xc0")
+		alloc   = types.GenesisAlloc{
+			// PUSH1 0 SLOAD PUSH1 1 ADD PUSH1 0 SSTORE STOP
+			counter: {Balance: new(big.Int), Code: []byte{0x60, 0x00, 0x54, 0x60, 0x01, 0x01, 0x60, 0x00, 0x55, 0x00}},
+		}
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		senders[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+		alloc[senders[i]] = types.GenesisAccount{Balance: big.NewInt(params.Ether)}
+	}
+	generate := func(m *mock.MockSentry, n int) *core.ChainPack {
+		signer := types.LatestSignerForChainID(m.ChainConfig.ChainID)
+		chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, n, func(i int, b *core.BlockGen) {
+			b.SetCoinbase(senders[2])
+			send := func(from int, to *libcommon.Address, value uint64, gas uint64, data []byte) {
+				var tx types.Transaction
+				if to == nil {
+					tx = types.NewContractCreation(b.TxNonce(senders[from]), uint256.NewInt(value), gas, uint256.NewInt(params.GWei), data)
+				} else {
+					tx = types.NewTransaction(b.TxNonce(senders[from]), *to, uint256.NewInt(value), gas, uint256.NewInt(params.GWei), data)
+				}
+				signed, err := types.SignTx(tx, *signer, keys[from])
+				require.NoError(t, err)
+				b.AddTx(signed)
+			}
+			fresh := libcommon.BigToAddress(big.NewInt(int64(0x1000 + i)))
+			send(0, &counter, 0, 100_000, nil)
+			send(1, &counter, 0, 100_000, nil)
+			send(0, &fresh, params.GWei, params.TxGas, nil)
+			send(1, &senders[0], params.GWei, params.TxGas, nil)
+			send(0, &counter, 0, 100_000, nil)
+			send(1, &fresh, params.GWei, params.TxGas, nil)
+			// deploys the counter: PUSH10 <code> PUSH1 0 MSTORE PUSH1 10 PUSH1 22 RETURN
+			send(0, nil, 0, 200_000, []byte{0x69, 0x60, 0x00, 0x54, 0x60, 0x01, 0x01, 0x60, 0x00, 0x55, 0x00, 0x60, 0x00, 0x52, 0x60, 0x0a, 0x60, 0x16, 0xf3})
+			send(2, &counter, 0, 100_000, nil)
+			send(2, &senders[1], params.GWei, params.TxGas, nil)
+		})
+		require.NoError(t, err)
+		return chain
+	}
+	return &types.Genesis{Config: params.TestChainConfig, Alloc: alloc}, keys[0], generate
+}
+
+// TestBlockSTM executes blocks of conflicting transactions with BlockSTM, and
+// checks the receipts and the writes of each transaction against the serial
+// execution of the blocks.
+func TestBlockSTM(t *testing.T) {
+	gspec, key, generate := conflictingChain(t)
+	m := mock.MockWithGenesis(t, gspec, key, false)
+	chain := generate(m, 3)
+	require.NoError(t, m.InsertChain(chain))
+
+	tx, err := m.DB.BeginRo(m.Ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	blockSTM := exec3.NewBlockSTM(m.Ctx, log.New(), m.ChainConfig, m.Engine, vm.Config{}, 4)
+	for i, block := range chain.Blocks {
+		header := block.Header()
+		blockNum := block.NumberU64()
+		minTxNum, err := rawdbv3.TxNums.Min(tx, blockNum)
+		require.NoError(t, err)
+
+		// the state once the block is initialised, before its first transaction
+		base := state.NewHistoryReaderV3()
+		base.SetTx(tx)
+		base.SetTxNum(minTxNum + 1)
+
+		txs := block.Transactions()
+		receipts := make(types.Receipts, len(txs))
+		blockContext := core.NewEVMBlockContext(header, core.GetHashFn(header, nil), m.Engine, nil, m.ChainConfig)
+		blockSigner := *types.MakeSigner(m.ChainConfig, blockNum, header.Time)
+		tasks := make([]*state.TxTask, len(txs))
+		for txIndex, txn := range txs {
+			txTask := &state.TxTask{
+				BlockNum:        blockNum,
+				Header:          header,
+				Coinbase:        block.Coinbase(),
+				Rules:           m.ChainConfig.Rules(blockNum, header.Time),
+				Txs:             txs,
+				TxNum:           minTxNum + 1 + uint64(txIndex),
+				TxIndex:         txIndex,
+				BlockHash:       block.Hash(),
+				EvmBlockContext: blockContext,
+				BlockReceipts:   receipts,
+				Config:          m.ChainConfig,
+				Tx:              txn,
+			}
+			txTask.TxAsMessage, err = txn.AsMessage(blockSigner, header.BaseFee, txTask.Rules)
+			require.NoError(t, err)
+			sender, err := blockSigner.Sender(txn)
+			require.NoError(t, err)
+			txTask.Sender = &sender
+			tasks[txIndex] = txTask
+		}
+		require.NoError(t, blockSTM.Execute(m.Ctx, tasks, base))
+
+		for _, txTask := range tasks {
+			require.NoError(t, txTask.Error)
+			txTask.CreateReceipt(tx)
+
+			before, after := state.NewHistoryReaderV3(), state.NewHistoryReaderV3()
+			before.SetTx(tx)
+			before.SetTxNum(txTask.TxNum)
+			after.SetTx(tx)
+			after.SetTxNum(txTask.TxNum + 1)
+			writes := &checkedWrites{accounts: map[libcommon.Address]*accounts.Account{}, storage: map[libcommon.Address]map[libcommon.Hash]uint256.Int{}}
+			require.NoError(t, txTask.VersionedWrites.Apply(writes))
+			for address, increase := range txTask.BalanceIncreaseSet {
+				account, ok := writes.accounts[address]
+				if !ok {
+					account, err = before.ReadAccountData(address)
+					require.NoError(t, err)
+				}
+				if account == nil {
+					created := accounts.NewAccount()
+					account = &created
+				}
+				writes.accounts[address] = account
+				account.Balance.Add(&account.Balance, &increase)
+			}
+			for address, account := range writes.accounts {
+				want, err := after.ReadAccountData(address)
+				require.NoError(t, err)
+				if account == nil {
+					require.Nil(t, want, "block %d, tx %d, %x", blockNum, txTask.TxIndex, address)
+					continue
+				}
+				require.NotNil(t, want, "block %d, tx %d, %x", blockNum, txTask.TxIndex, address)
+				require.Equal(t, want.Nonce, account.Nonce, "block %d, tx %d, %x", blockNum, txTask.TxIndex, address)
+				require.Equal(t, want.Balance, account.Balance, "block %d, tx %d, %x", blockNum, txTask.TxIndex, address)
+				require.Equal(t, want.CodeHash, account.CodeHash, "block %d, tx %d, %x", blockNum, txTask.TxIndex, address)
+			}
+			for address, slots := range writes.storage {
+				for key, value := range slots {
+					want, err := after.ReadAccountStorage(address, 0, &key)
+					require.NoError(t, err)
+					require.Equal(t, new(uint256.Int).SetBytes(want), &value, "block %d, tx %d, %x %x", blockNum, txTask.TxIndex, address, key)
+				}
+			}
+		}
+		require.Equal(t, chain.Receipts[i].Len(), receipts.Len())
+		for j, receipt := range receipts {
+			require.Equal(t, chain.Receipts[i][j].Status, receipt.Status)
+			require.Equal(t, chain.Receipts[i][j].CumulativeGasUsed, receipt.CumulativeGasUsed)
+		}
+		require.Equal(t, block.ReceiptHash(), types.DeriveSha(receipts))
+	}
+}
+
+// TestExecV3BlockSTM executes blocks of conflicting transactions with ExecV3,
+// serially and with BlockSTM, and compares the state roots after each block.
+func TestExecV3BlockSTM(t *testing.T) {
+	gspec, key, generate := conflictingChain(t)
+	serial := mock.MockWithGenesis(t, gspec, key, false)
+	syncCfg := ethconfig.Defaults.Sync
+	syncCfg.ExecBlockSTM = true
+	parallel := mock.MockWithSyncConfig(t, gspec, key, syncCfg)
+
+	blockSTMTxs := func() uint64 {
+		return metrics.GetOrCreateCounter(`exec_blockstm_txs{result="valid"}`).GetValueUint64() +
+			metrics.GetOrCreateCounter(`exec_blockstm_txs{result="reexecuted"}`).GetValueUint64()
+	}
+	executed := blockSTMTxs()
+	chain := generate(serial, 3)
+	for i := range chain.Blocks {
+		require.NoError(t, serial.InsertChain(chain.Slice(i, i+1)))
+		require.NoError(t, parallel.InsertChain(chain.Slice(i, i+1)))
+		root := stateRoot(t, serial)
+		require.Equal(t, chain.Headers[i].Root, root, "block %d", chain.Headers[i].Number)
+		require.Equal(t, root, stateRoot(t, parallel), "block %d", chain.Headers[i].Number)
+	}
+	require.Greater(t, blockSTMTxs(), executed)
+}
+
+// stateRoot returns the root of the latest state of the mock.
+func stateRoot(t *testing.T, m *mock.MockSentry) libcommon.Hash {
+	tx, err := m.DB.BeginRo(m.Ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	doms, err := state2.NewSharedDomains(tx, log.New())
+	require.NoError(t, err)
+	defer doms.Close()
+	root, err := doms.ComputeCommitment(m.Ctx, false, doms.BlockNum(), "")
+	require.NoError(t, err)
+	return libcommon.BytesToHash(root)
+}
+
+// checkedWrites collects the final writes of a transaction.
+type checkedWrites struct {
+	accounts map[libcommon.Address]*accounts.Account // nil if deleted
+	storage  map[libcommon.Address]map[libcommon.Hash]uint256.Int
+}
+
+func (w *checkedWrites) UpdateAccountData(address libcommon.Address, original, account *accounts.Account) error {
+	copied := *account
+	w.accounts[address] = &copied
+	return nil
+}
+
+func (w *checkedWrites) UpdateAccountCode(address libcommon.Address, incarnation uint64, codeHash libcommon.Hash, code []byte) error {
+	return nil
+}
+
+func (w *checkedWrites) DeleteAccount(address libcommon.Address, original *accounts.Account) error {
+	w.accounts[address] = nil
+	delete(w.storage, address)
+	return nil
+}
+
+func (w *checkedWrites) WriteAccountStorage(address libcommon.Address, incarnation uint64, key *libcommon.Hash, original, value *uint256.Int) error {
+	if w.storage[address] == nil {
+		w.storage[address] = map[libcommon.Hash]uint256.Int{}
+	}
+	w.storage[address][*key] = *value
+	return nil
+}
+
+func (w *checkedWrites) CreateContract(address libcommon.Address) error {
+	return nil
+}
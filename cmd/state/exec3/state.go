@@ -104,6 +104,13 @@ func NewWorker(lock sync.Locker, logger log.Logger, ctx context.Context, backgro
 
 func (rw *Worker) LogLRUStats() { rw.evm.JumpDestCache.LogStats() }
 
+// SetVMConfig sets the configuration of the EVM, the call tracer of the worker
+// remaining its tracer.
+func (rw *Worker) SetVMConfig(vmCfg vm.Config) {
+	vmCfg.Debug, vmCfg.Tracer = true, rw.callTracer
+	rw.vmCfg = vmCfg
+}
+
 func (rw *Worker) ResetState(rs *state.StateV3, accumulator *shards.Accumulator) {
 	rw.rs = rs
 	if rw.background {
@@ -251,36 +258,7 @@ func (rw *Worker) RunTxTaskNoLock(txTask *state.TxTask, isMining bool) {
 			}
 		}
 	default:
-		rw.taskGasPool.Reset(txTask.Tx.GetGas(), rw.chainConfig.GetMaxBlobGasPerBlock())
-		rw.callTracer.Reset()
-		rw.vmCfg.SkipAnalysis = txTask.SkipAnalysis
-		ibs.SetTxContext(txTask.TxIndex)
-		msg := txTask.TxAsMessage
-		if msg.FeeCap().IsZero() && rw.engine != nil {
-			// Only zero-gas transactions may be service ones
-			syscall := func(contract libcommon.Address, data []byte) ([]byte, error) {
-				return core.SysCallContract(contract, data, rw.chainConfig, ibs, header, rw.engine, true /* constCall */)
-			}
-			msg.SetIsFree(rw.engine.IsServiceTransaction(msg.From(), syscall))
-		}
-
-		rw.evm.ResetBetweenBlocks(txTask.EvmBlockContext, core.NewEVMTxContext(msg), ibs, rw.vmCfg, rules)
-
-		// MA applytx
-		applyRes, err := core.ApplyMessage(rw.evm, msg, rw.taskGasPool, true /* refunds */, false /* gasBailout */)
-		if err != nil {
-			txTask.Error = err
-		} else {
-			txTask.Failed = applyRes.Failed()
-			txTask.UsedGas = applyRes.UsedGas
-			// Update the state with pending changes
-			ibs.SoftFinalise()
-			//txTask.Error = ibs.FinalizeTx(rules, noop)
-			txTask.Logs = ibs.GetLogs(txTask.TxIndex, txTask.Tx.Hash(), txTask.BlockNum, txTask.BlockHash)
-			txTask.TraceFroms = rw.callTracer.Froms()
-			txTask.TraceTos = rw.callTracer.Tos()
-		}
-
+		rw.runTx(txTask, ibs, rules)
 	}
 	// Prepare read set, write set and balanceIncrease set and send for serialisation
 	if txTask.Error == nil {
@@ -297,6 +275,39 @@ func (rw *Worker) RunTxTaskNoLock(txTask *state.TxTask, isMining bool) {
 	}
 }
 
+// runTx executes a transaction of the block on ibs.
+func (rw *Worker) runTx(txTask *state.TxTask, ibs *state.IntraBlockState, rules *chain.Rules) {
+	rw.taskGasPool.Reset(txTask.Tx.GetGas(), rw.chainConfig.GetMaxBlobGasPerBlock())
+	rw.callTracer.Reset()
+	rw.vmCfg.SkipAnalysis = txTask.SkipAnalysis
+	ibs.SetTxContext(txTask.TxIndex)
+	msg := txTask.TxAsMessage
+	if msg.FeeCap().IsZero() && rw.engine != nil {
+		// Only zero-gas transactions may be service ones
+		syscall := func(contract libcommon.Address, data []byte) ([]byte, error) {
+			return core.SysCallContract(contract, data, rw.chainConfig, ibs, txTask.Header, rw.engine, true /* constCall */)
+		}
+		msg.SetIsFree(rw.engine.IsServiceTransaction(msg.From(), syscall))
+	}
+
+	rw.evm.ResetBetweenBlocks(txTask.EvmBlockContext, core.NewEVMTxContext(msg), ibs, rw.vmCfg, rules)
+
+	// MA applytx
+	applyRes, err := core.ApplyMessage(rw.evm, msg, rw.taskGasPool, true /* refunds */, false /* gasBailout */)
+	if err != nil {
+		txTask.Error = err
+	} else {
+		txTask.Failed = applyRes.Failed()
+		txTask.UsedGas = applyRes.UsedGas
+		// Update the state with pending changes
+		ibs.SoftFinalise()
+		//txTask.Error = ibs.FinalizeTx(rules, noop)
+		txTask.Logs = ibs.GetLogs(txTask.TxIndex, txTask.Tx.Hash(), txTask.BlockNum, txTask.BlockHash)
+		txTask.TraceFroms = rw.callTracer.Froms()
+		txTask.TraceTos = rw.callTracer.Tos()
+	}
+}
+
 func NewWorkersPool(lock sync.Locker, accumulator *shards.Accumulator, logger log.Logger, ctx context.Context, background bool, chainDb kv.RoDB, rs *state.StateV3, in *state.QueueWithRetry, blockReader services.FullBlockReader, chainConfig *chain.Config, genesis *types.Genesis, engine consensus.Engine, workerCount int, dirs datadir.Dirs, isMining bool) (reconWorkers []*Worker, applyWorker *Worker, rws *state.ResultsQueue, clear func(), wait func()) {
 	reconWorkers = make([]*Worker, workerCount)
 
@@ -49,6 +49,7 @@ import (
 	downloadercfg2 "github.com/erigontech/erigon-lib/downloader/downloadercfg"
 	"github.com/erigontech/erigon-lib/txpool/txpoolcfg"
 
+	"github.com/erigontech/erigon/cl/beacon/beacon_router_configuration"
 	"github.com/erigontech/erigon/cl/clparams"
 	"github.com/erigontech/erigon/cmd/downloader/downloadernat"
 	"github.com/erigontech/erigon/cmd/utils/flags"
@@ -866,6 +867,80 @@ var (
 		Usage: "Enable caplin validator monitoring metrics",
 		Value: false,
 	}
+	CaplinValidatorMonitorIndiciesFlag = cli.Uint64SliceFlag{
+		Name:  "caplin.validator-monitor.indices",
+		Usage: "Comma separated validator indices to observe with the validator monitor, implies --caplin.validator-monitor",
+	}
+	CaplinLightClientFlag = cli.BoolFlag{
+		Name:  "caplin.light-client",
+		Usage: "Run Caplin as a light client: follow the chain with light client updates only and drive the execution engine's forkchoice (requires --caplin.light-client.trusted-root)",
+		Value: false,
+	}
+	CaplinLightClientTrustedRootFlag = cli.StringFlag{
+		Name:  "caplin.light-client.trusted-root",
+		Usage: "Trusted beacon block root to bootstrap the light client from, ideally a recent finalized checkpoint root",
+		Value: "",
+	}
+	CaplinValidatorKeystoreDirFlag = cli.StringFlag{
+		Name:  "caplin.validator.keystore-dir",
+		Usage: "Directory with EIP-2335 keystores. Caplin runs in-process validator client if this is set (requires --beacon.api=beacon,validator)",
+		Value: "",
+	}
+	CaplinValidatorPasswordFileFlag = cli.StringFlag{
+		Name:  "caplin.validator.password-file",
+		Usage: "File with password of keystores in --caplin.validator.keystore-dir",
+		Value: "",
+	}
+	CaplinValidatorFeeRecipientFlag = cli.StringFlag{
+		Name:  "caplin.validator.fee-recipient",
+		Usage: "Fee recipient of blocks proposed by in-process validator client",
+		Value: "",
+	}
+	CaplinValidatorGraffitiFlag = cli.StringFlag{
+		Name:  "caplin.validator.graffiti",
+		Usage: "Graffiti of blocks proposed by in-process validator client",
+		Value: "",
+	}
+	CaplinValidatorRemoteSignerURLFlag = cli.StringFlag{
+		Name:  "caplin.validator.remote-signer-url",
+		Usage: "Web3Signer compatible remote signer. In-process validator client signs with all keys it has",
+		Value: "",
+	}
+	CaplinValidatorDoppelgangerEpochsFlag = cli.Uint64Flag{
+		Name:  "caplin.validator.doppelganger-epochs",
+		Usage: "Amount of epochs to watch for activity of the same validators elsewhere before signing anything (0 - disabled)",
+		Value: 2,
+	}
+	CaplinValidatorSlashingProtectionImportFlag = cli.StringFlag{
+		Name:  "caplin.validator.slashing-protection-import",
+		Usage: "EIP-3076 slashing protection interchange file to import on start",
+		Value: "",
+	}
+	CaplinValidatorSlashingProtectionExportFlag = cli.StringFlag{
+		Name:  "caplin.validator.slashing-protection-export",
+		Usage: "EIP-3076 slashing protection interchange file to export on stop",
+		Value: "",
+	}
+	CaplinKeymanagerFlag = cli.BoolFlag{
+		Name:  "caplin.keymanager",
+		Usage: "Enable keymanager API of in-process validator client (requires --beacon.api=beacon,validator)",
+		Value: false,
+	}
+	CaplinKeymanagerAddrFlag = cli.StringFlag{
+		Name:  "caplin.keymanager.addr",
+		Usage: "sets the host to listen for keymanager api requests",
+		Value: "localhost",
+	}
+	CaplinKeymanagerPortFlag = cli.UintFlag{
+		Name:  "caplin.keymanager.port",
+		Usage: "sets the port to listen for keymanager api requests",
+		Value: 5556,
+	}
+	CaplinKeymanagerTokenFileFlag = cli.StringFlag{
+		Name:  "caplin.keymanager.token-file",
+		Usage: "File with bearer token of keymanager api, a random token is written there if it doesn't exist (default: <datadir>/caplin/validator/api-token.txt)",
+		Value: "",
+	}
 	CaplinMaxPeerCount = cli.Uint64Flag{
 		Name:  "caplin.max-peer-count",
 		Usage: "Max number of peers to connect",
@@ -1733,6 +1808,39 @@ func setBeaconAPI(ctx *cli.Context, cfg *ethconfig.Config) error {
 	return nil
 }
 
+// SetCaplinValidatorClient - in-process validator client settings, shared by erigon and standalone caplin
+func SetCaplinValidatorClient(ctx *cli.Context, cfg *clparams.CaplinConfig) {
+	cfg.ValidatorKeystoreDir = ctx.String(CaplinValidatorKeystoreDirFlag.Name)
+	cfg.ValidatorPasswordFile = ctx.String(CaplinValidatorPasswordFileFlag.Name)
+	cfg.ValidatorFeeRecipient = libcommon.HexToAddress(ctx.String(CaplinValidatorFeeRecipientFlag.Name))
+	cfg.ValidatorGraffiti = ctx.String(CaplinValidatorGraffitiFlag.Name)
+	cfg.ValidatorRemoteSignerURL = ctx.String(CaplinValidatorRemoteSignerURLFlag.Name)
+	cfg.ValidatorDoppelgangerEpochs = ctx.Uint64(CaplinValidatorDoppelgangerEpochsFlag.Name)
+	cfg.ValidatorSlashingProtectionImport = ctx.String(CaplinValidatorSlashingProtectionImportFlag.Name)
+	cfg.ValidatorSlashingProtectionExport = ctx.String(CaplinValidatorSlashingProtectionExportFlag.Name)
+	cfg.KeymanagerAPI = beacon_router_configuration.KeymanagerConfiguration{
+		Active:          ctx.Bool(CaplinKeymanagerFlag.Name),
+		Protocol:        "tcp",
+		Address:         fmt.Sprintf("%s:%d", ctx.String(CaplinKeymanagerAddrFlag.Name), ctx.Uint(CaplinKeymanagerPortFlag.Name)),
+		TokenFile:       ctx.String(CaplinKeymanagerTokenFileFlag.Name),
+		ReadTimeTimeout: 30 * time.Second,
+		IdleTimeout:     60 * time.Second,
+		WriteTimeout:    5 * time.Minute, // keystores decryption is slow
+	}
+}
+
+// SetCaplinValidatorMonitor - validator monitor settings, shared by erigon and standalone caplin
+func SetCaplinValidatorMonitor(ctx *cli.Context, cfg *clparams.CaplinConfig) {
+	cfg.ValidatorMonitorIndicies = ctx.Uint64Slice(CaplinValidatorMonitorIndiciesFlag.Name)
+	cfg.EnableValidatorMonitor = ctx.Bool(CaplinValidatorMonitorFlag.Name) || len(cfg.ValidatorMonitorIndicies) > 0
+}
+
+// SetCaplinLightClient - light client sync mode settings, shared by erigon and standalone caplin
+func SetCaplinLightClient(ctx *cli.Context, cfg *clparams.CaplinConfig) {
+	cfg.LightClient = ctx.Bool(CaplinLightClientFlag.Name)
+	cfg.LightClientTrustedRoot = libcommon.HexToHash(ctx.String(CaplinLightClientTrustedRootFlag.Name))
+}
+
 func setCaplin(ctx *cli.Context, cfg *ethconfig.Config) {
 	// Caplin's block's backfilling is enabled if any of the following flags are set
 	cfg.CaplinConfig.Backfilling = ctx.Bool(CaplinBackfillingFlag.Name) || ctx.Bool(CaplinArchiveFlag.Name) || ctx.Bool(CaplinBlobBackfillingFlag.Name)
@@ -1743,7 +1851,9 @@ func setCaplin(ctx *cli.Context, cfg *ethconfig.Config) {
 	cfg.CaplinConfig.DisabledCheckpointSync = ctx.Bool(CaplinDisableCheckpointSyncFlag.Name)
 	cfg.CaplinConfig.Archive = ctx.Bool(CaplinArchiveFlag.Name)
 	cfg.CaplinConfig.MevRelayUrl = ctx.String(CaplinMevRelayUrl.Name)
-	cfg.CaplinConfig.EnableValidatorMonitor = ctx.Bool(CaplinValidatorMonitorFlag.Name)
+	SetCaplinValidatorMonitor(ctx, &cfg.CaplinConfig)
+	SetCaplinValidatorClient(ctx, &cfg.CaplinConfig)
+	SetCaplinLightClient(ctx, &cfg.CaplinConfig)
 	if checkpointUrls := ctx.StringSlice(CaplinCheckpointSyncUrlFlag.Name); len(checkpointUrls) > 0 {
 		clparams.ConfigurableCheckpointsURLs = checkpointUrls
 	}
@@ -28,6 +28,7 @@ import (
 	"math/big"
 	"math/rand"
 	"net/http"
+	"runtime"
 	"sync"
 	"time"
 
@@ -72,6 +73,44 @@ func (ethash *Ethash) Seal(chain consensus.ChainHeaderReader, block *types.Block
 	return nil
 }
 
+// SealLocally searches on the local CPU for a nonce satisfying the difficulty of the header, and returns
+// the sealed copy of it. Unlike Seal it blocks until done, which suits tools assembling single blocks.
+func (ethash *Ethash) SealLocally(header *types.Header, stop <-chan struct{}) (*types.Header, error) {
+	// If we're running a shared PoW, delegate sealing to it
+	if ethash.shared != nil {
+		return ethash.shared.SealLocally(header, stop)
+	}
+	if header.Difficulty == nil || header.Difficulty.Sign() <= 0 {
+		return nil, errInvalidDifficulty
+	}
+	var (
+		hash    = ethash.SealHash(header).Bytes()
+		target  = new(big.Int).Div(two256, header.Difficulty)
+		dataset = ethash.dataset(header.Number.Uint64(), false)
+	)
+	// Datasets are unmapped in a finalizer. Ensure that the dataset stays alive
+	// until the search is over so it's not unmapped while being used.
+	defer runtime.KeepAlive(dataset)
+
+	for nonce := uint64(0); ; nonce++ {
+		select {
+		case <-stop:
+			return nil, errors.New("ethash sealing aborted")
+		default:
+		}
+		digest, result := hashimotoFull(dataset.dataset, hash, nonce)
+		if new(big.Int).SetBytes(result).Cmp(target) <= 0 {
+			sealed := types.CopyHeader(header)
+			sealed.Nonce = types.EncodeNonce(nonce)
+			sealed.MixDigest = libcommon.BytesToHash(digest)
+			return sealed, nil
+		}
+		if nonce == math.MaxUint64 {
+			return nil, errors.New("ethash nonce space exhausted")
+		}
+	}
+}
+
 // This is the timeout for HTTP requests to notify external miners.
 const remoteSealerTimeout = 1 * time.Second
 
@@ -86,6 +86,29 @@ func TestRemoteNotify(t *testing.T) {
 	}
 }
 
+// Tests that a block sealed on the local CPU passes the seal verification.
+func TestSealLocally(t *testing.T) {
+	ethash := NewTester(nil, false)
+	defer ethash.Close()
+
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+	sealed, err := ethash.SealLocally(header, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ethash.VerifySeal(nil, sealed); err != nil {
+		t.Fatalf("sealed header failed verification: %v", err)
+	}
+	if header.MixDigest != (libcommon.Hash{}) || header.Nonce != (types.BlockNonce{}) {
+		t.Fatalf("original header modified")
+	}
+	stop := make(chan struct{})
+	close(stop)
+	if _, err := ethash.SealLocally(&types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(1 << 62)}, stop); err == nil {
+		t.Fatalf("aborted sealing succeeded")
+	}
+}
+
 // Tests whether remote HTTP servers are correctly notified of new work. (Full pending block body / --miner.notify.full)
 func TestRemoteNotifyFull(t *testing.T) {
 	// Start a simple web server to capture notifications.
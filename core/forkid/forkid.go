@@ -243,6 +243,12 @@ func GatherForks(config *chain.Config, genesisTime uint64) (heightForks []uint64
 		}
 	}
 
+	for _, p := range config.Precompiles {
+		if p.Time != nil && p.Time.Uint64() > genesisTime {
+			timeForks = append(timeForks, p.Time.Uint64())
+		}
+	}
+
 	if config.Aura != nil && config.Aura.PosdaoTransition != nil {
 		heightForks = append(heightForks, *config.Aura.PosdaoTransition)
 	}
@@ -52,6 +52,7 @@ import (
 	"github.com/erigontech/erigon/core/state"
 	"github.com/erigontech/erigon/core/tracing"
 	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/vm"
 	"github.com/erigontech/erigon/crypto"
 	"github.com/erigontech/erigon/params"
 )
@@ -157,6 +158,9 @@ func WriteGenesisBlock(tx kv.RwTx, genesis *types.Genesis, overridePragueTime *b
 	if err := newCfg.CheckConfigForkOrder(); err != nil {
 		return newCfg, nil, err
 	}
+	if err := vm.CheckPrecompiles(newCfg); err != nil {
+		return newCfg, nil, err
+	}
 	storedCfg, storedErr := rawdb.ReadChainConfig(tx, storedHash)
 	if storedErr != nil && newCfg.Bor == nil {
 		return newCfg, nil, storedErr
@@ -175,6 +179,9 @@ func WriteGenesisBlock(tx kv.RwTx, genesis *types.Genesis, overridePragueTime *b
 	if genesis == nil && params.ChainConfigByGenesisHash(storedHash) == nil {
 		newCfg = storedCfg
 		applyOverrides(newCfg)
+		if err := vm.CheckPrecompiles(newCfg); err != nil {
+			return newCfg, nil, err
+		}
 	}
 	// Check config compatibility and write the config. Compatibility errors
 	// are returned to the caller unless we're already at block zero.
@@ -241,6 +248,9 @@ func write(tx kv.RwTx, g *types.Genesis, dirs datadir.Dirs, logger log.Logger) (
 	if err := config.CheckConfigForkOrder(); err != nil {
 		return nil, nil, err
 	}
+	if err := vm.CheckPrecompiles(config); err != nil {
+		return nil, nil, err
+	}
 
 	if err := rawdb.WriteBlock(tx, block); err != nil {
 		return nil, nil, err
@@ -71,6 +71,7 @@ type TxTask struct {
 	Logs               []*types.Log
 	TraceFroms         map[libcommon.Address]struct{}
 	TraceTos           map[libcommon.Address]struct{}
+	VersionedWrites    *VersionedWrites // writes of the txn when executed by BlockSTM, to apply on commit
 
 	UsedGas uint64
 
@@ -149,6 +150,7 @@ func (t *TxTask) Reset() {
 	t.Logs = nil
 	t.TraceFroms = nil
 	t.TraceTos = nil
+	t.VersionedWrites = nil
 }
 
 // TxTaskQueue non-thread-safe priority-queue
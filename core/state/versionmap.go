@@ -0,0 +1,485 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/holiman/uint256"
+
+	"github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/core/types/accounts"
+)
+
+// VersionMap is the multi-version memory of a block executed by Block-STM. It
+// holds the writes of each transaction of the block, so that a transaction
+// reads the writes of the transactions before it instead of the state at the
+// beginning of the block.
+//
+// Values are kept encoded as in the domains, so that a transaction reads what
+// it would read once the writes of the previous ones are applied to the
+// domains. The state at the beginning of the block does not change while the
+// block executes, so the values read from it are cached.
+type VersionMap struct {
+	lock         sync.RWMutex
+	emptyRemoval bool
+	txs          []*txVersion
+	writers      map[common.Address][]int // indexes of the txs writing an account, its code or its storage, in order
+
+	baseAccounts map[common.Address][]byte
+	baseStorage  map[storageKey][]byte
+	baseCode     map[common.Address][]byte
+}
+
+type storageKey struct {
+	address common.Address
+	key     common.Hash
+}
+
+// txVersion is what a transaction wrote, as seen by the transactions after it.
+type txVersion struct {
+	accounts map[common.Address]accountVersion
+	code     map[common.Address][]byte
+	storage  map[storageKey][]byte
+	cleared  map[common.Address]struct{} // accounts whose storage was deleted before the writes in storage
+}
+
+type accountVersion struct {
+	enc        []byte // nil for a deleted account
+	increase   uint256.Int
+	isIncrease bool // the balance increased without the account being read
+}
+
+// NewVersionMap creates the multi-version memory of a block of txCount
+// transactions. emptyRemoval is set if empty accounts are deleted (EIP-161).
+func NewVersionMap(txCount int, emptyRemoval bool) *VersionMap {
+	return &VersionMap{
+		emptyRemoval: emptyRemoval,
+		txs:          make([]*txVersion, txCount),
+		writers:      map[common.Address][]int{},
+		baseAccounts: map[common.Address][]byte{},
+		baseStorage:  map[storageKey][]byte{},
+		baseCode:     map[common.Address][]byte{},
+	}
+}
+
+// Publish makes the writes of an execution of a transaction, and the balance
+// increases it left to apply, visible to the transactions after it. They
+// replace those of any previous execution of the transaction.
+func (vm *VersionMap) Publish(txIndex int, writes *VersionedWrites, balanceIncreases map[common.Address]uint256.Int) {
+	v := &txVersion{
+		accounts: map[common.Address]accountVersion{},
+		code:     map[common.Address][]byte{},
+		storage:  map[storageKey][]byte{},
+		cleared:  map[common.Address]struct{}{},
+	}
+	clearStorage := func(address common.Address) {
+		v.cleared[address] = struct{}{}
+		for k := range v.storage {
+			if k.address == address {
+				delete(v.storage, k)
+			}
+		}
+	}
+	// The effects of the writes are those of StateWriterV3 on the domains.
+	for i := range writes.ops {
+		w := &writes.ops[i]
+		switch w.op {
+		case opUpdateAccount:
+			if w.original.Incarnation > w.account.Incarnation {
+				v.code[w.address] = nil
+				clearStorage(w.address)
+			}
+			v.accounts[w.address] = accountVersion{enc: accounts.SerialiseV3(&w.account)}
+		case opUpdateCode:
+			v.code[w.address] = w.code
+		case opDeleteAccount:
+			clearStorage(w.address)
+			v.code[w.address] = nil
+			v.accounts[w.address] = accountVersion{}
+		case opWriteStorage:
+			if w.originalValue == w.value {
+				continue
+			}
+			var enc []byte
+			if !w.value.IsZero() {
+				enc = w.value.Bytes()
+			}
+			v.storage[storageKey{w.address, w.key}] = enc
+		}
+	}
+	for address, increase := range balanceIncreases {
+		v.accounts[address] = accountVersion{increase: increase, isIncrease: true}
+	}
+
+	vm.lock.Lock()
+	defer vm.lock.Unlock()
+	if prev := vm.txs[txIndex]; prev != nil {
+		for address := range prev.addresses() {
+			writers := vm.writers[address]
+			i := sort.SearchInts(writers, txIndex)
+			vm.writers[address] = append(writers[:i], writers[i+1:]...)
+		}
+	}
+	vm.txs[txIndex] = v
+	for address := range v.addresses() {
+		writers := vm.writers[address]
+		i := sort.SearchInts(writers, txIndex)
+		writers = append(writers, 0)
+		copy(writers[i+1:], writers[i:])
+		writers[i] = txIndex
+		vm.writers[address] = writers
+	}
+}
+
+func (v *txVersion) addresses() map[common.Address]struct{} {
+	addresses := make(map[common.Address]struct{}, len(v.accounts)+len(v.code)+len(v.cleared))
+	for address := range v.accounts {
+		addresses[address] = struct{}{}
+	}
+	for address := range v.code {
+		addresses[address] = struct{}{}
+	}
+	for k := range v.storage {
+		addresses[k.address] = struct{}{}
+	}
+	for address := range v.cleared {
+		addresses[address] = struct{}{}
+	}
+	return addresses
+}
+
+// writersBefore returns the indexes of the transactions before txIndex that
+// wrote the account, its code or its storage, in order. The lock must be held.
+func (vm *VersionMap) writersBefore(txIndex int, address common.Address) []int {
+	writers := vm.writers[address]
+	return writers[:sort.SearchInts(writers, txIndex)]
+}
+
+// account returns the encoded account as the transaction at txIndex reads it.
+func (vm *VersionMap) account(txIndex int, address common.Address, base StateReader) ([]byte, error) {
+	var (
+		enc       []byte
+		found     bool
+		increases []uint256.Int
+	)
+	vm.lock.RLock()
+	writers := vm.writersBefore(txIndex, address)
+	for i := len(writers) - 1; i >= 0; i-- {
+		a, ok := vm.txs[writers[i]].accounts[address]
+		if !ok {
+			continue
+		}
+		if a.isIncrease {
+			increases = append(increases, a.increase)
+			continue
+		}
+		enc, found = a.enc, true
+		break
+	}
+	if !found {
+		enc, found = vm.baseAccounts[address]
+	}
+	vm.lock.RUnlock()
+
+	if !found {
+		acc, err := base.ReadAccountData(address)
+		if err != nil {
+			return nil, err
+		}
+		if acc != nil {
+			enc = accounts.SerialiseV3(acc)
+		}
+		vm.lock.Lock()
+		vm.baseAccounts[address] = enc
+		vm.lock.Unlock()
+	}
+	// Balance increases apply in order, as StateV3.ApplyState4 does.
+	for i := len(increases) - 1; i >= 0; i-- {
+		var acc accounts.Account
+		acc.Reset()
+		if len(enc) > 0 {
+			if err := accounts.DeserialiseV3(&acc, enc); err != nil {
+				return nil, err
+			}
+		}
+		acc.Balance.Add(&acc.Balance, &increases[i])
+		if vm.emptyRemoval && acc.Nonce == 0 && acc.Balance.IsZero() && acc.IsEmptyCodeHash() {
+			enc = nil
+		} else {
+			enc = accounts.SerialiseV3(&acc)
+		}
+	}
+	return enc, nil
+}
+
+// storage returns the storage slot as the transaction at txIndex reads it.
+func (vm *VersionMap) storage(txIndex int, address common.Address, incarnation uint64, key common.Hash, base StateReader) ([]byte, error) {
+	k := storageKey{address, key}
+	vm.lock.RLock()
+	writers := vm.writersBefore(txIndex, address)
+	for i := len(writers) - 1; i >= 0; i-- {
+		v := vm.txs[writers[i]]
+		if enc, ok := v.storage[k]; ok {
+			vm.lock.RUnlock()
+			return enc, nil
+		}
+		if _, ok := v.cleared[address]; ok {
+			vm.lock.RUnlock()
+			return nil, nil
+		}
+	}
+	enc, ok := vm.baseStorage[k]
+	vm.lock.RUnlock()
+	if ok {
+		return enc, nil
+	}
+
+	enc, err := base.ReadAccountStorage(address, incarnation, &key)
+	if err != nil {
+		return nil, err
+	}
+	enc = common.Copy(enc)
+	vm.lock.Lock()
+	vm.baseStorage[k] = enc
+	vm.lock.Unlock()
+	return enc, nil
+}
+
+// code returns the code of the account as the transaction at txIndex reads it.
+func (vm *VersionMap) code(txIndex int, address common.Address, incarnation uint64, codeHash common.Hash, base StateReader) ([]byte, error) {
+	vm.lock.RLock()
+	writers := vm.writersBefore(txIndex, address)
+	for i := len(writers) - 1; i >= 0; i-- {
+		if code, ok := vm.txs[writers[i]].code[address]; ok {
+			vm.lock.RUnlock()
+			return code, nil
+		}
+	}
+	code, ok := vm.baseCode[address]
+	vm.lock.RUnlock()
+	if ok {
+		return code, nil
+	}
+
+	code, err := base.ReadAccountCode(address, incarnation, codeHash)
+	if err != nil {
+		return nil, err
+	}
+	code = common.Copy(code)
+	vm.lock.Lock()
+	vm.baseCode[address] = code
+	vm.lock.Unlock()
+	return code, nil
+}
+
+// NewReader returns the state reader of an execution of the transaction at
+// txIndex, reading from base what no transaction before it wrote.
+func (vm *VersionMap) NewReader(txIndex int, base StateReader) *VersionedReader {
+	return &VersionedReader{
+		versions: vm,
+		txIndex:  txIndex,
+		base:     base,
+		accounts: map[common.Address][]byte{},
+		storage:  map[storageKey][]byte{},
+		code:     map[common.Address][]byte{},
+	}
+}
+
+// VersionedReader is the StateReader of a transaction executed by Block-STM.
+// It records the values the transaction reads, which stay the same for the
+// whole execution, to validate them once the transactions before it are
+// committed.
+type VersionedReader struct {
+	versions *VersionMap
+	txIndex  int
+	base     StateReader
+
+	accounts map[common.Address][]byte
+	storage  map[storageKey][]byte
+	code     map[common.Address][]byte
+	failed   bool // a read failed, and is missing from the values read
+}
+
+func (r *VersionedReader) ReadAccountData(address common.Address) (*accounts.Account, error) {
+	enc, ok := r.accounts[address]
+	if !ok {
+		var err error
+		if enc, err = r.versions.account(r.txIndex, address, r.base); err != nil {
+			r.failed = true
+			return nil, err
+		}
+		r.accounts[address] = enc
+	}
+	if len(enc) == 0 {
+		return nil, nil
+	}
+	var acc accounts.Account
+	if err := accounts.DeserialiseV3(&acc, enc); err != nil {
+		return nil, err
+	}
+	return &acc, nil
+}
+
+func (r *VersionedReader) ReadAccountStorage(address common.Address, incarnation uint64, key *common.Hash) ([]byte, error) {
+	k := storageKey{address, *key}
+	enc, ok := r.storage[k]
+	if !ok {
+		var err error
+		if enc, err = r.versions.storage(r.txIndex, address, incarnation, *key, r.base); err != nil {
+			r.failed = true
+			return nil, err
+		}
+		r.storage[k] = enc
+	}
+	return enc, nil
+}
+
+func (r *VersionedReader) ReadAccountCode(address common.Address, incarnation uint64, codeHash common.Hash) ([]byte, error) {
+	code, ok := r.code[address]
+	if !ok {
+		var err error
+		if code, err = r.versions.code(r.txIndex, address, incarnation, codeHash, r.base); err != nil {
+			r.failed = true
+			return nil, err
+		}
+		r.code[address] = code
+	}
+	return code, nil
+}
+
+func (r *VersionedReader) ReadAccountCodeSize(address common.Address, incarnation uint64, codeHash common.Hash) (int, error) {
+	code, err := r.ReadAccountCode(address, incarnation, codeHash)
+	return len(code), err
+}
+
+// ReadAccountIncarnation is not versioned: the incarnations are in the
+// accounts the transactions write.
+func (r *VersionedReader) ReadAccountIncarnation(address common.Address) (uint64, error) {
+	return r.base.ReadAccountIncarnation(address)
+}
+
+// Validate tells whether the transaction would still read the same values,
+// given the writes published so far by the transactions before it.
+func (r *VersionedReader) Validate(base StateReader) (bool, error) {
+	if r.failed {
+		return false, nil
+	}
+	for address, enc := range r.accounts {
+		v, err := r.versions.account(r.txIndex, address, base)
+		if err != nil || !bytes.Equal(v, enc) {
+			return false, err
+		}
+	}
+	for k, enc := range r.storage {
+		v, err := r.versions.storage(r.txIndex, k.address, 0, k.key, base)
+		if err != nil || !bytes.Equal(v, enc) {
+			return false, err
+		}
+	}
+	for address, code := range r.code {
+		v, err := r.versions.code(r.txIndex, address, 0, common.Hash{}, base)
+		if err != nil || !bytes.Equal(v, code) {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+type writeOp uint8
+
+const (
+	opUpdateAccount writeOp = iota
+	opUpdateCode
+	opDeleteAccount
+	opWriteStorage
+	opCreateContract
+)
+
+type versionedWrite struct {
+	op                   writeOp
+	address              common.Address
+	key                  common.Hash
+	incarnation          uint64
+	codeHash             common.Hash
+	code                 []byte
+	original, account    accounts.Account
+	originalValue, value uint256.Int
+}
+
+// VersionedWrites is the StateWriter recording the writes of a transaction
+// executed by Block-STM, to publish them in the VersionMap and to apply them
+// to the state once the transaction is committed.
+type VersionedWrites struct {
+	ops []versionedWrite
+}
+
+func (w *VersionedWrites) UpdateAccountData(address common.Address, original, account *accounts.Account) error {
+	w.ops = append(w.ops, versionedWrite{op: opUpdateAccount, address: address})
+	op := &w.ops[len(w.ops)-1]
+	op.original.Copy(original)
+	op.account.Copy(account)
+	return nil
+}
+
+func (w *VersionedWrites) UpdateAccountCode(address common.Address, incarnation uint64, codeHash common.Hash, code []byte) error {
+	w.ops = append(w.ops, versionedWrite{op: opUpdateCode, address: address, incarnation: incarnation, codeHash: codeHash, code: code})
+	return nil
+}
+
+func (w *VersionedWrites) DeleteAccount(address common.Address, original *accounts.Account) error {
+	w.ops = append(w.ops, versionedWrite{op: opDeleteAccount, address: address})
+	w.ops[len(w.ops)-1].original.Copy(original)
+	return nil
+}
+
+func (w *VersionedWrites) WriteAccountStorage(address common.Address, incarnation uint64, key *common.Hash, original, value *uint256.Int) error {
+	w.ops = append(w.ops, versionedWrite{op: opWriteStorage, address: address, key: *key, incarnation: incarnation, originalValue: *original, value: *value})
+	return nil
+}
+
+func (w *VersionedWrites) CreateContract(address common.Address) error {
+	w.ops = append(w.ops, versionedWrite{op: opCreateContract, address: address})
+	return nil
+}
+
+// Apply replays the writes on stateWriter, in the order they were made.
+func (w *VersionedWrites) Apply(stateWriter StateWriter) error {
+	for i := range w.ops {
+		op := &w.ops[i]
+		var err error
+		switch op.op {
+		case opUpdateAccount:
+			err = stateWriter.UpdateAccountData(op.address, &op.original, &op.account)
+		case opUpdateCode:
+			err = stateWriter.UpdateAccountCode(op.address, op.incarnation, op.codeHash, op.code)
+		case opDeleteAccount:
+			err = stateWriter.DeleteAccount(op.address, &op.original)
+		case opWriteStorage:
+			err = stateWriter.WriteAccountStorage(op.address, op.incarnation, &op.key, &op.originalValue, &op.value)
+		case opCreateContract:
+			err = stateWriter.CreateContract(op.address)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -67,6 +67,13 @@ type Contract struct {
 	CodeAddr *libcommon.Address
 	Input    []byte
 
+	// Container is the EOF container of the code, nil for legacy code. Code
+	// then holds the code section being executed, and returnStack the
+	// positions to resume at after the RETF of the enclosing CALLF calls.
+	Container   *Container
+	CodeSection uint64
+	returnStack []returnFrame
+
 	Gas   uint64
 	value *uint256.Int
 }
@@ -233,10 +240,36 @@ func (c *Contract) SetCallCode(addr *libcommon.Address, hash libcommon.Hash, cod
 	c.CodeAddr = addr
 }
 
+// SetEOFContainer sets the EOF container to run, starting with its first code section.
+func (c *Contract) SetEOFContainer(container *Container) {
+	c.Container = container
+	c.Code = container.codeSections[0]
+}
+
+// returnFrame is the position execution resumes at after a RETF.
+type returnFrame struct {
+	section uint64
+	pc      uint64
+}
+
+// setCodeSection switches execution to the given code section of the EOF container.
+func (c *Contract) setCodeSection(section uint64) {
+	c.CodeSection = section
+	c.Code = c.Container.codeSections[section]
+}
+
+// IsEOF returns whether the contract runs EOF code.
+func (c *Contract) IsEOF() bool {
+	return c.Container != nil
+}
+
 // SetCodeOptionalHash can be used to provide code, but it's optional to provide hash.
 // In case hash is not provided, the jumpdest analysis will not be saved to the parent context
 func (c *Contract) SetCodeOptionalHash(addr *libcommon.Address, codeAndHash *codeAndHash) {
 	c.Code = codeAndHash.code
 	c.CodeHash = codeAndHash.hash
 	c.CodeAddr = addr
+	if codeAndHash.container != nil {
+		c.SetEOFContainer(codeAndHash.container)
+	}
 }
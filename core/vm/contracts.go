@@ -188,8 +188,13 @@ func init() {
 	}
 }
 
-// ActivePrecompiles returns the precompiles enabled with the current configuration.
+// ActivePrecompiles returns the precompiles enabled with the current configuration,
+// including the custom precompiles of the chain.
 func ActivePrecompiles(rules *chain.Rules) []libcommon.Address {
+	return withCustomPrecompiles(rules, activeStandardPrecompiles(rules))
+}
+
+func activeStandardPrecompiles(rules *chain.Rules) []libcommon.Address {
 	switch {
 	case rules.IsPrague:
 		return PrecompiledAddressesPrague
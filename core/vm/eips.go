@@ -331,3 +331,152 @@ func enable7702(jt *JumpTable) {
 	jt[STATICCALL].dynamicGas = gasStaticCallEIP7702
 	jt[DELEGATECALL].dynamicGas = gasDelegateCallEIP7702
 }
+
+// enable3540 applies the changes of EIP-3540 (EOF v1) to legacy code:
+// - EXTCODESIZE, EXTCODECOPY and EXTCODEHASH see EOF code as the 0xEF00 magic
+func enable3540(jt *JumpTable) {
+	jt[EXTCODESIZE].execute = opExtCodeSizeEOF
+	jt[EXTCODECOPY].execute = opExtCodeCopyEOF
+	jt[EXTCODEHASH].execute = opExtCodeHashEOF
+}
+
+// enableEOF turns the instruction set into the one of EOF code:
+// - removes the instructions depending on the code layout or observing gas (EIP-3670, EIP-7069)
+// - defines the relative jumps (EIP-4200), functions (EIP-4750, EIP-6206),
+// data section access (EIP-7480), DUPN, SWAPN and EXCHANGE (EIP-663),
+// EXT*CALL and RETURNDATALOAD (EIP-7069), EOFCREATE and RETURNCONTRACT (EIP-7620)
+func enableEOF(jt *JumpTable) {
+	for _, op := range []OpCode{
+		CALL, CALLCODE, DELEGATECALL, STATICCALL, SELFDESTRUCT, JUMP, JUMPI, PC,
+		CREATE, CREATE2, CODESIZE, CODECOPY, EXTCODESIZE, EXTCODECOPY, EXTCODEHASH, GAS,
+	} {
+		jt[op] = &operation{execute: opUndefined, undefined: true}
+	}
+	// INVALID is a valid instruction of EOF code, aborting the execution.
+	jt[INVALID] = &operation{execute: opUndefined}
+	jt[RJUMP] = &operation{
+		execute:     opRjump,
+		constantGas: GasQuickStep,
+		numPop:      0,
+		numPush:     0,
+	}
+	jt[RJUMPI] = &operation{
+		execute:     opRjumpi,
+		constantGas: GasFastishStep,
+		numPop:      1,
+		numPush:     0,
+	}
+	jt[RJUMPV] = &operation{
+		execute:     opRjumpv,
+		constantGas: GasFastishStep,
+		numPop:      1,
+		numPush:     0,
+	}
+	jt[CALLF] = &operation{
+		execute:     opCallf,
+		constantGas: GasFastStep,
+		numPop:      0,
+		numPush:     0,
+	}
+	jt[RETF] = &operation{
+		execute:     opRetf,
+		constantGas: GasFastestStep,
+		numPop:      0,
+		numPush:     0,
+	}
+	jt[JUMPF] = &operation{
+		execute:     opJumpf,
+		constantGas: GasFastStep,
+		numPop:      0,
+		numPush:     0,
+	}
+	jt[DUPN] = &operation{
+		execute:     opDupN,
+		constantGas: GasFastestStep,
+		numPop:      0,
+		numPush:     1,
+	}
+	jt[SWAPN] = &operation{
+		execute:     opSwapN,
+		constantGas: GasFastestStep,
+		numPop:      0,
+		numPush:     0,
+	}
+	jt[EXCHANGE] = &operation{
+		execute:     opExchange,
+		constantGas: GasFastestStep,
+		numPop:      0,
+		numPush:     0,
+	}
+	jt[DATALOAD] = &operation{
+		execute:     opDataLoad,
+		constantGas: GasFastishStep,
+		numPop:      1,
+		numPush:     1,
+	}
+	jt[DATALOADN] = &operation{
+		execute:     opDataLoadN,
+		constantGas: GasFastestStep,
+		numPop:      0,
+		numPush:     1,
+	}
+	jt[DATASIZE] = &operation{
+		execute:     opDataSize,
+		constantGas: GasQuickStep,
+		numPop:      0,
+		numPush:     1,
+	}
+	jt[DATACOPY] = &operation{
+		execute:     opDataCopy,
+		constantGas: GasFastestStep,
+		dynamicGas:  gasDataCopy,
+		numPop:      3,
+		numPush:     0,
+		memorySize:  memoryDataCopy,
+	}
+	jt[RETURNDATALOAD] = &operation{
+		execute:     opReturnDataLoad,
+		constantGas: GasFastestStep,
+		numPop:      1,
+		numPush:     1,
+	}
+	jt[EXTCALL] = &operation{
+		execute:     opExtCall,
+		constantGas: params.WarmStorageReadCostEIP2929,
+		dynamicGas:  gasExtCall,
+		numPop:      4,
+		numPush:     1,
+		memorySize:  memoryExtCall,
+	}
+	jt[EXTDELEGATECALL] = &operation{
+		execute:     opExtDelegateCall,
+		constantGas: params.WarmStorageReadCostEIP2929,
+		dynamicGas:  gasExtDelegateCall,
+		numPop:      3,
+		numPush:     1,
+		memorySize:  memoryExtCall,
+	}
+	jt[EXTSTATICCALL] = &operation{
+		execute:     opExtStaticCall,
+		constantGas: params.WarmStorageReadCostEIP2929,
+		dynamicGas:  gasExtStaticCall,
+		numPop:      3,
+		numPush:     1,
+		memorySize:  memoryExtCall,
+	}
+	jt[EOFCREATE] = &operation{
+		execute:     opEOFCreate,
+		constantGas: params.CreateGas,
+		dynamicGas:  gasEOFCreate,
+		numPop:      4,
+		numPush:     1,
+		memorySize:  memoryEOFCreate,
+	}
+	jt[RETURNCONTRACT] = &operation{
+		execute:    opReturnContract,
+		dynamicGas: gasReturnContract,
+		numPop:     2,
+		numPush:    0,
+		memorySize: memoryReturnContract,
+	}
+}
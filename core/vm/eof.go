@@ -0,0 +1,469 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/erigontech/erigon/params"
+)
+
+const (
+	offsetVersion   = 2
+	offsetTypesKind = 3
+	offsetCodeKind  = 6
+
+	kindTypes     = 1
+	kindCode      = 2
+	kindContainer = 3
+	kindData      = 0xff
+
+	eofFormatByte = 0xef
+	eof1Version   = 1
+
+	maxInputItems         = 127
+	maxOutputItems        = 128
+	maxStackIncreaseLimit = 1023
+	maxCodeSections       = 1024
+	maxContainerSections  = 256
+	maxReturnStackDepth   = 1024
+
+	nonReturningFunction = 0x80
+)
+
+var eofMagic = []byte{0xef, 0x00}
+
+// HasEOFByte returns true if code starts with the 0xEF byte, which is reserved
+// for EOF containers by EIP-3541.
+func HasEOFByte(code []byte) bool {
+	return len(code) != 0 && code[0] == eofFormatByte
+}
+
+// hasEOFMagic returns true if code starts with the EOF magic prefix 0xEF00.
+func hasEOFMagic(code []byte) bool {
+	return len(eofMagic) <= len(code) && bytes.Equal(eofMagic, code[0:len(eofMagic)])
+}
+
+// isEOFVersion1 returns true if the code's version byte equals eof1Version. It
+// does not verify the EOF magic is valid.
+func isEOFVersion1(code []byte) bool {
+	return 2 < len(code) && code[offsetVersion] == byte(eof1Version)
+}
+
+// Container is an EOF container object as defined by EIP-3540.
+type Container struct {
+	types         []*functionMetadata
+	codeSections  [][]byte
+	subContainers []*Container
+	// subContainerCodes holds the raw encoding of the subcontainers, which is
+	// what EOFCREATE executes and RETURNCONTRACT deploys.
+	subContainerCodes [][]byte
+	data              []byte
+	// dataSize is the declared size of the data section, which may exceed
+	// len(data) in containers whose data is completed by RETURNCONTRACT.
+	dataSize int
+}
+
+// functionMetadata is an EOF function signature.
+type functionMetadata struct {
+	inputs           uint8
+	outputs          uint8
+	maxStackIncrease uint16
+}
+
+// stackDelta returns the #outputs - #inputs
+func (meta *functionMetadata) stackDelta() int {
+	return int(meta.outputs) - int(meta.inputs)
+}
+
+// checkInputs checks the current minimum stack (stackMin) against the required inputs
+// of the metadata, and returns an error if the stack is too shallow.
+func (meta *functionMetadata) checkInputs(stackMin int) error {
+	if int(meta.inputs) > stackMin {
+		return ErrEOFStackUnderflow
+	}
+	return nil
+}
+
+// checkStackMax checks if current maximum stack combined with the
+// function max stack will result in a stack overflow, and if so returns an error.
+func (meta *functionMetadata) checkStackMax(stackMax int) error {
+	newMaxStack := stackMax + int(meta.maxStackIncrease)
+	if newMaxStack > int(params.StackLimit) {
+		return fmt.Errorf("%w: overflow %d", ErrEOFStackOverflow, newMaxStack)
+	}
+	return nil
+}
+
+// returning reports whether the function returns to its caller.
+func (meta *functionMetadata) returning() bool {
+	return meta.outputs != nonReturningFunction
+}
+
+// MarshalBinary encodes an EOF container into binary format.
+func (c *Container) MarshalBinary() []byte {
+	// Build EOF prefix.
+	b := make([]byte, 2)
+	copy(b, eofMagic)
+	b = append(b, eof1Version)
+
+	// Write section headers.
+	b = append(b, kindTypes)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(c.types)*4))
+	b = append(b, kindCode)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(c.codeSections)))
+	for _, codeSection := range c.codeSections {
+		b = binary.BigEndian.AppendUint16(b, uint16(len(codeSection)))
+	}
+	var encodedContainers [][]byte
+	if len(c.subContainers) != 0 {
+		b = append(b, kindContainer)
+		b = binary.BigEndian.AppendUint16(b, uint16(len(c.subContainers)))
+		for _, section := range c.subContainers {
+			encoded := section.MarshalBinary()
+			b = binary.BigEndian.AppendUint32(b, uint32(len(encoded)))
+			encodedContainers = append(encodedContainers, encoded)
+		}
+	}
+	b = append(b, kindData)
+	b = binary.BigEndian.AppendUint16(b, uint16(c.dataSize))
+	b = append(b, 0) // terminator
+
+	// Write section contents.
+	for _, ty := range c.types {
+		b = append(b, []byte{ty.inputs, ty.outputs, byte(ty.maxStackIncrease >> 8), byte(ty.maxStackIncrease & 0x00ff)}...)
+	}
+	for _, code := range c.codeSections {
+		b = append(b, code...)
+	}
+	for _, section := range encodedContainers {
+		b = append(b, section...)
+	}
+	b = append(b, c.data...)
+
+	return b
+}
+
+// UnmarshalBinary decodes an EOF container. A top level container must be
+// complete, whereas a subcontainer may have a truncated data section.
+func (c *Container) UnmarshalBinary(b []byte, isTopLevel bool) error {
+	h, err := parseEOFHeader(b)
+	if err != nil {
+		return err
+	}
+	// Only the data section of a subcontainer can be truncated, to be filled
+	// with aux data on deployment.
+	if (isTopLevel && len(b) < h.size()) || len(b) > h.size() {
+		return fmt.Errorf("%w: have %d, want %d", ErrInvalidContainerSize, len(b), h.size())
+	}
+
+	// Parse types section.
+	idx := h.headerSize
+	types := make([]*functionMetadata, 0, h.typesSize/4)
+	for i := 0; i < h.typesSize/4; i++ {
+		sig := &functionMetadata{
+			inputs:           b[idx+i*4],
+			outputs:          b[idx+i*4+1],
+			maxStackIncrease: binary.BigEndian.Uint16(b[idx+i*4+2:]),
+		}
+		if sig.inputs > maxInputItems {
+			return fmt.Errorf("%w for section %d: have %d", ErrTooManyInputs, i, sig.inputs)
+		}
+		if sig.outputs > maxOutputItems {
+			return fmt.Errorf("%w for section %d: have %d", ErrTooManyOutputs, i, sig.outputs)
+		}
+		if sig.maxStackIncrease > maxStackIncreaseLimit {
+			return fmt.Errorf("%w for section %d: have %d", ErrTooLargeMaxStackHeight, i, sig.maxStackIncrease)
+		}
+		if int(sig.inputs)+int(sig.maxStackIncrease) > int(params.StackLimit) {
+			return fmt.Errorf("%w for section %d: have %d", ErrTooLargeMaxStackHeight, i, int(sig.inputs)+int(sig.maxStackIncrease))
+		}
+		types = append(types, sig)
+	}
+	if types[0].inputs != 0 || types[0].outputs != nonReturningFunction {
+		return fmt.Errorf("%w: have %d, %d", ErrInvalidSection0Type, types[0].inputs, types[0].outputs)
+	}
+	c.types = types
+
+	// Parse code sections.
+	idx += h.typesSize
+	codeSections := make([][]byte, len(h.codeSizes))
+	for i, size := range h.codeSizes {
+		codeSections[i] = b[idx : idx+size]
+		idx += size
+	}
+	c.codeSections = codeSections
+
+	// Parse the optional container sizes.
+	if len(h.containerSizes) != 0 {
+		subContainerCodes := make([][]byte, 0, len(h.containerSizes))
+		subContainers := make([]*Container, 0, len(h.containerSizes))
+		for i, size := range h.containerSizes {
+			subContainer := new(Container)
+			if err := subContainer.UnmarshalBinary(b[idx:idx+size], false); err != nil {
+				return fmt.Errorf("subcontainer %d: %w", i, err)
+			}
+			subContainers = append(subContainers, subContainer)
+			subContainerCodes = append(subContainerCodes, b[idx:idx+size])
+			idx += size
+		}
+		c.subContainers = subContainers
+		c.subContainerCodes = subContainerCodes
+	}
+
+	// Parse data section.
+	c.data = b[idx:]
+	c.dataSize = h.dataSize
+
+	return nil
+}
+
+// eofHeader holds the section sizes declared by an EOF container header.
+type eofHeader struct {
+	headerSize     int
+	typesSize      int
+	codeSizes      []int
+	containerSizes []int
+	dataSize       int
+}
+
+// size returns the total declared size of the container.
+func (h *eofHeader) size() int {
+	size := h.headerSize + h.typesSize + h.dataSize
+	for _, s := range h.codeSizes {
+		size += s
+	}
+	for _, s := range h.containerSizes {
+		size += s
+	}
+	return size
+}
+
+// parseEOFHeader decodes the header of an EOF container, checking the
+// section kinds and the consistency of the declared sizes.
+func parseEOFHeader(b []byte) (*eofHeader, error) {
+	if !hasEOFMagic(b) {
+		return nil, fmt.Errorf("%w: want %x", ErrInvalidMagic, eofMagic)
+	}
+	if !isEOFVersion1(b) {
+		return nil, fmt.Errorf("%w: have %d, want %d", ErrInvalidVersion, b[2], eof1Version)
+	}
+
+	var (
+		kind, typesSize, dataSize int
+		codeSizes                 []int
+		containerSizes            []int
+		err                       error
+	)
+	// Parse type section header.
+	kind, typesSize, err = parseSection(b, offsetTypesKind)
+	if err != nil {
+		return nil, err
+	}
+	if kind != kindTypes {
+		return nil, fmt.Errorf("%w: found section kind %x instead", ErrMissingTypeHeader, kind)
+	}
+	if typesSize < 4 || typesSize%4 != 0 {
+		return nil, fmt.Errorf("%w: type section size must be divisible by 4, have %d", ErrInvalidTypeSize, typesSize)
+	}
+	if typesSize/4 > maxCodeSections {
+		return nil, fmt.Errorf("%w: type section must not exceed 4*1024, have %d", ErrInvalidTypeSize, typesSize)
+	}
+
+	// Parse code section header.
+	kind, codeSizes, err = parseSectionList(b, offsetCodeKind, 2)
+	if err != nil {
+		return nil, err
+	}
+	if kind != kindCode {
+		return nil, fmt.Errorf("%w: found section kind %x instead", ErrMissingCodeHeader, kind)
+	}
+	if len(codeSizes) != typesSize/4 {
+		return nil, fmt.Errorf("%w: mismatch of code sections found and type signatures, types %d, code %d", ErrInvalidCodeSize, typesSize/4, len(codeSizes))
+	}
+	for i, size := range codeSizes {
+		if size == 0 {
+			return nil, fmt.Errorf("%w for section %d: size must not be 0", ErrInvalidCodeSize, i)
+		}
+	}
+
+	// Parse the optional container section header.
+	offset := offsetCodeKind + 2 + 2*len(codeSizes) + 1
+	if offset < len(b) && b[offset] == kindContainer {
+		_, containerSizes, err = parseSectionList(b, offset, 4)
+		if err != nil {
+			return nil, err
+		}
+		if len(containerSizes) > maxContainerSections {
+			return nil, fmt.Errorf("%w: number of container sections may not exceed %d, have %d", ErrInvalidContainerSectionSize, maxContainerSections, len(containerSizes))
+		}
+		for i, size := range containerSizes {
+			if size == 0 {
+				return nil, fmt.Errorf("%w for section %d: size must not be 0", ErrInvalidContainerSectionSize, i)
+			}
+		}
+		offset += 2 + 4*len(containerSizes) + 1
+	}
+
+	// Parse data section header.
+	kind, dataSize, err = parseSection(b, offset)
+	if err != nil {
+		return nil, err
+	}
+	if kind != kindData {
+		return nil, fmt.Errorf("%w: found section %x instead", ErrMissingDataHeader, kind)
+	}
+	offset += 3
+
+	// Check for terminator.
+	if offset >= len(b) {
+		return nil, fmt.Errorf("%w: invalid offset to terminator", io.ErrUnexpectedEOF)
+	}
+	if b[offset] != 0 {
+		return nil, fmt.Errorf("%w: have %x", ErrMissingTerminator, b[offset])
+	}
+	offset++
+
+	h := &eofHeader{
+		headerSize:     offset,
+		typesSize:      typesSize,
+		codeSizes:      codeSizes,
+		containerSizes: containerSizes,
+		dataSize:       dataSize,
+	}
+	// The types and code sections, and the container sections if any, must be
+	// fully present.
+	if len(b) < h.size()-dataSize {
+		return nil, fmt.Errorf("%w: have %d, want at least %d", ErrInvalidContainerSize, len(b), h.size()-dataSize)
+	}
+	return h, nil
+}
+
+// eofContainerSize returns the declared size of the EOF container at the
+// start of b.
+func eofContainerSize(b []byte) (int, error) {
+	h, err := parseEOFHeader(b)
+	if err != nil {
+		return 0, err
+	}
+	return h.size(), nil
+}
+
+// parseSection decodes a (kind, size) pair from an EOF header.
+func parseSection(b []byte, idx int) (kind, size int, err error) {
+	if idx+3 > len(b) {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	kind = int(b[idx])
+	size = int(binary.BigEndian.Uint16(b[idx+1:]))
+	return kind, size, nil
+}
+
+// parseSectionList decodes a (kind, len, []sizes) tuple from an EOF header,
+// each size taking width bytes.
+func parseSectionList(b []byte, idx int, width int) (kind int, list []int, err error) {
+	if idx >= len(b) {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	kind = int(b[idx])
+	if idx+3 > len(b) {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	count := int(binary.BigEndian.Uint16(b[idx+1:]))
+	if count == 0 {
+		return 0, nil, fmt.Errorf("%w: section kind %x must have at least one entry", ErrInvalidSectionCount, kind)
+	}
+	idx += 3
+	if idx+count*width > len(b) {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	list = make([]int, count)
+	for i := 0; i < count; i++ {
+		if width == 2 {
+			list[i] = int(binary.BigEndian.Uint16(b[idx+width*i:]))
+		} else {
+			list[i] = int(binary.BigEndian.Uint32(b[idx+width*i:]))
+		}
+	}
+	return kind, list, nil
+}
+
+// String returns a human readable string representation of the container.
+func (c *Container) String() string {
+	var result = fmt.Sprintf(`Header
+  - EOFMagic: %02x
+  - EOFVersion: %02x
+  - KindType: %02x
+  - TypesSize: %04x
+  - KindCode: %02x
+  - KindData: %02x
+  - DataSize: %04x
+  - Number of code sections: %d
+`,
+		eofMagic, eof1Version, kindTypes, len(c.types)*4, kindCode, kindData, c.dataSize, len(c.codeSections))
+	for i, code := range c.codeSections {
+		result += fmt.Sprintf("    - Code section %d length: %04x\n", i, len(code))
+	}
+
+	result += fmt.Sprintf("  - Number of subcontainers: %d\n", len(c.subContainers))
+	if len(c.subContainers) > 0 {
+		for i, section := range c.subContainers {
+			result += fmt.Sprintf("    - subcontainer %d length: %04x\n", i, len(section.MarshalBinary()))
+		}
+	}
+	result += "Body\n"
+	for i, typ := range c.types {
+		result += fmt.Sprintf("  - Type %v: %x\n", i,
+			[]byte{typ.inputs, typ.outputs, byte(typ.maxStackIncrease >> 8), byte(typ.maxStackIncrease & 0x00ff)})
+	}
+	for i, code := range c.codeSections {
+		result += fmt.Sprintf("  - Code section %d: %#x\n", i, code)
+	}
+	for i, section := range c.subContainers {
+		result += fmt.Sprintf("  - Subcontainer %d:\n%s", i, indent(section.String()))
+	}
+	result += fmt.Sprintf("  - Data: %#x\n", c.data)
+	return result
+}
+
+func indent(s string) string {
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = "    " + line
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// withAuxData returns the encoding of the container with auxData appended to
+// its data section, as deployed by RETURNCONTRACT.
+func (c *Container) withAuxData(auxData []byte) ([]byte, error) {
+	dataSize := len(c.data) + len(auxData)
+	if dataSize < c.dataSize {
+		return nil, fmt.Errorf("%w: have %d, want at least %d", ErrInvalidDataSize, dataSize, c.dataSize)
+	}
+	if dataSize > 0xffff {
+		return nil, fmt.Errorf("%w: have %d, max %d", ErrInvalidDataSize, dataSize, 0xffff)
+	}
+	deployed := *c
+	deployed.data = append(append(make([]byte, 0, dataSize), c.data...), auxData...)
+	deployed.dataSize = dataSize
+	return deployed.MarshalBinary(), nil
+}
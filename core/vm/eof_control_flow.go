@@ -0,0 +1,144 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/erigontech/erigon/params"
+)
+
+// stackBounds is the range of the operand stack height at an instruction,
+// over all the paths reaching it.
+type stackBounds struct {
+	min, max int
+	set      bool
+}
+
+// validateControlFlow performs the stack validation of EIP-5450: it computes
+// the range of the stack height at every instruction of the code section in a
+// single linear pass, and returns the maximum stack height reached.
+//
+// Every instruction must be reachable by forward jumps or by falling through,
+// backward jumps must agree with the height already recorded at their target,
+// the stack must never underflow or exceed the limit, and the code must not
+// fall off its end.
+func validateControlFlow(code []byte, section int, types []*functionMetadata, jt *JumpTable) (int, error) {
+	var (
+		bounds         = make([]stackBounds, len(code))
+		inputs         = int(types[section].inputs)
+		maxStackHeight = inputs
+	)
+	bounds[0] = stackBounds{min: inputs, max: inputs, set: true}
+
+	for pos := 0; pos < len(code); {
+		op := OpCode(code[pos])
+		cur := bounds[pos]
+		if !cur.set {
+			return 0, fmt.Errorf("%w: pos %d", ErrUnreachableCode, pos)
+		}
+		size := int(immediates[op])
+		if op == RJUMPV {
+			size = 1 + 2*(int(code[pos+1])+1)
+		}
+		next := pos + size + 1
+
+		var required, change int
+		switch op {
+		case CALLF:
+			target := types[binary.BigEndian.Uint16(code[pos+1:])]
+			if err := target.checkInputs(cur.min); err != nil {
+				return 0, fmt.Errorf("%w: pos %d", err, pos)
+			}
+			if err := target.checkStackMax(cur.max); err != nil {
+				return 0, fmt.Errorf("%w: pos %d", err, pos)
+			}
+			required, change = int(target.inputs), target.stackDelta()
+		case RETF:
+			want := int(types[section].outputs)
+			if cur.min != want || cur.max != want {
+				return 0, fmt.Errorf("%w: have [%d, %d], want %d, pos %d", ErrInvalidOutputs, cur.min, cur.max, want, pos)
+			}
+		case JUMPF:
+			target := types[binary.BigEndian.Uint16(code[pos+1:])]
+			if err := target.checkStackMax(cur.max); err != nil {
+				return 0, fmt.Errorf("%w: pos %d", err, pos)
+			}
+			if target.returning() {
+				want := int(types[section].outputs) + int(target.inputs) - int(target.outputs)
+				if cur.min != want || cur.max != want {
+					return 0, fmt.Errorf("%w: have [%d, %d], want %d, pos %d", ErrJumpfIncompatibleOutputs, cur.min, cur.max, want, pos)
+				}
+			} else if err := target.checkInputs(cur.min); err != nil {
+				return 0, fmt.Errorf("%w: pos %d", err, pos)
+			}
+		case DUPN:
+			required, change = int(code[pos+1])+1, 1
+		case SWAPN:
+			required = int(code[pos+1]) + 2
+		case EXCHANGE:
+			n, m := int(code[pos+1]>>4)+1, int(code[pos+1]&0x0f)+1
+			required = n + m + 1
+		default:
+			required, change = jt[op].numPop, jt[op].numPush-jt[op].numPop
+		}
+		if cur.min < required {
+			return 0, fmt.Errorf("%w: op %s, have %d, want %d, pos %d", ErrEOFStackUnderflow, op, cur.min, required, pos)
+		}
+		newMin, newMax := cur.min+change, cur.max+change
+		if newMax > int(params.StackLimit) {
+			return 0, fmt.Errorf("%w: op %s, have %d, pos %d", ErrEOFStackOverflow, op, newMax, pos)
+		}
+		maxStackHeight = max(maxStackHeight, newMax)
+
+		// Collect the successors of the instruction.
+		var successors []int
+		switch {
+		case op == RJUMP:
+			successors = append(successors, next+int(int16(binary.BigEndian.Uint16(code[pos+1:]))))
+		case op == RJUMPI:
+			successors = append(successors, next, next+int(int16(binary.BigEndian.Uint16(code[pos+1:]))))
+		case op == RJUMPV:
+			successors = append(successors, next)
+			for i := 0; i < int(code[pos+1])+1; i++ {
+				successors = append(successors, next+int(int16(binary.BigEndian.Uint16(code[pos+2+2*i:]))))
+			}
+		case !terminals[op]:
+			successors = append(successors, next)
+		}
+		for _, s := range successors {
+			if s >= len(code) {
+				return 0, fmt.Errorf("%w: pos %d", ErrInvalidCodeTermination, pos)
+			}
+			if s <= pos {
+				// Backward jumps must not change the recorded stack height.
+				if b := bounds[s]; b.min != newMin || b.max != newMax {
+					return 0, fmt.Errorf("%w: have [%d, %d], want [%d, %d], pos %d", ErrInvalidBackwardJump, newMin, newMax, b.min, b.max, pos)
+				}
+				continue
+			}
+			if b := &bounds[s]; !b.set {
+				*b = stackBounds{min: newMin, max: newMax, set: true}
+			} else {
+				b.min, b.max = min(b.min, newMin), max(b.max, newMax)
+			}
+		}
+		pos = next
+	}
+	return maxStackHeight, nil
+}
@@ -0,0 +1,441 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/holiman/uint256"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	cmath "github.com/erigontech/erigon-lib/common/math"
+
+	"github.com/erigontech/erigon/core/tracing"
+	"github.com/erigontech/erigon/core/vm/stack"
+	"github.com/erigontech/erigon/crypto"
+	"github.com/erigontech/erigon/params"
+)
+
+// eofMagicHash is the code hash legacy code observes for EOF code.
+var eofMagicHash = crypto.Keccak256Hash(eofMagic)
+
+// opRjump implements the RJUMP opcode.
+func opRjump(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	offset := int16(binary.BigEndian.Uint16(scope.Contract.Code[*pc+1:]))
+	// Move past the immediate and apply the relative offset, minus one for the
+	// increment of the interpreter loop.
+	*pc = uint64(int64(*pc) + 3 + int64(offset) - 1)
+	return nil, nil
+}
+
+// opRjumpi implements the RJUMPI opcode.
+func opRjumpi(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	condition := scope.Stack.Pop()
+	if condition.IsZero() {
+		// Not branching, just skip over the immediate.
+		*pc += 2
+		return nil, nil
+	}
+	return opRjump(pc, interpreter, scope)
+}
+
+// opRjumpv implements the RJUMPV opcode.
+func opRjumpv(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		code  = scope.Contract.Code
+		count = uint64(code[*pc+1]) + 1
+		idx   = scope.Stack.Pop()
+	)
+	end := *pc + 1 + count*2
+	if idx, overflow := idx.Uint64WithOverflow(); !overflow && idx < count {
+		offset := int16(binary.BigEndian.Uint16(code[*pc+2+2*idx:]))
+		*pc = uint64(int64(end) + int64(offset))
+		return nil, nil
+	}
+	// Index out of bounds, fall through.
+	*pc = end
+	return nil, nil
+}
+
+// opCallf implements the CALLF opcode.
+func opCallf(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		contract = scope.Contract
+		section  = uint64(binary.BigEndian.Uint16(contract.Code[*pc+1:]))
+		typ      = contract.Container.types[section]
+	)
+	if limit := int(params.StackLimit) - int(typ.maxStackIncrease); scope.Stack.Len() > limit {
+		return nil, &ErrStackOverflow{stackLen: scope.Stack.Len(), limit: limit}
+	}
+	if len(contract.returnStack) >= maxReturnStackDepth {
+		return nil, ErrReturnStackExceeded
+	}
+	contract.returnStack = append(contract.returnStack, returnFrame{section: contract.CodeSection, pc: *pc + 3})
+	contract.setCodeSection(section)
+	// The increment of the interpreter loop wraps pc to the start of the section.
+	*pc = math.MaxUint64
+	return nil, nil
+}
+
+// opRetf implements the RETF opcode.
+func opRetf(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	contract := scope.Contract
+	frame := contract.returnStack[len(contract.returnStack)-1]
+	contract.returnStack = contract.returnStack[:len(contract.returnStack)-1]
+	contract.setCodeSection(frame.section)
+	*pc = frame.pc - 1
+	return nil, nil
+}
+
+// opJumpf implements the JUMPF opcode.
+func opJumpf(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		contract = scope.Contract
+		section  = uint64(binary.BigEndian.Uint16(contract.Code[*pc+1:]))
+		typ      = contract.Container.types[section]
+	)
+	if limit := int(params.StackLimit) - int(typ.maxStackIncrease); scope.Stack.Len() > limit {
+		return nil, &ErrStackOverflow{stackLen: scope.Stack.Len(), limit: limit}
+	}
+	contract.setCodeSection(section)
+	*pc = math.MaxUint64
+	return nil, nil
+}
+
+// opDupN implements the DUPN opcode.
+func opDupN(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	n := int(scope.Contract.Code[*pc+1]) + 1
+	scope.Stack.Dup(n)
+	*pc += 1
+	return nil, nil
+}
+
+// opSwapN implements the SWAPN opcode.
+func opSwapN(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	n := int(scope.Contract.Code[*pc+1]) + 1
+	scope.Stack.Swap(n + 1)
+	*pc += 1
+	return nil, nil
+}
+
+// opExchange implements the EXCHANGE opcode.
+func opExchange(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		imm = scope.Contract.Code[*pc+1]
+		n   = int(imm>>4) + 1
+		m   = int(imm&0x0f) + 1
+	)
+	a, b := scope.Stack.Back(n), scope.Stack.Back(n+m)
+	*a, *b = *b, *a
+	*pc += 1
+	return nil, nil
+}
+
+// opDataLoad implements the DATALOAD opcode.
+func opDataLoad(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	x := scope.Stack.Peek()
+	if offset, overflow := x.Uint64WithOverflow(); !overflow {
+		x.SetBytes(getData(scope.Contract.Container.data, offset, 32))
+	} else {
+		x.Clear()
+	}
+	return nil, nil
+}
+
+// opDataLoadN implements the DATALOADN opcode.
+func opDataLoadN(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	offset := uint64(binary.BigEndian.Uint16(scope.Contract.Code[*pc+1:]))
+	scope.Stack.Push(new(uint256.Int).SetBytes(getData(scope.Contract.Container.data, offset, 32)))
+	*pc += 2
+	return nil, nil
+}
+
+// opDataSize implements the DATASIZE opcode.
+func opDataSize(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	scope.Stack.Push(new(uint256.Int).SetUint64(uint64(len(scope.Contract.Container.data))))
+	return nil, nil
+}
+
+// opDataCopy implements the DATACOPY opcode.
+func opDataCopy(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		memOffset = scope.Stack.Pop()
+		offset    = scope.Stack.Pop()
+		length    = scope.Stack.Pop()
+	)
+	// These values are checked for overflow during gas cost calculation
+	memOffset64 := memOffset.Uint64()
+	length64 := length.Uint64()
+	scope.Memory.Set(memOffset64, length64, getDataBig(scope.Contract.Container.data, &offset, length64))
+	return nil, nil
+}
+
+// opReturnDataLoad implements the RETURNDATALOAD opcode.
+func opReturnDataLoad(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	x := scope.Stack.Peek()
+	if offset, overflow := x.Uint64WithOverflow(); !overflow {
+		x.SetBytes(getData(interpreter.returnData, offset, 32))
+	} else {
+		x.Clear()
+	}
+	return nil, nil
+}
+
+// opExtCall implements the EXTCALL opcode.
+func opExtCall(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	stack := scope.Stack
+	addr, inOffset, inSize, value := stack.Pop(), stack.Pop(), stack.Pop(), stack.Pop()
+	if !value.IsZero() && interpreter.readOnly {
+		return nil, ErrWriteProtection
+	}
+	return extCall(EXTCALL, interpreter, scope, &addr, &inOffset, &inSize, &value)
+}
+
+// opExtDelegateCall implements the EXTDELEGATECALL opcode.
+func opExtDelegateCall(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	stack := scope.Stack
+	addr, inOffset, inSize := stack.Pop(), stack.Pop(), stack.Pop()
+	return extCall(EXTDELEGATECALL, interpreter, scope, &addr, &inOffset, &inSize, nil)
+}
+
+// opExtStaticCall implements the EXTSTATICCALL opcode.
+func opExtStaticCall(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	stack := scope.Stack
+	addr, inOffset, inSize := stack.Pop(), stack.Pop(), stack.Pop()
+	return extCall(EXTSTATICCALL, interpreter, scope, &addr, &inOffset, &inSize, new(uint256.Int))
+}
+
+// Status codes pushed by the EXT*CALL instructions.
+const (
+	extCallSuccess = 0
+	extCallRevert  = 1
+	extCallFailure = 2
+)
+
+// extCall performs the EXT*CALL instructions of EIP-7069. Unlike the legacy
+// calls, the callee gets all but max(1/64, MIN_RETAINED_GAS) of the gas left,
+// and conditions known before the call, such as a lack of gas or balance, make
+// it fail without consuming the gas passed on, with the revert status.
+func extCall(typ OpCode, interpreter *EVMInterpreter, scope *ScopeContext, addr, inOffset, inSize, value *uint256.Int) ([]byte, error) {
+	if addrBytes := addr.Bytes32(); !allZero(addrBytes[:12]) {
+		return nil, ErrInvalidAddress
+	}
+	var (
+		evm    = interpreter.evm
+		toAddr = libcommon.Address(addr.Bytes20())
+		args   = scope.Memory.GetPtr(int64(inOffset.Uint64()), int64(inSize.Uint64()))
+		gas    = scope.Contract.Gas
+		status uint256.Int
+	)
+	retained := max(gas/64, params.MinRetainedGasEIP7069)
+	if gas < retained {
+		gas = 0
+	} else {
+		gas -= retained
+	}
+	lightFailure := gas < params.MinCalleeGasEIP7069 || interpreter.Depth() > int(params.CallCreateDepth)
+	switch typ {
+	case EXTCALL:
+		lightFailure = lightFailure || (!value.IsZero() && !evm.Context.CanTransfer(evm.IntraBlockState(), scope.Contract.Address(), value))
+	case EXTDELEGATECALL:
+		// Only EOF code can be delegated to.
+		lightFailure = lightFailure || !hasEOFMagic(evm.IntraBlockState().ResolveCode(toAddr))
+	}
+	if lightFailure {
+		interpreter.returnData = nil
+		scope.Stack.Push(status.SetUint64(extCallRevert))
+		return nil, nil
+	}
+
+	scope.Contract.UseGas(gas, tracing.GasChangeCallOpCode)
+	var (
+		ret       []byte
+		returnGas uint64
+		err       error
+	)
+	switch typ {
+	case EXTCALL:
+		ret, returnGas, err = evm.Call(scope.Contract, toAddr, args, gas, value, false /* bailout */)
+	case EXTDELEGATECALL:
+		ret, returnGas, err = evm.DelegateCall(scope.Contract, toAddr, args, gas)
+	case EXTSTATICCALL:
+		ret, returnGas, err = evm.StaticCall(scope.Contract, toAddr, args, gas)
+	}
+	scope.Contract.RefundGas(returnGas, tracing.GasChangeCallLeftOverRefunded)
+
+	switch err {
+	case nil:
+		status.SetUint64(extCallSuccess)
+	case ErrExecutionReverted, ErrDepth, ErrInsufficientBalance:
+		status.SetUint64(extCallRevert)
+	default:
+		status.SetUint64(extCallFailure)
+	}
+	scope.Stack.Push(&status)
+	if err == nil || err == ErrExecutionReverted {
+		interpreter.returnData = libcommon.CopyBytes(ret)
+	} else {
+		interpreter.returnData = nil
+	}
+	return nil, nil
+}
+
+// opEOFCreate implements the EOFCREATE opcode.
+func opEOFCreate(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	if interpreter.readOnly {
+		return nil, ErrWriteProtection
+	}
+	var (
+		idx           = scope.Contract.Code[*pc+1]
+		initContainer = scope.Contract.Container.subContainers[idx]
+		initCode      = scope.Contract.Container.subContainerCodes[idx]
+		value         = scope.Stack.Pop()
+		salt          = scope.Stack.Pop()
+		offset, size  = scope.Stack.Pop(), scope.Stack.Pop()
+		input         = scope.Memory.GetCopy(int64(offset.Uint64()), int64(size.Uint64()))
+	)
+	*pc += 1
+	// Charge the hashing of the initcontainer for the address derivation.
+	if !scope.Contract.UseGas(params.Keccak256WordGas*ToWordSize(uint64(len(initCode))), tracing.GasChangeIgnored) {
+		return nil, ErrOutOfGas
+	}
+	gas := scope.Contract.Gas
+	gas -= gas / 64
+	scope.Contract.UseGas(gas, tracing.GasChangeCallContractCreation2)
+
+	res, addr, returnGas, suberr := interpreter.evm.EOFCreate(scope.Contract, initContainer, initCode, input, gas, &value, &salt)
+	// Push item on the stack based on the returned error.
+	if suberr != nil {
+		size.Clear()
+	} else {
+		size.SetBytes(addr.Bytes())
+	}
+	scope.Stack.Push(&size)
+	scope.Contract.RefundGas(returnGas, tracing.GasChangeCallLeftOverRefunded)
+
+	if suberr == ErrExecutionReverted {
+		interpreter.returnData = res // set REVERT data to return data buffer
+		return nil, nil
+	}
+	interpreter.returnData = nil // clear dirty return data buffer
+	return nil, nil
+}
+
+// opReturnContract implements the RETURNCONTRACT opcode, which ends initcode
+// by deploying one of its subcontainers with the aux data appended.
+func opReturnContract(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		idx          = scope.Contract.Code[*pc+1]
+		offset, size = scope.Stack.Pop(), scope.Stack.Pop()
+		auxData      = scope.Memory.GetPtr(int64(offset.Uint64()), int64(size.Uint64()))
+	)
+	ret, err := scope.Contract.Container.subContainers[idx].withAuxData(auxData)
+	if err != nil {
+		return nil, err
+	}
+	return ret, errStopToken
+}
+
+// opExtCodeSizeEOF implements EXTCODESIZE for legacy code, seeing EOF code as its magic.
+func opExtCodeSizeEOF(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	slot := scope.Stack.Peek()
+	code := interpreter.evm.IntraBlockState().ResolveCode(slot.Bytes20())
+	if hasEOFMagic(code) {
+		code = eofMagic
+	}
+	slot.SetUint64(uint64(len(code)))
+	return nil, nil
+}
+
+// opExtCodeCopyEOF implements EXTCODECOPY for legacy code, seeing EOF code as its magic.
+func opExtCodeCopyEOF(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		stack      = scope.Stack
+		a          = stack.Pop()
+		memOffset  = stack.Pop()
+		codeOffset = stack.Pop()
+		length     = stack.Pop()
+	)
+	addr := libcommon.Address(a.Bytes20())
+	len64 := length.Uint64()
+
+	code := interpreter.evm.IntraBlockState().ResolveCode(addr)
+	if hasEOFMagic(code) {
+		code = eofMagic
+	}
+	scope.Memory.Set(memOffset.Uint64(), len64, getDataBig(code, &codeOffset, len64))
+	return nil, nil
+}
+
+// opExtCodeHashEOF implements EXTCODEHASH for legacy code, seeing EOF code as its magic.
+func opExtCodeHashEOF(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	slot := scope.Stack.Peek()
+	address := libcommon.Address(slot.Bytes20())
+
+	switch ibs := interpreter.evm.IntraBlockState(); {
+	case ibs.Empty(address):
+		slot.Clear()
+	case hasEOFMagic(ibs.ResolveCode(address)):
+		slot.SetBytes(eofMagicHash.Bytes())
+	default:
+		slot.SetBytes(ibs.ResolveCodeHash(address).Bytes())
+	}
+	return nil, nil
+}
+
+var (
+	gasDataCopy       = memoryCopierGas(2)
+	gasEOFCreate      = pureMemoryGascost
+	gasReturnContract = pureMemoryGascost
+
+	gasExtCall         = makeGasExtCall(true)
+	gasExtDelegateCall = makeGasExtCall(false)
+	gasExtStaticCall   = makeGasExtCall(false)
+)
+
+// makeGasExtCall creates the dynamic gas function of the EXT*CALL opcodes,
+// which charges the memory expansion, the cold access to the target and, for
+// EXTCALL, the value transfer. The gas passed on is charged by the opcodes.
+func makeGasExtCall(withValue bool) gasFunc {
+	return func(evm *EVM, contract *Contract, stack *stack.Stack, mem *Memory, memorySize uint64) (uint64, error) {
+		gas, err := memoryGasCost(mem, memorySize)
+		if err != nil {
+			return 0, err
+		}
+		var (
+			addr     = libcommon.Address(stack.Back(0).Bytes20())
+			overflow bool
+		)
+		// The warm storage read cost is already charged as constantGas
+		if evm.IntraBlockState().AddAddressToAccessList(addr) {
+			if gas, overflow = cmath.SafeAdd(gas, params.ColdAccountAccessCostEIP2929-params.WarmStorageReadCostEIP2929); overflow {
+				return 0, ErrGasUintOverflow
+			}
+		}
+		if withValue && !stack.Back(3).IsZero() {
+			transferGas := params.CallValueTransferGas
+			if evm.IntraBlockState().Empty(addr) {
+				transferGas += params.CallNewAccountGas
+			}
+			if gas, overflow = cmath.SafeAdd(gas, transferGas); overflow {
+				return 0, ErrGasUintOverflow
+			}
+		}
+		return gas, nil
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/vm/evmtypes"
+	"github.com/erigontech/erigon/crypto"
+	"github.com/erigontech/erigon/params"
+)
+
+func TestEOFExecution(t *testing.T) {
+	t.Parallel()
+	tx, sd := testTemporalTxSD(t, testTemporalDB(t))
+	defer tx.Rollback()
+
+	r, w := state.NewReaderV3(sd), state.NewWriterV4(sd)
+	s := state.New(r)
+
+	config := *params.AllProtocolChanges
+	config.PragueTime = big.NewInt(0)
+	config.OsakaTime = big.NewInt(0)
+
+	var (
+		nonReturning = &functionMetadata{inputs: 0, outputs: 0x80}
+		data         = make([]byte, 32)
+		// CALLF into a section adding its two inputs, add the last word of
+		// the data section and return the result.
+		adder = &Container{
+			types: []*functionMetadata{
+				{inputs: 0, outputs: 0x80, maxStackIncrease: 2},
+				{inputs: 2, outputs: 1, maxStackIncrease: 0},
+			},
+			codeSections: [][]byte{
+				hexutil.MustDecode("0x60026003e30001d10000015f5260205ff3"),
+				hexutil.MustDecode("0x01e4"),
+			},
+			data:     data,
+			dataSize: len(data),
+		}
+		runtime = &Container{
+			types:        []*functionMetadata{nonReturning},
+			codeSections: [][]byte{{byte(STOP)}},
+			data:         []byte{},
+		}
+		initcode = &Container{
+			types:         []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 2}},
+			codeSections:  [][]byte{hexutil.MustDecode("0x5f5fee00")},
+			subContainers: []*Container{runtime},
+			data:          []byte{},
+		}
+		// EOFCREATE the initcode and return the created address.
+		creator = &Container{
+			types:         []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 4}},
+			codeSections:  [][]byte{hexutil.MustDecode("0x5f5f5f5fec005f5260205ff3")},
+			subContainers: []*Container{initcode},
+			data:          []byte{},
+		}
+		adderAddress   = libcommon.BytesToAddress([]byte("adder"))
+		creatorAddress = libcommon.BytesToAddress([]byte("creator"))
+		legacyAddress  = libcommon.BytesToAddress([]byte("legacy"))
+	)
+	data[31] = 10
+	for addr, code := range map[libcommon.Address][]byte{
+		adderAddress:   adder.MarshalBinary(),
+		creatorAddress: creator.MarshalBinary(),
+		// EXTCODESIZE of the adder, returned.
+		legacyAddress: append(append([]byte{byte(PUSH20)}, adderAddress.Bytes()...), hexutil.MustDecode("0x3b5f5260205ff3")...),
+	} {
+		s.CreateAccount(addr, true)
+		s.SetCode(addr, code)
+	}
+	_ = s.CommitBlock(config.Rules(0, 0), w)
+
+	vmctx := evmtypes.BlockContext{
+		CanTransfer: func(evmtypes.IntraBlockState, libcommon.Address, *uint256.Int) bool { return true },
+		Transfer:    func(evmtypes.IntraBlockState, libcommon.Address, libcommon.Address, *uint256.Int, bool) {},
+	}
+	vmenv := NewEVM(vmctx, evmtypes.TxContext{}, s, &config, Config{})
+
+	ret, _, err := vmenv.Call(AccountRef(libcommon.Address{}), adderAddress, nil, 100_000, new(uint256.Int), false /* bailout */)
+	if err != nil {
+		t.Fatalf("adder: unexpected error: %v", err)
+	}
+	if have := new(uint256.Int).SetBytes(ret); have.Uint64() != 15 {
+		t.Errorf("adder: have %d, want 15", have)
+	}
+
+	ret, _, err = vmenv.Call(AccountRef(libcommon.Address{}), legacyAddress, nil, 100_000, new(uint256.Int), false /* bailout */)
+	if err != nil {
+		t.Fatalf("legacy: unexpected error: %v", err)
+	}
+	if have := new(uint256.Int).SetBytes(ret); have.Uint64() != 2 {
+		t.Errorf("legacy: EXTCODESIZE of EOF code: have %d, want 2", have)
+	}
+
+	ret, _, err = vmenv.Call(AccountRef(libcommon.Address{}), creatorAddress, nil, 1_000_000, new(uint256.Int), false /* bailout */)
+	if err != nil {
+		t.Fatalf("creator: unexpected error: %v", err)
+	}
+	want := crypto.CreateAddress2(creatorAddress, [32]byte{}, crypto.Keccak256(initcode.MarshalBinary()))
+	if have := libcommon.BytesToAddress(ret); have != want {
+		t.Fatalf("creator: have address %x, want %x", have, want)
+	}
+	if code := vmenv.IntraBlockState().GetCode(want); !bytes.Equal(code, runtime.MarshalBinary()) {
+		t.Errorf("creator: have code %x, want %x", code, runtime.MarshalBinary())
+	}
+}
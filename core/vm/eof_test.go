@@ -0,0 +1,142 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/erigontech/erigon-lib/common/hexutil"
+)
+
+func TestEOFMarshaling(t *testing.T) {
+	t.Parallel()
+	for i, test := range []struct {
+		want Container
+		err  error
+	}{
+		{
+			want: Container{
+				types:        []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 1}},
+				codeSections: [][]byte{hexutil.MustDecode("0x604200")},
+				data:         []byte{0x01, 0x02, 0x03},
+				dataSize:     3,
+			},
+		},
+		{
+			want: Container{
+				types: []*functionMetadata{
+					{inputs: 0, outputs: 0x80, maxStackIncrease: 1},
+					{inputs: 2, outputs: 3, maxStackIncrease: 4},
+					{inputs: 1, outputs: 1, maxStackIncrease: 1},
+				},
+				codeSections: [][]byte{
+					hexutil.MustDecode("0x604200"),
+					hexutil.MustDecode("0x6042604200"),
+					hexutil.MustDecode("0x00"),
+				},
+				data: []byte{},
+			},
+		},
+	} {
+		var (
+			b   = test.want.MarshalBinary()
+			got Container
+		)
+		if err := got.UnmarshalBinary(b, true); err != nil && err != test.err {
+			t.Fatalf("test %d: got error \"%v\", want \"%v\"", i, err, test.err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Fatalf("test %d: have %v, want %v", i, got, test.want)
+		}
+	}
+}
+
+func TestEOFSubContainers(t *testing.T) {
+	t.Parallel()
+	sub := &Container{
+		types:        []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 0}},
+		codeSections: [][]byte{{byte(STOP)}},
+		data:         []byte{0xaa},
+		dataSize:     3,
+	}
+	c := Container{
+		types:         []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 2}},
+		codeSections:  [][]byte{hexutil.MustDecode("0x60006000ee00")},
+		subContainers: []*Container{sub},
+		data:          []byte{},
+	}
+	b := c.MarshalBinary()
+
+	var got Container
+	if err := got.UnmarshalBinary(b, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got.subContainers) != 1 || !reflect.DeepEqual(got.subContainerCodes[0], sub.MarshalBinary()) {
+		t.Fatalf("subcontainer mismatch: have %x, want %x", got.subContainerCodes, sub.MarshalBinary())
+	}
+	// The data of a subcontainer may be truncated, but not the data of the
+	// top level container.
+	if got.subContainers[0].dataSize != 3 || len(got.subContainers[0].data) != 1 {
+		t.Fatalf("subcontainer data mismatch: have %d/%d, want 1/3", len(got.subContainers[0].data), got.subContainers[0].dataSize)
+	}
+	if err := new(Container).UnmarshalBinary(sub.MarshalBinary(), true); !errors.Is(err, ErrInvalidContainerSize) {
+		t.Fatalf("truncated top level container: have %v, want %v", err, ErrInvalidContainerSize)
+	}
+
+	deployed, err := got.subContainers[0].withAuxData([]byte{0xbb, 0xcc})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var full Container
+	if err := full.UnmarshalBinary(deployed, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(full.data, []byte{0xaa, 0xbb, 0xcc}) {
+		t.Fatalf("data mismatch: have %x, want aabbcc", full.data)
+	}
+	if _, err := got.subContainers[0].withAuxData(nil); !errors.Is(err, ErrInvalidDataSize) {
+		t.Fatalf("short aux data: have %v, want %v", err, ErrInvalidDataSize)
+	}
+}
+
+func TestEOFParseErrors(t *testing.T) {
+	t.Parallel()
+	for i, test := range []struct {
+		code string
+		err  error
+	}{
+		{"0xef01", ErrInvalidMagic},
+		{"0xef0002", ErrInvalidVersion},
+		{"0xef00010200040200010001ff00000000800000fe", ErrMissingTypeHeader},
+		{"0xef00010100030200010001ff00000000800000fe", ErrInvalidTypeSize},
+		{"0xef00010100040300010001ff00000000800000fe", ErrMissingCodeHeader},
+		{"0xef000101000402000000ff000000", ErrInvalidSectionCount},
+		{"0xef00010100040200010000ff00000000800000", ErrInvalidCodeSize},
+		{"0xef000101000402000100010500000000800000fe", ErrMissingDataHeader},
+		{"0xef00010100040200010001ff00000100800000fe", ErrMissingTerminator},
+		{"0xef00010100040200010001ff00000001800000fe", ErrInvalidSection0Type},
+		{"0xef00010100040200010001ff00000000800000fefe", ErrInvalidContainerSize},
+		{"0xef00010100040200010001ff00010000800000fe", ErrInvalidContainerSize},
+	} {
+		var c Container
+		if err := c.UnmarshalBinary(hexutil.MustDecode(test.code), true); !errors.Is(err, test.err) {
+			t.Errorf("test %d: have %v, want %v", i, err, test.err)
+		}
+	}
+}
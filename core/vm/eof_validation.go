@@ -0,0 +1,242 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Below are the reference kinds of a subcontainer, which tell how its code is
+// run and thus which instructions it may contain.
+const (
+	notRefByEither = iota
+	refByReturnContract
+	refByEOFCreate
+)
+
+// immediates holds the size of the immediate arguments of each instruction
+// in EOF code. The size of the RJUMPV immediate is variable and depends on
+// its first byte.
+var immediates [256]uint8
+
+// terminals holds the instructions ending the execution of a code section.
+var terminals [256]bool
+
+func init() {
+	for op := PUSH1; op <= PUSH32; op++ {
+		immediates[op] = uint8(op - PUSH1 + 1)
+	}
+	immediates[DATALOADN] = 2
+	immediates[RJUMP] = 2
+	immediates[RJUMPI] = 2
+	immediates[RJUMPV] = 3
+	immediates[CALLF] = 2
+	immediates[JUMPF] = 2
+	immediates[DUPN] = 1
+	immediates[SWAPN] = 1
+	immediates[EXCHANGE] = 1
+	immediates[EOFCREATE] = 1
+	immediates[RETURNCONTRACT] = 1
+
+	for _, op := range []OpCode{STOP, RETF, JUMPF, RETURNCONTRACT, RETURN, REVERT, INVALID} {
+		terminals[op] = true
+	}
+}
+
+// containerRefs collects the code sections and subcontainers referenced by
+// the code sections of a container.
+type containerRefs struct {
+	codeSections  []bool
+	subContainers []int
+}
+
+// ValidateEOFContainer parses and validates code as an EOF container, as
+// runtime code or, if initcode is set, as the initcode of a creation.
+func ValidateEOFContainer(code []byte, initcode bool) (*Container, error) {
+	var c Container
+	if err := c.UnmarshalBinary(code, true); err != nil {
+		return nil, err
+	}
+	if err := c.validate(&eofInstructionSet, initcode); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// validate checks the code sections of the container and recursively its
+// subcontainers. Code sections must all be reachable from the first one, and
+// subcontainers must all be referenced, either as initcode by EOFCREATE or as
+// runtime code by RETURNCONTRACT but not both.
+func (c *Container) validate(jt *JumpTable, isInitCode bool) error {
+	refs := &containerRefs{
+		codeSections:  make([]bool, len(c.codeSections)),
+		subContainers: make([]int, len(c.subContainers)),
+	}
+	refs.codeSections[0] = true
+	toVisit := []int{0}
+	for len(toVisit) > 0 {
+		section := toVisit[0]
+		toVisit = toVisit[1:]
+		visited := make([]bool, len(refs.codeSections))
+		copy(visited, refs.codeSections)
+		if err := validateCode(c.codeSections[section], section, c, jt, isInitCode, refs); err != nil {
+			return err
+		}
+		for i, ref := range refs.codeSections {
+			if ref && !visited[i] {
+				toVisit = append(toVisit, i)
+			}
+		}
+	}
+	for i, ref := range refs.codeSections {
+		if !ref {
+			return fmt.Errorf("%w: section %d", ErrUnreachableCodeSections, i)
+		}
+	}
+	for i, subContainer := range c.subContainers {
+		switch refs.subContainers[i] {
+		case notRefByEither:
+			return fmt.Errorf("%w: subcontainer %d", ErrOrphanedSubcontainer, i)
+		case refByEOFCreate:
+			if err := subContainer.validate(jt, true); err != nil {
+				return fmt.Errorf("subcontainer %d: %w", i, err)
+			}
+		case refByReturnContract:
+			if err := subContainer.validate(jt, false); err != nil {
+				return fmt.Errorf("subcontainer %d: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateCode validates the code section at the given index of the container
+// as defined by EIP-3670 and its extensions, and records the code sections and
+// subcontainers it references in refs.
+func validateCode(code []byte, section int, container *Container, jt *JumpTable, isInitCode bool, refs *containerRefs) error {
+	var (
+		i         = 0
+		analysis  = make(bitvec, len(code)/64+1)
+		targets   []int
+		returning bool
+		types     = container.types
+	)
+	for i < len(code) {
+		op := OpCode(code[i])
+		if jt[op].undefined {
+			return fmt.Errorf("%w: op %s, pos %d", ErrUndefinedInstruction, op, i)
+		}
+		size := int(immediates[op])
+		if size != 0 && len(code) <= i+size {
+			return fmt.Errorf("%w: op %s, pos %d", ErrTruncatedImmediate, op, i)
+		}
+		switch op {
+		case RJUMP, RJUMPI:
+			targets = append(targets, i+3+int(int16(binary.BigEndian.Uint16(code[i+1:]))))
+		case RJUMPV:
+			count := int(code[i+1]) + 1
+			size = 1 + 2*count
+			if len(code) <= i+size {
+				return fmt.Errorf("%w: op %s, pos %d", ErrTruncatedImmediate, op, i)
+			}
+			for j := 0; j < count; j++ {
+				targets = append(targets, i+1+size+int(int16(binary.BigEndian.Uint16(code[i+2+2*j:]))))
+			}
+		case CALLF:
+			arg := int(binary.BigEndian.Uint16(code[i+1:]))
+			if arg >= len(types) {
+				return fmt.Errorf("%w: arg %d, last %d, pos %d", ErrInvalidSectionArgument, arg, len(types)-1, i)
+			}
+			if !types[arg].returning() {
+				return fmt.Errorf("%w: section %d, pos %d", ErrInvalidCallArgument, arg, i)
+			}
+			refs.codeSections[arg] = true
+		case RETF:
+			returning = true
+		case JUMPF:
+			arg := int(binary.BigEndian.Uint16(code[i+1:]))
+			if arg >= len(types) {
+				return fmt.Errorf("%w: arg %d, last %d, pos %d", ErrInvalidSectionArgument, arg, len(types)-1, i)
+			}
+			if types[arg].returning() {
+				// A returning section can only be reached by JUMPF from a
+				// returning section with at least as many outputs.
+				if !types[section].returning() || types[section].outputs < types[arg].outputs {
+					return fmt.Errorf("%w: section %d, pos %d", ErrJumpfIncompatibleOutputs, arg, i)
+				}
+				returning = true
+			}
+			refs.codeSections[arg] = true
+		case DATALOADN:
+			arg := int(binary.BigEndian.Uint16(code[i+1:]))
+			if arg+32 > container.dataSize {
+				return fmt.Errorf("%w: arg %d, data size %d, pos %d", ErrInvalidDataloadNArgument, arg, container.dataSize, i)
+			}
+		case EOFCREATE:
+			arg := int(code[i+1])
+			if arg >= len(container.subContainers) {
+				return fmt.Errorf("%w: arg %d, pos %d", ErrInvalidContainerArgument, arg, i)
+			}
+			if subContainer := container.subContainers[arg]; len(subContainer.data) < subContainer.dataSize {
+				return fmt.Errorf("%w: container %d, pos %d", ErrEOFCreateWithTruncatedSection, arg, i)
+			}
+			if refs.subContainers[arg] == refByReturnContract {
+				return fmt.Errorf("%w: container %d", ErrAmbiguousContainer, arg)
+			}
+			refs.subContainers[arg] = refByEOFCreate
+		case RETURNCONTRACT:
+			if !isInitCode {
+				return fmt.Errorf("%w: %s in runtime code, pos %d", ErrIncompatibleContainerKind, op, i)
+			}
+			arg := int(code[i+1])
+			if arg >= len(container.subContainers) {
+				return fmt.Errorf("%w: arg %d, pos %d", ErrInvalidContainerArgument, arg, i)
+			}
+			if refs.subContainers[arg] == refByEOFCreate {
+				return fmt.Errorf("%w: container %d", ErrAmbiguousContainer, arg)
+			}
+			refs.subContainers[arg] = refByReturnContract
+		case RETURN, STOP:
+			if isInitCode {
+				return fmt.Errorf("%w: %s in initcode, pos %d", ErrIncompatibleContainerKind, op, i)
+			}
+		}
+		for j := 1; j <= size; j++ {
+			analysis.set1(uint64(i + j))
+		}
+		i += size + 1
+	}
+	// Relative jumps must target the start of an instruction.
+	for _, target := range targets {
+		if target < 0 || target >= len(code) || !analysis.codeSegment(uint64(target)) {
+			return fmt.Errorf("%w: target %d", ErrInvalidJumpDest, target)
+		}
+	}
+	// The section is flagged returning if and only if it can return.
+	if returning != types[section].returning() {
+		return fmt.Errorf("%w: section %d", ErrInvalidNonReturning, section)
+	}
+	height, err := validateControlFlow(code, section, types, jt)
+	if err != nil {
+		return err
+	}
+	if want := int(types[section].inputs) + int(types[section].maxStackIncrease); height != want {
+		return fmt.Errorf("%w: have %d, want %d", ErrInvalidMaxStackHeight, height, want)
+	}
+	return nil
+}
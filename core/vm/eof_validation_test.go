@@ -0,0 +1,134 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/erigontech/erigon-lib/common/hexutil"
+)
+
+func TestValidateCode(t *testing.T) {
+	t.Parallel()
+	for i, test := range []struct {
+		code     string
+		section  int
+		metadata []*functionMetadata
+		err      error
+	}{
+		{"0x00", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 0}}, nil},
+		{"0x60015000", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 1}}, nil},
+		{"0x5fe100010000", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 1}}, nil},
+		{"0x5fe201000000010000", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 1}}, nil},
+		{"0xe3000100", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 0}, {inputs: 0, outputs: 0, maxStackIncrease: 0}}, nil},
+		{"0xe4", 1, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 0}, {inputs: 0, outputs: 0, maxStackIncrease: 0}}, nil},
+		{"0x5f5fe50001", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 2}, {inputs: 2, outputs: 0x80, maxStackIncrease: 0}}, nil},
+		{"0xd100005000", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 1}}, nil},
+		{"0xd100015000", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 1}}, ErrInvalidDataloadNArgument},
+		{"0x5f5fe700e60050505000", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 3}}, nil},
+		{"0x5fe800", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 1}}, ErrEOFStackUnderflow},
+		{"0x5600", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 0}}, ErrUndefinedInstruction},
+		{"0x5a5000", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 1}}, ErrUndefinedInstruction},
+		{"0x60", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 1}}, ErrTruncatedImmediate},
+		{"0xe0fffe", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 0}}, ErrInvalidJumpDest},
+		{"0xe0000100", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 0}}, ErrInvalidJumpDest},
+		{"0x5f50", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 1}}, ErrInvalidCodeTermination},
+		{"0x5000", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 0}}, ErrEOFStackUnderflow},
+		{"0xe3000500", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 0}}, ErrInvalidSectionArgument},
+		{"0xe3000100", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 0}, {inputs: 0, outputs: 0x80, maxStackIncrease: 0}}, ErrInvalidCallArgument},
+		{"0x0000", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 0}}, ErrUnreachableCode},
+		{"0x60015000", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 2}}, ErrInvalidMaxStackHeight},
+		{"0x5fe0fffc", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 1}}, ErrInvalidBackwardJump},
+		{"0xe4", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 0}}, ErrInvalidNonReturning},
+		{"0x00", 1, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 0}, {inputs: 0, outputs: 0, maxStackIncrease: 0}}, ErrInvalidNonReturning},
+		{"0x5fe4", 1, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 0}, {inputs: 0, outputs: 0, maxStackIncrease: 1}}, ErrInvalidOutputs},
+		{"0xe50001", 0, []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: 0}, {inputs: 0, outputs: 0, maxStackIncrease: 0}}, ErrJumpfIncompatibleOutputs},
+	} {
+		container := &Container{
+			types:    test.metadata,
+			data:     make([]byte, 32),
+			dataSize: 32,
+		}
+		refs := &containerRefs{codeSections: make([]bool, len(test.metadata))}
+		err := validateCode(hexutil.MustDecode(test.code), test.section, container, &eofInstructionSet, false, refs)
+		if !errors.Is(err, test.err) {
+			t.Errorf("test %d (%s): unexpected error (want: %v, got: %v)", i, test.code, test.err, err)
+		}
+	}
+}
+
+func TestValidateEOFContainer(t *testing.T) {
+	t.Parallel()
+	var (
+		nonReturning = func(maxStackIncrease uint16) []*functionMetadata {
+			return []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackIncrease: maxStackIncrease}}
+		}
+		runtime = &Container{
+			types:        nonReturning(0),
+			codeSections: [][]byte{{byte(STOP)}},
+			data:         []byte{},
+		}
+		// PUSH0 PUSH0 RETURNCONTRACT 0
+		initcode = &Container{
+			types:         nonReturning(2),
+			codeSections:  [][]byte{hexutil.MustDecode("0x5f5fee00")},
+			subContainers: []*Container{runtime},
+			data:          []byte{},
+		}
+	)
+	for i, test := range []struct {
+		container *Container
+		initcode  bool
+		err       error
+	}{
+		{runtime, false, nil},
+		{runtime, true, ErrIncompatibleContainerKind},
+		{initcode, true, nil},
+		{initcode, false, ErrIncompatibleContainerKind},
+		// PUSH0 PUSH0 PUSH0 PUSH0 EOFCREATE 0 POP STOP
+		{&Container{
+			types:         nonReturning(4),
+			codeSections:  [][]byte{hexutil.MustDecode("0x5f5f5f5fec005000")},
+			subContainers: []*Container{initcode},
+			data:          []byte{},
+		}, false, nil},
+		{&Container{
+			types:         nonReturning(0),
+			codeSections:  [][]byte{{byte(STOP)}},
+			subContainers: []*Container{initcode},
+			data:          []byte{},
+		}, false, ErrOrphanedSubcontainer},
+		// EOFCREATE 0 and RETURNCONTRACT 0 in the same container
+		{&Container{
+			types:         nonReturning(4),
+			codeSections:  [][]byte{hexutil.MustDecode("0x5f5f5f5fec0050ee00")},
+			subContainers: []*Container{initcode},
+			data:          []byte{},
+		}, true, ErrAmbiguousContainer},
+		{&Container{
+			types:        append(nonReturning(0), &functionMetadata{inputs: 0, outputs: 0, maxStackIncrease: 0}),
+			codeSections: [][]byte{{byte(STOP)}, {byte(RETF)}},
+			data:         []byte{},
+		}, false, ErrUnreachableCodeSections},
+	} {
+		_, err := ValidateEOFContainer(test.container.MarshalBinary(), test.initcode)
+		if !errors.Is(err, test.err) {
+			t.Errorf("test %d: unexpected error (want: %v, got: %v)", i, test.err, err)
+		}
+	}
+}
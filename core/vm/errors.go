@@ -45,12 +45,63 @@ var (
 	ErrReturnStackExceeded      = errors.New("return stack limit reached")
 	ErrInvalidCode              = errors.New("invalid code")
 	ErrNonceUintOverflow        = errors.New("nonce uint64 overflow")
+	ErrInvalidAddress           = errors.New("invalid address")
+
+	// ErrPrecompileNotRegistered is returned by a call to the address of a
+	// custom precompile of the chain config that is not registered.
+	ErrPrecompileNotRegistered = errors.New("precompile is not registered")
+
+	// ErrInvalidEOFInitcode is returned by a creation transaction whose EOF
+	// initcode fails validation.
+	ErrInvalidEOFInitcode = errors.New("invalid eof initcode")
 
 	// errStopToken is an internal token indicating interpreter loop termination,
 	// never returned to outside callers.
 	errStopToken = errors.New("stop token")
 )
 
+// EOF container and code validation errors, see EIP-3540, EIP-3670,
+// EIP-4200, EIP-4750, EIP-5450, EIP-6206 and EIP-7620.
+var (
+	ErrInvalidMagic                  = errors.New("invalid magic")
+	ErrInvalidVersion                = errors.New("invalid version")
+	ErrMissingTypeHeader             = errors.New("missing type header")
+	ErrInvalidTypeSize               = errors.New("invalid type section size")
+	ErrMissingCodeHeader             = errors.New("missing code header")
+	ErrInvalidCodeSize               = errors.New("invalid code size")
+	ErrInvalidSectionCount           = errors.New("invalid section count")
+	ErrInvalidContainerSectionSize   = errors.New("invalid container section size")
+	ErrMissingDataHeader             = errors.New("missing data header")
+	ErrMissingTerminator             = errors.New("missing header terminator")
+	ErrInvalidContainerSize          = errors.New("invalid container size")
+	ErrTooManyInputs                 = errors.New("invalid type content, too many inputs")
+	ErrTooManyOutputs                = errors.New("invalid type content, too many outputs")
+	ErrTooLargeMaxStackHeight        = errors.New("invalid type content, max stack height exceeds limit")
+	ErrInvalidSection0Type           = errors.New("invalid section 0 type, input and output should be zero and non-returning (0x80)")
+	ErrUndefinedInstruction          = errors.New("undefined instruction")
+	ErrTruncatedImmediate            = errors.New("truncated immediate")
+	ErrInvalidSectionArgument        = errors.New("invalid section argument")
+	ErrInvalidCallArgument           = errors.New("callf into non-returning section")
+	ErrInvalidDataloadNArgument      = errors.New("invalid dataloadN argument")
+	ErrInvalidJumpDest               = errors.New("invalid jump destination")
+	ErrInvalidBackwardJump           = errors.New("invalid backward jump")
+	ErrInvalidOutputs                = errors.New("invalid number of outputs")
+	ErrInvalidMaxStackHeight         = errors.New("invalid max stack height")
+	ErrInvalidCodeTermination        = errors.New("invalid code termination")
+	ErrEOFStackUnderflow             = errors.New("stack underflow")
+	ErrEOFStackOverflow              = errors.New("stack overflow")
+	ErrUnreachableCode               = errors.New("unreachable code")
+	ErrUnreachableCodeSections       = errors.New("unreachable code sections")
+	ErrInvalidNonReturning           = errors.New("invalid non-returning flag, bad RETF")
+	ErrJumpfIncompatibleOutputs      = errors.New("jumpf into section with incompatible outputs")
+	ErrInvalidContainerArgument      = errors.New("invalid container argument")
+	ErrEOFCreateWithTruncatedSection = errors.New("eofcreate with truncated container")
+	ErrOrphanedSubcontainer          = errors.New("subcontainer not referenced at all")
+	ErrIncompatibleContainerKind     = errors.New("incompatible container kind")
+	ErrAmbiguousContainer            = errors.New("container referenced by both eofcreate and returncontract")
+	ErrInvalidDataSize               = errors.New("invalid data section size")
+)
+
 // ErrStackUnderflow wraps an evm error when the items on the stack less
 // than the minimal requirement.
 type ErrStackUnderflow struct {
@@ -20,6 +20,7 @@
 package vm
 
 import (
+	"fmt"
 	"sync/atomic"
 
 	"github.com/holiman/uint256"
@@ -37,7 +38,10 @@ import (
 
 var emptyHash = libcommon.Hash{}
 
-func (evm *EVM) precompile(addr libcommon.Address) (PrecompiledContract, bool) {
+func (evm *EVM) precompile(addr libcommon.Address) (PrecompiledContract, bool, error) {
+	if p, ok, err := customPrecompile(evm.chainRules, addr); ok {
+		return p, true, err
+	}
 	var precompiles map[libcommon.Address]PrecompiledContract
 	switch {
 	case evm.chainRules.IsPrague:
@@ -56,7 +60,7 @@ func (evm *EVM) precompile(addr libcommon.Address) (PrecompiledContract, bool) {
 		precompiles = PrecompiledContractsHomestead
 	}
 	p, ok := precompiles[addr]
-	return p, ok
+	return p, ok, nil
 }
 
 // run runs the given contract and takes care of running precompiles with a fallback to the byte code interpreter.
@@ -191,7 +195,10 @@ func (evm *EVM) call(typ OpCode, caller ContractRef, addr libcommon.Address, inp
 			}
 		}
 	}
-	p, isPrecompile := evm.precompile(addr)
+	p, isPrecompile, err := evm.precompile(addr)
+	if err != nil {
+		return nil, gas, err
+	}
 	var code []byte
 	if !isPrecompile {
 		code = evm.intraBlockState.ResolveCode(addr)
@@ -276,11 +283,23 @@ func (evm *EVM) call(typ OpCode, caller ContractRef, addr libcommon.Address, inp
 			contract = NewContract(caller, addrCopy, value, gas, evm.config.SkipAnalysis, evm.JumpDestCache)
 		}
 		contract.SetCallCode(&addrCopy, codeHash, code)
-		readOnly := false
-		if typ == STATICCALL {
-			readOnly = true
+		if evm.chainRules.IsOsaka && hasEOFMagic(code) {
+			// Deployed EOF code is validated, so failing to parse it means
+			// the account holds invalid code.
+			var container Container
+			if err = container.UnmarshalBinary(code, true); err != nil {
+				err = ErrInvalidCode
+			} else {
+				contract.SetEOFContainer(&container)
+			}
+		}
+		if err == nil {
+			readOnly := false
+			if typ == STATICCALL {
+				readOnly = true
+			}
+			ret, err = run(evm, contract, input, readOnly)
 		}
-		ret, err = run(evm, contract, input, readOnly)
 		gas = contract.Gas
 	}
 	// When an error was returned by the EVM or when setting the creation code
@@ -335,8 +354,9 @@ func (evm *EVM) StaticCall(caller ContractRef, addr libcommon.Address, input []b
 }
 
 type codeAndHash struct {
-	code []byte
-	hash libcommon.Hash
+	code      []byte
+	hash      libcommon.Hash
+	container *Container // parsed EOF initcode, nil for legacy code
 }
 
 func NewCodeAndHash(code []byte) *codeAndHash {
@@ -351,11 +371,12 @@ func (c *codeAndHash) Hash() libcommon.Hash {
 }
 
 func (evm *EVM) OverlayCreate(caller ContractRef, codeAndHash *codeAndHash, gas uint64, value *uint256.Int, address libcommon.Address, typ OpCode, incrementNonce bool) ([]byte, libcommon.Address, uint64, error) {
-	return evm.create(caller, codeAndHash, gas, value, address, typ, incrementNonce, false)
+	return evm.create(caller, codeAndHash, nil, gas, value, address, typ, incrementNonce, false)
 }
 
-// create creates a new contract using code as deployment code.
-func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gasRemaining uint64, value *uint256.Int, address libcommon.Address, typ OpCode, incrementNonce bool, bailout bool) ([]byte, libcommon.Address, uint64, error) {
+// create creates a new contract using code as deployment code. The input is
+// only passed to EOF initcode, legacy initcode gets no calldata.
+func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, input []byte, gasRemaining uint64, value *uint256.Int, address libcommon.Address, typ OpCode, incrementNonce bool, bailout bool) ([]byte, libcommon.Address, uint64, error) {
 	var ret []byte
 	var err error
 	var gasConsumption uint64
@@ -423,7 +444,7 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gasRemainin
 		return nil, address, gasRemaining, nil
 	}
 
-	ret, err = run(evm, contract, nil, false)
+	ret, err = run(evm, contract, input, false)
 
 	// EIP-170: Contract code size limit
 	if err == nil && evm.chainRules.IsSpuriousDragon && len(ret) > evm.maxCodeSize() {
@@ -434,8 +455,9 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gasRemainin
 		}
 	}
 
-	// Reject code starting with 0xEF if EIP-3541 is enabled.
-	if err == nil && evm.chainRules.IsLondon && len(ret) >= 1 && ret[0] == 0xEF {
+	// Reject code starting with 0xEF if EIP-3541 is enabled, unless it is
+	// deployed by EOF initcode, which only returns valid EOF containers.
+	if err == nil && evm.chainRules.IsLondon && codeAndHash.container == nil && len(ret) >= 1 && ret[0] == 0xEF {
 		err = ErrInvalidCode
 	}
 	// if the contract creation ran successfully and no errors were returned
@@ -478,7 +500,32 @@ func (evm *EVM) maxCodeSize() int {
 // DESCRIBED: docs/programmers_guide/guide.md#nonce
 func (evm *EVM) Create(caller ContractRef, code []byte, gasRemaining uint64, endowment *uint256.Int, bailout bool) (ret []byte, contractAddr libcommon.Address, leftOverGas uint64, err error) {
 	contractAddr = crypto.CreateAddress(caller.Address(), evm.intraBlockState.GetNonce(caller.Address()))
-	return evm.create(caller, &codeAndHash{code: code}, gasRemaining, endowment, contractAddr, CREATE, true /* incrementNonce */, bailout)
+	if evm.chainRules.IsOsaka && evm.interpreter.Depth() == 0 && hasEOFMagic(code) {
+		return evm.createEOF(caller, code, gasRemaining, endowment, contractAddr, bailout)
+	}
+	return evm.create(caller, &codeAndHash{code: code}, nil, gasRemaining, endowment, contractAddr, CREATE, true /* incrementNonce */, bailout)
+}
+
+// createEOF runs a creation transaction with EOF initcode, as defined by
+// EIP-7698. The transaction data is the initcontainer followed by the calldata
+// passed to it. If the initcontainer is invalid, the nonce of the sender is
+// still incremented and all the gas is consumed.
+func (evm *EVM) createEOF(caller ContractRef, data []byte, gasRemaining uint64, endowment *uint256.Int, contractAddr libcommon.Address, bailout bool) ([]byte, libcommon.Address, uint64, error) {
+	size, err := eofContainerSize(data)
+	var container *Container
+	if err == nil {
+		container, err = ValidateEOFContainer(data[:size], true)
+	}
+	if err != nil {
+		nonce := evm.intraBlockState.GetNonce(caller.Address())
+		if nonce+1 < nonce {
+			return nil, libcommon.Address{}, gasRemaining, ErrNonceUintOverflow
+		}
+		evm.intraBlockState.SetNonce(caller.Address(), nonce+1)
+		return nil, contractAddr, 0, fmt.Errorf("%w: %w", ErrInvalidEOFInitcode, err)
+	}
+	initCode := &codeAndHash{code: data[:size], container: container}
+	return evm.create(caller, initCode, data[size:], gasRemaining, endowment, contractAddr, CREATE, true /* incrementNonce */, bailout)
 }
 
 // Create2 creates a new contract using code as deployment code.
@@ -489,13 +536,22 @@ func (evm *EVM) Create(caller ContractRef, code []byte, gasRemaining uint64, end
 func (evm *EVM) Create2(caller ContractRef, code []byte, gasRemaining uint64, endowment *uint256.Int, salt *uint256.Int, bailout bool) (ret []byte, contractAddr libcommon.Address, leftOverGas uint64, err error) {
 	codeAndHash := &codeAndHash{code: code}
 	contractAddr = crypto.CreateAddress2(caller.Address(), salt.Bytes32(), codeAndHash.Hash().Bytes())
-	return evm.create(caller, codeAndHash, gasRemaining, endowment, contractAddr, CREATE2, true /* incrementNonce */, bailout)
+	return evm.create(caller, codeAndHash, nil, gasRemaining, endowment, contractAddr, CREATE2, true /* incrementNonce */, bailout)
+}
+
+// EOFCreate creates a new contract from an EOF initcontainer, as done by the
+// EOFCREATE opcode of EIP-7620. The container has already been validated as
+// part of the calling code, and the input is passed to it as calldata.
+func (evm *EVM) EOFCreate(caller ContractRef, container *Container, code []byte, input []byte, gasRemaining uint64, endowment *uint256.Int, salt *uint256.Int) (ret []byte, contractAddr libcommon.Address, leftOverGas uint64, err error) {
+	codeAndHash := &codeAndHash{code: code, container: container}
+	contractAddr = crypto.CreateAddress2(caller.Address(), salt.Bytes32(), codeAndHash.Hash().Bytes())
+	return evm.create(caller, codeAndHash, input, gasRemaining, endowment, contractAddr, EOFCREATE, true /* incrementNonce */, false)
 }
 
 // SysCreate is a special (system) contract creation methods for genesis constructors.
 // Unlike the normal Create & Create2, it doesn't increment caller's nonce.
 func (evm *EVM) SysCreate(caller ContractRef, code []byte, gas uint64, endowment *uint256.Int, contractAddr libcommon.Address) (ret []byte, leftOverGas uint64, err error) {
-	ret, _, leftOverGas, err = evm.create(caller, &codeAndHash{code: code}, gas, endowment, contractAddr, CREATE, false /* incrementNonce */, false)
+	ret, _, leftOverGas, err = evm.create(caller, &codeAndHash{code: code}, nil, gas, endowment, contractAddr, CREATE, false /* incrementNonce */, false)
 	return
 }
 
@@ -99,6 +99,7 @@ type keccakState interface {
 type EVMInterpreter struct {
 	*VM
 	jt    *JumpTable // EVM instruction table
+	eofJt *JumpTable // EVM instruction table of EOF code, nil before EOF activation
 	depth int
 }
 
@@ -129,8 +130,11 @@ func copyJumpTable(jt *JumpTable) *JumpTable {
 
 // NewEVMInterpreter returns a new instance of the Interpreter.
 func NewEVMInterpreter(evm *EVM, cfg Config) *EVMInterpreter {
-	var jt *JumpTable
+	var jt, eofJt *JumpTable
 	switch {
+	case evm.ChainRules().IsOsaka:
+		jt = &osakaInstructionSet
+		eofJt = &eofInstructionSet
 	case evm.ChainRules().IsPrague:
 		jt = &pragueInstructionSet
 	case evm.ChainRules().IsCancun:
@@ -167,6 +171,15 @@ func NewEVMInterpreter(evm *EVM, cfg Config) *EVMInterpreter {
 				log.Error("EIP activation failed", "eip", eip, "err", err)
 			}
 		}
+		if eofJt != nil {
+			// EOF code sees the same extra EIPs as legacy code
+			eofJt = copyJumpTable(eofJt)
+			for _, eip := range cfg.ExtraEips {
+				if err := EnableEIP(eip, eofJt); err != nil {
+					log.Error("EIP activation failed", "eip", eip, "err", err)
+				}
+			}
+		}
 	}
 
 	return &EVMInterpreter{
@@ -174,7 +187,8 @@ func NewEVMInterpreter(evm *EVM, cfg Config) *EVMInterpreter {
 			evm: evm,
 			cfg: cfg,
 		},
-		jt: jt,
+		jt:    jt,
+		eofJt: eofJt,
 	}
 }
 
@@ -220,6 +234,11 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 
 	contract.Input = input
 
+	jt := in.jt
+	if contract.IsEOF() {
+		jt = in.eofJt
+	}
+
 	// Make sure the readOnly is only set if we aren't in readOnly yet.
 	// This makes also sure that the readOnly flag isn't removed for child calls.
 	restoreReadonly := readOnly && !in.readOnly
@@ -263,7 +282,7 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 		// Get the operation from the jump table and validate the stack to ensure there are
 		// enough stack items available to perform the operation.
 		op = contract.GetOp(_pc)
-		operation := in.jt[op]
+		operation := jt[op]
 		cost = operation.constantGas // For tracing
 		// Validate stack
 		if sLen := locStack.Len(); sLen < operation.numPop {
@@ -51,6 +51,8 @@ type operation struct {
 	opNum   int // only for push, swap, dup
 	// memorySize returns the memory size required for the operation
 	memorySize memorySizeFunc
+	// undefined denotes if the instruction is not officially defined in the jump table
+	undefined bool
 }
 
 var (
@@ -67,8 +69,18 @@ var (
 	napoliInstructionSet           = newNapoliInstructionSet()
 	cancunInstructionSet           = newCancunInstructionSet()
 	pragueInstructionSet           = newPragueInstructionSet()
+	osakaInstructionSet            = newOsakaInstructionSet()
 )
 
+// eofInstructionSet is built in init rather than in the block above, since its
+// instructions lead back to it through the validation of EOF creation
+// transactions in evm.Create.
+var eofInstructionSet JumpTable
+
+func init() {
+	eofInstructionSet = newEOFInstructionSet()
+}
+
 // JumpTable contains the EVM opcodes supported at a given fork.
 type JumpTable [256]*operation
 
@@ -90,6 +102,26 @@ func validateAndFillMaxStack(jt *JumpTable) {
 	}
 }
 
+// newEOFInstructionSet returns the instructions of EOF code, which replaces
+// the instructions depending on the code layout or observing gas with the EOF
+// ones.
+func newEOFInstructionSet() JumpTable {
+	instructionSet := newOsakaInstructionSet()
+	enableEOF(&instructionSet)
+	validateAndFillMaxStack(&instructionSet)
+	return instructionSet
+}
+
+// newOsakaInstructionSet returns the frontier, homestead, byzantium,
+// constantinople, istanbul, petersburg, berlin, london, paris, shanghai,
+// cancun, prague and osaka instructions of legacy code.
+func newOsakaInstructionSet() JumpTable {
+	instructionSet := newPragueInstructionSet()
+	enable3540(&instructionSet) // EIP-3540: legacy code introspection of EOF code
+	validateAndFillMaxStack(&instructionSet)
+	return instructionSet
+}
+
 // newPragueInstructionSet returns the frontier, homestead, byzantium,
 // constantinople, istanbul, petersburg, berlin, london, paris, shanghai,
 // cancun, and prague instructions.
@@ -1215,7 +1247,7 @@ func newFrontierInstructionSet() JumpTable {
 	// Fill all unassigned slots with opUndefined.
 	for i, entry := range tbl {
 		if entry == nil {
-			tbl[i] = &operation{execute: opUndefined}
+			tbl[i] = &operation{execute: opUndefined, undefined: true}
 		}
 	}
 
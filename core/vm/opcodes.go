@@ -208,6 +208,29 @@ const (
 	LOG4
 )
 
+// 0xd0 range - EOF data section ops.
+const (
+	DATALOAD  OpCode = 0xd0
+	DATALOADN OpCode = 0xd1
+	DATASIZE  OpCode = 0xd2
+	DATACOPY  OpCode = 0xd3
+)
+
+// 0xe0 range - EOF control flow and stack ops.
+const (
+	RJUMP          OpCode = 0xe0
+	RJUMPI         OpCode = 0xe1
+	RJUMPV         OpCode = 0xe2
+	CALLF          OpCode = 0xe3
+	RETF           OpCode = 0xe4
+	JUMPF          OpCode = 0xe5
+	DUPN           OpCode = 0xe6
+	SWAPN          OpCode = 0xe7
+	EXCHANGE       OpCode = 0xe8
+	EOFCREATE      OpCode = 0xec
+	RETURNCONTRACT OpCode = 0xee
+)
+
 // 0xf0 range - closures.
 const (
 	CREATE OpCode = 0xf0 + iota
@@ -222,6 +245,14 @@ const (
 	SELFDESTRUCT OpCode = 0xff
 )
 
+// 0xf0 range - EOF calls.
+const (
+	RETURNDATALOAD  OpCode = 0xf7
+	EXTCALL         OpCode = 0xf8
+	EXTDELEGATECALL OpCode = 0xf9
+	EXTSTATICCALL   OpCode = 0xfb
+)
+
 // Since the opcodes aren't all in order we can't use a regular slice.
 var opCodeToString = map[OpCode]string{
 	// 0x0 range - arithmetic ops.
@@ -392,6 +423,27 @@ var opCodeToString = map[OpCode]string{
 	REVERT:       "REVERT",
 	INVALID:      "INVALID",
 	SELFDESTRUCT: "SELFDESTRUCT",
+
+	// EOF ops.
+	DATALOAD:        "DATALOAD",
+	DATALOADN:       "DATALOADN",
+	DATASIZE:        "DATASIZE",
+	DATACOPY:        "DATACOPY",
+	RJUMP:           "RJUMP",
+	RJUMPI:          "RJUMPI",
+	RJUMPV:          "RJUMPV",
+	CALLF:           "CALLF",
+	RETF:            "RETF",
+	JUMPF:           "JUMPF",
+	DUPN:            "DUPN",
+	SWAPN:           "SWAPN",
+	EXCHANGE:        "EXCHANGE",
+	EOFCREATE:       "EOFCREATE",
+	RETURNCONTRACT:  "RETURNCONTRACT",
+	RETURNDATALOAD:  "RETURNDATALOAD",
+	EXTCALL:         "EXTCALL",
+	EXTDELEGATECALL: "EXTDELEGATECALL",
+	EXTSTATICCALL:   "EXTSTATICCALL",
 }
 
 func (op OpCode) String() string {
@@ -553,6 +605,26 @@ var stringToOp = map[string]OpCode{
 	"REVERT":         REVERT,
 	"INVALID":        INVALID,
 	"SELFDESTRUCT":   SELFDESTRUCT,
+
+	"DATALOAD":        DATALOAD,
+	"DATALOADN":       DATALOADN,
+	"DATASIZE":        DATASIZE,
+	"DATACOPY":        DATACOPY,
+	"RJUMP":           RJUMP,
+	"RJUMPI":          RJUMPI,
+	"RJUMPV":          RJUMPV,
+	"CALLF":           CALLF,
+	"RETF":            RETF,
+	"JUMPF":           JUMPF,
+	"DUPN":            DUPN,
+	"SWAPN":           SWAPN,
+	"EXCHANGE":        EXCHANGE,
+	"EOFCREATE":       EOFCREATE,
+	"RETURNCONTRACT":  RETURNCONTRACT,
+	"RETURNDATALOAD":  RETURNDATALOAD,
+	"EXTCALL":         EXTCALL,
+	"EXTDELEGATECALL": EXTDELEGATECALL,
+	"EXTSTATICCALL":   EXTSTATICCALL,
 }
 
 // StringToOp finds the opcode whose name is stored in `str`.
@@ -0,0 +1,102 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/erigontech/erigon-lib/chain"
+	libcommon "github.com/erigontech/erigon-lib/common"
+)
+
+// The registry of the precompiles of custom chains: a binary embedding Erigon
+// registers its precompiles by name, and the chain config of a custom chain
+// lists under which address and from when they run (see chain.CustomPrecompile).
+var (
+	registeredPrecompiles   = map[string]PrecompiledContract{}
+	registeredPrecompilesMu sync.RWMutex
+)
+
+// RegisterPrecompile registers a precompile under a name, for the chain
+// configs to refer to it. It is meant to be called on init, before any block
+// is executed, by every binary executing the custom chain - including
+// standalone RPC daemons. It panics if the name is already registered.
+func RegisterPrecompile(name string, p PrecompiledContract) {
+	registeredPrecompilesMu.Lock()
+	defer registeredPrecompilesMu.Unlock()
+	if p == nil {
+		panic("vm: RegisterPrecompile of nil precompile " + name)
+	}
+	if _, ok := registeredPrecompiles[name]; ok {
+		panic("vm: RegisterPrecompile called twice for " + name)
+	}
+	registeredPrecompiles[name] = p
+}
+
+// RegisteredPrecompile returns the precompile registered under a name.
+func RegisteredPrecompile(name string) (PrecompiledContract, bool) {
+	registeredPrecompilesMu.RLock()
+	defer registeredPrecompilesMu.RUnlock()
+	p, ok := registeredPrecompiles[name]
+	return p, ok
+}
+
+// CheckPrecompiles checks that the custom precompiles of a chain config are
+// registered.
+func CheckPrecompiles(config *chain.Config) error {
+	for _, p := range config.Precompiles {
+		if _, ok := RegisteredPrecompile(p.Name); !ok {
+			return fmt.Errorf("%w: %q at %x", ErrPrecompileNotRegistered, p.Name, p.Address)
+		}
+	}
+	return nil
+}
+
+// customPrecompile returns the custom precompile active at an address. The
+// address of a configured precompile missing from the registry never runs as
+// a normal account: it returns ErrPrecompileNotRegistered instead.
+func customPrecompile(rules *chain.Rules, addr libcommon.Address) (PrecompiledContract, bool, error) {
+	name, ok := rules.Precompiles[addr]
+	if !ok {
+		return nil, false, nil
+	}
+	p, ok := RegisteredPrecompile(name)
+	if !ok {
+		return nil, true, fmt.Errorf("%w: %q at %x", ErrPrecompileNotRegistered, name, addr)
+	}
+	return p, true, nil
+}
+
+// withCustomPrecompiles adds the addresses of the active custom precompiles
+// to those of the standard ones, sorted for the list not to depend on the
+// iteration order of the rules.
+func withCustomPrecompiles(rules *chain.Rules, addresses []libcommon.Address) []libcommon.Address {
+	if len(rules.Precompiles) == 0 {
+		return addresses
+	}
+	custom := make([]libcommon.Address, 0, len(rules.Precompiles))
+	for addr := range rules.Precompiles {
+		if !slices.Contains(addresses, addr) {
+			custom = append(custom, addr)
+		}
+	}
+	slices.SortFunc(custom, func(a, b libcommon.Address) int { return bytes.Compare(a[:], b[:]) })
+	return append(slices.Clip(addresses), custom...)
+}
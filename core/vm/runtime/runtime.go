@@ -59,6 +59,8 @@ type Config struct {
 	Debug       bool
 	EVMConfig   vm.Config
 	BaseFee     *uint256.Int
+	BlobBaseFee *uint256.Int
+	Random      *libcommon.Hash // PREVRANDAO, DIFFICULTY returns Difficulty if nil
 
 	State     *state.IntraBlockState
 	r         state.StateReader
@@ -24,7 +24,9 @@ import (
 	"fmt"
 	"math/big"
 	"os"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/holiman/uint256"
@@ -49,6 +51,7 @@ import (
 	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/core/vm"
 	"github.com/erigontech/erigon/eth/tracers/logger"
+	"github.com/erigontech/erigon/params"
 	"github.com/erigontech/erigon/rlp"
 )
 
@@ -173,6 +176,82 @@ func TestCall(t *testing.T) {
 	}
 }
 
+// echoPrecompile returns its input.
+type echoPrecompile struct{}
+
+func (echoPrecompile) RequiredGas(input []byte) uint64  { return 100 }
+func (echoPrecompile) Run(input []byte) ([]byte, error) { return input, nil }
+
+// registerEchoPrecompile registers the echo precompile once, the tests being
+// possibly run more than once.
+var registerEchoPrecompile sync.Once
+
+func TestCustomPrecompile(t *testing.T) {
+	t.Parallel()
+	registerEchoPrecompile.Do(func() { vm.RegisterPrecompile("runtime-test-echo", echoPrecompile{}) })
+
+	var (
+		custom   = libcommon.HexToAddress("0x0100")
+		other    = libcommon.HexToAddress("0x00ff")
+		sha256   = libcommon.BytesToAddress([]byte{2})
+		input    = []byte{1, 2, 3}
+		chainCfg = *params.AllProtocolChanges
+	)
+	chainCfg.Precompiles = []chain.CustomPrecompile{
+		{Name: "runtime-test-echo", Address: custom, Time: big.NewInt(10)},
+		{Name: "runtime-test-echo", Address: sha256},
+		{Name: "runtime-test-echo", Address: other},
+	}
+	require.NoError(t, vm.CheckPrecompiles(&chainCfg))
+	require.ErrorContains(t, vm.CheckPrecompiles(&chain.Config{Precompiles: []chain.CustomPrecompile{{Name: "runtime-test-missing"}}}), "not registered")
+
+	for _, tt := range []struct {
+		time   int64
+		active bool
+	}{{9, false}, {10, true}} {
+		_, tx, _ := NewTestTemporalDb(t)
+		domains, err := stateLib.NewSharedDomains(tx, log.New())
+		require.NoError(t, err)
+		defer domains.Close()
+		cfg := &Config{State: state.New(state.NewReaderV3(domains)), ChainConfig: &chainCfg, Time: big.NewInt(tt.time), GasLimit: 10_000}
+
+		ret, leftOverGas, err := Call(custom, input, cfg)
+		require.NoError(t, err)
+		require.Equal(t, tt.active, slices.Contains(vm.ActivePrecompiles(chainCfg.Rules(0, uint64(tt.time))), custom))
+		if tt.active {
+			require.Equal(t, input, ret)
+			require.Equal(t, uint64(10_000-100), leftOverGas)
+		} else {
+			require.Empty(t, ret)
+		}
+
+		// the standard precompile is replaced from genesis
+		ret, _, err = Call(sha256, input, cfg)
+		require.NoError(t, err)
+		require.Equal(t, input, ret)
+	}
+
+	// the custom precompiles follow the standard ones, sorted by address
+	for i := 0; i < 10; i++ {
+		active := vm.ActivePrecompiles(chainCfg.Rules(0, 10))
+		require.Equal(t, []libcommon.Address{other, custom}, active[len(active)-2:])
+	}
+
+	// a configured precompile missing from the registry fails instead of running as an account
+	missing := libcommon.HexToAddress("0x0200")
+	missingCfg := *params.AllProtocolChanges
+	missingCfg.Precompiles = []chain.CustomPrecompile{{Name: "runtime-test-missing", Address: missing}}
+	require.ErrorIs(t, vm.CheckPrecompiles(&missingCfg), vm.ErrPrecompileNotRegistered)
+	_, tx, _ := NewTestTemporalDb(t)
+	domains, err := stateLib.NewSharedDomains(tx, log.New())
+	require.NoError(t, err)
+	defer domains.Close()
+	cfg := &Config{State: state.New(state.NewReaderV3(domains)), ChainConfig: &missingCfg, GasLimit: 10_000}
+	_, _, err = Call(missing, input, cfg)
+	require.ErrorIs(t, err, vm.ErrPrecompileNotRegistered)
+	require.Contains(t, vm.ActivePrecompiles(missingCfg.Rules(0, 0)), missing)
+}
+
 func testTemporalDB(t testing.TB) *temporal.DB {
 	db := memdb.NewStateDB(t.TempDir())
 
@@ -82,6 +82,9 @@ type Config struct {
 	// See also EIP-6110: Supply validator deposits on chain
 	DepositContract common.Address `json:"depositContractAddress,omitempty"`
 
+	// (Optional) precompiles of custom chains, implemented by the binary
+	Precompiles []CustomPrecompile `json:"precompiles,omitempty"`
+
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty"`
 	Clique *CliqueConfig `json:"clique,omitempty"`
@@ -91,6 +94,16 @@ type Config struct {
 	BorJSON json.RawMessage `json:"bor,omitempty"`
 }
 
+// CustomPrecompile is a precompile of a custom chain: the precompile
+// registered under Name in core/vm by the binary runs at Address from Time.
+// A precompile may replace a standard one, or one listed before it at the
+// same address.
+type CustomPrecompile struct {
+	Name    string         `json:"name"`
+	Address common.Address `json:"address"`
+	Time    *big.Int       `json:"time,omitempty"` // active from genesis if nil
+}
+
 type BorConfig interface {
 	fmt.Stringer
 	IsAgra(num uint64) bool
@@ -512,6 +525,7 @@ type Rules struct {
 	IsCancun, IsNapoli                                bool
 	IsPrague, IsOsaka                                 bool
 	IsAura                                            bool
+	Precompiles                                       map[common.Address]string // custom precompiles, by name
 }
 
 // Rules ensures c's ChainID is not nil and returns a new Rules instance
@@ -538,7 +552,23 @@ func (c *Config) Rules(num uint64, time uint64) *Rules {
 		IsPrague:           c.IsPrague(time),
 		IsOsaka:            c.IsOsaka(time),
 		IsAura:             c.Aura != nil,
+		Precompiles:        c.activePrecompiles(time),
+	}
+}
+
+// activePrecompiles returns the custom precompiles active at the given time.
+func (c *Config) activePrecompiles(time uint64) map[common.Address]string {
+	var active map[common.Address]string
+	for _, p := range c.Precompiles {
+		if p.Time != nil && !isForked(p.Time, time) {
+			continue
+		}
+		if active == nil {
+			active = map[common.Address]string{}
+		}
+		active[p.Address] = p.Name
 	}
+	return active
 }
 
 // isForked returns whether a fork scheduled at block s is active at the given head block.
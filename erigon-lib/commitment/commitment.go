@@ -668,6 +668,40 @@ func (branchData BranchData) decodeCells() (touchMap, afterMap uint16, row [16]*
 	return
 }
 
+// DiffCells compares cells of two branches ignoring encoding details (touchMap, memoized leaf hashes)
+// and returns bitmap of nibbles which cells differ.
+func (branchData BranchData) DiffCells(other BranchData) (diff uint16, err error) {
+	decode := func(b BranchData) (afterMap uint16, row [16]*cell, err error) {
+		if len(b) < 4 {
+			return 0, row, nil
+		}
+		_, afterMap, row, err = b.decodeCells()
+		return afterMap, row, err
+	}
+	afterA, rowA, err := decode(branchData)
+	if err != nil {
+		return 0, err
+	}
+	afterB, rowB, err := decode(other)
+	if err != nil {
+		return 0, err
+	}
+	diff = afterA ^ afterB
+	for bitset := afterA & afterB; bitset != 0; {
+		bit := bitset & -bitset
+		nibble := bits.TrailingZeros16(bit)
+		a, b := rowA[nibble], rowB[nibble]
+		if !bytes.Equal(a.extension[:a.extLen], b.extension[:b.extLen]) ||
+			!bytes.Equal(a.accountAddr[:a.accountAddrLen], b.accountAddr[:b.accountAddrLen]) ||
+			!bytes.Equal(a.storageAddr[:a.storageAddrLen], b.storageAddr[:b.storageAddrLen]) ||
+			!bytes.Equal(a.hash[:a.hashLen], b.hash[:b.hashLen]) {
+			diff |= bit
+		}
+		bitset ^= bit
+	}
+	return diff, nil
+}
+
 type BranchMerger struct {
 	buf []byte
 	num [4]byte
@@ -22,6 +22,7 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"math/bits"
 	"math/rand"
 	"sort"
 	"testing"
@@ -302,6 +303,54 @@ func TestBranchData_ReplacePlainKeys_WithEmpty(t *testing.T) {
 	})
 }
 
+func TestBranchData_DiffCells(t *testing.T) {
+	t.Parallel()
+
+	row, bm := generateCellRow(t, 16)
+	be := NewBranchEncoder(1024, t.TempDir())
+	enc, _, err := be.EncodeBranch(bm, bm, bm, func(i int, skip bool) (*cell, error) {
+		return row[i], nil
+	})
+	require.NoError(t, err)
+	enc = common.Copy(enc)
+
+	diff, err := enc.DiffCells(enc)
+	require.NoError(t, err)
+	require.Zero(t, diff)
+
+	// memoized leaf hashes are not part of the trie
+	for i := range row {
+		if row[i].accountAddrLen > 0 {
+			rand.Read(row[i].stateHash[:])
+			row[i].stateHashLen = 32
+		}
+	}
+	withLeafHashes, _, err := be.EncodeBranch(bm, bm, bm, func(i int, skip bool) (*cell, error) {
+		return row[i], nil
+	})
+	require.NoError(t, err)
+	diff, err = enc.DiffCells(withLeafHashes)
+	require.NoError(t, err)
+	require.Zero(t, diff)
+
+	nibble := bits.TrailingZeros16(bm)
+	row[nibble].hash[0]++
+	if row[nibble].hashLen == 0 {
+		row[nibble].hashLen = 32
+	}
+	changed, _, err := be.EncodeBranch(bm, bm, bm, func(i int, skip bool) (*cell, error) {
+		return row[i], nil
+	})
+	require.NoError(t, err)
+	diff, err = enc.DiffCells(changed)
+	require.NoError(t, err)
+	require.EqualValues(t, uint16(1)<<nibble, diff)
+
+	diff, err = enc.DiffCells(nil)
+	require.NoError(t, err)
+	require.EqualValues(t, bm, diff)
+}
+
 func TestNewUpdates(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,230 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package kzg
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"strings"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/fft"
+	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
+)
+
+// EIP-7594 extends every blob to twice its size and splits the extended blob into cells: cell k holds the evaluations
+// of the blob polynomial over the k-th slice of the bit-reversed roots of unity of order FieldElementsPerExtBlob, which
+// is the coset h_k * <mu> of the FieldElementsPerCell-th roots of unity, in bit-reversed order.
+const (
+	FieldElementsPerExtBlob = 2 * gokzg4844.ScalarsPerBlob
+	FieldElementsPerCell    = 64
+	BytesPerCell            = FieldElementsPerCell * gokzg4844.SerializedScalarSize
+	CellsPerExtBlob         = FieldElementsPerExtBlob / FieldElementsPerCell
+)
+
+// [s^FieldElementsPerCell]_2 of the Ethereum KZG ceremony, the only point of the setup needed to verify cell proofs
+// which the 4844 context does not expose.
+const ceremonyG2MonomialCell = "0x92dcc5a1c8c3e1b28b1524e3dd6dbecd63017c9201da9dbe077f1b82adc08c50169f56fc7b5a3b28ec6b89254de3e2fd12838a761053437883c3e01ba616670cea843754548ef84bcc397de2369adcca2ab54cd73c55dc68d87aec3fc2fe4f10"
+
+var (
+	ErrInvalidCellProof = errors.New("invalid cell proof")
+	ErrInvalidCellIndex = errors.New("invalid cell index")
+
+	errCellBatchLength = errors.New("mismatched cell batch lengths")
+)
+
+// Cell is a slice of FieldElementsPerCell evaluations of an extended blob.
+type Cell [BytesPerCell]byte
+
+var (
+	cellCtx        *cellVerifier
+	cellCtxErr     error
+	initCellCtx    sync.Once
+	cellDomain     = fft.NewDomain(FieldElementsPerCell)
+	cellCosetShift = computeCellCosetShifts()
+)
+
+// cellVerifier holds the part of the trusted setup needed to verify cell proofs.
+type cellVerifier struct {
+	g1Monomial  []bls12381.G1Affine // [s^i]_1 for i < FieldElementsPerCell
+	g2Generator bls12381.G2Affine
+	g2CellPower bls12381.G2Affine // [s^FieldElementsPerCell]_2
+}
+
+// computeCellCosetShifts returns h_k for every cell k: the k-th bit-reversed root of unity of order FieldElementsPerExtBlob,
+// as the first element of every cell is the bit-reversed root at index k*FieldElementsPerCell.
+func computeCellCosetShifts() (shifts [CellsPerExtBlob]fr.Element) {
+	root, err := fft.Generator(FieldElementsPerExtBlob)
+	if err != nil {
+		panic(err)
+	}
+	for k := range shifts {
+		shifts[k].Exp(root, big.NewInt(int64(reverseBits(uint64(k), CellsPerExtBlob))))
+	}
+	return
+}
+
+func reverseBits(n, order uint64) uint64 {
+	return bits.Reverse64(n) >> (65 - bits.Len64(order))
+}
+
+// cellCryptoCtx returns the cell verifier for the trusted setup in use, the monomial G1 points are derived by committing
+// to the monomials with the 4844 context.
+func cellCryptoCtx() (*cellVerifier, error) {
+	initCellCtx.Do(func() {
+		ctx := Ctx()
+		blobRoot, err := fft.Generator(gokzg4844.ScalarsPerBlob)
+		if err != nil {
+			cellCtxErr = err
+			return
+		}
+		domain := make([]fr.Element, gokzg4844.ScalarsPerBlob)
+		for i := range domain {
+			domain[i].Exp(blobRoot, big.NewInt(int64(reverseBits(uint64(i), gokzg4844.ScalarsPerBlob))))
+		}
+		// the blob of x^i holds the evaluations of x^i over the (bit-reversed) domain
+		g1Monomial := make([]bls12381.G1Affine, FieldElementsPerCell)
+		monomial := make([]fr.Element, gokzg4844.ScalarsPerBlob)
+		for i := range monomial {
+			monomial[i].SetOne()
+		}
+		var blob gokzg4844.Blob
+		for i := range g1Monomial {
+			for j := range monomial {
+				b := monomial[j].Bytes()
+				copy(blob[j*gokzg4844.SerializedScalarSize:], b[:])
+				monomial[j].Mul(&monomial[j], &domain[j])
+			}
+			commitment, err := ctx.BlobToKZGCommitment(blob, 0)
+			if err != nil {
+				cellCtxErr = err
+				return
+			}
+			if _, err := g1Monomial[i].SetBytes(commitment[:]); err != nil {
+				cellCtxErr = err
+				return
+			}
+		}
+
+		g2CellPowerHex := ceremonyG2MonomialCell
+		if trustedSetupFile != "" {
+			if len(trustedSetupG2) <= FieldElementsPerCell {
+				cellCtxErr = fmt.Errorf("trusted setup has %d G2 points, %d are needed for cell proofs", len(trustedSetupG2), FieldElementsPerCell+1)
+				return
+			}
+			g2CellPowerHex = trustedSetupG2[FieldElementsPerCell]
+		}
+		g2CellPowerBytes, err := hex.DecodeString(strings.TrimPrefix(g2CellPowerHex, "0x"))
+		if err != nil {
+			cellCtxErr = err
+			return
+		}
+		var g2CellPower bls12381.G2Affine
+		if _, err := g2CellPower.SetBytes(g2CellPowerBytes); err != nil {
+			cellCtxErr = err
+			return
+		}
+		cellCtx = newCellVerifier(g1Monomial, g2CellPower)
+	})
+	return cellCtx, cellCtxErr
+}
+
+func newCellVerifier(g1Monomial []bls12381.G1Affine, g2CellPower bls12381.G2Affine) *cellVerifier {
+	_, _, _, g2Generator := bls12381.Generators()
+	return &cellVerifier{
+		g1Monomial:  g1Monomial,
+		g2Generator: g2Generator,
+		g2CellPower: g2CellPower,
+	}
+}
+
+// VerifyCellKZGProofBatch implements verify_cell_kzg_proof_batch from EIP-7594: it checks that every cell holds the
+// evaluations of the blob with the matching commitment at the cell's coset.
+func VerifyCellKZGProofBatch(commitments []gokzg4844.KZGCommitment, cellIndices []uint64, cells []Cell, proofs []gokzg4844.KZGProof) error {
+	if len(commitments) != len(cellIndices) || len(cellIndices) != len(cells) || len(cells) != len(proofs) {
+		return errCellBatchLength
+	}
+	verifier, err := cellCryptoCtx()
+	if err != nil {
+		return err
+	}
+	for i := range cells {
+		if err := verifier.verifyCellProof(commitments[i], cellIndices[i], &cells[i], proofs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyCellProof checks e(proof, [s^n - h^n]_2) == e(commitment - [I(s)]_1, [1]_2), with I the polynomial interpolating
+// the cell over its coset h * <mu> and n = FieldElementsPerCell.
+func (v *cellVerifier) verifyCellProof(commitmentBytes gokzg4844.KZGCommitment, cellIndex uint64, cell *Cell, proofBytes gokzg4844.KZGProof) error {
+	if cellIndex >= CellsPerExtBlob {
+		return ErrInvalidCellIndex
+	}
+	var commitment, proof bls12381.G1Affine
+	if _, err := commitment.SetBytes(commitmentBytes[:]); err != nil {
+		return err
+	}
+	if _, err := proof.SetBytes(proofBytes[:]); err != nil {
+		return err
+	}
+	coefficients := make([]fr.Element, FieldElementsPerCell)
+	for i := range coefficients {
+		if err := coefficients[i].SetBytesCanonical(cell[i*gokzg4844.SerializedScalarSize : (i+1)*gokzg4844.SerializedScalarSize]); err != nil {
+			return err
+		}
+	}
+	// the evaluations are in bit-reversed order, so this gives the coefficients of I(h*x) in natural order.
+	cellDomain.FFTInverse(coefficients, fft.DIT)
+	shift := cellCosetShift[cellIndex]
+	var shiftInv, shiftInvPower fr.Element
+	shiftInv.Inverse(&shift)
+	shiftInvPower.SetOne()
+	for i := range coefficients {
+		coefficients[i].Mul(&coefficients[i], &shiftInvPower)
+		shiftInvPower.Mul(&shiftInvPower, &shiftInv)
+	}
+	var interpolation bls12381.G1Affine
+	if _, err := interpolation.MultiExp(v.g1Monomial, coefficients, ecc.MultiExpConfig{}); err != nil {
+		return err
+	}
+
+	var shiftPower fr.Element
+	var shiftPowerBig big.Int
+	shiftPower.Exp(shift, big.NewInt(FieldElementsPerCell)).BigInt(&shiftPowerBig)
+	var vanishing bls12381.G2Affine
+	vanishing.ScalarMultiplication(&v.g2Generator, &shiftPowerBig)
+	vanishing.Sub(&v.g2CellPower, &vanishing)
+
+	var negatedNumerator bls12381.G1Affine
+	negatedNumerator.Sub(&interpolation, &commitment)
+	ok, err := bls12381.PairingCheck([]bls12381.G1Affine{proof, negatedNumerator}, []bls12381.G2Affine{vanishing, v.g2Generator})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidCellProof
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package kzg
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/fft"
+	gokzg4844 "github.com/crate-crypto/go-kzg-4844"
+	"github.com/stretchr/testify/require"
+)
+
+func evaluatePolynomial(coefficients []fr.Element, x fr.Element) fr.Element {
+	var result fr.Element
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		result.Mul(&result, &x).Add(&result, &coefficients[i])
+	}
+	return result
+}
+
+// commitPolynomial commits to a polynomial of degree < 4096 through its blob.
+func commitPolynomial(t *testing.T, coefficients []fr.Element) [48]byte {
+	root, err := fft.Generator(gokzg4844.ScalarsPerBlob)
+	require.NoError(t, err)
+	var blob gokzg4844.Blob
+	for j := 0; j < gokzg4844.ScalarsPerBlob; j++ {
+		var x fr.Element
+		x.Exp(root, big.NewInt(int64(reverseBits(uint64(j), gokzg4844.ScalarsPerBlob))))
+		y := evaluatePolynomial(coefficients, x)
+		b := y.Bytes()
+		copy(blob[j*gokzg4844.SerializedScalarSize:], b[:])
+	}
+	commitment, err := Ctx().BlobToKZGCommitment(blob, 0)
+	require.NoError(t, err)
+	return commitment
+}
+
+func TestVerifyCellKZGProofBatch(t *testing.T) {
+	polynomial := make([]fr.Element, 200)
+	for i := range polynomial {
+		polynomial[i].SetRandom()
+	}
+	commitment := commitPolynomial(t, polynomial)
+
+	extRoot, err := fft.Generator(FieldElementsPerExtBlob)
+	require.NoError(t, err)
+
+	var (
+		commitments []gokzg4844.KZGCommitment
+		indices     []uint64
+		cells       []Cell
+		proofs      []gokzg4844.KZGProof
+	)
+	for _, cellIndex := range []uint64{0, 1, 77, CellsPerExtBlob - 1} {
+		var cell Cell
+		var first fr.Element
+		for j := uint64(0); j < FieldElementsPerCell; j++ {
+			var x fr.Element
+			x.Exp(extRoot, big.NewInt(int64(reverseBits(cellIndex*FieldElementsPerCell+j, FieldElementsPerExtBlob))))
+			if j == 0 {
+				first = x
+			}
+			y := evaluatePolynomial(polynomial, x)
+			b := y.Bytes()
+			copy(cell[j*gokzg4844.SerializedScalarSize:], b[:])
+		}
+		// divide by the vanishing polynomial of the coset, x^n - h^n, the remainder being the interpolation of the cell.
+		var shiftPower fr.Element
+		shiftPower.Exp(first, big.NewInt(FieldElementsPerCell))
+		remainder := append([]fr.Element{}, polynomial...)
+		quotient := make([]fr.Element, len(polynomial)-FieldElementsPerCell)
+		for i := len(remainder) - 1; i >= FieldElementsPerCell; i-- {
+			quotient[i-FieldElementsPerCell] = remainder[i]
+			var carry fr.Element
+			carry.Mul(&remainder[i], &shiftPower)
+			remainder[i-FieldElementsPerCell].Add(&remainder[i-FieldElementsPerCell], &carry)
+		}
+
+		commitments = append(commitments, commitment)
+		indices = append(indices, cellIndex)
+		cells = append(cells, cell)
+		proofs = append(proofs, commitPolynomial(t, quotient))
+	}
+	require.NoError(t, VerifyCellKZGProofBatch(commitments, indices, cells, proofs))
+
+	// a cell checked against another coset
+	require.ErrorIs(t, VerifyCellKZGProofBatch(commitments[:1], []uint64{2}, cells[:1], proofs[:1]), ErrInvalidCellProof)
+	require.ErrorIs(t, VerifyCellKZGProofBatch(commitments[:1], []uint64{CellsPerExtBlob}, cells[:1], proofs[:1]), ErrInvalidCellIndex)
+	// a tampered cell
+	cells[1][BytesPerCell-1] ^= 1
+	require.ErrorIs(t, VerifyCellKZGProofBatch(commitments, indices, cells, proofs), ErrInvalidCellProof)
+	require.Error(t, VerifyCellKZGProofBatch(commitments, indices[:1], cells, proofs))
+}
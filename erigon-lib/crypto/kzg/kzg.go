@@ -45,6 +45,9 @@ var (
 
 	gokzgCtx      *gokzg4844.Context
 	initCryptoCtx sync.Once
+
+	// G2 points of the custom trusted setup, if any, used to verify cell proofs
+	trustedSetupG2 []string
 )
 
 func init() {
@@ -74,6 +77,7 @@ func InitKZGCtx() {
 			if err != nil {
 				panic(fmt.Sprintf("could not create KZG context, err: %v", err))
 			}
+			trustedSetupG2 = setup.SetupG2
 		} else {
 			var err error
 			// Initialize context to match the configurations that the
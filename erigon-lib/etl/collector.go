@@ -24,9 +24,11 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/c2h5oh/datasize"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/dir"
@@ -51,6 +53,11 @@ type Collector struct {
 	autoClean     bool
 	logger        log.Logger
 
+	// compress - zstd-compress and checksum tmp files. loadWorkers > 1 - enables partitioned parallel merge in Load (only for compressed files)
+	compress    bool
+	loadWorkers int
+	spillStats  SpillStats
+
 	// sortAndFlushInBackground increase insert performance, but make RAM use less-predictable:
 	//   - if disk is over-loaded - app may have much background threads which waiting for flush - and each thread whill hold own `buf` (can't free RAM until flush is done)
 	//   - enable it only when writing to `etl` is a bottleneck and unlikely to have many parallel collectors (to not overload CPU/Disk)
@@ -75,14 +82,17 @@ func NewCollectorFromFiles(logPrefix, tmpdir string, logger log.Logger) (*Collec
 		if err != nil {
 			return nil, fmt.Errorf("collector from files - reading file info %s: %w", dirEntry.Name(), err)
 		}
-		var dataProvider fileDataProvider
-		dataProvider.file, err = os.Open(filepath.Join(tmpdir, fileInfo.Name()))
+		file, err := os.Open(filepath.Join(tmpdir, fileInfo.Name()))
 		if err != nil {
 			return nil, fmt.Errorf("collector from files - opening file %s: %w", fileInfo.Name(), err)
 		}
-		dataProviders[i] = &dataProvider
+		if strings.HasPrefix(fileInfo.Name(), compressedFilePrefix) {
+			dataProviders[i] = &compressedFileDataProvider{file: file, fileName: file.Name(), wg: &errgroup.Group{}}
+			continue
+		}
+		dataProviders[i] = &fileDataProvider{file: file, wg: &errgroup.Group{}}
 	}
-	return &Collector{dataProviders: dataProviders, allFlushed: true, autoClean: false, logPrefix: logPrefix}, nil
+	return &Collector{dataProviders: dataProviders, allFlushed: true, autoClean: false, logPrefix: logPrefix, logger: logger}, nil
 }
 
 // NewCriticalCollector does not clean up temporary files if loading has failed
@@ -93,11 +103,28 @@ func NewCriticalCollector(logPrefix, tmpdir string, sortableBuffer Buffer, logge
 }
 
 func NewCollector(logPrefix, tmpdir string, sortableBuffer Buffer, logger log.Logger) *Collector {
-	return &Collector{autoClean: true, bufType: getTypeByBuffer(sortableBuffer), buf: sortableBuffer, logPrefix: logPrefix, tmpdir: tmpdir, logLvl: log.LvlInfo, logger: logger}
+	return &Collector{autoClean: true, bufType: getTypeByBuffer(sortableBuffer), buf: sortableBuffer, logPrefix: logPrefix, tmpdir: tmpdir, logLvl: log.LvlInfo, logger: logger,
+		compress: SpillCompression, loadWorkers: LoadWorkers}
 }
 
 func (c *Collector) SortAndFlushInBackground(v bool) { c.sortAndFlushInBackground = v }
 
+// CompressSpillFiles - zstd-compress tmp files and validate their checksums on read-back.
+// Trade CPU for tmp disk space: useful for collectors which produce 100's GB of tmp files.
+func (c *Collector) CompressSpillFiles(v bool) *Collector {
+	c.compress = v
+	return c
+}
+
+// LoadWorkers - amount of goroutines which decompress and merge tmp files in Load. Has effect only if CompressSpillFiles enabled.
+func (c *Collector) LoadWorkers(n int) *Collector {
+	c.loadWorkers = n
+	return c
+}
+
+// SpillStats - sizes of tmp files flushed by this collector
+func (c *Collector) SpillStats() *SpillStats { return &c.spillStats }
+
 func (c *Collector) extractNextFunc(originalK, k []byte, v []byte) error {
 	c.buf.Put(k, v)
 	if !c.buf.CheckFlushSize() {
@@ -135,13 +162,21 @@ func (c *Collector) flushBuffer(canStoreInRam bool) error {
 			prevLen, prevSize := fullBuf.Len(), fullBuf.SizeLimit()
 			c.buf = getBufferByType(c.bufType, datasize.ByteSize(c.buf.SizeLimit()), c.buf)
 
-			provider, err = FlushToDiskAsync(c.logPrefix, fullBuf, c.tmpdir, doFsync, c.logLvl)
+			if c.compress {
+				provider, err = FlushToDiskCompressed(c.logPrefix, fullBuf, c.tmpdir, doFsync, c.logLvl, &c.spillStats, true)
+			} else {
+				provider, err = FlushToDiskAsync(c.logPrefix, fullBuf, c.tmpdir, doFsync, c.logLvl)
+			}
 			if err != nil {
 				return err
 			}
 			c.buf.Prealloc(prevLen/8, prevSize/8)
 		} else {
-			provider, err = FlushToDisk(c.logPrefix, c.buf, c.tmpdir, doFsync, c.logLvl)
+			if c.compress {
+				provider, err = FlushToDiskCompressed(c.logPrefix, c.buf, c.tmpdir, doFsync, c.logLvl, &c.spillStats, false)
+			} else {
+				provider, err = FlushToDisk(c.logPrefix, c.buf, c.tmpdir, doFsync, c.logLvl)
+			}
 			if err != nil {
 				return err
 			}
@@ -219,6 +254,9 @@ func (c *Collector) Load(db kv.RwTx, toBucket string, loadFunc LoadFunc, args Tr
 			} else {
 				logArs = append(logArs, "current_prefix", makeCurrentKeyStr(k))
 			}
+			if c.compress {
+				logArs = append(logArs, c.spillStats.LogArgs()...)
+			}
 
 			c.logger.Log(c.logLvl, fmt.Sprintf("[%s] ETL [2/2] Loading", c.logPrefix), logArs...)
 		}
@@ -265,9 +303,16 @@ func (c *Collector) Load(db kv.RwTx, toBucket string, loadFunc LoadFunc, args Tr
 	simpleLoad := func(k, v []byte) error {
 		return loadFunc(k, v, currentTable, loadNextFunc)
 	}
-	if err := mergeSortFiles(c.logPrefix, c.dataProviders, simpleLoad, args, c.buf); err != nil {
+	if canMergeParallel(c.dataProviders, c.loadWorkers) {
+		if err := mergeSortFilesParallel(c.logPrefix, c.dataProviders, simpleLoad, args, c.loadWorkers); err != nil {
+			return fmt.Errorf("loadIntoTable %s: %w", toBucket, err)
+		}
+	} else if err := mergeSortFiles(c.logPrefix, c.dataProviders, simpleLoad, args, c.buf); err != nil {
 		return fmt.Errorf("loadIntoTable %s: %w", toBucket, err)
 	}
+	if c.compress && c.spillStats.Files() > 0 {
+		c.logger.Log(c.logLvl, fmt.Sprintf("[%s] ETL [2/2] Loaded", c.logPrefix), append([]interface{}{"into", bucket}, c.spillStats.LogArgs()...)...)
+	}
 	//logger.Trace(fmt.Sprintf("[%s] ETL Load done", c.logPrefix), "bucket", bucket, "records", i)
 	return nil
 }
@@ -313,7 +358,7 @@ func mergeSortFiles(logPrefix string, providers []dataProvider, loadFunc simpleL
 		}
 	}
 
-	var prevK, prevV []byte
+	loader := &mergeLoader{bufferType: args.BufferType, loadFunc: loadFunc}
 
 	// Main loading loop
 	for h.Len() > 0 {
@@ -324,34 +369,8 @@ func mergeSortFiles(logPrefix string, providers []dataProvider, loadFunc simpleL
 		element := heapPop(h)
 		provider := providers[element.TimeIdx]
 
-		// SortableOldestAppearedBuffer must guarantee that only 1 oldest value of key will appear
-		// but because size of buffer is limited - each flushed file does guarantee "oldest appeared"
-		// property, but files may overlap. files are sorted, just skip repeated keys here
-		if args.BufferType == SortableOldestAppearedBuffer {
-			if !bytes.Equal(prevK, element.Key) {
-				if err = loadFunc(element.Key, element.Value); err != nil {
-					return err
-				}
-				// Need to copy k because the underlying space will be re-used for the next key
-				prevK = common.Copy(element.Key)
-			}
-		} else if args.BufferType == SortableAppendBuffer {
-			if !bytes.Equal(prevK, element.Key) {
-				if prevK != nil {
-					if err = loadFunc(prevK, prevV); err != nil {
-						return err
-					}
-				}
-				// Need to copy k because the underlying space will be re-used for the next key
-				prevK = common.Copy(element.Key)
-				prevV = common.Copy(element.Value)
-			} else {
-				prevV = append(prevV, element.Value...)
-			}
-		} else {
-			if err = loadFunc(element.Key, element.Value); err != nil {
-				return err
-			}
+		if err = loader.add(element.Key, element.Value); err != nil {
+			return err
 		}
 
 		if element.Key, element.Value, err = provider.Next(element.Key[:0], element.Value[:0]); err == nil {
@@ -361,15 +380,7 @@ func mergeSortFiles(logPrefix string, providers []dataProvider, loadFunc simpleL
 		}
 	}
 
-	if args.BufferType == SortableAppendBuffer {
-		if prevK != nil {
-			if err = loadFunc(prevK, prevV); err != nil {
-				return err
-			}
-		}
-	}
-
-	return nil
+	return loader.finish()
 }
 
 func makeCurrentKeyStr(k []byte) string {
@@ -633,3 +633,113 @@ func TestSortable(t *testing.T) {
 	require.Equal([][]byte{{1}, {2}, {3}, {4}, {5}, {6}, {7}, {1}, {20}, nil}, vals)
 
 }
+
+func collectCompressed(t *testing.T, bufType int, workers int, keys int) (*Collector, [][]byte, [][]byte) {
+	t.Helper()
+	collector := NewCollector(t.Name(), t.TempDir(), getBufferByType(bufType, 1024, nil), log.New()).CompressSpillFiles(true).LoadWorkers(workers)
+	t.Cleanup(collector.Close)
+	for i := 0; i < keys; i++ {
+		k := []byte(fmt.Sprintf("key-%05d", (i*7919)%keys))
+		require.NoError(t, collector.Collect(k, []byte(fmt.Sprintf("v%d", i))))
+		require.NoError(t, collector.Collect(k, []byte(fmt.Sprintf("w%d", i))))
+	}
+
+	var gotK, gotV [][]byte
+	require.NoError(t, collector.Load(nil, "", func(k, v []byte, table CurrentTableReader, next LoadNextFunc) error {
+		gotK = append(gotK, common.Copy(k))
+		gotV = append(gotV, common.Copy(v))
+		return nil
+	}, TransformArgs{BufferType: bufType}))
+	return collector, gotK, gotV
+}
+
+func TestCompressedSpill(t *testing.T) {
+	defer func(v int) { spillFrameSize = v }(spillFrameSize)
+	spillFrameSize = 128
+
+	for _, bufType := range []int{SortableSliceBuffer, SortableAppendBuffer, SortableOldestAppearedBuffer} {
+		t.Run(fmt.Sprintf("type%d", bufType), func(t *testing.T) {
+			require := require.New(t)
+			collector, keys, vals := collectCompressed(t, bufType, 1, 500)
+			require.Greater(collector.SpillStats().Files(), uint64(1))
+			require.Greater(collector.SpillStats().RawBytes(), uint64(0))
+
+			require.True(sort.SliceIsSorted(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 }))
+			switch bufType {
+			case SortableSliceBuffer:
+				require.Equal(1000, len(keys))
+			case SortableAppendBuffer:
+				require.Equal(500, len(keys))
+				require.True(bytes.HasPrefix(vals[0], []byte("v")))
+				require.True(bytes.Contains(vals[0], []byte("w")))
+			case SortableOldestAppearedBuffer:
+				require.Equal(500, len(keys))
+				require.True(bytes.HasPrefix(vals[0], []byte("v")))
+			}
+
+			_, parKeys, parVals := collectCompressed(t, bufType, 4, 500)
+			require.Equal(keys, parKeys)
+			require.Equal(vals, parVals)
+		})
+	}
+}
+
+func TestCompressedSpillChecksum(t *testing.T) {
+	require := require.New(t)
+	b := NewSortableBuffer(1024)
+	for i := 0; i < 100; i++ {
+		b.Put([]byte(fmt.Sprintf("key-%d", i)), []byte(fmt.Sprintf("value-%d", i)))
+	}
+	provider, err := FlushToDiskCompressed(t.Name(), b, t.TempDir(), false, log.LvlTrace, nil, false)
+	require.NoError(err)
+	p := provider.(*compressedFileDataProvider)
+	defer p.Dispose()
+
+	k, v, err := p.Next(nil, nil)
+	require.NoError(err)
+	require.Equal("key-0", string(k))
+	require.Equal("value-0", string(v))
+
+	// corrupt stored checksum of first frame
+	raw, err := os.ReadFile(p.fileName)
+	require.NoError(err)
+	raw[0] ^= 0xff
+	require.NoError(os.WriteFile(p.fileName, raw, 0644))
+
+	r, f, err := p.rangeReader(nil)
+	require.NoError(err)
+	defer f.Close()
+	defer r.Close()
+	_, _, err = r.Next(nil, nil)
+	require.ErrorIs(err, ErrSpillChecksum)
+}
+
+func TestCompressedSpillChecksumParallel(t *testing.T) {
+	defer func(v int) { spillFrameSize = v }(spillFrameSize)
+	spillFrameSize = 128
+
+	require := require.New(t)
+	collector := NewCollector(t.Name(), t.TempDir(), getBufferByType(SortableAppendBuffer, 1024, nil), log.New()).CompressSpillFiles(true).LoadWorkers(4)
+	defer collector.Close()
+	for i := 0; i < 500; i++ {
+		k := []byte(fmt.Sprintf("key-%05d", (i*7919)%500))
+		require.NoError(collector.Collect(k, []byte(fmt.Sprintf("v%d", i))))
+	}
+	require.Greater(len(collector.dataProviders), 1)
+
+	// corrupt stored checksum of first frame: only the first partition reads it
+	p := collector.dataProviders[0].(*compressedFileDataProvider)
+	require.NoError(p.Wait())
+	raw, err := os.ReadFile(p.fileName)
+	require.NoError(err)
+	raw[0] ^= 0xff
+	require.NoError(os.WriteFile(p.fileName, raw, 0644))
+
+	var keys []string
+	err = collector.Load(nil, "", func(k, v []byte, table CurrentTableReader, next LoadNextFunc) error {
+		keys = append(keys, string(k))
+		return nil
+	}, TransformArgs{BufferType: SortableAppendBuffer})
+	require.ErrorIs(err, ErrSpillChecksum)
+	require.NotContains(keys, "key-00499")
+}
@@ -0,0 +1,274 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package etl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/erigontech/erigon-lib/common"
+)
+
+// mergeLoader - applies buffer-type-specific rules to sorted stream of entries
+type mergeLoader struct {
+	bufferType   int
+	loadFunc     simpleLoadFunc
+	prevK, prevV []byte
+}
+
+func (l *mergeLoader) add(k, v []byte) error {
+	switch l.bufferType {
+	case SortableOldestAppearedBuffer:
+		// SortableOldestAppearedBuffer must guarantee that only 1 oldest value of key will appear
+		// but because size of buffer is limited - each flushed file does guarantee "oldest appeared"
+		// property, but files may overlap. files are sorted, just skip repeated keys here
+		if !bytes.Equal(l.prevK, k) {
+			if err := l.loadFunc(k, v); err != nil {
+				return err
+			}
+			// Need to copy k because the underlying space will be re-used for the next key
+			l.prevK = common.Copy(k)
+		}
+	case SortableAppendBuffer:
+		if !bytes.Equal(l.prevK, k) {
+			if l.prevK != nil {
+				if err := l.loadFunc(l.prevK, l.prevV); err != nil {
+					return err
+				}
+			}
+			// Need to copy k because the underlying space will be re-used for the next key
+			l.prevK = common.Copy(k)
+			l.prevV = common.Copy(v)
+		} else {
+			l.prevV = append(l.prevV, v...)
+		}
+	default:
+		return l.loadFunc(k, v)
+	}
+	return nil
+}
+
+func (l *mergeLoader) finish() error {
+	if l.bufferType == SortableAppendBuffer && l.prevK != nil {
+		return l.loadFunc(l.prevK, l.prevV)
+	}
+	return nil
+}
+
+// canMergeParallel - partitioned merge requires random access to all providers
+func canMergeParallel(providers []dataProvider, workers int) bool {
+	if workers <= 1 || len(providers) < 2 {
+		return false
+	}
+	for _, p := range providers {
+		cp, ok := p.(*compressedFileDataProvider)
+		if !ok || cp.frames == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// partitionBounds - splits key-space to `n` ranges with approximately equal amount of frames
+func partitionBounds(providers []dataProvider, n int) [][]byte {
+	var keys [][]byte
+	for _, p := range providers {
+		for _, f := range p.(*compressedFileDataProvider).frames {
+			keys = append(keys, f.firstKey)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+	var bounds [][]byte
+	for i := 1; i < n; i++ {
+		b := keys[i*len(keys)/n]
+		if len(b) == 0 || (len(bounds) > 0 && bytes.Equal(bounds[len(bounds)-1], b)) {
+			continue
+		}
+		bounds = append(bounds, b)
+	}
+	return bounds
+}
+
+type mergeBatch struct {
+	keys, vals [][]byte
+}
+
+const mergeBatchLen = 4 * 1024
+
+// mergeSortFilesParallel - same as mergeSortFiles, but splits key-space into partitions.
+// Each partition is decompressed and k-way merged by own goroutine, results are loaded in partitions order.
+func mergeSortFilesParallel(logPrefix string, providers []dataProvider, loadFunc simpleLoadFunc, args TransformArgs, workers int) error {
+	for _, provider := range providers {
+		if err := provider.Wait(); err != nil {
+			return err
+		}
+	}
+	bounds := partitionBounds(providers, workers)
+	parts := len(bounds) + 1
+
+	g, ctx := errgroup.WithContext(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	outs := make([]chan mergeBatch, parts)
+	for i := range outs {
+		outs[i] = make(chan mergeBatch, 4)
+	}
+	for i := 0; i < parts; i++ {
+		var from, to []byte
+		if i > 0 {
+			from = bounds[i-1]
+		}
+		if i < len(bounds) {
+			to = bounds[i]
+		}
+		out := outs[i]
+		g.Go(func() error {
+			// closed only on success: the partition of a failed worker never looks complete to the loader
+			if err := mergePartition(ctx, providers, from, to, out); err != nil {
+				return err
+			}
+			close(out)
+			return nil
+		})
+	}
+
+	loader := &mergeLoader{bufferType: args.BufferType, loadFunc: loadFunc}
+	loadErr := func() error {
+		for _, out := range outs {
+			for {
+				var batch mergeBatch
+				var ok bool
+				select {
+				case batch, ok = <-out:
+				case <-ctx.Done():
+					// a worker failed: stop before loading further partitions and before finish
+					return ctx.Err()
+				}
+				if !ok {
+					break
+				}
+				if err := common.Stopped(args.Quit); err != nil {
+					return err
+				}
+				for j := range batch.keys {
+					if err := loader.add(batch.keys[j], batch.vals[j]); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return loader.finish()
+	}()
+	cancel()
+	if err := g.Wait(); err != nil && !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("%s: parallel merge: %w", logPrefix, err)
+	}
+	return loadErr
+}
+
+// mergePartition - k-way merge of `[from, to)` range of all providers
+func mergePartition(ctx context.Context, providers []dataProvider, from, to []byte, out chan<- mergeBatch) error {
+	readers := make([]*frameReader, len(providers))
+	defer func() {
+		for _, r := range readers {
+			if r != nil {
+				r.Close()
+			}
+		}
+	}()
+	var files []*os.File
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+
+	h := &Heap{}
+	heapInit(h)
+	next := func(i int, keyBuf, valBuf []byte) (k, v []byte, ok bool, err error) {
+		for {
+			k, v, err = readers[i].Next(keyBuf[:0], valBuf[:0])
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil, nil, false, nil
+				}
+				return nil, nil, false, err
+			}
+			if from != nil && bytes.Compare(k, from) < 0 {
+				continue
+			}
+			if to != nil && bytes.Compare(k, to) >= 0 {
+				return nil, nil, false, nil
+			}
+			return k, v, true, nil
+		}
+	}
+	for i, p := range providers {
+		r, f, err := p.(*compressedFileDataProvider).rangeReader(from)
+		if err != nil {
+			return err
+		}
+		readers[i], files = r, append(files, f)
+		k, v, ok, err := next(i, nil, nil)
+		if err != nil {
+			return err
+		}
+		if ok {
+			heapPush(h, &HeapElem{k, v, i})
+		}
+	}
+
+	batch := mergeBatch{}
+	send := func() error {
+		if len(batch.keys) == 0 {
+			return nil
+		}
+		select {
+		case out <- batch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		batch = mergeBatch{}
+		return nil
+	}
+	for h.Len() > 0 {
+		element := heapPop(h)
+		batch.keys = append(batch.keys, common.Copy(element.Key))
+		batch.vals = append(batch.vals, common.Copy(element.Value))
+		if len(batch.keys) >= mergeBatchLen {
+			if err := send(); err != nil {
+				return err
+			}
+		}
+		k, v, ok, err := next(element.TimeIdx, element.Key, element.Value)
+		if err != nil {
+			return err
+		}
+		if ok {
+			element.Key, element.Value = k, v
+			heapPush(h, element)
+		}
+	}
+	return send()
+}
@@ -0,0 +1,351 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package etl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/klauspost/compress/zstd"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/erigontech/erigon-lib/common/dbg"
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+// Compressed spill files format:
+//
+//	file  = frame*
+//	frame = crc32c(raw) [4]byte | uvarint(len(raw)) | uvarint(len(payload)) | payload
+//
+// `raw` - is entries in same encoding as un-compressed spill files (see `readElementFromDisk`), `payload` - is zstd(raw).
+// Frames always start from new entry - it allows start reading file from any frame (used by parallel merge).
+// Provider keeps in RAM sparse index: offset and first key of each frame.
+
+var (
+	// SpillCompression - compress (zstd) and checksum tmp files of new collectors. var because we want to sometimes change it from tests or command-line flags
+	SpillCompression = dbg.EnvBool("ETL_COMPRESS", false)
+	// LoadWorkers - amount of goroutines for partitioned k-way merge in Collector.Load. Works only with compressed spill files.
+	LoadWorkers = dbg.EnvInt("ETL_LOAD_WORKERS", 1)
+
+	spillFrameSize = int(1 * datasize.MB)
+)
+
+const compressedFilePrefix = "erigon-sortable-zbuf-"
+
+var ErrSpillChecksum = errors.New("etl: spill file checksum mismatch")
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// SpillStats - accumulates size of collector's tmp files. Safe for concurrent use (flush may happen in background).
+type SpillStats struct {
+	files       atomic.Uint64
+	rawBytes    atomic.Uint64
+	onDiskBytes atomic.Uint64
+}
+
+func (s *SpillStats) add(raw, onDisk uint64) {
+	s.files.Add(1)
+	s.rawBytes.Add(raw)
+	s.onDiskBytes.Add(onDisk)
+}
+
+func (s *SpillStats) Files() uint64       { return s.files.Load() }
+func (s *SpillStats) RawBytes() uint64    { return s.rawBytes.Load() }
+func (s *SpillStats) OnDiskBytes() uint64 { return s.onDiskBytes.Load() }
+
+// Saved - how much tmp space was saved by compression
+func (s *SpillStats) Saved() datasize.ByteSize {
+	raw, onDisk := s.RawBytes(), s.OnDiskBytes()
+	if onDisk >= raw {
+		return 0
+	}
+	return datasize.ByteSize(raw - onDisk)
+}
+
+func (s *SpillStats) LogArgs() []interface{} {
+	return []interface{}{"tmp_files", s.Files(), "tmp_raw", datasize.ByteSize(s.RawBytes()).HR(), "tmp_on_disk", datasize.ByteSize(s.OnDiskBytes()).HR(), "tmp_saved", s.Saved().HR()}
+}
+
+type frameIndex struct {
+	offset   int64
+	firstKey []byte
+}
+
+type compressedFileDataProvider struct {
+	fileName string
+	file     *os.File
+	frames   []frameIndex // nil for files left from previous run
+	wg       *errgroup.Group
+
+	r *frameReader
+}
+
+// FlushToDiskCompressed - same as FlushToDisk/FlushToDiskAsync, but file is zstd-compressed and checksummed
+func FlushToDiskCompressed(logPrefix string, b Buffer, tmpdir string, doFsync bool, lvl log.Lvl, stats *SpillStats, async bool) (dataProvider, error) {
+	if b.Len() == 0 {
+		return nil, nil
+	}
+	provider := &compressedFileDataProvider{wg: &errgroup.Group{}}
+	flush := func() error {
+		raw, onDisk, err := provider.sortAndFlush(b, tmpdir, doFsync)
+		if err != nil {
+			return err
+		}
+		if stats != nil {
+			stats.add(raw, onDisk)
+		}
+		_, fName := filepath.Split(provider.fileName)
+		log.Log(lvl, fmt.Sprintf("[%s] Flushed buffer file", logPrefix), "name", fName, "raw", datasize.ByteSize(raw).HR(), "on_disk", datasize.ByteSize(onDisk).HR())
+		return nil
+	}
+	if async {
+		provider.wg.Go(flush)
+		return provider, nil
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+func (p *compressedFileDataProvider) sortAndFlush(b Buffer, tmpdir string, doFsync bool) (raw, onDisk uint64, err error) {
+	b.Sort()
+
+	if tmpdir != "" {
+		if err := os.MkdirAll(tmpdir, 0755); err != nil {
+			return 0, 0, err
+		}
+	}
+	p.file, err = os.CreateTemp(tmpdir, compressedFilePrefix)
+	if err != nil {
+		return 0, 0, err
+	}
+	p.fileName = p.file.Name()
+	if doFsync {
+		defer p.file.Sync() //nolint:errcheck
+	}
+
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest), zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer enc.Close()
+
+	w := bufio.NewWriterSize(p.file, BufIOSize)
+	var frame, payload, keyBuf, valBuf []byte
+	var firstKey []byte
+	var numBuf [binary.MaxVarintLen64]byte
+	flushFrame := func() error {
+		if len(frame) == 0 {
+			return nil
+		}
+		p.frames = append(p.frames, frameIndex{offset: int64(onDisk), firstKey: firstKey})
+		payload = enc.EncodeAll(frame, payload[:0])
+		n, err := writeFrameHeader(w, frame, payload)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(payload); err != nil {
+			return err
+		}
+		raw += uint64(len(frame))
+		onDisk += uint64(n + len(payload))
+		frame, firstKey = frame[:0], nil
+		return nil
+	}
+
+	for i := 0; i < b.Len(); i++ {
+		keyBuf, valBuf = b.Get(i, keyBuf[:0], valBuf[:0])
+		if len(frame) == 0 {
+			firstKey = append([]byte{}, keyBuf...)
+		}
+		frame = appendElement(frame, numBuf[:], keyBuf)
+		frame = appendElement(frame, numBuf[:], valBuf)
+		if len(frame) >= spillFrameSize {
+			if err = flushFrame(); err != nil {
+				return 0, 0, fmt.Errorf("error writing entries to disk: %w", err)
+			}
+		}
+	}
+	if err = flushFrame(); err != nil {
+		return 0, 0, fmt.Errorf("error writing entries to disk: %w", err)
+	}
+	if err = w.Flush(); err != nil {
+		return 0, 0, fmt.Errorf("error writing entries to disk: %w", err)
+	}
+	return raw, onDisk, nil
+}
+
+func appendElement(dst, numBuf, v []byte) []byte {
+	l := int64(len(v))
+	if v == nil {
+		l = -1
+	}
+	n := binary.PutVarint(numBuf, l)
+	dst = append(dst, numBuf[:n]...)
+	return append(dst, v...)
+}
+
+func writeFrameHeader(w io.Writer, raw, payload []byte) (int, error) {
+	var hdr [4 + 2*binary.MaxVarintLen64]byte
+	binary.BigEndian.PutUint32(hdr[:4], crc32.Checksum(raw, crcTable))
+	n := 4
+	n += binary.PutUvarint(hdr[n:], uint64(len(raw)))
+	n += binary.PutUvarint(hdr[n:], uint64(len(payload)))
+	return w.Write(hdr[:n])
+}
+
+// frameReader - reads entries frame-by-frame, validates checksum of each frame
+type frameReader struct {
+	br      *bufio.Reader
+	dec     *zstd.Decoder
+	payload []byte
+	raw     []byte
+	cur     *bytes.Reader
+}
+
+func newFrameReader(r io.Reader) (*frameReader, error) {
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return &frameReader{br: bufio.NewReaderSize(r, BufIOSize), dec: dec, cur: bytes.NewReader(nil)}, nil
+}
+
+func (r *frameReader) Close() {
+	if r.dec != nil {
+		r.dec.Close()
+		r.dec = nil
+	}
+}
+
+func (r *frameReader) nextFrame() error {
+	var crc [4]byte
+	if _, err := io.ReadFull(r.br, crc[:]); err != nil {
+		return err // io.EOF at frame boundary - is normal end of file
+	}
+	rawLen, err := binary.ReadUvarint(r.br)
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+	payloadLen, err := binary.ReadUvarint(r.br)
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+	if cap(r.payload) < int(payloadLen) {
+		r.payload = make([]byte, payloadLen)
+	}
+	r.payload = r.payload[:payloadLen]
+	if _, err = io.ReadFull(r.br, r.payload); err != nil {
+		return unexpectedEOF(err)
+	}
+	if r.raw, err = r.dec.DecodeAll(r.payload, r.raw[:0]); err != nil {
+		return fmt.Errorf("etl: decompress spill frame: %w", err)
+	}
+	if uint64(len(r.raw)) != rawLen || crc32.Checksum(r.raw, crcTable) != binary.BigEndian.Uint32(crc[:]) {
+		return ErrSpillChecksum
+	}
+	r.cur.Reset(r.raw)
+	return nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func (r *frameReader) Next(keyBuf, valBuf []byte) ([]byte, []byte, error) {
+	if r.cur.Len() == 0 {
+		if err := r.nextFrame(); err != nil {
+			return nil, nil, err
+		}
+	}
+	k, v, err := readElementFromDisk(r.cur, r.cur, keyBuf, valBuf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("etl: corrupted spill frame: %w", unexpectedEOF(err))
+	}
+	return k, v, nil
+}
+
+func (p *compressedFileDataProvider) Next(keyBuf, valBuf []byte) ([]byte, []byte, error) {
+	if p.r == nil {
+		if _, err := p.file.Seek(0, 0); err != nil {
+			return nil, nil, err
+		}
+		r, err := newFrameReader(p.file)
+		if err != nil {
+			return nil, nil, err
+		}
+		p.r = r
+	}
+	return p.r.Next(keyBuf, valBuf)
+}
+
+// rangeReader - opens own file descriptor and returns entries `[from, to)` (nil means unbounded).
+// Starts reading from last frame which first key is < from.
+func (p *compressedFileDataProvider) rangeReader(from []byte) (*frameReader, *os.File, error) {
+	f, err := os.Open(p.fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if from != nil {
+		i := sort.Search(len(p.frames), func(i int) bool { return bytes.Compare(p.frames[i].firstKey, from) >= 0 })
+		if i > 0 {
+			if _, err = f.Seek(p.frames[i-1].offset, io.SeekStart); err != nil {
+				f.Close()
+				return nil, nil, err
+			}
+		}
+	}
+	r, err := newFrameReader(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return r, f, nil
+}
+
+func (p *compressedFileDataProvider) Wait() error { return p.wg.Wait() }
+func (p *compressedFileDataProvider) Dispose() {
+	if p.file != nil { //invariant: safe to call multiple time
+		p.Wait()
+		if p.r != nil {
+			p.r.Close()
+			p.r = nil
+		}
+		_ = p.file.Close()
+		go func(fPath string) { _ = os.Remove(fPath) }(p.file.Name())
+		p.file = nil
+	}
+}
+
+func (p *compressedFileDataProvider) String() string {
+	return fmt.Sprintf("%T(file: %s, frames: %d)", p, p.fileName, len(p.frames))
+}
@@ -0,0 +1,703 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+// Package btreedb - pure-Go (no cgo) in-memory implementation of kv.RwDB.
+//
+// Every table is a copy-on-write b-tree. RoTx takes an O(1) snapshot of all tables,
+// RwTx works on private copies and publishes them on Commit. It gives Snapshot-Isolation
+// semantic similar to MDBX: 1 writer and many readers, readers never see uncommitted data.
+//
+// Target use-cases: unit-tests and builds without cgo (for example rpcdaemon in remote mode).
+// Nothing is persisted to disk.
+package btreedb
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/tidwall/btree"
+
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/order"
+	"github.com/erigontech/erigon-lib/kv/stream"
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+const pageSize = 4096
+
+var (
+	ErrTableNotFound = errors.New("table not found")
+	ErrKeyExist      = errors.New("key/data pair already exists")
+	ErrKeyMismatch   = errors.New("append: key/data not in ascending order")
+	ErrTxDone        = errors.New("transaction already committed or rolled back")
+	ErrTxReadOnly    = errors.New("write in read-only transaction")
+)
+
+type TableCfgFunc func(defaultBuckets kv.TableCfg) kv.TableCfg
+
+type BtreeOpts struct {
+	log        log.Logger
+	bucketsCfg TableCfgFunc
+	label      kv.Label
+}
+
+func NewBtree(log log.Logger) BtreeOpts {
+	return BtreeOpts{
+		log:        log,
+		bucketsCfg: func(defaultBuckets kv.TableCfg) kv.TableCfg { return defaultBuckets },
+		label:      kv.InMem,
+	}
+}
+
+func (opts BtreeOpts) Label(label kv.Label) BtreeOpts {
+	opts.label = label
+	return opts
+}
+
+func (opts BtreeOpts) WithTableCfg(f TableCfgFunc) BtreeOpts {
+	opts.bucketsCfg = f
+	return opts
+}
+
+func (opts BtreeOpts) Open(ctx context.Context) (kv.RwDB, error) {
+	db := &BtreeKV{
+		opts:    opts,
+		log:     opts.log,
+		buckets: kv.TableCfg{},
+		tables:  map[string]*table{},
+	}
+	for name, cfg := range opts.bucketsCfg(kv.ChaindataTablesCfg) { // copy map to avoid changing global variable
+		db.buckets[name] = cfg
+		if cfg.IsDeprecated {
+			continue
+		}
+		db.tables[name] = newTable(cfg.Flags&kv.DupSort != 0)
+	}
+	opts.log.Debug("[db] open", "label", opts.label, "impl", "btree")
+	return db, nil
+}
+
+func (opts BtreeOpts) MustOpen() kv.RwDB {
+	db, err := opts.Open(context.Background())
+	if err != nil {
+		panic(fmt.Errorf("fail to open btree db: %w", err))
+	}
+	return db
+}
+
+// pair - item of table. For DupSort tables order is (k, v), otherwise only k.
+// `inf` is a search-only marker: it's greater than any other pair with same key.
+type pair struct {
+	k, v []byte
+	inf  bool
+}
+
+type table struct {
+	tree    *btree.BTreeG[pair]
+	dupSort bool
+}
+
+func newTable(dupSort bool) *table {
+	less := lessKey
+	if dupSort {
+		less = lessKeyValue
+	}
+	return &table{tree: btree.NewBTreeG[pair](less), dupSort: dupSort}
+}
+
+func lessKey(a, b pair) bool {
+	if c := bytes.Compare(a.k, b.k); c != 0 {
+		return c < 0
+	}
+	return !a.inf && b.inf
+}
+
+func lessKeyValue(a, b pair) bool {
+	if c := bytes.Compare(a.k, b.k); c != 0 {
+		return c < 0
+	}
+	if a.inf || b.inf {
+		return !a.inf && b.inf
+	}
+	return bytes.Compare(a.v, b.v) < 0
+}
+
+func (t *table) copy() *table { return &table{tree: t.tree.Copy(), dupSort: t.dupSort} }
+
+type BtreeKV struct {
+	log     log.Logger
+	opts    BtreeOpts
+	buckets kv.TableCfg
+
+	mu       sync.RWMutex // protects `tables` and `buckets`
+	tables   map[string]*table
+	writerMu sync.Mutex // only 1 RwTx at a time
+	viewID   uint64     // id of last committed RwTx
+	closed   atomic.Bool
+	txs      sync.WaitGroup
+}
+
+func (db *BtreeKV) ReadOnly() bool          { return false }
+func (db *BtreeKV) PageSize() uint64        { return pageSize }
+func (db *BtreeKV) CHandle() unsafe.Pointer { return nil }
+func (db *BtreeKV) AllTables() kv.TableCfg {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.buckets
+}
+
+// Close closes db
+// All transactions must be closed before closing the database.
+func (db *BtreeKV) Close() {
+	if ok := db.closed.CompareAndSwap(false, true); !ok {
+		return
+	}
+	db.txs.Wait()
+	db.mu.Lock()
+	db.tables = nil
+	db.mu.Unlock()
+}
+
+// snapshot - O(1) copy-on-write copy of all tables
+func (db *BtreeKV) snapshot() (map[string]*table, kv.TableCfg, uint64) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	tables := make(map[string]*table, len(db.tables))
+	for name, t := range db.tables {
+		tables[name] = t.copy()
+	}
+	buckets := make(kv.TableCfg, len(db.buckets))
+	for name, cfg := range db.buckets {
+		buckets[name] = cfg
+	}
+	return tables, buckets, db.viewID
+}
+
+func (db *BtreeKV) BeginRo(ctx context.Context) (kv.Tx, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	if db.closed.Load() {
+		return nil, errors.New("db closed")
+	}
+	db.txs.Add(1)
+	tables, buckets, viewID := db.snapshot()
+	return &BtreeTx{ctx: ctx, db: db, tables: tables, buckets: buckets, viewID: viewID, readOnly: true}, nil
+}
+
+func (db *BtreeKV) BeginRw(ctx context.Context) (kv.RwTx, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	if db.closed.Load() {
+		return nil, errors.New("db closed")
+	}
+	db.writerMu.Lock()
+	db.txs.Add(1)
+	tables, buckets, viewID := db.snapshot()
+	return &BtreeTx{ctx: ctx, db: db, tables: tables, buckets: buckets, viewID: viewID + 1}, nil
+}
+
+// BeginRwNosync - same as BeginRw: nothing to sync
+func (db *BtreeKV) BeginRwNosync(ctx context.Context) (kv.RwTx, error) { return db.BeginRw(ctx) }
+
+func (db *BtreeKV) View(ctx context.Context, f func(tx kv.Tx) error) error {
+	tx, err := db.BeginRo(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	return f(tx)
+}
+
+func (db *BtreeKV) Update(ctx context.Context, f func(tx kv.RwTx) error) error {
+	tx, err := db.BeginRw(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err = f(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (db *BtreeKV) UpdateNosync(ctx context.Context, f func(tx kv.RwTx) error) error {
+	return db.Update(ctx, f)
+}
+
+type BtreeTx struct {
+	ctx      context.Context
+	db       *BtreeKV
+	tables   map[string]*table
+	buckets  kv.TableCfg
+	viewID   uint64
+	readOnly bool
+	done     bool
+
+	statelessCursors map[string]*BtreeCursor
+}
+
+func (tx *BtreeTx) ViewID() uint64          { return tx.viewID }
+func (tx *BtreeTx) CHandle() unsafe.Pointer { return nil }
+func (tx *BtreeTx) CollectMetrics()         {}
+
+func (tx *BtreeTx) table(name string) (*table, error) {
+	if tx.done {
+		return nil, ErrTxDone
+	}
+	t, ok := tx.tables[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTableNotFound, name)
+	}
+	return t, nil
+}
+
+func (tx *BtreeTx) rwTable(name string) (*table, error) {
+	if tx.readOnly {
+		return nil, fmt.Errorf("%w: table %s", ErrTxReadOnly, name)
+	}
+	return tx.table(name)
+}
+
+func (tx *BtreeTx) Commit() error {
+	if tx.done {
+		return nil
+	}
+	defer tx.finish()
+	if tx.readOnly {
+		return nil
+	}
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.tables = tx.tables
+	tx.db.buckets = tx.buckets
+	tx.db.viewID = tx.viewID
+	return nil
+}
+
+func (tx *BtreeTx) Rollback() {
+	if tx.done {
+		return
+	}
+	tx.finish()
+}
+
+func (tx *BtreeTx) finish() {
+	tx.done = true
+	tx.tables = nil
+	tx.statelessCursors = nil
+	if !tx.readOnly {
+		tx.db.writerMu.Unlock()
+	}
+	tx.db.txs.Done()
+}
+
+func (tx *BtreeTx) statelessCursor(bucket string) (*BtreeCursor, error) {
+	if tx.statelessCursors == nil {
+		tx.statelessCursors = make(map[string]*BtreeCursor)
+	}
+	c, ok := tx.statelessCursors[bucket]
+	if !ok {
+		var err error
+		c, err = tx.newCursor(bucket)
+		if err != nil {
+			return nil, err
+		}
+		tx.statelessCursors[bucket] = c
+	}
+	return c, nil
+}
+
+func (tx *BtreeTx) Has(table string, key []byte) (bool, error) {
+	v, err := tx.GetOne(table, key)
+	return v != nil, err
+}
+
+func (tx *BtreeTx) GetOne(table string, key []byte) ([]byte, error) {
+	t, err := tx.table(table)
+	if err != nil {
+		return nil, err
+	}
+	item, ok := seekGE(t, pair{k: key})
+	if !ok || !bytes.Equal(item.k, key) {
+		return nil, nil
+	}
+	return item.v, nil
+}
+
+func (tx *BtreeTx) Put(table string, k, v []byte) error {
+	c, err := tx.statelessCursor(table)
+	if err != nil {
+		return err
+	}
+	return c.Put(k, v)
+}
+
+// Delete - removes key and all it's values (for DupSort tables)
+func (tx *BtreeTx) Delete(table string, k []byte) error {
+	c, err := tx.statelessCursor(table)
+	if err != nil {
+		return err
+	}
+	return c.Delete(k)
+}
+
+func (tx *BtreeTx) Append(table string, k, v []byte) error {
+	c, err := tx.statelessCursor(table)
+	if err != nil {
+		return err
+	}
+	return c.Append(k, v)
+}
+
+func (tx *BtreeTx) AppendDup(table string, k, v []byte) error {
+	c, err := tx.statelessCursor(table)
+	if err != nil {
+		return err
+	}
+	return c.AppendDup(k, v)
+}
+
+func (tx *BtreeTx) IncrementSequence(bucket string, amount uint64) (uint64, error) {
+	currentV, err := tx.ReadSequence(bucket)
+	if err != nil {
+		return 0, err
+	}
+	newVBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(newVBytes, currentV+amount)
+	if err = tx.Put(kv.Sequence, []byte(bucket), newVBytes); err != nil {
+		return 0, err
+	}
+	return currentV, nil
+}
+
+func (tx *BtreeTx) ReadSequence(bucket string) (uint64, error) {
+	v, err := tx.GetOne(kv.Sequence, []byte(bucket))
+	if err != nil {
+		return 0, err
+	}
+	var currentV uint64
+	if len(v) > 0 {
+		currentV = binary.BigEndian.Uint64(v)
+	}
+	return currentV, nil
+}
+
+func (tx *BtreeTx) ForEach(bucket string, fromPrefix []byte, walker func(k, v []byte) error) error {
+	c, err := tx.Cursor(bucket)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	for k, v, err := c.Seek(fromPrefix); k != nil; k, v, err = c.Next() {
+		if err != nil {
+			return err
+		}
+		if err := walker(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tx *BtreeTx) ForAmount(bucket string, fromPrefix []byte, amount uint32, walker func(k, v []byte) error) error {
+	if amount == 0 {
+		return nil
+	}
+	c, err := tx.Cursor(bucket)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	for k, v, err := c.Seek(fromPrefix); k != nil && amount > 0; k, v, err = c.Next() {
+		if err != nil {
+			return err
+		}
+		if err := walker(k, v); err != nil {
+			return err
+		}
+		amount--
+	}
+	return nil
+}
+
+func (tx *BtreeTx) Count(bucket string) (uint64, error) {
+	t, err := tx.table(bucket)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(t.tree.Len()), nil
+}
+
+// BucketSize - approximation: sum of keys and values sizes
+func (tx *BtreeTx) BucketSize(bucket string) (uint64, error) {
+	t, err := tx.table(bucket)
+	if err != nil {
+		return 0, err
+	}
+	var size uint64
+	t.tree.Scan(func(item pair) bool {
+		size += uint64(len(item.k) + len(item.v))
+		return true
+	})
+	return size, nil
+}
+
+func (tx *BtreeTx) DBSize() (uint64, error) {
+	var size uint64
+	for name := range tx.tables {
+		sz, err := tx.BucketSize(name)
+		if err != nil {
+			return 0, err
+		}
+		size += sz
+	}
+	return size, nil
+}
+
+func (tx *BtreeTx) ListBuckets() ([]string, error) {
+	if tx.done {
+		return nil, ErrTxDone
+	}
+	res := make([]string, 0, len(tx.tables))
+	for name := range tx.tables {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+func (tx *BtreeTx) CreateBucket(name string) error {
+	if tx.done {
+		return ErrTxDone
+	}
+	if _, ok := tx.tables[name]; ok {
+		return nil
+	}
+	cfg := tx.buckets[name]
+	cfg.IsDeprecated = false
+	tx.buckets[name] = cfg
+	tx.tables[name] = newTable(cfg.Flags&kv.DupSort != 0)
+	return nil
+}
+
+func (tx *BtreeTx) ExistsBucket(name string) (bool, error) {
+	if tx.done {
+		return false, ErrTxDone
+	}
+	_, ok := tx.tables[name]
+	return ok, nil
+}
+
+func (tx *BtreeTx) ClearBucket(name string) error {
+	t, err := tx.rwTable(name)
+	if err != nil {
+		if errors.Is(err, ErrTableNotFound) {
+			return nil
+		}
+		return err
+	}
+	t.tree.Clear()
+	return nil
+}
+
+func (tx *BtreeTx) DropBucket(name string) error {
+	if cfg, ok := tx.buckets[name]; !(ok && cfg.IsDeprecated) {
+		return fmt.Errorf("%w, bucket: %s", kv.ErrAttemptToDeleteNonDeprecatedBucket, name)
+	}
+	delete(tx.tables, name)
+	return nil
+}
+
+func (tx *BtreeTx) Cursor(bucket string) (kv.Cursor, error) { return tx.RwCursor(bucket) }
+func (tx *BtreeTx) CursorDupSort(bucket string) (kv.CursorDupSort, error) {
+	return tx.RwCursorDupSort(bucket)
+}
+
+func (tx *BtreeTx) RwCursor(bucket string) (kv.RwCursor, error) {
+	c, err := tx.newCursor(bucket)
+	if err != nil {
+		return nil, err
+	}
+	if c.t.dupSort {
+		return &BtreeDupSortCursor{BtreeCursor: c}, nil
+	}
+	return c, nil
+}
+
+func (tx *BtreeTx) RwCursorDupSort(bucket string) (kv.RwCursorDupSort, error) {
+	c, err := tx.newCursor(bucket)
+	if err != nil {
+		return nil, err
+	}
+	return &BtreeDupSortCursor{BtreeCursor: c}, nil
+}
+
+func (tx *BtreeTx) newCursor(bucket string) (*BtreeCursor, error) {
+	t, err := tx.table(bucket)
+	if err != nil {
+		return nil, err
+	}
+	return &BtreeCursor{tx: tx, bucketName: bucket, t: t}, nil
+}
+
+func (tx *BtreeTx) Prefix(table string, prefix []byte) (stream.KV, error) {
+	nextPrefix, ok := kv.NextSubtree(prefix)
+	if !ok {
+		return tx.Range(table, prefix, nil)
+	}
+	return tx.Range(table, prefix, nextPrefix)
+}
+
+func (tx *BtreeTx) Range(table string, fromPrefix, toPrefix []byte) (stream.KV, error) {
+	return tx.RangeAscend(table, fromPrefix, toPrefix, -1)
+}
+func (tx *BtreeTx) RangeAscend(table string, fromPrefix, toPrefix []byte, limit int) (stream.KV, error) {
+	return tx.rangeOrderLimit(table, fromPrefix, toPrefix, order.Asc, limit)
+}
+func (tx *BtreeTx) RangeDescend(table string, fromPrefix, toPrefix []byte, limit int) (stream.KV, error) {
+	return tx.rangeOrderLimit(table, fromPrefix, toPrefix, order.Desc, limit)
+}
+
+// rangeOrderLimit - works on O(1) snapshot of table: later writes of this tx are not visible to the stream.
+func (tx *BtreeTx) rangeOrderLimit(table string, fromPrefix, toPrefix []byte, orderAscend order.By, limit int) (stream.KV, error) {
+	if orderAscend && fromPrefix != nil && toPrefix != nil && bytes.Compare(fromPrefix, toPrefix) >= 0 {
+		return nil, fmt.Errorf("tx.Range: %x must be lexicographicaly before %x", fromPrefix, toPrefix)
+	}
+	if !orderAscend && fromPrefix != nil && toPrefix != nil && bytes.Compare(fromPrefix, toPrefix) <= 0 {
+		return nil, fmt.Errorf("tx.Range: %x must be lexicographicaly before %x", toPrefix, fromPrefix)
+	}
+	t, err := tx.table(table)
+	if err != nil {
+		return nil, err
+	}
+	t = t.copy()
+	s := &treeIter{ctx: tx.ctx, t: t, toPrefix: toPrefix, orderAscend: orderAscend, limit: limit}
+	switch {
+	case fromPrefix == nil && bool(orderAscend):
+		s.next, s.ok = t.tree.Min()
+	case fromPrefix == nil:
+		s.next, s.ok = t.tree.Max()
+	case bool(orderAscend):
+		s.next, s.ok = seekGE(t, pair{k: fromPrefix})
+	default:
+		// last pair with prefix `fromPrefix`
+		nextPrefix, ok := kv.NextSubtree(fromPrefix)
+		if !ok {
+			s.next, s.ok = t.tree.Max()
+		} else {
+			s.next, s.ok = seekLT(t, pair{k: nextPrefix})
+		}
+	}
+	return s, nil
+}
+
+func (tx *BtreeTx) RangeDupSort(table string, key []byte, fromPrefix, toPrefix []byte, asc order.By, limit int) (stream.KV, error) {
+	if asc && fromPrefix != nil && toPrefix != nil && bytes.Compare(fromPrefix, toPrefix) >= 0 {
+		return nil, fmt.Errorf("tx.RangeDupSort: %x must be lexicographicaly before %x", fromPrefix, toPrefix)
+	}
+	if !asc && fromPrefix != nil && toPrefix != nil && bytes.Compare(fromPrefix, toPrefix) <= 0 {
+		return nil, fmt.Errorf("tx.RangeDupSort: %x must be lexicographicaly before %x", toPrefix, fromPrefix)
+	}
+	t, err := tx.table(table)
+	if err != nil {
+		return nil, err
+	}
+	t = t.copy()
+	s := &treeIter{ctx: tx.ctx, t: t, key: key, toPrefix: toPrefix, orderAscend: asc, limit: limit}
+	switch {
+	case fromPrefix == nil && bool(asc):
+		s.next, s.ok = seekGE(t, pair{k: key})
+	case fromPrefix == nil:
+		s.next, s.ok = seekLT(t, pair{k: key, inf: true})
+	case bool(asc):
+		s.next, s.ok = seekGE(t, pair{k: key, v: fromPrefix})
+	default:
+		nextPrefix, ok := kv.NextSubtree(fromPrefix)
+		if !ok {
+			s.next, s.ok = seekLT(t, pair{k: key, inf: true})
+		} else {
+			s.next, s.ok = seekLT(t, pair{k: key, v: nextPrefix})
+		}
+	}
+	return s, nil
+}
+
+// treeIter - stream.KV over snapshot of table.
+// if `key != nil` - iterates only over values of this key (DupSort) and `toPrefix` bounds values.
+type treeIter struct {
+	ctx         context.Context
+	t           *table
+	key         []byte
+	toPrefix    []byte
+	orderAscend order.By
+	limit       int
+	next        pair
+	ok          bool
+}
+
+func (s *treeIter) Close() {}
+
+func (s *treeIter) HasNext() bool {
+	if s.limit == 0 || !s.ok {
+		return false
+	}
+	if s.key != nil && !bytes.Equal(s.next.k, s.key) {
+		return false
+	}
+	if s.toPrefix == nil {
+		return true
+	}
+
+	//Asc:  [from, to) AND from < to
+	//Desc: [from, to) AND from > to
+	cur := s.next.k
+	if s.key != nil {
+		cur = s.next.v
+	}
+	cmp := bytes.Compare(cur, s.toPrefix)
+	return (bool(s.orderAscend) && cmp < 0) || (!bool(s.orderAscend) && cmp > 0)
+}
+
+func (s *treeIter) Next() (k, v []byte, err error) {
+	select {
+	case <-s.ctx.Done():
+		return nil, nil, s.ctx.Err()
+	default:
+	}
+	s.limit--
+	k, v = s.next.k, s.next.v
+	if s.orderAscend {
+		s.next, s.ok = seekGT(s.t, s.next)
+	} else {
+		s.next, s.ok = seekLT(s.t, s.next)
+	}
+	return k, v, nil
+}
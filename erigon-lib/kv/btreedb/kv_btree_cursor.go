@@ -0,0 +1,404 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package btreedb
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/erigontech/erigon-lib/common"
+)
+
+// seekGE - first pair >= pivot
+func seekGE(t *table, pivot pair) (res pair, ok bool) {
+	t.tree.Ascend(pivot, func(item pair) bool {
+		res, ok = item, true
+		return false
+	})
+	return res, ok
+}
+
+// seekGT - first pair > pivot
+func seekGT(t *table, pivot pair) (res pair, ok bool) {
+	t.tree.Ascend(pivot, func(item pair) bool {
+		if !t.tree.Less(pivot, item) {
+			return true
+		}
+		res, ok = item, true
+		return false
+	})
+	return res, ok
+}
+
+// seekLT - last pair < pivot
+func seekLT(t *table, pivot pair) (res pair, ok bool) {
+	t.tree.Descend(pivot, func(item pair) bool {
+		if !t.tree.Less(item, pivot) {
+			return true
+		}
+		res, ok = item, true
+		return false
+	})
+	return res, ok
+}
+
+// BtreeCursor - doesn't hold b-tree iterators (they are invalidated by writes),
+// instead it remembers current pair and re-seeks on every move. Every operation is O(log(n)).
+//
+// Semantic follows MDBX cursors:
+//   - Next/Prev on un-positioned cursor - are same as First/Last
+//   - after DeleteCurrent: Current and Next return the record which was after deleted one
+type BtreeCursor struct {
+	tx         *BtreeTx
+	t          *table
+	bucketName string
+
+	cur         pair
+	positioned  bool
+	afterDelete bool // `cur` is a successor of deleted pair (or `positioned=false` if there was no successor)
+	deleted     pair // last deleted pair, used to Prev() from the end of table
+}
+
+func (c *BtreeCursor) set(item pair, ok bool) ([]byte, []byte, error) {
+	c.afterDelete = false
+	if !ok {
+		return nil, nil, nil
+	}
+	c.cur, c.positioned = item, true
+	return item.k, item.v, nil
+}
+
+func (c *BtreeCursor) checkTx() error {
+	if c.tx.done {
+		return fmt.Errorf("table: %s, %w", c.bucketName, ErrTxDone)
+	}
+	return nil
+}
+
+func (c *BtreeCursor) First() ([]byte, []byte, error) {
+	if err := c.checkTx(); err != nil {
+		return []byte{}, nil, err
+	}
+	return c.set(c.t.tree.Min())
+}
+
+func (c *BtreeCursor) Last() ([]byte, []byte, error) {
+	if err := c.checkTx(); err != nil {
+		return []byte{}, nil, err
+	}
+	return c.set(c.t.tree.Max())
+}
+
+func (c *BtreeCursor) Seek(seek []byte) ([]byte, []byte, error) {
+	if err := c.checkTx(); err != nil {
+		return []byte{}, nil, err
+	}
+	if len(seek) == 0 {
+		return c.set(c.t.tree.Min())
+	}
+	return c.set(seekGE(c.t, pair{k: seek}))
+}
+
+func (c *BtreeCursor) SeekExact(key []byte) ([]byte, []byte, error) {
+	if err := c.checkTx(); err != nil {
+		return []byte{}, nil, err
+	}
+	item, ok := seekGE(c.t, pair{k: key})
+	if !ok || !bytes.Equal(item.k, key) {
+		return nil, nil, nil
+	}
+	return c.set(item, true)
+}
+
+func (c *BtreeCursor) Next() ([]byte, []byte, error) {
+	if err := c.checkTx(); err != nil {
+		return []byte{}, nil, err
+	}
+	if c.afterDelete {
+		c.afterDelete = false
+		if !c.positioned {
+			return nil, nil, nil
+		}
+		return c.cur.k, c.cur.v, nil
+	}
+	if !c.positioned {
+		return c.First()
+	}
+	return c.set(seekGT(c.t, c.cur))
+}
+
+func (c *BtreeCursor) Prev() ([]byte, []byte, error) {
+	if err := c.checkTx(); err != nil {
+		return []byte{}, nil, err
+	}
+	if c.afterDelete && !c.positioned {
+		return c.set(seekLT(c.t, c.deleted))
+	}
+	if !c.positioned {
+		return c.Last()
+	}
+	return c.set(seekLT(c.t, c.cur))
+}
+
+// Current - return key/data at current cursor position
+func (c *BtreeCursor) Current() ([]byte, []byte, error) {
+	if err := c.checkTx(); err != nil {
+		return []byte{}, nil, err
+	}
+	if !c.positioned {
+		return nil, nil, nil
+	}
+	return c.cur.k, c.cur.v, nil
+}
+
+func (c *BtreeCursor) Put(k, v []byte) error {
+	if _, err := c.tx.rwTable(c.bucketName); err != nil {
+		return err
+	}
+	item := pair{k: common.Copy(k), v: common.Copy(v)}
+	c.t.tree.Set(item)
+	c.set(item, true)
+	return nil
+}
+
+// Append - append the given key/data pair to the end of the table.
+// Returns error if provided pair is not greater than the last pair of table.
+func (c *BtreeCursor) Append(k, v []byte) error {
+	if _, err := c.tx.rwTable(c.bucketName); err != nil {
+		return err
+	}
+	item := pair{k: k, v: v}
+	if last, ok := c.t.tree.Max(); ok && !c.t.tree.Less(last, item) {
+		return fmt.Errorf("table: %s, key: %x, %w", c.bucketName, k, ErrKeyMismatch)
+	}
+	return c.Put(k, v)
+}
+
+// AppendDup - same as Append, but only values of the key `k` must be ordered
+func (c *BtreeCursor) AppendDup(k, v []byte) error {
+	if _, err := c.tx.rwTable(c.bucketName); err != nil {
+		return err
+	}
+	item := pair{k: k, v: v}
+	if last, ok := seekLT(c.t, pair{k: k, inf: true}); ok && bytes.Equal(last.k, k) && !c.t.tree.Less(last, item) {
+		return fmt.Errorf("table: %s, key: %x, %w", c.bucketName, k, ErrKeyMismatch)
+	}
+	return c.Put(k, v)
+}
+
+// Delete - short version of SeekExact+DeleteCurrent. For DupSort tables removes all values of the key.
+func (c *BtreeCursor) Delete(k []byte) error {
+	if _, err := c.tx.rwTable(c.bucketName); err != nil {
+		return err
+	}
+	if k, _, err := c.SeekExact(k); err != nil || k == nil {
+		return err
+	}
+	if c.t.dupSort {
+		return c.deleteCurrentDuplicates()
+	}
+	return c.DeleteCurrent()
+}
+
+// DeleteCurrent This function deletes the key/data pair to which the cursor refers.
+// This does not invalidate the cursor, so operations such as MDB_NEXT
+// can still be used on it.
+// Both MDB_NEXT and MDB_GET_CURRENT will return the same record after
+// this operation.
+func (c *BtreeCursor) DeleteCurrent() error {
+	if _, err := c.tx.rwTable(c.bucketName); err != nil {
+		return err
+	}
+	if !c.positioned {
+		return fmt.Errorf("table: %s, DeleteCurrent on not positioned cursor", c.bucketName)
+	}
+	c.t.tree.Delete(c.cur)
+	c.deleted = c.cur
+	c.cur, c.positioned = seekGE(c.t, c.cur)
+	c.afterDelete = true
+	return nil
+}
+
+func (c *BtreeCursor) deleteCurrentDuplicates() error {
+	if _, err := c.tx.rwTable(c.bucketName); err != nil {
+		return err
+	}
+	if !c.positioned {
+		return fmt.Errorf("table: %s, DeleteCurrentDuplicates on not positioned cursor", c.bucketName)
+	}
+	key := c.cur.k
+	for {
+		item, ok := seekGE(c.t, pair{k: key})
+		if !ok || !bytes.Equal(item.k, key) {
+			break
+		}
+		c.t.tree.Delete(item)
+	}
+	c.deleted = pair{k: key, inf: true}
+	c.cur, c.positioned = seekGE(c.t, c.deleted)
+	c.afterDelete = true
+	return nil
+}
+
+func (c *BtreeCursor) Close() {}
+
+type BtreeDupSortCursor struct {
+	*BtreeCursor
+}
+
+// DeleteExact - delete 1 value from given key
+func (c *BtreeDupSortCursor) DeleteExact(k1, k2 []byte) error {
+	k, _, err := c.SeekBothExact(k1, k2)
+	if err != nil || k == nil {
+		return err
+	}
+	return c.DeleteCurrent()
+}
+
+func (c *BtreeDupSortCursor) SeekBothExact(key, value []byte) ([]byte, []byte, error) {
+	if err := c.checkTx(); err != nil {
+		return []byte{}, nil, err
+	}
+	item, ok := c.t.tree.Get(pair{k: key, v: value})
+	if !ok || (!c.t.dupSort && !bytes.Equal(item.v, value)) {
+		return nil, nil, nil
+	}
+	return c.set(item, true)
+}
+
+func (c *BtreeDupSortCursor) SeekBothRange(key, value []byte) ([]byte, error) {
+	if err := c.checkTx(); err != nil {
+		return nil, err
+	}
+	item, ok := seekGE(c.t, pair{k: key, v: value})
+	if !ok || !bytes.Equal(item.k, key) {
+		return nil, nil
+	}
+	if !c.t.dupSort && bytes.Compare(item.v, value) < 0 {
+		return nil, nil
+	}
+	_, v, err := c.set(item, true)
+	return v, err
+}
+
+func (c *BtreeDupSortCursor) FirstDup() ([]byte, error) {
+	if err := c.checkTx(); err != nil {
+		return nil, err
+	}
+	if !c.positioned {
+		return nil, nil
+	}
+	_, v, err := c.set(seekGE(c.t, pair{k: c.cur.k}))
+	return v, err
+}
+
+func (c *BtreeDupSortCursor) LastDup() ([]byte, error) {
+	if err := c.checkTx(); err != nil {
+		return nil, err
+	}
+	if !c.positioned {
+		return nil, nil
+	}
+	_, v, err := c.set(seekLT(c.t, pair{k: c.cur.k, inf: true}))
+	return v, err
+}
+
+// NextDup - iterate only over duplicates of current key
+func (c *BtreeDupSortCursor) NextDup() ([]byte, []byte, error) {
+	if err := c.checkTx(); err != nil {
+		return []byte{}, nil, err
+	}
+	if !c.positioned {
+		return nil, nil, nil
+	}
+	item, ok := seekGT(c.t, c.cur)
+	if !ok || !bytes.Equal(item.k, c.cur.k) {
+		return nil, nil, nil
+	}
+	return c.set(item, true)
+}
+
+// NextNoDup - iterate with skipping all duplicates
+func (c *BtreeDupSortCursor) NextNoDup() ([]byte, []byte, error) {
+	if err := c.checkTx(); err != nil {
+		return []byte{}, nil, err
+	}
+	if !c.positioned {
+		return c.First()
+	}
+	return c.set(seekGT(c.t, pair{k: c.cur.k, inf: true}))
+}
+
+func (c *BtreeDupSortCursor) PrevDup() ([]byte, []byte, error) {
+	if err := c.checkTx(); err != nil {
+		return []byte{}, nil, err
+	}
+	if !c.positioned {
+		return nil, nil, nil
+	}
+	item, ok := seekLT(c.t, c.cur)
+	if !ok || !bytes.Equal(item.k, c.cur.k) {
+		return nil, nil, nil
+	}
+	return c.set(item, true)
+}
+
+// PrevNoDup - position at last data item of previous key
+func (c *BtreeDupSortCursor) PrevNoDup() ([]byte, []byte, error) {
+	if err := c.checkTx(); err != nil {
+		return []byte{}, nil, err
+	}
+	if !c.positioned {
+		return c.Last()
+	}
+	return c.set(seekLT(c.t, pair{k: c.cur.k}))
+}
+
+// CountDuplicates returns the number of duplicates for the current key
+func (c *BtreeDupSortCursor) CountDuplicates() (uint64, error) {
+	if err := c.checkTx(); err != nil {
+		return 0, err
+	}
+	if !c.positioned {
+		return 0, nil
+	}
+	var cnt uint64
+	c.t.tree.Ascend(pair{k: c.cur.k}, func(item pair) bool {
+		if !bytes.Equal(item.k, c.cur.k) {
+			return false
+		}
+		cnt++
+		return true
+	})
+	return cnt, nil
+}
+
+// PutNoDupData - inserts key/value pair, returns error if this pair already exists
+func (c *BtreeDupSortCursor) PutNoDupData(k, v []byte) error {
+	if _, err := c.tx.rwTable(c.bucketName); err != nil {
+		return err
+	}
+	if _, ok := c.t.tree.Get(pair{k: k, v: v}); ok {
+		return fmt.Errorf("table: %s, key: %x, %w", c.bucketName, k, ErrKeyExist)
+	}
+	return c.Put(k, v)
+}
+
+// DeleteCurrentDuplicates - deletes all of the data items for the current key
+func (c *BtreeDupSortCursor) DeleteCurrentDuplicates() error {
+	return c.deleteCurrentDuplicates()
+}
@@ -0,0 +1,240 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package btreedb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/order"
+	"github.com/erigontech/erigon-lib/kv/stream"
+	"github.com/erigontech/erigon-lib/log/v3"
+)
+
+func BaseCase(t *testing.T) (kv.RwDB, kv.RwTx, kv.RwCursorDupSort) {
+	t.Helper()
+	db := NewBtree(log.New()).MustOpen()
+	t.Cleanup(db.Close)
+	tx, err := db.BeginRw(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(tx.Rollback)
+
+	c, err := tx.RwCursorDupSort(kv.PlainState)
+	require.NoError(t, err)
+
+	// Insert some dupsorted records
+	require.NoError(t, c.Put([]byte("key1"), []byte("value1.1")))
+	require.NoError(t, c.Put([]byte("key3"), []byte("value3.1")))
+	require.NoError(t, c.Put([]byte("key1"), []byte("value1.3")))
+	require.NoError(t, c.Put([]byte("key3"), []byte("value3.3")))
+	return db, tx, c
+}
+
+func TestSeekBothRange(t *testing.T) {
+	_, _, c := BaseCase(t)
+
+	v, err := c.SeekBothRange([]byte("key2"), []byte("value1.2"))
+	require.NoError(t, err)
+	require.Nil(t, v)
+
+	v, err = c.SeekBothRange([]byte("key3"), []byte("value3.2"))
+	require.NoError(t, err)
+	require.Equal(t, "value3.3", string(v))
+}
+
+func TestDupSortNavigation(t *testing.T) {
+	_, _, c := BaseCase(t)
+	require.NoError(t, c.Put([]byte("key1"), []byte("value1.2")))
+
+	k, v, err := c.First()
+	require.NoError(t, err)
+	require.Equal(t, "key1", string(k))
+	require.Equal(t, "value1.1", string(v))
+
+	cnt, err := c.CountDuplicates()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), cnt)
+
+	v, err = c.LastDup()
+	require.NoError(t, err)
+	require.Equal(t, "value1.3", string(v))
+
+	k, v, err = c.NextDup()
+	require.NoError(t, err)
+	require.Nil(t, k)
+	require.Nil(t, v)
+
+	k, v, err = c.NextNoDup()
+	require.NoError(t, err)
+	require.Equal(t, "key3", string(k))
+	require.Equal(t, "value3.1", string(v))
+
+	k, v, err = c.PrevNoDup()
+	require.NoError(t, err)
+	require.Equal(t, "key1", string(k))
+	require.Equal(t, "value1.3", string(v))
+
+	k, v, err = c.PrevDup()
+	require.NoError(t, err)
+	require.Equal(t, "key1", string(k))
+	require.Equal(t, "value1.2", string(v))
+
+	v, err = c.FirstDup()
+	require.NoError(t, err)
+	require.Equal(t, "value1.1", string(v))
+
+	k, v, err = c.Last()
+	require.NoError(t, err)
+	require.Equal(t, "key3", string(k))
+	require.Equal(t, "value3.3", string(v))
+
+	k, v, err = c.SeekBothExact([]byte("key1"), []byte("value1.2"))
+	require.NoError(t, err)
+	require.Equal(t, "key1", string(k))
+	require.Equal(t, "value1.2", string(v))
+
+	k, _, err = c.SeekBothExact([]byte("key1"), []byte("value1.4"))
+	require.NoError(t, err)
+	require.Nil(t, k)
+}
+
+func TestDeleteCurrent(t *testing.T) {
+	_, tx, c := BaseCase(t)
+
+	_, _, err := c.SeekBothExact([]byte("key1"), []byte("value1.3"))
+	require.NoError(t, err)
+	require.NoError(t, c.DeleteCurrent())
+
+	// mdbx semantic: Current and Next return the record after deleted one
+	k, v, err := c.Current()
+	require.NoError(t, err)
+	require.Equal(t, "key3", string(k))
+	require.Equal(t, "value3.1", string(v))
+	k, v, err = c.Next()
+	require.NoError(t, err)
+	require.Equal(t, "key3", string(k))
+	require.Equal(t, "value3.1", string(v))
+
+	require.NoError(t, c.DeleteExact([]byte("key3"), []byte("value3.1")))
+	cnt, err := tx.Count(kv.PlainState)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), cnt)
+
+	require.NoError(t, tx.Delete(kv.PlainState, []byte("key3")))
+	k, v, err = c.Last()
+	require.NoError(t, err)
+	require.Equal(t, "key1", string(k))
+	require.Equal(t, "value1.1", string(v))
+
+	require.NoError(t, c.DeleteCurrentDuplicates())
+	k, _, err = c.First()
+	require.NoError(t, err)
+	require.Nil(t, k)
+}
+
+func TestAppend(t *testing.T) {
+	_, tx, c := BaseCase(t)
+
+	require.Error(t, c.Append([]byte("key2"), []byte("value2.1")))
+	require.NoError(t, c.Append([]byte("key4"), []byte("value4.1")))
+	require.NoError(t, c.AppendDup([]byte("key1"), []byte("value1.4")))
+	require.Error(t, c.AppendDup([]byte("key1"), []byte("value1.2")))
+	require.Error(t, c.PutNoDupData([]byte("key1"), []byte("value1.1")))
+
+	require.NoError(t, tx.Put(kv.Headers, []byte{1}, []byte{1}))
+	require.NoError(t, tx.Append(kv.Headers, []byte{2}, []byte{1}))
+	require.Error(t, tx.Append(kv.Headers, []byte{2}, []byte{2}))
+	require.NoError(t, tx.Put(kv.Headers, []byte{2}, []byte{2})) // not DupSort table: overwrite
+	v, err := tx.GetOne(kv.Headers, []byte{2})
+	require.NoError(t, err)
+	require.Equal(t, []byte{2}, v)
+}
+
+func TestRange(t *testing.T) {
+	_, tx, _ := BaseCase(t)
+
+	it, err := tx.Range(kv.PlainState, []byte("key1"), []byte("key3"))
+	require.NoError(t, err)
+	keys, values, err := stream.ToArrayKV(it)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("key1"), []byte("key1")}, keys)
+	require.Equal(t, [][]byte{[]byte("value1.1"), []byte("value1.3")}, values)
+
+	it, err = tx.RangeDescend(kv.PlainState, []byte("key3"), nil, 3)
+	require.NoError(t, err)
+	_, values, err = stream.ToArrayKV(it)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("value3.3"), []byte("value3.1"), []byte("value1.3")}, values)
+
+	it, err = tx.RangeDupSort(kv.PlainState, []byte("key3"), nil, nil, order.Desc, -1)
+	require.NoError(t, err)
+	_, values, err = stream.ToArrayKV(it)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("value3.3"), []byte("value3.1")}, values)
+
+	it, err = tx.RangeDupSort(kv.PlainState, []byte("key1"), []byte("value1.2"), nil, order.Asc, -1)
+	require.NoError(t, err)
+	_, values, err = stream.ToArrayKV(it)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("value1.3")}, values)
+
+	cnt := 0
+	require.NoError(t, tx.ForEach(kv.PlainState, []byte("key2"), func(k, v []byte) error {
+		cnt++
+		return nil
+	}))
+	require.Equal(t, 2, cnt)
+}
+
+func TestSnapshotIsolation(t *testing.T) {
+	db, tx, _ := BaseCase(t)
+	ctx := context.Background()
+
+	// uncommitted data is not visible
+	require.NoError(t, db.View(ctx, func(roTx kv.Tx) error {
+		cnt, err := roTx.Count(kv.PlainState)
+		require.NoError(t, err)
+		require.Zero(t, cnt)
+		return nil
+	}))
+	require.NoError(t, tx.Commit())
+
+	roTx, err := db.BeginRo(ctx)
+	require.NoError(t, err)
+	defer roTx.Rollback()
+
+	require.NoError(t, db.Update(ctx, func(tx kv.RwTx) error {
+		return tx.ClearBucket(kv.PlainState)
+	}))
+
+	// old reader still see old data
+	cnt, err := roTx.Count(kv.PlainState)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), cnt)
+	roTx.Rollback()
+
+	err = db.View(ctx, func(roTx kv.Tx) error {
+		cnt, err := roTx.Count(kv.PlainState)
+		require.NoError(t, err)
+		require.Zero(t, cnt)
+		return roTx.(kv.RwTx).Put(kv.PlainState, []byte{1}, []byte{1})
+	})
+	require.ErrorIs(t, err, ErrTxReadOnly)
+}
@@ -25,19 +25,20 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/erigontech/mdbx-go/mdbx"
-
 	"github.com/erigontech/erigon-lib/common/hexutility"
 
 	"github.com/erigontech/erigon-lib/common"
 )
 
+// MaxPageSize - same as mdbx.MaxPageSize (MDBX_MAX_PAGESIZE). Declared here to keep package `kv` free of cgo.
+const MaxPageSize = 65536
+
 func DefaultPageSize() uint64 {
 	osPageSize := os.Getpagesize()
 	if osPageSize < 4096 { // reduce further may lead to errors (because some data is just big)
 		osPageSize = 4096
-	} else if osPageSize > mdbx.MaxPageSize {
-		osPageSize = mdbx.MaxPageSize
+	} else if osPageSize > MaxPageSize {
+		osPageSize = MaxPageSize
 	}
 	osPageSize = osPageSize / 4096 * 4096 // ensure it's rounded
 	return uint64(osPageSize)
@@ -31,6 +31,7 @@ import (
 	"github.com/erigontech/erigon-lib/gointerfaces"
 	remote "github.com/erigontech/erigon-lib/gointerfaces/remoteproto"
 	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/btreedb"
 	"github.com/erigontech/erigon-lib/kv/mdbx"
 	"github.com/erigontech/erigon-lib/kv/memdb"
 	"github.com/erigontech/erigon-lib/kv/remotedb"
@@ -143,7 +144,7 @@ func TestManagedTx(t *testing.T) {
 		db := db
 		msg := fmt.Sprintf("%T", db)
 		switch db.(type) {
-		case *remotedb.DB:
+		case *remotedb.DB, *btreedb.BtreeKV:
 		default:
 			continue
 		}
@@ -337,6 +338,7 @@ func setupDatabases(t *testing.T, logger log.Logger, f mdbx.TableCfgFunc) (write
 	writeDBs = []kv.RwDB{
 		mdbx.NewMDBX(logger).InMem("").WithTableCfg(f).MustOpen(),
 		mdbx.NewMDBX(logger).InMem("").WithTableCfg(f).MustOpen(), // for remote db
+		btreedb.NewBtree(logger).WithTableCfg(btreedb.TableCfgFunc(f)).MustOpen(),
 	}
 
 	conn := bufconn.Listen(1024 * 1024)
@@ -358,6 +360,7 @@ func setupDatabases(t *testing.T, logger log.Logger, f mdbx.TableCfgFunc) (write
 		writeDBs[0],
 		writeDBs[1],
 		rdb,
+		writeDBs[2],
 	}
 
 	t.Cleanup(func() {
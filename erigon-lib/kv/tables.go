@@ -423,6 +423,8 @@ const (
 	LastBeaconSnapshotKey = "LastBeaconSnapshotKey"
 
 	BlockRootToKzgCommitments = "BlockRootToKzgCommitments"
+	// [Block Root] => bitvector of the data columns stored for the block
+	BlockRootToDataColumns = "BlockRootToDataColumns"
 
 	// [Block Root] => [Parent Root]
 	BlockRootToParentRoot  = "BlockRootToParentRoot"
@@ -444,6 +446,8 @@ const (
 	StaticValidators          = "StaticValidators"
 	StateEvents               = "StateEvents"
 	ActiveValidatorIndicies   = "ActiveValidatorIndicies"
+	// Slot => epoch participation flags changed by the block at that slot
+	ParticipationDiffs = "ParticipationDiffs"
 
 	// External data
 	StateRoot = "StateRoot"
@@ -458,6 +462,8 @@ const (
 	HistoricalRoots      = "HistoricalRoots"
 	HistoricalSummaries  = "HistoricalSummaries"
 	Eth1DataVotes        = "Eth1DataVotes"
+	// deposit count => eth1 block hash + finalized roots of the EIP-4881 deposit tree holding that many deposits
+	DepositTreeSnapshots = "DepositTreeSnapshots"
 
 	IntraRandaoMixes = "IntraRandaoMixes" // [validator_index+slot] => [randao_mix]
 	RandaoMixes      = "RandaoMixes"      // [validator_index+slot] => [randao_mix]
@@ -604,6 +610,7 @@ var ChaindataTables = []string{
 	ParentRootToBlockRoots,
 	// Blob Storage
 	BlockRootToKzgCommitments,
+	BlockRootToDataColumns,
 	// State Reconstitution
 	ValidatorPublicKeys,
 	InvertedValidatorPublicKeys,
@@ -612,6 +619,7 @@ var ChaindataTables = []string{
 	ValidatorSlashings,
 	StaticValidators,
 	StateEvents,
+	ParticipationDiffs,
 	// Other stuff (related to state reconstitution)
 	BlockRoot,
 	StateRoot,
@@ -626,6 +634,7 @@ var ChaindataTables = []string{
 	HistoricalRoots,
 	HistoricalSummaries,
 	Eth1DataVotes,
+	DepositTreeSnapshots,
 	IntraRandaoMixes,
 	ActiveValidatorIndicies,
 	EffectiveBalancesDump,
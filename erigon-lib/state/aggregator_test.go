@@ -1373,3 +1373,54 @@ func TestAggregator_RebuildCommitmentBasedOnFiles(t *testing.T) {
 
 	require.EqualValues(t, roots[len(roots)-1][:], finalRoot[:])
 }
+
+func TestAggregator_VerifyCommitment(t *testing.T) {
+	db, agg := testDbAndAggregatorv3(t, 20)
+
+	ctx := context.Background()
+	ac := agg.BeginFilesRo()
+	defer ac.Close()
+
+	rwTx, err := db.BeginRw(context.Background())
+	require.NoError(t, err)
+	defer rwTx.Rollback()
+
+	domains, err := NewSharedDomains(WrapTxWithCtx(rwTx, ac), log.New())
+	require.NoError(t, err)
+	defer domains.Close()
+
+	txCount := 320
+	keys, vals := generateInputData(t, 20, 16, txCount)
+	for i := 0; i < len(vals); i++ {
+		domains.SetTxNum(uint64(i))
+		for j := 0; j < len(keys); j++ {
+			buf := types.EncodeAccountBytesV3(uint64(i), uint256.NewInt(uint64(i*100_000)), nil, 0)
+			prev, step, err := domains.DomainGet(kv.AccountsDomain, keys[j], nil)
+			require.NoError(t, err)
+			err = domains.DomainPut(kv.AccountsDomain, keys[j], nil, buf, prev, step)
+			require.NoError(t, err)
+		}
+		if uint64(i+1)%agg.StepSize() == 0 {
+			_, err := domains.ComputeCommitment(ctx, true, domains.BlockNum(), "")
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, domains.Flush(context.Background(), rwTx))
+	domains.Close()
+	require.NoError(t, rwTx.Commit())
+	require.NoError(t, agg.BuildFiles(uint64(txCount)))
+
+	ac = agg.BeginFilesRo()
+	defer ac.Close()
+	steps := ac.CommitmentFilesEndSteps()
+	require.NotEmpty(t, steps)
+	for _, step := range steps {
+		report, err := ac.VerifyCommitment(ctx, step, 0)
+		require.NoError(t, err)
+		require.True(t, report.Ok(), "step %d: diverged %d branches, stored root %x rebuilt %x", step, len(report.Diverged), report.StoredRoot, report.RebuiltRoot)
+		require.NotZero(t, report.Branches)
+	}
+
+	_, err = ac.VerifyCommitment(ctx, steps[len(steps)-1]+1, 0)
+	require.Error(t, err)
+}
@@ -2,9 +2,11 @@ package state
 
 import (
 	"fmt"
+	"hash/maphash"
 	"sync"
 
 	"github.com/elastic/go-freelru"
+	"github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/common/dbg"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/log/v3"
@@ -151,3 +153,78 @@ func (v *iiVisible) returnSeekInFilesCache(c *IISeekInFilesCache) {
 	c.LogStats(v.name)
 	v.caches.Put(c)
 }
+
+var (
+	latestCacheLimit = uint32(dbg.EnvInt("LATEST_LRU", 1_000_000))
+	latestCacheSeed  = maphash.MakeSeed()
+)
+
+func stringHash(s string) uint32 { return uint32(maphash.String(latestCacheSeed, s)) }
+
+// LatestCache holds latest values of the accounts, storage and code domains,
+// read ahead of their use by SharedDomains, which serves them when it has no
+// write of their keys. The values must be read from the db state as of the
+// last Reset, which is what the epoch passed to Put is checked against: values
+// read from an older state are dropped.
+type LatestCache struct {
+	lock    sync.RWMutex
+	epoch   uint64
+	domains [kv.DomainLen]*freelru.SyncedLRU[string, dataWithPrevStep]
+}
+
+func NewLatestCache() *LatestCache {
+	c := &LatestCache{}
+	for _, d := range []kv.Domain{kv.AccountsDomain, kv.StorageDomain, kv.CodeDomain} {
+		lru, err := freelru.NewSynced[string, dataWithPrevStep](latestCacheLimit, stringHash)
+		if err != nil {
+			panic(err)
+		}
+		c.domains[d] = lru
+	}
+	return c
+}
+
+// Epoch returns the epoch of the values to read, it changes on Reset.
+func (c *LatestCache) Epoch() uint64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.epoch
+}
+
+// Put adds the value of a key, as read from the state of the given epoch.
+func (c *LatestCache) Put(epoch uint64, domain kv.Domain, k, v []byte, step uint64) {
+	lru := c.domains[domain]
+	if lru == nil {
+		return
+	}
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if epoch != c.epoch {
+		return
+	}
+	lru.Add(string(k), dataWithPrevStep{data: common.Copy(v), prevStep: step})
+}
+
+func (c *LatestCache) Get(domain kv.Domain, k []byte) (v []byte, step uint64, ok bool) {
+	lru := c.domains[domain]
+	if lru == nil {
+		return nil, 0, false
+	}
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	data, ok := lru.Get(string(k))
+	return data.data, data.prevStep, ok
+}
+
+// Reset drops the values and starts a new epoch, to be called once the db
+// state the values are read from changed.
+func (c *LatestCache) Reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.epoch++
+	for _, lru := range c.domains {
+		if lru != nil {
+			lru.Purge()
+		}
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/erigontech/erigon-lib/commitment"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/cryptozerocopy"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/kv/stream"
+	"github.com/erigontech/erigon-lib/types"
+)
+
+// CommitmentBranchDiff - branch of recomputed trie which doesn't match branch stored in Commitment domain
+type CommitmentBranchDiff struct {
+	Prefix   []byte                // compacted nibbles, as Commitment domain key
+	Children uint16                // bitmap of child cells which differ
+	Stored   commitment.BranchData // nil if Commitment domain has no such branch
+	Rebuilt  commitment.BranchData
+}
+
+// CommitmentVerifyReport - result of VerifyCommitment
+type CommitmentVerifyReport struct {
+	Step  uint64 // end step of files (exclusive)
+	TxNum uint64 // last txNum covered by files
+
+	// commitment state stored in Commitment domain at Step. Usually it's behind TxNum (commitment is computed
+	// at the end of block/batch): in this case state is rolled back to StateTxNum by history files
+	StateTxNum, StateBlockNum uint64
+	StoredRoot                []byte
+
+	RebuiltRoot []byte
+	Keys        uint64 // touched Accounts/Storage keys
+	Branches    uint64 // compared branches
+	// Diverged - in order of trie folding: deeper branches go first, so Diverged[0] is the first diverging
+	// branch of the first diverging subtree (its parents differ only because of it)
+	Diverged []CommitmentBranchDiff
+	Took     time.Duration
+}
+
+func (r *CommitmentVerifyReport) Ok() bool {
+	return len(r.Diverged) == 0 && bytes.Equal(r.StoredRoot, r.RebuiltRoot)
+}
+
+// CommitmentFilesEndSteps - steps at which Commitment domain files end: VerifyCommitment can check only them
+func (ac *AggregatorRoTx) CommitmentFilesEndSteps() (steps []uint64) {
+	for _, f := range ac.d[kv.CommitmentDomain].files {
+		steps = append(steps, f.endTxNum/ac.a.StepSize())
+	}
+	return steps
+}
+
+// VerifyCommitment - recomputes HexPatriciaHashed trie from scratch using Accounts/Storage/Code domain (and history)
+// files visible at `step` (files only, DB is not used) and compares every produced branch with Commitment domain files.
+// Doesn't write anything. Keeps in memory only set of touched keys (same as RebuildCommitmentFiles).
+// `maxDiffs` limits amount of diverged branches in report (0 - unlimited).
+func (ac *AggregatorRoTx) VerifyCommitment(ctx context.Context, step uint64, maxDiffs int) (*CommitmentVerifyReport, error) {
+	start := time.Now()
+	toTxNum := step * ac.a.StepSize()
+	if !slices.Contains(ac.CommitmentFilesEndSteps(), step) {
+		return nil, fmt.Errorf("no commitment file ends at step %d, available: %v", step, ac.CommitmentFilesEndSteps())
+	}
+	report := &CommitmentVerifyReport{Step: step, TxNum: toTxNum - 1}
+
+	stateVal, found, _, _, err := ac.d[kv.CommitmentDomain].getFromFiles(keyCommitmentState, toTxNum)
+	if err != nil {
+		return nil, err
+	}
+	if !found || len(stateVal) < 18 {
+		return nil, fmt.Errorf("commitment state not found in files up to step %d", step)
+	}
+	report.StateTxNum, report.StateBlockNum = _decodeTxBlockNums(stateVal)
+	if report.StoredRoot, err = commitment.HexTrieExtractStateRoot(stateVal); err != nil {
+		return nil, fmt.Errorf("decode commitment state: %w", err)
+	}
+
+	trie, updates := commitment.InitializeTrieAndUpdates(commitment.VariantHexPatriciaTrie, commitment.ModeDirect, ac.a.tmpdir)
+	defer updates.Close()
+
+	var keys stream.KV
+	for _, d := range []kv.Domain{kv.AccountsDomain, kv.StorageDomain} {
+		for i, f := range ac.d[d].files {
+			if f.endTxNum > toTxNum {
+				break
+			}
+			keys = stream.UnionKV(keys, NewSegStreamReader(ac.d[d].statelessGetter(i), -1), -1)
+		}
+	}
+	if keys == nil {
+		return nil, fmt.Errorf("no Accounts/Storage files up to step %d", step)
+	}
+	for keys.HasNext() {
+		k, _, err := keys.Next()
+		if err != nil {
+			return nil, err
+		}
+		updates.TouchPlainKey(k, nil, nil)
+		report.Keys++
+	}
+
+	vc := &commitmentVerifyContext{
+		ac:        ac,
+		toTxNum:   toTxNum,
+		asOfTxNum: report.StateTxNum + 1,
+		keccak:    sha3.NewLegacyKeccak256().(cryptozerocopy.KeccakState),
+		onBranch: func(prefix []byte, rebuilt commitment.BranchData) error {
+			report.Branches++
+			if maxDiffs > 0 && len(report.Diverged) >= maxDiffs {
+				return nil
+			}
+			stored, found, fromTxNum, endTxNum, err := ac.d[kv.CommitmentDomain].getFromFiles(prefix, toTxNum)
+			if err != nil {
+				return err
+			}
+			if found {
+				if stored, err = ac.replaceShortenedKeysInBranch(prefix, stored, fromTxNum, endTxNum); err != nil {
+					return err
+				}
+			}
+			children, err := rebuilt.DiffCells(stored)
+			if err != nil {
+				return fmt.Errorf("branch %x: %w", prefix, err)
+			}
+			if children != 0 {
+				report.Diverged = append(report.Diverged, CommitmentBranchDiff{
+					Prefix: common.Copy(prefix), Children: children, Stored: common.Copy(stored), Rebuilt: common.Copy(rebuilt),
+				})
+			}
+			return nil
+		},
+	}
+	trie.ResetContext(vc)
+	if report.RebuiltRoot, err = trie.Process(ctx, updates, fmt.Sprintf("verify step %d", step)); err != nil {
+		return nil, err
+	}
+	report.Took = time.Since(start)
+	return report, nil
+}
+
+// commitmentVerifyContext - PatriciaContext of trie built from scratch: there are no branches to load,
+// all produced branches are passed to `onBranch`, state is read from files only
+type commitmentVerifyContext struct {
+	ac        *AggregatorRoTx
+	toTxNum   uint64
+	asOfTxNum uint64
+	keccak    cryptozerocopy.KeccakState
+	onBranch  func(prefix []byte, data commitment.BranchData) error
+}
+
+func (vc *commitmentVerifyContext) read(d kv.Domain, key []byte) ([]byte, error) {
+	if vc.asOfTxNum < vc.toTxNum {
+		v, ok, err := vc.ac.d[d].ht.historySeekInFiles(key, vc.asOfTxNum)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return v, nil
+		}
+	}
+	v, _, err := vc.ac.DomainGetAsOfFile(d, key, vc.toTxNum)
+	return v, err
+}
+
+func (vc *commitmentVerifyContext) Branch(prefix []byte) ([]byte, uint64, error) { return nil, 0, nil }
+
+func (vc *commitmentVerifyContext) PutBranch(prefix []byte, data []byte, prevData []byte, prevStep uint64) error {
+	return vc.onBranch(prefix, data)
+}
+
+func (vc *commitmentVerifyContext) Account(plainKey []byte) (*commitment.Update, error) {
+	encAccount, err := vc.read(kv.AccountsDomain, plainKey)
+	if err != nil {
+		return nil, fmt.Errorf("GetAccount failed: %w", err)
+	}
+	u := new(commitment.Update)
+	u.Reset()
+	if len(encAccount) > 0 {
+		nonce, balance, chash := types.DecodeAccountBytesV3(encAccount)
+		u.Flags |= commitment.NonceUpdate | commitment.BalanceUpdate
+		u.Nonce = nonce
+		u.Balance.Set(balance)
+		if len(chash) > 0 {
+			u.Flags |= commitment.CodeUpdate
+			copy(u.CodeHash[:], chash)
+		}
+	}
+	if u.CodeHash == commitment.EmptyCodeHashArray {
+		if len(encAccount) == 0 {
+			u.Flags = commitment.DeleteUpdate
+		}
+		return u, nil
+	}
+
+	code, err := vc.read(kv.CodeDomain, plainKey)
+	if err != nil {
+		return nil, fmt.Errorf("GetAccount/Code: %w", err)
+	}
+	if len(code) > 0 {
+		vc.keccak.Reset()
+		vc.keccak.Write(code)
+		vc.keccak.Read(u.CodeHash[:])
+		u.Flags |= commitment.CodeUpdate
+	} else {
+		copy(u.CodeHash[:], commitment.EmptyCodeHashArray[:])
+	}
+	if len(encAccount) == 0 && len(code) == 0 {
+		u.Flags = commitment.DeleteUpdate
+	}
+	return u, nil
+}
+
+func (vc *commitmentVerifyContext) Storage(plainKey []byte) (*commitment.Update, error) {
+	enc, err := vc.read(kv.StorageDomain, plainKey)
+	if err != nil {
+		return nil, err
+	}
+	u := new(commitment.Update)
+	u.StorageLen = len(enc)
+	if len(enc) == 0 {
+		u.Flags = commitment.DeleteUpdate
+	} else {
+		u.Flags |= commitment.StorageUpdate
+		copy(u.Storage[:u.StorageLen], enc)
+	}
+	return u, nil
+}
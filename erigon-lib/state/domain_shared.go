@@ -102,6 +102,8 @@ type SharedDomains struct {
 
 	currentChangesAccumulator *StateChangeSet
 	pastChangesAccumulator    map[string]*StateChangeSet
+
+	latestCache *LatestCache // values read ahead from the db state of roTx, nil if none
 }
 
 type HasAggTx interface {
@@ -178,6 +180,10 @@ func (sd *SharedDomains) GetDiffset(tx kv.RwTx, blockHash common.Hash, blockNumb
 
 func (sd *SharedDomains) AggTx() any { return sd.aggTx }
 
+// SetLatestCache sets the cache of values read ahead, from the same db state
+// as the one of the tx of the domains.
+func (sd *SharedDomains) SetLatestCache(c *LatestCache) { sd.latestCache = c }
+
 // aggregator context should call aggTx.Unwind before this one.
 func (sd *SharedDomains) Unwind(ctx context.Context, rwTx kv.RwTx, blockUnwindTo, txUnwindTo uint64, changeset *[kv.DomainLen][]DomainEntryDiff) error {
 	step := txUnwindTo / sd.aggTx.a.StepSize()
@@ -458,7 +464,7 @@ func (sd *SharedDomains) LatestCommitment(prefix []byte) ([]byte, uint64, error)
 	}
 
 	// replace shortened keys in the branch with full keys to allow HPH work seamlessly
-	rv, err := sd.replaceShortenedKeysInBranch(prefix, commitment.BranchData(v), startTx, endTx)
+	rv, err := sd.aggTx.replaceShortenedKeysInBranch(prefix, commitment.BranchData(v), startTx, endTx)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -466,43 +472,43 @@ func (sd *SharedDomains) LatestCommitment(prefix []byte) ([]byte, uint64, error)
 }
 
 // replaceShortenedKeysInBranch replaces shortened keys in the branch with full keys
-func (sd *SharedDomains) replaceShortenedKeysInBranch(prefix []byte, branch commitment.BranchData, fStartTxNum uint64, fEndTxNum uint64) (commitment.BranchData, error) {
-	if !sd.aggTx.d[kv.CommitmentDomain].d.replaceKeysInValues && sd.aggTx.a.commitmentValuesTransform {
+func (ac *AggregatorRoTx) replaceShortenedKeysInBranch(prefix []byte, branch commitment.BranchData, fStartTxNum uint64, fEndTxNum uint64) (commitment.BranchData, error) {
+	if !ac.d[kv.CommitmentDomain].d.replaceKeysInValues && ac.a.commitmentValuesTransform {
 		panic("domain.replaceKeysInValues is disabled, but agg.commitmentValuesTransform is enabled")
 	}
 
-	if !sd.aggTx.a.commitmentValuesTransform ||
+	if !ac.a.commitmentValuesTransform ||
 		len(branch) == 0 ||
-		sd.aggTx.minimaxTxNumInDomainFiles() == 0 ||
-		bytes.Equal(prefix, keyCommitmentState) || ((fEndTxNum-fStartTxNum)/sd.aggTx.a.StepSize())%2 != 0 {
+		ac.minimaxTxNumInDomainFiles() == 0 ||
+		bytes.Equal(prefix, keyCommitmentState) || ((fEndTxNum-fStartTxNum)/ac.a.StepSize())%2 != 0 {
 
 		return branch, nil // do not transform, return as is
 	}
 
-	sto := sd.aggTx.d[kv.StorageDomain]
-	acc := sd.aggTx.d[kv.AccountsDomain]
+	sto := ac.d[kv.StorageDomain]
+	acc := ac.d[kv.AccountsDomain]
 	storageItem := sto.lookupVisibleFileByItsRange(fStartTxNum, fEndTxNum)
 	if storageItem == nil {
-		sd.logger.Warn(fmt.Sprintf("visible storage file of steps %d-%d not found\n", fStartTxNum/sd.aggTx.a.aggregationStep, fEndTxNum/sd.aggTx.a.aggregationStep))
+		ac.a.logger.Warn(fmt.Sprintf("visible storage file of steps %d-%d not found\n", fStartTxNum/ac.a.aggregationStep, fEndTxNum/ac.a.aggregationStep))
 		storageItem = sto.lookupDirtyFileByItsRange(fStartTxNum, fEndTxNum)
 		if storageItem == nil {
-			sd.logger.Crit(fmt.Sprintf("dirty storage file of steps %d-%d not found\n", fStartTxNum/sd.aggTx.a.aggregationStep, fEndTxNum/sd.aggTx.a.aggregationStep))
+			ac.a.logger.Crit(fmt.Sprintf("dirty storage file of steps %d-%d not found\n", fStartTxNum/ac.a.aggregationStep, fEndTxNum/ac.a.aggregationStep))
 			return nil, errors.New("storage file not found")
 		}
 	}
 	accountItem := acc.lookupVisibleFileByItsRange(fStartTxNum, fEndTxNum)
 	if accountItem == nil {
-		sd.logger.Warn(fmt.Sprintf("visible account file of steps %d-%d not found\n", fStartTxNum/sd.aggTx.a.aggregationStep, fEndTxNum/sd.aggTx.a.aggregationStep))
+		ac.a.logger.Warn(fmt.Sprintf("visible account file of steps %d-%d not found\n", fStartTxNum/ac.a.aggregationStep, fEndTxNum/ac.a.aggregationStep))
 		accountItem = acc.lookupDirtyFileByItsRange(fStartTxNum, fEndTxNum)
 		if accountItem == nil {
-			sd.logger.Crit(fmt.Sprintf("dirty account file of steps %d-%d not found\n", fStartTxNum/sd.aggTx.a.aggregationStep, fEndTxNum/sd.aggTx.a.aggregationStep))
+			ac.a.logger.Crit(fmt.Sprintf("dirty account file of steps %d-%d not found\n", fStartTxNum/ac.a.aggregationStep, fEndTxNum/ac.a.aggregationStep))
 			return nil, errors.New("account file not found")
 		}
 	}
 	storageGetter := seg.NewReader(storageItem.decompressor.MakeGetter(), sto.d.compression)
 	accountGetter := seg.NewReader(accountItem.decompressor.MakeGetter(), acc.d.compression)
 	metricI := 0
-	for i, f := range sd.aggTx.d[kv.CommitmentDomain].files {
+	for i, f := range ac.d[kv.CommitmentDomain].files {
 		if i > 5 {
 			metricI = 5
 			break
@@ -524,8 +530,8 @@ func (sd *SharedDomains) replaceShortenedKeysInBranch(prefix []byte, branch comm
 			// Optimised key referencing a state file record (file number and offset within the file)
 			storagePlainKey, found := sto.lookupByShortenedKey(key, storageGetter)
 			if !found {
-				s0, s1 := fStartTxNum/sd.aggTx.a.StepSize(), fEndTxNum/sd.aggTx.a.StepSize()
-				sd.logger.Crit("replace back lost storage full key", "shortened", fmt.Sprintf("%x", key),
+				s0, s1 := fStartTxNum/ac.a.StepSize(), fEndTxNum/ac.a.StepSize()
+				ac.a.logger.Crit("replace back lost storage full key", "shortened", fmt.Sprintf("%x", key),
 					"decoded", fmt.Sprintf("step %d-%d; offt %d", s0, s1, decodeShorterKey(key)))
 				return nil, fmt.Errorf("replace back lost storage full key: %x", key)
 			}
@@ -541,8 +547,8 @@ func (sd *SharedDomains) replaceShortenedKeysInBranch(prefix []byte, branch comm
 		}
 		apkBuf, found := acc.lookupByShortenedKey(key, accountGetter)
 		if !found {
-			s0, s1 := fStartTxNum/sd.aggTx.a.StepSize(), fEndTxNum/sd.aggTx.a.StepSize()
-			sd.logger.Crit("replace back lost account full key", "shortened", fmt.Sprintf("%x", key),
+			s0, s1 := fStartTxNum/ac.a.StepSize(), fEndTxNum/ac.a.StepSize()
+			ac.a.logger.Crit("replace back lost account full key", "shortened", fmt.Sprintf("%x", key),
 				"decoded", fmt.Sprintf("step %d-%d; offt %d", s0, s1, decodeShorterKey(key)))
 			return nil, fmt.Errorf("replace back lost account full key: %x", key)
 		}
@@ -965,6 +971,11 @@ func (sd *SharedDomains) DomainGet(domain kv.Domain, k, k2 []byte) (v []byte, st
 	if v, prevStep, ok := sd.get(domain, k); ok {
 		return v, prevStep, nil
 	}
+	if sd.latestCache != nil {
+		if v, prevStep, ok := sd.latestCache.Get(domain, k); ok {
+			return v, prevStep, nil
+		}
+	}
 	v, step, _, err = sd.aggTx.GetLatest(domain, k, nil, sd.roTx)
 	if err != nil {
 		return nil, 0, fmt.Errorf("storage %x read error: %w", k, err)
@@ -272,6 +272,8 @@ type Sync struct {
 	BreakAfterStage            string
 	LoopBlockLimit             uint
 	ParallelStateFlushing      bool
+	ExecPrefetch               bool // speculatively execute upcoming blocks to warm up the state they read
+	ExecBlockSTM               bool // execute the transactions of each block in parallel, with conflict detection
 
 	UploadLocation   string
 	UploadFrom       rpc.BlockNumber
@@ -0,0 +1,335 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/holiman/uint256"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon/consensus/misc"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/rpc"
+)
+
+var (
+	ErrInvalidProbability = errors.New("invalid inclusion probability")
+	ErrInvalidBlockCount  = errors.New("invalid block count")
+)
+
+const (
+	// feeEstimateHistory is the number of recent blocks sampled for a fee
+	// estimate.
+	feeEstimateHistory = 64
+	// maxFeeEstimateBlocks is the maximum number of blocks a fee estimate can
+	// be requested for.
+	maxFeeEstimateBlocks = 32
+	// maxFeeGrowth bounds the growth of the base fees from a block to the next,
+	// assumed when the sampled blocks are too few to model it.
+	maxFeeGrowth = 1.125
+)
+
+// FeeSamples are the fees of the recent blocks, which fee estimates model the
+// inclusion of transactions from. They are cached for the head they are
+// sampled at.
+type FeeSamples struct {
+	BlockNumber  uint64     // of the head
+	GasLimit     uint64     // of the head
+	MinTips      []*big.Int // lowest effective tip of each block, nil for the empty blocks
+	BaseFees     []*big.Int // of each block and of the next one
+	BlobBaseFees []*big.Int // of each block since Cancun and of the next one
+}
+
+// PendingFunc returns the best pending transactions of the txpool, in the
+// order it would include them, until their gas exceeds the given gas.
+type PendingFunc func(ctx context.Context, gas uint64) ([]types.Transaction, error)
+
+// FeeEstimate are the fees for a transaction to be included within a number
+// of blocks with a given probability.
+type FeeEstimate struct {
+	BlockNumber          uint64  // head the estimate is made at
+	Blocks               int     // number of blocks the transaction is to be included within
+	Probability          float64 // modelled probability of the tip being high enough
+	BaseFee              *big.Int
+	MaxPriorityFeePerGas *big.Int
+	MaxFeePerGas         *big.Int
+	PendingGas           uint64   // gas of the pending transactions outbidding the tip
+	BlobBaseFee          *big.Int // nil before Cancun
+	MaxFeePerBlobGas     *big.Int // nil before Cancun
+}
+
+// FeeEstimate returns the fees for a transaction to be included within the
+// given number of blocks with the given probability. The pending transactions
+// are sampled once per head, as many as the most blocks an estimate can be
+// requested for can fit.
+//
+// A block includes a transaction if its tip is at least the lowest tip of the
+// block, and never if the block is empty: assuming the blocks are independent,
+// the tip is the lowest one which enough of the recent blocks include for the
+// probability of inclusion within the blocks. It is raised to outbid the
+// pending transactions, if they are more than the blocks can fit. The maximal
+// fees cover the growth of the base fees over the blocks for the probability,
+// as modelled from the recent blocks.
+func (oracle *Oracle) FeeEstimate(ctx context.Context, blocks int, probability float64, pendingFn PendingFunc) (*FeeEstimate, error) {
+	if blocks < 1 || blocks > maxFeeEstimateBlocks {
+		return nil, fmt.Errorf("%w: %d, expected 1 to %d", ErrInvalidBlockCount, blocks, maxFeeEstimateBlocks)
+	}
+	if !(probability > 0 && probability < 1) {
+		return nil, fmt.Errorf("%w: %f, expected between 0 and 1", ErrInvalidProbability, probability)
+	}
+	head, err := oracle.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if head == nil {
+		return nil, errors.New("head header not found")
+	}
+	samples, err := oracle.feeSamples(ctx, head)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := oracle.pending(ctx, head, pendingFn)
+	if err != nil {
+		return nil, err
+	}
+
+	tips := make([]*big.Int, 0, len(samples.MinTips))
+	for _, tip := range samples.MinTips {
+		if tip != nil {
+			tips = append(tips, tip)
+		}
+	}
+	sort.Slice(tips, func(i, j int) bool { return tips[i].Cmp(tips[j]) < 0 })
+
+	tip := new(big.Int)
+	if len(tips) > 0 {
+		// the probability of a single block including the transaction
+		blockProbability := 1 - math.Pow(1-probability, 1/float64(blocks))
+		tip.Set(tips[quantileIndex(len(samples.MinTips), blockProbability, len(tips))])
+	} else if oracle.lastPrice != nil {
+		tip.Set(oracle.lastPrice)
+	}
+	baseFee := samples.BaseFees[len(samples.BaseFees)-1]
+	baseFee256, overflow := uint256.FromBig(baseFee)
+	if overflow {
+		return nil, errors.New("overflow in FeeEstimate: baseFee > 2^256-1")
+	}
+	if competition := pendingTip(pending, baseFee256, uint64(blocks)*samples.GasLimit); competition != nil && competition.Cmp(tip) > 0 {
+		tip.Set(competition)
+	}
+	if tip.Cmp(oracle.maxPrice) > 0 {
+		tip.Set(oracle.maxPrice)
+	}
+
+	estimate := &FeeEstimate{
+		BlockNumber:          samples.BlockNumber,
+		Blocks:               blocks,
+		BaseFee:              baseFee,
+		MaxPriorityFeePerGas: tip,
+		MaxFeePerGas:         new(big.Int).Add(growFee(baseFee, feeGrowth(samples.BaseFees, blocks, probability)), tip),
+	}
+	if len(samples.MinTips) > 0 {
+		included := sort.Search(len(tips), func(i int) bool { return tips[i].Cmp(tip) > 0 })
+		estimate.Probability = 1 - math.Pow(1-float64(included)/float64(len(samples.MinTips)), float64(blocks))
+	}
+	tip256, _ := uint256.FromBig(tip)
+	for _, txn := range pending {
+		if !txn.GetEffectiveGasTip(baseFee256).Lt(tip256) {
+			estimate.PendingGas += txn.GetGas()
+		}
+	}
+	if len(samples.BlobBaseFees) > 0 {
+		estimate.BlobBaseFee = samples.BlobBaseFees[len(samples.BlobBaseFees)-1]
+		estimate.MaxFeePerBlobGas = growFee(estimate.BlobBaseFee, feeGrowth(samples.BlobBaseFees, blocks, probability))
+	}
+	return estimate, nil
+}
+
+// feeSamples returns the fee samples of the recent blocks up to the head,
+// sampling them unless they are cached.
+func (oracle *Oracle) feeSamples(ctx context.Context, head *types.Header) (*FeeSamples, error) {
+	headHash := head.Hash()
+	if samples := oracle.cache.GetFeeSamples(headHash); samples != nil {
+		return samples, nil
+	}
+	ignoreUnder, overflow := uint256.FromBig(oracle.ignorePrice)
+	if overflow {
+		return nil, errors.New("overflow in feeSamples: ignoreUnder too large")
+	}
+
+	history := uint64(feeEstimateHistory)
+	if oracle.maxBlockHistory > 0 && uint64(oracle.maxBlockHistory) < history {
+		history = uint64(oracle.maxBlockHistory)
+	}
+	headNumber := head.Number.Uint64()
+	history = min(history, headNumber) // the genesis is not sampled
+	samples := &FeeSamples{BlockNumber: headNumber, GasLimit: head.GasLimit}
+	chainconfig := oracle.backend.ChainConfig()
+	for number := headNumber + 1 - history; number <= headNumber; number++ {
+		if err := libcommon.Stopped(ctx.Done()); err != nil {
+			return nil, err
+		}
+		block, err := oracle.backend.BlockByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			return nil, fmt.Errorf("block %d not found", number)
+		}
+		baseFee := block.BaseFee()
+		if baseFee == nil {
+			baseFee = new(big.Int)
+		}
+		samples.MinTips = append(samples.MinTips, minTip(block, ignoreUnder))
+		samples.BaseFees = append(samples.BaseFees, baseFee)
+		if excessBlobGas := block.HeaderNoCopy().ExcessBlobGas; excessBlobGas != nil {
+			blobBaseFee, err := misc.GetBlobGasPrice(chainconfig, *excessBlobGas)
+			if err != nil {
+				return nil, err
+			}
+			samples.BlobBaseFees = append(samples.BlobBaseFees, blobBaseFee.ToBig())
+		}
+	}
+	if chainconfig.IsLondon(headNumber + 1) {
+		samples.BaseFees = append(samples.BaseFees, misc.CalcBaseFee(chainconfig, head))
+	} else {
+		samples.BaseFees = append(samples.BaseFees, new(big.Int))
+	}
+	if head.ExcessBlobGas != nil {
+		nextBlobBaseFee, err := misc.GetBlobGasPrice(chainconfig, misc.CalcExcessBlobGas(chainconfig, head))
+		if err != nil {
+			return nil, err
+		}
+		samples.BlobBaseFees = append(samples.BlobBaseFees, nextBlobBaseFee.ToBig())
+	}
+
+	oracle.cache.SetFeeSamples(headHash, samples)
+	return samples, nil
+}
+
+// pending returns the pending transactions at the head, sampling them unless
+// they are cached.
+func (oracle *Oracle) pending(ctx context.Context, head *types.Header, pendingFn PendingFunc) ([]types.Transaction, error) {
+	if pendingFn == nil {
+		return nil, nil
+	}
+	headHash := head.Hash()
+	if pending, ok := oracle.cache.GetPending(headHash); ok {
+		return pending, nil
+	}
+	pending, err := pendingFn(ctx, maxFeeEstimateBlocks*head.GasLimit)
+	if err != nil {
+		return nil, err
+	}
+	oracle.cache.SetPending(headHash, pending)
+	return pending, nil
+}
+
+// minTip returns the lowest effective tip of the transactions of a block, but
+// for those sent by the miner itself or tipping under ignoreUnder: the block
+// then includes the transactions tipping at least ignoreUnder. It returns nil
+// if the block is empty.
+func minTip(block *types.Block, ignoreUnder *uint256.Int) *big.Int {
+	txs := block.Transactions()
+	if len(txs) == 0 {
+		return nil
+	}
+	var baseFee *uint256.Int
+	if block.BaseFee() != nil {
+		baseFee, _ = uint256.FromBig(block.BaseFee())
+	}
+	var lowest *uint256.Int
+	for _, txn := range txs {
+		tip := txn.GetEffectiveGasTip(baseFee)
+		if tip.Lt(ignoreUnder) {
+			continue
+		}
+		if sender, ok := txn.GetSender(); ok && sender == block.Coinbase() {
+			continue
+		}
+		if lowest == nil || tip.Lt(lowest) {
+			lowest = tip
+		}
+	}
+	if lowest == nil {
+		return ignoreUnder.ToBig()
+	}
+	return lowest.ToBig()
+}
+
+// pendingTip returns the tip outbidding enough pending transactions for the
+// rest to fit in the given gas, or nil if they all fit.
+func pendingTip(pending []types.Transaction, baseFee *uint256.Int, gas uint64) *big.Int {
+	tips := make([]*uint256.Int, len(pending))
+	for i, txn := range pending {
+		tips[i] = txn.GetEffectiveGasTip(baseFee)
+	}
+	order := make([]int, len(pending))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return tips[order[j]].Lt(tips[order[i]]) })
+
+	var total uint64
+	for _, i := range order {
+		if total += pending[i].GetGas(); total > gas {
+			return tips[i].ToBig()
+		}
+	}
+	return nil
+}
+
+// quantileIndex returns the index in the ascending values of the q quantile
+// of a distribution of n values, the missing ones being larger than any of the
+// values. It is capped at the last value.
+func quantileIndex(n int, q float64, values int) int {
+	i := int(math.Ceil(q*float64(n))) - 1
+	return max(0, min(i, values-1))
+}
+
+// feeGrowth returns the q quantile of the growth of the fees over the given
+// number of blocks, at least 1, or its bound if the fees are too few.
+func feeGrowth(fees []*big.Int, blocks int, q float64) float64 {
+	growths := make([]float64, 0, len(fees))
+	for i := 0; i+blocks < len(fees); i++ {
+		if fees[i].Sign() == 0 {
+			continue
+		}
+		growth, _ := new(big.Float).Quo(new(big.Float).SetInt(fees[i+blocks]), new(big.Float).SetInt(fees[i])).Float64()
+		growths = append(growths, growth)
+	}
+	if len(growths) == 0 {
+		return math.Pow(maxFeeGrowth, float64(blocks))
+	}
+	sort.Float64s(growths)
+	return max(1, growths[quantileIndex(len(growths), q, len(growths))])
+}
+
+// growFee returns a fee grown by a factor, rounded up.
+func growFee(fee *big.Int, growth float64) *big.Int {
+	grown, accuracy := new(big.Float).Mul(new(big.Float).SetInt(fee), big.NewFloat(growth)).Int(nil)
+	if accuracy == big.Below {
+		grown.Add(grown, libcommon.Big1)
+	}
+	return grown
+}
@@ -51,6 +51,13 @@ type OracleBackend interface {
 type Cache interface {
 	GetLatest() (libcommon.Hash, *big.Int)
 	SetLatest(hash libcommon.Hash, price *big.Int)
+
+	// The fee samples and the pending transactions fee estimates are made
+	// from, for the head they are sampled at.
+	GetFeeSamples(head libcommon.Hash) *FeeSamples
+	SetFeeSamples(head libcommon.Hash, samples *FeeSamples)
+	GetPending(head libcommon.Hash) ([]types.Transaction, bool)
+	SetPending(head libcommon.Hash, pending []types.Transaction)
 }
 
 // Oracle recommends gas prices based on the content of recent
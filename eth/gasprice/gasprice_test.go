@@ -21,6 +21,7 @@ package gasprice_test
 
 import (
 	"context"
+	"errors"
 	"math"
 	"math/big"
 	"testing"
@@ -39,6 +40,7 @@ import (
 	"github.com/erigontech/erigon/core"
 	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/crypto"
+	"github.com/erigontech/erigon/eth/ethconfig"
 	"github.com/erigontech/erigon/eth/gasprice"
 	"github.com/erigontech/erigon/params"
 )
@@ -101,3 +103,97 @@ func TestSuggestPrice(t *testing.T) {
 		t.Fatalf("Gas price mismatch, want %d, got %d", expect, got)
 	}
 }
+
+func TestFeeEstimate(t *testing.T) {
+	m := newTestBackend(t)
+	baseApi := jsonrpc.NewBaseApi(nil, kvcache.NewDummy(), m.BlockReader, false, rpccfg.DefaultEvmCallTimeout, m.Engine, m.Dirs, nil)
+
+	tx, _ := m.DB.BeginRo(m.Ctx)
+	defer tx.Rollback()
+
+	cache := jsonrpc.NewGasPriceCache()
+	oracle := gasprice.NewOracle(jsonrpc.NewGasPriceOracleBackend(tx, baseApi), ethconfig.Defaults.GPO, cache, log.New())
+	header := func(number uint64) *types.Header {
+		h, err := m.BlockReader.HeaderByNumber(m.Ctx, tx, number)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+	// The tip of the only transaction of block i is i gwei, there is no base fee
+	tip := func(number uint64) *big.Int {
+		return big.NewInt(int64(number) * params.GWei)
+	}
+
+	for _, c := range []struct {
+		blocks      int
+		probability float64
+		tipBlock    uint64
+	}{
+		{1, 0.5, 16},
+		{1, 0.9, 29},
+		{4, 0.9, 15},
+		{1, 0.99, 32},
+	} {
+		estimate, err := oracle.FeeEstimate(context.Background(), c.blocks, c.probability, nil)
+		if err != nil {
+			t.Fatalf("Failed to estimate fees: %v", err)
+		}
+		if estimate.BlockNumber != 32 {
+			t.Fatalf("Head mismatch, want 32, got %d", estimate.BlockNumber)
+		}
+		if want := tip(c.tipBlock); estimate.MaxPriorityFeePerGas.Cmp(want) != 0 {
+			t.Fatalf("Tip mismatch within %d blocks at %f, want %d, got %d", c.blocks, c.probability, want, estimate.MaxPriorityFeePerGas)
+		}
+		if estimate.Probability < c.probability {
+			t.Fatalf("Probability within %d blocks at %f too low: %f", c.blocks, c.probability, estimate.Probability)
+		}
+		if want := new(big.Int).Add(estimate.BaseFee, estimate.MaxPriorityFeePerGas); estimate.MaxFeePerGas.Cmp(want) != 0 {
+			t.Fatalf("Max fee mismatch, want %d, got %d", want, estimate.MaxFeePerGas)
+		}
+	}
+
+	// The samples are cached for the head
+	if samples := cache.GetFeeSamples(header(32).Hash()); samples == nil || len(samples.MinTips) != 32 {
+		t.Fatalf("Fee samples not cached")
+	}
+
+	// Pending transactions taking a whole block outbid the tip of the next block
+	competition := &types.DynamicFeeTransaction{
+		CommonTx: types.CommonTx{Gas: header(32).GasLimit},
+		Tip:      uint256.NewInt(100 * params.GWei),
+		FeeCap:   uint256.NewInt(200 * params.GWei),
+	}
+	var sampled int
+	pending := func(_ context.Context, gas uint64) ([]types.Transaction, error) {
+		sampled++
+		// as many as the most blocks an estimate is requested for fit
+		if want := 32 * header(32).GasLimit; gas != want {
+			t.Fatalf("Pending gas requested mismatch, want %d, got %d", want, gas)
+		}
+		return []types.Transaction{competition, competition}, nil
+	}
+	// A new oracle sharing the cache samples the pending transactions once for the head
+	for _, oracle := range []*gasprice.Oracle{oracle, gasprice.NewOracle(jsonrpc.NewGasPriceOracleBackend(tx, baseApi), ethconfig.Defaults.GPO, cache, log.New())} {
+		estimate, err := oracle.FeeEstimate(context.Background(), 1, 0.5, pending)
+		if err != nil {
+			t.Fatalf("Failed to estimate fees: %v", err)
+		}
+		if want := big.NewInt(100 * params.GWei); estimate.MaxPriorityFeePerGas.Cmp(want) != 0 {
+			t.Fatalf("Tip mismatch with pending transactions, want %d, got %d", want, estimate.MaxPriorityFeePerGas)
+		}
+		if estimate.PendingGas != 2*header(32).GasLimit {
+			t.Fatalf("Pending gas mismatch, want %d, got %d", 2*header(32).GasLimit, estimate.PendingGas)
+		}
+	}
+	if sampled != 1 {
+		t.Fatalf("Pending transactions sampled %d times for the head, want 1", sampled)
+	}
+
+	if _, err := oracle.FeeEstimate(context.Background(), 1, 1, nil); !errors.Is(err, gasprice.ErrInvalidProbability) {
+		t.Fatalf("Error mismatch, want %v, got %v", gasprice.ErrInvalidProbability, err)
+	}
+	if _, err := oracle.FeeEstimate(context.Background(), 0, 0.5, nil); !errors.Is(err, gasprice.ErrInvalidBlockCount) {
+		t.Fatalf("Error mismatch, want %v, got %v", gasprice.ErrInvalidBlockCount, err)
+	}
+}
@@ -688,6 +688,31 @@ func ExecV3(ctx context.Context,
 		}
 	}
 
+	// speculative execution of the upcoming blocks, to read ahead the state they touch
+	var prefetcher *statePrefetcher
+	var prefetchReads *prefetchTracker
+	var prefetchReadsTracked bool
+	if !parallel && !isMining && cfg.syncCfg.ExecPrefetch {
+		// the values read ahead are those of the committed state, which is the
+		// one the executor reads only if it owns its tx
+		var cache *state2.LatestCache
+		if !useExternalTx && !inMemExec {
+			cache = state2.NewLatestCache()
+			doms.SetLatestCache(cache)
+		}
+		var stop func()
+		prefetcher, stop = newStatePrefetcher(ctx, &cfg, logger, cache, workerCount, blockNum)
+		defer stop()
+		prefetchReads = &prefetchTracker{ResettableStateReader: state.NewReaderV3(rs.Domains())}
+		defer prefetchReads.reset(nil)
+	}
+
+	// parallel execution of the txs of each block
+	var blockSTM *exec3.BlockSTM
+	if !parallel && !isMining && cfg.syncCfg.ExecBlockSTM {
+		blockSTM = exec3.NewBlockSTM(ctx, logger, chainConfig, engine, *cfg.vmConfig, cfg.syncCfg.ExecWorkerCount)
+	}
+
 	var b *types.Block
 
 	// Only needed by bor chains
@@ -723,6 +748,21 @@ Loop:
 		inputBlockNum.Store(blockNum)
 		doms.SetBlockNum(blockNum)
 
+		var blockExecTook metrics.Summary
+		if prefetcher != nil {
+			var prefetched *prefetchedBlock
+			if prefetched, blockExecTook, err = prefetcher.startBlock(applyTx, blockNum, maxBlockNum); err != nil {
+				return err
+			}
+			// the history reader is used at first to catch up to the tx where we left off
+			if offsetFromBlockBeginning == 0 && !prefetchReadsTracked {
+				applyWorker.SetReader(prefetchReads)
+				prefetchReadsTracked = true
+			}
+			prefetchReads.reset(prefetched)
+		}
+		blockExecStart := time.Now()
+
 		b, err = blockWithSenders(ctx, chainDb, applyTx, blockReader, blockNum)
 		if err != nil {
 			return err
@@ -792,31 +832,58 @@ Loop:
 		skipPostEvaluation := false
 		var usedGas, blobGasUsed uint64
 
+		setTxMessage := func(txTask *state.TxTask) error {
+			txTask.Tx = txs[txTask.TxIndex]
+			txTask.TxAsMessage, err = txTask.Tx.AsMessage(signer, header.BaseFee, txTask.Rules)
+			if err != nil {
+				return err
+			}
+
+			if sender, ok := txTask.Tx.GetSender(); ok {
+				txTask.Sender = &sender
+			} else {
+				sender, err := signer.Sender(txTask.Tx)
+				if err != nil {
+					return err
+				}
+				txTask.Sender = &sender
+				logger.Warn("[Execution] expensive lazy sender recovery", "blockNum", txTask.BlockNum, "txIdx", txTask.TxIndex)
+			}
+			return nil
+		}
+		var stmTasks []*state.TxTask // txs of the block executed by BlockSTM
+		var stmWriter *state.StateWriterV3
+
 		for txIndex := -1; txIndex <= len(txs); txIndex++ {
 			// Do not oversend, wait for the result heap to go under certain size
-			txTask := &state.TxTask{
-				BlockNum:           blockNum,
-				Header:             header,
-				Coinbase:           b.Coinbase(),
-				Uncles:             b.Uncles(),
-				Rules:              rules,
-				Txs:                txs,
-				TxNum:              inputTxNum,
-				TxIndex:            txIndex,
-				BlockHash:          b.Hash(),
-				SkipAnalysis:       skipAnalysis,
-				Final:              txIndex == len(txs),
-				GetHashFn:          getHashFn,
-				EvmBlockContext:    blockContext,
-				Withdrawals:        b.Withdrawals(),
-				PruneNonEssentials: pruneNonEssentials,
-
-				// use history reader instead of state reader to catch up to the tx where we left off
-				HistoryExecution: offsetFromBlockBeginning > 0 && txIndex < int(offsetFromBlockBeginning),
-
-				BlockReceipts: blockReceipts,
-
-				Config: chainConfig,
+			var txTask *state.TxTask
+			if txIndex >= 0 && txIndex < len(stmTasks) {
+				txTask = stmTasks[txIndex]
+			} else {
+				txTask = &state.TxTask{
+					BlockNum:           blockNum,
+					Header:             header,
+					Coinbase:           b.Coinbase(),
+					Uncles:             b.Uncles(),
+					Rules:              rules,
+					Txs:                txs,
+					TxNum:              inputTxNum,
+					TxIndex:            txIndex,
+					BlockHash:          b.Hash(),
+					SkipAnalysis:       skipAnalysis,
+					Final:              txIndex == len(txs),
+					GetHashFn:          getHashFn,
+					EvmBlockContext:    blockContext,
+					Withdrawals:        b.Withdrawals(),
+					PruneNonEssentials: pruneNonEssentials,
+
+					// use history reader instead of state reader to catch up to the tx where we left off
+					HistoryExecution: offsetFromBlockBeginning > 0 && txIndex < int(offsetFromBlockBeginning),
+
+					BlockReceipts: blockReceipts,
+
+					Config: chainConfig,
+				}
 			}
 			if txTask.HistoryExecution && usedGas == 0 {
 				usedGas, blobGasUsed, _, err = rawtemporaldb.ReceiptAsOf(applyTx.(kv.TemporalTx), txTask.TxNum)
@@ -837,23 +904,10 @@ Loop:
 			doms.SetTxNum(txTask.TxNum)
 			doms.SetBlockNum(txTask.BlockNum)
 
-			if txIndex >= 0 && txIndex < len(txs) {
-				txTask.Tx = txs[txIndex]
-				txTask.TxAsMessage, err = txTask.Tx.AsMessage(signer, header.BaseFee, txTask.Rules)
-				if err != nil {
+			if txIndex >= 0 && txIndex < len(txs) && stmTasks == nil {
+				if err := setTxMessage(txTask); err != nil {
 					return err
 				}
-
-				if sender, ok := txs[txIndex].GetSender(); ok {
-					txTask.Sender = &sender
-				} else {
-					sender, err := signer.Sender(txTask.Tx)
-					if err != nil {
-						return err
-					}
-					txTask.Sender = &sender
-					logger.Warn("[Execution] expensive lazy sender recovery", "blockNum", txTask.BlockNum, "txIdx", txTask.TxIndex)
-				}
 			}
 
 			if parallel {
@@ -870,10 +924,34 @@ Loop:
 			}
 
 			count++
-			if txTask.Error != nil {
-				break Loop
+			// the txs of a block executed from its beginning are executed in parallel, once it is initialised
+			if blockSTM != nil && txIndex == 0 && len(txs) > 1 && offsetFromBlockBeginning == 0 && !skipPostEvaluation {
+				stmTasks = make([]*state.TxTask, len(txs))
+				stmTasks[0] = txTask
+				for i := 1; i < len(txs); i++ {
+					stmTask := *txTask
+					stmTask.TxNum, stmTask.TxIndex = txTask.TxNum+uint64(i), i
+					if err := setTxMessage(&stmTask); err != nil {
+						return err
+					}
+					stmTasks[i] = &stmTask
+				}
+				if err := blockSTM.Execute(ctx, stmTasks, state.NewReaderV3(rs.Domains())); err != nil {
+					return err
+				}
+				stmWriter = state.NewStateWriterV3(rs, accumulator)
+			}
+			if stmTasks != nil && !txTask.Final {
+				// executed by BlockSTM, with its writes left to apply
+				if txTask.Error == nil {
+					txTask.Error = txTask.VersionedWrites.Apply(stmWriter)
+				}
+			} else {
+				if txTask.Error != nil {
+					break Loop
+				}
+				applyWorker.RunTxTaskNoLock(txTask, isMining)
 			}
-			applyWorker.RunTxTaskNoLock(txTask, isMining)
 			if err := func() error {
 				if errors.Is(txTask.Error, context.Canceled) {
 					return err
@@ -954,6 +1032,9 @@ Loop:
 			inputTxNum++
 		}
 		mxExecBlocks.Add(1)
+		if blockExecTook != nil {
+			blockExecTook.ObserveDuration(blockExecStart)
+		}
 
 		if shouldGenerateChangesets {
 			aggTx := applyTx.(state2.HasAggTx).AggTx().(*state2.AggregatorRoTx)
@@ -1067,6 +1148,14 @@ Loop:
 
 					applyWorker.ResetTx(applyTx)
 					applyWorker.ResetState(rs, accumulator)
+					if prefetchReads != nil {
+						prefetchReads.ResettableStateReader = state.NewReaderV3(rs.Domains())
+						prefetchReadsTracked = false
+					}
+					if prefetcher != nil && prefetcher.cache != nil {
+						prefetcher.cache.Reset()
+						doms.SetLatestCache(prefetcher.cache)
+					}
 
 					return nil
 				}(); err != nil {
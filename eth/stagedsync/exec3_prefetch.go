@@ -0,0 +1,389 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package stagedsync
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/metrics"
+	state2 "github.com/erigontech/erigon-lib/state"
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/types/accounts"
+	"github.com/erigontech/erigon/core/vm"
+)
+
+var (
+	mxExecPrefetchReady   = metrics.GetOrCreateCounter(`exec_prefetch_blocks{result="ready"}`)
+	mxExecPrefetchPending = metrics.GetOrCreateCounter(`exec_prefetch_blocks{result="pending"}`)
+	mxExecPrefetchMissed  = metrics.GetOrCreateCounter(`exec_prefetch_blocks{result="missed"}`)
+	mxExecPrefetchKeys    = metrics.GetOrCreateCounter(`exec_prefetch_keys`)
+	mxExecPrefetchHits    = metrics.GetOrCreateCounter(`exec_prefetch_reads{result="hit"}`)
+	mxExecPrefetchMisses  = metrics.GetOrCreateCounter(`exec_prefetch_reads{result="miss"}`)
+
+	// Execution time of the blocks, by how far their prefetch was when their execution started.
+	mxExecBlockPrefetchReady   = metrics.GetOrCreateSummary(`exec_block_seconds{prefetch="ready"}`)
+	mxExecBlockPrefetchPending = metrics.GetOrCreateSummary(`exec_block_seconds{prefetch="pending"}`)
+	mxExecBlockPrefetchMissed  = metrics.GetOrCreateSummary(`exec_block_seconds{prefetch="missed"}`)
+)
+
+const (
+	prefetchBlocksAhead = 4
+	prefetchQueueSize   = 4_096 // transactions
+)
+
+// statePrefetcher warms up the state read by the execution of the upcoming
+// blocks. Their transactions are run speculatively by a pool of workers, each
+// independently of the others and against the latest committed state, in
+// read-only transactions of their own: the accounts, storage and code they read
+// are loaded from the domain files ahead of the executor, which finds them in
+// the page cache, and in its domains cache if the prefetcher has one. The
+// declared access lists of transactions are read as well.
+//
+// The keys read are remembered by block, to tell the hit rate of the executor.
+type statePrefetcher struct {
+	ctx    context.Context
+	cfg    *ExecuteBlockCfg
+	logger log.Logger
+	cache  *state2.LatestCache // nil if the executor does not start from the committed state
+	tasks  chan prefetchTask
+	next   uint64 // next block to schedule
+
+	lock   sync.Mutex
+	blocks map[uint64]*prefetchedBlock
+}
+
+// prefetchedBlock is a block scheduled for prefetch.
+type prefetchedBlock struct {
+	block   *types.Block
+	pending atomic.Int32 // transactions left to prefetch
+
+	lock sync.RWMutex
+	keys map[string]struct{} // domain byte followed by the key
+}
+
+func (b *prefetchedBlock) has(key []byte) bool {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	_, ok := b.keys[string(key)]
+	return ok
+}
+
+func (b *prefetchedBlock) add(keys map[string]struct{}) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for k := range keys {
+		b.keys[k] = struct{}{}
+	}
+}
+
+type prefetchTask struct {
+	block   *prefetchedBlock
+	txIndex int
+}
+
+// newStatePrefetcher starts the prefetch workers, the blocks being scheduled
+// from the given one on. The values read are added to the cache, if any, which
+// must be reset whenever the committed state changes. The returned function
+// stops the workers.
+func newStatePrefetcher(ctx context.Context, cfg *ExecuteBlockCfg, logger log.Logger, cache *state2.LatestCache, workers int, from uint64) (*statePrefetcher, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	p := &statePrefetcher{
+		ctx:    ctx,
+		cfg:    cfg,
+		logger: logger,
+		cache:  cache,
+		tasks:  make(chan prefetchTask, prefetchQueueSize),
+		next:   from,
+		blocks: make(map[uint64]*prefetchedBlock),
+	}
+	g, gCtx := errgroup.WithContext(ctx)
+	for i := 0; i < workers; i++ {
+		g.Go(func() error { return p.work(gCtx) })
+	}
+	return p, func() {
+		cancel()
+		close(p.tasks)
+		_ = g.Wait()
+	}
+}
+
+// schedule queues the blocks up to the given one for prefetch, reading them
+// with tx as they may not be committed yet. It does not block: the blocks
+// which do not fit in the queue are scheduled by a later call.
+func (p *statePrefetcher) schedule(tx kv.Tx, upTo uint64) error {
+	for ; p.next <= upTo; p.next++ {
+		b, err := p.cfg.blockReader.BlockByNumber(p.ctx, tx, p.next)
+		if err != nil {
+			return err
+		}
+		if b == nil {
+			return nil
+		}
+		txs := min(len(b.Transactions()), cap(p.tasks))
+		if len(p.tasks)+txs > cap(p.tasks) {
+			return nil
+		}
+		pb := &prefetchedBlock{block: b, keys: make(map[string]struct{})}
+		pb.pending.Store(int32(txs))
+		p.lock.Lock()
+		p.blocks[p.next] = pb
+		p.lock.Unlock()
+		for i := 0; i < txs; i++ {
+			p.tasks <- prefetchTask{block: pb, txIndex: i}
+		}
+	}
+	return nil
+}
+
+// take returns the prefetch of the given block, nil if it was not scheduled,
+// and forgets the blocks up to it.
+func (p *statePrefetcher) take(blockNum uint64) *prefetchedBlock {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	b := p.blocks[blockNum]
+	for n := range p.blocks {
+		if n <= blockNum {
+			delete(p.blocks, n)
+		}
+	}
+	return b
+}
+
+func (p *statePrefetcher) work(ctx context.Context) (err error) {
+	var tx kv.Tx
+	var epoch uint64
+	defer func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}()
+	for i := 0; ; i++ {
+		var task prefetchTask
+		select {
+		case t, ok := <-p.tasks:
+			if !ok {
+				return nil
+			}
+			task = t
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		// Renew the transaction from time to time, to see the latest state and
+		// not to hold on to old files, and as soon as the cache is reset, for
+		// its values to be read from the state it was reset to.
+		if i%100 == 0 || (p.cache != nil && p.cache.Epoch() != epoch) {
+			if tx != nil {
+				tx.Rollback()
+			}
+			if p.cache != nil {
+				epoch = p.cache.Epoch()
+			}
+			if tx, err = p.cfg.db.BeginRo(ctx); err != nil {
+				return err
+			}
+		}
+		p.prefetch(ctx, tx, epoch, task)
+		task.block.pending.Add(-1)
+	}
+}
+
+// prefetch runs a transaction speculatively, its errors being of no interest.
+func (p *statePrefetcher) prefetch(ctx context.Context, tx kv.Tx, epoch uint64, task prefetchTask) {
+	var (
+		chainConfig = p.cfg.chainConfig
+		header      = task.block.block.HeaderNoCopy()
+		txn         = task.block.block.Transactions()[task.txIndex]
+		rules       = chainConfig.Rules(header.Number.Uint64(), header.Time)
+		signer      = types.MakeSigner(chainConfig, header.Number.Uint64(), header.Time)
+	)
+	msg, err := txn.AsMessage(*signer, header.BaseFee, rules)
+	if err != nil {
+		return
+	}
+	// The state may be behind the block, which must not stop the execution.
+	msg.SetCheckNonce(false)
+
+	getter := &prefetchGetter{TemporalGetter: tx.(kv.TemporalTx), cache: p.cache, epoch: epoch}
+	reader := &prefetchRecorder{StateReader: state.NewReaderV3(getter), keys: make(map[string]struct{})}
+	for _, tuple := range txn.GetAccessList() {
+		_, _ = reader.ReadAccountData(tuple.Address)
+		for i := range tuple.StorageKeys {
+			_, _ = reader.ReadAccountStorage(tuple.Address, 0, &tuple.StorageKeys[i])
+		}
+	}
+
+	getHeader := func(hash common.Hash, number uint64) *types.Header {
+		h, _ := p.cfg.blockReader.Header(ctx, tx, hash, number)
+		return h
+	}
+	blockContext := core.NewEVMBlockContext(header, core.GetHashFn(header, getHeader), p.cfg.engine, p.cfg.author, chainConfig)
+	evm := vm.NewEVM(blockContext, core.NewEVMTxContext(msg), state.New(reader), chainConfig, vm.Config{})
+	gp := new(core.GasPool).AddGas(msg.Gas()).AddBlobGas(msg.BlobGas())
+	func() {
+		// The state being behind the block may lead the execution astray, up
+		// to a panic: the keys read until then are kept all the same.
+		defer func() {
+			if rec := recover(); rec != nil {
+				p.logger.Debug("[prefetch] speculative execution panicked", "block", header.Number.Uint64(), "txIndex", task.txIndex, "err", rec)
+			}
+		}()
+		_, _ = core.ApplyMessage(evm, msg, gp, true /* refunds */, true /* gasBailout */)
+	}()
+
+	task.block.add(reader.keys)
+	mxExecPrefetchKeys.AddInt(len(reader.keys))
+}
+
+// prefetchKey appends the key of an account, its code or one of its storage
+// slots, prefixed by its domain, to buf.
+func prefetchKey(buf []byte, domain kv.Domain, address common.Address, slot *common.Hash) []byte {
+	buf = append(append(buf[:0], byte(domain)), address[:]...)
+	if slot != nil {
+		buf = append(buf, slot[:]...)
+	}
+	return buf
+}
+
+// prefetchGetter reads the latest state, adding the values it reads to the
+// cache of the executor, if any.
+type prefetchGetter struct {
+	kv.TemporalGetter
+	cache *state2.LatestCache
+	epoch uint64 // of the cache when the state was read
+	key   []byte
+}
+
+func (g *prefetchGetter) DomainGet(name kv.Domain, k, k2 []byte) ([]byte, uint64, error) {
+	v, step, err := g.TemporalGetter.DomainGet(name, k, k2)
+	if err != nil || g.cache == nil {
+		return v, step, err
+	}
+	g.key = append(append(g.key[:0], k...), k2...)
+	g.cache.Put(g.epoch, name, g.key, v, step)
+	return v, step, nil
+}
+
+// prefetchRecorder is a state reader remembering the keys it read.
+type prefetchRecorder struct {
+	state.StateReader
+	keys map[string]struct{}
+	buf  []byte
+}
+
+func (r *prefetchRecorder) record(domain kv.Domain, address common.Address, slot *common.Hash) {
+	r.buf = prefetchKey(r.buf, domain, address, slot)
+	r.keys[string(r.buf)] = struct{}{}
+}
+
+func (r *prefetchRecorder) ReadAccountData(address common.Address) (*accounts.Account, error) {
+	r.record(kv.AccountsDomain, address, nil)
+	return r.StateReader.ReadAccountData(address)
+}
+
+func (r *prefetchRecorder) ReadAccountStorage(address common.Address, incarnation uint64, key *common.Hash) ([]byte, error) {
+	r.record(kv.StorageDomain, address, key)
+	return r.StateReader.ReadAccountStorage(address, incarnation, key)
+}
+
+func (r *prefetchRecorder) ReadAccountCode(address common.Address, incarnation uint64, codeHash common.Hash) ([]byte, error) {
+	r.record(kv.CodeDomain, address, nil)
+	return r.StateReader.ReadAccountCode(address, incarnation, codeHash)
+}
+
+func (r *prefetchRecorder) ReadAccountCodeSize(address common.Address, incarnation uint64, codeHash common.Hash) (int, error) {
+	r.record(kv.CodeDomain, address, nil)
+	return r.StateReader.ReadAccountCodeSize(address, incarnation, codeHash)
+}
+
+// prefetchTracker is the state reader of the executor, counting its reads of
+// keys prefetched for the block being executed.
+type prefetchTracker struct {
+	state.ResettableStateReader
+	block        *prefetchedBlock // nil if the block was not prefetched
+	buf          []byte
+	hits, misses int
+}
+
+// reset starts tracking the reads of the next block, after reporting those
+// of the previous one.
+func (r *prefetchTracker) reset(block *prefetchedBlock) {
+	mxExecPrefetchHits.AddInt(r.hits)
+	mxExecPrefetchMisses.AddInt(r.misses)
+	r.block, r.hits, r.misses = block, 0, 0
+}
+
+func (r *prefetchTracker) track(domain kv.Domain, address common.Address, slot *common.Hash) {
+	if r.block == nil {
+		return
+	}
+	r.buf = prefetchKey(r.buf, domain, address, slot)
+	if r.block.has(r.buf) {
+		r.hits++
+	} else {
+		r.misses++
+	}
+}
+
+func (r *prefetchTracker) ReadAccountData(address common.Address) (*accounts.Account, error) {
+	r.track(kv.AccountsDomain, address, nil)
+	return r.ResettableStateReader.ReadAccountData(address)
+}
+
+func (r *prefetchTracker) ReadAccountStorage(address common.Address, incarnation uint64, key *common.Hash) ([]byte, error) {
+	r.track(kv.StorageDomain, address, key)
+	return r.ResettableStateReader.ReadAccountStorage(address, incarnation, key)
+}
+
+func (r *prefetchTracker) ReadAccountCode(address common.Address, incarnation uint64, codeHash common.Hash) ([]byte, error) {
+	r.track(kv.CodeDomain, address, nil)
+	return r.ResettableStateReader.ReadAccountCode(address, incarnation, codeHash)
+}
+
+func (r *prefetchTracker) ReadAccountCodeSize(address common.Address, incarnation uint64, codeHash common.Hash) (int, error) {
+	r.track(kv.CodeDomain, address, nil)
+	return r.ResettableStateReader.ReadAccountCodeSize(address, incarnation, codeHash)
+}
+
+// startBlock schedules the prefetch of the upcoming blocks and returns the
+// prefetch of the block about to be executed, if any, along with the summary
+// of its execution time.
+func (p *statePrefetcher) startBlock(tx kv.Tx, blockNum, maxBlockNum uint64) (*prefetchedBlock, metrics.Summary, error) {
+	if err := p.schedule(tx, min(blockNum+prefetchBlocksAhead, maxBlockNum)); err != nil {
+		return nil, nil, err
+	}
+	b := p.take(blockNum)
+	switch {
+	case b == nil:
+		mxExecPrefetchMissed.Inc()
+		return nil, mxExecBlockPrefetchMissed, nil
+	case b.pending.Load() == 0:
+		mxExecPrefetchReady.Inc()
+		return b, mxExecBlockPrefetchReady, nil
+	default:
+		mxExecPrefetchPending.Inc()
+		return b, mxExecBlockPrefetchPending, nil
+	}
+}
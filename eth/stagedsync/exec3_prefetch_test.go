@@ -0,0 +1,109 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package stagedsync_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	types2 "github.com/erigontech/erigon-lib/types"
+
+	"github.com/erigontech/erigon/core"
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/crypto"
+	"github.com/erigontech/erigon/eth/stagedsync"
+	"github.com/erigontech/erigon/params"
+	"github.com/erigontech/erigon/turbo/stages/mock"
+)
+
+func TestStatePrefetcher(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		contract = libcommon.HexToAddress("0xc0")
+		listed   = libcommon.HexToAddress("0xa1")
+		receiver = libcommon.HexToAddress("0xb0")
+		slot     = libcommon.Hash{31: 1}
+		declared = libcommon.Hash{31: 2}
+		gspec    = &types.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				sender: {Balance: big.NewInt(params.Ether)},
+				// PUSH1 0 CALLDATALOAD PUSH1 1 SSTORE STOP
+				contract: {Balance: new(big.Int), Code: []byte{0x60, 0x00, 0x35, 0x60, 0x01, 0x55, 0x00}},
+			},
+		}
+	)
+	m := mock.MockWithGenesis(t, gspec, key, false)
+	signer := types.LatestSignerForChainID(m.ChainConfig.ChainID)
+
+	chain, err := core.GenerateChain(m.ChainConfig, m.Genesis, m.Engine, m.DB, 1, func(i int, b *core.BlockGen) {
+		call, err := types.SignTx(&types.AccessListTx{
+			LegacyTx: types.LegacyTx{
+				CommonTx: types.CommonTx{Nonce: b.TxNonce(sender), To: &contract, Gas: 100_000, Data: slot[:]},
+				GasPrice: uint256.NewInt(params.GWei),
+			},
+			ChainID:    uint256.MustFromBig(m.ChainConfig.ChainID),
+			AccessList: types2.AccessList{{Address: listed, StorageKeys: []libcommon.Hash{declared}}},
+		}, *signer, key)
+		require.NoError(t, err)
+		b.AddTx(call)
+		transfer, err := types.SignTx(types.NewTransaction(b.TxNonce(sender), receiver, uint256.NewInt(1), params.TxGas, uint256.NewInt(params.GWei), nil), *signer, key)
+		require.NoError(t, err)
+		b.AddTx(transfer)
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.InsertChain(chain))
+
+	keys, cache, err := stagedsync.PrefetchBlock(m.Ctx, m.DB, m.BlockReader, m.ChainConfig, m.Engine, 1)
+	require.NoError(t, err)
+	for _, want := range []string{
+		stagedsync.PrefetchKey(kv.AccountsDomain, sender, nil),
+		stagedsync.PrefetchKey(kv.AccountsDomain, contract, nil),
+		stagedsync.PrefetchKey(kv.CodeDomain, contract, nil),
+		stagedsync.PrefetchKey(kv.StorageDomain, contract, &libcommon.Hash{31: 1}),
+		stagedsync.PrefetchKey(kv.AccountsDomain, receiver, nil),
+		stagedsync.PrefetchKey(kv.AccountsDomain, listed, nil),
+		stagedsync.PrefetchKey(kv.StorageDomain, listed, &declared),
+	} {
+		require.Contains(t, keys, want)
+	}
+	require.NotContains(t, keys, stagedsync.PrefetchKey(kv.StorageDomain, contract, &declared))
+
+	// the values read are those of the executor's cache
+	code, _, ok := cache.Get(kv.CodeDomain, contract[:])
+	require.True(t, ok)
+	require.Equal(t, gspec.Alloc[contract].Code, code)
+	_, _, ok = cache.Get(kv.AccountsDomain, sender[:])
+	require.True(t, ok)
+	value, _, ok := cache.Get(kv.StorageDomain, append(contract[:], slot[:]...))
+	require.True(t, ok)
+	require.Equal(t, []byte{1}, value)
+
+	// values read from an older state are dropped once it is reset
+	cache.Reset()
+	_, _, ok = cache.Get(kv.CodeDomain, contract[:])
+	require.False(t, ok)
+	cache.Put(0, kv.CodeDomain, contract[:], []byte{0x00}, 0)
+	_, _, ok = cache.Get(kv.CodeDomain, contract[:])
+	require.False(t, ok)
+}
@@ -0,0 +1,59 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package stagedsync
+
+import (
+	"context"
+	"time"
+
+	"github.com/erigontech/erigon-lib/chain"
+	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
+	"github.com/erigontech/erigon-lib/state"
+	"github.com/erigontech/erigon/consensus"
+	"github.com/erigontech/erigon/turbo/services"
+)
+
+// PrefetchKey returns the key of an account, its code or one of its storage
+// slots in the prefetched keys.
+func PrefetchKey(domain kv.Domain, address common.Address, slot *common.Hash) string {
+	return string(prefetchKey(nil, domain, address, slot))
+}
+
+// PrefetchBlock runs the state prefetcher on a block and returns the keys it
+// read, along with the cache of the values.
+func PrefetchBlock(ctx context.Context, db kv.RwDB, blockReader services.FullBlockReader, chainConfig *chain.Config, engine consensus.Engine, blockNum uint64) (map[string]struct{}, *state.LatestCache, error) {
+	cfg := &ExecuteBlockCfg{db: db, blockReader: blockReader, chainConfig: chainConfig, engine: engine}
+	cache := state.NewLatestCache()
+	p, stop := newStatePrefetcher(ctx, cfg, log.New(), cache, 2, blockNum)
+	defer stop()
+
+	tx, err := db.BeginRo(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+	b, _, err := p.startBlock(tx, blockNum, blockNum)
+	if err != nil || b == nil {
+		return nil, nil, err
+	}
+	for b.pending.Load() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	return b.keys, cache, nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/erigontech/erigon-lib/common/hexutility"
 	"github.com/erigontech/erigon/common/math"
 	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/eth/tracers/sourcemap"
 )
 
 var _ = (*structLogMarshaling)(nil)
@@ -28,6 +29,7 @@ func (s StructLog) MarshalJSON() ([]byte, error) {
 		Storage       map[common.Hash]common.Hash `json:"-"`
 		Depth         int                         `json:"depth"`
 		RefundCounter uint64                      `json:"refund"`
+		Source        *sourcemap.Location         `json:"source,omitempty"`
 		Err           error                       `json:"-"`
 		OpName        string                      `json:"opName"`
 		ErrorString   string                      `json:"error"`
@@ -49,6 +51,7 @@ func (s StructLog) MarshalJSON() ([]byte, error) {
 	enc.Storage = s.Storage
 	enc.Depth = s.Depth
 	enc.RefundCounter = s.RefundCounter
+	enc.Source = s.Source
 	enc.Err = s.Err
 	enc.OpName = s.OpName()
 	enc.ErrorString = s.ErrorString()
@@ -69,6 +72,7 @@ func (s *StructLog) UnmarshalJSON(input []byte) error {
 		Storage       map[common.Hash]common.Hash `json:"-"`
 		Depth         *int                        `json:"depth"`
 		RefundCounter *uint64                     `json:"refund"`
+		Source        *sourcemap.Location         `json:"source,omitempty"`
 		Err           error                       `json:"-"`
 	}
 	var dec StructLog
@@ -111,6 +115,9 @@ func (s *StructLog) UnmarshalJSON(input []byte) error {
 	if dec.RefundCounter != nil {
 		s.RefundCounter = *dec.RefundCounter
 	}
+	if dec.Source != nil {
+		s.Source = dec.Source
+	}
 	if dec.Err != nil {
 		s.Err = dec.Err
 	}
@@ -38,6 +38,7 @@ import (
 type JsonStreamLogger struct {
 	ctx          context.Context
 	cfg          LogConfig
+	sources      sourceLocator
 	stream       *jsoniter.Stream
 	hexEncodeBuf [128]byte
 	firstCapture bool
@@ -60,6 +61,7 @@ func NewJsonStreamLogger(cfg *LogConfig, ctx context.Context, stream *jsoniter.S
 	}
 	if cfg != nil {
 		logger.cfg = *cfg
+		logger.sources.registry = cfg.SourceMaps
 	}
 	return logger
 }
@@ -141,6 +143,11 @@ func (l *JsonStreamLogger) CaptureState(pc uint64, op vm.OpCode, gas, cost uint6
 	l.stream.WriteMore()
 	l.stream.WriteObjectField("depth")
 	l.stream.WriteInt(depth)
+	if source := l.sources.locate(contract, pc); source != nil {
+		l.stream.WriteMore()
+		l.stream.WriteObjectField("source")
+		l.stream.WriteVal(source)
+	}
 	if err != nil {
 		l.stream.WriteMore()
 		l.stream.WriteObjectField("error")
@@ -36,6 +36,7 @@ import (
 	"github.com/erigontech/erigon/common/math"
 	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/eth/tracers/sourcemap"
 )
 
 var ErrTraceLimitReached = errors.New("the number of logs reached the specified limit")
@@ -58,6 +59,27 @@ type LogConfig struct {
 	Limit             int  // maximum length of output, but zero means unlimited
 	// Chain overrides, can be used to execute a trace using future fork rules
 	Overrides *chain.Config `json:"overrides,omitempty"`
+	// Source maps of known contracts, used to annotate the logs with the
+	// source location of the executed code
+	SourceMaps *sourcemap.Registry `json:"-"`
+}
+
+// sourceLocator resolves the source location of executed instructions with
+// the configured source maps, remembering the code of the last contract.
+type sourceLocator struct {
+	registry *sourcemap.Registry
+	contract *vm.Contract
+	code     *sourcemap.Code
+}
+
+func (s *sourceLocator) locate(contract *vm.Contract, pc uint64) *sourcemap.Location {
+	if s.registry == nil {
+		return nil
+	}
+	if contract != s.contract {
+		s.contract, s.code = contract, s.registry.Lookup(contract)
+	}
+	return s.code.Locate(pc)
 }
 
 //go:generate gencodec -type StructLog -field-override structLogMarshaling -out gen_structlog.go
@@ -76,6 +98,7 @@ type StructLog struct {
 	Storage       map[libcommon.Hash]libcommon.Hash `json:"-"`
 	Depth         int                               `json:"depth"`
 	RefundCounter uint64                            `json:"refund"`
+	Source        *sourcemap.Location               `json:"source,omitempty"`
 	Err           error                             `json:"-"`
 }
 
@@ -106,15 +129,16 @@ func (s *StructLog) ErrorString() string {
 // StructLogRes stores a structured log emitted by the EVM while replaying a
 // transaction in debug mode
 type StructLogRes struct {
-	Pc      uint64             `json:"pc"`
-	Op      string             `json:"op"`
-	Gas     uint64             `json:"gas"`
-	GasCost uint64             `json:"gasCost"`
-	Depth   int                `json:"depth"`
-	Error   error              `json:"error,omitempty"`
-	Stack   *[]string          `json:"stack,omitempty"`
-	Memory  *[]string          `json:"memory,omitempty"`
-	Storage *map[string]string `json:"storage,omitempty"`
+	Pc      uint64              `json:"pc"`
+	Op      string              `json:"op"`
+	Gas     uint64              `json:"gas"`
+	GasCost uint64              `json:"gasCost"`
+	Depth   int                 `json:"depth"`
+	Error   error               `json:"error,omitempty"`
+	Stack   *[]string           `json:"stack,omitempty"`
+	Memory  *[]string           `json:"memory,omitempty"`
+	Storage *map[string]string  `json:"storage,omitempty"`
+	Source  *sourcemap.Location `json:"source,omitempty"`
 }
 
 // StructLogger is an EVM state logger and implements Tracer.
@@ -123,7 +147,8 @@ type StructLogRes struct {
 // a track record of modified storage which is used in reporting snapshots of the
 // contract their storage.
 type StructLogger struct {
-	cfg LogConfig
+	cfg     LogConfig
+	sources sourceLocator
 
 	storage map[libcommon.Address]Storage
 	logs    []StructLog
@@ -139,6 +164,7 @@ func NewStructLogger(cfg *LogConfig) *StructLogger {
 	}
 	if cfg != nil {
 		logger.cfg = *cfg
+		logger.sources.registry = cfg.SourceMaps
 	}
 	return logger
 }
@@ -216,7 +242,7 @@ func (l *StructLogger) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, s
 		copy(rdata, rData)
 	}
 	// create a new snapshot of the EVM.
-	log := StructLog{pc, op, gas, cost, mem, memory.Len(), stck, rdata, storage, depth, l.env.IntraBlockState().GetRefund(), err}
+	log := StructLog{pc, op, gas, cost, mem, memory.Len(), stck, rdata, storage, depth, l.env.IntraBlockState().GetRefund(), l.sources.locate(contract, pc), err}
 	l.logs = append(l.logs, log)
 }
 
@@ -276,6 +302,7 @@ func FormatLogs(logs []StructLog) []StructLogRes {
 			GasCost: trace.GasCost,
 			Depth:   trace.Depth,
 			Error:   trace.Err,
+			Source:  trace.Source,
 		}
 		if trace.Stack != nil {
 			stack := make([]string, len(trace.Stack))
@@ -0,0 +1,346 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync/atomic"
+
+	"github.com/holiman/uint256"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/eth/tracers"
+	"github.com/erigontech/erigon/params"
+)
+
+func init() {
+	register("profileTracer", newProfileTracer)
+}
+
+// profileTracer aggregates the gas spent by a transaction per contract,
+// function selector and opcode, along with the cold and warm storage accesses,
+// and the gas spent per call stack in the folded format of flame graph tools.
+//
+// The gas of every opcode is attributed to the code executing it, excluding
+// the gas used by the calls it makes, so that the gas of all the entries adds
+// up to the gas used by the execution. A call stack frame is the address of
+// the code and the selector it is called with, so a contract reached by
+// DELEGATECALL shows up as the library holding the code.
+//
+// Example:
+//
+//	> debug.traceTransaction("0x214e...", {tracer: "profileTracer"})
+//	{
+//	  "gasUsed": 43494,
+//	  "intrinsicGas": 21064,
+//	  "contracts": [{
+//	    "address": "0x...",
+//	    "selector": "0xa9059cbb",
+//	    "gas": 22430,
+//	    "opcodes": {"SSTORE": {"count": 2, "gas": 20000}, ...},
+//	    "sload": {"cold": 2, "warm": 2},
+//	    "sstore": {"cold": 0, "warm": 2}
+//	  }],
+//	  "folded": ["0x...:0xa9059cbb;SSTORE 20000", ...]
+//	}
+type profileTracer struct {
+	noopTracer
+	env          *vm.EVM
+	frames       []*profileFrame
+	entries      map[profileKey]*profileEntry
+	folded       map[string]uint64
+	gasLimit     uint64
+	gasUsed      uint64
+	intrinsicGas uint64
+	selfdestruct bool   // Whether a SELFDESTRUCT scope is being entered
+	interrupt    uint32 // Atomic flag to signal execution interruption
+	reason       error  // Textual reason for the interruption
+}
+
+// profileKey identifies the code running in a call frame.
+type profileKey struct {
+	address  libcommon.Address
+	selector string
+}
+
+// profileEntry is the profile of the code of a contract called with a selector.
+type profileEntry struct {
+	Address  libcommon.Address         `json:"address"`
+	Selector string                    `json:"selector"`
+	Gas      uint64                    `json:"gas"`
+	Opcodes  map[string]*opcodeProfile `json:"opcodes"`
+	SLoad    storageAccesses           `json:"sload"`
+	SStore   storageAccesses           `json:"sstore"`
+}
+
+type opcodeProfile struct {
+	Count uint64 `json:"count"`
+	Gas   uint64 `json:"gas"`
+}
+
+type storageAccesses struct {
+	Cold uint64 `json:"cold"`
+	Warm uint64 `json:"warm"`
+}
+
+// profileFrame tracks the gas attribution within a call frame. The gas of an
+// opcode is only known once the next one starts, or once the frame exits.
+type profileFrame struct {
+	path       string // folded call stack down to this frame
+	entry      *profileEntry
+	precompile bool
+	accounted  uint64 // gas attributed to the opcodes and calls of the frame so far
+
+	pending    bool // whether op is waiting for its gas to be attributed
+	op         vm.OpCode
+	opGas      uint64 // gas available before op
+	opChildGas uint64 // gas used by the calls made by op
+}
+
+// profileResult is the result of the profileTracer.
+type profileResult struct {
+	GasUsed      uint64          `json:"gasUsed"`
+	IntrinsicGas uint64          `json:"intrinsicGas"`
+	Contracts    []*profileEntry `json:"contracts"`
+	Folded       []string        `json:"folded"`
+}
+
+// newProfileTracer returns a native go tracer which profiles the gas usage of
+// a transaction, and implements vm.EVMLogger.
+func newProfileTracer(ctx *tracers.Context, _ json.RawMessage) (tracers.Tracer, error) {
+	return &profileTracer{
+		entries: make(map[profileKey]*profileEntry),
+		folded:  make(map[string]uint64),
+	}, nil
+}
+
+func (t *profileTracer) CaptureTxStart(gasLimit uint64) {
+	t.gasLimit = gasLimit
+}
+
+func (t *profileTracer) CaptureTxEnd(restGas uint64) {
+	t.gasUsed = t.gasLimit - restGas
+}
+
+// CaptureStart implements the EVMLogger interface to initialize the tracing operation.
+func (t *profileTracer) CaptureStart(env *vm.EVM, from libcommon.Address, to libcommon.Address, precompile bool, create bool, input []byte, gas uint64, value *uint256.Int, code []byte) {
+	t.env = env
+	if t.gasLimit > gas {
+		t.intrinsicGas = t.gasLimit - gas
+	}
+	t.enter(to, precompile, create, input)
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *profileTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	t.exit(gasUsed)
+}
+
+// CaptureEnter is called when EVM enters a new scope (via call, create or selfdestruct).
+func (t *profileTracer) CaptureEnter(typ vm.OpCode, from libcommon.Address, to libcommon.Address, precompile, create bool, input []byte, gas uint64, value *uint256.Int, code []byte) {
+	// SELFDESTRUCT enters and exits a scope without running any code
+	if typ == vm.SELFDESTRUCT {
+		t.selfdestruct = true
+		return
+	}
+	t.enter(to, precompile, create, input)
+}
+
+// CaptureExit is called when EVM exits a scope, even if the scope didn't
+// execute any code.
+func (t *profileTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if t.selfdestruct {
+		t.selfdestruct = false
+		return
+	}
+	t.exit(gasUsed)
+}
+
+// CaptureState implements the EVMLogger interface to trace a single step of VM execution.
+func (t *profileTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	// Skip if tracing was interrupted
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		return
+	}
+	frame := t.top()
+	if frame == nil {
+		return
+	}
+	t.settle(frame, gas)
+	frame.pending, frame.op, frame.opGas, frame.opChildGas = true, op, gas, 0
+	t.opcode(frame, op).Count++
+
+	if (op == vm.SLOAD || op == vm.SSTORE) && err == nil && scope.Stack.Len() > 0 {
+		accesses := &frame.entry.SLoad
+		if op == vm.SSTORE {
+			accesses = &frame.entry.SStore
+		}
+		// Before Berlin, every access is priced as a cold one
+		if !t.env.ChainRules().IsBerlin || isColdAccess(op, cost) {
+			accesses.Cold++
+		} else {
+			accesses.Warm++
+		}
+	}
+}
+
+// isColdAccess reports whether an SLOAD or SSTORE of the given cost paid the
+// EIP-2929 cold slot surcharge. The slot is already in the access list when
+// the opcode is traced, since it is added while charging the gas.
+func isColdAccess(op vm.OpCode, cost uint64) bool {
+	if cost < params.ColdSloadCostEIP2929 {
+		return false
+	}
+	if op == vm.SLOAD {
+		return true
+	}
+	switch cost - params.ColdSloadCostEIP2929 {
+	case params.WarmStorageReadCostEIP2929, params.SstoreSetGasEIP2200, params.SstoreResetGasEIP2200 - params.ColdSloadCostEIP2929:
+		return true
+	}
+	return false
+}
+
+// enter pushes the frame of a call to the code at the given address.
+func (t *profileTracer) enter(addr libcommon.Address, precompile, create bool, input []byte) {
+	key := profileKey{address: addr, selector: "fallback"}
+	switch {
+	case create:
+		key.selector = "constructor"
+	case len(input) >= 4:
+		key.selector = bytesToHex(input[:4])
+	}
+	entry, ok := t.entries[key]
+	if !ok {
+		entry = &profileEntry{Address: addr, Selector: key.selector, Opcodes: make(map[string]*opcodeProfile)}
+		t.entries[key] = entry
+	}
+	path := fmt.Sprintf("0x%x:%s", addr, key.selector)
+	if parent := t.top(); parent != nil {
+		path = parent.path + ";" + path
+	}
+	t.frames = append(t.frames, &profileFrame{path: path, entry: entry, precompile: precompile})
+}
+
+// exit pops the current frame, attributing the gas it used that is not
+// accounted for yet to its last opcode.
+func (t *profileTracer) exit(gasUsed uint64) {
+	frame := t.top()
+	if frame == nil {
+		return
+	}
+	t.frames = t.frames[:len(t.frames)-1]
+
+	var rest uint64
+	if gasUsed > frame.accounted {
+		rest = gasUsed - frame.accounted
+	}
+	switch {
+	case frame.pending:
+		t.attribute(frame, t.opcode(frame, frame.op), frame.op.String(), rest)
+	case frame.precompile && rest > 0:
+		t.attribute(frame, t.opcodeByName(frame, "PRECOMPILE"), "PRECOMPILE", rest)
+	}
+	if parent := t.top(); parent != nil {
+		parent.accounted += gasUsed
+		parent.opChildGas += gasUsed
+	}
+}
+
+// settle attributes the gas of the pending opcode of a frame, given the gas
+// available after it.
+func (t *profileTracer) settle(frame *profileFrame, gas uint64) {
+	if !frame.pending {
+		return
+	}
+	var spent uint64
+	if frame.opGas > gas+frame.opChildGas {
+		spent = frame.opGas - gas - frame.opChildGas
+	}
+	t.attribute(frame, t.opcode(frame, frame.op), frame.op.String(), spent)
+	frame.pending = false
+}
+
+func (t *profileTracer) attribute(frame *profileFrame, profile *opcodeProfile, name string, gas uint64) {
+	profile.Gas += gas
+	frame.entry.Gas += gas
+	frame.accounted += gas
+	if gas > 0 {
+		t.folded[frame.path+";"+name] += gas
+	}
+}
+
+func (t *profileTracer) opcode(frame *profileFrame, op vm.OpCode) *opcodeProfile {
+	return t.opcodeByName(frame, op.String())
+}
+
+func (t *profileTracer) opcodeByName(frame *profileFrame, name string) *opcodeProfile {
+	profile, ok := frame.entry.Opcodes[name]
+	if !ok {
+		profile = new(opcodeProfile)
+		frame.entry.Opcodes[name] = profile
+	}
+	return profile
+}
+
+func (t *profileTracer) top() *profileFrame {
+	if len(t.frames) == 0 {
+		return nil
+	}
+	return t.frames[len(t.frames)-1]
+}
+
+// GetResult returns the json-encoded gas profile, and any error arising from
+// the encoding or forceful termination (via `Stop`).
+func (t *profileTracer) GetResult() (json.RawMessage, error) {
+	result := profileResult{
+		GasUsed:      t.gasUsed,
+		IntrinsicGas: t.intrinsicGas,
+		Contracts:    make([]*profileEntry, 0, len(t.entries)),
+		Folded:       make([]string, 0, len(t.folded)),
+	}
+	for _, entry := range t.entries {
+		result.Contracts = append(result.Contracts, entry)
+	}
+	sort.Slice(result.Contracts, func(i, j int) bool {
+		if c := bytes.Compare(result.Contracts[i].Address[:], result.Contracts[j].Address[:]); c != 0 {
+			return c < 0
+		}
+		return result.Contracts[i].Selector < result.Contracts[j].Selector
+	})
+	for stack, gas := range t.folded {
+		result.Folded = append(result.Folded, fmt.Sprintf("%s %d", stack, gas))
+	}
+	sort.Strings(result.Folded)
+
+	res, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *profileTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
@@ -0,0 +1,342 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sourcemap
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/crypto"
+)
+
+// Artifact is the compilation output registered with a Registry: the solc
+// standard-json output, with the bytecode, source maps and ASTs selected in
+// its outputSelection, and the content of the compiled sources by path.
+type Artifact struct {
+	Output  json.RawMessage   `json:"output"`
+	Sources map[string]string `json:"sources"`
+}
+
+// compilerOutput is the part of the solc standard-json output used here.
+type compilerOutput struct {
+	Sources map[string]struct {
+		ID  int `json:"id"`
+		AST any `json:"ast"`
+	} `json:"sources"`
+	Contracts map[string]map[string]struct {
+		EVM struct {
+			Bytecode         bytecode `json:"bytecode"`
+			DeployedBytecode bytecode `json:"deployedBytecode"`
+		} `json:"evm"`
+	} `json:"contracts"`
+}
+
+type bytecode struct {
+	Object              string                            `json:"object"`
+	SourceMap           string                            `json:"sourceMap"`
+	LinkReferences      map[string]map[string][]byteRange `json:"linkReferences"`
+	ImmutableReferences map[string][]byteRange            `json:"immutableReferences"`
+}
+
+type byteRange struct {
+	Start  int `json:"start"`
+	Length int `json:"length"`
+}
+
+// Code is the creation or runtime code of a compiled contract.
+type Code struct {
+	Contract string // "path:Name" of the contract
+
+	code      []byte
+	masked    []bool // bytes not known at compile time: libraries, immutables, metadata
+	indexes   []int32
+	locations []*Location // by instruction index
+}
+
+// Locate returns the source location of the instruction at pc, or nil if it
+// is unknown.
+func (c *Code) Locate(pc uint64) *Location {
+	if c == nil || pc >= uint64(len(c.indexes)) {
+		return nil
+	}
+	if i := c.indexes[pc]; int(i) < len(c.locations) {
+		return c.locations[i]
+	}
+	return nil
+}
+
+// matches reports whether the code of a contract is this code, up to the
+// masked bytes. Creation code is followed by the constructor arguments.
+func (c *Code) matches(code []byte, creation bool) bool {
+	if len(code) < len(c.code) || (!creation && len(code) != len(c.code)) {
+		return false
+	}
+	for i, b := range c.code {
+		if !c.masked[i] && code[i] != b {
+			return false
+		}
+	}
+	return true
+}
+
+// resolvedCacheSize is the number of code hashes whose match is remembered.
+const resolvedCacheSize = 4096
+
+// Registry holds the source maps of registered contracts and matches them
+// against the code of executing contracts. It is safe for concurrent use.
+type Registry struct {
+	lock      sync.RWMutex
+	contracts []string
+	runtime   map[int][]*Code // by code length
+	creation  []*Code
+	resolved  *lru.Cache[libcommon.Hash, *Code] // by code hash, nil if unmatched
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	resolved, _ := lru.New[libcommon.Hash, *Code](resolvedCacheSize)
+	return &Registry{
+		runtime:  make(map[int][]*Code),
+		resolved: resolved,
+	}
+}
+
+// Len returns the number of registered contracts.
+func (r *Registry) Len() int {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return len(r.contracts)
+}
+
+// Register adds the contracts of a solc standard-json output, whose sources
+// have the given content by path, and returns their names.
+func (r *Registry) Register(output []byte, contents map[string]string) ([]string, error) {
+	var out compilerOutput
+	if err := json.Unmarshal(output, &out); err != nil {
+		return nil, fmt.Errorf("invalid compiler output: %w", err)
+	}
+	sources := make(map[int]*source, len(out.Sources))
+	for path, s := range out.Sources {
+		sources[s.ID] = newSource(path, contents[path], s.AST)
+	}
+	var (
+		names    []string
+		runtime  []*Code
+		creation []*Code
+	)
+	for path, contracts := range out.Contracts {
+		for name, contract := range contracts {
+			name = path + ":" + name
+			created, err := newCode(name, &contract.EVM.Bytecode, sources)
+			if err != nil {
+				return nil, fmt.Errorf("%s bytecode: %w", name, err)
+			}
+			deployed, err := newCode(name, &contract.EVM.DeployedBytecode, sources)
+			if err != nil {
+				return nil, fmt.Errorf("%s deployed bytecode: %w", name, err)
+			}
+			if created == nil && deployed == nil {
+				continue
+			}
+			if created != nil {
+				creation = append(creation, created)
+			}
+			if deployed != nil {
+				runtime = append(runtime, deployed)
+			}
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, errors.New("no contract with bytecode and source map in compiler output")
+	}
+	sort.Strings(names)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, c := range runtime {
+		r.runtime[len(c.code)] = append(r.runtime[len(c.code)], c)
+	}
+	r.creation = append(r.creation, creation...)
+	r.contracts = append(r.contracts, names...)
+	// Contracts previously left unmatched may match the new ones.
+	for _, hash := range r.resolved.Keys() {
+		if c, ok := r.resolved.Peek(hash); ok && c == nil {
+			r.resolved.Remove(hash)
+		}
+	}
+	return names, nil
+}
+
+// LoadDir registers the artifacts stored as JSON files in dir, and returns
+// the names of their contracts. A missing directory holds no artifacts.
+func (r *Registry) LoadDir(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return names, err
+		}
+		var artifact Artifact
+		if err := json.Unmarshal(data, &artifact); err != nil {
+			return names, fmt.Errorf("%s: %w", file, err)
+		}
+		registered, err := r.Register(artifact.Output, artifact.Sources)
+		if err != nil {
+			return names, fmt.Errorf("%s: %w", file, err)
+		}
+		names = append(names, registered...)
+	}
+	return names, nil
+}
+
+// Lookup returns the registered code the contract is executing, or nil if
+// there is none. EOF code is not supported.
+func (r *Registry) Lookup(contract *vm.Contract) *Code {
+	if len(contract.Code) == 0 || contract.IsEOF() {
+		return nil
+	}
+	hash := contract.CodeHash
+	if hash == (libcommon.Hash{}) {
+		hash = crypto.Keccak256Hash(contract.Code)
+	}
+	r.lock.RLock()
+	c, ok := r.resolved.Get(hash)
+	r.lock.RUnlock()
+	if ok {
+		return c
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	c = r.match(contract.Code)
+	r.resolved.Add(hash, c)
+	return c
+}
+
+func (r *Registry) match(code []byte) *Code {
+	for _, c := range r.runtime[len(code)] {
+		if c.matches(code, false) {
+			return c
+		}
+	}
+	for _, c := range r.creation {
+		if c.matches(code, true) {
+			return c
+		}
+	}
+	return nil
+}
+
+// newCode decodes the bytecode of a contract and resolves the source location
+// of each of its instructions. It returns nil if the bytecode or its source
+// map is missing, as for interfaces and abstract contracts.
+func newCode(name string, b *bytecode, sources map[int]*source) (*Code, error) {
+	object := strings.TrimPrefix(b.Object, "0x")
+	if object == "" || b.SourceMap == "" {
+		return nil, nil
+	}
+	// Library addresses are left as placeholders in the hex code.
+	var links []byteRange
+	for _, libs := range b.LinkReferences {
+		for _, refs := range libs {
+			links = append(links, refs...)
+		}
+	}
+	hexCode := []byte(object)
+	for _, ref := range links {
+		if 2*(ref.Start+ref.Length) > len(hexCode) {
+			return nil, fmt.Errorf("link reference out of range: %d+%d", ref.Start, ref.Length)
+		}
+		for i := 2 * ref.Start; i < 2*(ref.Start+ref.Length); i++ {
+			hexCode[i] = '0'
+		}
+	}
+	code := make([]byte, len(hexCode)/2)
+	if _, err := hex.Decode(code, hexCode); err != nil {
+		return nil, err
+	}
+	entries, err := decode(b.SourceMap)
+	if err != nil {
+		return nil, err
+	}
+
+	masked := make([]bool, len(code))
+	for _, refs := range b.ImmutableReferences {
+		links = append(links, refs...)
+	}
+	for _, ref := range links {
+		if ref.Start+ref.Length > len(code) {
+			return nil, fmt.Errorf("reference out of range: %d+%d", ref.Start, ref.Length)
+		}
+		for i := ref.Start; i < ref.Start+ref.Length; i++ {
+			masked[i] = true
+		}
+	}
+	// The CBOR-encoded metadata appended by the compiler is followed by its
+	// length, and its hash depends on more than the code.
+	if n := len(code); n >= 2 {
+		if size := int(binary.BigEndian.Uint16(code[n-2:])); size+2 <= n && code[n-2-size]&0xe0 == 0xa0 {
+			for i := n - 2 - size; i < n; i++ {
+				masked[i] = true
+			}
+		}
+	}
+
+	c := &Code{
+		Contract:  name,
+		code:      code,
+		masked:    masked,
+		indexes:   instructionIndexes(code),
+		locations: make([]*Location, len(entries)),
+	}
+	cache := make(map[entry]*Location)
+	for i, e := range entries {
+		loc, ok := cache[e]
+		if !ok {
+			if s := sources[e.file]; s != nil && e.file >= 0 {
+				line, column := s.position(e.start)
+				loc = &Location{
+					File:     s.path,
+					Line:     line,
+					Column:   column,
+					Function: s.function(e.start, e.start+e.length),
+				}
+			}
+			cache[e] = loc
+		}
+		c.locations[i] = loc
+	}
+	return c, nil
+}
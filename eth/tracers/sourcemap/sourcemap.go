@@ -0,0 +1,187 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+// Package sourcemap maps the program counter of executing contracts to the
+// Solidity source code they were compiled from, using the source maps and
+// ASTs of the solc standard-json output.
+package sourcemap
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/erigontech/erigon/core/vm"
+)
+
+// Location is the position in the sources of the code an instruction was
+// generated from. Line and Column are 1-based and left out when the content
+// of the source file is unknown.
+type Location struct {
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Function string `json:"function,omitempty"`
+}
+
+// entry is a decoded element of a source map: the byte range of the source
+// code an instruction was generated from, in the source file with the given
+// index. A negative file index stands for code without source, such as the
+// internal routines generated by the compiler.
+type entry struct {
+	start, length, file int
+}
+
+// decode decompresses a source map, a list of "s:l:f:j:m" elements separated
+// by semicolons in which empty or missing fields repeat the value of the
+// previous element.
+func decode(sourceMap string) ([]entry, error) {
+	if sourceMap == "" {
+		return nil, nil
+	}
+	elems := strings.Split(sourceMap, ";")
+	entries := make([]entry, len(elems))
+	cur := entry{file: -1}
+	for i, elem := range elems {
+		for j, field := range strings.SplitN(elem, ":", 4) {
+			if j > 2 {
+				break
+			}
+			if field == "" {
+				continue
+			}
+			v, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("source map element %d: %w", i, err)
+			}
+			switch j {
+			case 0:
+				cur.start = v
+			case 1:
+				cur.length = v
+			case 2:
+				cur.file = v
+			}
+		}
+		entries[i] = cur
+	}
+	return entries, nil
+}
+
+// instructionIndexes maps each offset of code to the index of the instruction
+// at that offset, the immediate arguments of PUSH instructions belonging to
+// the PUSH.
+func instructionIndexes(code []byte) []int32 {
+	indexes := make([]int32, len(code))
+	var n int32
+	for pc := 0; pc < len(code); n++ {
+		size := 1
+		if op := vm.OpCode(code[pc]); op >= vm.PUSH1 && op <= vm.PUSH32 {
+			size += int(op-vm.PUSH1) + 1
+		}
+		for i := pc; i < pc+size && i < len(code); i++ {
+			indexes[i] = n
+		}
+		pc += size
+	}
+	return indexes
+}
+
+// function is the byte range of a function or modifier definition.
+type function struct {
+	start, end int
+	name       string
+}
+
+// source is a compiled source file.
+type source struct {
+	path      string
+	lines     []int // offsets of the line starts, nil if the content is unknown
+	functions []function
+}
+
+func newSource(path, content string, ast any) *source {
+	s := &source{path: path}
+	if content != "" {
+		s.lines = append(s.lines, 0)
+		for i := 0; i < len(content); i++ {
+			if content[i] == '\n' {
+				s.lines = append(s.lines, i+1)
+			}
+		}
+	}
+	collectFunctions(ast, "", &s.functions)
+	return s
+}
+
+// position returns the line and column of a byte offset in the source.
+func (s *source) position(offset int) (line, column int) {
+	if len(s.lines) == 0 {
+		return 0, 0
+	}
+	line = sort.Search(len(s.lines), func(i int) bool { return s.lines[i] > offset })
+	return line, offset - s.lines[line-1] + 1
+}
+
+// function returns the name of the innermost function or modifier enclosing
+// the given byte range of the source.
+func (s *source) function(start, end int) string {
+	var found *function
+	for i := range s.functions {
+		f := &s.functions[i]
+		if f.start <= start && end <= f.end && (found == nil || f.end-f.start < found.end-found.start) {
+			found = f
+		}
+	}
+	if found == nil {
+		return ""
+	}
+	return found.name
+}
+
+// collectFunctions walks a solc JSON AST and collects the function and
+// modifier definitions, named after their contract.
+func collectFunctions(node any, contract string, out *[]function) {
+	switch n := node.(type) {
+	case []any:
+		for _, child := range n {
+			collectFunctions(child, contract, out)
+		}
+	case map[string]any:
+		name, _ := n["name"].(string)
+		switch n["nodeType"] {
+		case "ContractDefinition":
+			contract = name
+		case "FunctionDefinition", "ModifierDefinition":
+			if name == "" {
+				// constructor, fallback or receive
+				name, _ = n["kind"].(string)
+			}
+			if contract != "" {
+				name = contract + "." + name
+			}
+			if src, ok := n["src"].(string); ok {
+				if e, err := decode(src); err == nil && len(e) == 1 {
+					*out = append(*out, function{start: e[0].start, end: e[0].start + e[0].length, name: name})
+				}
+			}
+		}
+		for _, child := range n {
+			collectFunctions(child, contract, out)
+		}
+	}
+}
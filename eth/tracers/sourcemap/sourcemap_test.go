@@ -0,0 +1,147 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package sourcemap
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/core/vm"
+)
+
+func TestDecode(t *testing.T) {
+	entries, err := decode("1:2:1;:9;2:1:2;;-1:0:-1:o;:::i")
+	require.NoError(t, err)
+	assert.Equal(t, []entry{
+		{start: 1, length: 2, file: 1},
+		{start: 1, length: 9, file: 1},
+		{start: 2, length: 1, file: 2},
+		{start: 2, length: 1, file: 2},
+		{start: -1, length: 0, file: -1},
+		{start: -1, length: 0, file: -1},
+	}, entries)
+
+	_, err = decode("1:x:0")
+	require.Error(t, err)
+}
+
+func TestInstructionIndexes(t *testing.T) {
+	// PUSH1 01 PUSH2 0203 ADD PUSH0 STOP
+	indexes := instructionIndexes([]byte{0x60, 0x01, 0x61, 0x02, 0x03, 0x01, 0x5f, 0x00})
+	assert.Equal(t, []int32{0, 0, 1, 1, 1, 2, 3, 4}, indexes)
+}
+
+const testSource = `pragma solidity ^0.8.0;
+contract C {
+    uint x;
+    function set(uint v) public {
+        x = v;
+    }
+}
+`
+
+func testOutput() string {
+	var (
+		contract = strings.Index(testSource, "contract")
+		function = strings.Index(testSource, "function")
+		assign   = strings.Index(testSource, "x = v")
+	)
+	ast := fmt.Sprintf(`{"nodeType":"SourceUnit","src":"0:%d:0","nodes":[
+		{"nodeType":"ContractDefinition","name":"C","src":"%d:%d:0","nodes":[
+			{"nodeType":"FunctionDefinition","name":"set","kind":"function","src":"%d:%d:0"}]}]}`,
+		len(testSource), contract, len(testSource)-contract-1, function, assign+len("x = v;\n    }")-function)
+	// The runtime code is PUSH1 <immutable> PUSH1 00 SSTORE STOP followed by
+	// metadata, the creation code PUSH1 00 PUSH1 00 RETURN.
+	return fmt.Sprintf(`{
+		"sources": {"c.sol": {"id": 0, "ast": %s}},
+		"contracts": {"c.sol": {
+			"C": {"evm": {
+				"bytecode": {"object": "60006000f3", "sourceMap": "%d:%d:0;;"},
+				"deployedBytecode": {
+					"object": "602a60005500a00001",
+					"sourceMap": "%d:5:0;;;-1:0:-1",
+					"immutableReferences": {"3": [{"start": 1, "length": 1}]}
+				}
+			}},
+			"I": {"evm": {"bytecode": {"object": "", "sourceMap": ""}, "deployedBytecode": {"object": "", "sourceMap": ""}}}
+		}}
+	}`, ast, contract, len(testSource)-contract-1, assign)
+}
+
+func testContract(code []byte) *vm.Contract {
+	contract := vm.NewContract(vm.AccountRef{}, libcommon.Address{}, nil, 0, false, nil)
+	contract.Code = code
+	return contract
+}
+
+func TestRegistry(t *testing.T) {
+	// The immutable and the metadata differ from the compiler output.
+	deployed := testContract([]byte{0x60, 0x07, 0x60, 0x00, 0x55, 0x00, 0xa1, 0x00, 0x01})
+
+	r := NewRegistry()
+	assert.Nil(t, r.Lookup(deployed))
+	names, err := r.Register([]byte(testOutput()), map[string]string{"c.sol": testSource})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c.sol:C"}, names)
+	assert.Equal(t, 1, r.Len())
+
+	runtime := r.Lookup(deployed)
+	require.NotNil(t, runtime)
+	assert.Equal(t, "c.sol:C", runtime.Contract)
+	want := &Location{File: "c.sol", Line: 5, Column: 9, Function: "C.set"}
+	for _, pc := range []uint64{0, 1, 4} {
+		assert.Equal(t, want, runtime.Locate(pc), "pc %d", pc)
+	}
+	assert.Nil(t, runtime.Locate(5), "code without source")
+	assert.Nil(t, runtime.Locate(8), "past the source map")
+	assert.Nil(t, runtime.Locate(100), "past the code")
+
+	// The creation code is followed by the constructor arguments.
+	creation := r.Lookup(testContract([]byte{0x60, 0x00, 0x60, 0x00, 0xf3, 0x01, 0x02}))
+	require.NotNil(t, creation)
+	assert.Equal(t, &Location{File: "c.sol", Line: 2, Column: 1, Function: ""}, creation.Locate(2))
+
+	unknown := testContract([]byte{0x60, 0x07, 0x60, 0x01, 0x55, 0x00, 0xa0, 0x00, 0x01})
+	assert.Nil(t, r.Lookup(unknown))
+	assert.Nil(t, r.Lookup(unknown).Locate(0))
+}
+
+func TestRegistryWithoutContent(t *testing.T) {
+	r := NewRegistry()
+	_, err := r.Register([]byte(testOutput()), nil)
+	require.NoError(t, err)
+	code := r.Lookup(testContract([]byte{0x60, 0x2a, 0x60, 0x00, 0x55, 0x00, 0xa0, 0x00, 0x01}))
+	require.NotNil(t, code)
+	assert.Equal(t, &Location{File: "c.sol", Function: "C.set"}, code.Locate(2))
+
+	_, err = r.Register([]byte(`{"contracts": {}}`), nil)
+	require.Error(t, err)
+}
+
+func TestRegistryResolvedBounded(t *testing.T) {
+	r := NewRegistry()
+	for i := 0; i < resolvedCacheSize+10; i++ {
+		assert.Nil(t, r.Lookup(testContract([]byte{0x61, byte(i >> 8), byte(i), 0x00})))
+	}
+	assert.Equal(t, resolvedCacheSize, r.resolved.Len())
+}
@@ -136,3 +136,110 @@ func TestPrestateTracerCreate2(t *testing.T) {
 		t.Fatalf("Expected 0x60f3f640a8508fc6a86d45df051962668e1e8ac7 in result")
 	}
 }
+
+func TestProfileTracer(t *testing.T) {
+	var (
+		contract = libcommon.HexToAddress("0x00000000000000000000000000000000000000aa")
+		library  = libcommon.HexToAddress("0x00000000000000000000000000000000000000bb")
+	)
+	unsignedTx := types.NewTransaction(1, contract, uint256.NewInt(0), 5000000, uint256.NewInt(1), hexutil.MustDecode("0xaabbccdd"))
+
+	privateKeyECDSA, err := ecdsa.GenerateKey(crypto.S256(), rand.Reader)
+	require.NoError(t, err)
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	txn, err := types.SignTx(unsignedTx, *signer, privateKeyECDSA)
+	require.NoError(t, err)
+	origin, _ := signer.Sender(txn)
+	txContext := evmtypes.TxContext{
+		Origin:   origin,
+		GasPrice: uint256.NewInt(1),
+	}
+	context := evmtypes.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    consensus.Transfer,
+		Coinbase:    libcommon.Address{},
+		BlockNumber: 8000000,
+		Time:        5,
+		Difficulty:  big.NewInt(0x30000),
+		GasLimit:    uint64(6000000),
+		BaseFee:     uint256.NewInt(0),
+		BlobBaseFee: uint256.NewInt(50000),
+	}
+	alloc := types.GenesisAlloc{
+		// SLOAD slot 0 twice, SSTORE 1 at slot 1, then call the library
+		contract: {
+			Nonce:   1,
+			Code:    hexutil.MustDecode("0x600054506000545060016001556000600060006000600073" + library.Hex()[2:] + "5af15000"),
+			Balance: big.NewInt(1),
+		},
+		// SLOAD slot 0
+		library: {
+			Nonce: 1,
+			Code:  hexutil.MustDecode("0x6000545000"),
+		},
+		origin: {
+			Nonce:   1,
+			Balance: big.NewInt(500000000000000),
+		},
+	}
+
+	m := mock.Mock(t)
+	tx, err := m.DB.BeginRw(m.Ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	rules := params.AllProtocolChanges.Rules(context.BlockNumber, context.Time)
+	statedb, _ := tests.MakePreState(rules, tx, alloc, context.BlockNumber)
+
+	tracer, err := tracers.New("profileTracer", new(tracers.Context), nil)
+	require.NoError(t, err)
+	evm := vm.NewEVM(context, txContext, statedb, params.AllProtocolChanges, vm.Config{Debug: true, Tracer: tracer})
+
+	msg, err := txn.AsMessage(*signer, nil, rules)
+	require.NoError(t, err)
+	st := core.NewStateTransition(evm, msg, new(core.GasPool).AddGas(txn.GetGas()).AddBlobGas(txn.GetBlobGas()))
+	result, err := st.TransitionDb(false, false)
+	require.NoError(t, err)
+	require.NoError(t, result.Err)
+
+	res, err := tracer.GetResult()
+	require.NoError(t, err)
+	var profile struct {
+		GasUsed      uint64 `json:"gasUsed"`
+		IntrinsicGas uint64 `json:"intrinsicGas"`
+		Contracts    []struct {
+			Address  libcommon.Address `json:"address"`
+			Selector string            `json:"selector"`
+			Gas      uint64            `json:"gas"`
+			Opcodes  map[string]struct {
+				Count uint64 `json:"count"`
+				Gas   uint64 `json:"gas"`
+			} `json:"opcodes"`
+			SLoad  struct{ Cold, Warm uint64 } `json:"sload"`
+			SStore struct{ Cold, Warm uint64 } `json:"sstore"`
+		} `json:"contracts"`
+		Folded []string `json:"folded"`
+	}
+	require.NoError(t, json.Unmarshal(res, &profile))
+
+	require.Equal(t, result.UsedGas, profile.GasUsed)
+	require.Len(t, profile.Contracts, 2)
+	total := profile.IntrinsicGas
+	for _, c := range profile.Contracts {
+		total += c.Gas
+	}
+	require.Equal(t, profile.GasUsed, total)
+
+	caller, callee := profile.Contracts[0], profile.Contracts[1]
+	require.Equal(t, contract, caller.Address)
+	require.Equal(t, "0xaabbccdd", caller.Selector)
+	require.Equal(t, uint64(1), caller.SLoad.Cold)
+	require.Equal(t, uint64(1), caller.SLoad.Warm)
+	require.Equal(t, uint64(1), caller.SStore.Cold)
+	require.Equal(t, uint64(2), caller.Opcodes["SLOAD"].Count)
+	require.Equal(t, params.ColdSloadCostEIP2929+params.WarmStorageReadCostEIP2929, caller.Opcodes["SLOAD"].Gas)
+
+	require.Equal(t, library, callee.Address)
+	require.Equal(t, "fallback", callee.Selector)
+	require.Equal(t, uint64(1), callee.SLoad.Cold)
+	require.Contains(t, profile.Folded, "0x00000000000000000000000000000000000000aa:0xaabbccdd;0x00000000000000000000000000000000000000bb:fallback;SLOAD 2100")
+}
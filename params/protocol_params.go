@@ -41,6 +41,9 @@ const (
 	LogDataGas            uint64 = 8     // Per byte in a LOG* operation's data.
 	CallStipend           uint64 = 2300  // Free gas given at beginning of call.
 
+	MinRetainedGasEIP7069 uint64 = 5000 // Minimum gas retained by the caller of an EXT*CALL instruction
+	MinCalleeGasEIP7069   uint64 = 2300 // Minimum gas passed to the callee of an EXT*CALL instruction, below which the call fails
+
 	Keccak256Gas     uint64 = 30 // Once per KECCAK256 operation.
 	Keccak256WordGas uint64 = 6  // Once per word of the KECCAK256 operation's data.
 	InitCodeWordGas  uint64 = 2  // Once per word of the init code when creating a contract.
@@ -335,7 +335,7 @@ func (h *handler) startCallProc(fn func(*callProc)) {
 		ctx, cancel := context.WithCancel(h.rootCtx)
 		defer h.callWG.Done()
 		defer cancel()
-		fn(&callProc{ctx: ctx})
+		fn(&callProc{ctx: withCallReadView(ctx)})
 	}()
 }
 
@@ -532,10 +532,16 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage, stream *jso
 func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value, stream *jsoniter.Stream) *jsonrpcMessage {
 	if !callb.streamable {
 		result, err := callb.call(ctx, msg.Method, args, stream)
+		var resp *jsonrpcMessage
 		if err != nil {
-			return msg.errorResponse(err)
+			resp = msg.errorResponse(err)
+		} else {
+			resp = msg.response(result)
+		}
+		if head, ok := ReadViewFromContext(ctx).responseHead(); ok {
+			resp.ReadViewHead = &head
 		}
-		return msg.response(result)
+		return resp
 	}
 
 	stream.WriteObjectStart()
@@ -554,6 +560,11 @@ func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *cal
 		stream.WriteMore()
 		HandleError(err, stream)
 	}
+	if head, ok := ReadViewFromContext(ctx).responseHead(); ok {
+		stream.WriteMore()
+		stream.WriteObjectField("readViewHead")
+		stream.WriteUint64(head)
+	}
 	stream.WriteObjectEnd()
 	stream.Flush()
 	return nil
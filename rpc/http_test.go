@@ -20,9 +20,12 @@
 package rpc
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/erigontech/erigon-lib/log/v3"
@@ -169,3 +172,91 @@ func TestHTTPPeerInfo(t *testing.T) {
 		t.Errorf("wrong HTTP.Origin %q", info.HTTP.UserAgent)
 	}
 }
+
+type readViewService struct{ resolves atomic.Int32 }
+
+func (s *readViewService) Head(ctx context.Context) (uint64, error) {
+	v := ReadViewFromContext(ctx)
+	if v == nil {
+		return 0, nil
+	}
+	return v.Head(func(tag BlockNumber) (uint64, error) {
+		s.resolves.Add(1)
+		if tag == SafeBlockNumber {
+			return 43, nil
+		}
+		return 42, nil
+	})
+}
+
+func TestHTTPReadView(t *testing.T) {
+	logger := log.New()
+	s := NewServer(50, false /* traceRequests */, false /* debugSingleRequests */, true, logger, 100)
+	defer s.Stop()
+	service := new(readViewService)
+	if err := s.RegisterName("test", service); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	post := func(view string) *http.Response {
+		t.Helper()
+		body := `[{"jsonrpc":"2.0","id":1,"method":"test_head"},{"jsonrpc":"2.0","id":2,"method":"test_head"}]`
+		request, err := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		request.Header.Set("Content-Type", contentType)
+		if view != "" {
+			request.Header.Set(ReadViewHeader, view)
+		}
+		resp, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	for _, tt := range []struct {
+		view, head   string
+		wantResolves int32
+	}{
+		{view: "finalized", head: "42", wantResolves: 1},
+		{view: "safe", head: "43", wantResolves: 1},
+		{view: "", head: "0", wantResolves: 0},
+	} {
+		service.resolves.Store(0)
+		resp := post(tt.view)
+		var res []jsonrpcMessage
+		err := json.NewDecoder(resp.Body).Decode(&res)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		confirmStatusCode(t, resp.StatusCode, http.StatusOK)
+		if len(res) != 2 {
+			t.Fatalf("view %q: wrong number of responses %d", tt.view, len(res))
+		}
+		// all calls of batch must be served at the same head
+		for _, msg := range res {
+			if string(msg.Result) != tt.head {
+				t.Errorf("view %q: wrong result %s, want %s", tt.view, msg.Result, tt.head)
+			}
+		}
+		if got := service.resolves.Load(); got != tt.wantResolves {
+			t.Errorf("view %q: head resolved %d times, want %d", tt.view, got, tt.wantResolves)
+		}
+		wantHeader := tt.head
+		if tt.view == "" {
+			wantHeader = ""
+		}
+		if got := resp.Header.Get(ReadViewHeadHeader); got != wantHeader {
+			t.Errorf("view %q: wrong %s header %q, want %q", tt.view, ReadViewHeadHeader, got, wantHeader)
+		}
+	}
+
+	resp := post("latest")
+	resp.Body.Close()
+	confirmStatusCode(t, resp.StatusCode, http.StatusBadRequest)
+}
@@ -58,6 +58,8 @@ type jsonrpcMessage struct {
 	Params  json.RawMessage `json:"params,omitempty"`
 	Error   *jsonError      `json:"error,omitempty"`
 	Result  json.RawMessage `json:"result,omitempty"`
+
+	ReadViewHead *uint64 `json:"readViewHead,omitempty"` // see ReadViewHeadHeader
 }
 
 func (msg *jsonrpcMessage) isNotification() bool {
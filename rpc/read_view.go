@@ -0,0 +1,102 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+const (
+	// ReadViewHeader - request header: "finalized" or "safe". Pins the request (HTTP) or every call of the connection (WebSocket)
+	// to the given head: "latest"/"pending" and open-ended ranges are clamped to it, and explicit blocks above it are rejected.
+	ReadViewHeader = "Erigon-Read-View"
+	// ReadViewHeadHeader - response header: number of the block the request was served at.
+	// WebSocket responses have no headers: each response carries the number in "readViewHead" member instead.
+	ReadViewHeadHeader = "Erigon-Read-View-Head"
+)
+
+// ParseReadView - parses value of ReadViewHeader. Returns 0 if header is empty.
+func ParseReadView(v string) (BlockNumber, error) {
+	switch v {
+	case "":
+		return 0, nil
+	case "finalized":
+		return FinalizedBlockNumber, nil
+	case "safe":
+		return SafeBlockNumber, nil
+	default:
+		return 0, fmt.Errorf("invalid %s header: %q, expected \"finalized\" or \"safe\"", ReadViewHeader, v)
+	}
+}
+
+// ReadView - head which all calls of one request are served at. Resolved lazily (on first use) and only once:
+// all calls of batch see the same head.
+type ReadView struct {
+	Tag BlockNumber // FinalizedBlockNumber or SafeBlockNumber
+
+	once   sync.Once
+	head   uint64
+	err    error
+	pinned bool
+	onPin  func(head uint64) // nil: head is reported in response body, see responseHead
+}
+
+// Head - returns pinned head, calls `resolve` only if head is not pinned yet
+func (v *ReadView) Head(resolve func(tag BlockNumber) (uint64, error)) (uint64, error) {
+	v.once.Do(func() {
+		v.head, v.err = resolve(v.Tag)
+		v.pinned = v.err == nil
+		if v.pinned && v.onPin != nil {
+			v.onPin(v.head)
+		}
+	})
+	return v.head, v.err
+}
+
+// responseHead - head to report in response of a finished call, if it was pinned per call (WebSocket)
+func (v *ReadView) responseHead() (uint64, bool) {
+	if v == nil || v.onPin != nil || !v.pinned {
+		return 0, false
+	}
+	return v.head, true
+}
+
+type readViewContextKey struct{}
+
+func contextWithReadView(ctx context.Context, tag BlockNumber, onPin func(head uint64)) context.Context {
+	return context.WithValue(ctx, readViewContextKey{}, &ReadView{Tag: tag, onPin: onPin})
+}
+
+// ReadViewFromContext - returns nil if request is not pinned by ReadViewHeader
+func ReadViewFromContext(ctx context.Context) *ReadView {
+	v, _ := ctx.Value(readViewContextKey{}).(*ReadView)
+	return v
+}
+
+// withCallReadView - WebSocket connections are long-lived: pin each call separately
+func withCallReadView(ctx context.Context) context.Context {
+	if ReadViewFromContext(ctx) != nil {
+		return ctx
+	}
+	tag, err := ParseReadView(PeerInfoFromContext(ctx).HTTP.ReadView)
+	if err != nil || tag == 0 {
+		return ctx
+	}
+	return contextWithReadView(ctx, tag, nil)
+}
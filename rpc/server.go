@@ -198,6 +198,7 @@ type PeerInfo struct {
 		UserAgent string
 		Origin    string
 		Host      string
+		ReadView  string // see ReadViewHeader
 	}
 }
 
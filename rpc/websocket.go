@@ -62,6 +62,10 @@ func (s *Server) WebsocketHandler(allowedOrigins []string, jwtSecret []byte, com
 		if jwtSecret != nil && !CheckJwtSecret(w, r, jwtSecret) {
 			return
 		}
+		if _, err := ParseReadView(r.Header.Get(ReadViewHeader)); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		conn, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
 			logger.Warn("WebSocket upgrade failed", "err", err)
@@ -269,6 +273,7 @@ func NewWebsocketCodec(conn *websocket.Conn, host string, req http.Header) Serve
 	if req != nil {
 		wc.info.HTTP.Origin = req.Get("Origin")
 		wc.info.HTTP.UserAgent = req.Get("User-Agent")
+		wc.info.HTTP.ReadView = req.Get(ReadViewHeader)
 	}
 	// Start pinger.
 	wc.wg.Add(1)
@@ -119,6 +119,56 @@ func TestWebsocketLargeCall(t *testing.T) {
 	}
 }
 
+// This test checks that each call of WebSocket connection is pinned separately and reports its head.
+func TestWebsocketReadView(t *testing.T) {
+	t.Parallel()
+	logger := log.New()
+
+	srv := NewServer(50, false /* traceRequests */, false /* debugSingleRequests */, true, logger, 100)
+	defer srv.Stop()
+	service := new(readViewService)
+	if err := srv.RegisterName("test", service); err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(srv.WebsocketHandler([]string{"*"}, nil, false, logger))
+	defer httpsrv.Close()
+	wsURL := "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+
+	for _, tt := range []struct {
+		view string
+		head *uint64
+	}{
+		{view: "finalized", head: func() *uint64 { h := uint64(42); return &h }()},
+		{view: "", head: nil},
+	} {
+		header := http.Header{}
+		if tt.view != "" {
+			header.Set(ReadViewHeader, tt.view)
+		}
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 2; i++ {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"method":"test_head"}`)); err != nil {
+				t.Fatal(err)
+			}
+			var resp jsonrpcMessage
+			if err := conn.ReadJSON(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if (resp.ReadViewHead == nil) != (tt.head == nil) || (tt.head != nil && *resp.ReadViewHead != *tt.head) {
+				t.Fatalf("view %q: wrong readViewHead %v, want %v", tt.view, resp.ReadViewHead, tt.head)
+			}
+		}
+		conn.Close()
+	}
+	// each call is pinned separately
+	if got := service.resolves.Load(); got != 2 {
+		t.Errorf("head resolved %d times, want 2", got)
+	}
+}
+
 // This test checks that client handles WebSocket ping frames correctly.
 func TestClientWebsocketPing(t *testing.T) {
 	if runtime.GOOS == "windows" {
@@ -0,0 +1,83 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/erigontech/erigon-lib/common/datadir"
+	"github.com/erigontech/erigon-lib/kv/temporal/temporaltest"
+
+	"github.com/erigontech/erigon/core/vm"
+)
+
+func TestEOF(t *testing.T) {
+	et := new(testMatcher)
+
+	et.walk(t, eofTestDir, func(t *testing.T, name string, test *EOFTest) {
+		if err := et.checkFailure(t, test.Run()); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestCheckEOFResult(t *testing.T) {
+	invalid := fmt.Errorf("%w: at pos 0", vm.ErrInvalidMagic)
+	for i, tt := range []struct {
+		result eofResult
+		err    error
+		ok     bool
+	}{
+		{eofResult{Result: true}, nil, true},
+		{eofResult{Result: true}, invalid, false},
+		{eofResult{Exception: "EOFException.INVALID_MAGIC"}, nil, false},
+		{eofResult{Exception: "EOFException.INVALID_MAGIC"}, invalid, true},
+		{eofResult{Exception: "EOFException.INVALID_VERSION|EOFException.INVALID_MAGIC"}, invalid, true},
+		{eofResult{Exception: "EOFException.INVALID_VERSION"}, invalid, false},
+		{eofResult{Exception: "EOFException.UNKNOWN"}, invalid, false},
+	} {
+		if err := checkEOFResult(tt.result, tt.err); (err == nil) != tt.ok {
+			t.Errorf("test %d: unexpected result %v", i, err)
+		}
+	}
+}
+
+func TestEOFExecution(t *testing.T) {
+	st := new(testMatcher)
+
+	dirs := datadir.New(t.TempDir())
+	db, _ := temporaltest.NewTestDB(t, dirs)
+	st.walk(t, eofStateTestDir, func(t *testing.T, name string, test *StateTest) {
+		for _, subtest := range test.Subtests() {
+			subtest := subtest
+			key := fmt.Sprintf("%s/%d", subtest.Fork, subtest.Index)
+			t.Run(key, func(t *testing.T) {
+				tx, err := db.BeginRw(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer tx.Rollback()
+				_, _, err = test.Run(tx, subtest, vm.Config{}, dirs)
+				if err := st.checkFailure(t, err); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,142 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/erigontech/erigon-lib/common/hexutility"
+
+	"github.com/erigontech/erigon/core/vm"
+)
+
+// EOFTest checks the validation of EOF containers.
+type EOFTest struct {
+	Vectors map[string]eofVector `json:"vectors"`
+}
+
+type eofVector struct {
+	Code          hexutility.Bytes     `json:"code"`
+	ContainerKind string               `json:"containerKind"`
+	Results       map[string]eofResult `json:"results"`
+}
+
+type eofResult struct {
+	Exception string `json:"exception"`
+	Result    bool   `json:"result"`
+}
+
+// errEOFNotActive is the validation result of every container before Osaka,
+// where code starting with 0xEF is rejected by EIP-3541.
+var errEOFNotActive = errors.New("EOF is not active")
+
+// eofExceptions maps the exceptions of the test fixtures to the validation
+// errors of the vm package. A vector expecting an exception missing from the
+// map fails, the exception has to be mapped first.
+var eofExceptions = map[string]error{
+	"EOFException.INVALID_MAGIC":                          vm.ErrInvalidMagic,
+	"EOFException.INVALID_VERSION":                        vm.ErrInvalidVersion,
+	"EOFException.MISSING_TYPE_HEADER":                    vm.ErrMissingTypeHeader,
+	"EOFException.INVALID_TYPE_SECTION_SIZE":              vm.ErrInvalidTypeSize,
+	"EOFException.MISSING_CODE_HEADER":                    vm.ErrMissingCodeHeader,
+	"EOFException.MISSING_DATA_SECTION":                   vm.ErrMissingDataHeader,
+	"EOFException.MISSING_TERMINATOR":                     vm.ErrMissingTerminator,
+	"EOFException.ZERO_SECTION_SIZE":                      vm.ErrInvalidCodeSize,
+	"EOFException.TOPLEVEL_CONTAINER_TRUNCATED":           vm.ErrInvalidContainerSize,
+	"EOFException.INVALID_FIRST_SECTION_TYPE":             vm.ErrInvalidSection0Type,
+	"EOFException.INPUTS_OUTPUTS_NUM_ABOVE_LIMIT":         vm.ErrTooManyInputs,
+	"EOFException.MAX_STACK_HEIGHT_ABOVE_LIMIT":           vm.ErrTooLargeMaxStackHeight,
+	"EOFException.UNDEFINED_INSTRUCTION":                  vm.ErrUndefinedInstruction,
+	"EOFException.TRUNCATED_INSTRUCTION":                  vm.ErrTruncatedImmediate,
+	"EOFException.INVALID_CODE_SECTION_INDEX":             vm.ErrInvalidSectionArgument,
+	"EOFException.CALLF_TO_NON_RETURNING":                 vm.ErrInvalidCallArgument,
+	"EOFException.INVALID_DATALOADN_INDEX":                vm.ErrInvalidDataloadNArgument,
+	"EOFException.INVALID_RJUMP_DESTINATION":              vm.ErrInvalidJumpDest,
+	"EOFException.INVALID_MAX_STACK_HEIGHT":               vm.ErrInvalidMaxStackHeight,
+	"EOFException.MISSING_STOP_OPCODE":                    vm.ErrInvalidCodeTermination,
+	"EOFException.STACK_UNDERFLOW":                        vm.ErrEOFStackUnderflow,
+	"EOFException.STACK_OVERFLOW":                         vm.ErrEOFStackOverflow,
+	"EOFException.UNREACHABLE_INSTRUCTIONS":               vm.ErrUnreachableCode,
+	"EOFException.UNREACHABLE_CODE_SECTIONS":              vm.ErrUnreachableCodeSections,
+	"EOFException.INVALID_NON_RETURNING_FLAG":             vm.ErrInvalidNonReturning,
+	"EOFException.JUMPF_DESTINATION_INCOMPATIBLE_OUTPUTS": vm.ErrJumpfIncompatibleOutputs,
+	"EOFException.INVALID_CONTAINER_SECTION_INDEX":        vm.ErrInvalidContainerArgument,
+	"EOFException.EOFCREATE_WITH_TRUNCATED_CONTAINER":     vm.ErrEOFCreateWithTruncatedSection,
+	"EOFException.ORPHAN_SUBCONTAINER":                    vm.ErrOrphanedSubcontainer,
+	"EOFException.INCOMPATIBLE_CONTAINER_KIND":            vm.ErrIncompatibleContainerKind,
+	"EOFException.AMBIGUOUS_CONTAINER_KIND":               vm.ErrAmbiguousContainer,
+}
+
+func (et *EOFTest) Run() error {
+	names := make([]string, 0, len(et.Vectors))
+	for name := range et.Vectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		vector := et.Vectors[name]
+		forks := make([]string, 0, len(vector.Results))
+		for fork := range vector.Results {
+			forks = append(forks, fork)
+		}
+		sort.Strings(forks)
+		for _, fork := range forks {
+			config, ok := Forks[fork]
+			if !ok {
+				return UnsupportedForkError{fork}
+			}
+			err := errEOFNotActive
+			if config.IsOsaka(0) {
+				_, err = vm.ValidateEOFContainer(vector.Code, vector.ContainerKind == "INITCODE")
+			}
+			if err := checkEOFResult(vector.Results[fork], err); err != nil {
+				return fmt.Errorf("vector %s, fork %s: %w", name, fork, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkEOFResult compares the validation error of a container with the
+// expected result, an exception may list several alternatives separated by |.
+func checkEOFResult(result eofResult, err error) error {
+	if result.Result {
+		if err != nil {
+			return fmt.Errorf("unexpected error: %w", err)
+		}
+		return nil
+	}
+	if err == nil {
+		return fmt.Errorf("expected error %v, got none", result.Exception)
+	}
+	if result.Exception == "" {
+		return nil
+	}
+	for _, exception := range strings.Split(result.Exception, "|") {
+		want, ok := eofExceptions[exception]
+		if !ok {
+			return fmt.Errorf("unknown exception %v", exception)
+		}
+		if errors.Is(err, want) {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected error %v, got %w", result.Exception, err)
+}
@@ -30,6 +30,9 @@ func TestExecutionSpec(t *testing.T) {
 	log.Root().SetHandler(log.LvlFilterHandler(log.LvlError, log.StderrHandler))
 
 	bt := new(testMatcher)
+	// EOF validation and execution tests are run by TestEOF and TestEOFExecution
+	bt.skipLoad("^eof_tests/")
+	bt.skipLoad("^eof_state_tests/")
 
 	dir := filepath.Join(".", "execution-spec-tests")
 	checkStateRoot := true
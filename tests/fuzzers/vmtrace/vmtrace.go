@@ -0,0 +1,549 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+// Package vmtrace checks the semantics of the opcodes of the interpreter on
+// the EIP-3155 traces of programs executed at each fork: random programs are
+// checked against a model of the opcodes, and the programs of testdata against
+// their recorded traces. The recorded traces are regression snapshots of this
+// interpreter, not traces of an independent EVM: they catch changes of
+// behaviour, while the correctness of each step rests on the model.
+package vmtrace
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/holiman/uint256"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/common/math"
+	"github.com/erigontech/erigon/core/state"
+	"github.com/erigontech/erigon/core/tracing"
+	"github.com/erigontech/erigon/core/types/accounts"
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/core/vm/runtime"
+	"github.com/erigontech/erigon/crypto"
+	"github.com/erigontech/erigon/eth/tracers/logger"
+	"github.com/erigontech/erigon/tests"
+)
+
+// forks are the forks the programs are executed at, in order.
+var forks = []string{"Frontier", "Homestead", "Byzantium", "ConstantinopleFix", "Istanbul", "Berlin", "London", "Shanghai", "Cancun", "Prague"}
+
+const (
+	gasLimit = 1_000_000
+	// fuzzSteps bounds the steps of the random programs, which may loop until
+	// out of gas, with as many items on the stack
+	fuzzSteps = 1_000
+)
+
+var (
+	origin = libcommon.HexToAddress("0x0a")
+	// contract is the address runtime.Execute executes the code at.
+	contract = libcommon.BytesToAddress([]byte("contract"))
+	// random is the PREVRANDAO of the forks after the merge.
+	random = libcommon.HexToHash("0x5eed")
+)
+
+// program is a program with the state it is executed on.
+type program struct {
+	Code     []byte
+	Input    []byte
+	Accounts []account // accounts besides the executing one
+}
+
+type account struct {
+	Address libcommon.Address
+	Balance uint64
+	Code    []byte
+	Storage map[libcommon.Hash]libcommon.Hash
+}
+
+// accountAddress is the address of the i-th account of a program.
+func accountAddress(i int) libcommon.Address {
+	return libcommon.BigToAddress(big.NewInt(int64(0xacc0 + i)))
+}
+
+// decodeProgram decodes a program from random input: the number of accounts,
+// and for each its balance, storage slots and code, followed by the input and
+// the code of the program.
+func decodeProgram(input []byte) *program {
+	r := bytes.NewReader(input)
+	next := func(n int) []byte {
+		b := make([]byte, min(n, r.Len()))
+		_, _ = io.ReadFull(r, b)
+		return b
+	}
+	nextByte := func() int {
+		if b := next(1); len(b) == 1 {
+			return int(b[0])
+		}
+		return 0
+	}
+
+	p := &program{}
+	for i, n := 0, nextByte()%4; i < n; i++ {
+		a := account{Address: accountAddress(i), Balance: uint64(nextByte()), Storage: map[libcommon.Hash]libcommon.Hash{}}
+		for j, slots := 0, nextByte()%4; j < slots; j++ {
+			a.Storage[libcommon.Hash{31: byte(nextByte())}] = libcommon.Hash{31: byte(nextByte())}
+		}
+		a.Code = next(nextByte() % 32)
+		p.Accounts = append(p.Accounts, a)
+	}
+	p.Input = next(nextByte() % 64)
+	p.Code = next(r.Len())
+	return p
+}
+
+// execute executes a program at a fork, and returns its EIP-3155 trace. When
+// fuzzing, the trace leaves out the memory, and the execution is cancelled
+// after about fuzzSteps steps.
+func execute(p *program, fork string, fuzzing bool) ([]byte, error) {
+	config, ok := tests.Forks[fork]
+	if !ok {
+		return nil, fmt.Errorf("unknown fork %s", fork)
+	}
+	ibs := state.New(emptyState{})
+	ibs.CreateAccount(contract, false)
+	ibs.SetBalance(contract, uint256.NewInt(1_000), tracing.BalanceChangeUnspecified)
+	for _, a := range p.Accounts {
+		ibs.CreateAccount(a.Address, false)
+		ibs.SetBalance(a.Address, uint256.NewInt(a.Balance), tracing.BalanceChangeUnspecified)
+		ibs.SetCode(a.Address, a.Code)
+		for key, value := range a.Storage {
+			ibs.SetState(a.Address, &key, *uint256.NewInt(0).SetBytes(value[:]))
+		}
+	}
+
+	var (
+		trace  bytes.Buffer
+		tracer vm.EVMLogger = logger.NewJSONLogger(nil, &trace)
+	)
+	if fuzzing {
+		tracer = &boundedLogger{JSONLogger: logger.NewJSONLogger(&logger.LogConfig{DisableMemory: true}, &trace)}
+	}
+	cfg := &runtime.Config{
+		ChainConfig: config,
+		Difficulty:  big.NewInt(0x20000),
+		Origin:      origin,
+		Coinbase:    libcommon.HexToAddress("0xc0"),
+		BlockNumber: big.NewInt(1),
+		Time:        big.NewInt(1),
+		GasLimit:    gasLimit,
+		GasPrice:    uint256.NewInt(10),
+		Value:       new(uint256.Int),
+		BaseFee:     uint256.NewInt(7),
+		BlobBaseFee: uint256.NewInt(1),
+		GetHashFn:   blockHash,
+		EVMConfig:   vm.Config{Debug: true, Tracer: tracer},
+		State:       ibs,
+	}
+	if config.ShanghaiTime != nil {
+		cfg.Random = &random
+	}
+	_, _, err := runtime.Execute(p.Code, p.Input, cfg, "")
+	return trace.Bytes(), err
+}
+
+// blockHash is the hash BLOCKHASH returns for block n, the one of the runtime
+// defaults.
+func blockHash(n uint64) libcommon.Hash {
+	return libcommon.BytesToHash(crypto.Keccak256([]byte(strconv.FormatUint(n, 10))))
+}
+
+// boundedLogger is a JSON logger cancelling the execution after fuzzSteps
+// steps. The interpreter only checks it every 1000 steps of a call frame, so
+// the steps past the bound are not logged.
+type boundedLogger struct {
+	*logger.JSONLogger
+	env   *vm.EVM
+	steps int
+}
+
+func (l *boundedLogger) CaptureStart(env *vm.EVM, from libcommon.Address, to libcommon.Address, precompile bool, create bool, input []byte, gas uint64, value *uint256.Int, code []byte) {
+	l.env = env
+	l.JSONLogger.CaptureStart(env, from, to, precompile, create, input, gas, value, code)
+}
+
+func (l *boundedLogger) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if l.steps++; l.steps == fuzzSteps {
+		l.env.Cancel()
+	}
+	if l.steps > fuzzSteps {
+		return
+	}
+	l.JSONLogger.CaptureState(pc, op, gas, cost, scope, rData, depth, err)
+}
+
+// result is the last line of a trace.
+type result struct {
+	Output  string              `json:"output"`
+	GasUsed math.HexOrDecimal64 `json:"gasUsed"`
+	Err     string              `json:"error,omitempty"`
+}
+
+// step is a step of a trace.
+type step struct {
+	logger.StructLog
+	Error string // the error of the step, which logger.StructLog does not decode
+}
+
+// parseTrace parses the steps and the result of a trace.
+func parseTrace(trace []byte) (steps []step, res result, err error) {
+	scanner := bufio.NewScanner(bytes.NewReader(trace))
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if bytes.Contains(line, []byte(`"gasUsed"`)) {
+			err = json.Unmarshal(line, &res)
+			return steps, res, err
+		}
+		var s step
+		if err := json.Unmarshal(line, &s.StructLog); err != nil {
+			return nil, res, err
+		}
+		if err := json.Unmarshal(line, &struct {
+			Error *string `json:"error"`
+		}{&s.Error}); err != nil {
+			return nil, res, err
+		}
+		steps = append(steps, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, res, err
+	}
+	return nil, res, errors.New("trace without result")
+}
+
+// checkTrace checks the steps of a trace against the model of the opcodes: the
+// gas, the memory and the stack after each step, when it is followed by a step
+// of the same call frame.
+func checkTrace(code, input []byte, steps []step) error {
+	for i := range steps {
+		cur := &steps[i]
+		if cur.MemorySize%32 != 0 || (len(cur.Memory) > 0 && len(cur.Memory) != cur.MemorySize) {
+			return fmt.Errorf("step %d (%s): memory size %d, memory of %d bytes", i, cur.Op, cur.MemorySize, len(cur.Memory))
+		}
+		if len(cur.Stack) > 1024 {
+			return fmt.Errorf("step %d (%s): stack of %d items", i, cur.Op, len(cur.Stack))
+		}
+		if i+1 == len(steps) || cur.Error != "" {
+			continue
+		}
+		next := &steps[i+1]
+		if next.Depth != cur.Depth || callOps[cur.Op] {
+			continue
+		}
+		if next.Gas != cur.Gas-cur.GasCost {
+			return fmt.Errorf("step %d (%s): gas %d, cost %d, then gas %d", i, cur.Op, cur.Gas, cur.GasCost, next.Gas)
+		}
+		if next.MemorySize < cur.MemorySize {
+			return fmt.Errorf("step %d (%s): memory shrank from %d to %d", i, cur.Op, cur.MemorySize, next.MemorySize)
+		}
+		// code is that of the program only at the outermost call frame
+		if cur.Depth != 1 {
+			continue
+		}
+		if err := checkStep(code, input, &cur.StructLog, &next.StructLog); err != nil {
+			return fmt.Errorf("step %d (%s): %w", i, cur.Op, err)
+		}
+	}
+	return nil
+}
+
+// callOps are the opcodes entering a call frame, after which the gas is that
+// returned by the frame.
+var callOps = map[vm.OpCode]bool{
+	vm.CALL: true, vm.CALLCODE: true, vm.DELEGATECALL: true, vm.STATICCALL: true, vm.CREATE: true, vm.CREATE2: true,
+}
+
+// checkStep checks the program counter and the stack after a step executed
+// without error, for the opcodes of the model.
+func checkStep(code, input []byte, cur, next *logger.StructLog) error {
+	stack := make([]uint256.Int, len(cur.Stack))
+	for i, v := range cur.Stack {
+		stack[i].SetFromBig(v)
+	}
+	top := func(n int) *uint256.Int { return &stack[len(stack)-1-n] }
+	pop := func(n int) { stack = stack[:len(stack)-n] }
+	push := func(v *uint256.Int) { stack = append(stack, *v) }
+
+	op, nextPc := cur.Op, cur.Pc+1
+	switch {
+	case op >= vm.PUSH0 && op <= vm.PUSH32:
+		n := uint64(op - vm.PUSH0)
+		immediate := make([]byte, n)
+		if cur.Pc+1 < uint64(len(code)) {
+			copy(immediate, code[cur.Pc+1:])
+		}
+		push(new(uint256.Int).SetBytes(immediate))
+		nextPc += n
+	case op >= vm.DUP1 && op <= vm.DUP16:
+		push(top(int(op - vm.DUP1)))
+	case op >= vm.SWAP1 && op <= vm.SWAP16:
+		a, b := top(0), top(int(op-vm.SWAP1)+1)
+		*a, *b = *b, *a
+	default:
+		f, ok := model[op]
+		if !ok {
+			if op != vm.JUMP && op != vm.JUMPI {
+				return nil
+			}
+			dest, cond := *top(0), new(uint256.Int)
+			if op == vm.JUMPI {
+				cond = top(1)
+				pop(2)
+			} else {
+				cond.SetOne()
+				pop(1)
+			}
+			if !cond.IsZero() {
+				nextPc = dest.Uint64()
+			}
+			break
+		}
+		args := make([]*uint256.Int, f.pop)
+		for i := range args {
+			args[i] = new(uint256.Int).Set(top(i))
+		}
+		pop(f.pop)
+		if v := f.eval(cur, input, args); v != nil {
+			push(v)
+		}
+	}
+
+	if next.Pc != nextPc {
+		return fmt.Errorf("pc %d, want %d", next.Pc, nextPc)
+	}
+	if len(next.Stack) != len(stack) {
+		return fmt.Errorf("stack of %d items, want %d", len(next.Stack), len(stack))
+	}
+	for i := range stack {
+		if v, overflow := uint256.FromBig(next.Stack[i]); overflow || !v.Eq(&stack[i]) {
+			return fmt.Errorf("stack item %d is %x, want %x", i, next.Stack[i], &stack[i])
+		}
+	}
+	return nil
+}
+
+// opModel is the model of an opcode: the number of items it pops, and the
+// item it pushes, if any.
+type opModel struct {
+	pop  int
+	eval func(cur *logger.StructLog, input []byte, args []*uint256.Int) *uint256.Int
+}
+
+func binaryOp(f func(z, x, y *uint256.Int) *uint256.Int) opModel {
+	return opModel{2, func(_ *logger.StructLog, _ []byte, args []*uint256.Int) *uint256.Int {
+		return f(new(uint256.Int), args[0], args[1])
+	}}
+}
+
+func predicate(f func(x, y *uint256.Int) bool) opModel {
+	return opModel{2, func(_ *logger.StructLog, _ []byte, args []*uint256.Int) *uint256.Int {
+		return boolean(f(args[0], args[1]))
+	}}
+}
+
+func shift(f func(z, x *uint256.Int, n uint) *uint256.Int, overflow func(x *uint256.Int) *uint256.Int) opModel {
+	return opModel{2, func(_ *logger.StructLog, _ []byte, args []*uint256.Int) *uint256.Int {
+		if !args[0].LtUint64(256) {
+			return overflow(args[1])
+		}
+		return f(new(uint256.Int), args[1], uint(args[0].Uint64()))
+	}}
+}
+
+func unaryOp(f func(x *uint256.Int) *uint256.Int) opModel {
+	return opModel{1, func(_ *logger.StructLog, _ []byte, args []*uint256.Int) *uint256.Int {
+		return f(args[0])
+	}}
+}
+
+func nullaryOp(f func(cur *logger.StructLog, input []byte) *uint256.Int) opModel {
+	return opModel{0, func(cur *logger.StructLog, input []byte, _ []*uint256.Int) *uint256.Int {
+		return f(cur, input)
+	}}
+}
+
+func boolean(b bool) *uint256.Int {
+	if b {
+		return uint256.NewInt(1)
+	}
+	return new(uint256.Int)
+}
+
+var model = map[vm.OpCode]opModel{
+	vm.ADD: binaryOp((*uint256.Int).Add),
+	vm.SUB: binaryOp((*uint256.Int).Sub),
+	vm.MUL: binaryOp((*uint256.Int).Mul),
+	vm.DIV: binaryOp((*uint256.Int).Div),
+	vm.MOD: binaryOp((*uint256.Int).Mod),
+	vm.AND: binaryOp((*uint256.Int).And),
+	vm.OR:  binaryOp((*uint256.Int).Or),
+	vm.XOR: binaryOp((*uint256.Int).Xor),
+	vm.LT:  predicate((*uint256.Int).Lt),
+	vm.GT:  predicate((*uint256.Int).Gt),
+	vm.SLT: predicate((*uint256.Int).Slt),
+	vm.SGT: predicate((*uint256.Int).Sgt),
+	vm.EQ:  predicate((*uint256.Int).Eq),
+	vm.SHL: shift((*uint256.Int).Lsh, func(*uint256.Int) *uint256.Int { return new(uint256.Int) }),
+	vm.SHR: shift((*uint256.Int).Rsh, func(*uint256.Int) *uint256.Int { return new(uint256.Int) }),
+	vm.SAR: shift((*uint256.Int).SRsh, func(x *uint256.Int) *uint256.Int {
+		if x.Sign() < 0 {
+			return new(uint256.Int).SetAllOne()
+		}
+		return new(uint256.Int)
+	}),
+	vm.BYTE: binaryOp(func(z, n, x *uint256.Int) *uint256.Int { return z.Set(x).Byte(n) }),
+
+	vm.ISZERO: unaryOp(func(x *uint256.Int) *uint256.Int { return boolean(x.IsZero()) }),
+	vm.NOT:    unaryOp(func(x *uint256.Int) *uint256.Int { return new(uint256.Int).Not(x) }),
+	vm.POP:    unaryOp(func(*uint256.Int) *uint256.Int { return nil }),
+	vm.CALLDATALOAD: {1, func(_ *logger.StructLog, input []byte, args []*uint256.Int) *uint256.Int {
+		var word [32]byte
+		if offset, overflow := args[0].Uint64WithOverflow(); !overflow && offset < uint64(len(input)) {
+			copy(word[:], input[offset:])
+		}
+		return new(uint256.Int).SetBytes(word[:])
+	}},
+
+	vm.JUMPDEST: nullaryOp(func(*logger.StructLog, []byte) *uint256.Int { return nil }),
+	vm.PC:       nullaryOp(func(cur *logger.StructLog, _ []byte) *uint256.Int { return uint256.NewInt(cur.Pc) }),
+	vm.MSIZE:    nullaryOp(func(cur *logger.StructLog, _ []byte) *uint256.Int { return uint256.NewInt(uint64(cur.MemorySize)) }),
+	vm.GAS:      nullaryOp(func(cur *logger.StructLog, _ []byte) *uint256.Int { return uint256.NewInt(cur.Gas - cur.GasCost) }),
+	vm.CALLDATASIZE: nullaryOp(func(_ *logger.StructLog, input []byte) *uint256.Int {
+		return uint256.NewInt(uint64(len(input)))
+	}),
+}
+
+// compareTraces compares the steps and the results of two traces, on the
+// gas, the stack and the memory.
+func compareTraces(have, want []byte) error {
+	haveSteps, haveResult, err := parseTrace(have)
+	if err != nil {
+		return err
+	}
+	wantSteps, wantResult, err := parseTrace(want)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(haveSteps) && i < len(wantSteps); i++ {
+		h, w := &haveSteps[i], &wantSteps[i]
+		switch {
+		case h.Pc != w.Pc || h.Op != w.Op || h.Depth != w.Depth:
+			return fmt.Errorf("step %d: %s at pc %d depth %d, want %s at pc %d depth %d", i, h.Op, h.Pc, h.Depth, w.Op, w.Pc, w.Depth)
+		case h.Gas != w.Gas || h.GasCost != w.GasCost || h.RefundCounter != w.RefundCounter:
+			return fmt.Errorf("step %d (%s): gas %d cost %d refund %d, want gas %d cost %d refund %d", i, h.Op, h.Gas, h.GasCost, h.RefundCounter, w.Gas, w.GasCost, w.RefundCounter)
+		case !equalStacks(h.Stack, w.Stack):
+			return fmt.Errorf("step %d (%s): stack %v, want %v", i, h.Op, h.Stack, w.Stack)
+		case h.MemorySize != w.MemorySize || !bytes.Equal(h.Memory, w.Memory):
+			return fmt.Errorf("step %d (%s): memory %x, want %x", i, h.Op, h.Memory, w.Memory)
+		case h.Error != w.Error:
+			return fmt.Errorf("step %d (%s): error %q, want %q", i, h.Op, h.Error, w.Error)
+		}
+	}
+	if len(haveSteps) != len(wantSteps) {
+		return fmt.Errorf("%d steps, want %d", len(haveSteps), len(wantSteps))
+	}
+	if haveResult != wantResult {
+		return fmt.Errorf("result %+v, want %+v", haveResult, wantResult)
+	}
+	return nil
+}
+
+func equalStacks(a, b []*big.Int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Cmp(b[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// coverage counts the opcodes executed at each fork.
+type coverage map[string]map[vm.OpCode]int
+
+func (c coverage) add(fork string, steps []step) {
+	if c[fork] == nil {
+		c[fork] = map[vm.OpCode]int{}
+	}
+	for _, step := range steps {
+		if step.Error == "" {
+			c[fork][step.Op]++
+		}
+	}
+}
+
+// report writes, for each fork, the share of the opcodes defined at the fork
+// that were executed, and those that were not.
+func (c coverage) report(w io.Writer) {
+	for _, fork := range forks {
+		defined := definedOpcodes(fork)
+		var uncovered []string
+		for _, op := range defined {
+			if c[fork][op] == 0 {
+				uncovered = append(uncovered, op.String())
+			}
+		}
+		sort.Strings(uncovered)
+		fmt.Fprintf(w, "%s: %d/%d opcodes executed\n", fork, len(defined)-len(uncovered), len(defined))
+		if len(uncovered) > 0 {
+			fmt.Fprintf(w, "  not executed: %s\n", strings.Join(uncovered, " "))
+		}
+	}
+}
+
+// definedOpcodes returns the opcodes defined at a fork: those not failing as
+// invalid when executed.
+func definedOpcodes(fork string) []vm.OpCode {
+	var defined []vm.OpCode
+	for op := 0; op < 256; op++ {
+		_, err := execute(&program{Code: []byte{byte(op)}}, fork, false)
+		var invalid *vm.ErrInvalidOpCode
+		if !errors.As(err, &invalid) {
+			defined = append(defined, vm.OpCode(op))
+		}
+	}
+	return defined
+}
+
+// emptyState is the state without any account, the programs set theirs up.
+type emptyState struct{}
+
+func (emptyState) ReadAccountData(libcommon.Address) (*accounts.Account, error) { return nil, nil }
+func (emptyState) ReadAccountStorage(libcommon.Address, uint64, *libcommon.Hash) ([]byte, error) {
+	return nil, nil
+}
+func (emptyState) ReadAccountCode(libcommon.Address, uint64, libcommon.Hash) ([]byte, error) {
+	return nil, nil
+}
+func (emptyState) ReadAccountCodeSize(libcommon.Address, uint64, libcommon.Hash) (int, error) {
+	return 0, nil
+}
+func (emptyState) ReadAccountIncarnation(libcommon.Address) (uint64, error) { return 0, nil }
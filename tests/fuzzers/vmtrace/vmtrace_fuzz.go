@@ -0,0 +1,54 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package vmtrace
+
+import "fmt"
+
+// Fuzz is the entry point for go-fuzz: it executes a program decoded from the
+// input at each fork, and checks its trace against the model of the opcodes.
+//
+// It returns 1 for programs executing more than their first opcode, 0 otherwise.
+func Fuzz(input []byte) int {
+	executed, err := fuzz(input)
+	if err != nil {
+		panic(err)
+	}
+	if executed {
+		return 1
+	}
+	return 0
+}
+
+// fuzz executes a program decoded from the input at each fork, checks its
+// traces, and returns whether it executed more than its first opcode.
+func fuzz(input []byte) (bool, error) {
+	p := decodeProgram(input)
+	executed := false
+	for _, fork := range forks {
+		// the errors of the program are in its trace
+		trace, _ := execute(p, fork, true)
+		steps, _, err := parseTrace(trace)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", fork, err)
+		}
+		if err := checkTrace(p.Code, p.Input, steps); err != nil {
+			return false, fmt.Errorf("%s: %w\ncode: %x\ninput: %x", fork, err, p.Code, p.Input)
+		}
+		executed = executed || len(steps) > 1
+	}
+	return executed, nil
+}
@@ -0,0 +1,36 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !nofuzz
+
+package vmtrace
+
+import (
+	"testing"
+)
+
+// go test -trimpath -v -fuzz=FuzzVMTrace -fuzztime=60s ./tests/fuzzers/vmtrace
+
+func FuzzVMTrace(f *testing.F) {
+	for _, p := range referencePrograms(f) {
+		f.Add(encodeProgram(p))
+	}
+	f.Fuzz(func(t *testing.T, input []byte) {
+		if _, err := fuzz(input); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
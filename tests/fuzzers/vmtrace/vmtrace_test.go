@@ -0,0 +1,150 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package vmtrace
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/core/asm"
+)
+
+// To record the reference traces and the opcode coverage report, run
+//
+//	go test -run TestReferenceTraces -write-reference-traces
+//
+// The traces are recorded by Erigon itself, so they are regression snapshots:
+// review the diff of testdata/traces before committing re-recorded traces.
+var writeReferenceTracesFlag = flag.Bool("write-reference-traces", false, "Overwrite the reference traces and the opcode coverage report in testdata/")
+
+// referenceAccounts are the accounts the reference programs are executed with:
+// one returning its caller, and one reverting.
+var referenceAccounts = []account{
+	{
+		Address: accountAddress(0),
+		Balance: 100,
+		// CALLER PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+		Code:    []byte{0x33, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3},
+		Storage: map[libcommon.Hash]libcommon.Hash{{31: 1}: {31: 0x2a}},
+	},
+	{
+		Address: accountAddress(1),
+		// PUSH1 0x2a PUSH1 0 MSTORE PUSH1 32 PUSH1 0 REVERT
+		Code:    []byte{0x60, 0x2a, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xfd},
+		Storage: map[libcommon.Hash]libcommon.Hash{},
+	},
+}
+
+// referencePrograms compiles the programs of testdata/programs, by name.
+func referencePrograms(tb testing.TB) map[string]*program {
+	files, err := filepath.Glob(filepath.Join("testdata", "programs", "*.easm"))
+	require.NoError(tb, err)
+	require.NotEmpty(tb, files)
+
+	programs := map[string]*program{}
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		require.NoError(tb, err)
+		compiler := asm.NewCompiler(false)
+		compiler.Feed(asm.Lex(src, false))
+		bin, errs := compiler.Compile()
+		require.Empty(tb, errs, file)
+		programs[strings.TrimSuffix(filepath.Base(file), ".easm")] = &program{
+			Code:     libcommon.FromHex(bin),
+			Input:    libcommon.FromHex("0xa9059cbb000000000000000000000000000000000000000000000000000000000000acc0"),
+			Accounts: referenceAccounts,
+		}
+	}
+	return programs
+}
+
+// encodeProgram encodes a program as the input decodeProgram decodes it from.
+func encodeProgram(p *program) []byte {
+	b := []byte{byte(len(p.Accounts))}
+	for _, a := range p.Accounts {
+		b = append(b, byte(a.Balance), byte(len(a.Storage)))
+		for key, value := range a.Storage {
+			b = append(b, key[31], value[31])
+		}
+		b = append(b, byte(len(a.Code)))
+		b = append(b, a.Code...)
+	}
+	b = append(b, byte(len(p.Input)))
+	b = append(b, p.Input...)
+	return append(b, p.Code...)
+}
+
+func TestDecodeProgram(t *testing.T) {
+	for name, p := range referencePrograms(t) {
+		require.Equal(t, p, decodeProgram(encodeProgram(p)), name)
+	}
+}
+
+// TestReferenceTraces checks the traces of the reference programs at each fork
+// against the model of the opcodes and against their recorded snapshots, and
+// the opcode coverage of the reference programs against its recorded report.
+func TestReferenceTraces(t *testing.T) {
+	programs := referencePrograms(t)
+	names := make([]string, 0, len(programs))
+	for name := range programs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cov := coverage{}
+	for _, name := range names {
+		p := programs[name]
+		for _, fork := range forks {
+			trace, _ := execute(p, fork, false)
+			steps, _, err := parseTrace(trace)
+			require.NoError(t, err)
+			require.NoError(t, checkTrace(p.Code, p.Input, steps), "%s at %s", name, fork)
+			cov.add(fork, steps)
+
+			file := filepath.Join("testdata", "traces", name, fork+".jsonl")
+			if *writeReferenceTracesFlag {
+				require.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+				require.NoError(t, os.WriteFile(file, trace, 0644))
+				continue
+			}
+			want, err := os.ReadFile(file)
+			require.NoError(t, err)
+			require.NoError(t, compareTraces(trace, want), "%s at %s", name, fork)
+		}
+	}
+
+	var report bytes.Buffer
+	cov.report(&report)
+	t.Log("opcode coverage of the reference programs:\n" + report.String())
+	file := filepath.Join("testdata", "coverage.txt")
+	if *writeReferenceTracesFlag {
+		require.NoError(t, os.WriteFile(file, report.Bytes(), 0644))
+		return
+	}
+	want, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.Equal(t, string(want), report.String())
+}
@@ -43,6 +43,9 @@ var (
 	transactionTestDir = filepath.Join(baseDir, "TransactionTests")
 	rlpTestDir         = filepath.Join(baseDir, "RLPTests")
 	difficultyTestDir  = filepath.Join(baseDir, "DifficultyTests")
+	// EOF fixtures from the execution-spec-tests eip7692 releases, not vendored yet
+	eofTestDir      = filepath.Join(".", "execution-spec-tests", "eof_tests")
+	eofStateTestDir = filepath.Join(".", "execution-spec-tests", "eof_state_tests")
 )
 
 func readJSON(reader io.Reader, value interface{}) error {
@@ -107,6 +107,7 @@ var DefaultFlags = []cli.Flag{
 	&RpcSubscriptionFiltersMaxTxsFlag,
 	&RpcSubscriptionFiltersMaxAddressesFlag,
 	&RpcSubscriptionFiltersMaxTopicsFlag,
+	&RpcSubscriptionStateDiffsSocketFlag,
 
 	&utils.SnapKeepBlocksFlag,
 	&utils.SnapStopFlag,
@@ -221,6 +222,21 @@ var DefaultFlags = []cli.Flag{
 	&utils.CaplinEnableSnapshotGeneration,
 	&utils.CaplinMevRelayUrl,
 	&utils.CaplinValidatorMonitorFlag,
+	&utils.CaplinValidatorMonitorIndiciesFlag,
+	&utils.CaplinLightClientFlag,
+	&utils.CaplinLightClientTrustedRootFlag,
+	&utils.CaplinValidatorKeystoreDirFlag,
+	&utils.CaplinValidatorPasswordFileFlag,
+	&utils.CaplinValidatorFeeRecipientFlag,
+	&utils.CaplinValidatorGraffitiFlag,
+	&utils.CaplinValidatorRemoteSignerURLFlag,
+	&utils.CaplinValidatorDoppelgangerEpochsFlag,
+	&utils.CaplinValidatorSlashingProtectionImportFlag,
+	&utils.CaplinValidatorSlashingProtectionExportFlag,
+	&utils.CaplinKeymanagerFlag,
+	&utils.CaplinKeymanagerAddrFlag,
+	&utils.CaplinKeymanagerPortFlag,
+	&utils.CaplinKeymanagerTokenFileFlag,
 	&utils.CaplinCustomConfigFlag,
 	&utils.CaplinCustomGenesisFlag,
 
@@ -231,4 +247,6 @@ var DefaultFlags = []cli.Flag{
 	&SyncLoopBlockLimitFlag,
 	&SyncLoopBreakAfterFlag,
 	&SyncParallelStateFlushing,
+	&SyncExecPrefetchFlag,
+	&SyncExecBlockSTMFlag,
 }
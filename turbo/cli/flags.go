@@ -156,6 +156,18 @@ var (
 		Value: true,
 	}
 
+	SyncExecPrefetchFlag = cli.BoolFlag{
+		Name:  "sync.exec.prefetch",
+		Usage: "Speculatively executes upcoming blocks against the parent state, to read the state they touch ahead of the execution",
+		Value: false,
+	}
+
+	SyncExecBlockSTMFlag = cli.BoolFlag{
+		Name:  "sync.exec.blockstm",
+		Usage: "Executes the transactions of each block in parallel, re-executing those in conflict (Block-STM), where blocks are otherwise executed serially - e.g. at the chain tip",
+		Value: false,
+	}
+
 	UploadLocationFlag = cli.StringFlag{
 		Name:  "upload.location",
 		Usage: "Location to upload snapshot segments to",
@@ -260,6 +272,11 @@ var (
 		Usage: "Maximum number of topics per subscription to filter logs by.",
 		Value: rpchelper.DefaultFiltersConfig.RpcSubscriptionFiltersMaxTopics,
 	}
+	RpcSubscriptionStateDiffsSocketFlag = cli.StringFlag{
+		Name:  "rpc.subscription.statediffs.socket",
+		Usage: "Path of unix socket to stream per-block state diffs (NDJSON). Empty - disabled.",
+		Value: rpchelper.DefaultFiltersConfig.RpcSubscriptionStateDiffsSocket,
+	}
 
 	TxPoolCommitEvery = cli.DurationFlag{
 		Name:  "txpool.commit.every",
@@ -351,6 +368,8 @@ func ApplyFlagsForEthConfig(ctx *cli.Context, cfg *ethconfig.Config, logger log.
 		cfg.Sync.LoopBlockLimit = limit
 	}
 	cfg.Sync.ParallelStateFlushing = ctx.Bool(SyncParallelStateFlushing.Name)
+	cfg.Sync.ExecPrefetch = ctx.Bool(SyncExecPrefetchFlag.Name)
+	cfg.Sync.ExecBlockSTM = ctx.Bool(SyncExecBlockSTMFlag.Name)
 
 	if location := ctx.String(UploadLocationFlag.Name); len(location) > 0 {
 		cfg.Sync.UploadLocation = location
@@ -523,6 +542,7 @@ func setEmbeddedRpcDaemon(ctx *cli.Context, cfg *nodecfg.Config, logger log.Logg
 			RpcSubscriptionFiltersMaxTxs:       ctx.Int(RpcSubscriptionFiltersMaxTxsFlag.Name),
 			RpcSubscriptionFiltersMaxAddresses: ctx.Int(RpcSubscriptionFiltersMaxAddressesFlag.Name),
 			RpcSubscriptionFiltersMaxTopics:    ctx.Int(RpcSubscriptionFiltersMaxTopicsFlag.Name),
+			RpcSubscriptionStateDiffsSocket:    ctx.String(RpcSubscriptionStateDiffsSocketFlag.Name),
 		},
 		Gascap:                      ctx.Uint64(utils.RpcGasCapFlag.Name),
 		Feecap:                      ctx.Float64(utils.RPCGlobalTxFeeCapFlag.Name),
@@ -18,8 +18,10 @@ package jsonrpc
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"path/filepath"
 
 	jsoniter "github.com/json-iterator/go"
 
@@ -29,11 +31,14 @@ import (
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/kv/order"
 	"github.com/erigontech/erigon-lib/kv/rawdbv3"
+	"github.com/erigontech/erigon-lib/log/v3"
 
 	"github.com/erigontech/erigon/core/state"
 	"github.com/erigontech/erigon/core/types/accounts"
 	"github.com/erigontech/erigon/eth/stagedsync/stages"
 	tracersConfig "github.com/erigontech/erigon/eth/tracers/config"
+	"github.com/erigontech/erigon/eth/tracers/logger"
+	"github.com/erigontech/erigon/eth/tracers/sourcemap"
 	"github.com/erigontech/erigon/rlp"
 	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/turbo/adapter/ethapi"
@@ -62,22 +67,62 @@ type PrivateDebugAPI interface {
 	AccountAt(ctx context.Context, blockHash common.Hash, txIndex uint64, account common.Address) (*AccountResult, error)
 	GetRawHeader(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (hexutility.Bytes, error)
 	GetRawBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (hexutility.Bytes, error)
+	RegisterSourceMap(ctx context.Context, output json.RawMessage, sources map[string]string) ([]string, error)
 }
 
 // PrivateDebugAPIImpl is implementation of the PrivateDebugAPI interface based on remote Db access
 type PrivateDebugAPIImpl struct {
 	*BaseAPI
-	db     kv.RoDB
-	GasCap uint64
+	db         kv.RoDB
+	GasCap     uint64
+	sourceMaps *sourcemap.Registry
 }
 
 // NewPrivateDebugAPI returns PrivateDebugAPIImpl instance
 func NewPrivateDebugAPI(base *BaseAPI, db kv.RoDB, gascap uint64) *PrivateDebugAPIImpl {
+	sourceMaps := sourcemap.NewRegistry()
+	if base.dirs.DataDir != "" {
+		dir := filepath.Join(base.dirs.DataDir, "sourcemaps")
+		contracts, err := sourceMaps.LoadDir(dir)
+		if err != nil {
+			log.Warn("[rpc] failed to load source maps", "dir", dir, "err", err)
+		}
+		if len(contracts) > 0 {
+			log.Info("[rpc] loaded source maps", "dir", dir, "contracts", len(contracts))
+		}
+	}
 	return &PrivateDebugAPIImpl{
-		BaseAPI: base,
-		db:      db,
-		GasCap:  gascap,
+		BaseAPI:    base,
+		db:         db,
+		GasCap:     gascap,
+		sourceMaps: sourceMaps,
+	}
+}
+
+// RegisterSourceMap implements debug_registerSourceMap. Registers the contracts of a solc standard-json
+// output, whose sources have the given content by path, so that struct logs of their execution are
+// annotated with source locations. Returns the names of the registered contracts.
+func (api *PrivateDebugAPIImpl) RegisterSourceMap(ctx context.Context, output json.RawMessage, sources map[string]string) ([]string, error) {
+	return api.sourceMaps.Register(output, sources)
+}
+
+// withSourceMaps returns the trace config with the registered source maps, when
+// struct logs are traced.
+func (api *PrivateDebugAPIImpl) withSourceMaps(config *tracersConfig.TraceConfig) *tracersConfig.TraceConfig {
+	if api.sourceMaps == nil || api.sourceMaps.Len() == 0 || (config != nil && config.Tracer != nil) {
+		return config
+	}
+	var cfg tracersConfig.TraceConfig
+	if config != nil {
+		cfg = *config
+	}
+	var logCfg logger.LogConfig
+	if cfg.LogConfig != nil {
+		logCfg = *cfg.LogConfig
 	}
+	logCfg.SourceMaps = api.sourceMaps
+	cfg.LogConfig = &logCfg
+	return &cfg
 }
 
 // storageRangeAt implements debug_storageRangeAt. Returns information about a range of storage locations (if any) for the given address.
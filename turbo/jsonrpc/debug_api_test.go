@@ -19,7 +19,9 @@ package jsonrpc
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
@@ -29,6 +31,7 @@ import (
 	"github.com/erigontech/erigon-lib/log/v3"
 
 	"github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutility"
 	"github.com/erigontech/erigon-lib/kv"
 	"github.com/erigontech/erigon-lib/kv/kvcache"
 	"github.com/erigontech/erigon-lib/kv/order"
@@ -37,6 +40,8 @@ import (
 	"github.com/erigontech/erigon/cmd/rpcdaemon/rpcdaemontest"
 	"github.com/erigontech/erigon/core/types"
 	tracersConfig "github.com/erigontech/erigon/eth/tracers/config"
+	"github.com/erigontech/erigon/eth/tracers/logger"
+	"github.com/erigontech/erigon/eth/tracers/sourcemap"
 	"github.com/erigontech/erigon/rpc"
 	"github.com/erigontech/erigon/rpc/rpccfg"
 	"github.com/erigontech/erigon/turbo/adapter/ethapi"
@@ -521,3 +526,42 @@ func TestAccountAt(t *testing.T) {
 		require.Equal(0, int(results.Nonce))
 	})
 }
+
+func TestTraceCallWithSourceMap(t *testing.T) {
+	m, _, _ := rpcdaemontest.CreateTestSentry(t)
+	api := NewPrivateDebugAPI(newBaseApiForTest(m), m.DB, 0)
+
+	source := "contract C {\n    function f() public {\n        x = 1;\n    }\n}\n"
+	output := fmt.Sprintf(`{
+		"sources": {"c.sol": {"id": 0, "ast": {"nodeType": "SourceUnit", "src": "0:%d:0", "nodes": [
+			{"nodeType": "ContractDefinition", "name": "C", "src": "0:%d:0", "nodes": [
+				{"nodeType": "FunctionDefinition", "name": "f", "kind": "function", "src": "%d:%d:0"}]}]}}},
+		"contracts": {"c.sol": {"C": {"evm": {"deployedBytecode": {"object": "602a60005500", "sourceMap": "%d:6:0;;;"}}}}}
+	}`, len(source), len(source)-1, strings.Index(source, "function"), strings.Index(source, "}\n}")+1-strings.Index(source, "function"),
+		strings.Index(source, "x = 1;"))
+	_, err := api.RegisterSourceMap(m.Ctx, json.RawMessage(`{"contracts": {}}`), nil)
+	require.Error(t, err)
+	contracts, err := api.RegisterSourceMap(m.Ctx, json.RawMessage(output), map[string]string{"c.sol": source})
+	require.NoError(t, err)
+	require.Equal(t, []string{"c.sol:C"}, contracts)
+
+	to := common.HexToAddress("0x00000000000000000000000000000000000000c0")
+	code := hexutility.Bytes(common.FromHex("602a60005500"))
+	var buf bytes.Buffer
+	stream := jsoniter.NewStream(jsoniter.ConfigDefault, &buf, 4096)
+	err = api.TraceCall(m.Ctx, ethapi.CallArgs{To: &to}, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber), &tracersConfig.TraceConfig{
+		StateOverrides: &ethapi.StateOverrides{to: ethapi.Account{Code: &code}},
+	}, stream)
+	require.NoError(t, err)
+	require.NoError(t, stream.Flush())
+
+	var result struct {
+		StructLogs []logger.StructLogRes `json:"structLogs"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result), buf.String())
+	require.Len(t, result.StructLogs, 4)
+	want := &sourcemap.Location{File: "c.sol", Line: 3, Column: 9, Function: "C.f"}
+	for _, log := range result.StructLogs {
+		require.Equal(t, want, log.Source, "%s at pc %d", log.Op, log.Pc)
+	}
+}
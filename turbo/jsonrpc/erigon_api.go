@@ -25,7 +25,9 @@ import (
 
 	"github.com/erigontech/erigon/eth/filters"
 
+	txpool "github.com/erigontech/erigon-lib/gointerfaces/txpoolproto"
 	"github.com/erigontech/erigon-lib/kv"
+	"github.com/erigontech/erigon-lib/log/v3"
 
 	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/p2p"
@@ -45,6 +47,9 @@ type ErigonAPI interface {
 	GetBlockByTimestamp(ctx context.Context, timeStamp rpc.Timestamp, fullTx bool) (map[string]interface{}, error)
 	GetBalanceChangesInBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (map[common.Address]*hexutil.Big, error)
 
+	// Fee related (see ./erigon_fees.go)
+	FeeEstimate(ctx context.Context, blockCount rpc.DecimalOrHex, probability float64) (*feeEstimateResult, error)
+
 	// Receipt related (see ./erigon_receipts.go)
 	GetLogsByHash(ctx context.Context, hash common.Hash) ([][]*types.Log, error)
 	//GetLogsByNumber(ctx context.Context, number rpc.BlockNumber) ([][]*types.Log, error)
@@ -62,13 +67,17 @@ type ErigonImpl struct {
 	*BaseAPI
 	db         kv.RoDB
 	ethBackend rpchelper.ApiBackend
+	txPool     txpool.TxpoolClient
+	logger     log.Logger
 }
 
 // NewErigonAPI returns ErigonImpl instance
-func NewErigonAPI(base *BaseAPI, db kv.RoDB, eth rpchelper.ApiBackend) *ErigonImpl {
+func NewErigonAPI(base *BaseAPI, db kv.RoDB, eth rpchelper.ApiBackend, txPool txpool.TxpoolClient, logger log.Logger) *ErigonImpl {
 	return &ErigonImpl{
 		BaseAPI:    base,
 		db:         db,
 		ethBackend: eth,
+		txPool:     txPool,
+		logger:     logger,
 	}
 }
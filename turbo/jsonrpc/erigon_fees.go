@@ -0,0 +1,100 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package jsonrpc
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/erigontech/erigon-lib/common/hexutil"
+
+	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/eth/ethconfig"
+	"github.com/erigontech/erigon/eth/gasprice"
+	"github.com/erigontech/erigon/rpc"
+)
+
+type feeEstimateResult struct {
+	BlockNumber          hexutil.Uint64 `json:"blockNumber"`
+	Blocks               hexutil.Uint64 `json:"blocks"`
+	Probability          float64        `json:"probability"`
+	BaseFee              *hexutil.Big   `json:"baseFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"maxPriorityFeePerGas"`
+	MaxFeePerGas         *hexutil.Big   `json:"maxFeePerGas"`
+	PendingGas           hexutil.Uint64 `json:"pendingGas"`
+	BlobBaseFee          *hexutil.Big   `json:"baseFeePerBlobGas,omitempty"`
+	MaxFeePerBlobGas     *hexutil.Big   `json:"maxFeePerBlobGas,omitempty"`
+}
+
+// FeeEstimate implements erigon_feeEstimate. Returns the fees for a transaction to be included within
+// blockCount blocks with the given probability, between 0 and 1, modelled from the effective tips of the
+// recent blocks and from the pending transactions of the txpool.
+func (api *ErigonImpl) FeeEstimate(ctx context.Context, blockCount rpc.DecimalOrHex, probability float64) (*feeEstimateResult, error) {
+	tx, err := api.db.BeginRo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	oracle := gasprice.NewOracle(NewGasPriceOracleBackend(tx, api.BaseAPI), ethconfig.Defaults.GPO, api.gasCache, api.logger.New("app", "gasPriceOracle"))
+
+	estimate, err := oracle.FeeEstimate(ctx, int(blockCount), probability, api.pendingTransactions)
+	if err != nil {
+		return nil, err
+	}
+	result := &feeEstimateResult{
+		BlockNumber:          hexutil.Uint64(estimate.BlockNumber),
+		Blocks:               hexutil.Uint64(estimate.Blocks),
+		Probability:          estimate.Probability,
+		BaseFee:              (*hexutil.Big)(estimate.BaseFee),
+		MaxPriorityFeePerGas: (*hexutil.Big)(estimate.MaxPriorityFeePerGas),
+		MaxFeePerGas:         (*hexutil.Big)(estimate.MaxFeePerGas),
+		PendingGas:           hexutil.Uint64(estimate.PendingGas),
+	}
+	if estimate.BlobBaseFee != nil {
+		result.BlobBaseFee = (*hexutil.Big)(estimate.BlobBaseFee)
+		result.MaxFeePerBlobGas = (*hexutil.Big)(estimate.MaxFeePerBlobGas)
+	}
+	return result, nil
+}
+
+// pendingTransactions returns the best pending transactions of the txpool, if any, until their gas
+// exceeds the given gas. The oracle samples them once per head.
+func (api *ErigonImpl) pendingTransactions(ctx context.Context, gas uint64) ([]types.Transaction, error) {
+	if api.txPool == nil {
+		return nil, nil
+	}
+	reply, err := api.txPool.Pending(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	// the txpool returns its pending transactions best first
+	var (
+		pending []types.Transaction
+		total   uint64
+	)
+	for i := 0; i < len(reply.Txs) && total <= gas; i++ {
+		txn, err := types.DecodeWrappedTransaction(reply.Txs[i].RlpTx)
+		if err != nil {
+			return nil, fmt.Errorf("decoding transaction from: %x: %w", reply.Txs[i].RlpTx, err)
+		}
+		pending = append(pending, txn)
+		total += txn.GetGas()
+	}
+	return pending, nil
+}
@@ -88,7 +88,7 @@ func (api *ErigonImpl) GetLogs(ctx context.Context, crit filters.FilterCriteria)
 
 	} else {
 		// Convert the RPC block numbers into internal representations
-		latest, err := rpchelper.GetLatestBlockNumber(tx)
+		latest, err := rpchelper.GetLatestBlockNumberInView(ctx, tx)
 		if err != nil {
 			return nil, err
 		}
@@ -109,6 +109,9 @@ func (api *ErigonImpl) GetLogs(ctx context.Context, crit filters.FilterCriteria)
 				return nil, fmt.Errorf("negative value for ToBlock: %v", crit.ToBlock)
 			}
 		}
+		if end, err = rpchelper.ClampToReadView(ctx, tx, end); err != nil {
+			return nil, err
+		}
 	}
 	if end < begin {
 		return nil, fmt.Errorf("end (%d) < begin (%d)", end, begin)
@@ -162,7 +165,7 @@ func (api *ErigonImpl) GetLatestLogs(ctx context.Context, crit filters.FilterCri
 		end = header.Number.Uint64()
 	} else {
 		// Convert the RPC block numbers into internal representations
-		latest, err := rpchelper.GetLatestBlockNumber(tx)
+		latest, err := rpchelper.GetLatestBlockNumberInView(ctx, tx)
 		if err != nil {
 			return nil, err
 		}
@@ -183,6 +186,9 @@ func (api *ErigonImpl) GetLatestLogs(ctx context.Context, crit filters.FilterCri
 				return nil, fmt.Errorf("negative value for ToBlock: %v", crit.ToBlock)
 			}
 		}
+		if end, err = rpchelper.ClampToReadView(ctx, tx, end); err != nil {
+			return nil, err
+		}
 	}
 	if end < begin {
 		return nil, fmt.Errorf("end (%d) < begin (%d)", end, begin)
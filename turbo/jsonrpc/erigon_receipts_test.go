@@ -77,7 +77,7 @@ func TestErigonGetLatestLogs(t *testing.T) {
 	assert := assert.New(t)
 	m, _, _ := rpcdaemontest.CreateTestSentry(t)
 	db := m.DB
-	api := NewErigonAPI(newBaseApiForTest(m), db, nil)
+	api := NewErigonAPI(newBaseApiForTest(m), db, nil, nil, log.New())
 	expectedLogs, _ := api.GetLogs(m.Ctx, filters.FilterCriteria{FromBlock: big.NewInt(0), ToBlock: big.NewInt(rpc.LatestBlockNumber.Int64())})
 
 	expectedErigonLogs := make(types.ErigonLogs, 0)
@@ -123,7 +123,7 @@ func TestErigonGetLatestLogsIgnoreTopics(t *testing.T) {
 	assert := assert.New(t)
 	m, _, _ := rpcdaemontest.CreateTestSentry(t)
 	db := m.DB
-	api := NewErigonAPI(newBaseApiForTest(m), db, nil)
+	api := NewErigonAPI(newBaseApiForTest(m), db, nil, nil, log.New())
 	expectedLogs, _ := api.GetLogs(m.Ctx, filters.FilterCriteria{FromBlock: big.NewInt(0), ToBlock: big.NewInt(rpc.LatestBlockNumber.Int64())})
 
 	expectedErigonLogs := make([]*types.ErigonLog, 0)
@@ -210,7 +210,7 @@ func TestGetBlockReceiptsByBlockHash(t *testing.T) {
 	}
 	// Assemble the test environment
 	m := mockWithGenerator(t, 4, generator)
-	api := NewErigonAPI(newBaseApiForTest(m), m.DB, nil)
+	api := NewErigonAPI(newBaseApiForTest(m), m.DB, nil, nil, log.New())
 
 	expect := map[uint64]string{
 		0: `[]`,
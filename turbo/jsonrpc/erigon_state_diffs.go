@@ -0,0 +1,66 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package jsonrpc
+
+import (
+	"context"
+
+	"github.com/erigontech/erigon-lib/log/v3"
+
+	"github.com/erigontech/erigon/common/debug"
+	"github.com/erigontech/erigon/rpc"
+)
+
+// StateDiffs send a notification with account/storage/code changes of each new canonical block.
+// Websocket-only: `{"method": "erigon_subscribe", "params": ["stateDiffs"]}`.
+// Notification with `"unwind": true` - is reorg marker: chain was unwound to given block (see rpchelper.StateDiff).
+func (api *ErigonImpl) StateDiffs(ctx context.Context) (*rpc.Subscription, error) {
+	if api.filters == nil {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer debug.LogPanic()
+		diffs, id := api.filters.SubscribeStateDiffs(256)
+		defer api.filters.UnsubscribeStateDiffs(id)
+		for {
+			select {
+			case d, ok := <-diffs:
+				if d != nil {
+					err := notifier.Notify(rpcSub.ID, d)
+					if err != nil {
+						log.Warn("[rpc] error while notifying subscription", "err", err)
+					}
+				}
+				if !ok {
+					log.Warn("[rpc] state diffs channel was closed")
+					return
+				}
+			case <-rpcSub.Err():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
@@ -106,6 +106,9 @@ func (api *ErigonImpl) BlockNumber(ctx context.Context, rpcBlockNumPtr *rpc.Bloc
 			return 0, err
 		}
 	}
+	if blockNum, err = rpchelper.ClampToReadView(ctx, tx, blockNum); err != nil {
+		return 0, err
+	}
 
 	return hexutil.Uint64(blockNum), nil
 }
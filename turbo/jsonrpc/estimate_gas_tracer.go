@@ -0,0 +1,131 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package jsonrpc
+
+import (
+	"github.com/holiman/uint256"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+
+	"github.com/erigontech/erigon/core/vm"
+	"github.com/erigontech/erigon/params"
+)
+
+// gasFrame is a call frame traced by gasRequirementTracer.
+type gasFrame struct {
+	gas      uint64 // gas the frame starts with
+	required uint64 // gas the frame requires, from its start
+
+	// the call or create operation in progress
+	callGas  uint64 // gas of the frame before the operation
+	callCost uint64 // cost of the operation, including the gas sent for calls
+	callOp   vm.OpCode
+	overhead uint64 // cost of the operation but for the gas sent
+	stipend  uint64 // gas the callee gets for free
+}
+
+// gasRequirementTracer traces the gas a transaction requires to execute as
+// it does: the gas of each operation, and for each call the gas the callee
+// requires with the 63/64 rule (EIP-150) - which keeps a 64th of the gas of
+// the caller - and with the stipend of value transfers.
+type gasRequirementTracer struct {
+	gasLimit uint64
+	frames   []*gasFrame
+	required uint64
+}
+
+func (t *gasRequirementTracer) CaptureTxStart(gasLimit uint64) {
+	t.gasLimit = gasLimit
+}
+
+func (t *gasRequirementTracer) CaptureTxEnd(restGas uint64) {}
+
+func (t *gasRequirementTracer) CaptureStart(env *vm.EVM, from libcommon.Address, to libcommon.Address, precompile bool, create bool, input []byte, gas uint64, value *uint256.Int, code []byte) {
+	t.frames = append(t.frames[:0], &gasFrame{gas: gas})
+}
+
+func (t *gasRequirementTracer) CaptureEnd(output []byte, usedGas uint64, err error) {
+	frame := t.frames[0]
+	// the intrinsic gas is charged before the top frame
+	t.required = t.gasLimit - frame.gas + max(frame.required, usedGas)
+}
+
+func (t *gasRequirementTracer) CaptureEnter(typ vm.OpCode, from libcommon.Address, to libcommon.Address, precompile bool, create bool, input []byte, gas uint64, value *uint256.Int, code []byte) {
+	if len(t.frames) == 0 {
+		return
+	}
+	caller := t.frames[len(t.frames)-1]
+	switch caller.callOp {
+	case vm.CREATE, vm.CREATE2:
+		// the gas sent is not part of the cost of creates
+		caller.overhead, caller.stipend = caller.callCost, 0
+	default:
+		caller.stipend = 0
+		if (typ == vm.CALL || typ == vm.CALLCODE) && value != nil && !value.IsZero() {
+			caller.stipend = params.CallStipend
+		}
+		caller.overhead = caller.callCost - min(caller.callCost, gas-caller.stipend)
+	}
+	t.frames = append(t.frames, &gasFrame{gas: gas})
+}
+
+func (t *gasRequirementTracer) CaptureExit(output []byte, usedGas uint64, err error) {
+	if len(t.frames) < 2 {
+		return
+	}
+	callee := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+	caller := t.frames[len(t.frames)-1]
+
+	required := max(callee.required, usedGas)
+	required -= min(required, caller.stipend)
+	caller.required = max(caller.required, caller.gas-caller.callGas+caller.overhead+allButOne64thInverse(required))
+}
+
+// allButOne64thInverse returns the lowest gas of which all but a 64th is at
+// least the given gas: the gas to be left for a callee to get it.
+func allButOne64thInverse(gas uint64) uint64 {
+	inverse := gas + gas/63
+	for inverse-inverse/64 < gas {
+		inverse++
+	}
+	for inverse > 0 && (inverse-1)-(inverse-1)/64 >= gas {
+		inverse--
+	}
+	return inverse
+}
+
+func (t *gasRequirementTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if len(t.frames) == 0 {
+		return
+	}
+	frame := t.frames[len(t.frames)-1]
+	switch op {
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL, vm.CREATE, vm.CREATE2:
+		// accounted for on the exit of the callee, with the gas it requires
+		frame.callGas, frame.callCost, frame.callOp = gas, cost, op
+		frame.overhead, frame.stipend = cost, 0
+		return
+	case vm.SSTORE:
+		// SSTORE requires more than the stipend left (EIP-2200)
+		frame.required = max(frame.required, frame.gas-gas+params.SstoreSentryGasEIP2200+1)
+	}
+	frame.required = max(frame.required, frame.gas-gas+cost)
+}
+
+func (t *gasRequirementTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
@@ -45,7 +45,9 @@ import (
 	"github.com/erigontech/erigon/core/rawdb"
 	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/core/types/accounts"
+	"github.com/erigontech/erigon/core/vm"
 	ethFilters "github.com/erigontech/erigon/eth/filters"
+	"github.com/erigontech/erigon/eth/gasprice"
 	"github.com/erigontech/erigon/ethdb/prune"
 	"github.com/erigontech/erigon/polygon/bor/borcfg"
 	"github.com/erigontech/erigon/polygon/bridge"
@@ -107,7 +109,8 @@ type EthAPI interface {
 
 	// Sending related (see ./eth_call.go)
 	Call(ctx context.Context, args ethapi2.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *ethapi2.StateOverrides) (hexutility.Bytes, error)
-	EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *ethapi2.StateOverrides) (hexutil.Uint64, error)
+	EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *ethapi2.StateOverrides, blockOverrides *BlockOverrides) (hexutil.Uint64, error)
+	EstimateGasDiagnostics(ctx context.Context, argsOrNil *ethapi2.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *ethapi2.StateOverrides, blockOverrides *BlockOverrides) (*GasEstimateDiagnostics, error)
 	SendRawTransaction(ctx context.Context, encodedTx hexutility.Bytes) (common.Hash, error)
 	SendTransaction(_ context.Context, txObject interface{}) (common.Hash, error)
 	Sign(ctx context.Context, _ common.Address, _ hexutility.Bytes) (hexutility.Bytes, error)
@@ -128,6 +131,7 @@ type BaseAPI struct {
 	// all caches are thread-safe
 	stateCache kvcache.Cache
 	blocksLRU  *lru.Cache[common.Hash, *types.Block]
+	gasCache   *GasPriceCache // shared by the gas price oracles of the APIs
 
 	filters      *rpchelper.Filters
 	_chainConfig atomic.Pointer[chain.Config]
@@ -165,6 +169,7 @@ func NewBaseApi(f *rpchelper.Filters, stateCache kvcache.Cache, blockReader serv
 		filters:             f,
 		stateCache:          stateCache,
 		blocksLRU:           blocksLRU,
+		gasCache:            NewGasPriceCache(),
 		_blockReader:        blockReader,
 		_txnReader:          blockReader,
 		evmCallTimeout:      evmCallTimeout,
@@ -269,6 +274,12 @@ func (api *BaseAPI) chainConfigWithGenesis(ctx context.Context, tx kv.Tx) (*chai
 	if err != nil {
 		return nil, nil, err
 	}
+	if cc != nil {
+		// a standalone daemon has to register the same precompiles as erigon
+		if err := vm.CheckPrecompiles(cc); err != nil {
+			return nil, nil, err
+		}
+	}
 	if cc != nil && genesisBlock != nil {
 		api._genesis.Store(genesisBlock)
 		api._chainConfig.Store(cc)
@@ -377,7 +388,6 @@ type APIImpl struct {
 	ethBackend                  rpchelper.ApiBackend
 	txPool                      txpool.TxpoolClient
 	mining                      txpool.MiningClient
-	gasCache                    *GasPriceCache
 	db                          kv.RoDB
 	GasCap                      uint64
 	FeeCap                      float64
@@ -404,7 +414,6 @@ func NewEthAPI(base *BaseAPI, db kv.RoDB, eth rpchelper.ApiBackend, txPool txpoo
 		ethBackend:                  eth,
 		txPool:                      txPool,
 		mining:                      mining,
-		gasCache:                    NewGasPriceCache(),
 		GasCap:                      gascap,
 		FeeCap:                      feecap,
 		AllowUnprotectedTxs:         allowUnprotectedTxs,
@@ -580,6 +589,10 @@ func newRPCRawTransactionFromBlockIndex(b *types.Block, index uint64) (hexutilit
 type GasPriceCache struct {
 	latestPrice *big.Int
 	latestHash  common.Hash
+	feeHead     common.Hash
+	feeSamples  *gasprice.FeeSamples
+	pending     []types.Transaction
+	hasPending  bool
 	mtx         sync.Mutex
 }
 
@@ -606,3 +619,43 @@ func (c *GasPriceCache) SetLatest(hash common.Hash, price *big.Int) {
 	c.latestHash = hash
 	c.mtx.Unlock()
 }
+
+func (c *GasPriceCache) GetFeeSamples(head common.Hash) *gasprice.FeeSamples {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.feeHead != head {
+		return nil
+	}
+	return c.feeSamples
+}
+
+func (c *GasPriceCache) SetFeeSamples(head common.Hash, samples *gasprice.FeeSamples) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.atFeeHead(head)
+	c.feeSamples = samples
+}
+
+func (c *GasPriceCache) GetPending(head common.Hash) ([]types.Transaction, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.feeHead != head {
+		return nil, false
+	}
+	return c.pending, c.hasPending
+}
+
+func (c *GasPriceCache) SetPending(head common.Hash, pending []types.Transaction) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.atFeeHead(head)
+	c.pending, c.hasPending = pending, true
+}
+
+// atFeeHead drops the fee samples and the pending transactions cached for
+// another head.
+func (c *GasPriceCache) atFeeHead(head common.Hash) {
+	if c.feeHead != head {
+		c.feeHead, c.feeSamples, c.pending, c.hasPending = head, nil, nil, false
+	}
+}
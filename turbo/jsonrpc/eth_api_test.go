@@ -50,7 +50,7 @@ func TestGetBalanceChangesInBlock(t *testing.T) {
 	myBlockNum := rpc.BlockNumberOrHashWithNumber(0)
 	m, _, _ := rpcdaemontest.CreateTestSentry(t)
 	db := m.DB
-	api := NewErigonAPI(newBaseApiForTest(m), db, nil)
+	api := NewErigonAPI(newBaseApiForTest(m), db, nil, nil, log.New())
 	balances, err := api.GetBalanceChangesInBlock(context.Background(), myBlockNum)
 	if err != nil {
 		t.Errorf("calling GetBalanceChangesInBlock resulted in an error: %v", err)
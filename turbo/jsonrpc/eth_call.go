@@ -34,6 +34,7 @@ import (
 	"github.com/holiman/uint256"
 	"google.golang.org/grpc"
 
+	"github.com/erigontech/erigon/accounts/abi"
 	"github.com/erigontech/erigon/core"
 	"github.com/erigontech/erigon/core/state"
 	"github.com/erigontech/erigon/core/types"
@@ -127,8 +128,67 @@ func headerByNumberOrHash(ctx context.Context, tx kv.Tx, blockNrOrHash rpc.Block
 	return header, nil
 }
 
+// GasEstimateDiagnostics are the diagnostics of a gas estimation, as returned by eth_estimateGasDiagnostics.
+type GasEstimateDiagnostics struct {
+	Gas                 *hexutil.Uint64  `json:"gas"`             // the estimate, nil if the estimation failed
+	Error               string           `json:"error,omitempty"` // why the estimation failed
+	GasCap              hexutil.Uint64   `json:"gasCap"`          // highest gas allowance
+	GasUsed             hexutil.Uint64   `json:"gasUsed"`         // at the highest gas allowance
+	GasRequired         hexutil.Uint64   `json:"gasRequired"`     // as traced at the highest gas allowance
+	LowestPassingGas    *hexutil.Uint64  `json:"lowestPassingGas"`
+	HighestFailingGas   *hexutil.Uint64  `json:"highestFailingGas"`
+	HighestFailingError string           `json:"highestFailingError,omitempty"`
+	Revert              hexutility.Bytes `json:"revert,omitempty"` // of the highest failing gas
+	RevertReason        string           `json:"revertReason,omitempty"`
+	Iterations          hexutil.Uint64   `json:"iterations"` // executions of the transaction
+}
+
+func (d *GasEstimateDiagnostics) pass(gas uint64) {
+	if d.LowestPassingGas == nil || gas < uint64(*d.LowestPassingGas) {
+		d.LowestPassingGas = (*hexutil.Uint64)(&gas)
+	}
+}
+
+func (d *GasEstimateDiagnostics) fail(gas uint64, err error, result *evmtypes.ExecutionResult) {
+	if d.HighestFailingGas != nil && gas < uint64(*d.HighestFailingGas) {
+		return
+	}
+	d.HighestFailingGas = (*hexutil.Uint64)(&gas)
+	d.HighestFailingError = err.Error()
+	d.Revert, d.RevertReason = nil, ""
+	if result != nil && len(result.Revert()) > 0 {
+		d.Revert = result.Revert()
+		if reason, errUnpack := abi.UnpackRevert(d.Revert); errUnpack == nil {
+			d.RevertReason = reason
+		}
+	}
+}
+
 // EstimateGas implements eth_estimateGas. Returns an estimate of how much gas is necessary to allow the transaction to complete. The transaction will not be added to the blockchain.
-func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *ethapi2.StateOverrides) (hexutil.Uint64, error) {
+func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *ethapi2.StateOverrides, blockOverrides *BlockOverrides) (hexutil.Uint64, error) {
+	diagnostics, err := api.estimateGas(ctx, argsOrNil, blockNrOrHash, overrides, blockOverrides)
+	if err != nil {
+		return 0, err
+	}
+	return *diagnostics.Gas, nil
+}
+
+// EstimateGasDiagnostics implements eth_estimateGasDiagnostics. Estimates the gas like eth_estimateGas, returning the
+// diagnostics of the estimation instead, also if it fails.
+func (api *APIImpl) EstimateGasDiagnostics(ctx context.Context, argsOrNil *ethapi2.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *ethapi2.StateOverrides, blockOverrides *BlockOverrides) (*GasEstimateDiagnostics, error) {
+	diagnostics, err := api.estimateGas(ctx, argsOrNil, blockNrOrHash, overrides, blockOverrides)
+	if diagnostics == nil {
+		return nil, err
+	}
+	if err != nil {
+		diagnostics.Error = err.Error()
+	}
+	return diagnostics, nil
+}
+
+// estimateGas binary searches the gas a transaction requires. It returns the diagnostics of the search as far
+// as it went, along with the error if the transaction fails at the highest gas allowance.
+func (api *APIImpl) estimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *ethapi2.StateOverrides, blockOverrides *BlockOverrides) (*GasEstimateDiagnostics, error) {
 	var args ethapi2.CallArgs
 	// if we actually get CallArgs here, we use them
 	if argsOrNil != nil {
@@ -137,7 +197,7 @@ func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs
 
 	dbtx, err := api.db.BeginRo(ctx)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	defer dbtx.Rollback()
 
@@ -146,6 +206,7 @@ func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs
 		lo     = params.TxGas - 1
 		hi     uint64
 		gasCap uint64
+		none   = &GasEstimateDiagnostics{Gas: new(hexutil.Uint64)}
 	)
 	// Use zero address if sender unspecified.
 	if args.From == nil {
@@ -160,25 +221,27 @@ func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs
 	// Determine the highest gas limit can be used during the estimation.
 	if args.Gas != nil && uint64(*args.Gas) >= params.TxGas {
 		hi = uint64(*args.Gas)
+	} else if blockOverrides != nil && blockOverrides.GasLimit != nil {
+		hi = uint64(*blockOverrides.GasLimit)
 	} else {
 		// Retrieve the block to act as the gas ceiling
 		h, err := headerByNumberOrHash(ctx, dbtx, bNrOrHash, api)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 		if h == nil {
 			// if a block number was supplied and there is no header return 0
 			if blockNrOrHash != nil {
-				return 0, nil
+				return none, nil
 			}
 
 			// block number not supplied, so we haven't found a pending block, read the latest block instead
 			h, err = headerByNumberOrHash(ctx, dbtx, latestNumOrHash, api)
 			if err != nil {
-				return 0, err
+				return nil, err
 			}
 			if h == nil {
-				return 0, nil
+				return none, nil
 			}
 		}
 		hi = h.GasLimit
@@ -186,7 +249,7 @@ func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs
 
 	var feeCap *big.Int
 	if args.GasPrice != nil && (args.MaxFeePerGas != nil || args.MaxPriorityFeePerGas != nil) {
-		return 0, errors.New("both gasPrice and (maxFeePerGas or maxPriorityFeePerGas) specified")
+		return nil, errors.New("both gasPrice and (maxFeePerGas or maxPriorityFeePerGas) specified")
 	} else if args.GasPrice != nil {
 		feeCap = args.GasPrice.ToInt()
 	} else if args.MaxFeePerGas != nil {
@@ -198,19 +261,19 @@ func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs
 	if feeCap.Sign() != 0 {
 		cacheView, err := api.stateCache.View(ctx, dbtx)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 		stateReader := rpchelper.CreateLatestCachedStateReader(cacheView, dbtx)
 		state := state.New(stateReader)
 		if state == nil {
-			return 0, errors.New("can't get the current state")
+			return nil, errors.New("can't get the current state")
 		}
 
 		balance := state.GetBalance(*args.From) // from can't be nil
 		available := balance.ToBig()
 		if args.Value != nil {
 			if args.Value.ToInt().Cmp(available) >= 0 {
-				return 0, errors.New("insufficient funds for transfer")
+				return nil, errors.New("insufficient funds for transfer")
 			}
 			available.Sub(available, args.Value.ToInt())
 		}
@@ -237,13 +300,13 @@ func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs
 
 	chainConfig, err := api.chainConfig(ctx, dbtx)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	engine := api.engine()
 
 	latestCanBlockNumber, latestCanHash, isLatest, err := rpchelper.GetCanonicalBlockNumber(ctx, latestNumOrHash, dbtx, api._blockReader, api.filters) // DoCall cannot be executed on non-canonical blocks
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	// try and get the block from the lru cache first then try DB before failing
@@ -251,75 +314,118 @@ func (api *APIImpl) EstimateGas(ctx context.Context, argsOrNil *ethapi2.CallArgs
 	if block == nil {
 		block, err = api.blockWithSenders(ctx, dbtx, latestCanHash, latestCanBlockNumber)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 	}
 	if block == nil {
-		return 0, errors.New("could not find latest block in cache or db")
+		return nil, errors.New("could not find latest block in cache or db")
 	}
 
 	txNumsReader := rawdbv3.TxNums.WithCustomReadTxNumFunc(freezeblocks.ReadTxNumFuncFromBlockReader(ctx, api._blockReader))
 	stateReader, err := rpchelper.CreateStateReaderFromBlockNumber(ctx, dbtx, txNumsReader, latestCanBlockNumber, isLatest, 0, api.stateCache, chainConfig.ChainName)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	header := block.HeaderNoCopy()
 
-	caller, err := transactions.NewReusableCaller(engine, stateReader, overrides, header, args, api.GasCap, latestNumOrHash, dbtx, api._blockReader, chainConfig, api.evmCallTimeout)
+	var overrideBlockCtx func(*evmtypes.BlockContext)
+	if blockOverrides != nil {
+		overrideBlockCtx = func(blockCtx *evmtypes.BlockContext) {
+			overrideBlockHash := make(map[uint64]libcommon.Hash)
+			blockHeaderOverride(blockCtx, *blockOverrides, overrideBlockHash)
+			if len(overrideBlockHash) > 0 {
+				getHash := blockCtx.GetHash
+				blockCtx.GetHash = func(n uint64) libcommon.Hash {
+					if hash, ok := overrideBlockHash[n]; ok {
+						return hash
+					}
+					return getHash(n)
+				}
+			}
+		}
+	}
+
+	caller, err := transactions.NewReusableCaller(engine, stateReader, overrides, overrideBlockCtx, header, args, api.GasCap, latestNumOrHash, dbtx, api._blockReader, chainConfig, api.evmCallTimeout)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
+	diagnostics := &GasEstimateDiagnostics{GasCap: hexutil.Uint64(gasCap)}
 	// Create a helper to check if a gas allowance results in an executable transaction
-	executable := func(gas uint64) (bool, *evmtypes.ExecutionResult, error) {
-		result, err := caller.DoCallWithNewGas(ctx, gas)
+	executable := func(gas uint64, tracer vm.EVMLogger) (bool, *evmtypes.ExecutionResult, error) {
+		diagnostics.Iterations++
+		result, err := caller.DoCallWithNewGas(ctx, gas, tracer)
 		if err != nil {
 			if errors.Is(err, core.ErrIntrinsicGas) {
 				// Special case, raise gas limit
+				diagnostics.fail(gas, err, nil)
 				return true, nil, nil
 			}
 
 			// Bail out
 			return true, nil, err
 		}
+		if result.Failed() {
+			diagnostics.fail(gas, result.Err, result)
+		} else {
+			diagnostics.pass(gas)
+		}
 		return result.Failed(), result, nil
 	}
 
-	// Execute the binary search and hone in on an executable gas limit
-	for lo+1 < hi {
-		mid := (hi + lo) / 2
-		failed, _, err := executable(mid)
-		// If the error is not nil(consensus error), it means the provided message
-		// call or transaction will never be accepted no matter how much gas it is
-		// assigened. Return the error directly, don't struggle any more.
+	// Execute at the highest allowance first, tracing the gas actually used and required, and reject the
+	// transaction as invalid if it fails there.
+	// If the error is not nil(consensus error), it means the provided message call or transaction will never
+	// be accepted no matter how much gas it is assigened. Return the error directly, don't struggle any more.
+	tracer := &gasRequirementTracer{}
+	failed, result, err := executable(hi, tracer)
+	if err != nil {
+		return nil, err
+	}
+	if failed {
+		if result != nil && !errors.Is(result.Err, vm.ErrOutOfGas) {
+			if len(result.Revert()) > 0 {
+				return diagnostics, ethapi2.NewRevertError(result)
+			}
+			return diagnostics, result.Err
+		}
+		// Otherwise, the specified gas cap is too low
+		return diagnostics, fmt.Errorf("gas required exceeds allowance (%d)", gasCap)
+	}
+	diagnostics.GasUsed, diagnostics.GasRequired = hexutil.Uint64(result.UsedGas), hexutil.Uint64(tracer.required)
+
+	// The gas used is a lower bound of the gas required, and the gas the trace requires is likely exactly it
+	lo = max(lo, result.UsedGas-1)
+	for _, gas := range []uint64{tracer.required, tracer.required - 1} {
+		if gas <= lo || gas >= hi {
+			break
+		}
+		failed, _, err := executable(gas, nil)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 		if failed {
-			lo = mid
-		} else {
-			hi = mid
+			lo = gas
+			break
 		}
+		hi = gas
 	}
 
-	// Reject the transaction as invalid if it still fails at the highest allowance
-	if hi == gasCap {
-		failed, result, err := executable(hi)
+	// Execute the binary search and hone in on an executable gas limit
+	for lo+1 < hi {
+		mid := (hi + lo) / 2
+		failed, _, err := executable(mid, nil)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 		if failed {
-			if result != nil && !errors.Is(result.Err, vm.ErrOutOfGas) {
-				if len(result.Revert()) > 0 {
-					return 0, ethapi2.NewRevertError(result)
-				}
-				return 0, result.Err
-			}
-			// Otherwise, the specified gas cap is too low
-			return 0, fmt.Errorf("gas required exceeds allowance (%d)", gasCap)
+			lo = mid
+		} else {
+			hi = mid
 		}
 	}
-	return hexutil.Uint64(hi), nil
+	diagnostics.Gas = (*hexutil.Uint64)(&hi)
+	return diagnostics, nil
 }
 
 // maxGetProofRewindBlockCount limits the number of blocks into the past that
@@ -66,11 +66,83 @@ func TestEstimateGas(t *testing.T) {
 	if _, err := api.EstimateGas(context.Background(), &ethapi.CallArgs{
 		From: &from,
 		To:   &to,
-	}, nil, nil); err != nil {
+	}, nil, nil, nil); err != nil {
 		t.Errorf("calling EstimateGas: %v", err)
 	}
 }
 
+func TestEstimateGasBlockOverrides(t *testing.T) {
+	m, bankAddress, _ := chainWithDeployedContract(t)
+	api := NewEthAPI(newBaseApiForTest(m), m.DB, nil, nil, nil, 5000000, 1e18, 100_000, false, 100_000, 128, log.New())
+
+	// Error("too early")
+	reason := make([]byte, 4+3*32)
+	copy(reason, []byte{0x08, 0xc3, 0x79, 0xa0})
+	reason[4+31] = 0x20
+	reason[4+63] = 9
+	copy(reason[4+64:], "too early")
+	// reverts with the reason before block 1000:
+	// PUSH2 1000 NUMBER LT PUSH1 9 JUMPI STOP JUMPDEST PUSH1 100 PUSH1 22 PUSH1 0 CODECOPY PUSH1 100 PUSH1 0 REVERT <reason>
+	code := hexutility.Bytes(append(hexutil.MustDecode("0x6103e84310600957005b6064601660003960646000fd"), reason...))
+	to := libcommon.HexToAddress("0x1000")
+	overrides := &ethapi.StateOverrides{to: {Code: &code}}
+	args := &ethapi.CallArgs{From: &bankAddress, To: &to}
+
+	_, err := api.EstimateGas(context.Background(), args, &latestNumOrHash, overrides, nil)
+	require.EqualError(t, err, "execution reverted: too early")
+
+	blockNumber := hexutil.Uint64(1000)
+	gas, err := api.EstimateGas(context.Background(), args, &latestNumOrHash, overrides, &BlockOverrides{BlockNumber: &blockNumber})
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Uint64(params.TxGas+21), gas)
+
+	diagnostics, err := api.EstimateGasDiagnostics(context.Background(), args, &latestNumOrHash, overrides, nil)
+	require.NoError(t, err)
+	require.Nil(t, diagnostics.Gas)
+	require.Equal(t, "execution reverted: too early", diagnostics.Error)
+	require.Nil(t, diagnostics.LowestPassingGas)
+	require.Equal(t, diagnostics.GasCap, *diagnostics.HighestFailingGas)
+	require.Equal(t, hexutility.Bytes(reason), diagnostics.Revert)
+	require.Equal(t, "too early", diagnostics.RevertReason)
+	require.Equal(t, hexutil.Uint64(1), diagnostics.Iterations)
+}
+
+func TestEstimateGasDiagnostics(t *testing.T) {
+	m, bankAddress, _ := chainWithDeployedContract(t)
+	api := NewEthAPI(newBaseApiForTest(m), m.DB, nil, nil, nil, 5000000, 1e18, 100_000, false, 100_000, 128, log.New())
+
+	var (
+		caller = libcommon.HexToAddress("0x1000")
+		callee = libcommon.HexToAddress("0x2000")
+		// PUSH1 0 PUSH1 0 PUSH1 0 PUSH1 0 PUSH1 0 PUSH20 callee GAS CALL PUSH1 40 JUMPI PUSH1 0 DUP1 REVERT JUMPDEST STOP
+		callerCode = hexutility.Bytes(hexutil.MustDecode("0x60006000600060006000730000000000000000000000000000000000002000" + "5af1602857600080fd5b00"))
+		// PUSH1 1 PUSH1 0 SSTORE STOP
+		calleeCode = hexutility.Bytes(hexutil.MustDecode("0x6001600055" + "00"))
+		overrides  = &ethapi.StateOverrides{caller: {Code: &callerCode}, callee: {Code: &calleeCode}}
+		args       = &ethapi.CallArgs{From: &bankAddress, To: &caller}
+	)
+	diagnostics, err := api.EstimateGasDiagnostics(context.Background(), args, &latestNumOrHash, overrides, nil)
+	require.NoError(t, err)
+	require.Empty(t, diagnostics.Error)
+	require.NotNil(t, diagnostics.Gas)
+	gas := *diagnostics.Gas
+	// the callee needs more than the gas used, which the caller keeps a 64th of
+	require.Greater(t, gas, diagnostics.GasUsed)
+	require.Equal(t, gas, *diagnostics.LowestPassingGas)
+	require.Equal(t, gas-1, *diagnostics.HighestFailingGas)
+	require.Equal(t, "execution reverted", diagnostics.HighestFailingError)
+	// the traced requirement saves most of the binary search
+	require.Equal(t, gas, diagnostics.GasRequired)
+	require.LessOrEqual(t, diagnostics.Iterations, hexutil.Uint64(3))
+
+	estimate, err := api.EstimateGas(context.Background(), args, &latestNumOrHash, overrides, nil)
+	require.NoError(t, err)
+	require.Equal(t, gas, estimate)
+	lower := gas - 1
+	_, err = api.EstimateGas(context.Background(), &ethapi.CallArgs{From: &bankAddress, To: &caller, Gas: &lower}, &latestNumOrHash, overrides, nil)
+	require.Error(t, err)
+}
+
 func TestEthCallNonCanonical(t *testing.T) {
 	m, _, _ := rpcdaemontest.CreateTestSentry(t)
 	stateCache := kvcache.New(kvcache.DefaultCoherentConfig)
@@ -240,7 +312,7 @@ func TestGetBlockByTimestampLatestTime(t *testing.T) {
 		t.Errorf("fail at beginning tx")
 	}
 	defer tx.Rollback()
-	api := NewErigonAPI(newBaseApiForTest(m), m.DB, nil)
+	api := NewErigonAPI(newBaseApiForTest(m), m.DB, nil, nil, log.New())
 
 	latestBlock, err := m.BlockReader.CurrentBlock(tx)
 	require.NoError(t, err)
@@ -275,7 +347,7 @@ func TestGetBlockByTimestampOldestTime(t *testing.T) {
 		t.Errorf("failed at beginning tx")
 	}
 	defer tx.Rollback()
-	api := NewErigonAPI(newBaseApiForTest(m), m.DB, nil)
+	api := NewErigonAPI(newBaseApiForTest(m), m.DB, nil, nil, log.New())
 
 	oldestBlock, err := m.BlockReader.BlockByNumber(m.Ctx, tx, 0)
 	if err != nil {
@@ -313,7 +385,7 @@ func TestGetBlockByTimeHigherThanLatestBlock(t *testing.T) {
 		t.Errorf("fail at beginning tx")
 	}
 	defer tx.Rollback()
-	api := NewErigonAPI(newBaseApiForTest(m), m.DB, nil)
+	api := NewErigonAPI(newBaseApiForTest(m), m.DB, nil, nil, log.New())
 
 	latestBlock, err := m.BlockReader.CurrentBlock(tx)
 	require.NoError(t, err)
@@ -349,7 +421,7 @@ func TestGetBlockByTimeMiddle(t *testing.T) {
 		t.Errorf("fail at beginning tx")
 	}
 	defer tx.Rollback()
-	api := NewErigonAPI(newBaseApiForTest(m), m.DB, nil)
+	api := NewErigonAPI(newBaseApiForTest(m), m.DB, nil, nil, log.New())
 
 	currentHeader := rawdb.ReadCurrentHeader(tx)
 	oldestHeader, err := api._blockReader.HeaderByNumber(ctx, tx, 0)
@@ -396,7 +468,7 @@ func TestGetBlockByTimestamp(t *testing.T) {
 		t.Errorf("fail at beginning tx")
 	}
 	defer tx.Rollback()
-	api := NewErigonAPI(newBaseApiForTest(m), m.DB, nil)
+	api := NewErigonAPI(newBaseApiForTest(m), m.DB, nil, nil, log.New())
 
 	highestBlockNumber := rawdb.ReadCurrentHeader(tx).Number
 	pickedBlock, err := m.BlockReader.BlockByNumber(m.Ctx, tx, highestBlockNumber.Uint64()/3)
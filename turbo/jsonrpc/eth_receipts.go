@@ -81,6 +81,11 @@ func (api *APIImpl) GetLogs(ctx context.Context, crit filters.FilterCriteria) (t
 		}
 
 		num := block.NumberU64()
+		if head, inView, err := rpchelper.ReadViewHead(ctx, tx); err != nil {
+			return nil, err
+		} else if inView && num > head {
+			return nil, fmt.Errorf("block %x is above read view head %d", *crit.BlockHash, head)
+		}
 		begin = num
 		end = num
 	} else {
@@ -117,13 +122,16 @@ func (api *APIImpl) GetLogs(ctx context.Context, crit filters.FilterCriteria) (t
 				}
 			}
 		}
+		if end, err = rpchelper.ClampToReadView(ctx, tx, end); err != nil {
+			return nil, err
+		}
 	}
 
 	if end < begin {
 		return nil, fmt.Errorf("end (%d) < begin (%d)", end, begin)
 	}
 	if end > roaring.MaxUint32 {
-		latest, err := rpchelper.GetLatestBlockNumber(tx)
+		latest, err := rpchelper.GetLatestBlockNumberInView(ctx, tx)
 		if err != nil {
 			return nil, err
 		}
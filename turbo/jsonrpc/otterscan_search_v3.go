@@ -28,6 +28,7 @@ import (
 	"github.com/erigontech/erigon-lib/log/v3"
 	"github.com/erigontech/erigon/core/types"
 	"github.com/erigontech/erigon/eth/ethutils"
+	"github.com/erigontech/erigon/turbo/rpchelper"
 	"github.com/erigontech/erigon/turbo/snapshotsync/freezeblocks"
 )
 
@@ -43,6 +44,10 @@ func (api *OtterscanAPIImpl) buildSearchResults(ctx context.Context, tx kv.Tempo
 	if err != nil {
 		return nil, nil, false, err
 	}
+	viewHead, inView, err := rpchelper.ReadViewHead(ctx, tx)
+	if err != nil {
+		return nil, nil, false, err
+	}
 
 	var block *types.Block
 	txs := make([]*RPCTransaction, 0, pageSize)
@@ -57,6 +62,10 @@ func (api *OtterscanAPIImpl) buildSearchResults(ctx context.Context, tx kv.Tempo
 		if err != nil {
 			return nil, nil, false, err
 		}
+		if inView && blockNum > viewHead {
+			// request is pinned by `Erigon-Read-View`: blocks above its head are invisible
+			continue
+		}
 
 		// Even if the desired page size is reached, drain the entire matching
 		// txs inside the block; reproduces e2 behavior. An e3/paginated-aware
@@ -333,6 +333,10 @@ func (api *TraceAPIImpl) Filter(ctx context.Context, req TraceFilterRequest, gas
 	} else {
 		toBlock = uint64(*req.ToBlock)
 	}
+	toBlock, err := rpchelper.ClampToReadView(ctx, dbtx, toBlock)
+	if err != nil {
+		return err
+	}
 	if fromBlock > toBlock {
 		return errors.New("invalid parameters: fromBlock cannot be greater than toBlock")
 	}
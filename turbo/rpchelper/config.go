@@ -24,6 +24,8 @@ type FiltersConfig struct {
 	RpcSubscriptionFiltersMaxTxs       int // Maximum number of transactions to store per subscription. Default: 0 (no limit)
 	RpcSubscriptionFiltersMaxAddresses int // Maximum number of addresses per subscription to filter logs by. Default: 0 (no limit)
 	RpcSubscriptionFiltersMaxTopics    int // Maximum number of topics per subscription to filter logs by. Default: 0 (no limit)
+
+	RpcSubscriptionStateDiffsSocket string // Path of unix socket to stream per-block state diffs as NDJSON. Default: "" (disabled)
 }
 
 // DefaultFiltersConfig defines the default settings for filter configurations.
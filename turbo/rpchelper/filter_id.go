@@ -31,6 +31,7 @@ type (
 	PendingBlockSubID SubscriptionID
 	PendingTxsSubID   SubscriptionID
 	LogsSubID         SubscriptionID
+	StateDiffsSubID   SubscriptionID
 )
 
 var globalSubscriptionId uint64
@@ -58,6 +58,8 @@ type Filters struct {
 	pendingLogsSubs  *concurrent.SyncMap[PendingLogsSubID, Sub[types.Logs]]
 	pendingBlockSubs *concurrent.SyncMap[PendingBlockSubID, Sub[*types.Block]]
 	pendingTxsSubs   *concurrent.SyncMap[PendingTxsSubID, Sub[[]types.Transaction]]
+	stateDiffsSubs   *concurrent.SyncMap[StateDiffsSubID, Sub[*StateDiff]]
+	stateDiffsSeq    atomic.Uint64 // Sequence of last StateDiff
 	logsSubs         *LogsFilterAggregator
 	logsRequestor    atomic.Value
 	onNewSnapshot    func()
@@ -81,6 +83,7 @@ func New(ctx context.Context, config FiltersConfig, ethBackend ApiBackend, txPoo
 		pendingTxsSubs:     concurrent.NewSyncMap[PendingTxsSubID, Sub[[]types.Transaction]](),
 		pendingLogsSubs:    concurrent.NewSyncMap[PendingLogsSubID, Sub[types.Logs]](),
 		pendingBlockSubs:   concurrent.NewSyncMap[PendingBlockSubID, Sub[*types.Block]](),
+		stateDiffsSubs:     concurrent.NewSyncMap[StateDiffsSubID, Sub[*StateDiff]](),
 		logsSubs:           NewLogsFilterAggregator(),
 		onNewSnapshot:      onNewSnapshot,
 		logsStores:         concurrent.NewSyncMap[LogsSubID, []*types.Log](),
@@ -147,6 +150,10 @@ func New(ctx context.Context, config FiltersConfig, ethBackend ApiBackend, txPoo
 		}
 	}()
 
+	if config.RpcSubscriptionStateDiffsSocket != "" {
+		go ff.serveStateDiffsSocket(ctx, config.RpcSubscriptionStateDiffsSocket)
+	}
+
 	if txPool != nil {
 		go func() {
 			activeSubscriptionsLogsClientGauge.With(prometheus.Labels{clientLabelName: "txPool_PendingTxs"}).Inc()
@@ -17,12 +17,19 @@
 package rpchelper
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/holiman/uint256"
 
 	"github.com/erigontech/erigon/core/types"
+	"github.com/erigontech/erigon/core/types/accounts"
 
 	libcommon "github.com/erigontech/erigon-lib/common"
 	"github.com/erigontech/erigon-lib/gointerfaces"
@@ -507,3 +514,124 @@ func TestFilters_AddPendingTxs(t *testing.T) {
 		})
 	}
 }
+
+func TestFilters_StateDiffs(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	socket := filepath.Join(t.TempDir(), "statediffs.sock")
+	f := New(ctx, FiltersConfig{RpcSubscriptionStateDiffsSocket: socket}, nil, nil, nil, func() {}, log.New())
+
+	diffs, id := f.SubscribeStateDiffs(8)
+	defer f.UnsubscribeStateDiffs(id)
+
+	var conn net.Conn
+	var err error
+	for i := 0; i < 100; i++ {
+		if conn, err = net.Dial("unix", socket); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for f.stateDiffsSubs.Len() != 2 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	acc := accounts.NewAccount()
+	acc.Nonce = 1
+	acc.Balance = *uint256.NewInt(100)
+	blockHash := libcommon.HexToHash("0x01")
+	location := libcommon.HexToHash("0x02")
+	// unwind restores accounts encoded for storage
+	restoredAcc := accounts.NewAccount()
+	restoredAcc.Nonce = 7
+	restored := make([]byte, restoredAcc.EncodingLengthForStorage())
+	restoredAcc.EncodeForStorage(restored)
+	f.OnStateChanges(&remote.StateChangeBatch{ChangeBatch: []*remote.StateChange{
+		{
+			Direction:   remote.Direction_FORWARD,
+			BlockHeight: 10,
+			BlockHash:   gointerfaces.ConvertHashToH256(blockHash),
+			Changes: []*remote.AccountChange{
+				{Address: address1H160, Action: remote.Action_UPSERT, Data: accounts.SerialiseV3(&acc),
+					StorageChanges: []*remote.StorageChange{{Location: gointerfaces.ConvertHashToH256(location), Data: []byte{3}}}},
+			},
+		},
+		{
+			Direction:   remote.Direction_UNWIND,
+			BlockHeight: 9,
+			BlockHash:   gointerfaces.ConvertHashToH256(libcommon.Hash{}),
+			Changes:     []*remote.AccountChange{{Address: address1H160, Action: remote.Action_UPSERT, Data: restored}},
+		},
+	}})
+
+	forward, unwind := <-diffs, <-diffs
+	if forward.Unwind || uint64(forward.BlockNumber) != 10 || forward.BlockHash != blockHash {
+		t.Fatalf("unexpected diff: %+v", forward)
+	}
+	if len(forward.Accounts) != 1 || forward.Accounts[0].Address != address1 || forward.Accounts[0].Action != "upsert" {
+		t.Fatalf("unexpected account diff: %+v", forward.Accounts)
+	}
+	if uint64(*forward.Accounts[0].Nonce) != 1 || forward.Accounts[0].Balance.ToInt().Uint64() != 100 {
+		t.Fatalf("unexpected account: %+v", forward.Accounts[0])
+	}
+	if !bytes.Equal(forward.Accounts[0].Storage[location], []byte{3}) {
+		t.Fatalf("unexpected storage: %+v", forward.Accounts[0].Storage)
+	}
+	if !unwind.Unwind || uint64(unwind.BlockNumber) != 9 {
+		t.Fatalf("expected unwind marker, got: %+v", unwind)
+	}
+	if len(unwind.Accounts) != 1 || uint64(*unwind.Accounts[0].Nonce) != 7 || unwind.Accounts[0].Balance.ToInt().Sign() != 0 {
+		t.Fatalf("unexpected restored account: %+v", unwind.Accounts)
+	}
+	if unwind.Sequence != forward.Sequence+1 {
+		t.Fatalf("unexpected sequence: %d after %d", unwind.Sequence, forward.Sequence)
+	}
+
+	// NDJSON sink gets same diffs, one per line
+	scanner := bufio.NewScanner(conn)
+	for _, expect := range []*StateDiff{forward, unwind} {
+		if !scanner.Scan() {
+			t.Fatal(scanner.Err())
+		}
+		var got StateDiff
+		if err := json.Unmarshal(scanner.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Sequence != expect.Sequence || got.BlockHash != expect.BlockHash || got.Unwind != expect.Unwind || len(got.Accounts) != len(expect.Accounts) {
+			t.Fatalf("unexpected ndjson line: %s", scanner.Bytes())
+		}
+	}
+
+	// overloaded subscriber misses diffs, and sees it by the gap in sequence
+	block := func(n uint64, changes ...*remote.AccountChange) *remote.StateChangeBatch {
+		return &remote.StateChangeBatch{ChangeBatch: []*remote.StateChange{{Direction: remote.Direction_FORWARD, BlockHeight: n,
+			BlockHash: gointerfaces.ConvertHashToH256(libcommon.Hash{}), Changes: changes}}}
+	}
+	for i := uint64(0); i < 10; i++ {
+		f.OnStateChanges(block(11 + i))
+	}
+	prev := unwind.Sequence
+	for i := 0; i < 8; i++ {
+		d := <-diffs
+		if d.Sequence != prev+1 {
+			t.Fatalf("unexpected sequence: %d after %d", d.Sequence, prev)
+		}
+		prev = d.Sequence
+	}
+	f.OnStateChanges(block(21))
+	if d := <-diffs; d.Sequence != prev+3 || uint64(d.BlockNumber) != 21 {
+		t.Fatalf("expected gap before block 21, got: %+v after %d", d, prev)
+	}
+
+	// undecodable account is not sent, but leaves a gap too
+	f.OnStateChanges(block(22, &remote.AccountChange{Address: address1H160, Action: remote.Action_UPSERT, Data: []byte{0, 0, 0}}))
+	f.OnStateChanges(block(23))
+	if d := <-diffs; d.Sequence != prev+5 || uint64(d.BlockNumber) != 23 {
+		t.Fatalf("expected gap before block 23, got: %+v after %d", d, prev+3)
+	}
+}
@@ -45,6 +45,58 @@ func (e nonCanonocalHashError) Error() string {
 	return fmt.Sprintf("hash %x is not currently canonical", e.hash)
 }
 
+// blockAboveReadViewError - explicitly requested block is above head of `Erigon-Read-View` (not finalized/safe yet)
+type blockAboveReadViewError struct{ number, head uint64 }
+
+func (e blockAboveReadViewError) ErrorCode() int { return UnknownBlockError.Code }
+
+func (e blockAboveReadViewError) Error() string {
+	return fmt.Sprintf("block %d is above read view head %d", e.number, e.head)
+}
+
+// ReadViewHead - returns head of `Erigon-Read-View` which request is pinned to. ok=false if request is not pinned.
+func ReadViewHead(ctx context.Context, tx kv.Tx) (head uint64, ok bool, err error) {
+	v := rpc.ReadViewFromContext(ctx)
+	if v == nil {
+		return 0, false, nil
+	}
+	head, err = v.Head(func(tag rpc.BlockNumber) (uint64, error) {
+		if tag == rpc.SafeBlockNumber {
+			return GetSafeBlockNumber(tx)
+		}
+		if whitelist.GetWhitelistingService() != nil {
+			num := borfinality.GetFinalizedBlockNumber(tx)
+			if num == 0 {
+				// nolint
+				return 0, errors.New("No finalized block")
+			}
+			return borfinality.CurrentFinalizedBlock(tx, num).NumberU64(), nil
+		}
+		return GetFinalizedBlockNumber(tx)
+	})
+	return head, true, err
+}
+
+// GetLatestBlockNumberInView - same as GetLatestBlockNumber, but returns head of `Erigon-Read-View` if request is pinned
+func GetLatestBlockNumberInView(ctx context.Context, tx kv.Tx) (uint64, error) {
+	if head, ok, err := ReadViewHead(ctx, tx); ok || err != nil {
+		return head, err
+	}
+	return GetLatestBlockNumber(tx)
+}
+
+// ClampToReadView - clamps end of blocks range by head of `Erigon-Read-View` (if request is pinned)
+func ClampToReadView(ctx context.Context, tx kv.Tx, blockNum uint64) (uint64, error) {
+	head, ok, err := ReadViewHead(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	if ok && blockNum > head {
+		return head, nil
+	}
+	return blockNum, nil
+}
+
 func GetBlockNumber(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, tx kv.Tx, br services.FullBlockReader, filters *Filters) (uint64, libcommon.Hash, bool, error) {
 	bn, bh, latest, _, err := _GetBlockNumber(ctx, blockNrOrHash.RequireCanonical, blockNrOrHash, tx, br, filters)
 	return bn, bh, latest, err
@@ -62,10 +114,20 @@ func _GetBlockNumber(ctx context.Context, requireCanonical bool, blockNrOrHash r
 	if plainStateBlockNumber, err = stages.GetStageProgress(tx, stages.Execution); err != nil {
 		return 0, libcommon.Hash{}, false, false, fmt.Errorf("getting plain state block number: %w", err)
 	}
+	viewHead, inView, err := ReadViewHead(ctx, tx)
+	if err != nil {
+		return 0, libcommon.Hash{}, false, false, err
+	}
 	var ok bool
 	hash, ok = blockNrOrHash.Hash()
 	if !ok {
 		number := *blockNrOrHash.BlockNumber
+		if inView {
+			switch number {
+			case rpc.LatestBlockNumber, rpc.PendingBlockNumber, rpc.LatestExecutedBlockNumber:
+				number = rpc.BlockNumber(viewHead)
+			}
+		}
 		switch number {
 		case rpc.LatestBlockNumber:
 			if blockNumber, err = GetLatestBlockNumber(tx); err != nil {
@@ -94,6 +156,9 @@ func _GetBlockNumber(ctx context.Context, requireCanonical bool, blockNrOrHash r
 			if err != nil {
 				return 0, libcommon.Hash{}, false, false, err
 			}
+			if inView && blockNumber > viewHead { // "safe" is ahead of "finalized"
+				blockNumber = viewHead
+			}
 		case rpc.PendingBlockNumber:
 			pendingBlock := filters.LastPendingBlock()
 			if pendingBlock == nil {
@@ -105,6 +170,9 @@ func _GetBlockNumber(ctx context.Context, requireCanonical bool, blockNrOrHash r
 			blockNumber = plainStateBlockNumber
 		default:
 			blockNumber = uint64(number.Int64())
+			if inView && blockNumber > viewHead {
+				return 0, libcommon.Hash{}, false, false, blockAboveReadViewError{blockNumber, viewHead}
+			}
 		}
 		hash, ok, err = br.CanonicalHash(ctx, tx, blockNumber)
 		if err != nil {
@@ -122,6 +190,9 @@ func _GetBlockNumber(ctx context.Context, requireCanonical bool, blockNrOrHash r
 			return 0, libcommon.Hash{}, false, false, fmt.Errorf("block %x not found", hash)
 		}
 		blockNumber = *number
+		if inView && blockNumber > viewHead {
+			return 0, libcommon.Hash{}, false, false, blockAboveReadViewError{blockNumber, viewHead}
+		}
 
 		ch, ok, err := br.CanonicalHash(ctx, tx, blockNumber)
 		if err != nil {
@@ -0,0 +1,231 @@
+// Copyright 2024 The Erigon Authors
+// This file is part of Erigon.
+//
+// Erigon is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Erigon is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with Erigon. If not, see <http://www.gnu.org/licenses/>.
+
+package rpchelper
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	libcommon "github.com/erigontech/erigon-lib/common"
+	"github.com/erigontech/erigon-lib/common/hexutil"
+	"github.com/erigontech/erigon-lib/common/hexutility"
+	"github.com/erigontech/erigon-lib/gointerfaces"
+	remote "github.com/erigontech/erigon-lib/gointerfaces/remoteproto"
+
+	"github.com/erigontech/erigon/core/types/accounts"
+)
+
+// StateDiff - account/storage/code changes of one canonical block.
+// Produced from `remote.StateChangeBatch` (the same stream which feeds kvcache and txpool).
+//
+// Unwind=true is a reorg marker: chain was unwound to BlockNumber/BlockHash, and Accounts
+// carry values which were restored by unwind. Blocks of new canonical chain will follow as usual diffs.
+//
+// Sequence is increased by 1 for every diff produced by this process: subscriber which was overloaded
+// (and its diffs dropped) sees a gap in Sequence and must re-sync.
+type StateDiff struct {
+	Sequence    hexutil.Uint64 `json:"sequence"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   libcommon.Hash `json:"blockHash"`
+	Unwind      bool           `json:"unwind"`
+	Accounts    []*AccountDiff `json:"accounts"`
+}
+
+// AccountDiff - changes of one account. Action is one of: "upsert", "upsertCode", "code", "storage", "remove"
+type AccountDiff struct {
+	Address     libcommon.Address                   `json:"address"`
+	Action      string                              `json:"action"`
+	Incarnation hexutil.Uint64                      `json:"incarnation"`
+	Nonce       *hexutil.Uint64                     `json:"nonce,omitempty"`
+	Balance     *hexutil.Big                        `json:"balance,omitempty"`
+	CodeHash    *libcommon.Hash                     `json:"codeHash,omitempty"`
+	Code        hexutility.Bytes                    `json:"code,omitempty"`
+	Storage     map[libcommon.Hash]hexutility.Bytes `json:"storage,omitempty"`
+}
+
+var stateDiffActions = map[remote.Action]string{
+	remote.Action_STORAGE:     "storage",
+	remote.Action_UPSERT:      "upsert",
+	remote.Action_CODE:        "code",
+	remote.Action_UPSERT_CODE: "upsertCode",
+	remote.Action_REMOVE:      "remove",
+}
+
+// NewStateDiffs converts batch of state changes to list of per-block diffs (in same order)
+func NewStateDiffs(batch *remote.StateChangeBatch) ([]*StateDiff, error) {
+	diffs := make([]*StateDiff, 0, len(batch.ChangeBatch))
+	for _, change := range batch.ChangeBatch {
+		diff := &StateDiff{
+			BlockNumber: hexutil.Uint64(change.BlockHeight),
+			BlockHash:   gointerfaces.ConvertH256ToHash(change.BlockHash),
+			Unwind:      change.Direction == remote.Direction_UNWIND,
+			Accounts:    make([]*AccountDiff, 0, len(change.Changes)),
+		}
+		for _, ch := range change.Changes {
+			d, err := newAccountDiff(ch, diff.Unwind)
+			if err != nil {
+				return nil, fmt.Errorf("state diff of block %d: %w", change.BlockHeight, err)
+			}
+			diff.Accounts = append(diff.Accounts, d)
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+// newAccountDiff - accounts of forward changes are serialised by accounts.SerialiseV3 (as in domain),
+// but accounts restored by unwind are encoded by Account.EncodeForStorage (see StateV3.Unwind)
+func newAccountDiff(ch *remote.AccountChange, unwind bool) (*AccountDiff, error) {
+	d := &AccountDiff{
+		Address:     gointerfaces.ConvertH160toAddress(ch.Address),
+		Action:      stateDiffActions[ch.Action],
+		Incarnation: hexutil.Uint64(ch.Incarnation),
+	}
+	switch ch.Action {
+	case remote.Action_UPSERT, remote.Action_UPSERT_CODE:
+		var acc accounts.Account
+		var err error
+		if unwind {
+			err = acc.DecodeForStorage(ch.Data)
+		} else {
+			err = accounts.DeserialiseV3(&acc, ch.Data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decode account %x: %w", d.Address, err)
+		}
+		nonce := hexutil.Uint64(acc.Nonce)
+		codeHash := acc.CodeHash
+		d.Nonce, d.Balance, d.CodeHash = &nonce, (*hexutil.Big)(acc.Balance.ToBig()), &codeHash
+	}
+	switch ch.Action {
+	case remote.Action_CODE, remote.Action_UPSERT_CODE:
+		d.Code = ch.Code
+	}
+	if len(ch.StorageChanges) > 0 {
+		d.Storage = make(map[libcommon.Hash]hexutility.Bytes, len(ch.StorageChanges))
+		for _, sc := range ch.StorageChanges {
+			d.Storage[gointerfaces.ConvertH256ToHash(sc.Location)] = sc.Data
+		}
+	}
+	return d, nil
+}
+
+// OnStateChanges distributes state changes to `erigon_subscribe("stateDiffs")` subscribers
+func (ff *Filters) OnStateChanges(batch *remote.StateChangeBatch) {
+	if ff.stateDiffsSubs.Len() == 0 {
+		return
+	}
+	diffs, err := NewStateDiffs(batch)
+	if err != nil {
+		// skipped diffs leave a gap in Sequence, so subscribers know they missed them
+		ff.stateDiffsSeq.Add(uint64(len(batch.ChangeBatch)))
+		ff.logger.Warn("rpc filters: can't build state diffs", "err", err)
+		return
+	}
+	for _, diff := range diffs {
+		diff.Sequence = hexutil.Uint64(ff.stateDiffsSeq.Add(1))
+		ff.stateDiffsSubs.Range(func(k StateDiffsSubID, v Sub[*StateDiff]) error {
+			v.Send(diff)
+			return nil
+		})
+	}
+}
+
+// SubscribeStateDiffs subscribes to per-block state diffs and returns a channel to receive them
+// and a subscription ID to manage the subscription.
+func (ff *Filters) SubscribeStateDiffs(size int) (<-chan *StateDiff, StateDiffsSubID) {
+	id := StateDiffsSubID(generateSubscriptionID())
+	sub := newChanSub[*StateDiff](size)
+	ff.stateDiffsSubs.Put(id, sub)
+	return sub.ch, id
+}
+
+// UnsubscribeStateDiffs unsubscribes from state diffs using the given subscription ID.
+func (ff *Filters) UnsubscribeStateDiffs(id StateDiffsSubID) bool {
+	ch, ok := ff.stateDiffsSubs.Get(id)
+	if !ok {
+		return false
+	}
+	ch.Close()
+	_, ok = ff.stateDiffsSubs.Delete(id)
+	return ok
+}
+
+// serveStateDiffsSocket - streams state diffs as NDJSON (1 StateDiff per line) to every client connected to unix socket `path`
+func (ff *Filters) serveStateDiffsSocket(ctx context.Context, path string) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		ff.logger.Warn("rpc filters: can't remove stale state diffs socket", "path", path, "err", err)
+		return
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		ff.logger.Warn("rpc filters: can't listen state diffs socket", "path", path, "err", err)
+		return
+	}
+	ff.logger.Info("rpc filters: streaming state diffs", "socket", path)
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				ff.logger.Warn("rpc filters: state diffs socket closed", "err", err)
+			}
+			return
+		}
+		go ff.streamStateDiffs(ctx, conn)
+	}
+}
+
+func (ff *Filters) streamStateDiffs(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	diffs, id := ff.SubscribeStateDiffs(256)
+	defer ff.UnsubscribeStateDiffs(id)
+
+	w := bufio.NewWriter(conn)
+	enc := json.NewEncoder(w) // Encode adds '\n' after each value
+	var prevSeq hexutil.Uint64
+	for {
+		select {
+		case diff, ok := <-diffs:
+			if !ok {
+				return
+			}
+			if prevSeq != 0 && diff.Sequence != prevSeq+1 {
+				ff.logger.Warn("rpc filters: state diffs socket client is too slow, diffs dropped", "from", prevSeq+1, "to", diff.Sequence-1)
+			}
+			prevSeq = diff.Sequence
+			if err := enc.Encode(diff); err != nil {
+				return
+			}
+			if len(diffs) == 0 { // flush when caught up, to batch writes of long reorgs/catch-ups
+				if err := w.Flush(); err != nil {
+					return
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -263,8 +263,20 @@ func MockWithGenesisPruneMode(tb testing.TB, gspec *types.Genesis, key *ecdsa.Pr
 	return MockWithEverything(tb, gspec, key, prune, engine, blockBufferSize, false, withPosDownloader, checkStateRoot)
 }
 
+// MockWithSyncConfig is MockWithGenesis with the given sync settings instead
+// of the default ones.
+func MockWithSyncConfig(tb testing.TB, gspec *types.Genesis, key *ecdsa.PrivateKey, syncCfg ethconfig.Sync) *MockSentry {
+	return mockWithSyncConfig(tb, gspec, key, prune.DefaultMode, ethash.NewFaker(), blockBufferSize, false, false, true, syncCfg)
+}
+
 func MockWithEverything(tb testing.TB, gspec *types.Genesis, key *ecdsa.PrivateKey, prune prune.Mode,
 	engine consensus.Engine, blockBufferSize int, withTxPool, withPosDownloader, checkStateRoot bool,
+) *MockSentry {
+	return mockWithSyncConfig(tb, gspec, key, prune, engine, blockBufferSize, withTxPool, withPosDownloader, checkStateRoot, ethconfig.Defaults.Sync)
+}
+
+func mockWithSyncConfig(tb testing.TB, gspec *types.Genesis, key *ecdsa.PrivateKey, prune prune.Mode,
+	engine consensus.Engine, blockBufferSize int, withTxPool, withPosDownloader, checkStateRoot bool, syncCfg ethconfig.Sync,
 ) *MockSentry {
 	tmpdir := os.TempDir()
 	if tb != nil {
@@ -275,6 +287,7 @@ func MockWithEverything(tb testing.TB, gspec *types.Genesis, key *ecdsa.PrivateK
 	var err error
 
 	cfg := ethconfig.Defaults
+	cfg.Sync = syncCfg
 	cfg.StateStream = true
 	cfg.BatchSize = 1 * datasize.MB
 	cfg.Sync.BodyDownloadTimeoutSeconds = 10
@@ -535,7 +548,7 @@ func MockWithEverything(tb testing.TB, gspec *types.Genesis, key *ecdsa.PrivateK
 			mock.BlockReader,
 			mock.sentriesClient.Hd,
 			mock.gspec,
-			ethconfig.Defaults.Sync,
+			cfg.Sync,
 			nil,
 		), stagedsync.StageTxLookupCfg(mock.DB, prune, dirs.Tmp, mock.ChainConfig.Bor, mock.BlockReader), stagedsync.StageFinishCfg(mock.DB, dirs.Tmp, forkValidator), !withPosDownloader),
 		stagedsync.DefaultUnwindOrder,
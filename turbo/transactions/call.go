@@ -146,6 +146,7 @@ func MakeHeaderGetter(requireCanonical bool, tx kv.Getter, headerReader services
 type ReusableCaller struct {
 	evm             *vm.EVM
 	intraBlockState *state.IntraBlockState
+	overrides       *ethapi2.StateOverrides
 	gasCap          uint64
 	baseFee         *uint256.Int
 	stateReader     state.StateReader
@@ -153,9 +154,12 @@ type ReusableCaller struct {
 	message         *types.Message
 }
 
+// DoCallWithNewGas executes the call with a new gas limit, traced if the
+// tracer is not nil.
 func (r *ReusableCaller) DoCallWithNewGas(
 	ctx context.Context,
 	newGas uint64,
+	tracer vm.EVMLogger,
 ) (*evmtypes.ExecutionResult, error) {
 	var cancel context.CancelFunc
 	if r.callTimeout > 0 {
@@ -173,7 +177,18 @@ func (r *ReusableCaller) DoCallWithNewGas(
 	// reset the EVM so that we can continue to use it with the new context
 	txCtx := core.NewEVMTxContext(r.message)
 	r.intraBlockState = state.New(r.stateReader)
-	r.evm.Reset(txCtx, r.intraBlockState)
+	if r.overrides != nil {
+		if err := r.overrides.Override(r.intraBlockState); err != nil {
+			return nil, err
+		}
+	}
+	if tracer != nil || r.evm.Config().Debug {
+		vmConfig := r.evm.Config()
+		vmConfig.Debug, vmConfig.Tracer = tracer != nil, tracer
+		r.evm.ResetBetweenBlocks(r.evm.Context, txCtx, r.intraBlockState, vmConfig, r.evm.ChainRules())
+	} else {
+		r.evm.Reset(txCtx, r.intraBlockState)
+	}
 
 	timedOut := false
 	go func() {
@@ -200,6 +215,7 @@ func NewReusableCaller(
 	engine consensus.EngineReader,
 	stateReader state.StateReader,
 	overrides *ethapi2.StateOverrides,
+	blockOverrides func(*evmtypes.BlockContext),
 	header *types.Header,
 	initialArgs ethapi2.CallArgs,
 	gasCap uint64,
@@ -226,12 +242,19 @@ func NewReusableCaller(
 		}
 	}
 
+	blockCtx := NewEVMBlockContext(engine, header, blockNrOrHash.RequireCanonical, tx, headerReader, chainConfig)
+	if blockOverrides != nil {
+		blockOverrides(&blockCtx)
+		if baseFee != nil {
+			baseFee = blockCtx.BaseFee
+		}
+	}
+
 	msg, err := initialArgs.ToMessage(gasCap, baseFee)
 	if err != nil {
 		return nil, err
 	}
 
-	blockCtx := NewEVMBlockContext(engine, header, blockNrOrHash.RequireCanonical, tx, headerReader, chainConfig)
 	txCtx := core.NewEVMTxContext(msg)
 
 	evm := vm.NewEVM(blockCtx, txCtx, ibs, chainConfig, vm.Config{NoBaseFee: true})
@@ -239,6 +262,7 @@ func NewReusableCaller(
 	return &ReusableCaller{
 		evm:             evm,
 		intraBlockState: ibs,
+		overrides:       overrides,
 		baseFee:         baseFee,
 		gasCap:          gasCap,
 		callTimeout:     callTimeout,